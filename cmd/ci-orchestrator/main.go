@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"embed"
 	"fmt"
 	"log"
 	"os"
@@ -34,12 +35,18 @@ var (
 	BuildTimestamp = "unknown"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for the docs subcommand.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "ci-orchestrator",
 	LocalDir:     "cmd/ci-orchestrator/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 func main() {
@@ -61,38 +68,43 @@ func main() {
 }
 
 func printUsage() {
-	fmt.Print(`ci-orchestrator - Orchestrate CI pipelines (not yet implemented)
+	fmt.Print(`ci-orchestrator - Orchestrate CI pipelines
 
 Usage:
-  ci-orchestrator --mcp           Run as MCP server (not yet implemented)
+  ci-orchestrator --mcp           Run as MCP server
   ci-orchestrator version         Show version information
   ci-orchestrator help            Show this help message
 
 Description:
-  ci-orchestrator is a placeholder for future CI pipeline orchestration
-  functionality. Currently not implemented.
+  ci-orchestrator runs a pipeline definition: a list of stages, each
+  calling a tool on a forge engine over MCP. Stages run sequentially and
+  the pipeline stops at the first failing stage, unless that stage sets
+  continueOnError. With "mode: dag", stages run as soon as the stages
+  listed in their dependsOn have succeeded, independent stages running
+  concurrently.
 `)
 }
 
 // RunInput represents the input for the run tool.
 type RunInput struct {
+	// Pipeline is the path to the pipeline definition (YAML).
 	Pipeline string `json:"pipeline"`
 }
 
 func runMCPServer() error {
 	server := mcpserver.New("ci-orchestrator", Version)
 
-	// Register run tool (not yet implemented)
+	// Register run tool
 	mcpserver.RegisterTool(server, &mcp.Tool{
 		Name:        "run",
-		Description: "Run CI pipeline (not yet implemented)",
+		Description: "Run a CI pipeline: execute its stages sequentially by calling forge engines",
 	}, handleRunTool)
 
 	if err := enginedocs.RegisterDocsTools(server, *docsConfig); err != nil {
 		return err
 	}
 
-	return server.RunDefault()
+	return server.Run(enginecli.Context())
 }
 
 func handleRunTool(
@@ -100,6 +112,30 @@ func handleRunTool(
 	req *mcp.CallToolRequest,
 	input RunInput,
 ) (*mcp.CallToolResult, any, error) {
-	log.Printf("Run called (not yet implemented): pipeline=%s", input.Pipeline)
-	return mcputil.ErrorResult("ci-orchestrator: not yet implemented"), nil, nil
+	log.Printf("Running pipeline: %s", input.Pipeline)
+
+	if result := mcputil.ValidateRequired(map[string]string{
+		"pipeline": input.Pipeline,
+	}); result != nil {
+		return result, nil, nil
+	}
+
+	pipelineResult, err := newPipelineRunner(Version).runPipelineFile(input.Pipeline)
+	if err != nil {
+		return mcputil.ErrorResult(fmt.Sprintf("ci-orchestrator: %v", err)), nil, nil
+	}
+
+	message := pipelineResult.summary()
+	if pipelineResult.Status == statusFailed {
+		for _, stage := range pipelineResult.Stages {
+			if stage.Status == statusFailed {
+				message += fmt.Sprintf("; %s: %s", stage.Name, stage.Error)
+			}
+		}
+		result, artifact := mcputil.ErrorResultWithArtifact(message, pipelineResult)
+		return result, artifact, nil
+	}
+
+	result, artifact := mcputil.SuccessResultWithArtifact(message, pipelineResult)
+	return result, artifact, nil
 }
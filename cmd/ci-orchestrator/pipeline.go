@@ -0,0 +1,196 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// defaultStageTool is the MCP tool called on a stage's engine when the stage does not set one.
+const defaultStageTool = "build"
+
+// Pipeline execution modes.
+const (
+	// modeSequential runs stages one after another, in order.
+	modeSequential = "sequential"
+	// modeDAG runs each stage once the stages it depends on have succeeded,
+	// running independent stages concurrently.
+	modeDAG = "dag"
+)
+
+// Pipeline is a CI pipeline definition: a list of stages run sequentially or as a
+// dependency graph.
+type Pipeline struct {
+	// Name identifies the pipeline in logs and results.
+	Name string `json:"name"`
+	// Mode is "sequential" (default) or "dag".
+	Mode string `json:"mode,omitempty"`
+	// Concurrency caps the number of stages running at the same time in dag mode.
+	// Defaults to the number of CPUs.
+	Concurrency int `json:"concurrency,omitempty"`
+	// Stages are executed in order in sequential mode, or following DependsOn in dag mode.
+	Stages []Stage `json:"stages"`
+}
+
+// Stage is a single pipeline step that calls a tool on a forge engine over MCP.
+type Stage struct {
+	// Name identifies the stage in logs and results.
+	Name string `json:"name"`
+	// Engine is the engine URI (e.g., "go://generic-builder").
+	Engine string `json:"engine"`
+	// Tool is the MCP tool to call on the engine. Defaults to "build".
+	Tool string `json:"tool,omitempty"`
+	// Input is passed as-is as the tool's arguments.
+	Input map[string]any `json:"input,omitempty"`
+	// ContinueOnError lets the pipeline go on when this stage fails.
+	// The stage is still reported as failed, but does not fail the pipeline.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+	// DependsOn lists the stages that must succeed before this stage runs (dag mode only).
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// loadPipeline reads and validates the pipeline definition at path.
+func loadPipeline(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline %s: %w", path, err)
+	}
+
+	var pipeline Pipeline
+	if err := yaml.UnmarshalStrict(data, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline %s: %w", path, err)
+	}
+
+	if err := pipeline.validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline %s: %w", path, err)
+	}
+
+	return &pipeline, nil
+}
+
+// validate checks that the pipeline has stages, that every stage names a unique
+// stage and an engine, and that stage dependencies form an acyclic graph.
+// It defaults the pipeline's mode and concurrency and each stage's tool and input.
+func (p *Pipeline) validate() error {
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("pipeline has no stages")
+	}
+
+	switch p.Mode {
+	case "":
+		p.Mode = modeSequential
+	case modeSequential, modeDAG:
+	default:
+		return fmt.Errorf("unknown mode %q (expected %q or %q)", p.Mode, modeSequential, modeDAG)
+	}
+
+	if p.Concurrency < 0 {
+		return fmt.Errorf("concurrency must be positive, got %d", p.Concurrency)
+	}
+	if p.Concurrency == 0 {
+		p.Concurrency = runtime.NumCPU()
+	}
+
+	seen := make(map[string]bool, len(p.Stages))
+	for i := range p.Stages {
+		stage := &p.Stages[i]
+		if stage.Name == "" {
+			return fmt.Errorf("stages[%d]: name is required", i)
+		}
+		if seen[stage.Name] {
+			return fmt.Errorf("stages[%d]: duplicate stage name %q", i, stage.Name)
+		}
+		seen[stage.Name] = true
+
+		if stage.Engine == "" {
+			return fmt.Errorf("stage %q: engine is required", stage.Name)
+		}
+		if stage.Tool == "" {
+			stage.Tool = defaultStageTool
+		}
+		if stage.Input == nil {
+			stage.Input = map[string]any{}
+		}
+		if len(stage.DependsOn) > 0 && p.Mode != modeDAG {
+			return fmt.Errorf("stage %q: dependsOn requires mode %q", stage.Name, modeDAG)
+		}
+	}
+
+	for _, stage := range p.Stages {
+		for _, dep := range stage.DependsOn {
+			if !seen[dep] {
+				return fmt.Errorf("stage %q depends on unknown stage %q", stage.Name, dep)
+			}
+		}
+	}
+
+	return p.detectCycle()
+}
+
+// detectCycle returns an error naming the stages of the first dependency cycle found.
+func (p *Pipeline) detectCycle() error {
+	dependsOn := make(map[string][]string, len(p.Stages))
+	for _, stage := range p.Stages {
+		dependsOn[stage.Name] = stage.DependsOn
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(p.Stages))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			// Report the cycle starting from the first occurrence of name in the path
+			for i, stage := range path {
+				if stage == name {
+					cycle := append(append([]string{}, path[i:]...), name)
+					return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+				}
+			}
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range dependsOn[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, stage := range p.Stages {
+		if err := visit(stage.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
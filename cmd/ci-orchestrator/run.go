@@ -0,0 +1,267 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/mcpcaller"
+)
+
+// Stage and pipeline statuses reported in PipelineResult.
+const (
+	statusSuccess = "success"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// PipelineResult aggregates the outcome of a pipeline run.
+type PipelineResult struct {
+	Pipeline string        `json:"pipeline"`
+	Status   string        `json:"status"`   // "success" or "failed"
+	Duration float64       `json:"duration"` // seconds
+	Stages   []StageResult `json:"stages"`
+}
+
+// StageResult is the outcome of a single stage.
+type StageResult struct {
+	Name     string  `json:"name"`
+	Engine   string  `json:"engine"`
+	Status   string  `json:"status"`   // "success", "failed" or "skipped"
+	Duration float64 `json:"duration"` // seconds
+	Error    string  `json:"error,omitempty"`
+	// Output is the structured content returned by the engine, if any.
+	Output any `json:"output,omitempty"`
+}
+
+// pipelineRunner executes pipeline stages by calling forge engines over MCP.
+type pipelineRunner struct {
+	resolveEngine mcpcaller.EngineResolver
+	callMCP       mcpcaller.MCPCaller
+}
+
+// newPipelineRunner creates a runner that spawns engines with the given forge version.
+func newPipelineRunner(forgeVersion string) *pipelineRunner {
+	caller := mcpcaller.NewCaller(forgeVersion)
+	return &pipelineRunner{
+		resolveEngine: caller.GetEngineResolver(),
+		callMCP:       caller.GetMCPCaller(),
+	}
+}
+
+// run executes the pipeline according to its mode.
+func (r *pipelineRunner) run(pipeline *Pipeline) *PipelineResult {
+	start := time.Now()
+	result := &PipelineResult{
+		Pipeline: pipeline.Name,
+		Status:   statusSuccess,
+	}
+
+	if pipeline.Mode == modeDAG {
+		result.Stages = r.runDAG(pipeline)
+	} else {
+		result.Stages = r.runSequential(pipeline)
+	}
+
+	for i, stage := range pipeline.Stages {
+		if result.Stages[i].Status == statusFailed && !stage.ContinueOnError {
+			result.Status = statusFailed
+		}
+	}
+
+	result.Duration = time.Since(start).Seconds()
+	return result
+}
+
+// runSequential executes the stages in order. The first failing stage stops the pipeline and
+// the remaining stages are reported as skipped, unless the failing stage sets continueOnError.
+func (r *pipelineRunner) runSequential(pipeline *Pipeline) []StageResult {
+	results := make([]StageResult, 0, len(pipeline.Stages))
+	failed := false
+
+	for _, stage := range pipeline.Stages {
+		if failed {
+			results = append(results, skippedStage(stage))
+			continue
+		}
+
+		stageResult := r.runStage(stage)
+		results = append(results, stageResult)
+		failed = blocksPipeline(stage, stageResult)
+	}
+
+	return results
+}
+
+// runDAG executes each stage once all the stages it depends on have completed, running ready
+// stages concurrently up to the pipeline concurrency. When a stage fails, the stages depending
+// on it (directly or not) are reported as skipped, unless the failing stage sets continueOnError.
+// Independent stages keep running. Results are returned in definition order.
+func (r *pipelineRunner) runDAG(pipeline *Pipeline) []StageResult {
+	stages := pipeline.Stages
+	results := make([]StageResult, len(stages))
+
+	index := make(map[string]int, len(stages))
+	for i, stage := range stages {
+		index[stage.Name] = i
+	}
+
+	// pending counts the dependencies of each stage that have not completed yet
+	pending := make([]int, len(stages))
+	dependents := make([][]int, len(stages))
+	var ready []int
+	for i, stage := range stages {
+		pending[i] = len(stage.DependsOn)
+		for _, dep := range stage.DependsOn {
+			dependents[index[dep]] = append(dependents[index[dep]], i)
+		}
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	completed := 0
+	skipped := make([]bool, len(stages))
+	var skip func(i int)
+	skip = func(i int) {
+		if skipped[i] {
+			return
+		}
+		skipped[i] = true
+		results[i] = skippedStage(stages[i])
+		completed++
+		for _, dependent := range dependents[i] {
+			skip(dependent)
+		}
+	}
+
+	done := make(chan int)
+	running := 0
+	for completed < len(stages) {
+		// Start ready stages up to the concurrency limit
+		for len(ready) > 0 && running < pipeline.Concurrency {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				results[i] = r.runStage(stages[i])
+				done <- i
+			}()
+		}
+
+		i := <-done
+		running--
+		completed++
+
+		if blocksPipeline(stages[i], results[i]) {
+			for _, dependent := range dependents[i] {
+				skip(dependent)
+			}
+			continue
+		}
+		for _, dependent := range dependents[i] {
+			pending[dependent]--
+			if pending[dependent] == 0 && !skipped[dependent] {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	return results
+}
+
+// skippedStage returns the result of a stage that was not run.
+func skippedStage(stage Stage) StageResult {
+	log.Printf("ci-orchestrator: skipping stage %s", stage.Name)
+	return StageResult{Name: stage.Name, Engine: stage.Engine, Status: statusSkipped}
+}
+
+// blocksPipeline reports whether the stage result stops the stages that come after it:
+// the stage failed and does not set continueOnError.
+func blocksPipeline(stage Stage, result StageResult) bool {
+	if result.Status != statusFailed {
+		return false
+	}
+	if stage.ContinueOnError {
+		log.Printf("ci-orchestrator: stage %s failed, continuing: %s", stage.Name, result.Error)
+		return false
+	}
+	log.Printf("ci-orchestrator: stage %s failed: %s", stage.Name, result.Error)
+	return true
+}
+
+// runStage resolves the stage's engine and calls its tool.
+func (r *pipelineRunner) runStage(stage Stage) StageResult {
+	log.Printf("ci-orchestrator: running stage %s (%s %s)", stage.Name, stage.Engine, stage.Tool)
+	start := time.Now()
+	result := StageResult{Name: stage.Name, Engine: stage.Engine}
+
+	output, err := r.callStage(stage)
+	result.Duration = time.Since(start).Seconds()
+	if err != nil {
+		result.Status = statusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = statusSuccess
+	result.Output = output
+	return result
+}
+
+// callStage resolves the stage's engine and calls its tool with the stage input.
+func (r *pipelineRunner) callStage(stage Stage) (any, error) {
+	command, args, err := r.resolveEngine(stage.Engine)
+	if err != nil {
+		return nil, fmt.Errorf("engine resolution failed: %w", err)
+	}
+
+	output, err := r.callMCP(command, args, stage.Tool, stage.Input)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", stage.Tool, err)
+	}
+
+	return output, nil
+}
+
+// runPipelineFile loads the pipeline definition at path and runs it.
+func (r *pipelineRunner) runPipelineFile(path string) (*PipelineResult, error) {
+	pipeline, err := loadPipeline(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.run(pipeline), nil
+}
+
+// summary returns a one-line description of the pipeline result.
+func (p *PipelineResult) summary() string {
+	var succeeded, failed, skipped int
+	for _, stage := range p.Stages {
+		switch stage.Status {
+		case statusSuccess:
+			succeeded++
+		case statusFailed:
+			failed++
+		case statusSkipped:
+			skipped++
+		}
+	}
+
+	return fmt.Sprintf("pipeline %s %s in %.2fs: %d succeeded, %d failed, %d skipped",
+		p.Pipeline, p.Status, p.Duration, succeeded, failed, skipped)
+}
@@ -0,0 +1,489 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// engineCall records a single MCP call made by the pipeline runner.
+type engineCall struct {
+	command string
+	tool    string
+	input   map[string]any
+}
+
+// fakeEngines returns a pipeline runner whose engines are in-process fakes.
+// Engine "go://<name>" resolves to command <name>; builds fail for commands listed in failing.
+func fakeEngines(failing ...string) (*pipelineRunner, *[]engineCall) {
+	var mu sync.Mutex
+	var calls []engineCall
+	runner := &pipelineRunner{
+		resolveEngine: func(engineURI string) (string, []string, error) {
+			name, ok := strings.CutPrefix(engineURI, "go://")
+			if !ok {
+				return "", nil, fmt.Errorf("unsupported engine protocol: %s", engineURI)
+			}
+			return name, nil, nil
+		},
+		callMCP: func(command string, _ []string, toolName string, params interface{}) (interface{}, error) {
+			input := params.(map[string]any)
+			mu.Lock()
+			calls = append(calls, engineCall{command: command, tool: toolName, input: input})
+			mu.Unlock()
+			for _, name := range failing {
+				if command == name {
+					return nil, errors.New("tool call failed: boom")
+				}
+			}
+			return map[string]any{"name": input["name"], "type": "binary"}, nil
+		},
+	}
+	return runner, &calls
+}
+
+// writePipeline writes a pipeline definition to a temporary file and returns its path.
+func writePipeline(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write pipeline: %v", err)
+	}
+	return path
+}
+
+func TestRunPipelineFile_TwoBuilderStages(t *testing.T) {
+	path := writePipeline(t, `
+name: ci
+stages:
+  - name: build-api
+    engine: go://builder-a
+    input:
+      name: api
+  - name: build-cli
+    engine: go://builder-b
+    tool: build
+    input:
+      name: cli
+`)
+	runner, calls := fakeEngines()
+
+	result, err := runner.runPipelineFile(path)
+	if err != nil {
+		t.Fatalf("runPipelineFile() unexpected error: %v", err)
+	}
+
+	if result.Pipeline != "ci" || result.Status != statusSuccess {
+		t.Fatalf("result = %s/%s, want ci/success", result.Pipeline, result.Status)
+	}
+	wantCalls := []engineCall{
+		{command: "builder-a", tool: "build", input: map[string]any{"name": "api"}},
+		{command: "builder-b", tool: "build", input: map[string]any{"name": "cli"}},
+	}
+	if !reflect.DeepEqual(*calls, wantCalls) {
+		t.Errorf("calls = %+v, want %+v", *calls, wantCalls)
+	}
+
+	if len(result.Stages) != 2 {
+		t.Fatalf("got %d stage results, want 2", len(result.Stages))
+	}
+	for i, name := range []string{"api", "cli"} {
+		stage := result.Stages[i]
+		if stage.Status != statusSuccess {
+			t.Errorf("stage %s status = %q, want success", stage.Name, stage.Status)
+		}
+		output, ok := stage.Output.(map[string]any)
+		if !ok || output["name"] != name {
+			t.Errorf("stage %s output = %v, want artifact %q", stage.Name, stage.Output, name)
+		}
+	}
+}
+
+func TestRun_FailFast(t *testing.T) {
+	pipeline := &Pipeline{Name: "ci", Stages: []Stage{
+		{Name: "first", Engine: "go://broken", Tool: "build", Input: map[string]any{}},
+		{Name: "second", Engine: "go://builder", Tool: "build", Input: map[string]any{}},
+	}}
+	runner, calls := fakeEngines("broken")
+
+	result := runner.run(pipeline)
+
+	if result.Status != statusFailed {
+		t.Fatalf("status = %q, want failed", result.Status)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("made %d engine calls, want 1 (later stages must not run)", len(*calls))
+	}
+	if got := result.Stages[0]; got.Status != statusFailed || !strings.Contains(got.Error, "boom") {
+		t.Errorf("first stage = %+v, want failed with the engine error", got)
+	}
+	if got := result.Stages[1].Status; got != statusSkipped {
+		t.Errorf("second stage status = %q, want skipped", got)
+	}
+}
+
+func TestRun_ContinueOnError(t *testing.T) {
+	pipeline := &Pipeline{Name: "ci", Stages: []Stage{
+		{Name: "optional", Engine: "go://broken", Tool: "build", Input: map[string]any{}, ContinueOnError: true},
+		{Name: "required", Engine: "go://builder", Tool: "build", Input: map[string]any{}},
+	}}
+	runner, calls := fakeEngines("broken")
+
+	result := runner.run(pipeline)
+
+	if result.Status != statusSuccess {
+		t.Fatalf("status = %q, want success", result.Status)
+	}
+	if len(*calls) != 2 {
+		t.Errorf("made %d engine calls, want 2", len(*calls))
+	}
+	if got := result.Stages[0].Status; got != statusFailed {
+		t.Errorf("optional stage status = %q, want failed", got)
+	}
+	if got := result.Stages[1].Status; got != statusSuccess {
+		t.Errorf("required stage status = %q, want success", got)
+	}
+	if got := result.summary(); !strings.Contains(got, "1 succeeded, 1 failed, 0 skipped") {
+		t.Errorf("summary() = %q", got)
+	}
+}
+
+func TestRun_EngineResolutionFailure(t *testing.T) {
+	pipeline := &Pipeline{Name: "ci", Stages: []Stage{
+		{Name: "build", Engine: "alias://my-builder", Tool: "build", Input: map[string]any{}},
+	}}
+	runner, _ := fakeEngines()
+
+	result := runner.run(pipeline)
+
+	if result.Status != statusFailed {
+		t.Fatalf("status = %q, want failed", result.Status)
+	}
+	if !strings.Contains(result.Stages[0].Error, "engine resolution failed") {
+		t.Errorf("error = %q, want an engine resolution failure", result.Stages[0].Error)
+	}
+}
+
+func TestLoadPipeline_Defaults(t *testing.T) {
+	path := writePipeline(t, `
+name: ci
+stages:
+  - name: build
+    engine: go://generic-builder
+`)
+
+	pipeline, err := loadPipeline(path)
+	if err != nil {
+		t.Fatalf("loadPipeline() unexpected error: %v", err)
+	}
+
+	stage := pipeline.Stages[0]
+	if stage.Tool != defaultStageTool {
+		t.Errorf("tool = %q, want %q", stage.Tool, defaultStageTool)
+	}
+	if stage.Input == nil {
+		t.Error("input = nil, want an empty map")
+	}
+}
+
+func TestLoadPipeline_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "no stages", content: "name: ci\n", wantErr: "pipeline has no stages"},
+		{
+			name:    "missing stage name",
+			content: "stages:\n  - engine: go://generic-builder\n",
+			wantErr: "stages[0]: name is required",
+		},
+		{
+			name:    "missing engine",
+			content: "stages:\n  - name: build\n",
+			wantErr: `stage "build": engine is required`,
+		},
+		{
+			name:    "duplicate stage",
+			content: "stages:\n  - name: build\n    engine: go://a\n  - name: build\n    engine: go://b\n",
+			wantErr: `duplicate stage name "build"`,
+		},
+		{
+			name:    "unknown field",
+			content: "stages:\n  - name: build\n    engine: go://a\n    continueOnErrors: true\n",
+			wantErr: "failed to parse pipeline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadPipeline(writePipeline(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadPipeline() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPipeline_MissingFile(t *testing.T) {
+	_, err := loadPipeline(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "failed to read pipeline") {
+		t.Errorf("loadPipeline() error = %v, want a read error", err)
+	}
+}
+
+func TestLoadPipeline_DetectsCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "three stages",
+			content: `
+mode: dag
+stages:
+  - {name: a, engine: go://x, dependsOn: [b]}
+  - {name: b, engine: go://x, dependsOn: [c]}
+  - {name: c, engine: go://x, dependsOn: [a]}
+`,
+			wantErr: "dependency cycle: a -> b -> c -> a",
+		},
+		{
+			name: "self dependency",
+			content: `
+mode: dag
+stages:
+  - {name: a, engine: go://x}
+  - {name: b, engine: go://x, dependsOn: [a, b]}
+`,
+			wantErr: "dependency cycle: b -> b",
+		},
+		{
+			name: "cycle below an acyclic root",
+			content: `
+mode: dag
+stages:
+  - {name: root, engine: go://x, dependsOn: [left]}
+  - {name: left, engine: go://x, dependsOn: [right]}
+  - {name: right, engine: go://x, dependsOn: [left]}
+`,
+			wantErr: "dependency cycle: left -> right -> left",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadPipeline(writePipeline(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadPipeline() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPipeline_InvalidDAG(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown mode",
+			content: "mode: parallel\nstages:\n  - {name: a, engine: go://x}\n",
+			wantErr: `unknown mode "parallel"`,
+		},
+		{
+			name:    "dependsOn in sequential mode",
+			content: "stages:\n  - {name: a, engine: go://x}\n  - {name: b, engine: go://x, dependsOn: [a]}\n",
+			wantErr: `stage "b": dependsOn requires mode "dag"`,
+		},
+		{
+			name:    "unknown dependency",
+			content: "mode: dag\nstages:\n  - {name: a, engine: go://x, dependsOn: [missing]}\n",
+			wantErr: `stage "a" depends on unknown stage "missing"`,
+		},
+		{
+			name:    "negative concurrency",
+			content: "mode: dag\nconcurrency: -1\nstages:\n  - {name: a, engine: go://x}\n",
+			wantErr: "concurrency must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadPipeline(writePipeline(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadPipeline() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// span records when a fake stage ran.
+type span struct {
+	start, end time.Time
+}
+
+// timedEngines returns a pipeline runner whose engines sleep for d and record when each
+// command ran. Builds fail for commands listed in failing.
+func timedEngines(d time.Duration, failing ...string) (*pipelineRunner, func() map[string]span) {
+	var mu sync.Mutex
+	spans := make(map[string]span)
+	runner, _ := fakeEngines(failing...)
+	call := runner.callMCP
+	runner.callMCP = func(command string, args []string, toolName string, params interface{}) (interface{}, error) {
+		start := time.Now()
+		time.Sleep(d)
+		out, err := call(command, args, toolName, params)
+
+		mu.Lock()
+		spans[command] = span{start: start, end: time.Now()}
+		mu.Unlock()
+		return out, err
+	}
+
+	return runner, func() map[string]span {
+		mu.Lock()
+		defer mu.Unlock()
+		return spans
+	}
+}
+
+func TestRun_DAGDiamond(t *testing.T) {
+	path := writePipeline(t, `
+name: diamond
+mode: dag
+concurrency: 2
+stages:
+  - {name: d, engine: go://d, dependsOn: [b, c]}
+  - {name: b, engine: go://b, dependsOn: [a]}
+  - {name: c, engine: go://c, dependsOn: [a]}
+  - {name: a, engine: go://a}
+`)
+	runner, spans := timedEngines(50 * time.Millisecond)
+
+	result, err := runner.runPipelineFile(path)
+	if err != nil {
+		t.Fatalf("runPipelineFile() unexpected error: %v", err)
+	}
+
+	if result.Status != statusSuccess {
+		t.Fatalf("status = %q, want success", result.Status)
+	}
+	// Results keep the definition order
+	for i, name := range []string{"d", "b", "c", "a"} {
+		if got := result.Stages[i]; got.Name != name || got.Status != statusSuccess {
+			t.Errorf("stages[%d] = %s/%s, want %s/success", i, got.Name, got.Status, name)
+		}
+	}
+
+	ran := spans()
+	if len(ran) != 4 {
+		t.Fatalf("%d stages ran, want 4", len(ran))
+	}
+	for _, name := range []string{"b", "c"} {
+		if ran[name].start.Before(ran["a"].end) {
+			t.Errorf("stage %s started before its dependency a completed", name)
+		}
+		if ran["d"].start.Before(ran[name].end) {
+			t.Errorf("stage d started before its dependency %s completed", name)
+		}
+	}
+	// b and c only depend on a, so they run concurrently
+	if !ran["b"].start.Before(ran["c"].end) || !ran["c"].start.Before(ran["b"].end) {
+		t.Errorf("stages b and c did not run concurrently: b=%v c=%v", ran["b"], ran["c"])
+	}
+}
+
+func TestRun_DAGConcurrencyLimit(t *testing.T) {
+	pipeline := &Pipeline{Name: "ci", Mode: modeDAG, Concurrency: 1, Stages: []Stage{
+		{Name: "a", Engine: "go://a", Tool: "build", Input: map[string]any{}},
+		{Name: "b", Engine: "go://b", Tool: "build", Input: map[string]any{}},
+		{Name: "c", Engine: "go://c", Tool: "build", Input: map[string]any{}},
+	}}
+	runner, spans := timedEngines(20 * time.Millisecond)
+
+	if result := runner.run(pipeline); result.Status != statusSuccess {
+		t.Fatalf("status = %q, want success", result.Status)
+	}
+
+	ran := spans()
+	for _, x := range []string{"a", "b", "c"} {
+		for _, y := range []string{"a", "b", "c"} {
+			if x != y && ran[x].start.Before(ran[y].start) && ran[y].start.Before(ran[x].end) {
+				t.Errorf("stages %s and %s overlapped with concurrency 1", x, y)
+			}
+		}
+	}
+}
+
+func TestRun_DAGFailedUpstreamSkipsDependents(t *testing.T) {
+	pipeline := &Pipeline{Name: "ci", Mode: modeDAG, Concurrency: 2, Stages: []Stage{
+		{Name: "build", Engine: "go://broken", Tool: "build", Input: map[string]any{}},
+		{Name: "test", Engine: "go://tester", Tool: "build", Input: map[string]any{}, DependsOn: []string{"build"}},
+		{Name: "publish", Engine: "go://publisher", Tool: "build", Input: map[string]any{}, DependsOn: []string{"test"}},
+		{Name: "lint", Engine: "go://linter", Tool: "build", Input: map[string]any{}},
+	}}
+	runner, calls := fakeEngines("broken")
+
+	result := runner.run(pipeline)
+
+	if result.Status != statusFailed {
+		t.Fatalf("status = %q, want failed", result.Status)
+	}
+	want := map[string]string{
+		"build":   statusFailed,
+		"test":    statusSkipped,
+		"publish": statusSkipped,
+		"lint":    statusSuccess,
+	}
+	for _, stage := range result.Stages {
+		if stage.Status != want[stage.Name] {
+			t.Errorf("stage %s status = %q, want %q", stage.Name, stage.Status, want[stage.Name])
+		}
+	}
+	if len(*calls) != 2 {
+		t.Errorf("made %d engine calls, want 2 (build and lint)", len(*calls))
+	}
+}
+
+func TestRun_DAGContinueOnErrorRunsDependents(t *testing.T) {
+	pipeline := &Pipeline{Name: "ci", Mode: modeDAG, Concurrency: 1, Stages: []Stage{
+		{Name: "docs", Engine: "go://broken", Tool: "build", Input: map[string]any{}, ContinueOnError: true},
+		{Name: "package", Engine: "go://packager", Tool: "build", Input: map[string]any{}, DependsOn: []string{"docs"}},
+	}}
+	runner, _ := fakeEngines("broken")
+
+	result := runner.run(pipeline)
+
+	if result.Status != statusSuccess {
+		t.Fatalf("status = %q, want success", result.Status)
+	}
+	if got := result.Stages[1].Status; got != statusSuccess {
+		t.Errorf("package stage status = %q, want success", got)
+	}
+}
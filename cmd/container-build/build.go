@@ -48,6 +48,8 @@ type Envs struct {
 	// KanikoCacheDir is the local directory to use for kaniko layer caching.
 	// Defaults to ~/.kaniko-cache
 	KanikoCacheDir string `env:"KANIKO_CACHE_DIR"          envDefault:"~/.kaniko-cache"`
+	// SecretArgs holds the resolved `--secret` values of spec.secrets (not read from the environment).
+	SecretArgs []string `env:"-"`
 }
 
 // ----------------------------------------------------- BUILD ------------------------------------------------------- //
@@ -58,8 +60,14 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 
 	// Note: spec contains typed fields like Dockerfile, Context, BuildArgs, Tags, Target, Push, Registry
 	// These can be used in future iterations to enhance build functionality
-	// For now, the build uses input.Src as the Dockerfile path and input.Spec for dependsOn parsing
-	_ = spec // TODO: Use spec fields for enhanced build configuration
+	// For now, the build uses input.Src as the Dockerfile path, input.Spec for dependsOn parsing,
+	// spec.Platforms/spec.Registry for multi-platform builds and spec.BuildArgs/spec.Secrets as build flags
+	var platforms []string
+	var registry string
+	if spec != nil {
+		platforms = spec.Platforms
+		registry = spec.Registry
+	}
 
 	// Parse environment variables (CONTAINER_BUILD_ENGINE is required)
 	envs := Envs{} //nolint:exhaustruct
@@ -72,6 +80,22 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 		return nil, err
 	}
 
+	// Validate target platforms
+	if err := validatePlatforms(envs.BuildEngine, platforms, registry); err != nil {
+		return nil, err
+	}
+
+	// Apply build args and secrets from the spec
+	if spec != nil {
+		envs.BuildArgs = append(envs.BuildArgs, specBuildArgs(spec.BuildArgs)...)
+
+		secretArgs, err := resolveSecretMounts(envs.BuildEngine, spec.Secrets, input.RootDir)
+		if err != nil {
+			return nil, err
+		}
+		envs.SecretArgs = secretArgs
+	}
+
 	// Resolve build context directory
 	// Prefer input.Context (set by forge CLI), fall back to CWD for backward compat
 	buildContextDir := input.Context
@@ -100,12 +124,17 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 
 	// Build the container (isMCPMode=true)
 	var store forge.ArtifactStore
-	if err := buildContainer(envs, buildSpec, version, "", &store, true, buildContextDir); err != nil {
+	location := fmt.Sprintf("%s:%s", input.Name, version)
+	if len(platforms) > 0 {
+		if err := buildContainerPlatforms(envs, buildSpec, platforms, registry, version, "", &store, true, buildContextDir); err != nil {
+			return nil, err
+		}
+		location = fmt.Sprintf("%s:%s", platformImageRef(input.Name, platforms, registry), version)
+	} else if err := buildContainer(envs, buildSpec, version, "", &store, true, buildContextDir); err != nil {
 		return nil, err
 	}
 
 	// Retrieve the artifact from store to get dependencies
-	location := fmt.Sprintf("%s:%s", input.Name, version)
 	artifact := engineframework.CreateCustomArtifact(
 		input.Name,
 		"container",
@@ -201,33 +230,18 @@ func buildContainerDocker(
 		wd,
 	)
 
-	// Add build args if provided
-	for _, buildArg := range envs.BuildArgs {
-		cmd.Args = append(cmd.Args, "--build-arg", buildArg)
-	}
+	// Add build args and secrets if provided
+	cmd.Args = appendBuildFlags(cmd.Args, envs)
+	cmd.Env = buildEnv(envs)
 
 	if err := runCmd(cmd, isMCPMode); err != nil {
 		return flaterrors.Join(err, errBuildingContainer)
 	}
 
 	// Detect dependencies after successful build
-	var dependencies []forge.ArtifactDependency
-	var detectorEngines []string
-	var dependsOnSpec []forge.DependsOnSpec
-
-	dependsOn, err := forge.ParseDependsOn(spec.Spec)
+	dependencies, detectorEngines, dependsOnSpec, err := detectBuildDependencies(out, spec)
 	if err != nil {
-		// ParseDependsOn error: log and skip detection (don't fail build)
-		_, _ = fmt.Fprintf(out, "Warning: failed to parse dependsOn: %v\n", err)
-		_, _ = fmt.Fprintf(out, "   Skipping dependency detection\n")
-	} else if len(dependsOn) > 0 {
-		// Call dependency detection
-		dependencies, detectorEngines, err = detectDependenciesFromSpec(dependsOn, spec)
-		if err != nil {
-			// Detection failed - FAIL the build
-			return flaterrors.Join(err, errBuildingContainer)
-		}
-		dependsOnSpec = dependsOn
+		return err
 	}
 
 	// Add to artifact store
@@ -267,33 +281,18 @@ func buildContainerPodman(
 		wd,
 	)
 
-	// Add build args if provided
-	for _, buildArg := range envs.BuildArgs {
-		cmd.Args = append(cmd.Args, "--build-arg", buildArg)
-	}
+	// Add build args and secrets if provided
+	cmd.Args = appendBuildFlags(cmd.Args, envs)
+	cmd.Env = buildEnv(envs)
 
 	if err := runCmd(cmd, isMCPMode); err != nil {
 		return flaterrors.Join(err, errBuildingContainer)
 	}
 
 	// Detect dependencies after successful build
-	var dependencies []forge.ArtifactDependency
-	var detectorEngines []string
-	var dependsOnSpec []forge.DependsOnSpec
-
-	dependsOn, err := forge.ParseDependsOn(spec.Spec)
+	dependencies, detectorEngines, dependsOnSpec, err := detectBuildDependencies(out, spec)
 	if err != nil {
-		// ParseDependsOn error: log and skip detection (don't fail build)
-		_, _ = fmt.Fprintf(out, "Warning: failed to parse dependsOn: %v\n", err)
-		_, _ = fmt.Fprintf(out, "   Skipping dependency detection\n")
-	} else if len(dependsOn) > 0 {
-		// Call dependency detection
-		dependencies, detectorEngines, err = detectDependenciesFromSpec(dependsOn, spec)
-		if err != nil {
-			// Detection failed - FAIL the build
-			return flaterrors.Join(err, errBuildingContainer)
-		}
-		dependsOnSpec = dependsOn
+		return err
 	}
 
 	// Add to artifact store
@@ -387,23 +386,9 @@ func buildContainerKaniko(
 	}
 
 	// Detect dependencies after successful build
-	var dependencies []forge.ArtifactDependency
-	var detectorEngines []string
-	var dependsOnSpec []forge.DependsOnSpec
-
-	dependsOn, err := forge.ParseDependsOn(spec.Spec)
+	dependencies, detectorEngines, dependsOnSpec, err := detectBuildDependencies(out, spec)
 	if err != nil {
-		// ParseDependsOn error: log and skip detection (don't fail build)
-		_, _ = fmt.Fprintf(out, "Warning: failed to parse dependsOn: %v\n", err)
-		_, _ = fmt.Fprintf(out, "   Skipping dependency detection\n")
-	} else if len(dependsOn) > 0 {
-		// Call dependency detection
-		dependencies, detectorEngines, err = detectDependenciesFromSpec(dependsOn, spec)
-		if err != nil {
-			// Detection failed - FAIL the build
-			return flaterrors.Join(err, errBuildingContainer)
-		}
-		dependsOnSpec = dependsOn
+		return err
 	}
 
 	// Add to artifact store
@@ -422,6 +407,33 @@ func tagImage(containerEngine, imageID, tag string, isMCPMode bool) error {
 	return runCmd(cmd, isMCPMode)
 }
 
+// detectBuildDependencies runs the dependency detectors configured in the dependsOn spec
+// of a successfully built container.
+// A dependsOn spec that cannot be parsed is logged and skipped, while a failing detector fails the build.
+func detectBuildDependencies(
+	out io.Writer,
+	spec forge.BuildSpec,
+) ([]forge.ArtifactDependency, []string, []forge.DependsOnSpec, error) {
+	dependsOn, err := forge.ParseDependsOn(spec.Spec)
+	if err != nil {
+		// ParseDependsOn error: log and skip detection (don't fail build)
+		_, _ = fmt.Fprintf(out, "Warning: failed to parse dependsOn: %v\n", err)
+		_, _ = fmt.Fprintf(out, "   Skipping dependency detection\n")
+		return nil, nil, nil, nil
+	}
+	if len(dependsOn) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	// Call dependency detection
+	dependencies, detectorEngines, err := detectDependenciesFromSpec(dependsOn, spec)
+	if err != nil {
+		// Detection failed - FAIL the build
+		return nil, nil, nil, flaterrors.Join(err, errBuildingContainer)
+	}
+	return dependencies, detectorEngines, dependsOn, nil
+}
+
 // addArtifactToStore adds a container artifact to the store.
 func addArtifactToStore(
 	store *forge.ArtifactStore,
@@ -514,6 +526,8 @@ func getImageIDFromTar(containerEngine, tarPath string) (string, error) {
 
 // runCmd runs a command, redirecting output to stderr in MCP mode to avoid corrupting JSON-RPC.
 func runCmd(cmd *exec.Cmd, isMCPMode bool) error {
+	log.Printf("Running: %s", redactCommand(cmd.Args))
+
 	if isMCPMode {
 		// MCP mode: redirect all output to stderr (safe for JSON-RPC on stdout)
 		cmd.Stdout = os.Stderr
@@ -0,0 +1,195 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/flaterrors"
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// ----------------------------------------------------- PLATFORMS -------------------------------------------------- //
+
+var errInvalidPlatforms = errors.New("invalid platforms")
+
+// validatePlatforms validates the target platforms requested in the spec.
+// A multi-platform image cannot be loaded into the local daemon, so it is pushed as a
+// manifest list and requires a registry.
+func validatePlatforms(engine string, platforms []string, registry string) error {
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	for i, platform := range platforms {
+		if strings.TrimSpace(platform) == "" {
+			return fmt.Errorf("%w: platforms[%d] is empty", errInvalidPlatforms, i)
+		}
+	}
+
+	if engine == "kaniko" {
+		return fmt.Errorf("%w: platforms are not supported by the kaniko build engine (use docker or podman)",
+			errInvalidPlatforms)
+	}
+
+	if len(platforms) > 1 && registry == "" {
+		return fmt.Errorf("%w: registry is required to push a multi-platform image (platforms: %s)",
+			errInvalidPlatforms, strings.Join(platforms, ","))
+	}
+
+	return nil
+}
+
+// isMultiPlatform reports whether the platforms require a manifest list.
+func isMultiPlatform(platforms []string) bool {
+	return len(platforms) > 1
+}
+
+// platformImageRef returns the image reference (without tag) of a platform build.
+// Multi-platform images are pushed to the registry; single-platform images stay local.
+func platformImageRef(name string, platforms []string, registry string) string {
+	if !isMultiPlatform(platforms) {
+		return name
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + name
+}
+
+// dockerBuildxArgs returns the arguments of the `docker buildx build` command for the platforms.
+// A single-platform image is loaded into the docker daemon, while a multi-platform image is
+// pushed as a manifest list.
+func dockerBuildxArgs(
+	envs Envs,
+	spec forge.BuildSpec,
+	platforms []string,
+	imageRef, version, contextDir string,
+) []string {
+	args := []string{
+		"buildx", "build",
+		"--platform", strings.Join(platforms, ","),
+		"-f", spec.Src,
+		"-t", fmt.Sprintf("%s:%s", imageRef, version),
+		"-t", fmt.Sprintf("%s:latest", imageRef),
+	}
+
+	if isMultiPlatform(platforms) {
+		args = append(args, "--push")
+	} else {
+		args = append(args, "--load")
+	}
+
+	args = appendBuildFlags(args, envs)
+
+	return append(args, contextDir)
+}
+
+// podmanPlatformBuildArgs returns the arguments of the `podman build` command for the platforms.
+// A multi-platform build is collected into a local manifest list named after the versioned image.
+func podmanPlatformBuildArgs(
+	envs Envs,
+	spec forge.BuildSpec,
+	platforms []string,
+	imageRef, version, contextDir string,
+) []string {
+	args := []string{
+		"build",
+		"--platform", strings.Join(platforms, ","),
+		"-f", spec.Src,
+	}
+
+	if isMultiPlatform(platforms) {
+		args = append(args, "--manifest", fmt.Sprintf("%s:%s", imageRef, version))
+	} else {
+		args = append(args,
+			"-t", fmt.Sprintf("%s:%s", imageRef, version),
+			"-t", fmt.Sprintf("%s:latest", imageRef),
+		)
+	}
+
+	args = appendBuildFlags(args, envs)
+
+	return append(args, contextDir)
+}
+
+// podmanManifestPushArgs returns the arguments of the `podman manifest push` commands that push
+// the manifest list of a multi-platform build with its version and latest tags.
+func podmanManifestPushArgs(imageRef, version string) [][]string {
+	manifest := fmt.Sprintf("%s:%s", imageRef, version)
+	return [][]string{
+		{"manifest", "push", "--all", manifest, "docker://" + manifest},
+		{"manifest", "push", "--all", manifest, fmt.Sprintf("docker://%s:latest", imageRef)},
+	}
+}
+
+// buildContainerPlatforms builds a container for the given platforms using docker buildx or podman.
+func buildContainerPlatforms(
+	envs Envs,
+	spec forge.BuildSpec,
+	platforms []string,
+	registry, version, timestamp string,
+	store *forge.ArtifactStore,
+	isMCPMode bool,
+	contextDir string,
+) error {
+	out := os.Stdout
+	if isMCPMode {
+		out = os.Stderr
+	}
+
+	printBuildStart(out, spec.Name)
+	_, _ = fmt.Fprintf(out, "Target platforms: %s\n", strings.Join(platforms, ","))
+
+	imageRef := platformImageRef(spec.Name, platforms, registry)
+
+	var cmds []*exec.Cmd
+	switch envs.BuildEngine {
+	case "docker":
+		cmds = append(cmds, exec.Command("docker", dockerBuildxArgs(envs, spec, platforms, imageRef, version, contextDir)...))
+	case "podman":
+		cmds = append(cmds, exec.Command("podman", podmanPlatformBuildArgs(envs, spec, platforms, imageRef, version, contextDir)...))
+		if isMultiPlatform(platforms) {
+			for _, args := range podmanManifestPushArgs(imageRef, version) {
+				cmds = append(cmds, exec.Command("podman", args...))
+			}
+		}
+	default:
+		return flaterrors.Join(
+			fmt.Errorf("%w: platforms are not supported by the %s build engine", errInvalidPlatforms, envs.BuildEngine),
+			errBuildingContainer,
+		)
+	}
+
+	for _, cmd := range cmds {
+		cmd.Env = buildEnv(envs)
+		if err := runCmd(cmd, isMCPMode); err != nil {
+			return flaterrors.Join(err, errBuildingContainer)
+		}
+	}
+
+	// Detect dependencies after successful build
+	dependencies, detectorEngines, dependsOnSpec, err := detectBuildDependencies(out, spec)
+	if err != nil {
+		return err
+	}
+
+	// Add to artifact store
+	addArtifactToStore(store, spec.Name, version, timestamp, dependencies, detectorEngines, dependsOnSpec)
+
+	printBuildSuccess(out, spec.Name, version)
+	return nil
+}
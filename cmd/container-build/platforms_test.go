@@ -0,0 +1,153 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+func TestValidatePlatforms(t *testing.T) {
+	tests := []struct {
+		name      string
+		engine    string
+		platforms []string
+		registry  string
+		wantErr   bool
+	}{
+		{"no platforms", "kaniko", nil, "", false},
+		{"single platform without registry", "docker", []string{"linux/arm64"}, "", false},
+		{"multi platform with registry", "docker", []string{"linux/amd64", "linux/arm64"}, "ghcr.io/acme", false},
+		{"multi platform podman with registry", "podman", []string{"linux/amd64", "linux/arm64"}, "ghcr.io/acme", false},
+		{"multi platform without registry", "docker", []string{"linux/amd64", "linux/arm64"}, "", true},
+		{"empty platform", "docker", []string{"linux/amd64", " "}, "ghcr.io/acme", true},
+		{"kaniko", "kaniko", []string{"linux/amd64"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePlatforms(tt.engine, tt.platforms, tt.registry)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePlatforms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errInvalidPlatforms) {
+				t.Errorf("validatePlatforms() error = %v, want errInvalidPlatforms", err)
+			}
+		})
+	}
+}
+
+func TestDockerBuildxArgs(t *testing.T) {
+	envs := Envs{BuildEngine: "docker", BuildArgs: []string{"GO_VERSION=1.25"}}
+	spec := forge.BuildSpec{Name: "my-app", Src: "Containerfile"}
+
+	tests := []struct {
+		name      string
+		platforms []string
+		registry  string
+		want      []string
+	}{
+		{
+			name:      "single platform loads into daemon",
+			platforms: []string{"linux/arm64"},
+			want: []string{
+				"buildx", "build",
+				"--platform", "linux/arm64",
+				"-f", "Containerfile",
+				"-t", "my-app:v1",
+				"-t", "my-app:latest",
+				"--load",
+				"--build-arg", "GO_VERSION=1.25",
+				"/src",
+			},
+		},
+		{
+			name:      "multi platform pushes manifest list",
+			platforms: []string{"linux/amd64", "linux/arm64"},
+			registry:  "ghcr.io/acme/",
+			want: []string{
+				"buildx", "build",
+				"--platform", "linux/amd64,linux/arm64",
+				"-f", "Containerfile",
+				"-t", "ghcr.io/acme/my-app:v1",
+				"-t", "ghcr.io/acme/my-app:latest",
+				"--push",
+				"--build-arg", "GO_VERSION=1.25",
+				"/src",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imageRef := platformImageRef(spec.Name, tt.platforms, tt.registry)
+			got := dockerBuildxArgs(envs, spec, tt.platforms, imageRef, "v1", "/src")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dockerBuildxArgs() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodmanPlatformArgs(t *testing.T) {
+	envs := Envs{BuildEngine: "podman"}
+	spec := forge.BuildSpec{Name: "my-app", Src: "Containerfile"}
+
+	t.Run("single platform tags locally", func(t *testing.T) {
+		platforms := []string{"linux/arm64"}
+		got := podmanPlatformBuildArgs(envs, spec, platforms, platformImageRef(spec.Name, platforms, ""), "v1", "/src")
+		want := []string{
+			"build",
+			"--platform", "linux/arm64",
+			"-f", "Containerfile",
+			"-t", "my-app:v1",
+			"-t", "my-app:latest",
+			"/src",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("podmanPlatformBuildArgs() =\n%v\nwant\n%v", got, want)
+		}
+	})
+
+	t.Run("multi platform builds and pushes a manifest", func(t *testing.T) {
+		platforms := []string{"linux/amd64", "linux/arm64"}
+		imageRef := platformImageRef(spec.Name, platforms, "ghcr.io/acme")
+
+		got := podmanPlatformBuildArgs(envs, spec, platforms, imageRef, "v1", "/src")
+		want := []string{
+			"build",
+			"--platform", "linux/amd64,linux/arm64",
+			"-f", "Containerfile",
+			"--manifest", "ghcr.io/acme/my-app:v1",
+			"/src",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("podmanPlatformBuildArgs() =\n%v\nwant\n%v", got, want)
+		}
+
+		wantPush := [][]string{
+			{"manifest", "push", "--all", "ghcr.io/acme/my-app:v1", "docker://ghcr.io/acme/my-app:v1"},
+			{"manifest", "push", "--all", "ghcr.io/acme/my-app:v1", "docker://ghcr.io/acme/my-app:latest"},
+		}
+		if gotPush := podmanManifestPushArgs(imageRef, "v1"); !reflect.DeepEqual(gotPush, wantPush) {
+			t.Errorf("podmanManifestPushArgs() =\n%v\nwant\n%v", gotPush, wantPush)
+		}
+	})
+}
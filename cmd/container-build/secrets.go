@@ -0,0 +1,140 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------- BUILD FLAGS ------------------------------------------------ //
+
+var errInvalidSecret = errors.New("invalid secret")
+
+// specBuildArgs converts spec.buildArgs to KEY=VALUE build arguments, sorted by key.
+func specBuildArgs(buildArgs map[string]string) []string {
+	keys := make([]string, 0, len(buildArgs))
+	for key := range buildArgs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("%s=%s", key, buildArgs[key]))
+	}
+	return args
+}
+
+// resolveSecretMounts validates spec.secrets and returns the matching `--secret` values.
+// Secret sources are resolved relative to rootDir (or the working directory if rootDir is empty)
+// and must be existing files, so that a missing secret fails before the builder is invoked.
+func resolveSecretMounts(engine string, secrets []SecretMount, rootDir string) ([]string, error) {
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+
+	if engine == "kaniko" {
+		return nil, fmt.Errorf("%w: secrets are not supported by the kaniko build engine (use docker or podman)",
+			errInvalidSecret)
+	}
+
+	args := make([]string, 0, len(secrets))
+	seen := make(map[string]bool, len(secrets))
+	for i, secret := range secrets {
+		if secret.Id == "" || secret.Src == "" {
+			return nil, fmt.Errorf("%w: secrets[%d] requires both id and src", errInvalidSecret, i)
+		}
+		if strings.ContainsAny(secret.Id, ",=") {
+			return nil, fmt.Errorf("%w: secret id %q must not contain ',' or '='", errInvalidSecret, secret.Id)
+		}
+		if seen[secret.Id] {
+			return nil, fmt.Errorf("%w: duplicate secret id %q", errInvalidSecret, secret.Id)
+		}
+		seen[secret.Id] = true
+
+		src := secret.Src
+		if !filepath.IsAbs(src) && rootDir != "" {
+			src = filepath.Join(rootDir, src)
+		}
+		src, err := filepath.Abs(src)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to resolve source of secret %q: %w", errInvalidSecret, secret.Id, err)
+		}
+
+		info, err := os.Stat(src)
+		if err != nil {
+			return nil, fmt.Errorf("%w: source of secret %q: %w", errInvalidSecret, secret.Id, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%w: source of secret %q is not a regular file: %s", errInvalidSecret, secret.Id, src)
+		}
+
+		args = append(args, fmt.Sprintf("id=%s,src=%s", secret.Id, src))
+	}
+
+	return args, nil
+}
+
+// appendBuildFlags appends the build arguments and secrets of envs to the builder arguments.
+func appendBuildFlags(args []string, envs Envs) []string {
+	for _, buildArg := range envs.BuildArgs {
+		args = append(args, "--build-arg", buildArg)
+	}
+	for _, secret := range envs.SecretArgs {
+		args = append(args, "--secret", secret)
+	}
+	return args
+}
+
+// buildEnv returns the environment of the builder command.
+// Secret mounts require BuildKit, which is enabled explicitly for older docker versions.
+// A nil environment makes the command inherit the current process environment.
+func buildEnv(envs Envs) []string {
+	if len(envs.SecretArgs) == 0 {
+		return nil
+	}
+	return append(os.Environ(), "DOCKER_BUILDKIT=1")
+}
+
+// redactCommand formats a command line for logging.
+// Only the id of `--secret` flags is kept, so that secret sources never appear in logs.
+func redactCommand(args []string) string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		switch {
+		case i > 0 && args[i-1] == "--secret":
+			redacted[i] = redactSecret(arg)
+		case strings.HasPrefix(arg, "--secret="):
+			redacted[i] = "--secret=" + redactSecret(strings.TrimPrefix(arg, "--secret="))
+		}
+	}
+	return strings.Join(redacted, " ")
+}
+
+// redactSecret keeps only the id of a `--secret` value.
+func redactSecret(value string) string {
+	for _, field := range strings.Split(value, ",") {
+		if id, ok := strings.CutPrefix(field, "id="); ok {
+			return "id=" + id + ",<redacted>"
+		}
+	}
+	return "<redacted>"
+}
@@ -0,0 +1,158 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+func TestSpecBuildArgs(t *testing.T) {
+	got := specBuildArgs(map[string]string{"VERSION": "1.0.0", "COMMIT": "abc123"})
+	want := []string{"COMMIT=abc123", "VERSION=1.0.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("specBuildArgs() = %v, want %v", got, want)
+	}
+
+	if got := specBuildArgs(nil); len(got) != 0 {
+		t.Errorf("specBuildArgs(nil) = %v, want empty", got)
+	}
+}
+
+func TestResolveSecretMounts(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rootDir, "secrets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tokenPath := filepath.Join(rootDir, "secrets", "token")
+	if err := os.WriteFile(tokenPath, []byte("s3cr3t"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("resolves sources relative to root dir", func(t *testing.T) {
+		got, err := resolveSecretMounts("docker", []SecretMount{
+			{Id: "token", Src: "secrets/token"},
+			{Id: "abs", Src: tokenPath},
+		}, rootDir)
+		if err != nil {
+			t.Fatalf("resolveSecretMounts() error = %v", err)
+		}
+		want := []string{"id=token,src=" + tokenPath, "id=abs,src=" + tokenPath}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("resolveSecretMounts() = %v, want %v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		engine  string
+		secrets []SecretMount
+	}{
+		{"missing file", "docker", []SecretMount{{Id: "token", Src: "secrets/missing"}}},
+		{"directory", "podman", []SecretMount{{Id: "token", Src: "secrets"}}},
+		{"missing id", "docker", []SecretMount{{Src: "secrets/token"}}},
+		{"invalid id", "docker", []SecretMount{{Id: "a,b", Src: "secrets/token"}}},
+		{"duplicate id", "docker", []SecretMount{{Id: "token", Src: "secrets/token"}, {Id: "token", Src: "secrets/token"}}},
+		{"kaniko", "kaniko", []SecretMount{{Id: "token", Src: "secrets/token"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveSecretMounts(tt.engine, tt.secrets, rootDir)
+			if !errors.Is(err, errInvalidSecret) {
+				t.Errorf("resolveSecretMounts() error = %v, want errInvalidSecret", err)
+			}
+		})
+	}
+}
+
+func TestBuildFlagsAssembly(t *testing.T) {
+	envs := Envs{
+		BuildEngine: "docker",
+		BuildArgs:   []string{"FROM_ENV=1", "VERSION=1.0.0"},
+		SecretArgs:  []string{"id=token,src=/secrets/token"},
+	}
+
+	got := appendBuildFlags([]string{"build"}, envs)
+	want := []string{
+		"build",
+		"--build-arg", "FROM_ENV=1",
+		"--build-arg", "VERSION=1.0.0",
+		"--secret", "id=token,src=/secrets/token",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("appendBuildFlags() = %v, want %v", got, want)
+	}
+
+	// Platform builds get the same flags before the context directory
+	spec := forge.BuildSpec{Name: "my-app", Src: "Containerfile"}
+	buildx := dockerBuildxArgs(envs, spec, []string{"linux/arm64"}, "my-app", "v1", "/src")
+	if tail := buildx[len(buildx)-7:]; !reflect.DeepEqual(tail, append(want[1:], "/src")) {
+		t.Errorf("dockerBuildxArgs() tail = %v", tail)
+	}
+
+	if env := buildEnv(envs); !slices.Contains(env, "DOCKER_BUILDKIT=1") {
+		t.Error("buildEnv() should enable BuildKit when secrets are mounted")
+	}
+	if env := buildEnv(Envs{}); env != nil {
+		t.Errorf("buildEnv() = %v, want nil without secrets", env)
+	}
+}
+
+func TestRunCmd_RedactsSecrets(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secretPath, []byte("s3cr3t"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	cmd := exec.Command("true",
+		"build",
+		"--build-arg", "VERSION=1.0.0",
+		"--secret", "id=token,src="+secretPath,
+		"--secret=id=other,src="+secretPath,
+		".",
+	)
+	if err := runCmd(cmd, true); err != nil {
+		t.Fatalf("runCmd() error = %v", err)
+	}
+
+	logged := buf.String()
+	for _, leaked := range []string{secretPath, "s3cr3t"} {
+		if strings.Contains(logged, leaked) {
+			t.Errorf("logged command leaks %q: %s", leaked, logged)
+		}
+	}
+	for _, kept := range []string{"VERSION=1.0.0", "--secret id=token,<redacted>", "--secret=id=other,<redacted>"} {
+		if !strings.Contains(logged, kept) {
+			t.Errorf("logged command should contain %q: %s", kept, logged)
+		}
+	}
+}
@@ -1,20 +1,28 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml
-// SourceChecksum: sha256:93abc4bf66872c4f411ab6dc112bfddb35fa4d2508725503e33e9c0d254e9d7d
+// SourceChecksum: sha256:bae6879d8472668d830f8c8709131d1c6de0180794d5aeae0b9e38d4d54036c7
 
 package main
 
 import (
+	"embed"
+
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for documentation tools.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "container-build",
 	LocalDir:     "cmd/container-build/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 // RegisterDocsMCPTools registers the docs-list and docs-get MCP tools.
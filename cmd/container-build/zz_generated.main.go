@@ -1,15 +1,18 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml + spec.openapi.yaml
-// SourceChecksum: sha256:93abc4bf66872c4f411ab6dc112bfddb35fa4d2508725503e33e9c0d254e9d7d
+// SourceChecksum: sha256:bae6879d8472668d830f8c8709131d1c6de0180794d5aeae0b9e38d4d54036c7
 
 package main
 
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
+	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
@@ -32,29 +35,55 @@ func main() {
 		Version:        Version,
 		CommitSHA:      CommitSHA,
 		BuildTimestamp: BuildTimestamp,
-		RunCLI:         nil, // Generated engines are MCP-only
+		RunCLI:         runBuilderCLI,
+		FailureHandler: printCLIFailure,
 		RunMCP:         runMCPServer,
 		DocsConfig:     docsConfig,
+		SetupMCP:       setupMCPServer,
 	})
 }
 
 // runMCPServer creates and runs the MCP server.
 func runMCPServer() error {
+	server, err := setupMCPServer()
+	if err != nil {
+		return err
+	}
+
+	if err := server.Run(enginecli.Context()); err != nil {
+		return fmt.Errorf("running MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// setupMCPServer creates the MCP server and registers all its tools.
+func setupMCPServer() (*mcpserver.Server, error) {
 	server, err := SetupMCPServer(Name, Version, Build)
 	if err != nil {
-		return fmt.Errorf("setting up MCP server: %w", err)
+		return nil, fmt.Errorf("setting up MCP server: %w", err)
 	}
 
 	// Register docs MCP tools (docs-list, docs-get)
 	if err := RegisterDocsMCPTools(server); err != nil {
-		return fmt.Errorf("registering docs MCP tools: %w", err)
+		return nil, fmt.Errorf("registering docs MCP tools: %w", err)
 	}
 
-	if err := server.Run(context.Background()); err != nil {
-		return fmt.Errorf("running MCP server: %w", err)
-	}
+	return server, nil
+}
 
-	return nil
+// runBuilderCLI runs the engine in CLI mode: "container-build build --input build-input.json".
+func runBuilderCLI() error {
+	return engineframework.RunBuilderCLI(context.Background(), engineframework.BuilderConfig{
+		Name:      Name,
+		Version:   Version,
+		BuildFunc: wrapBuildFunc(Build),
+	}, os.Args[1:], os.Stdout)
+}
+
+// printCLIFailure prints CLI mode errors to stderr.
+func printCLIFailure(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s: error: %s\n", Name, err.Error())
 }
 
 // Build is the build function that must be implemented by the engine author.
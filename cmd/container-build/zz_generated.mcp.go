@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:93abc4bf66872c4f411ab6dc112bfddb35fa4d2508725503e33e9c0d254e9d7d
+// SourceChecksum: sha256:bae6879d8472668d830f8c8709131d1c6de0180794d5aeae0b9e38d4d54036c7
 
 package main
 
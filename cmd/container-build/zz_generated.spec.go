@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:93abc4bf66872c4f411ab6dc112bfddb35fa4d2508725503e33e9c0d254e9d7d
+// SourceChecksum: sha256:bae6879d8472668d830f8c8709131d1c6de0180794d5aeae0b9e38d4d54036c7
 
 package main
 
@@ -8,25 +8,64 @@ import (
 	"fmt"
 )
 
+// SecretMount represents the SecretMount configuration.
+// A build secret read from a local file
+type SecretMount struct {
+	// Secret ID referenced by RUN --mount=type=secret,id=<id>
+	Id string `json:"id"`
+	// Path to the file containing the secret, relative to the repository root
+	Src string `json:"src"`
+}
+
 // Spec represents the Spec configuration.
 // Configuration for container-build engine
 type Spec struct {
-	// Build arguments (optional)
+	// Build arguments passed as --build-arg KEY=VALUE (optional)
 	BuildArgs map[string]string `json:"buildArgs,omitempty"`
 	// Build context path (optional)
 	Context string `json:"context,omitempty"`
 	// Path to Dockerfile (optional)
 	Dockerfile string `json:"dockerfile,omitempty"`
+	// Target platforms (optional, e.g. ["linux/amd64", "linux/arm64"]). Builds with docker buildx or podman --platform. When more than one platform is requested, the image is pushed as a manifest list to registry, which is then required.
+	Platforms []string `json:"platforms,omitempty"`
 	// Whether to push image (optional)
 	Push bool `json:"push,omitempty"`
 	// Registry URL (optional)
 	Registry string `json:"registry,omitempty"`
+	// Build secrets mounted with --secret id=...,src=... (optional). Secrets are only available to RUN --mount=type=secret instructions and are never baked into layers.
+	Secrets []SecretMount `json:"secrets,omitempty"`
 	// Image tags (optional)
 	Tags []string `json:"tags,omitempty"`
 	// Build target stage (optional)
 	Target string `json:"target,omitempty"`
 }
 
+// SecretMountFromMap creates a SecretMount from a map[string]interface{}.
+func SecretMountFromMap(m map[string]interface{}) (*SecretMount, error) {
+	if m == nil {
+		return &SecretMount{}, nil
+	}
+
+	s := &SecretMount{}
+	// Parse id
+	if v, ok := m["id"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Id = val
+		} else {
+			return nil, fmt.Errorf("field id: expected string, got %T", v)
+		}
+	}
+	// Parse src
+	if v, ok := m["src"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Src = val
+		} else {
+			return nil, fmt.Errorf("field src: expected string, got %T", v)
+		}
+	}
+	return s, nil
+}
+
 // SpecFromMap creates a Spec from a map[string]interface{}.
 func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	if m == nil {
@@ -67,6 +106,23 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field dockerfile: expected string, got %T", v)
 		}
 	}
+	// Parse platforms
+	if v, ok := m["platforms"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Platforms = make([]string, 0, len(arr))
+			for i, item := range arr {
+				if str, ok := item.(string); ok {
+					s.Platforms = append(s.Platforms, str)
+				} else {
+					return nil, fmt.Errorf("field platforms[%d]: expected string, got %T", i, item)
+				}
+			}
+		} else if arr, ok := v.([]string); ok {
+			s.Platforms = arr
+		} else {
+			return nil, fmt.Errorf("field platforms: expected []string, got %T", v)
+		}
+	}
 	// Parse push
 	if v, ok := m["push"]; ok && v != nil {
 		if val, ok := v.(bool); ok {
@@ -83,6 +139,27 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field registry: expected string, got %T", v)
 		}
 	}
+	// Parse secrets
+	if v, ok := m["secrets"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Secrets = make([]SecretMount, 0, len(arr))
+			for i, item := range arr {
+				if obj, ok := item.(map[string]interface{}); ok {
+					ref, err := SecretMountFromMap(obj)
+					if err != nil {
+						return nil, fmt.Errorf("field secrets[%d]: %w", i, err)
+					}
+					if ref != nil {
+						s.Secrets = append(s.Secrets, *ref)
+					}
+				} else {
+					return nil, fmt.Errorf("field secrets[%d]: expected object, got %T", i, item)
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("field secrets: expected []object, got %T", v)
+		}
+	}
 	// Parse tags
 	if v, ok := m["tags"]; ok && v != nil {
 		if arr, ok := v.([]interface{}); ok {
@@ -111,6 +188,22 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	return s, nil
 }
 
+// ToMap converts a SecretMount to a map[string]interface{}.
+func (s *SecretMount) ToMap() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{})
+	if s.Id != "" {
+		m["id"] = s.Id
+	}
+	if s.Src != "" {
+		m["src"] = s.Src
+	}
+	return m
+}
+
 // ToMap converts a Spec to a map[string]interface{}.
 func (s *Spec) ToMap() map[string]interface{} {
 	if s == nil {
@@ -127,12 +220,22 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if s.Dockerfile != "" {
 		m["dockerfile"] = s.Dockerfile
 	}
+	if len(s.Platforms) > 0 {
+		m["platforms"] = s.Platforms
+	}
 	if s.Push {
 		m["push"] = s.Push
 	}
 	if s.Registry != "" {
 		m["registry"] = s.Registry
 	}
+	if len(s.Secrets) > 0 {
+		arr := make([]interface{}, 0, len(s.Secrets))
+		for _, item := range s.Secrets {
+			arr = append(arr, item.ToMap())
+		}
+		m["secrets"] = arr
+	}
 	if len(s.Tags) > 0 {
 		m["tags"] = s.Tags
 	}
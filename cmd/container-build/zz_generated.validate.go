@@ -1,13 +1,52 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:93abc4bf66872c4f411ab6dc112bfddb35fa4d2508725503e33e9c0d254e9d7d
+// SourceChecksum: sha256:bae6879d8472668d830f8c8709131d1c6de0180794d5aeae0b9e38d4d54036c7
 
 package main
 
 import (
+	"fmt"
+
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
+// ValidateSecretMount validates a SecretMount and returns validation results.
+// It checks required fields and validates enum values.
+func ValidateSecretMount(s *SecretMount) *mcptypes.ConfigValidateOutput {
+	if s == nil {
+		return &mcptypes.ConfigValidateOutput{
+			Valid: true,
+		}
+	}
+
+	var errors []mcptypes.ValidationError
+	// Validate required field: id
+	if s.Id == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.id",
+			Message: "required field is missing",
+		})
+	}
+	// Validate required field: src
+	if s.Src == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.src",
+			Message: "required field is missing",
+		})
+	}
+
+	if len(errors) > 0 {
+		return &mcptypes.ConfigValidateOutput{
+			Valid:  false,
+			Errors: errors,
+		}
+	}
+
+	return &mcptypes.ConfigValidateOutput{
+		Valid: true,
+	}
+}
+
 // ValidateSpec validates a Spec and returns validation results.
 // It checks required fields and validates enum values.
 func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
@@ -18,6 +57,18 @@ func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
 	}
 
 	var errors []mcptypes.ValidationError
+	// Validate array of references: secrets
+	for i, item := range s.Secrets {
+		nestedResult := ValidateSecretMount(&item)
+		if !nestedResult.Valid {
+			for _, e := range nestedResult.Errors {
+				errors = append(errors, mcptypes.ValidationError{
+					Field:   fmt.Sprintf("spec.secrets[%d].%s", i, e.Field),
+					Message: e.Message,
+				})
+			}
+		}
+	}
 
 	if len(errors) > 0 {
 		return &mcptypes.ConfigValidateOutput{
@@ -70,6 +70,26 @@ type Config struct {
 
 	// Generate contains code generation settings.
 	Generate GenerateConfig `yaml:"generate"`
+
+	// SelfTest enables the "self-test" MCP tool (optional).
+	SelfTest *SelfTestConfig `yaml:"selfTest,omitempty"`
+}
+
+// SelfTestConfig lists the external dependencies checked by the "self-test" MCP tool.
+type SelfTestConfig struct {
+	// Dependencies are the external commands the engine relies on.
+	Dependencies []SelfTestDependency `yaml:"dependencies"`
+}
+
+// SelfTestDependency describes an external command checked by the self-test.
+type SelfTestDependency struct {
+	// Name is the human-readable dependency name (required).
+	Name string `yaml:"name"`
+	// Command is the executable looked up in PATH (required).
+	Command string `yaml:"command"`
+	// Args are the probe arguments run to check the command works (optional).
+	// Example: ["version", "--short"]
+	Args []string `yaml:"args,omitempty"`
 }
 
 // OpenAPIConfig contains OpenAPI specification configuration.
@@ -90,6 +110,11 @@ type GenerateConfig struct {
 	CreateFunc string `yaml:"createFunc,omitempty"`
 	// DeleteFunc is the function name for testenv-subengine delete operation (default: "Delete").
 	DeleteFunc string `yaml:"deleteFunc,omitempty"`
+	// CLIFunc is the function run in CLI mode (optional, signature: func() error).
+	// When empty, the generated engine is MCP-only.
+	CLIFunc string `yaml:"cliFunc,omitempty"`
+	// ToolsFunc registers additional MCP tools (optional, signature: func(*mcpserver.Server) error).
+	ToolsFunc string `yaml:"toolsFunc,omitempty"`
 	// SpecTypes configures external spec types generation (optional).
 	SpecTypes *SpecTypesConfig `yaml:"specTypes,omitempty"`
 }
@@ -276,6 +301,30 @@ func ValidateConfig(c *Config) []ValidationError {
 		}
 	}
 
+	// Validate selfTest dependencies when set
+	if c.SelfTest != nil {
+		if len(c.SelfTest.Dependencies) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   "selfTest.dependencies",
+				Message: "at least one dependency is required when selfTest is set",
+			})
+		}
+		for i, dep := range c.SelfTest.Dependencies {
+			if dep.Name == "" {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("selfTest.dependencies[%d].name", i),
+					Message: "required field is missing",
+				})
+			}
+			if dep.Command == "" {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("selfTest.dependencies[%d].command", i),
+					Message: "required field is missing",
+				})
+			}
+		}
+	}
+
 	return errors
 }
 
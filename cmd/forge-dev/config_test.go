@@ -722,6 +722,61 @@ func TestValidateConfig(t *testing.T) {
 			t.Errorf("ValidateConfig returned errors for nil specTypes: %v", errors)
 		}
 	})
+
+	// SelfTest validation tests
+	t.Run("selfTest with valid dependencies", func(t *testing.T) {
+		config := &Config{
+			Name:     "go-build",
+			Type:     EngineTypeBuilder,
+			Version:  "0.15.0",
+			OpenAPI:  OpenAPIConfig{SpecPath: "./spec.openapi.yaml"},
+			Generate: GenerateConfig{PackageName: "main"},
+			SelfTest: &SelfTestConfig{
+				Dependencies: []SelfTestDependency{
+					{Name: "go", Command: "go", Args: []string{"version"}},
+				},
+			},
+		}
+
+		errors := ValidateConfig(config)
+		if len(errors) > 0 {
+			t.Errorf("ValidateConfig returned errors for valid selfTest: %v", errors)
+		}
+	})
+
+	t.Run("selfTest without dependencies", func(t *testing.T) {
+		config := &Config{
+			Name:     "go-build",
+			Type:     EngineTypeBuilder,
+			Version:  "0.15.0",
+			OpenAPI:  OpenAPIConfig{SpecPath: "./spec.openapi.yaml"},
+			Generate: GenerateConfig{PackageName: "main"},
+			SelfTest: &SelfTestConfig{},
+		}
+
+		errors := ValidateConfig(config)
+		if !hasErrorForField(errors, "selfTest.dependencies") {
+			t.Error("Expected error for empty selfTest.dependencies")
+		}
+	})
+
+	t.Run("selfTest dependency missing command", func(t *testing.T) {
+		config := &Config{
+			Name:     "go-build",
+			Type:     EngineTypeBuilder,
+			Version:  "0.15.0",
+			OpenAPI:  OpenAPIConfig{SpecPath: "./spec.openapi.yaml"},
+			Generate: GenerateConfig{PackageName: "main"},
+			SelfTest: &SelfTestConfig{
+				Dependencies: []SelfTestDependency{{Name: "go"}},
+			},
+		}
+
+		errors := ValidateConfig(config)
+		if !hasErrorForField(errors, "selfTest.dependencies[0].command") {
+			t.Error("Expected error for missing selfTest dependency command")
+		}
+	})
 }
 
 func TestValidationError(t *testing.T) {
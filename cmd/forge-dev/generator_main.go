@@ -41,6 +41,12 @@ type MainTemplateData struct {
 	CreateFunc string
 	// DeleteFunc is the delete function name for testenv-subengine engines.
 	DeleteFunc string
+	// CLIFunc is the CLI mode function name (empty for MCP-only engines).
+	CLIFunc string
+	// ToolsFunc is the function registering additional MCP tools (empty when none).
+	ToolsFunc string
+	// SelfTestDependencies are the dependencies checked by the self-test tool (nil when disabled).
+	SelfTestDependencies []SelfTestDependency
 	// SpecTypesContext holds external spec types info (nil when disabled).
 	SpecTypesContext *SpecTypesContext
 }
@@ -63,8 +69,13 @@ func GenerateMainFile(config *Config, checksum string, specTypesCtx *SpecTypesCo
 		RunFunc:          config.GetRunFunc(),
 		CreateFunc:       config.GetCreateFunc(),
 		DeleteFunc:       config.GetDeleteFunc(),
+		CLIFunc:          config.Generate.CLIFunc,
+		ToolsFunc:        config.Generate.ToolsFunc,
 		SpecTypesContext: specTypesCtx,
 	}
+	if config.SelfTest != nil {
+		data.SelfTestDependencies = config.SelfTest.Dependencies
+	}
 
 	// Parse and execute template
 	tmpl, err := parseTemplate("main.go.tmpl")
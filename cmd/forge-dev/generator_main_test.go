@@ -0,0 +1,182 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateMainFile_CLIFunc(t *testing.T) {
+	tests := []struct {
+		name       string
+		engineType EngineType
+		cliFunc    string
+		contains   string
+	}{
+		{
+			name:       "MCP-only by default",
+			engineType: EngineTypeTestRunner,
+			cliFunc:    "",
+			contains:   "RunCLI:         nil, // Generated engines are MCP-only",
+		},
+		{
+			name:       "CLI function wired when configured",
+			engineType: EngineTypeTestRunner,
+			cliFunc:    "runCLI",
+			contains:   "RunCLI:         runCLI,",
+		},
+		{
+			name:       "builder CLI by default",
+			engineType: EngineTypeBuilder,
+			cliFunc:    "",
+			contains:   "engineframework.RunBuilderCLI(context.Background(), engineframework.BuilderConfig{",
+		},
+		{
+			name:       "builder CLI function wired when configured",
+			engineType: EngineTypeBuilder,
+			cliFunc:    "runCLI",
+			contains:   "RunCLI:         runCLI,",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Name:    "test-engine",
+				Type:    tt.engineType,
+				Version: "0.1.0",
+				Generate: GenerateConfig{
+					PackageName: "main",
+					CLIFunc:     tt.cliFunc,
+				},
+			}
+
+			got, err := GenerateMainFile(config, "sha256:abc123", nil)
+			if err != nil {
+				t.Fatalf("GenerateMainFile() error = %v\n%s", err, got)
+			}
+			if !strings.Contains(string(got), tt.contains) {
+				t.Errorf("Expected generated code to contain %q, got:\n%s", tt.contains, got)
+			}
+		})
+	}
+}
+
+func TestGenerateMainFile_ToolsFunc(t *testing.T) {
+	config := &Config{
+		Name:    "test-engine",
+		Type:    EngineTypeTestEnvSubengine,
+		Version: "0.1.0",
+		Generate: GenerateConfig{
+			PackageName: "main",
+			ToolsFunc:   "registerTools",
+		},
+	}
+
+	got, err := GenerateMainFile(config, "sha256:abc123", nil)
+	if err != nil {
+		t.Fatalf("GenerateMainFile() error = %v\n%s", err, got)
+	}
+
+	want := "if err := registerTools(server); err != nil {"
+	if !strings.Contains(string(got), want) {
+		t.Errorf("Expected generated code to contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestGenerateMainFile_SelfTest(t *testing.T) {
+	config := &Config{
+		Name:    "test-engine",
+		Type:    EngineTypeBuilder,
+		Version: "0.1.0",
+		Generate: GenerateConfig{
+			PackageName: "main",
+		},
+		SelfTest: &SelfTestConfig{
+			Dependencies: []SelfTestDependency{
+				{Name: "helm", Command: "helm", Args: []string{"version", "--short"}},
+				{Name: "kubectl", Command: "kubectl"},
+			},
+		},
+	}
+
+	got, err := GenerateMainFile(config, "sha256:abc123", nil)
+	if err != nil {
+		t.Fatalf("GenerateMainFile() error = %v\n%s", err, got)
+	}
+
+	for _, want := range []string{
+		`"github.com/alexandremahdhaoui/forge/pkg/engineframework"`,
+		"engineframework.RegisterSelfTestTool(server, engineframework.SelfTestConfig{",
+		`{Name: "helm", Command: "helm", Args: []string{"version", "--short"}},`,
+		`{Name: "kubectl", Command: "kubectl"},`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerateMainFile_NoSelfTest(t *testing.T) {
+	config := &Config{
+		Name:    "test-engine",
+		Type:    EngineTypeBuilder,
+		Version: "0.1.0",
+		Generate: GenerateConfig{
+			PackageName: "main",
+		},
+	}
+
+	got, err := GenerateMainFile(config, "sha256:abc123", nil)
+	if err != nil {
+		t.Fatalf("GenerateMainFile() error = %v\n%s", err, got)
+	}
+
+	if strings.Contains(string(got), "RegisterSelfTestTool") {
+		t.Errorf("Expected no self-test registration, got:\n%s", got)
+	}
+}
+
+func TestGenerateMainFile_SetupMCPForDocsVerify(t *testing.T) {
+	for _, engineType := range []EngineType{EngineTypeBuilder, EngineTypeTestRunner, EngineTypeTestEnvSubengine, EngineTypeDependencyDetector} {
+		t.Run(string(engineType), func(t *testing.T) {
+			config := &Config{
+				Name:    "test-engine",
+				Type:    engineType,
+				Version: "0.1.0",
+				Generate: GenerateConfig{
+					PackageName: "main",
+				},
+			}
+
+			got, err := GenerateMainFile(config, "sha256:abc123", nil)
+			if err != nil {
+				t.Fatalf("GenerateMainFile() error = %v\n%s", err, got)
+			}
+			for _, want := range []string{
+				"SetupMCP:       setupMCPServer,",
+				"func setupMCPServer() (*mcpserver.Server, error) {",
+				"server, err := setupMCPServer()",
+			} {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("Expected generated code to contain %q, got:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
@@ -209,6 +209,7 @@ func needsFmtImportForTypes(types []ForgeTypeDefinition) bool {
 	supportedTypes := map[string]bool{
 		"string":            true,
 		"bool":              true,
+		"*bool":             true,
 		"int":               true,
 		"float64":           true,
 		"[]string":          true,
@@ -1022,3 +1022,43 @@ func TestGenerateSpecFileFromTypes_MultipleNonPointerRefs(t *testing.T) {
 		t.Errorf("Generated code does not compile (likely duplicate variable declaration bug): %v\nCode:\n%s", parseErr, code)
 	}
 }
+
+func TestGenerateSpecFileFromTypes_NullableBool(t *testing.T) {
+	types := []ForgeTypeDefinition{
+		{
+			Name:     "Spec",
+			JsonName: "Spec",
+			Properties: []ForgeProperty{
+				{Name: "Cgo", JsonName: "cgo", GoType: "*bool", Nullable: true, IsPointer: true},
+			},
+		},
+	}
+
+	config := &Config{
+		Name: "test-engine",
+		Type: EngineTypeBuilder,
+		Generate: GenerateConfig{
+			PackageName: "main",
+		},
+	}
+
+	got, err := GenerateSpecFileFromTypes(types, config, "sha256:nullable-bool-test", nil)
+	if err != nil {
+		t.Fatalf("GenerateSpecFileFromTypes() error = %v", err)
+	}
+
+	code := string(got)
+	for _, pattern := range []string{
+		"Cgo *bool `json:\"cgo,omitempty\"`",
+		"s.Cgo = &val",
+		"field cgo: expected bool, got %T",
+		"m[\"cgo\"] = *s.Cgo",
+	} {
+		if !strings.Contains(code, pattern) {
+			t.Errorf("Generated code missing pattern %q\nCode:\n%s", pattern, code)
+		}
+	}
+	if strings.Contains(code, "Unsupported type") {
+		t.Errorf("Generated code should support *bool\nCode:\n%s", code)
+	}
+}
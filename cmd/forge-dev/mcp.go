@@ -15,6 +15,7 @@
 package main
 
 import (
+	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
@@ -49,5 +50,5 @@ func runMCPServer() error {
 		Description: "Validate forge-dev engine scaffolding configuration. Checks forge-dev.yaml structure and spec.openapi.yaml schema definitions for code generation correctness.",
 	}, handleConfigValidate)
 
-	return server.RunDefault()
+	return server.Run(enginecli.Context())
 }
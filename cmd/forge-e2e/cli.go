@@ -0,0 +1,214 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const cliUsage = `Usage:
+  forge-e2e run <exact-test-name>   Run exactly one test by name
+  forge-e2e run --tag <expr>...     Run all tests matching the tag filter
+  forge-e2e run ... --report <path> Also write the JSON test report to path
+  forge-e2e run ... --metrics <path>
+                                    Also write Prometheus metrics to path
+  forge-e2e --list [--tag <expr>]... [-o json]
+                                    List the tests selected by the current filters without running them
+  forge-e2e --mcp                   Run as MCP server
+  forge-e2e docs <command>          Show engine documentation
+  forge-e2e version                 Show version information
+
+Tag filter syntax: "," means OR, "+" means AND, "!" negates a tag.
+Example: --tag slow+kind,destructive --tag '!network'
+Multiple --tag flags are combined with OR.
+The report path defaults to $FORGE_E2E_REPORT; the report is written even when tests fail.
+The metrics path defaults to $TEST_METRICS_FILE; metrics are labelled with stage "e2e".
+TEST_CATEGORY, TEST_NAME_PATTERN and TEST_TAGS filter the tests that are run or listed.`
+
+// runCLI runs forge-e2e in CLI mode.
+func runCLI() error {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return fmt.Errorf("missing command")
+	}
+
+	command := os.Args[1]
+	switch command {
+	case "run":
+		args, err := parseRunArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, cliUsage)
+			return err
+		}
+		outputs := runOutputs{
+			reportPath:  reportFilePath(args.report),
+			metricsPath: metricsFilePath(&Spec{MetricsFile: args.metrics}),
+		}
+		if args.name != "" {
+			return cmdRunSingle(args.name, outputs)
+		}
+		return cmdRunTagged(args.tags, outputs)
+	case "--list":
+		if err := cmdList(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, cliUsage)
+			return err
+		}
+		return nil
+	case "help", "--help", "-h":
+		fmt.Println(cliUsage)
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// runArgs holds the parsed arguments of the run command.
+type runArgs struct {
+	// name is the exact test name, or empty when tags are used.
+	name string
+	// tags is the tag filter built from the --tag flags (combined with OR).
+	tags tagFilter
+	// report is the --report path.
+	report string
+	// metrics is the --metrics path.
+	metrics string
+}
+
+// parseRunArgs parses the arguments of the run command. It returns either an exact
+// test name or a tag filter built from the --tag flags (combined with OR).
+func parseRunArgs(args []string) (runArgs, error) {
+	var parsed runArgs
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--tag":
+			if i+1 >= len(args) {
+				return runArgs{}, fmt.Errorf("--tag requires a value")
+			}
+			i++
+			parsed.tags = parsed.tags.or(parseTagFilter(args[i]))
+		case strings.HasPrefix(arg, "--tag="):
+			parsed.tags = parsed.tags.or(parseTagFilter(strings.TrimPrefix(arg, "--tag=")))
+		case arg == "--report", arg == "--metrics":
+			if i+1 >= len(args) {
+				return runArgs{}, fmt.Errorf("%s requires a path", arg)
+			}
+			i++
+			parsed.setPath(arg, args[i])
+		case strings.HasPrefix(arg, "--report="), strings.HasPrefix(arg, "--metrics="):
+			flag, path, _ := strings.Cut(arg, "=")
+			if path == "" {
+				return runArgs{}, fmt.Errorf("%s requires a path", flag)
+			}
+			parsed.setPath(flag, path)
+		case strings.HasPrefix(arg, "-"):
+			return runArgs{}, fmt.Errorf("unknown flag: %s", arg)
+		case parsed.name != "":
+			return runArgs{}, fmt.Errorf("run accepts a single test name, got %q and %q", parsed.name, arg)
+		default:
+			parsed.name = arg
+		}
+	}
+
+	if parsed.name != "" && len(parsed.tags) > 0 {
+		return runArgs{}, fmt.Errorf("a test name and --tag cannot be combined")
+	}
+	if parsed.name == "" && len(parsed.tags) == 0 {
+		return runArgs{}, fmt.Errorf("run requires a test name or --tag")
+	}
+	return parsed, nil
+}
+
+// setPath stores the path given to the --report or --metrics flag.
+func (a *runArgs) setPath(flag, path string) {
+	if flag == "--metrics" {
+		a.metrics = path
+		return
+	}
+	a.report = path
+}
+
+// cliMetricsStage is the stage label of the metrics written by CLI runs,
+// which are not started by a forge test stage.
+const cliMetricsStage = "e2e"
+
+// runOutputs holds the files written after a CLI run; empty paths are skipped.
+type runOutputs struct {
+	reportPath  string
+	metricsPath string
+}
+
+// emit writes the JSON report and the Prometheus metrics of report.
+func (o runOutputs) emit(report *DetailedTestReport) {
+	emitJSONReport(o.reportPath, report)
+	emitPrometheusMetrics(o.metricsPath, cliMetricsStage, report)
+}
+
+// cmdRunTagged runs every test selected by the tag filter, on top of the
+// TEST_CATEGORY and TEST_NAME_PATTERN filters, and fails if any test failed.
+func cmdRunTagged(tags tagFilter, outputs runOutputs) error {
+	suite := NewTestSuite()
+	suite.filters.Tags = tags
+	registerAllTests(suite)
+
+	if len(suite.tests) == 0 {
+		err := fmt.Errorf("no tests match tag filter %q", tags)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return err
+	}
+
+	report := suite.RunAll()
+	outputs.emit(report)
+	if report.Status != "passed" {
+		err := fmt.Errorf("e2e tests %s", report.Status)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// cmdRunSingle runs the test with the given exact name and fails if it did not pass.
+func cmdRunSingle(name string, outputs runOutputs) error {
+	suite := newSingleTestSuite()
+
+	report, err := suite.RunSingle(name)
+	if err != nil {
+		outputs.emit(setupFailedReport(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return err
+	}
+	outputs.emit(report)
+
+	if report.Status != "passed" {
+		err := fmt.Errorf("test %q %s", name, report.Status)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// newSingleTestSuite returns a suite with every test registered, ignoring the
+// TEST_CATEGORY and TEST_NAME_PATTERN filters so exact-name selection sees all tests.
+func newSingleTestSuite() *TestSuite {
+	suite := &TestSuite{
+		tests:   make([]Test, 0),
+		results: make([]TestResult, 0),
+	}
+	registerAllTests(suite)
+	return suite
+}
@@ -0,0 +1,90 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindTestByName(t *testing.T) {
+	tests := []Test{
+		{Name: "forge build", Category: CategoryBuild},
+		{Name: "forge build specific artifact", Category: CategoryBuild},
+		{Name: "testenv create", Category: CategoryTestEnv},
+	}
+
+	t.Run("exact match", func(t *testing.T) {
+		got, err := findTestByName(tests, "forge build")
+		if err != nil {
+			t.Fatalf("findTestByName() error = %v", err)
+		}
+		if got.Name != "forge build" {
+			t.Errorf("Expected exact match 'forge build', got %q", got.Name)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := findTestByName(tests, "does not exist")
+		if err == nil {
+			t.Fatal("Expected error for unknown test name")
+		}
+		if !strings.Contains(err.Error(), `no test named "does not exist"`) {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("partial name is not a match", func(t *testing.T) {
+		_, err := findTestByName(tests, "testenv")
+		if err == nil {
+			t.Fatal("Expected error for partial test name")
+		}
+		if !strings.Contains(err.Error(), `did you mean: "testenv create"`) {
+			t.Errorf("Expected suggestion in error, got: %v", err)
+		}
+	})
+}
+
+func TestRunSingle_NoMatch(t *testing.T) {
+	suite := newSingleTestSuite()
+
+	report, err := suite.RunSingle("no such forge-e2e test")
+	if err == nil {
+		t.Fatal("Expected error for unknown test name")
+	}
+	if report != nil {
+		t.Errorf("Expected no report, got %+v", report)
+	}
+	if len(suite.results) != 0 {
+		t.Errorf("Expected no test to run, got %d results", len(suite.results))
+	}
+}
+
+func TestNewSingleTestSuite_IgnoresFilters(t *testing.T) {
+	t.Setenv("TEST_CATEGORY", "mcp")
+	t.Setenv("TEST_NAME_PATTERN", "nothing-matches-this")
+
+	suite := newSingleTestSuite()
+
+	test, err := findTestByName(suite.tests, "forge build")
+	if err != nil {
+		t.Fatalf("Expected 'forge build' to be selectable despite filters: %v", err)
+	}
+	if test.Category != CategoryBuild {
+		t.Errorf("Expected category %s, got %s", CategoryBuild, test.Category)
+	}
+}
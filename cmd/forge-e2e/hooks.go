@@ -0,0 +1,102 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// CategoryHooks prepares fixtures needed only by the tests of one category
+// (e.g. a container registry for build tests).
+type CategoryHooks struct {
+	// Setup runs once before the first test of the category.
+	// When it fails, every test of the category is recorded as skipped and the run fails.
+	Setup func(*TestSuite) error
+	// Teardown runs once after the last test of the category, only if Setup succeeded.
+	Teardown func(*TestSuite) error
+}
+
+// SetCategoryHooks registers the setup and teardown hooks of a category.
+// Hooks only run when at least one test of the category is selected and not skipped,
+// so fixtures are not created for runs that filter the category out.
+// It is an extension point: the built-in test suite does not register any hooks yet.
+func (ts *TestSuite) SetCategoryHooks(category TestCategory, hooks CategoryHooks) {
+	if ts.hooks == nil {
+		ts.hooks = make(map[TestCategory]CategoryHooks)
+	}
+	ts.hooks[category] = hooks
+}
+
+// setupCategory runs the setup hook of a category and returns the teardown to run
+// once its tests are done. When setup fails, the category's tests are recorded as skipped,
+// the failure is recorded for the report and ok is false.
+func (ts *TestSuite) setupCategory(category TestCategory, tests []Test, reporter *testReporter) (teardown func(), ok bool) {
+	hooks, found := ts.hooks[category]
+	if !found || !hasRunnableTest(tests) {
+		return func() {}, true
+	}
+
+	if hooks.Setup != nil {
+		if err := hooks.Setup(ts); err != nil {
+			reason := fmt.Sprintf("setup of category %s failed: %v", category, err)
+			_, _ = fmt.Fprintf(reporter.writer, "❌ Setup of category %s failed: %v\n", category, err)
+			ts.setupErrors = append(ts.setupErrors, reason)
+			ts.skipTests(tests, reason, reporter)
+			return func() {}, false
+		}
+	}
+
+	return func() {
+		if hooks.Teardown == nil {
+			return
+		}
+		if os.Getenv("SKIP_CLEANUP") != "" {
+			_, _ = fmt.Fprintf(reporter.writer, "⚠️  SKIP_CLEANUP set, skipping teardown of category %s\n", category)
+			return
+		}
+		if err := hooks.Teardown(ts); err != nil {
+			_, _ = fmt.Fprintf(reporter.writer, "Warning: teardown of category %s failed: %v\n", category, err)
+		}
+	}, true
+}
+
+// skipTests records every test as skipped with the given reason.
+func (ts *TestSuite) skipTests(tests []Test, reason string, reporter *testReporter) {
+	executor := &testExecutor{suite: ts}
+	for _, test := range tests {
+		result := TestResult{
+			Name:     test.Name,
+			Category: test.Category,
+			Status:   "skipped",
+			Output:   reason,
+		}
+		if test.Skip {
+			result.Output = test.SkipReason
+		}
+		executor.recordResult(result)
+		reporter.printTestResult(result, false)
+	}
+}
+
+// hasRunnableTest reports whether at least one test is not skipped.
+func hasRunnableTest(tests []Test) bool {
+	for _, test := range tests {
+		if !test.Skip {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordingHooks returns category hooks that append their invocations to calls.
+func recordingHooks(calls *[]string, setupErr error) CategoryHooks {
+	return CategoryHooks{
+		Setup: func(*TestSuite) error {
+			*calls = append(*calls, "setup")
+			return setupErr
+		},
+		Teardown: func(*TestSuite) error {
+			*calls = append(*calls, "teardown")
+			return nil
+		},
+	}
+}
+
+// recordingTest returns a test that appends its name to calls when it runs.
+func recordingTest(name string, calls *[]string) Test {
+	return Test{
+		Name:     name,
+		Category: CategoryBuild,
+		Run: func(*TestSuite) error {
+			*calls = append(*calls, name)
+			return nil
+		},
+	}
+}
+
+func TestRunCategory_RunsHooksAroundTests(t *testing.T) {
+	var calls []string
+	suite := &TestSuite{}
+	suite.SetCategoryHooks(CategoryBuild, recordingHooks(&calls, nil))
+
+	tests := []Test{recordingTest("a", &calls), recordingTest("b", &calls)}
+	suite.runCategory(CategoryBuild, tests, &testReporter{writer: io.Discard})
+
+	want := []string{"setup", "a", "b", "teardown"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	for _, result := range suite.results {
+		if result.Status != "passed" {
+			t.Errorf("result %s = %s, want passed", result.Name, result.Status)
+		}
+	}
+}
+
+func TestRunCategory_SetupFailureSkipsTests(t *testing.T) {
+	var calls []string
+	suite := &TestSuite{}
+	suite.SetCategoryHooks(CategoryBuild, recordingHooks(&calls, errors.New("registry unavailable")))
+
+	tests := []Test{recordingTest("a", &calls), recordingTest("b", &calls)}
+	suite.runCategory(CategoryBuild, tests, &testReporter{writer: io.Discard})
+
+	// Neither the tests nor the teardown run
+	if want := []string{"setup"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	if len(suite.results) != len(tests) {
+		t.Fatalf("recorded %d results, want %d", len(suite.results), len(tests))
+	}
+	for _, result := range suite.results {
+		if result.Status != "skipped" {
+			t.Errorf("result %s = %s, want skipped", result.Name, result.Status)
+		}
+		if !strings.Contains(result.Output, "registry unavailable") {
+			t.Errorf("result %s output = %q, want the setup error", result.Name, result.Output)
+		}
+	}
+
+	report := suite.generateReport(0, &testReporter{writer: io.Discard})
+	if report.Status != "failed" || report.Skipped != 2 || report.Failed != 0 {
+		t.Errorf("report status = %s, skipped = %d, failed = %d, want failed with 2 skipped", report.Status, report.Skipped, report.Failed)
+	}
+	if !strings.Contains(report.ErrorMessage, "setup of category build failed: registry unavailable") {
+		t.Errorf("report error = %q, want the setup error", report.ErrorMessage)
+	}
+	if stats := report.Categories[CategoryBuild]; stats.Skipped != 2 {
+		t.Errorf("build category skipped = %d, want 2", stats.Skipped)
+	}
+}
+
+func TestRunCategory_NoHooksWithoutRunnableTests(t *testing.T) {
+	var calls []string
+	suite := &TestSuite{}
+	suite.SetCategoryHooks(CategoryBuild, recordingHooks(&calls, nil))
+
+	skipped := recordingTest("a", &calls)
+	skipped.Skip = true
+	skipped.SkipReason = "CONTAINER_ENGINE not available"
+	suite.runCategory(CategoryBuild, []Test{skipped}, &testReporter{writer: io.Discard})
+
+	if len(calls) != 0 {
+		t.Errorf("calls = %v, want no hook or test invocation", calls)
+	}
+	if len(suite.results) != 1 || suite.results[0].Output != skipped.SkipReason {
+		t.Errorf("results = %+v, want the test skipped with its own reason", suite.results)
+	}
+}
+
+func TestRunCategory_SkipCleanupKeepsFixtures(t *testing.T) {
+	t.Setenv("SKIP_CLEANUP", "1")
+
+	var calls []string
+	suite := &TestSuite{}
+	suite.SetCategoryHooks(CategoryBuild, recordingHooks(&calls, nil))
+	suite.runCategory(CategoryBuild, []Test{recordingTest("a", &calls)}, &testReporter{writer: io.Discard})
+
+	if want := []string{"setup", "a"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// listedTest describes a registered test without running it.
+type listedTest struct {
+	Name       string       `json:"name"`
+	Category   TestCategory `json:"category"`
+	Skip       bool         `json:"skip"`
+	SkipReason string       `json:"skipReason,omitempty"`
+	Parallel   bool         `json:"parallel"`
+	Tags       []string     `json:"tags,omitempty"`
+}
+
+// listTests registers all tests and returns those selected by filters, in registration order.
+func listTests(filters TestFilters) []listedTest {
+	suite := &TestSuite{
+		tests:   make([]Test, 0),
+		results: make([]TestResult, 0),
+		filters: filters,
+	}
+	registerAllTests(suite)
+
+	listed := make([]listedTest, 0, len(suite.tests))
+	for _, test := range suite.tests {
+		listed = append(listed, listedTest{
+			Name:       test.Name,
+			Category:   test.Category,
+			Skip:       test.Skip,
+			SkipReason: test.SkipReason,
+			Parallel:   test.Parallel,
+			Tags:       test.Tags,
+		})
+	}
+	return listed
+}
+
+// writeTestList writes the listed tests as a table (default) or as JSON.
+func writeTestList(w io.Writer, tests []listedTest, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tests)
+	case "", "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "NAME\tCATEGORY\tPARALLEL\tSKIP\tTAGS")
+		for _, test := range tests {
+			skip := strconv.FormatBool(test.Skip)
+			if test.Skip && test.SkipReason != "" {
+				skip = fmt.Sprintf("true (%s)", test.SkipReason)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%s\n",
+				test.Name, test.Category, test.Parallel, skip, strings.Join(test.Tags, ","))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q (expected table or json)", format)
+	}
+}
+
+// parseListArgs parses the arguments following --list: --tag filters and the -o output format.
+func parseListArgs(args []string) (tagFilter, string, error) {
+	var tags tagFilter
+	var format string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--tag":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("--tag requires a value")
+			}
+			i++
+			tags = tags.or(parseTagFilter(args[i]))
+		case strings.HasPrefix(arg, "--tag="):
+			tags = tags.or(parseTagFilter(strings.TrimPrefix(arg, "--tag=")))
+		case arg == "-o" || arg == "--output":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			format = args[i]
+		case strings.HasPrefix(arg, "-o="), strings.HasPrefix(arg, "--output="):
+			_, format, _ = strings.Cut(arg, "=")
+		default:
+			return nil, "", fmt.Errorf("unexpected argument: %s", arg)
+		}
+	}
+	return tags, format, nil
+}
+
+// cmdList prints the tests that would run under the current filters without running them.
+func cmdList(args []string) error {
+	tags, format, err := parseListArgs(args)
+	if err != nil {
+		return err
+	}
+
+	filters := newTestFilters()
+	if len(tags) > 0 {
+		filters.Tags = tags
+	}
+
+	return writeTestList(os.Stdout, listTests(filters), format)
+}
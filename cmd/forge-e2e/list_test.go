@@ -0,0 +1,144 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestListTests_RespectsFilters(t *testing.T) {
+	t.Setenv("TEST_TAGS", "")
+
+	t.Run("no filters lists all tests", func(t *testing.T) {
+		t.Setenv("TEST_CATEGORY", "")
+		t.Setenv("TEST_NAME_PATTERN", "")
+
+		all := newSingleTestSuite().tests
+		listed := listTests(newTestFilters())
+		if len(listed) != len(all) {
+			t.Errorf("listTests() returned %d tests, want all %d registered tests", len(listed), len(all))
+		}
+	})
+
+	t.Run("TEST_CATEGORY", func(t *testing.T) {
+		t.Setenv("TEST_CATEGORY", string(CategoryBuild))
+		t.Setenv("TEST_NAME_PATTERN", "")
+
+		listed := listTests(newTestFilters())
+		if len(listed) == 0 {
+			t.Fatal("listTests() returned no build tests")
+		}
+		for _, test := range listed {
+			if test.Category != CategoryBuild {
+				t.Errorf("listed %q in category %q, want only %q", test.Name, test.Category, CategoryBuild)
+			}
+		}
+	})
+
+	t.Run("TEST_NAME_PATTERN", func(t *testing.T) {
+		t.Setenv("TEST_CATEGORY", "")
+		t.Setenv("TEST_NAME_PATTERN", "ENVIRONMENT")
+
+		listed := listTests(newTestFilters())
+		if len(listed) == 0 {
+			t.Fatal("listTests() returned no test matching the name pattern")
+		}
+		for _, test := range listed {
+			if !strings.Contains(strings.ToLower(test.Name), "environment") {
+				t.Errorf("listed %q, want only names containing %q", test.Name, "environment")
+			}
+		}
+	})
+
+	t.Run("TEST_CATEGORY and TEST_NAME_PATTERN", func(t *testing.T) {
+		t.Setenv("TEST_CATEGORY", string(CategoryBuild))
+		t.Setenv("TEST_NAME_PATTERN", "container")
+
+		listed := listTests(newTestFilters())
+		if len(listed) != 1 || listed[0].Name != "forge build container" {
+			t.Fatalf("listTests() = %+v, want only %q", listed, "forge build container")
+		}
+		if !listed[0].Parallel || len(listed[0].Tags) == 0 {
+			t.Errorf("listed test = %+v, want its parallel flag and tags", listed[0])
+		}
+	})
+}
+
+func TestWriteTestList(t *testing.T) {
+	tests := []listedTest{
+		{Name: "forge build", Category: CategoryBuild, Parallel: true},
+		{Name: "forge build container", Category: CategoryBuild, Skip: true, SkipReason: "CONTAINER_ENGINE not available", Tags: []string{"container", "slow"}},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeTestList(&buf, tests, "json"); err != nil {
+			t.Fatalf("writeTestList() error = %v", err)
+		}
+
+		var got []listedTest
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+		}
+		if len(got) != 2 || got[1].SkipReason != "CONTAINER_ENGINE not available" || !got[0].Parallel {
+			t.Errorf("decoded list = %+v, want the listed tests", got)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeTestList(&buf, tests, ""); err != nil {
+			t.Fatalf("writeTestList() error = %v", err)
+		}
+
+		out := buf.String()
+		for _, want := range []string{"NAME", "forge build container", "true (CONTAINER_ENGINE not available)", "container,slow"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("table output missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if err := writeTestList(&bytes.Buffer{}, tests, "xml"); err == nil {
+			t.Error("writeTestList() expected an error for an unsupported format")
+		}
+	})
+}
+
+func TestParseListArgs(t *testing.T) {
+	tags, format, err := parseListArgs([]string{"--tag", "slow", "-o", "json"})
+	if err != nil {
+		t.Fatalf("parseListArgs() error = %v", err)
+	}
+	if format != "json" || len(tags) != 1 {
+		t.Errorf("parseListArgs() = (%v, %q), want one tag filter and json", tags, format)
+	}
+
+	if _, format, err := parseListArgs([]string{"--output=json"}); err != nil || format != "json" {
+		t.Errorf("parseListArgs(--output=json) = (%q, %v), want json", format, err)
+	}
+	if _, _, err := parseListArgs([]string{"-o"}); err == nil {
+		t.Error("parseListArgs(-o) expected an error for a missing value")
+	}
+	if _, _, err := parseListArgs([]string{"forge build"}); err == nil {
+		t.Error("parseListArgs() expected an error for an unexpected argument")
+	}
+}
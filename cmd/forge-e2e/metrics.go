@@ -0,0 +1,129 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// prometheusMetric describes a single metric family written to the .prom file.
+type prometheusMetric struct {
+	name  string
+	help  string
+	value func(CategoryStats) float64
+}
+
+// prometheusMetrics lists the per-category metric families emitted after a run.
+var prometheusMetrics = []prometheusMetric{
+	{
+		name:  "test_total",
+		help:  "Total number of e2e tests run per category.",
+		value: func(s CategoryStats) float64 { return float64(s.Total) },
+	},
+	{
+		name:  "test_passed",
+		help:  "Number of passed e2e tests per category.",
+		value: func(s CategoryStats) float64 { return float64(s.Passed) },
+	},
+	{
+		name:  "test_failed",
+		help:  "Number of failed e2e tests per category.",
+		value: func(s CategoryStats) float64 { return float64(s.Failed) },
+	},
+	{
+		name:  "test_duration_seconds",
+		help:  "Cumulative duration of e2e tests per category in seconds.",
+		value: func(s CategoryStats) float64 { return s.Duration },
+	},
+}
+
+// writePrometheusMetrics writes the report's per-category metrics to path in the
+// Prometheus text exposition format. The file is written to a temporary file and
+// renamed so that scrapers (e.g. node_exporter's textfile collector) never read
+// a partially written file.
+func writePrometheusMetrics(path, stage string, report *DetailedTestReport) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".forge-e2e-metrics-*.prom")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary metrics file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := formatPrometheusMetrics(tmp, stage, report); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close metrics file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set metrics file permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move metrics file into place: %w", err)
+	}
+	return nil
+}
+
+// emitPrometheusMetrics writes the report's metrics to path when set. A write failure
+// is reported but does not change the outcome of the run.
+func emitPrometheusMetrics(path, stage string, report *DetailedTestReport) {
+	if path == "" {
+		return
+	}
+	if err := writePrometheusMetrics(path, stage, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write metrics file %s: %v\n", path, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Wrote metrics to %s\n", path)
+}
+
+// formatPrometheusMetrics writes one series per category for each metric family.
+// Categories are sorted so the output is stable across runs.
+func formatPrometheusMetrics(w io.Writer, stage string, report *DetailedTestReport) error {
+	categories := make([]TestCategory, 0, len(report.Categories))
+	for category := range report.Categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+
+	for _, metric := range prometheusMetrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name); err != nil {
+			return err
+		}
+		for _, category := range categories {
+			stats := report.Categories[category]
+			if _, err := fmt.Fprintf(w, "%s{stage=\"%s\",category=\"%s\"} %g\n",
+				metric.name, escapeLabelValue(stage), escapeLabelValue(string(category)), metric.value(stats)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// escapeLabelValue escapes a label value as required by the text exposition format.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
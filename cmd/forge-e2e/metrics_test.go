@@ -0,0 +1,143 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func syntheticDetailedReport() *DetailedTestReport {
+	results := []TestResult{
+		{Name: "forge build", Category: CategoryBuild, Status: "passed", Duration: 1.5},
+		{Name: "forge build format", Category: CategoryBuild, Status: "failed", Duration: 0.5, Error: "boom"},
+		{Name: "testenv create", Category: CategoryTestEnv, Status: "passed", Duration: 3},
+		{Name: "mcp run", Category: CategoryMCP, Status: "skipped"},
+	}
+	return &DetailedTestReport{
+		TestReport: TestReport{Status: "failed", Total: 4, Passed: 2, Failed: 1, Skipped: 1, Duration: 5},
+		Results:    results,
+		Categories: computeStatistics(results),
+	}
+}
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics", "forge-e2e.prom")
+
+	if err := writePrometheusMetrics(path, "e2e", syntheticDetailedReport()); err != nil {
+		t.Fatalf("writePrometheusMetrics() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read metrics file: %v", err)
+	}
+	content := string(data)
+
+	expected := []string{
+		"# TYPE test_total gauge",
+		"# TYPE test_passed gauge",
+		"# TYPE test_failed gauge",
+		"# TYPE test_duration_seconds gauge",
+		`test_total{stage="e2e",category="build"} 2`,
+		`test_passed{stage="e2e",category="build"} 1`,
+		`test_failed{stage="e2e",category="build"} 1`,
+		`test_duration_seconds{stage="e2e",category="build"} 2`,
+		`test_total{stage="e2e",category="testenv"} 1`,
+		`test_duration_seconds{stage="e2e",category="testenv"} 3`,
+		`test_total{stage="e2e",category="mcp"} 1`,
+		`test_passed{stage="e2e",category="mcp"} 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(content, line+"\n") {
+			t.Errorf("Expected metrics file to contain %q, got:\n%s", line, content)
+		}
+	}
+
+	// No temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Failed to read metrics directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the metrics file in directory, got %d entries", len(entries))
+	}
+}
+
+func TestFormatPrometheusMetrics_StableOrder(t *testing.T) {
+	var first, second strings.Builder
+	report := syntheticDetailedReport()
+
+	if err := formatPrometheusMetrics(&first, "e2e", report); err != nil {
+		t.Fatalf("formatPrometheusMetrics() error = %v", err)
+	}
+	if err := formatPrometheusMetrics(&second, "e2e", report); err != nil {
+		t.Fatalf("formatPrometheusMetrics() error = %v", err)
+	}
+	if first.String() != second.String() {
+		t.Error("Expected metrics output to be deterministic")
+	}
+	if strings.Index(first.String(), `category="build"`) > strings.Index(first.String(), `category="testenv"`) {
+		t.Error("Expected categories to be sorted")
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	got := escapeLabelValue("a\"b\\c\nd")
+	want := `a\"b\\c\nd`
+	if got != want {
+		t.Errorf("escapeLabelValue() = %q, want %q", got, want)
+	}
+}
+
+func TestMetricsFilePath(t *testing.T) {
+	t.Setenv("TEST_METRICS_FILE", "")
+	if got := metricsFilePath(&Spec{}); got != "" {
+		t.Errorf("Expected metrics to be disabled by default, got %q", got)
+	}
+
+	t.Setenv("TEST_METRICS_FILE", "/tmp/env.prom")
+	if got := metricsFilePath(&Spec{}); got != "/tmp/env.prom" {
+		t.Errorf("Expected env path, got %q", got)
+	}
+	if got := metricsFilePath(&Spec{MetricsFile: "/tmp/spec.prom"}); got != "/tmp/spec.prom" {
+		t.Errorf("Expected spec path to take precedence, got %q", got)
+	}
+}
+
+func TestRunOutputs_EmitWritesReportAndMetrics(t *testing.T) {
+	dir := t.TempDir()
+	outputs := runOutputs{
+		reportPath:  filepath.Join(dir, "report.json"),
+		metricsPath: filepath.Join(dir, "e2e.prom"),
+	}
+
+	outputs.emit(syntheticDetailedReport())
+
+	if _, err := os.Stat(outputs.reportPath); err != nil {
+		t.Errorf("Expected report file to be written: %v", err)
+	}
+	content, err := os.ReadFile(outputs.metricsPath)
+	if err != nil {
+		t.Fatalf("Expected metrics file to be written: %v", err)
+	}
+	if want := `test_total{stage="e2e",category="build"} 2`; !strings.Contains(string(content), want) {
+		t.Errorf("Expected metrics file to contain %q, got:\n%s", want, content)
+	}
+}
@@ -0,0 +1,136 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timedTests returns parallel tests that record when they run and sleep for d.
+func timedTests(n int, d time.Duration) ([]Test, func() [][2]time.Time) {
+	var mu sync.Mutex
+	var intervals [][2]time.Time
+
+	tests := make([]Test, 0, n)
+	for i := 0; i < n; i++ {
+		tests = append(tests, Test{
+			Name:     fmt.Sprintf("timed test %d", i),
+			Category: CategorySystem,
+			Parallel: true,
+			Run: func(*TestSuite) error {
+				start := time.Now()
+				time.Sleep(d)
+				end := time.Now()
+
+				mu.Lock()
+				intervals = append(intervals, [2]time.Time{start, end})
+				mu.Unlock()
+				return nil
+			},
+		})
+	}
+
+	return tests, func() [][2]time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([][2]time.Time(nil), intervals...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i][0].Before(sorted[j][0]) })
+		return sorted
+	}
+}
+
+func TestRunTestsParallel_ParallelismOneDoesNotOverlap(t *testing.T) {
+	t.Setenv(parallelismEnvVar, "1")
+
+	tests, intervals := timedTests(4, 20*time.Millisecond)
+	suite := &TestSuite{results: make([]TestResult, 0)}
+	suite.runTestsParallel(tests, &testReporter{writer: io.Discard})
+
+	got := intervals()
+	if len(got) != len(tests) {
+		t.Fatalf("%d tests ran, want %d", len(got), len(tests))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i][0].Before(got[i-1][1]) {
+			t.Errorf("test started at %v before the previous test ended at %v", got[i][0], got[i-1][1])
+		}
+	}
+
+	// Results are still all recorded
+	if len(suite.results) != len(tests) {
+		t.Errorf("recorded %d results, want %d", len(suite.results), len(tests))
+	}
+	for _, result := range suite.results {
+		if result.Status != "passed" {
+			t.Errorf("result %s = %s, want passed", result.Name, result.Status)
+		}
+	}
+}
+
+func TestRunTestsParallel_CapsConcurrency(t *testing.T) {
+	t.Setenv(parallelismEnvVar, "2")
+
+	tests, intervals := timedTests(6, 30*time.Millisecond)
+	suite := &TestSuite{results: make([]TestResult, 0)}
+	suite.runTestsParallel(tests, &testReporter{writer: io.Discard})
+
+	got := intervals()
+	maxRunning := 0
+	for _, interval := range got {
+		running := 0
+		for _, other := range got {
+			if !other[0].After(interval[0]) && other[1].After(interval[0]) {
+				running++
+			}
+		}
+		maxRunning = max(maxRunning, running)
+	}
+	if maxRunning > 2 {
+		t.Errorf("%d tests ran at the same time, want at most 2", maxRunning)
+	}
+	if len(suite.results) != len(tests) {
+		t.Errorf("recorded %d results, want %d", len(suite.results), len(tests))
+	}
+}
+
+func TestTestParallelism(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: runtime.NumCPU()},
+		{value: "3", want: 3},
+		{value: "0", want: runtime.NumCPU()},
+		{value: "-1", want: runtime.NumCPU()},
+		{value: "many", want: runtime.NumCPU()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(parallelismEnvVar, tt.value)
+			if got := testParallelism(); got != tt.want {
+				t.Errorf("testParallelism() with %q = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// reportFileEnvVar sets the path of the JSON report when --report is not given.
+const reportFileEnvVar = "FORGE_E2E_REPORT"
+
+// reportFilePath returns the configured JSON report path. The --report flag takes
+// precedence over FORGE_E2E_REPORT; an empty path disables the report.
+func reportFilePath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv(reportFileEnvVar)
+}
+
+// setupFailedReport returns the report of a run that could not start its tests.
+func setupFailedReport(err error) *DetailedTestReport {
+	return &DetailedTestReport{
+		TestReport: TestReport{
+			Status:       "failed",
+			ErrorMessage: fmt.Sprintf("Setup failed: %v", err),
+			Duration:     0,
+			Total:        0,
+			Passed:       0,
+			Failed:       1,
+			Skipped:      0,
+		},
+	}
+}
+
+// marshalJSONReport serializes the full report, including per-test results and category stats.
+// Missing results and categories are serialized as empty collections rather than null.
+func marshalJSONReport(report *DetailedTestReport) ([]byte, error) {
+	out := *report
+	if out.Results == nil {
+		out.Results = []TestResult{}
+	}
+	if out.Categories == nil {
+		out.Categories = map[TestCategory]CategoryStats{}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// writeJSONReport writes the report as JSON to path. Like the metrics file, it is
+// written to a temporary file and renamed so that readers never see a partial report.
+func writeJSONReport(path string, report *DetailedTestReport) error {
+	data, err := marshalJSONReport(report)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".forge-e2e-report-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary report file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close report file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set report file permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move report file into place: %w", err)
+	}
+	return nil
+}
+
+// emitJSONReport writes the report to path when set. A write failure is reported
+// but does not change the outcome of the run.
+func emitJSONReport(path string, report *DetailedTestReport) {
+	if path == "" {
+		return
+	}
+	if err := writeJSONReport(path, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write report file %s: %v\n", path, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Wrote report to %s\n", path)
+}
@@ -0,0 +1,109 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSONReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "e2e.json")
+
+	if err := writeJSONReport(path, syntheticDetailedReport()); err != nil {
+		t.Fatalf("writeJSONReport() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, data)
+	}
+	for _, field := range []string{"status", "duration", "total", "passed", "failed", "skipped", "results", "categories"} {
+		if _, ok := raw[field]; !ok {
+			t.Errorf("report is missing required field %q", field)
+		}
+	}
+
+	var got DetailedTestReport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if got.Status != "failed" || got.Total != 4 || got.Passed != 2 || got.Failed != 1 || got.Skipped != 1 {
+		t.Errorf("report totals = %+v, want the run totals", got.TestReport)
+	}
+	if len(got.Results) != 4 {
+		t.Fatalf("report has %d results, want 4", len(got.Results))
+	}
+	failed := got.Results[1]
+	if failed.Name != "forge build format" || failed.Category != CategoryBuild || failed.Status != "failed" || failed.Error != "boom" {
+		t.Errorf("failed result = %+v, want forge build format failing with boom", failed)
+	}
+	if stats := got.Categories[CategoryBuild]; stats.Total != 2 || stats.Passed != 1 || stats.Failed != 1 || stats.Duration != 2 {
+		t.Errorf("build category stats = %+v, want 2 total, 1 passed, 1 failed, 2s", stats)
+	}
+}
+
+func TestWriteJSONReport_SetupFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "e2e.json")
+
+	if err := writeJSONReport(path, setupFailedReport(errors.New("kind not found"))); err != nil {
+		t.Fatalf("writeJSONReport() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, data)
+	}
+	if raw["status"] != "failed" || raw["error"] != "Setup failed: kind not found" {
+		t.Errorf("report status = %v, error = %v, want the setup failure", raw["status"], raw["error"])
+	}
+	if results, ok := raw["results"].([]any); !ok || len(results) != 0 {
+		t.Errorf("report results = %v, want an empty list", raw["results"])
+	}
+	if categories, ok := raw["categories"].(map[string]any); !ok || len(categories) != 0 {
+		t.Errorf("report categories = %v, want an empty object", raw["categories"])
+	}
+}
+
+func TestReportFilePath(t *testing.T) {
+	t.Setenv(reportFileEnvVar, "env.json")
+
+	if got := reportFilePath("flag.json"); got != "flag.json" {
+		t.Errorf("reportFilePath(flag) = %q, want the flag to take precedence", got)
+	}
+	if got := reportFilePath(""); got != "env.json" {
+		t.Errorf("reportFilePath() = %q, want %s", got, reportFileEnvVar)
+	}
+
+	t.Setenv(reportFileEnvVar, "")
+	if got := reportFilePath(""); got != "" {
+		t.Errorf("reportFilePath() = %q, want the report disabled", got)
+	}
+}
@@ -0,0 +1,111 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// flakyTest returns a test that fails the first failures times it runs, then succeeds.
+func flakyTest(failures, retries int) (Test, *int) {
+	calls := 0
+	return Test{
+		Name:     "flaky test",
+		Category: CategoryTestEnv,
+		Retries:  retries,
+		Run: func(*TestSuite) error {
+			calls++
+			if calls <= failures {
+				return errors.New("transient failure")
+			}
+			return nil
+		},
+	}, &calls
+}
+
+func TestExecuteTest_RetriesFlakyTest(t *testing.T) {
+	test, calls := flakyTest(1, 2)
+	executor := &testExecutor{suite: &TestSuite{}}
+
+	result := executor.executeTest(test)
+
+	if result.Status != "passed" {
+		t.Fatalf("status = %q, want passed (error: %s)", result.Status, result.Error)
+	}
+	if result.Retries != 1 {
+		t.Errorf("retries = %d, want 1", result.Retries)
+	}
+	if *calls != 2 {
+		t.Errorf("test ran %d times, want 2", *calls)
+	}
+}
+
+func TestExecuteTest_NoRetriesByDefault(t *testing.T) {
+	test, calls := flakyTest(1, 0)
+	executor := &testExecutor{suite: &TestSuite{}}
+
+	result := executor.executeTest(test)
+
+	if result.Status != "failed" {
+		t.Fatalf("status = %q, want failed", result.Status)
+	}
+	if result.Retries != 0 {
+		t.Errorf("retries = %d, want 0", result.Retries)
+	}
+	if *calls != 1 {
+		t.Errorf("test ran %d times, want 1", *calls)
+	}
+}
+
+func TestExecuteTest_FailsWhenRetriesExhausted(t *testing.T) {
+	test, calls := flakyTest(3, 2)
+	executor := &testExecutor{suite: &TestSuite{}}
+
+	result := executor.executeTest(test)
+
+	if result.Status != "failed" {
+		t.Fatalf("status = %q, want failed", result.Status)
+	}
+	if result.Error != "transient failure" {
+		t.Errorf("error = %q, want %q", result.Error, "transient failure")
+	}
+	if result.Retries != 2 {
+		t.Errorf("retries = %d, want 2", result.Retries)
+	}
+	if *calls != 3 {
+		t.Errorf("test ran %d times, want 3", *calls)
+	}
+}
+
+func TestGenerateReport_CountsPassedAfterRetry(t *testing.T) {
+	suite := &TestSuite{results: []TestResult{
+		{Name: "a", Category: CategoryBuild, Status: "passed"},
+		{Name: "b", Category: CategoryTestEnv, Status: "passed", Retries: 1},
+		{Name: "c", Category: CategoryTestEnv, Status: "failed", Retries: 2, Error: "boom"},
+	}}
+
+	report := suite.generateReport(1, &testReporter{writer: io.Discard})
+
+	if report.Passed != 2 {
+		t.Errorf("passed = %d, want 2", report.Passed)
+	}
+	if report.PassedAfterRetry != 1 {
+		t.Errorf("passedAfterRetry = %d, want 1", report.PassedAfterRetry)
+	}
+}
@@ -17,6 +17,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"time"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
@@ -24,12 +25,18 @@ import (
 )
 
 // Run implements the TestRunnerFunc for running e2e tests.
-func Run(ctx context.Context, input mcptypes.RunInput, _ *Spec) (*forge.TestReport, error) {
+func Run(ctx context.Context, input mcptypes.RunInput, spec *Spec) (*forge.TestReport, error) {
 	log.Printf("Running e2e tests: stage=%s, name=%s", input.Stage, input.Name)
 
 	// Run the actual tests
 	detailedReport := runTests(input.Stage, input.Name)
 
+	// Emit Prometheus metrics when requested; a write failure does not fail the run
+	emitPrometheusMetrics(metricsFilePath(spec), input.Stage, detailedReport)
+
+	// Write the full JSON report for CI ingestion when requested
+	emitJSONReport(reportFilePath(""), detailedReport)
+
 	// Convert DetailedTestReport to forge.TestReport
 	duration := time.Duration(detailedReport.Duration * float64(time.Second))
 	forgeReport := &forge.TestReport{
@@ -54,3 +61,13 @@ func Run(ctx context.Context, input mcptypes.RunInput, _ *Spec) (*forge.TestRepo
 	// Status field indicates pass/fail, error is only for execution failures
 	return forgeReport, nil
 }
+
+// metricsFilePath returns the configured metrics file path. The spec field takes
+// precedence over the TEST_METRICS_FILE environment variable. An empty result
+// disables metrics output.
+func metricsFilePath(spec *Spec) string {
+	if spec != nil && spec.MetricsFile != "" {
+		return spec.MetricsFile
+	}
+	return os.Getenv("TEST_METRICS_FILE")
+}
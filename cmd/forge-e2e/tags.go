@@ -0,0 +1,94 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+)
+
+// tagFilter selects tests by tag. It is a disjunction (OR) of groups, each group
+// being a conjunction (AND) of terms. A term is a tag the test must have, or a
+// tag prefixed with "!" the test must not have.
+//
+// Syntax: "," separates alternatives and "+" joins required tags, so
+// "slow+network,destructive" selects tests tagged both slow and network, or tagged destructive.
+type tagFilter [][]string
+
+// parseTagFilter parses a tag filter expression. Empty terms are ignored and an
+// empty expression yields a filter that matches every test.
+func parseTagFilter(expr string) tagFilter {
+	var filter tagFilter
+	for _, alternative := range strings.Split(expr, ",") {
+		var group []string
+		for _, term := range strings.Split(alternative, "+") {
+			term = strings.TrimSpace(term)
+			if term == "" || term == "!" {
+				continue
+			}
+			group = append(group, term)
+		}
+		if len(group) > 0 {
+			filter = append(filter, group)
+		}
+	}
+	return filter
+}
+
+// or returns a filter matching tests selected by either f or other.
+func (f tagFilter) or(other tagFilter) tagFilter {
+	return append(append(tagFilter{}, f...), other...)
+}
+
+// matches reports whether a test with the given tags is selected by the filter.
+func (f tagFilter) matches(tags []string) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	has := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		has[tag] = true
+	}
+
+	for _, group := range f {
+		if groupMatches(group, has) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupMatches reports whether every term of an AND group holds.
+func groupMatches(group []string, has map[string]bool) bool {
+	for _, term := range group {
+		if excluded, ok := strings.CutPrefix(term, "!"); ok {
+			if has[excluded] {
+				return false
+			}
+		} else if !has[term] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the filter in its expression syntax.
+func (f tagFilter) String() string {
+	alternatives := make([]string, 0, len(f))
+	for _, group := range f {
+		alternatives = append(alternatives, strings.Join(group, "+"))
+	}
+	return strings.Join(alternatives, ",")
+}
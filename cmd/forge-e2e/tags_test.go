@@ -0,0 +1,138 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want tagFilter
+	}{
+		{expr: "", want: nil},
+		{expr: "slow", want: tagFilter{{"slow"}}},
+		{expr: "slow,network", want: tagFilter{{"slow"}, {"network"}}},
+		{expr: "slow+network", want: tagFilter{{"slow", "network"}}},
+		{expr: " slow + kind , !destructive ", want: tagFilter{{"slow", "kind"}, {"!destructive"}}},
+		{expr: "slow,,+", want: tagFilter{{"slow"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got := parseTagFilter(tt.expr)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTagFilter(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagFilterMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		tags []string
+		want bool
+	}{
+		{name: "empty filter matches untagged", expr: "", tags: nil, want: true},
+		{name: "single tag match", expr: "slow", tags: []string{"slow", "kind"}, want: true},
+		{name: "single tag miss", expr: "slow", tags: []string{"kind"}, want: false},
+		{name: "untagged test excluded by filter", expr: "slow", tags: nil, want: false},
+		{name: "OR matches either", expr: "slow,network", tags: []string{"network"}, want: true},
+		{name: "OR matches none", expr: "slow,network", tags: []string{"kind"}, want: false},
+		{name: "AND requires all", expr: "slow+kind", tags: []string{"slow"}, want: false},
+		{name: "AND matches all", expr: "slow+kind", tags: []string{"kind", "slow"}, want: true},
+		{name: "negation excludes", expr: "!destructive", tags: []string{"destructive"}, want: false},
+		{name: "negation keeps untagged", expr: "!destructive", tags: nil, want: true},
+		{name: "AND with negation", expr: "slow+!kind", tags: []string{"slow", "kind"}, want: false},
+		{name: "mixed AND/OR", expr: "slow+kind,destructive", tags: []string{"destructive"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTagFilter(tt.expr).matches(tt.tags); got != tt.want {
+				t.Errorf("parseTagFilter(%q).matches(%v) = %v, want %v", tt.expr, tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestFilters_Tags(t *testing.T) {
+	t.Setenv("TEST_CATEGORY", "")
+	t.Setenv("TEST_NAME_PATTERN", "")
+	t.Setenv("TEST_TAGS", "slow+kind")
+
+	suite := NewTestSuite()
+	suite.AddTest(Test{Name: "kind slow", Category: CategoryTestRunner, Tags: []string{"kind", "slow"}})
+	suite.AddTest(Test{Name: "kind only", Category: CategoryError, Tags: []string{"kind"}})
+	suite.AddTest(Test{Name: "untagged", Category: CategoryBuild})
+
+	if len(suite.tests) != 1 || suite.tests[0].Name != "kind slow" {
+		t.Errorf("Expected only 'kind slow' to be selected, got %+v", suite.tests)
+	}
+}
+
+func TestParseRunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantName    string
+		wantTags    tagFilter
+		wantReport  string
+		wantMetrics string
+		wantErr     bool
+	}{
+		{name: "exact name", args: []string{"forge build"}, wantName: "forge build"},
+		{name: "tag flag", args: []string{"--tag", "slow"}, wantTags: tagFilter{{"slow"}}},
+		{name: "tag flags are ORed", args: []string{"--tag", "slow+kind", "--tag=destructive"}, wantTags: tagFilter{{"slow", "kind"}, {"destructive"}}},
+		{name: "report flag", args: []string{"forge build", "--report", "out/report.json"}, wantName: "forge build", wantReport: "out/report.json"},
+		{name: "report flag with equals", args: []string{"--tag=slow", "--report=report.json"}, wantTags: tagFilter{{"slow"}}, wantReport: "report.json"},
+		{name: "metrics flag", args: []string{"forge build", "--metrics", "out/e2e.prom"}, wantName: "forge build", wantMetrics: "out/e2e.prom"},
+		{name: "metrics flag with equals", args: []string{"--tag=slow", "--metrics=e2e.prom", "--report=report.json"}, wantTags: tagFilter{{"slow"}}, wantReport: "report.json", wantMetrics: "e2e.prom"},
+		{name: "missing metrics value", args: []string{"forge build", "--metrics"}, wantErr: true},
+		{name: "empty metrics value", args: []string{"forge build", "--metrics="}, wantErr: true},
+		{name: "missing report value", args: []string{"forge build", "--report"}, wantErr: true},
+		{name: "empty report value", args: []string{"forge build", "--report="}, wantErr: true},
+		{name: "name and tag", args: []string{"forge build", "--tag", "slow"}, wantErr: true},
+		{name: "missing tag value", args: []string{"--tag"}, wantErr: true},
+		{name: "two names", args: []string{"a", "b"}, wantErr: true},
+		{name: "no arguments", args: nil, wantErr: true},
+		{name: "unknown flag", args: []string{"--nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRunArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRunArgs() error = %v", err)
+			}
+			want := runArgs{name: tt.wantName, tags: tt.wantTags, report: tt.wantReport, metrics: tt.wantMetrics}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("parseRunArgs() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
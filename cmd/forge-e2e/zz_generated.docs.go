@@ -1,20 +1,28 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml
-// SourceChecksum: sha256:c0619ca0baa98fb2b1070a1f981604dfb7b19c4dc20d9e270002498df5d314db
+// SourceChecksum: sha256:e85cd052078af8112ea0de190c0da8425a613cfc92c8d5ccaeacf54aba1d16e7
 
 package main
 
 import (
+	"embed"
+
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for documentation tools.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "forge-e2e",
 	LocalDir:     "cmd/forge-e2e/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 // RegisterDocsMCPTools registers the docs-list and docs-get MCP tools.
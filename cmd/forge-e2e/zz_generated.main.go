@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml + spec.openapi.yaml
-// SourceChecksum: sha256:c0619ca0baa98fb2b1070a1f981604dfb7b19c4dc20d9e270002498df5d314db
+// SourceChecksum: sha256:e85cd052078af8112ea0de190c0da8425a613cfc92c8d5ccaeacf54aba1d16e7
 
 package main
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
@@ -32,29 +33,40 @@ func main() {
 		Version:        Version,
 		CommitSHA:      CommitSHA,
 		BuildTimestamp: BuildTimestamp,
-		RunCLI:         nil, // Generated engines are MCP-only
+		RunCLI:         runCLI,
 		RunMCP:         runMCPServer,
 		DocsConfig:     docsConfig,
+		SetupMCP:       setupMCPServer,
 	})
 }
 
 // runMCPServer creates and runs the MCP server.
 func runMCPServer() error {
+	server, err := setupMCPServer()
+	if err != nil {
+		return err
+	}
+
+	if err := server.Run(enginecli.Context()); err != nil {
+		return fmt.Errorf("running MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// setupMCPServer creates the MCP server and registers all its tools.
+func setupMCPServer() (*mcpserver.Server, error) {
 	server, err := SetupMCPServer(Name, Version, Run)
 	if err != nil {
-		return fmt.Errorf("setting up MCP server: %w", err)
+		return nil, fmt.Errorf("setting up MCP server: %w", err)
 	}
 
 	// Register docs MCP tools (docs-list, docs-get)
 	if err := RegisterDocsMCPTools(server); err != nil {
-		return fmt.Errorf("registering docs MCP tools: %w", err)
-	}
-
-	if err := server.Run(context.Background()); err != nil {
-		return fmt.Errorf("running MCP server: %w", err)
+		return nil, fmt.Errorf("registering docs MCP tools: %w", err)
 	}
 
-	return nil
+	return server, nil
 }
 
 // Run is the run function that must be implemented by the engine author.
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:c0619ca0baa98fb2b1070a1f981604dfb7b19c4dc20d9e270002498df5d314db
+// SourceChecksum: sha256:e85cd052078af8112ea0de190c0da8425a613cfc92c8d5ccaeacf54aba1d16e7
 
 package main
 
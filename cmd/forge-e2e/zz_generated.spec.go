@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:c0619ca0baa98fb2b1070a1f981604dfb7b19c4dc20d9e270002498df5d314db
+// SourceChecksum: sha256:e85cd052078af8112ea0de190c0da8425a613cfc92c8d5ccaeacf54aba1d16e7
 
 package main
 
@@ -13,6 +13,8 @@ import (
 type Spec struct {
 	// Filter tests by category (build, testenv, test-runner, prompt, system, error, cleanup, mcp, performance, artifact-store)
 	Category string `json:"category,omitempty"`
+	// Path of a Prometheus text-format (.prom) file to write run metrics to after the run (disabled when empty)
+	MetricsFile string `json:"metricsFile,omitempty"`
 	// Filter tests by name pattern (case-insensitive substring match)
 	NamePattern string `json:"namePattern,omitempty"`
 }
@@ -32,6 +34,14 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field category: expected string, got %T", v)
 		}
 	}
+	// Parse metricsFile
+	if v, ok := m["metricsFile"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.MetricsFile = val
+		} else {
+			return nil, fmt.Errorf("field metricsFile: expected string, got %T", v)
+		}
+	}
 	// Parse namePattern
 	if v, ok := m["namePattern"]; ok && v != nil {
 		if val, ok := v.(string); ok {
@@ -53,6 +63,9 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if s.Category != "" {
 		m["category"] = s.Category
 	}
+	if s.MetricsFile != "" {
+		m["metricsFile"] = s.MetricsFile
+	}
 	if s.NamePattern != "" {
 		m["namePattern"] = s.NamePattern
 	}
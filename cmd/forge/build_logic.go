@@ -16,6 +16,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -136,6 +137,9 @@ func buildAll(artifactName string, forceRebuild bool) (*BuildAllResult, error) {
 		if len(spec.Spec) > 0 {
 			params["spec"] = spec.Spec
 		}
+		if len(spec.Labels) > 0 {
+			params["labels"] = spec.Labels
+		}
 
 		engineSpecs[engine] = append(engineSpecs[engine], params)
 	}
@@ -209,7 +213,9 @@ func buildAll(artifactName string, forceRebuild bool) (*BuildAllResult, error) {
 				artifacts, err = buildWithSingleEngine(command, args, specs, dirs, engineConfig, forceRebuild)
 				if err != nil {
 					result.BuildErrors = append(result.BuildErrors, fmt.Sprintf("build failed for %s: %v", engineURI, err))
-					continue
+					if len(artifacts) == 0 {
+						continue
+					}
 				}
 			}
 		} else {
@@ -223,20 +229,25 @@ func buildAll(artifactName string, forceRebuild bool) (*BuildAllResult, error) {
 			artifacts, err = buildWithSingleEngine(command, args, specs, dirs, nil, forceRebuild)
 			if err != nil {
 				result.BuildErrors = append(result.BuildErrors, fmt.Sprintf("build failed for %s: %v", engineURI, err))
-				continue
+				if len(artifacts) == 0 {
+					continue
+				}
 			}
 		}
 
-		// Update artifact store
 		for _, artifact := range artifacts {
-			forge.AddOrUpdateArtifact(&store, artifact)
 			result.Artifacts = append(result.Artifacts, artifact)
 			result.TotalBuilt++
 		}
 	}
 
-	// Write updated artifact store
-	if err := forge.WriteArtifactStore(config.ArtifactStorePath, store); err != nil {
+	// Record the built artifacts in the artifact store, as it is now on disk
+	if err := forge.UpdateArtifactStore(config.ArtifactStorePath, func(store *forge.ArtifactStore) error {
+		for _, artifact := range result.Artifacts {
+			forge.AddOrUpdateArtifact(store, artifact)
+		}
+		return nil
+	}); err != nil {
 		return nil, fmt.Errorf("failed to write artifact store: %w", err)
 	}
 
@@ -359,6 +370,7 @@ func shouldRebuild(artifactName string, store forge.ArtifactStore, forceRebuild
 }
 
 // buildWithSingleEngine handles building with a single engine (either direct go:// URI or single-engine alias).
+// If only some builds of a batch failed, it returns the artifacts of the others along with the error.
 func buildWithSingleEngine(
 	command string,
 	args []string,
@@ -429,6 +441,13 @@ func buildWithSingleEngine(
 	}
 
 	if err != nil {
+		// Keep the artifacts of the builds that succeeded in a partially failed batch
+		var tErr *toolError
+		if errors.As(err, &tErr) && tErr.structuredContent != nil {
+			if artifacts, parseErr := parseArtifacts(tErr.structuredContent); parseErr == nil && len(artifacts) > 0 {
+				return artifacts, err
+			}
+		}
 		return nil, err
 	}
 
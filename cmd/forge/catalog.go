@@ -0,0 +1,141 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/enginecatalog"
+	"github.com/alexandremahdhaoui/forge/internal/forgepath"
+)
+
+// catalogQueryTimeout bounds the time spent starting and querying a single engine.
+const catalogQueryTimeout = 2 * time.Minute
+
+// runCatalog handles the "forge catalog" command.
+// It lists every known engine with its type, version and registered MCP tools.
+//
+// Options:
+//   - --no-query: only read engine manifests, do not start the engines
+//   - --format=<fmt> / -o <fmt>: table (default), json, yaml
+func runCatalog(args []string) error {
+	format, remaining := parseOutputFormat(args)
+
+	query := true
+	for _, arg := range remaining {
+		switch arg {
+		case "--no-query":
+			query = false
+		default:
+			return fmt.Errorf("unknown argument: %s\n\nusage: forge catalog [--no-query] [--format=table|json|yaml]", arg)
+		}
+	}
+
+	root, engines, err := discoverCatalogEngines()
+	if err != nil {
+		return err
+	}
+
+	builder := enginecatalog.Builder{Root: root}
+	if query {
+		builder.Query = enginecatalog.NewMCPQuerier(getVersion(), catalogQueryTimeout)
+	}
+
+	catalog := builder.Build(context.Background(), engines)
+	formatCatalogOutput(catalog, format)
+	return nil
+}
+
+// discoverCatalogEngines returns the engines to catalog and the repository root their paths are relative to.
+// When the forge repository is available locally, its engines registry is used, falling back to scanning cmd/.
+// Otherwise, the registry is fetched over HTTP and root is empty (engine manifests are not read).
+func discoverCatalogEngines() (string, []enginecatalog.Engine, error) {
+	if root, err := forgepath.FindForgeRepo(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(root, localEnginesList)); err == nil {
+			if engines, err := enginecatalog.ParseRegistry(data); err == nil {
+				return root, engines, nil
+			}
+		}
+
+		engines, err := enginecatalog.ScanDir(root, "cmd")
+		if err != nil {
+			return "", nil, err
+		}
+		return root, engines, nil
+	}
+
+	store, err := fetchEnginesStore()
+	if err != nil {
+		return "", nil, err
+	}
+
+	engines := make([]enginecatalog.Engine, 0, len(store.Engines))
+	for _, e := range store.Engines {
+		engines = append(engines, enginecatalog.Engine{Name: e.Name, Path: e.Path})
+	}
+	return "", engines, nil
+}
+
+// formatCatalogOutput formats the engine catalog for display.
+func formatCatalogOutput(catalog enginecatalog.Catalog, format outputFormat) {
+	switch format {
+	case outputFormatJSON:
+		printJSON(catalog)
+	case outputFormatYAML:
+		printYAML(catalog)
+	default:
+		if len(catalog.Engines) == 0 {
+			fmt.Println("No engines found.")
+			return
+		}
+
+		nameLen, typeLen, versionLen := len("ENGINE"), len("TYPE"), len("VERSION")
+		for _, e := range catalog.Engines {
+			nameLen = max(nameLen, len(e.Name))
+			typeLen = max(typeLen, len(e.Type))
+			versionLen = max(versionLen, len(e.Version))
+		}
+
+		fmt.Printf("%-*s  %-*s  %-*s  %s\n", nameLen, "ENGINE", typeLen, "TYPE", versionLen, "VERSION", "TOOLS")
+		fmt.Printf("%-*s  %-*s  %-*s  %s\n", nameLen, strings.Repeat("-", nameLen), typeLen, strings.Repeat("-", typeLen),
+			versionLen, strings.Repeat("-", versionLen), "-----")
+
+		failed := 0
+		for _, e := range catalog.Engines {
+			tools := make([]string, 0, len(e.Tools))
+			for _, t := range e.Tools {
+				tools = append(tools, t.Name)
+			}
+
+			toolsCol := strings.Join(tools, ", ")
+			if e.Error != "" {
+				failed++
+				toolsCol = "(query failed)"
+			}
+
+			fmt.Printf("%-*s  %-*s  %-*s  %s\n", nameLen, e.Name, typeLen, e.Type, versionLen, e.Version, toolsCol)
+		}
+
+		fmt.Printf("\nTotal: %d engine(s)\n", len(catalog.Engines))
+		if failed > 0 {
+			fmt.Printf("%d engine(s) could not be queried; use -o yaml to see the errors\n", failed)
+		}
+	}
+}
@@ -80,30 +80,19 @@ func runConfigValidate(args []string) error {
 		return nil
 	}
 
-	// Validate each engine's spec
-	ctx := context.Background()
-	var results []validationResult
-
 	fmt.Printf("Validating configuration: %s\n", cfgPath)
 	fmt.Printf("Project: %s\n", spec.Name)
-	fmt.Printf("Validating %d engine(s)...\n\n", len(engineRefs))
-
-	for _, ref := range engineRefs {
-		fmt.Printf("  Validating %s (%s: %s)...\n", ref.URI, ref.SpecType, ref.SpecName)
-		output, err := validateEngineSpec(ctx, ref, &spec, cfgPath)
-		if err != nil {
-			// Programming error - should not happen
-			return fmt.Errorf("internal error validating engine %s: %v", ref.URI, err)
-		}
-		results = append(results, validationResult{
-			Ref:    ref,
-			Output: output,
+	fmt.Printf("Validating %d engine spec(s)...\n\n", len(engineRefs))
+
+	// Validate each engine's spec, reporting progress as engines may take a while to start
+	combined, err := validateEngineRefs(context.Background(), engineRefs, &spec, cfgPath, validateEngineSpec,
+		func(ref engineReference) {
+			fmt.Printf("  Validating %s (%s: %s)...\n", ref.URI, ref.SpecType, ref.SpecName)
 		})
+	if err != nil {
+		return err
 	}
 
-	// Aggregate all results
-	combined := aggregateResults(results)
-
 	// Print validation summary
 	fmt.Println()
 
@@ -133,7 +122,11 @@ func runConfigValidate(args []string) error {
 	fmt.Fprintf(os.Stderr, "Errors (%d):\n", len(combined.Errors))
 	for _, e := range combined.Errors {
 		// Use the String() method which formats with full path context
-		fmt.Fprintf(os.Stderr, "  - %s\n", e.String())
+		if e.Line > 0 {
+			fmt.Fprintf(os.Stderr, "  - %s:%d: %s\n", cfgPath, e.Line, e.String())
+		} else {
+			fmt.Fprintf(os.Stderr, "  - %s\n", e.String())
+		}
 	}
 
 	// Print warnings if any
@@ -201,24 +194,12 @@ func validateConfig(configPathArg string) *mcptypes.ConfigValidateOutput {
 	}
 
 	// Validate each engine's spec
-	ctx := context.Background()
-	var results []validationResult
-
-	for _, ref := range engineRefs {
-		output, err := validateEngineSpec(ctx, ref, &spec, cfgPath)
-		if err != nil {
-			// Programming error - should not happen
-			return &mcptypes.ConfigValidateOutput{
-				Valid:      false,
-				InfraError: fmt.Sprintf("internal error validating engine %s: %v", ref.URI, err),
-			}
+	combined, err := validateEngineRefs(context.Background(), engineRefs, &spec, cfgPath, validateEngineSpec, nil)
+	if err != nil {
+		return &mcptypes.ConfigValidateOutput{
+			Valid:      false,
+			InfraError: err.Error(),
 		}
-		results = append(results, validationResult{
-			Ref:    ref,
-			Output: output,
-		})
 	}
-
-	// Aggregate all results
-	return aggregateResults(results)
+	return combined
 }
@@ -0,0 +1,153 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+	"gopkg.in/yaml.v3"
+)
+
+// configLineIndex resolves validation error paths to line numbers in forge.yaml.
+type configLineIndex struct {
+	root *yaml.Node
+}
+
+// newConfigLineIndex parses the forge.yaml content into a node tree used to look up line numbers.
+func newConfigLineIndex(data []byte) (*configLineIndex, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	return &configLineIndex{root: root}, nil
+}
+
+// annotateErrorLines sets the Line of every error (including nested errors) by resolving
+// its Path and Field against the config file at cfgPath. Errors are left unchanged if
+// the file cannot be read or parsed.
+func annotateErrorLines(errs []mcptypes.ValidationError, cfgPath string) {
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return
+	}
+	idx, err := newConfigLineIndex(data)
+	if err != nil {
+		return
+	}
+	idx.annotate(errs)
+}
+
+func (idx *configLineIndex) annotate(errs []mcptypes.ValidationError) {
+	for i := range errs {
+		if errs[i].Line == 0 {
+			errs[i].Line = idx.lineOf(errs[i].Path, errs[i].Field)
+		}
+		idx.annotate(errs[i].Nested)
+	}
+}
+
+// lineOf returns the line of the deepest node reachable by following path then field.
+// Path segments address mapping keys, sequence indices, or sequence items by their
+// "name" or "alias". Field is a JSON path such as "spec.charts[0].name".
+// Returns 0 if not even the first path segment can be found.
+func (idx *configLineIndex) lineOf(path []string, field string) int {
+	if idx == nil || idx.root == nil || len(path) == 0 {
+		return 0
+	}
+
+	node := idx.root
+	line := 0
+	for _, segment := range path {
+		next := lookupConfigNode(node, segment)
+		if next == nil {
+			return line
+		}
+		node, line = next, next.Line
+	}
+
+	for _, segment := range splitFieldPath(field) {
+		next := lookupConfigNode(node, segment)
+		if next == nil {
+			// Engines report fields relative to their spec ("spec.args"); the path
+			// may or may not already point at the spec node.
+			if segment == "spec" {
+				continue
+			}
+			break
+		}
+		node, line = next, next.Line
+	}
+
+	return line
+}
+
+// lookupConfigNode returns the child of node addressed by segment, or nil.
+// For mappings, the returned node is the key node so the reported line is the one naming the field.
+func lookupConfigNode(node *yaml.Node, segment string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				value := node.Content[i+1]
+				// Keep the key line for scalars; descend into collections
+				if value.Kind == yaml.ScalarNode {
+					return &yaml.Node{Kind: yaml.ScalarNode, Value: value.Value, Line: node.Content[i].Line}
+				}
+				return &yaml.Node{Kind: value.Kind, Content: value.Content, Line: node.Content[i].Line}
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(segment); err == nil {
+			if i >= 0 && i < len(node.Content) {
+				return node.Content[i]
+			}
+			return nil
+		}
+		for _, item := range node.Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+			for _, key := range []string{"name", "alias"} {
+				if v := lookupConfigNode(item, key); v != nil && v.Value == segment {
+					return item
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// splitFieldPath splits a JSON field path such as "spec.charts[0].name"
+// into segments: ["spec", "charts", "0", "name"].
+func splitFieldPath(field string) []string {
+	field = strings.NewReplacer("[", ".", "]", "").Replace(field)
+
+	var segments []string
+	for _, s := range strings.Split(field, ".") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
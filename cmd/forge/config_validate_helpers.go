@@ -43,19 +43,24 @@ type engineReference struct {
 	Spec map[string]interface{}
 }
 
-// extractEngineURIs extracts all unique engine URIs from a forge.Spec.
+// extractEngineURIs extracts the engine references to validate from a forge.Spec.
 // It iterates over build specs and test specs, extracting engine references
 // for build engines, test runners, and testenv orchestrators.
-// Returns a deduplicated list of engine references (by URI).
+// Build and test references are deduplicated by URI and spec, so every distinct spec
+// is validated even when several entries share an engine. Testenv references are
+// deduplicated by URI since testenv validates the complete forge spec.
 func extractEngineURIs(spec forge.Spec) []engineReference {
-	// Use a map to track seen URIs for deduplication
+	// Use a map to track seen references for deduplication
 	seen := make(map[string]bool)
 	var refs []engineReference
 
 	// Extract from build specs
 	for _, bs := range spec.Build {
-		if bs.Engine != "" && !seen[bs.Engine] {
-			seen[bs.Engine] = true
+		if bs.Engine == "" {
+			continue
+		}
+		if key := engineReferenceKey(bs.Engine, bs.Spec); !seen[key] {
+			seen[key] = true
 			refs = append(refs, engineReference{
 				URI:      bs.Engine,
 				SpecType: "build",
@@ -68,14 +73,16 @@ func extractEngineURIs(spec forge.Spec) []engineReference {
 	// Extract from test specs
 	for _, ts := range spec.Test {
 		// Extract runner URI
-		if ts.Runner != "" && !seen[ts.Runner] {
-			seen[ts.Runner] = true
-			refs = append(refs, engineReference{
-				URI:      ts.Runner,
-				SpecType: "test",
-				SpecName: ts.Name,
-				Spec:     ts.Spec,
-			})
+		if ts.Runner != "" {
+			if key := engineReferenceKey(ts.Runner, ts.Spec); !seen[key] {
+				seen[key] = true
+				refs = append(refs, engineReference{
+					URI:      ts.Runner,
+					SpecType: "test",
+					SpecName: ts.Name,
+					Spec:     ts.Spec,
+				})
+			}
 		}
 
 		// Extract testenv URI if set and not "noop" or empty
@@ -93,6 +100,20 @@ func extractEngineURIs(spec forge.Spec) []engineReference {
 	return refs
 }
 
+// engineReferenceKey returns the deduplication key of an engine reference.
+// References with the same URI and an identical spec only need to be validated once.
+func engineReferenceKey(uri string, spec map[string]interface{}) string {
+	if len(spec) == 0 {
+		return uri
+	}
+	// json.Marshal sorts map keys, so identical specs produce identical keys
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return uri
+	}
+	return uri + "#" + string(b)
+}
+
 // validateEngineSpec validates a single engine's spec by calling its config-validate MCP tool.
 // It parses the engine URI, resolves aliases, prepares the ConfigValidateInput, calls the engine,
 // and parses the result. If the MCP call fails, it returns a ConfigValidateOutput with InfraError set.
@@ -167,6 +188,43 @@ func validateEngineSpec(ctx context.Context, ref engineReference, forgeSpec *for
 	return output, nil
 }
 
+// engineSpecValidator validates the spec of a single engine reference.
+// validateEngineSpec is the implementation calling the engine's config-validate MCP tool.
+type engineSpecValidator func(ctx context.Context, ref engineReference, forgeSpec *forge.Spec, configPath string) (*mcptypes.ConfigValidateOutput, error)
+
+// validateEngineRefs runs the validation pass over every engine reference before anything is run.
+// It does not stop at the first invalid spec: all results are aggregated so that every error is
+// reported at once, and each error is located in the config file (see annotateErrorLines).
+// If progress is not nil, it is called before each engine is validated.
+// Returns an error only if there's a programming error (not validation failures).
+func validateEngineRefs(
+	ctx context.Context,
+	refs []engineReference,
+	forgeSpec *forge.Spec,
+	configPath string,
+	validate engineSpecValidator,
+	progress func(ref engineReference),
+) (*mcptypes.ConfigValidateOutput, error) {
+	results := make([]validationResult, 0, len(refs))
+	for _, ref := range refs {
+		if progress != nil {
+			progress(ref)
+		}
+		output, err := validate(ctx, ref, forgeSpec, configPath)
+		if err != nil {
+			return nil, fmt.Errorf("internal error validating engine %s: %v", ref.URI, err)
+		}
+		results = append(results, validationResult{
+			Ref:    ref,
+			Output: output,
+		})
+	}
+
+	combined := aggregateResults(results)
+	annotateErrorLines(combined.Errors, configPath)
+	return combined, nil
+}
+
 // parseConfigValidateOutput parses the MCP tool result into a ConfigValidateOutput.
 // The result can be either a map[string]any or a struct that needs JSON conversion.
 func parseConfigValidateOutput(result interface{}) (*mcptypes.ConfigValidateOutput, error) {
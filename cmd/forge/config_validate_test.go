@@ -17,6 +17,12 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
@@ -673,3 +679,163 @@ func TestAggregateResults_MultipleErrorsFromSingleEngine(t *testing.T) {
 		}
 	}
 }
+
+// -----------------------------------------------------------------------------
+// Tests for validateEngineRefs
+// -----------------------------------------------------------------------------
+
+const twoInvalidSpecsForgeYAML = `name: test-project
+artifactStorePath: .forge/artifacts.yaml
+envFile: .envrc
+
+build:
+  - name: app1
+    src: ./cmd/app1
+    dest: ./build/bin
+    engine: go://go-build
+    spec:
+      ldflags: 42
+  - name: app2
+    src: ./cmd/app2
+    dest: ./build/bin
+    engine: go://go-build
+    spec:
+      args:
+        - -trimpath
+        - 1
+
+test:
+  - name: unit
+    runner: go://go-test
+    testenv: noop
+`
+
+func TestValidateEngineRefs_ReportsAllErrorsWithLines(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "forge.yaml")
+	if err := os.WriteFile(cfgPath, []byte(twoInvalidSpecsForgeYAML), 0o600); err != nil {
+		t.Fatalf("failed to write forge.yaml: %v", err)
+	}
+
+	spec, err := forge.ReadSpecFromPath(cfgPath)
+	if err != nil {
+		t.Fatalf("ReadSpecFromPath() error = %v", err)
+	}
+
+	// Fake engine schema validation: go-build rejects non-string ldflags and args
+	validate := func(_ context.Context, ref engineReference, _ *forge.Spec, _ string) (*mcptypes.ConfigValidateOutput, error) {
+		var errs []mcptypes.ValidationError
+		if v, ok := ref.Spec["ldflags"]; ok {
+			if _, ok := v.(string); !ok {
+				errs = append(errs, mcptypes.ValidationError{Field: "spec.ldflags", Message: "must be a string"})
+			}
+		}
+		if args, ok := ref.Spec["args"].([]interface{}); ok {
+			for i, a := range args {
+				if _, ok := a.(string); !ok {
+					errs = append(errs, mcptypes.ValidationError{Field: fmt.Sprintf("spec.args[%d]", i), Message: "must be a string"})
+				}
+			}
+		}
+		return &mcptypes.ConfigValidateOutput{Valid: len(errs) == 0, Errors: errs}, nil
+	}
+
+	refs := extractEngineURIs(spec)
+	var validated []string
+	got, err := validateEngineRefs(context.Background(), refs, &spec, cfgPath, validate, func(ref engineReference) {
+		validated = append(validated, ref.SpecType+"/"+ref.SpecName)
+	})
+	if err != nil {
+		t.Fatalf("validateEngineRefs() error = %v", err)
+	}
+
+	// Both build specs share go://go-build but have distinct specs, so both are validated
+	wantValidated := []string{"build/app1", "build/app2", "test/unit"}
+	if strings.Join(validated, ",") != strings.Join(wantValidated, ",") {
+		t.Errorf("validated = %v, want %v", validated, wantValidated)
+	}
+
+	if got.Valid {
+		t.Fatal("validateEngineRefs() Valid = true, want false")
+	}
+
+	want := []struct {
+		location string
+		line     int
+	}{
+		{location: "[go://go-build] build.app1.ldflags", line: 11},
+		{location: "[go://go-build] build.app2.args[1]", line: 19},
+	}
+	if len(got.Errors) != len(want) {
+		t.Fatalf("validateEngineRefs() returned %d errors, want %d: %v", len(got.Errors), len(want), got.Errors)
+	}
+	for i, w := range want {
+		if loc := got.Errors[i].Location(); loc != w.location {
+			t.Errorf("Errors[%d].Location() = %q, want %q", i, loc, w.location)
+		}
+		if got.Errors[i].Line != w.line {
+			t.Errorf("Errors[%d].Line = %d, want %d", i, got.Errors[i].Line, w.line)
+		}
+	}
+}
+
+func TestValidateEngineRefs_InternalError(t *testing.T) {
+	refs := []engineReference{{URI: "go://broken", SpecType: "build", SpecName: "app"}}
+	validate := func(context.Context, engineReference, *forge.Spec, string) (*mcptypes.ConfigValidateOutput, error) {
+		return nil, errors.New("boom")
+	}
+
+	if _, err := validateEngineRefs(context.Background(), refs, &forge.Spec{}, "forge.yaml", validate, nil); err == nil {
+		t.Fatal("validateEngineRefs() expected error, got nil")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests for configLineIndex
+// -----------------------------------------------------------------------------
+
+func TestConfigLineIndex_LineOf(t *testing.T) {
+	data := []byte(`name: test-project
+engines:
+  - alias: my-testenv
+    type: testenv
+    testenv:
+      - engine: go://testenv-kind
+      - engine: go://testenv-helm-install
+        spec:
+          charts:
+            - name: podinfo
+              sourceType: helm-repo
+test:
+  - name: e2e
+    testenv: alias://my-testenv
+`)
+
+	idx, err := newConfigLineIndex(data)
+	if err != nil {
+		t.Fatalf("newConfigLineIndex() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		path  []string
+		field string
+		want  int
+	}{
+		{name: "sequence item by name", path: []string{"test", "e2e"}, want: 13},
+		{name: "sequence item by alias", path: []string{"engines", "my-testenv"}, want: 3},
+		{name: "field under testenv path", path: []string{"test", "e2e", "testenv"}, want: 14},
+		{name: "indexed path and spec field", path: []string{"engines", "0", "testenv", "1"}, field: "spec.charts[0].sourceType", want: 11},
+		{name: "path already at spec", path: []string{"engines", "0", "testenv", "1", "spec", "charts", "0"}, field: "spec.name", want: 10},
+		{name: "unknown field falls back to deepest node", path: []string{"test", "e2e"}, field: "spec.missing", want: 13},
+		{name: "unknown path", path: []string{"build", "app"}, want: 0},
+		{name: "empty path", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idx.lineOf(tt.path, tt.field); got != tt.want {
+				t.Errorf("lineOf(%v, %q) = %d, want %d", tt.path, tt.field, got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/alexandremahdhaoui/forge/internal/forgepath"
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
 )
 
 // parseGitRepoURL converts a git URL to a Go module-style path.
@@ -196,7 +197,7 @@ func resolveViaGoWork(modulePath string) (string, bool) {
 func gitCloneToTemp(gitURL string) (string, func(), error) {
 	noop := func() {}
 
-	tmpDir, err := os.MkdirTemp("", "forge-context-*")
+	tmpDir, err := tempdir.MkdirTemp("forge-context-*")
 	if err != nil {
 		return "", noop, fmt.Errorf("failed to create temp directory: %w", err)
 	}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/alexandremahdhaoui/forge/internal/cmdutil"
 	"github.com/alexandremahdhaoui/forge/pkg/engineversion"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 )
 
 // Version information (set via ldflags during build)
@@ -112,6 +114,12 @@ func main() {
 	}
 	// Note: We don't fail if loadConfig errors here - let the command handler report it
 
+	// Export spans of engine calls when an OTLP endpoint is configured
+	// (after the envFile, which may configure it)
+	if _, err := tracing.Setup(context.Background(), "forge"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tracing disabled: %v\n", err)
+	}
+
 	command := args[0]
 	cmdArgs := args[1:]
 
@@ -187,6 +195,16 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+	case "catalog":
+		if err := runCatalog(cmdArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "store":
+		if err := runStore(cmdArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	case "version", "--version", "-v":
 		versionInfo.Print()
 	case "help", "--help", "-h":
@@ -278,6 +296,8 @@ Commands:
   test-all                           Build all artifacts and run all test stages
   list [build|test]                  List available build targets and test stages
   docs <list|get> [name]             Fetch project documentation
+  catalog                            List all engines with their types, versions and tools
+  store prune                        Remove stale entries from the artifact store
   config <subcommand>                Configuration management
   cu <subcommand>                    Continuous-update operations (status, commit, checkout, go-get)
   ws <subcommand>                    Workspace lifecycle (list, create, delete, suspend, resume)
@@ -310,6 +330,16 @@ Docs:
   docs list                          List all available documentation
   docs get <name>                    Fetch a specific document
 
+Catalog:
+  catalog                            Query every engine and list its type, version and tools
+  catalog --no-query                 List engines from their manifests without starting them
+  catalog -o <json|yaml>             Output the catalog as JSON or YAML
+
+Store:
+  store prune                        Remove stale artifacts, test environments and test reports
+  store prune --dry-run              List the stale entries without removing them
+  store prune --keep <n>             Keep the n most recent artifacts per type and name (default: 1)
+
 Config:
   config validate [path]             Validate forge.yaml configuration
 
@@ -20,15 +20,28 @@ import (
 	"os"
 	"os/exec"
 
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// toolError is returned by callMCPEngine when the tool reports an error.
+// It keeps the structured content of the result, e.g. the artifacts built by a
+// buildBatch call in which only some of the builds failed.
+type toolError struct {
+	message           string
+	structuredContent any
+}
+
+func (e *toolError) Error() string {
+	return fmt.Sprintf("build failed: %s", e.message)
+}
+
 // callMCPEngine calls an MCP engine with the specified tool and parameters.
 // It spawns the engine process with --mcp flag, sets up stdio transport, and calls the tool.
 // The command and args parameters specify how to execute the MCP server:
 //   - For go run: command="go", args=["run", "package/path"]
 //   - For binary: command="binary-path", args=nil
-func callMCPEngine(command string, args []string, toolName string, params interface{}) (interface{}, error) {
+func callMCPEngine(command string, args []string, toolName string, params interface{}) (_ interface{}, err error) {
 	// Create command to spawn MCP server
 	// Append --mcp flag to the args
 	cmdArgs := append(args, "--mcp")
@@ -52,8 +65,9 @@ func callMCPEngine(command string, args []string, toolName string, params interf
 		Command: cmd,
 	}
 
-	// Connect to the MCP server
-	ctx := context.Background()
+	// Trace the call and connect to the MCP server
+	ctx, span := tracing.StartCall(context.Background(), command, args, toolName)
+	defer func() { tracing.End(span, err) }()
 	session, err := client.Connect(ctx, transport, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MCP server %s %v: %w", command, args, err)
@@ -73,6 +87,7 @@ func callMCPEngine(command string, args []string, toolName string, params interf
 
 	// Call the tool
 	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      tracing.InjectMeta(ctx),
 		Name:      toolName,
 		Arguments: arguments,
 	})
@@ -88,7 +103,7 @@ func callMCPEngine(command string, args []string, toolName string, params interf
 				errMsg = textContent.Text
 			}
 		}
-		return nil, fmt.Errorf("build failed: %s", errMsg)
+		return nil, &toolError{message: errMsg, structuredContent: result.StructuredContent}
 	}
 
 	// Return the structured content if available
@@ -0,0 +1,125 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// storeUsage is the usage of the "forge store" command.
+const storeUsage = "usage: forge store prune [--dry-run] [--keep N] [--format=table|json|yaml]"
+
+// runStore handles the "forge store" command, which maintains the artifact store.
+//
+// Subcommands:
+//   - prune: remove stale entries (see forge.PruneArtifactStore)
+//
+// Options of prune:
+//   - --dry-run: list the stale entries without removing them
+//   - --keep N: number of most recent artifacts kept per type+name (default: 1)
+//   - --format=<fmt> / -o <fmt>: table (default), json, yaml
+func runStore(args []string) error {
+	if len(args) == 0 || args[0] != "prune" {
+		return fmt.Errorf("%s", storeUsage)
+	}
+
+	format, remaining := parseOutputFormat(args[1:])
+
+	opts := forge.PruneOptions{KeepArtifacts: 1}
+	for i := 0; i < len(remaining); i++ {
+		switch remaining[i] {
+		case "--dry-run":
+			opts.DryRun = true
+		case "--keep":
+			if i+1 >= len(remaining) {
+				return fmt.Errorf("--keep requires a number\n\n%s", storeUsage)
+			}
+			keep, err := strconv.Atoi(remaining[i+1])
+			if err != nil || keep < 1 {
+				return fmt.Errorf("--keep must be a positive number, got %q", remaining[i+1])
+			}
+			opts.KeepArtifacts = keep
+			i++ // Skip the number
+		default:
+			return fmt.Errorf("unknown argument: %s\n\n%s", remaining[i], storeUsage)
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	artifactStorePath, err := forge.GetArtifactStorePath(config.ArtifactStorePath)
+	if err != nil {
+		return fmt.Errorf("failed to get artifact store path: %w", err)
+	}
+
+	result, err := forge.PruneArtifactStore(artifactStorePath, opts)
+	if err != nil {
+		return fmt.Errorf("failed to prune artifact store: %w", err)
+	}
+
+	formatPruneOutput(result, format)
+	return nil
+}
+
+// formatPruneOutput formats the result of an artifact store prune for display.
+func formatPruneOutput(result forge.PruneResult, format outputFormat) {
+	switch format {
+	case outputFormatJSON:
+		printJSON(result)
+	case outputFormatYAML:
+		printYAML(result)
+	default:
+		defer formatKeptTestEnvironments(result.Kept)
+		if len(result.Pruned) == 0 {
+			fmt.Println("No stale entries found.")
+			return
+		}
+
+		kindLen, idLen := len("KIND"), len("ID")
+		for _, entry := range result.Pruned {
+			kindLen = max(kindLen, len(entry.Kind))
+			idLen = max(idLen, len(entry.ID))
+		}
+
+		fmt.Printf("%-*s  %-*s  %s\n", kindLen, "KIND", idLen, "ID", "REASON")
+		for _, entry := range result.Pruned {
+			fmt.Printf("%-*s  %-*s  %s\n", kindLen, entry.Kind, idLen, entry.ID, entry.Reason)
+		}
+
+		if result.DryRun {
+			fmt.Printf("\n%d stale entries would be removed (dry run).\n", len(result.Pruned))
+		} else {
+			fmt.Printf("\nRemoved %d stale entries.\n", len(result.Pruned))
+		}
+	}
+}
+
+// formatKeptTestEnvironments lists the stale test environments that prune did not remove.
+func formatKeptTestEnvironments(kept []forge.PrunedEntry) {
+	if len(kept) == 0 {
+		return
+	}
+
+	fmt.Printf("\nKept %d test environment(s) whose subengine resources may still exist.\n", len(kept))
+	fmt.Println("Delete them with 'testenv gc' or 'testenv delete <ID>':")
+	for _, entry := range kept {
+		fmt.Printf("  %s  %s\n", entry.ID, entry.Reason)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -638,6 +639,13 @@ func testRun(config *forge.Spec, testSpec *forge.TestSpec, args []string) (strin
 		"buildDir": dirs.BuildDir,
 		"rootDir":  dirs.RootDir,
 	}
+	if len(testSpec.Labels) > 0 {
+		params["labels"] = testSpec.Labels
+	}
+	// Pass the local outputs of the build stage so that tests can run against them
+	if refs := upstreamArtifactRefs(config, dirs.RootDir); len(refs) > 0 {
+		params["artifacts"] = refs
+	}
 
 	// If testID is provided, get artifact files from test environment
 	if testID != "" {
@@ -877,21 +885,54 @@ func storeTestReportFromResult(result any, config *forge.Spec) error {
 		return fmt.Errorf("failed to get artifact store path: %w", err)
 	}
 
-	// Read or create artifact store
-	store, err := forge.ReadOrCreateArtifactStore(artifactStorePath)
-	if err != nil {
-		return fmt.Errorf("failed to read artifact store: %w", err)
+	// Add or update test report
+	if err := forge.UpdateArtifactStore(artifactStorePath, func(store *forge.ArtifactStore) error {
+		forge.AddOrUpdateTestReport(store, &report)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update artifact store: %w", err)
 	}
 
-	// Add or update test report
-	forge.AddOrUpdateTestReport(&store, &report)
+	return nil
+}
 
-	// Write artifact store
-	if err := forge.WriteArtifactStore(artifactStorePath, store); err != nil {
-		return fmt.Errorf("failed to write artifact store: %w", err)
+// upstreamArtifactRefs returns references to the latest local artifacts of the build specs of
+// config. It returns nil when the artifact store cannot be read.
+func upstreamArtifactRefs(config *forge.Spec, rootDir string) []forge.ArtifactRef {
+	artifactStorePath, err := forge.GetArtifactStorePath(config.ArtifactStorePath)
+	if err != nil {
+		return nil
 	}
+	store, err := forge.ReadArtifactStore(artifactStorePath)
+	if err != nil {
+		return nil
+	}
+	return artifactRefs(store, config.Build, rootDir)
+}
 
-	return nil
+// artifactRefs returns references to the latest artifact of each build spec whose file still
+// exists. Container images, artifacts that were never built and removed files are skipped.
+func artifactRefs(store forge.ArtifactStore, specs []forge.BuildSpec, rootDir string) []forge.ArtifactRef {
+	var refs []forge.ArtifactRef
+	for _, spec := range specs {
+		artifact, err := forge.GetLatestArtifact(store, spec.Name)
+		if err != nil {
+			continue
+		}
+		ref, ok := artifact.Ref()
+		if !ok {
+			continue
+		}
+		path := ref.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(rootDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
 }
 
 // updateTestStatus updates the status of a test environment in the artifact store.
@@ -905,21 +946,18 @@ func updateTestStatus(testID, status string) {
 		return
 	}
 
-	store, err := forge.ReadArtifactStore(artifactStorePath)
-	if err != nil {
-		return
-	}
-
-	env, err := forge.GetTestEnvironment(&store, testID)
-	if err != nil {
-		return
-	}
+	_ = forge.UpdateArtifactStore(artifactStorePath, func(store *forge.ArtifactStore) error {
+		env, err := forge.GetTestEnvironment(store, testID)
+		if err != nil {
+			return err
+		}
 
-	env.Status = status
-	env.UpdatedAt = time.Now().UTC()
+		env.Status = status
+		env.UpdatedAt = time.Now().UTC()
 
-	forge.AddOrUpdateTestEnvironment(&store, env)
-	_ = forge.WriteArtifactStore(artifactStorePath, store)
+		forge.AddOrUpdateTestEnvironment(store, env)
+		return nil
+	})
 }
 
 // getStringField safely gets a string field from a map.
@@ -436,3 +436,27 @@ func TestTestDeleteEnvRequiresEnvID(t *testing.T) {
 		t.Errorf("testDeleteEnv() error should contain 'usage', got: %v", err)
 	}
 }
+
+func TestArtifactRefs(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rootDir, "build", "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "build", "bin", "app"), []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	store := forge.ArtifactStore{Artifacts: []forge.Artifact{
+		{Name: "app", Type: "binary", Location: "./build/bin/app", Timestamp: "2025-01-01T00:00:00Z", Checksum: "sha256:old"},
+		{Name: "app", Type: "binary", Location: "./build/bin/app", Timestamp: "2025-01-02T00:00:00Z", Checksum: "sha256:new"},
+		{Name: "image", Type: "container", Location: "image:abc123", Timestamp: "2025-01-02T00:00:00Z"},
+		{Name: "removed", Type: "binary", Location: "./build/bin/removed", Timestamp: "2025-01-02T00:00:00Z"},
+	}}
+	specs := []forge.BuildSpec{{Name: "app"}, {Name: "image"}, {Name: "removed"}, {Name: "never-built"}}
+
+	refs := artifactRefs(store, specs, rootDir)
+	want := []forge.ArtifactRef{{Name: "app", Path: "./build/bin/app", Checksum: "sha256:new"}}
+	if len(refs) != len(want) || refs[0] != want[0] {
+		t.Errorf("artifactRefs() = %+v, want %+v", refs, want)
+	}
+}
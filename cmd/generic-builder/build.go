@@ -60,11 +60,43 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 		return nil, fmt.Errorf("command is required")
 	}
 
+	outputPath := resolvePath(spec.OutputPath, ctxDir)
+
 	processedArgs, err := processTemplatedArgs(args, input)
 	if err != nil {
 		return nil, fmt.Errorf("template processing failed: %w", err)
 	}
 
+	// Fingerprint the inputs so the command can be skipped while nothing it reads has changed
+	var inputsChecksum string
+	if outputPath != "" && len(spec.Inputs) > 0 {
+		inputs := make([]string, 0, len(spec.Inputs))
+		for _, in := range spec.Inputs {
+			inputs = append(inputs, resolvePath(in, ctxDir))
+		}
+		inputsChecksum, err = computeInputsChecksum(command, processedArgs, env, envFile, inputs)
+		if err != nil {
+			log.Printf("Warning: failed to fingerprint inputs of %s: %v (will rebuild)", input.Name, err)
+			inputsChecksum = ""
+		}
+	}
+
+	// Skip the command while its inputs are unchanged and its output still matches the last build
+	if inputsChecksum != "" && !input.Force {
+		if checksum, upToDate := buildUpToDate(input.Name, outputPath, inputsChecksum); upToDate {
+			log.Printf("Inputs and output %s unchanged (%s), skipping command", outputPath, checksum)
+			return &forge.Artifact{
+				Name:      input.Name,
+				Type:      "command-output",
+				Location:  outputPath,
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Version:   checksum,
+				Checksum:  checksum,
+				Labels:    map[string]string{inputsChecksumLabel: inputsChecksum},
+			}, nil
+		}
+	}
+
 	execInput := cmdutil.ExecuteInput{
 		Command: command,
 		Args:    processedArgs,
@@ -109,6 +141,21 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 		Version:   fmt.Sprintf("%s-exit%d", command, output.ExitCode),
 	}
 
+	// Record a content checksum of the output so that changes can be detected
+	if outputPath != "" {
+		checksum, err := computeOutputChecksum(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Output %s checksum: %s", outputPath, checksum)
+		artifact.Location = outputPath
+		artifact.Version = checksum
+		artifact.Checksum = checksum
+		if inputsChecksum != "" {
+			artifact.Labels = map[string]string{inputsChecksumLabel: inputsChecksum}
+		}
+	}
+
 	return artifact, nil
 }
 
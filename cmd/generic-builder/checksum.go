@@ -0,0 +1,140 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// checksumPrefix identifies the hash algorithm of an output checksum.
+const checksumPrefix = forge.ChecksumPrefixSHA256
+
+// computeOutputChecksum computes the checksum of the output file or directory tree.
+func computeOutputChecksum(path string) (string, error) {
+	return forge.ComputeChecksum(path)
+}
+
+// resolvePath resolves a spec path (outputPath or inputs) against the context directory.
+func resolvePath(path, ctxDir string) string {
+	if path == "" || filepath.IsAbs(path) || ctxDir == "" {
+		return path
+	}
+	return filepath.Join(ctxDir, path)
+}
+
+// inputsChecksumLabel is the artifact label recording the fingerprint of the build inputs.
+const inputsChecksumLabel = "generic-builder/inputs-checksum"
+
+// computeInputsChecksum fingerprints everything that determines the output of a build: the
+// command, its args, its env, the env file and the content of each input path.
+func computeInputsChecksum(command string, args []string, env map[string]string, envFile string, inputs []string) (string, error) {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "command %s\x00", command)
+	for _, arg := range args {
+		fmt.Fprintf(h, "arg %s\x00", arg)
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "env %s=%s\x00", k, env[k])
+	}
+
+	// A missing env file is not an error when loading it, so only its path is recorded
+	if envFile != "" {
+		checksum := ""
+		if _, err := os.Stat(envFile); err == nil {
+			if checksum, err = forge.ComputeChecksum(envFile); err != nil {
+				return "", err
+			}
+		}
+		fmt.Fprintf(h, "envFile %s\x00%s\x00", envFile, checksum)
+	}
+
+	for _, input := range inputs {
+		checksum, err := forge.ComputeChecksum(input)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "input %s\x00%s\x00", input, checksum)
+	}
+
+	return checksumPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// findPriorArtifact returns the last build of the named artifact, or nil if it was never built.
+func findPriorArtifact(name string) (*forge.Artifact, error) {
+	// Get artifact store path (environment variable takes precedence)
+	artifactStorePath := os.Getenv("FORGE_ARTIFACT_STORE_PATH")
+	if artifactStorePath == "" {
+		path, err := forge.GetArtifactStorePath(".forge/artifacts.yaml")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get artifact store path: %w", err)
+		}
+		artifactStorePath = path
+	}
+
+	store, err := forge.ReadOrCreateArtifactStore(artifactStorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact store: %w", err)
+	}
+
+	artifact, err := forge.GetLatestArtifact(store, name)
+	if err != nil {
+		return nil, nil // Never built
+	}
+	return &artifact, nil
+}
+
+// buildUpToDate reports whether the last build of the named artifact used the same inputs
+// and its output at path is unchanged. Any failure is logged and treated as out of date.
+func buildUpToDate(name, path, inputsChecksum string) (string, bool) {
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+
+	prior, err := findPriorArtifact(name)
+	if err != nil {
+		log.Printf("Warning: failed to look up previous build of %s: %v (will rebuild)", name, err)
+		return "", false
+	}
+	if prior == nil || !strings.HasPrefix(prior.Version, checksumPrefix) {
+		return "", false
+	}
+	if prior.Labels[inputsChecksumLabel] != inputsChecksum {
+		return "", false
+	}
+
+	current, err := computeOutputChecksum(path)
+	if err != nil {
+		log.Printf("Warning: %v (will rebuild)", err)
+		return "", false
+	}
+
+	return current, current == prior.Version
+}
@@ -0,0 +1,266 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+)
+
+// writeTree creates files (relative path -> content) under root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestComputeOutputChecksum(t *testing.T) {
+	tree := map[string]string{
+		"a.txt":         "alpha",
+		"b/c.txt":       "charlie",
+		"b/d/e.txt":     "echo",
+		"z/nested/f.go": "package f",
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, tree)
+
+	checksum, err := computeOutputChecksum(dir)
+	if err != nil {
+		t.Fatalf("computeOutputChecksum() error = %v", err)
+	}
+	if !strings.HasPrefix(checksum, checksumPrefix) || len(checksum) != len(checksumPrefix)+64 {
+		t.Fatalf("computeOutputChecksum() = %q, want sha256:<64 hex chars>", checksum)
+	}
+
+	t.Run("stable across runs and locations", func(t *testing.T) {
+		again, err := computeOutputChecksum(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again != checksum {
+			t.Errorf("checksum changed between runs: %s != %s", again, checksum)
+		}
+
+		other := t.TempDir()
+		writeTree(t, other, tree)
+		copied, err := computeOutputChecksum(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if copied != checksum {
+			t.Errorf("identical tree has a different checksum: %s != %s", copied, checksum)
+		}
+	})
+
+	changes := map[string]func(t *testing.T, root string){
+		"modified file": func(t *testing.T, root string) {
+			writeTree(t, root, map[string]string{"b/d/e.txt": "echo!"})
+		},
+		"added file": func(t *testing.T, root string) {
+			writeTree(t, root, map[string]string{"b/new.txt": ""})
+		},
+		"removed file": func(t *testing.T, root string) {
+			if err := os.Remove(filepath.Join(root, "a.txt")); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"renamed file": func(t *testing.T, root string) {
+			if err := os.Rename(filepath.Join(root, "b/c.txt"), filepath.Join(root, "b/c2.txt")); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"added empty directory": func(t *testing.T, root string) {
+			if err := os.Mkdir(filepath.Join(root, "empty"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, tree)
+			change(t, root)
+
+			changed, err := computeOutputChecksum(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed == checksum {
+				t.Errorf("checksum did not change after %s", name)
+			}
+		})
+	}
+
+	t.Run("single file", func(t *testing.T) {
+		fileChecksum, err := computeOutputChecksum(filepath.Join(dir, "a.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fileChecksum == checksum || !strings.HasPrefix(fileChecksum, checksumPrefix) {
+			t.Errorf("unexpected single file checksum %q", fileChecksum)
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		if _, err := computeOutputChecksum(filepath.Join(dir, "missing")); err == nil {
+			t.Error("computeOutputChecksum() expected an error for a missing path")
+		}
+	})
+}
+
+func TestBuild_SkipsWhenInputsAndOutputUnchanged(t *testing.T) {
+	ctxDir := t.TempDir()
+	storePath := filepath.Join(t.TempDir(), "artifacts.yaml")
+	t.Setenv("FORGE_ARTIFACT_STORE_PATH", storePath)
+	writeTree(t, ctxDir, map[string]string{"src/input.txt": "v1"})
+
+	// The run counter lives outside the context so it is not part of the inputs
+	counter := filepath.Join(t.TempDir(), "runs")
+	spec := &Spec{
+		Command:    "sh",
+		Args:       []string{"-c", "mkdir -p out && cp src/input.txt out/result && echo run >> " + counter},
+		Inputs:     []string{"src"},
+		OutputPath: "out",
+	}
+	input := mcptypes.BuildInput{Name: "generated", Context: ctxDir}
+
+	build := func(force bool) *forge.Artifact {
+		t.Helper()
+		input.Force = force
+		artifact, err := Build(context.Background(), input, spec)
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+
+		// Record the build as forge does
+		store, err := forge.ReadOrCreateArtifactStore(storePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forge.AddOrUpdateArtifact(&store, *artifact)
+		if err := forge.WriteArtifactStore(storePath, store); err != nil {
+			t.Fatal(err)
+		}
+		return artifact
+	}
+	runs := func() int {
+		t.Helper()
+		content, err := os.ReadFile(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(string(content), "run")
+	}
+
+	first := build(false)
+	if !strings.HasPrefix(first.Version, checksumPrefix) {
+		t.Fatalf("artifact version = %q, want a checksum", first.Version)
+	}
+	if first.Location != filepath.Join(ctxDir, "out") {
+		t.Errorf("artifact location = %q, want the output path", first.Location)
+	}
+	if first.Labels[inputsChecksumLabel] == "" {
+		t.Errorf("artifact labels = %v, want an inputs checksum", first.Labels)
+	}
+
+	second := build(false)
+	if runs() != 1 {
+		t.Errorf("command ran %d times, want it skipped while inputs and output are unchanged", runs())
+	}
+	if second.Version != first.Version {
+		t.Errorf("skipped build version = %q, want %q", second.Version, first.Version)
+	}
+
+	build(true)
+	if runs() != 2 {
+		t.Errorf("command ran %d times, want a forced rebuild", runs())
+	}
+
+	writeTree(t, ctxDir, map[string]string{"out/result": "tampered"})
+	build(false)
+	if runs() != 3 {
+		t.Errorf("command ran %d times, want a rebuild after the output changed", runs())
+	}
+
+	writeTree(t, ctxDir, map[string]string{"src/input.txt": "v2"})
+	rebuilt := build(false)
+	if runs() != 4 {
+		t.Errorf("command ran %d times, want a rebuild after the sources changed", runs())
+	}
+	if rebuilt.Version == first.Version {
+		t.Errorf("rebuilt version = %q, want it to reflect the new sources", rebuilt.Version)
+	}
+
+	spec.Args = append([]string{}, spec.Args...)
+	spec.Args[1] += " && true"
+	build(false)
+	if runs() != 5 {
+		t.Errorf("command ran %d times, want a rebuild after the args changed", runs())
+	}
+}
+
+func TestBuild_AlwaysRunsWithoutInputs(t *testing.T) {
+	ctxDir := t.TempDir()
+	storePath := filepath.Join(t.TempDir(), "artifacts.yaml")
+	t.Setenv("FORGE_ARTIFACT_STORE_PATH", storePath)
+
+	counter := filepath.Join(t.TempDir(), "runs")
+	spec := &Spec{
+		Command:    "sh",
+		Args:       []string{"-c", "mkdir -p out && echo built > out/result && echo run >> " + counter},
+		OutputPath: "out",
+	}
+	input := mcptypes.BuildInput{Name: "generated", Context: ctxDir}
+
+	for i := 0; i < 2; i++ {
+		artifact, err := Build(context.Background(), input, spec)
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		store, err := forge.ReadOrCreateArtifactStore(storePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forge.AddOrUpdateArtifact(&store, *artifact)
+		if err := forge.WriteArtifactStore(storePath, store); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(content), "run"); runs != 2 {
+		t.Errorf("command ran %d times, want it to run on every build without inputs", runs)
+	}
+}
@@ -1,20 +1,28 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml
-// SourceChecksum: sha256:e2f5645b1c66131e36236d4864b37ef47be5e65d89dd619ac2c87c0bf26d251e
+// SourceChecksum: sha256:2428404c47c829a105db489aa7fc8713b4d3340e297658db80d03342bd59633a
 
 package main
 
 import (
+	"embed"
+
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for documentation tools.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "generic-builder",
 	LocalDir:     "cmd/generic-builder/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 // RegisterDocsMCPTools registers the docs-list and docs-get MCP tools.
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:e2f5645b1c66131e36236d4864b37ef47be5e65d89dd619ac2c87c0bf26d251e
+// SourceChecksum: sha256:2428404c47c829a105db489aa7fc8713b4d3340e297658db80d03342bd59633a
 
 package main
 
@@ -21,6 +21,12 @@ type Spec struct {
 	Env map[string]string `json:"env,omitempty"`
 	// Path to environment file (optional)
 	EnvFile string `json:"envFile,omitempty"`
+	// Files or directories read by the command (optional). Relative paths are resolved against the context directory. When set together with outputPath, the command is skipped while its inputs, command, args and env are unchanged and the output still matches the last build
+	//
+	Inputs []string `json:"inputs,omitempty"`
+	// File or directory produced by the command (optional). Relative paths are resolved against the context directory. When set, a SHA-256 checksum of the output is stored as the artifact version
+	//
+	OutputPath string `json:"outputPath,omitempty"`
 }
 
 // SpecFromMap creates a Spec from a map[string]interface{}.
@@ -88,6 +94,31 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field envFile: expected string, got %T", v)
 		}
 	}
+	// Parse inputs
+	if v, ok := m["inputs"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Inputs = make([]string, 0, len(arr))
+			for i, item := range arr {
+				if str, ok := item.(string); ok {
+					s.Inputs = append(s.Inputs, str)
+				} else {
+					return nil, fmt.Errorf("field inputs[%d]: expected string, got %T", i, item)
+				}
+			}
+		} else if arr, ok := v.([]string); ok {
+			s.Inputs = arr
+		} else {
+			return nil, fmt.Errorf("field inputs: expected []string, got %T", v)
+		}
+	}
+	// Parse outputPath
+	if v, ok := m["outputPath"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.OutputPath = val
+		} else {
+			return nil, fmt.Errorf("field outputPath: expected string, got %T", v)
+		}
+	}
 	return s, nil
 }
 
@@ -113,6 +144,12 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if s.EnvFile != "" {
 		m["envFile"] = s.EnvFile
 	}
+	if len(s.Inputs) > 0 {
+		m["inputs"] = s.Inputs
+	}
+	if s.OutputPath != "" {
+		m["outputPath"] = s.OutputPath
+	}
 	return m
 }
 
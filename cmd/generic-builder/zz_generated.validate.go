@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:e2f5645b1c66131e36236d4864b37ef47be5e65d89dd619ac2c87c0bf26d251e
+// SourceChecksum: sha256:2428404c47c829a105db489aa7fc8713b4d3340e297658db80d03342bd59633a
 
 package main
 
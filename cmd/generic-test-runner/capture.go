@@ -0,0 +1,71 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// defaultMaxCaptureBytes is the default number of bytes of each output stream kept for the report.
+const defaultMaxCaptureBytes = 1024 * 1024
+
+// tailBuffer is an io.Writer keeping only the last max bytes written to it.
+// It is safe for concurrent use.
+type tailBuffer struct {
+	mu        sync.Mutex
+	max       int
+	buf       []byte
+	truncated int64
+}
+
+// newTailBuffer creates a tailBuffer keeping at most max bytes (defaultMaxCaptureBytes if max <= 0).
+func newTailBuffer(max int) *tailBuffer {
+	if max <= 0 {
+		max = defaultMaxCaptureBytes
+	}
+	return &tailBuffer{max: max}
+}
+
+// Write appends p, discarding the oldest bytes beyond the limit. It never fails.
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	if n >= b.max {
+		b.truncated += int64(len(b.buf) + n - b.max)
+		b.buf = append(b.buf[:0], p[n-b.max:]...)
+		return n, nil
+	}
+
+	if overflow := len(b.buf) + n - b.max; overflow > 0 {
+		b.truncated += int64(overflow)
+		b.buf = append(b.buf[:0], b.buf[overflow:]...)
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+// String returns the captured tail, prefixed with a marker if older output was truncated.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.truncated == 0 {
+		return string(b.buf)
+	}
+	return fmt.Sprintf("[... %d bytes truncated ...]\n%s", b.truncated, b.buf)
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	errUnterminatedReference = errors.New("unterminated variable reference")
+	errInvalidReference      = errors.New("invalid variable reference")
+)
+
+// loadEnvFile loads environment variables from a file.
+//
+// Lines are KEY=value, optionally prefixed with "export ". Values enclosed in matching
+// single or double quotes are unquoted. Unless single-quoted, values are interpolated in
+// file order (see expandEnvValue), so a line can reference keys loaded by the lines before it.
+func loadEnvFile(path string) (map[string]string, error) {
+	envVars := make(map[string]string)
+	if err := readEnvFileInto(path, envVars); err != nil {
+		return nil, err
+	}
+	return envVars, nil
+}
+
+// loadEnvFiles loads the environment files at paths in order, like loadEnvFile.
+// A later file overrides the keys of earlier ones and can reference them.
+func loadEnvFiles(paths []string) (map[string]string, error) {
+	envVars := make(map[string]string)
+	for _, path := range paths {
+		if err := readEnvFileInto(path, envVars); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return envVars, nil
+}
+
+// readEnvFileInto reads the environment file at path into envVars.
+// A missing file is skipped.
+func readEnvFileInto(path string, envVars map[string]string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	for lineNum, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		line = strings.TrimSpace(line)
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid format in env file at line %d: %s", lineNum+1, line)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		literal := false
+		if len(value) >= 2 {
+			if (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
+				(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
+				literal = value[0] == '\''
+				value = value[1 : len(value)-1]
+			}
+		}
+
+		if !literal {
+			value, err = expandEnvValue(value, envVars)
+			if err != nil {
+				return fmt.Errorf("invalid value in env file at line %d: %w", lineNum+1, err)
+			}
+		}
+
+		envVars[key] = value
+	}
+
+	return nil
+}
+
+// expandEnvValue expands the variable references of value:
+//   - $VAR and ${VAR} expand to the value of VAR
+//   - ${VAR:-default} expands to default when VAR is unset or empty (default is expanded too)
+//   - \$ is a literal dollar sign, and a $ not followed by a name or { is kept as is
+//
+// Variables are looked up in vars first, then in the process environment; unset variables
+// expand to the empty string.
+func expandEnvValue(value string, vars map[string]string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\\' && i+1 < len(value) && value[i+1] == '$':
+			out.WriteByte('$')
+			i++
+
+		case c != '$' || i+1 == len(value):
+			out.WriteByte(c)
+
+		case value[i+1] == '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%w: %s", errUnterminatedReference, value[i:])
+			}
+			expanded, err := expandBracedReference(value[i+2:end], vars)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(expanded)
+			i = end
+
+		case isEnvNameStart(value[i+1]):
+			end := i + 2
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			out.WriteString(lookupEnvVar(value[i+1:end], vars))
+			i = end - 1
+
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+// expandBracedReference expands the content of a ${...} reference: NAME or NAME:-default.
+func expandBracedReference(ref string, vars map[string]string) (string, error) {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if !isEnvName(name) {
+		return "", fmt.Errorf("%w: ${%s}", errInvalidReference, ref)
+	}
+
+	v := lookupEnvVar(name, vars)
+	if v != "" || !hasDefault {
+		return v, nil
+	}
+	return expandEnvValue(def, vars)
+}
+
+// closingBrace returns the index of the } closing a ${ whose content starts at start,
+// skipping nested ${...} references, or -1.
+func closingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '$':
+			i++
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// lookupEnvVar returns the value of name in vars, or else in the process environment.
+func lookupEnvVar(name string, vars map[string]string) string {
+	if v, ok := vars[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
+
+func isEnvName(s string) bool {
+	if s == "" || !isEnvNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isEnvNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || (c >= '0' && c <= '9')
+}
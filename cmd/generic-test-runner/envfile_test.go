@@ -0,0 +1,161 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadEnvFile_Interpolation(t *testing.T) {
+	t.Setenv("ENVFILE_TEST_HOME", "/home/forge")
+
+	path := writeEnvFile(t, `# comment
+HOST=localhost
+PORT="8080"
+export URL=http://${HOST}:$PORT/api
+DATA_DIR=$ENVFILE_TEST_HOME/data
+LITERAL='${HOST}'
+ESCAPED="price \$5 for $HOST"
+TRAILING=cost$
+REGION=${ENVFILE_TEST_UNSET:-eu-west-1}
+EMPTY_DEFAULT=${HOST:-other}
+NESTED=${ENVFILE_TEST_UNSET:-${HOST}:${PORT}}
+UNSET=$ENVFILE_TEST_UNSET
+`)
+
+	got, err := loadEnvFile(path)
+	if err != nil {
+		t.Fatalf("loadEnvFile() error = %v", err)
+	}
+
+	want := map[string]string{
+		"HOST":          "localhost",
+		"PORT":          "8080",
+		"URL":           "http://localhost:8080/api",
+		"DATA_DIR":      "/home/forge/data",
+		"LITERAL":       "${HOST}",
+		"ESCAPED":       "price $5 for localhost",
+		"TRAILING":      "cost$",
+		"REGION":        "eu-west-1",
+		"EMPTY_DEFAULT": "localhost",
+		"NESTED":        "localhost:8080",
+		"UNSET":         "",
+	}
+	for key, wantValue := range want {
+		if got[key] != wantValue {
+			t.Errorf("%s = %q, want %q", key, got[key], wantValue)
+		}
+	}
+}
+
+func TestLoadEnvFile_LinesExpandInOrder(t *testing.T) {
+	path := writeEnvFile(t, "A=${B:-unset}\nB=set\nC=$B\n")
+
+	got, err := loadEnvFile(path)
+	if err != nil {
+		t.Fatalf("loadEnvFile() error = %v", err)
+	}
+	if got["A"] != "unset" || got["C"] != "set" {
+		t.Errorf("A = %q, C = %q, want %q and %q", got["A"], got["C"], "unset", "set")
+	}
+}
+
+func TestLoadEnvFile_MalformedReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "unterminated",
+			content: "A=1\nB=${A\n",
+			wantErr: errUnterminatedReference,
+			wantMsg: "line 2",
+		},
+		{
+			name:    "empty name",
+			content: "A=${}\n",
+			wantErr: errInvalidReference,
+			wantMsg: "line 1",
+		},
+		{
+			name:    "invalid name",
+			content: "# comment\n\nA=${1A}\n",
+			wantErr: errInvalidReference,
+			wantMsg: "line 3",
+		},
+		{
+			name:    "unsupported operator",
+			content: "A=${B:?required}\n",
+			wantErr: errInvalidReference,
+			wantMsg: "line 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadEnvFile(writeEnvFile(t, tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("loadEnvFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("loadEnvFile() error = %v, want it to mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFiles_LaterFilesWin(t *testing.T) {
+	base := writeEnvFile(t, "HOST=localhost\nPORT=8080\nMODE=base\n")
+	local := writeEnvFile(t, "MODE=local\nURL=http://$HOST:$PORT\n")
+
+	got, err := loadEnvFiles([]string{base, local})
+	if err != nil {
+		t.Fatalf("loadEnvFiles() error = %v", err)
+	}
+
+	want := map[string]string{"HOST": "localhost", "PORT": "8080", "MODE": "local", "URL": "http://localhost:8080"}
+	for key, wantValue := range want {
+		if got[key] != wantValue {
+			t.Errorf("%s = %q, want %q", key, got[key], wantValue)
+		}
+	}
+}
+
+func TestLoadEnvFiles_ErrorNamesFile(t *testing.T) {
+	base := writeEnvFile(t, "A=1\n")
+	broken := writeEnvFile(t, "B=${A\n")
+
+	_, err := loadEnvFiles([]string{base, broken})
+	if !errors.Is(err, errUnterminatedReference) || !strings.Contains(err.Error(), broken) {
+		t.Errorf("loadEnvFiles() error = %v, want unterminated reference in %s", err, broken)
+	}
+}
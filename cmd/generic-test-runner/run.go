@@ -15,17 +15,21 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+	"github.com/google/uuid"
 )
 
 // ExecuteInput contains the parameters for command execution
@@ -33,15 +37,25 @@ type ExecuteInput struct {
 	Command string            // Command to execute
 	Args    []string          // Command arguments
 	Env     map[string]string // Environment variables
-	EnvFile string            // Path to environment file (optional)
 	Context string            // Context directory for command execution (optional)
+	// EnvFiles are paths to environment files, loaded in order; later files win (optional).
+	// Env always wins over the env files.
+	EnvFiles []string
+	// EnvFile is a path to an environment file, loaded before EnvFiles (optional).
+	//
+	// Deprecated: use EnvFiles.
+	EnvFile string
+	// Stream tees the command output to stderr in real time (optional)
+	Stream bool
+	// MaxCaptureBytes bounds the captured stdout and stderr, keeping the tail (default 1MB)
+	MaxCaptureBytes int
 }
 
 // ExecuteOutput contains the result of command execution
 type ExecuteOutput struct {
 	ExitCode int    // Command exit code
-	Stdout   string // Standard output
-	Stderr   string // Standard error
+	Stdout   string // Standard output (tail, see ExecuteInput.MaxCaptureBytes)
+	Stderr   string // Standard error (tail, see ExecuteInput.MaxCaptureBytes)
 	Error    string // Error message if execution failed
 }
 
@@ -65,7 +79,7 @@ func Run(ctx context.Context, input mcptypes.RunInput, spec *Spec) (*forge.TestR
 	}
 
 	envFile := spec.EnvFile
-	if envFile == "" {
+	if envFile == "" && len(spec.EnvFiles) == 0 {
 		envFile = input.EnvFile
 	}
 
@@ -77,105 +91,220 @@ func Run(ctx context.Context, input mcptypes.RunInput, spec *Spec) (*forge.TestR
 	if command == "" {
 		return nil, fmt.Errorf("command is required")
 	}
+	if spec.ParseRegex != "" {
+		if _, err := regexp.Compile(spec.ParseRegex); err != nil {
+			return nil, fmt.Errorf("invalid parseRegex: %w", err)
+		}
+	}
+
+	// Split the command string, or wrap it in "sh -c" in shell mode
+	program, programArgs, err := resolveCommandLine(command, args, spec.Shell)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command %q: %w", command, err)
+	}
 
 	// Execute command
 	execInput := ExecuteInput{
-		Command: command,
-		Args:    args,
-		Env:     env,
-		EnvFile: envFile,
-		Context: ctxDir,
+		Command:         program,
+		Args:            programArgs,
+		Env:             env,
+		EnvFile:         envFile,
+		EnvFiles:        spec.EnvFiles,
+		Context:         ctxDir,
+		Stream:          spec.Stream,
+		MaxCaptureBytes: spec.MaxCaptureBytes,
 	}
 
+	startTime := time.Now().UTC()
 	output := executeCommand(execInput)
+	duration := time.Since(startTime).Seconds()
+
+	// Log output, unless it was already written to stderr while the command ran
+	if !execInput.Stream {
+		if output.Stdout != "" {
+			log.Printf("Stdout: %s", output.Stdout)
+		}
+		if output.Stderr != "" {
+			log.Printf("Stderr: %s", output.Stderr)
+		}
+	}
+
+	report, err := buildTestReport(input, spec, output, startTime, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the captured output so the report can be inspected after the run
+	if input.TmpDir != "" {
+		outputPath, err := writeOutputFile(input.TmpDir, input.Stage, input.Name, output)
+		if err != nil {
+			log.Printf("Warning: failed to write test output: %v", err)
+		} else {
+			report.OutputPath = outputPath
+			report.ArtifactFiles = []string{outputPath}
+		}
+	}
+
+	// Store the report ourselves: forge does not store reports of failed runs
+	if err := storeTestReport(report); err != nil {
+		log.Printf("Warning: failed to store test report: %v", err)
+	}
 
-	// Create test report based on exit code
 	// CRITICAL: Return report even if tests failed (Status="failed")
+	return report, nil
+}
+
+// buildTestReport creates the test report of a command execution.
+// The status is derived from the exit code. Test counts are extracted from stdout
+// with spec.ParseRegex if set; otherwise the command counts as a single test.
+func buildTestReport(input mcptypes.RunInput, spec *Spec, output ExecuteOutput, startTime time.Time, duration float64) (*forge.TestReport, error) {
 	status := "passed"
-	passed := 1
-	failed := 0
 	errorMessage := ""
+	stats := forge.TestStats{Total: 1, Passed: 1}
 
 	if output.ExitCode != 0 {
 		status = "failed"
-		passed = 0
-		failed = 1
+		stats = forge.TestStats{Total: 1, Failed: 1}
 		errorMessage = fmt.Sprintf("Command exited with code %d", output.ExitCode)
 		if output.Error != "" {
 			errorMessage += fmt.Sprintf(": %s", output.Error)
 		}
 	}
 
-	// Log output
-	if output.Stdout != "" {
-		log.Printf("Stdout: %s", output.Stdout)
+	if spec.ParseRegex != "" {
+		parsed, ok, err := parseTestStats(spec.ParseRegex, output.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			stats = parsed
+		} else {
+			log.Printf("Warning: parseRegex %q did not match stdout, reporting the command as a single test", spec.ParseRegex)
+		}
 	}
-	if output.Stderr != "" {
-		log.Printf("Stderr: %s", output.Stderr)
+
+	// Reported failures fail the run even if the command exited successfully
+	if status == "passed" && stats.Failed > 0 {
+		status = "failed"
+		errorMessage = fmt.Sprintf("%d/%d tests failed", stats.Failed, stats.Total)
 	}
 
-	report := &forge.TestReport{
+	return &forge.TestReport{
 		ID:           input.ID,
 		Stage:        input.Stage,
 		Status:       status,
 		ErrorMessage: errorMessage,
-		StartTime:    time.Now().UTC(),
-		Duration:     0, // Duration not tracked for generic test runner
-		TestStats: forge.TestStats{
-			Total:  1,
-			Passed: passed,
-			Failed: failed,
-		},
+		StartTime:    startTime,
+		Duration:     duration,
+		TestStats:    stats,
 		Coverage: forge.Coverage{
 			Percentage: 0.0, // Coverage not tracked for generic test runner
 		},
-	}
-
-	return report, nil
+	}, nil
 }
 
-// loadEnvFile loads environment variables from a file
-func loadEnvFile(path string) (map[string]string, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return make(map[string]string), nil
-	}
-
-	content, err := os.ReadFile(path)
+// parseTestStats extracts test counts from stdout using the named groups
+// "total", "passed", "failed" and "skipped" of the last match of pattern.
+// When the total is not captured, it is computed from the other counts; when the
+// passed count is not captured, it is computed from the total.
+// Returns false if the pattern does not match.
+func parseTestStats(pattern, stdout string) (forge.TestStats, bool, error) {
+	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read env file: %w", err)
+		return forge.TestStats{}, false, fmt.Errorf("invalid parseRegex: %w", err)
 	}
 
-	envVars := make(map[string]string)
-	lines := strings.Split(string(content), "\n")
+	matches := re.FindAllStringSubmatch(stdout, -1)
+	if len(matches) == 0 {
+		return forge.TestStats{}, false, nil
+	}
+	match := matches[len(matches)-1]
 
-	for lineNum, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
+	counts := map[string]int{}
+	for i, name := range re.SubexpNames() {
+		switch name {
+		case "total", "passed", "failed", "skipped":
+		default:
+			continue
+		}
+		if match[i] == "" {
 			continue
 		}
+		n, err := strconv.Atoi(match[i])
+		if err != nil {
+			return forge.TestStats{}, false, fmt.Errorf("parseRegex group %q: %q is not a number", name, match[i])
+		}
+		counts[name] = n
+	}
+
+	stats := forge.TestStats{
+		Passed:  counts["passed"],
+		Failed:  counts["failed"],
+		Skipped: counts["skipped"],
+	}
+	total, hasTotal := counts["total"]
+	_, hasPassed := counts["passed"]
+	switch {
+	case !hasTotal:
+		stats.Total = stats.Passed + stats.Failed + stats.Skipped
+	case !hasPassed:
+		stats.Total = total
+		stats.Passed = max(total-stats.Failed-stats.Skipped, 0)
+	default:
+		stats.Total = total
+	}
+
+	return stats, true, nil
+}
 
-		line = strings.TrimPrefix(line, "export ")
-		line = strings.TrimSpace(line)
+// writeOutputFile writes the captured stdout and stderr of the command to tmpDir.
+func writeOutputFile(tmpDir, stage, name string, output ExecuteOutput) (string, error) {
+	outputPath := filepath.Join(tmpDir, fmt.Sprintf("test-%s-%s.log", stage, name))
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid format in env file at line %d: %s", lineNum+1, line)
+	var b strings.Builder
+	b.WriteString(output.Stdout)
+	if output.Stderr != "" {
+		if output.Stdout != "" && !strings.HasSuffix(output.Stdout, "\n") {
+			b.WriteString("\n")
 		}
+		b.WriteString(output.Stderr)
+	}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+	if err := os.WriteFile(outputPath, []byte(b.String()), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return outputPath, nil
+}
 
-		if len(value) >= 2 {
-			if (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
-				(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
-				value = value[1 : len(value)-1]
-			}
+// storeTestReport stores the test report in the artifact store.
+// A new ID is generated if forge did not provide one.
+func storeTestReport(report *forge.TestReport) error {
+	// Get artifact store path (environment variable takes precedence)
+	artifactStorePath := os.Getenv("FORGE_ARTIFACT_STORE_PATH")
+	if artifactStorePath == "" {
+		// Read forge.yaml to get the artifact store path
+		config, err := forge.ReadSpec()
+		if err != nil {
+			return fmt.Errorf("failed to read forge.yaml: %w", err)
 		}
+		artifactStorePath, err = forge.GetArtifactStorePath(config.ArtifactStorePath)
+		if err != nil {
+			return fmt.Errorf("failed to get artifact store path: %w", err)
+		}
+	}
+
+	if report.ID == "" {
+		report.ID = uuid.New().String()
+	}
 
-		envVars[key] = value
+	if err := forge.UpdateArtifactStore(artifactStorePath, func(store *forge.ArtifactStore) error {
+		forge.AddOrUpdateTestReport(store, report)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update artifact store: %w", err)
 	}
 
-	return envVars, nil
+	return nil
 }
 
 // executeCommand executes a shell command with the given parameters
@@ -188,8 +317,12 @@ func executeCommand(input ExecuteInput) ExecuteOutput {
 
 	env := os.Environ()
 
+	envFiles := input.EnvFiles
 	if input.EnvFile != "" {
-		envFileVars, err := loadEnvFile(input.EnvFile)
+		envFiles = append([]string{input.EnvFile}, envFiles...)
+	}
+	if len(envFiles) > 0 {
+		envFileVars, err := loadEnvFiles(envFiles)
 		if err != nil {
 			return ExecuteOutput{
 				ExitCode: -1,
@@ -207,9 +340,17 @@ func executeCommand(input ExecuteInput) ExecuteOutput {
 
 	cmd.Env = env
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Capture a bounded tail of the output; in streaming mode, also tee it to stderr
+	// (stdout is reserved for the MCP transport)
+	stdout := newTailBuffer(input.MaxCaptureBytes)
+	stderr := newTailBuffer(input.MaxCaptureBytes)
+	if input.Stream {
+		cmd.Stdout = io.MultiWriter(stdout, os.Stderr)
+		cmd.Stderr = io.MultiWriter(stderr, os.Stderr)
+	} else {
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	}
 
 	err := cmd.Run()
 
@@ -0,0 +1,304 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTestStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		stdout  string
+		want    forge.TestStats
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:    "all groups",
+			pattern: `(?P<total>\d+) tests, (?P<passed>\d+) passed, (?P<failed>\d+) failed, (?P<skipped>\d+) skipped`,
+			stdout:  "running...\n10 tests, 7 passed, 2 failed, 1 skipped\n",
+			want:    forge.TestStats{Total: 10, Passed: 7, Failed: 2, Skipped: 1},
+			wantOK:  true,
+		},
+		{
+			name:    "total computed from counts",
+			pattern: `passed=(?P<passed>\d+) failed=(?P<failed>\d+)`,
+			stdout:  "passed=4 failed=1",
+			want:    forge.TestStats{Total: 5, Passed: 4, Failed: 1},
+			wantOK:  true,
+		},
+		{
+			name:    "passed computed from total",
+			pattern: `(?P<total>\d+) tests, (?P<failed>\d+) failures`,
+			stdout:  "8 tests, 2 failures",
+			want:    forge.TestStats{Total: 8, Passed: 6, Failed: 2},
+			wantOK:  true,
+		},
+		{
+			name:    "last match wins",
+			pattern: `passed=(?P<passed>\d+)`,
+			stdout:  "passed=1\npassed=3\n",
+			want:    forge.TestStats{Total: 3, Passed: 3},
+			wantOK:  true,
+		},
+		{
+			name:    "optional group not matched",
+			pattern: `passed=(?P<passed>\d+)(?: failed=(?P<failed>\d+))?`,
+			stdout:  "passed=2",
+			want:    forge.TestStats{Total: 2, Passed: 2},
+			wantOK:  true,
+		},
+		{
+			name:    "no match",
+			pattern: `passed=(?P<passed>\d+)`,
+			stdout:  "nothing here",
+			wantOK:  false,
+		},
+		{
+			name:    "invalid regex",
+			pattern: `(?P<passed>\d+`,
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric group",
+			pattern: `passed=(?P<passed>\w+)`,
+			stdout:  "passed=many",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := parseTestStats(tt.pattern, tt.stdout)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildTestReport(t *testing.T) {
+	input := mcptypes.RunInput{ID: "report-id", Stage: "lint", Name: "lint"}
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		spec       Spec
+		output     ExecuteOutput
+		wantStatus string
+		wantStats  forge.TestStats
+	}{
+		{
+			name:       "success without regex",
+			output:     ExecuteOutput{ExitCode: 0},
+			wantStatus: "passed",
+			wantStats:  forge.TestStats{Total: 1, Passed: 1},
+		},
+		{
+			name:       "failure without regex",
+			output:     ExecuteOutput{ExitCode: 2},
+			wantStatus: "failed",
+			wantStats:  forge.TestStats{Total: 1, Failed: 1},
+		},
+		{
+			name:       "counts from regex",
+			spec:       Spec{ParseRegex: `(?P<passed>\d+) passed`},
+			output:     ExecuteOutput{ExitCode: 0, Stdout: "12 passed"},
+			wantStatus: "passed",
+			wantStats:  forge.TestStats{Total: 12, Passed: 12},
+		},
+		{
+			name:       "reported failures fail the run",
+			spec:       Spec{ParseRegex: `(?P<passed>\d+) passed, (?P<failed>\d+) failed`},
+			output:     ExecuteOutput{ExitCode: 0, Stdout: "3 passed, 1 failed"},
+			wantStatus: "failed",
+			wantStats:  forge.TestStats{Total: 4, Passed: 3, Failed: 1},
+		},
+		{
+			name:       "regex without match falls back to exit code",
+			spec:       Spec{ParseRegex: `(?P<passed>\d+) passed`},
+			output:     ExecuteOutput{ExitCode: 1, Stdout: "boom"},
+			wantStatus: "failed",
+			wantStats:  forge.TestStats{Total: 1, Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := buildTestReport(input, &tt.spec, tt.output, startTime, 1.5)
+			require.NoError(t, err)
+
+			assert.Equal(t, "report-id", report.ID)
+			assert.Equal(t, "lint", report.Stage)
+			assert.Equal(t, tt.wantStatus, report.Status)
+			assert.Equal(t, tt.wantStats, report.TestStats)
+			assert.Equal(t, startTime, report.StartTime)
+			assert.InDelta(t, 1.5, report.Duration, 0)
+			if tt.wantStatus == "failed" {
+				assert.NotEmpty(t, report.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestRun_StoresRetrievableReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	storePath := filepath.Join(tmpDir, "artifact-store.yaml")
+	t.Setenv("FORGE_ARTIFACT_STORE_PATH", storePath)
+
+	input := mcptypes.RunInput{
+		ID:              "generic-report",
+		Stage:           "smoke",
+		Name:            "smoke",
+		DirectoryParams: mcptypes.DirectoryParams{TmpDir: tmpDir},
+	}
+	spec := &Spec{
+		Command:    "sh",
+		Args:       []string{"-c", "echo '5 passed, 1 failed'; echo oops >&2; exit 1"},
+		ParseRegex: `(?P<passed>\d+) passed, (?P<failed>\d+) failed`,
+	}
+
+	report, err := Run(context.Background(), input, spec)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", report.Status)
+	assert.Equal(t, forge.TestStats{Total: 6, Passed: 5, Failed: 1}, report.TestStats)
+
+	// Captured output is kept next to the report
+	require.NotEmpty(t, report.OutputPath)
+	content, err := os.ReadFile(report.OutputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "5 passed, 1 failed")
+	assert.Contains(t, string(content), "oops")
+
+	// The report is retrievable from the artifact store, even though the run failed
+	store, err := forge.ReadArtifactStore(storePath)
+	require.NoError(t, err)
+	stored, err := forge.GetTestReport(&store, "generic-report")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", stored.Status)
+	assert.Equal(t, report.TestStats, stored.TestStats)
+	assert.Equal(t, []string{report.OutputPath}, stored.ArtifactFiles)
+}
+
+func TestRun_InvalidParseRegex(t *testing.T) {
+	input := mcptypes.RunInput{Stage: "smoke", Name: "smoke"}
+	spec := &Spec{Command: "true", ParseRegex: "("}
+
+	_, err := Run(context.Background(), input, spec)
+	assert.ErrorContains(t, err, "invalid parseRegex")
+}
+
+func TestTailBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		writes []string
+		want   string
+	}{
+		{name: "under the cap", max: 10, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "exactly the cap", max: 6, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "beyond the cap across writes", max: 4, writes: []string{"abc", "def"}, want: "[... 2 bytes truncated ...]\ncdef"},
+		{name: "single write beyond the cap", max: 3, writes: []string{"abcdefgh"}, want: "[... 5 bytes truncated ...]\nfgh"},
+		{name: "large write after small ones", max: 3, writes: []string{"ab", "cdefg"}, want: "[... 4 bytes truncated ...]\nefg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTailBuffer(tt.max)
+			for _, w := range tt.writes {
+				n, err := b.Write([]byte(w))
+				require.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func TestNewTailBuffer_DefaultCap(t *testing.T) {
+	assert.Equal(t, defaultMaxCaptureBytes, newTailBuffer(0).max)
+}
+
+func TestExecuteCommand_TruncatesOutputBeyondCap(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(map[bool]string{false: "buffered", true: "streaming"}[stream], func(t *testing.T) {
+			output := executeCommand(ExecuteInput{
+				Command:         "sh",
+				Args:            []string{"-c", "printf '0123456789abcdef'; printf 'err-output' >&2; exit 3"},
+				Stream:          stream,
+				MaxCaptureBytes: 6,
+			})
+
+			// Exit code handling is preserved
+			assert.Equal(t, 3, output.ExitCode)
+			assert.Empty(t, output.Error)
+
+			assert.Equal(t, "[... 10 bytes truncated ...]\nabcdef", output.Stdout)
+			assert.True(t, strings.HasPrefix(output.Stderr, "[... 4 bytes truncated ...]"), output.Stderr)
+			assert.True(t, strings.HasSuffix(output.Stderr, "output"), output.Stderr)
+		})
+	}
+}
+
+func TestExecuteCommand_StreamingCapturesFullOutputUnderCap(t *testing.T) {
+	output := executeCommand(ExecuteInput{
+		Command: "sh",
+		Args:    []string{"-c", "echo hello; echo world >&2"},
+		Stream:  true,
+	})
+
+	assert.Equal(t, 0, output.ExitCode)
+	assert.Equal(t, "hello\n", output.Stdout)
+	assert.Equal(t, "world\n", output.Stderr)
+}
+
+func TestExecuteCommand_EnvFilePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.env")
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	require.NoError(t, os.WriteFile(legacy, []byte("A=legacy\nB=legacy\nC=legacy\nD=legacy\n"), 0o600))
+	require.NoError(t, os.WriteFile(base, []byte("B=base\nC=base\nD=base\n"), 0o600))
+	require.NoError(t, os.WriteFile(local, []byte("C=local\nD=local\n"), 0o600))
+
+	output := executeCommand(ExecuteInput{
+		Command:  "sh",
+		Args:     []string{"-c", `printf '%s %s %s %s' "$A" "$B" "$C" "$D"`},
+		EnvFile:  legacy,
+		EnvFiles: []string{base, local},
+		Env:      map[string]string{"D": "inline"},
+	})
+
+	require.Equal(t, 0, output.ExitCode, output.Error)
+	assert.Equal(t, "legacy base local inline", output.Stdout)
+}
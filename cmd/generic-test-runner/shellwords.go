@@ -0,0 +1,134 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errUnterminatedQuote = errors.New("unterminated quote")
+	errTrailingBackslash = errors.New("trailing backslash")
+	errEmptyShellCommand = errors.New("command is empty")
+)
+
+// splitShellWords splits s into words like a POSIX shell, without any expansion:
+//   - unquoted whitespace separates words
+//   - single quotes preserve every character literally
+//   - double quotes preserve characters, except backslash escaping ", \, $, ` and newline
+//   - an unquoted backslash preserves the next character (a backslash-newline is removed)
+//
+// Quoted empty strings produce empty words.
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		runes   = []rune(s)
+		escapes = "\"\\$`\n"
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, errTrailingBackslash
+			}
+			i++
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+
+		case r == '\'':
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					closed = true
+					break
+				}
+				word.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, errUnterminatedQuote
+			}
+			inWord = true
+
+		case r == '"':
+			closed := false
+			for i++; i < len(runes); i++ {
+				c := runes[i]
+				if c == '"' {
+					closed = true
+					break
+				}
+				if c == '\\' && i+1 < len(runes) && strings.ContainsRune(escapes, runes[i+1]) {
+					i++
+					if runes[i] != '\n' {
+						word.WriteRune(runes[i])
+					}
+					continue
+				}
+				word.WriteRune(c)
+			}
+			if !closed {
+				return nil, errUnterminatedQuote
+			}
+			inWord = true
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// resolveCommandLine returns the program and arguments to execute for command and args.
+// With shell, the command string is a script run by "sh -c" and args are passed as its
+// positional parameters ($1, $2, ...). Otherwise, the command string is split with
+// splitShellWords and args are appended to the resulting words.
+func resolveCommandLine(command string, args []string, shell bool) (string, []string, error) {
+	if shell {
+		if strings.TrimSpace(command) == "" {
+			return "", nil, errEmptyShellCommand
+		}
+		// "sh" is $0, so that args start at $1
+		return "sh", append([]string{"-c", command, "sh"}, args...), nil
+	}
+
+	words, err := splitShellWords(command)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(words) == 0 {
+		return "", nil, errEmptyShellCommand
+	}
+
+	return words[0], append(words[1:], args...), nil
+}
@@ -0,0 +1,144 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "whitespace only", input: " \t\n ", want: nil},
+		{name: "simple words", input: "go test ./... -run Foo", want: []string{"go", "test", "./...", "-run", "Foo"}},
+		{name: "repeated whitespace", input: "  go\t\ttest  ", want: []string{"go", "test"}},
+		{name: "double quotes", input: `go test -run "Foo Bar"`, want: []string{"go", "test", "-run", "Foo Bar"}},
+		{name: "single quotes", input: `grep -E 'a|b c'`, want: []string{"grep", "-E", "a|b c"}},
+		{name: "single quotes keep backslashes and dollars", input: `echo '\n $HOME "x"'`, want: []string{"echo", `\n $HOME "x"`}},
+		{name: "double quotes keep dollars unexpanded", input: `echo "$HOME"`, want: []string{"echo", "$HOME"}},
+		{name: "escapes in double quotes", input: `echo "a \"b\" \\ \$c \x"`, want: []string{"echo", `a "b" \ $c \x`}},
+		{name: "escaped space", input: `ls my\ dir`, want: []string{"ls", "my dir"}},
+		{name: "escaped quote", input: `echo it\'s`, want: []string{"echo", "it's"}},
+		{name: "line continuation", input: "go test \\\n./...", want: []string{"go", "test", "./..."}},
+		{name: "adjacent quoted parts form one word", input: `-ldflags="-X main.v=1"'.0'`, want: []string{"-ldflags=-X main.v=1.0"}},
+		{name: "empty quoted words", input: `cmd "" ''`, want: []string{"cmd", "", ""}},
+		{name: "unicode", input: `echo "héllo wörld"`, want: []string{"echo", "héllo wörld"}},
+		{name: "unterminated double quote", input: `echo "foo`, wantErr: errUnterminatedQuote},
+		{name: "unterminated single quote", input: `echo 'foo`, wantErr: errUnterminatedQuote},
+		{name: "trailing backslash", input: `echo foo\`, wantErr: errTrailingBackslash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitShellWords(tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveCommandLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		args        []string
+		shell       bool
+		wantProgram string
+		wantArgs    []string
+		wantErr     error
+	}{
+		{
+			name:        "plain command keeps arg list",
+			command:     "golangci-lint",
+			args:        []string{"run", "./..."},
+			wantProgram: "golangci-lint",
+			wantArgs:    []string{"run", "./..."},
+		},
+		{
+			name:        "command string is split and args appended",
+			command:     `go test -run "Foo|Bar"`,
+			args:        []string{"./pkg/..."},
+			wantProgram: "go",
+			wantArgs:    []string{"test", "-run", "Foo|Bar", "./pkg/..."},
+		},
+		{
+			name:        "shell mode passes args as positional parameters",
+			command:     `echo "$1" | tr a-z A-Z`,
+			args:        []string{"hello"},
+			shell:       true,
+			wantProgram: "sh",
+			wantArgs:    []string{"-c", `echo "$1" | tr a-z A-Z`, "sh", "hello"},
+		},
+		{name: "empty command", command: "  ", wantErr: errEmptyShellCommand},
+		{name: "empty shell command", command: "", shell: true, wantErr: errEmptyShellCommand},
+		{name: "invalid quoting", command: `go test -run "Foo`, wantErr: errUnterminatedQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, args, err := resolveCommandLine(tt.command, tt.args, tt.shell)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProgram, program)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestRun_ShellCommand(t *testing.T) {
+	input := mcptypes.RunInput{Stage: "smoke", Name: "smoke"}
+	t.Setenv("FORGE_ARTIFACT_STORE_PATH", t.TempDir()+"/artifact-store.yaml")
+
+	t.Run("quoted argument survives splitting", func(t *testing.T) {
+		spec := &Spec{Command: `sh -c 'test "$0" = "a b"' "a b"`}
+		report, err := Run(context.Background(), input, spec)
+		require.NoError(t, err)
+		assert.Equal(t, "passed", report.Status)
+	})
+
+	t.Run("shell mode supports pipes", func(t *testing.T) {
+		spec := &Spec{Command: `echo "$1" | grep -q needle`, Args: []string{"haystack with needle"}, Shell: true}
+		report, err := Run(context.Background(), input, spec)
+		require.NoError(t, err)
+		assert.Equal(t, "passed", report.Status)
+	})
+
+	t.Run("shell mode preserves exit code", func(t *testing.T) {
+		spec := &Spec{Command: "exit 4", Shell: true}
+		report, err := Run(context.Background(), input, spec)
+		require.NoError(t, err)
+		assert.Equal(t, "failed", report.Status)
+		assert.Contains(t, report.ErrorMessage, "code 4")
+	})
+}
@@ -1,20 +1,28 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml
-// SourceChecksum: sha256:eb6aaa79427e6ad5878ea2c5275ca6f81a5c47d1faf5a369223c5617377d3f1a
+// SourceChecksum: sha256:21eef1b6feb6f395a432342a7a14d6cb467314bd12e639576f219d5a56acd159
 
 package main
 
 import (
+	"embed"
+
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for documentation tools.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "generic-test-runner",
 	LocalDir:     "cmd/generic-test-runner/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 // RegisterDocsMCPTools registers the docs-list and docs-get MCP tools.
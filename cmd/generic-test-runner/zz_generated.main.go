@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml + spec.openapi.yaml
-// SourceChecksum: sha256:eb6aaa79427e6ad5878ea2c5275ca6f81a5c47d1faf5a369223c5617377d3f1a
+// SourceChecksum: sha256:21eef1b6feb6f395a432342a7a14d6cb467314bd12e639576f219d5a56acd159
 
 package main
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
@@ -35,26 +36,37 @@ func main() {
 		RunCLI:         nil, // Generated engines are MCP-only
 		RunMCP:         runMCPServer,
 		DocsConfig:     docsConfig,
+		SetupMCP:       setupMCPServer,
 	})
 }
 
 // runMCPServer creates and runs the MCP server.
 func runMCPServer() error {
+	server, err := setupMCPServer()
+	if err != nil {
+		return err
+	}
+
+	if err := server.Run(enginecli.Context()); err != nil {
+		return fmt.Errorf("running MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// setupMCPServer creates the MCP server and registers all its tools.
+func setupMCPServer() (*mcpserver.Server, error) {
 	server, err := SetupMCPServer(Name, Version, Run)
 	if err != nil {
-		return fmt.Errorf("setting up MCP server: %w", err)
+		return nil, fmt.Errorf("setting up MCP server: %w", err)
 	}
 
 	// Register docs MCP tools (docs-list, docs-get)
 	if err := RegisterDocsMCPTools(server); err != nil {
-		return fmt.Errorf("registering docs MCP tools: %w", err)
-	}
-
-	if err := server.Run(context.Background()); err != nil {
-		return fmt.Errorf("running MCP server: %w", err)
+		return nil, fmt.Errorf("registering docs MCP tools: %w", err)
 	}
 
-	return nil
+	return server, nil
 }
 
 // Run is the run function that must be implemented by the engine author.
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:eb6aaa79427e6ad5878ea2c5275ca6f81a5c47d1faf5a369223c5617377d3f1a
+// SourceChecksum: sha256:21eef1b6feb6f395a432342a7a14d6cb467314bd12e639576f219d5a56acd159
 
 package main
 
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:eb6aaa79427e6ad5878ea2c5275ca6f81a5c47d1faf5a369223c5617377d3f1a
+// SourceChecksum: sha256:21eef1b6feb6f395a432342a7a14d6cb467314bd12e639576f219d5a56acd159
 
 package main
 
@@ -19,8 +19,21 @@ type Spec struct {
 	Context string `json:"context,omitempty"`
 	// Environment variables (optional)
 	Env map[string]string `json:"env,omitempty"`
-	// Path to environment file (optional)
+	// Deprecated: use envFiles. Path to environment file, loaded before envFiles (optional)
 	EnvFile string `json:"envFile,omitempty"`
+	// Paths to environment files loaded in order; a later file overrides the keys of earlier ones and can reference them. Inline env always overrides env files (optional).
+	//
+	EnvFiles []string `json:"envFiles,omitempty"`
+	// Maximum number of bytes of stdout and of stderr kept for the report; older output is truncated (optional, default 1048576)
+	MaxCaptureBytes int `json:"maxCaptureBytes,omitempty"`
+	// Regular expression applied to stdout to extract test counts (optional). Use the named groups "total", "passed", "failed" and "skipped"; the last match wins. Without it, the command counts as a single test that passed or failed based on its exit code.
+	//
+	ParseRegex string `json:"parseRegex,omitempty"`
+	// Run the command string with "sh -c", enabling pipes, redirections and variable expansion (optional). Args are passed as positional parameters ($1, $2, ...). When false, the command string is split into words honoring quotes and backslash escapes, without any expansion.
+	//
+	Shell bool `json:"shell,omitempty"`
+	// Stream the command output to stderr in real time instead of logging it once the command exits (optional)
+	Stream bool `json:"stream,omitempty"`
 }
 
 // SpecFromMap creates a Spec from a map[string]interface{}.
@@ -88,6 +101,60 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field envFile: expected string, got %T", v)
 		}
 	}
+	// Parse envFiles
+	if v, ok := m["envFiles"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.EnvFiles = make([]string, 0, len(arr))
+			for i, item := range arr {
+				if str, ok := item.(string); ok {
+					s.EnvFiles = append(s.EnvFiles, str)
+				} else {
+					return nil, fmt.Errorf("field envFiles[%d]: expected string, got %T", i, item)
+				}
+			}
+		} else if arr, ok := v.([]string); ok {
+			s.EnvFiles = arr
+		} else {
+			return nil, fmt.Errorf("field envFiles: expected []string, got %T", v)
+		}
+	}
+	// Parse maxCaptureBytes
+	if v, ok := m["maxCaptureBytes"]; ok && v != nil {
+		switch val := v.(type) {
+		case int:
+			s.MaxCaptureBytes = val
+		case int64:
+			s.MaxCaptureBytes = int(val)
+		case float64:
+			s.MaxCaptureBytes = int(val)
+		default:
+			return nil, fmt.Errorf("field maxCaptureBytes: expected int, got %T", v)
+		}
+	}
+	// Parse parseRegex
+	if v, ok := m["parseRegex"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.ParseRegex = val
+		} else {
+			return nil, fmt.Errorf("field parseRegex: expected string, got %T", v)
+		}
+	}
+	// Parse shell
+	if v, ok := m["shell"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.Shell = val
+		} else {
+			return nil, fmt.Errorf("field shell: expected bool, got %T", v)
+		}
+	}
+	// Parse stream
+	if v, ok := m["stream"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.Stream = val
+		} else {
+			return nil, fmt.Errorf("field stream: expected bool, got %T", v)
+		}
+	}
 	return s, nil
 }
 
@@ -113,6 +180,21 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if s.EnvFile != "" {
 		m["envFile"] = s.EnvFile
 	}
+	if len(s.EnvFiles) > 0 {
+		m["envFiles"] = s.EnvFiles
+	}
+	if s.MaxCaptureBytes != 0 {
+		m["maxCaptureBytes"] = s.MaxCaptureBytes
+	}
+	if s.ParseRegex != "" {
+		m["parseRegex"] = s.ParseRegex
+	}
+	if s.Shell {
+		m["shell"] = s.Shell
+	}
+	if s.Stream {
+		m["stream"] = s.Stream
+	}
 	return m
 }
 
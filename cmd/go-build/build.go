@@ -75,22 +75,33 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 		}
 	}
 
-	// Build command arguments
-	args := []string{
-		"build",
-		"-o", outputPath,
+	// spec.cgo takes precedence over CGO_ENABLED set in env
+	if cgoEnabled, ok := cgoEnabledValue(spec.Cgo); ok {
+		if err := os.Setenv("CGO_ENABLED", cgoEnabled); err != nil {
+			return nil, fmt.Errorf("failed to set CGO_ENABLED: %w", err)
+		}
 	}
 
-	// Add ldflags from environment if provided
-	if ldflags := os.Getenv("GO_BUILD_LDFLAGS"); ldflags != "" {
-		args = append(args, "-ldflags", ldflags)
+	// Merge spec ldflags with the ldflags from environment (GO_BUILD_LDFLAGS takes precedence)
+	specLdflags, err := renderLdflags(spec.Ldflags, engineframework.GetGitVersion)
+	if err != nil {
+		return nil, err
+	}
+	ldflags, overridden := mergeLdflags(specLdflags, os.Getenv("GO_BUILD_LDFLAGS"))
+	for _, flag := range overridden {
+		log.Printf("Warning: spec.ldflags %q is overridden by GO_BUILD_LDFLAGS", flag)
 	}
 
-	// Add custom args if provided
-	args = append(args, customArgs...)
+	// Cross-compile every target, each producing its own artifact
+	if len(spec.Targets) > 0 {
+		if err := validateTargets(spec.Targets); err != nil {
+			return nil, err
+		}
+		return nil, buildTargets(ctx, input, spec.Targets, dest, spec.Tags, ldflags, customArgs)
+	}
 
-	// Add source path
-	args = append(args, input.Src)
+	// Build command arguments
+	args := goBuildArgs(outputPath, input.Src, spec.Tags, ldflags, customArgs)
 
 	// Execute build
 	cmd := exec.Command("go", args...)
@@ -111,6 +122,13 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 		return nil, fmt.Errorf("failed to create artifact: %w", err)
 	}
 
+	// Record the binary checksum so that the artifact can be verified later
+	checksum, err := forge.ComputeChecksum(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	artifact.Checksum = checksum
+
 	// Detect dependencies if this is a main package
 	if err := detectDependenciesForArtifact(input.Src, artifact); err != nil {
 		return nil, fmt.Errorf("failed to detect dependencies: %w", err)
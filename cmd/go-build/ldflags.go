@@ -0,0 +1,154 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ----------------------------------------------------- BUILD FLAGS ------------------------------------------------ //
+
+// ldflagsData is the data available to spec.ldflags templates.
+type ldflagsData struct {
+	// GitVersion is the git commit SHA the binary is built from.
+	GitVersion string
+}
+
+// renderLdflags expands the {{.GitVersion}} template of spec.ldflags.
+// getGitVersion is only called when the flags contain a template.
+func renderLdflags(ldflags string, getGitVersion func() (string, error)) (string, error) {
+	if !strings.Contains(ldflags, "{{") {
+		return ldflags, nil
+	}
+
+	tmpl, err := template.New("ldflags").Option("missingkey=error").Parse(ldflags)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse spec.ldflags template: %w", err)
+	}
+
+	gitVersion, err := getGitVersion()
+	if err != nil {
+		return "", fmt.Errorf("failed to render spec.ldflags: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ldflagsData{GitVersion: gitVersion}); err != nil {
+		return "", fmt.Errorf("failed to render spec.ldflags: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// mergeLdflags merges the user-supplied spec.ldflags with the canonical ldflags forge passes
+// through GO_BUILD_LDFLAGS.
+// The canonical flags are appended last, and a user `-X` flag setting a symbol that the canonical
+// flags also set is dropped, so that forge's version information always wins.
+// The overridden user flags are returned so that callers can warn about them.
+func mergeLdflags(user, canonical string) (string, []string) {
+	canonicalTokens := splitLdflags(canonical)
+	canonicalSymbols := make(map[string]bool)
+	for _, assignment := range xAssignments(canonicalTokens) {
+		canonicalSymbols[xSymbol(assignment)] = true
+	}
+
+	userTokens := splitLdflags(user)
+	merged := make([]string, 0, len(userTokens)+len(canonicalTokens))
+	var overridden []string
+	for i := 0; i < len(userTokens); i++ {
+		token := userTokens[i]
+
+		assignment, width := xAssignmentAt(userTokens, i)
+		if width > 0 && canonicalSymbols[xSymbol(assignment)] {
+			overridden = append(overridden, "-X "+assignment)
+			i += width - 1
+			continue
+		}
+
+		merged = append(merged, token)
+	}
+
+	merged = append(merged, canonicalTokens...)
+	return strings.Join(merged, " "), overridden
+}
+
+// splitLdflags splits linker flags on whitespace.
+func splitLdflags(ldflags string) []string {
+	return strings.Fields(ldflags)
+}
+
+// xAssignments returns the `symbol=value` assignments of all `-X` flags.
+func xAssignments(tokens []string) []string {
+	var assignments []string
+	for i := 0; i < len(tokens); i++ {
+		if assignment, width := xAssignmentAt(tokens, i); width > 0 {
+			assignments = append(assignments, assignment)
+			i += width - 1
+		}
+	}
+	return assignments
+}
+
+// xAssignmentAt returns the `symbol=value` assignment of the `-X` flag starting at tokens[i]
+// and the number of tokens it spans (0 if tokens[i] is not a `-X` flag).
+// Both `-X symbol=value` and `-X=symbol=value` forms are recognized.
+func xAssignmentAt(tokens []string, i int) (string, int) {
+	switch token := tokens[i]; {
+	case token == "-X" && i+1 < len(tokens):
+		return tokens[i+1], 2
+	case strings.HasPrefix(token, "-X="):
+		return strings.TrimPrefix(token, "-X="), 1
+	default:
+		return "", 0
+	}
+}
+
+// xSymbol returns the symbol of a `symbol=value` assignment.
+func xSymbol(assignment string) string {
+	symbol, _, _ := strings.Cut(assignment, "=")
+	return symbol
+}
+
+// goBuildArgs returns the arguments of the `go build` command.
+func goBuildArgs(outputPath, src string, tags []string, ldflags string, customArgs []string) []string {
+	args := []string{
+		"build",
+		"-o", outputPath,
+	}
+
+	if len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, ","))
+	}
+
+	if ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
+	}
+
+	args = append(args, customArgs...)
+
+	return append(args, src)
+}
+
+// cgoEnabledValue returns the CGO_ENABLED value for spec.cgo, or false if spec.cgo is unset.
+func cgoEnabledValue(cgo *bool) (string, bool) {
+	if cgo == nil {
+		return "", false
+	}
+	if *cgo {
+		return "1", true
+	}
+	return "0", true
+}
@@ -0,0 +1,138 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRenderLdflags(t *testing.T) {
+	calls := 0
+	getGitVersion := func() (string, error) {
+		calls++
+		return "abc1234", nil
+	}
+
+	got, err := renderLdflags("-X main.Version={{.GitVersion}} -s -w", getGitVersion)
+	if err != nil {
+		t.Fatalf("renderLdflags() error = %v", err)
+	}
+	if want := "-X main.Version=abc1234 -s -w"; got != want {
+		t.Errorf("renderLdflags() = %q, want %q", got, want)
+	}
+
+	// Plain flags do not need the git version
+	if got, err := renderLdflags("-s -w", getGitVersion); err != nil || got != "-s -w" {
+		t.Errorf("renderLdflags() = %q, %v, want %q", got, err, "-s -w")
+	}
+	if calls != 1 {
+		t.Errorf("getGitVersion called %d times, want 1", calls)
+	}
+
+	failing := func() (string, error) { return "", errors.New("not a git repository") }
+	if _, err := renderLdflags("-X main.Version={{.GitVersion}}", failing); err == nil {
+		t.Error("renderLdflags() expected an error when the git version is unavailable")
+	}
+	if _, err := renderLdflags("-X main.Version={{.Unknown}}", getGitVersion); err == nil {
+		t.Error("renderLdflags() expected an error for an unknown template field")
+	}
+}
+
+func TestMergeLdflags(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           string
+		canonical      string
+		want           string
+		wantOverridden []string
+	}{
+		{
+			name: "user only",
+			user: "-s -w -X main.Version=v1",
+			want: "-s -w -X main.Version=v1",
+		},
+		{
+			name:      "canonical only",
+			canonical: "-X main.Version=v2",
+			want:      "-X main.Version=v2",
+		},
+		{
+			name:      "no conflict",
+			user:      "-s -w -X main.Name=app",
+			canonical: "-X main.Version=v2",
+			want:      "-s -w -X main.Name=app -X main.Version=v2",
+		},
+		{
+			name:           "conflicting version is overridden",
+			user:           "-s -X main.Version=v1 -X=main.CommitSHA=dirty -w",
+			canonical:      "-X main.Version=v2 -X main.CommitSHA=abc1234",
+			want:           "-s -w -X main.Version=v2 -X main.CommitSHA=abc1234",
+			wantOverridden: []string{"-X main.Version=v1", "-X main.CommitSHA=dirty"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, overridden := mergeLdflags(tt.user, tt.canonical)
+			if got != tt.want {
+				t.Errorf("mergeLdflags() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(overridden, tt.wantOverridden) {
+				t.Errorf("mergeLdflags() overridden = %v, want %v", overridden, tt.wantOverridden)
+			}
+		})
+	}
+}
+
+func TestGoBuildArgs(t *testing.T) {
+	ldflags, _ := mergeLdflags("-s -w -X main.Version=v1", "-X main.Version=v2")
+
+	got := goBuildArgs("build/bin/app", "./cmd/app", []string{"netgo", "osusergo"}, ldflags, []string{"-trimpath"})
+	want := []string{
+		"build",
+		"-o", "build/bin/app",
+		"-tags", "netgo,osusergo",
+		"-ldflags", "-s -w -X main.Version=v2",
+		"-trimpath",
+		"./cmd/app",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("goBuildArgs() =\n%v\nwant\n%v", got, want)
+	}
+
+	got = goBuildArgs("build/bin/app", "./cmd/app", nil, "", nil)
+	want = []string{"build", "-o", "build/bin/app", "./cmd/app"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("goBuildArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestCgoEnabledValue(t *testing.T) {
+	enabled, disabled := true, false
+
+	if _, ok := cgoEnabledValue(nil); ok {
+		t.Error("cgoEnabledValue(nil) should leave CGO_ENABLED unchanged")
+	}
+	if got, ok := cgoEnabledValue(&enabled); !ok || got != "1" {
+		t.Errorf("cgoEnabledValue(true) = %q, %v, want \"1\", true", got, ok)
+	}
+	if got, ok := cgoEnabledValue(&disabled); !ok || got != "0" {
+		t.Errorf("cgoEnabledValue(false) = %q, %v, want \"0\", true", got, ok)
+	}
+}
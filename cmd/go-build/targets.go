@@ -0,0 +1,154 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+)
+
+// targetArtifactName returns the artifact name of a cross-compiled target, e.g. "my-app-linux-amd64".
+func targetArtifactName(name string, t Target) string {
+	return fmt.Sprintf("%s-%s-%s", name, t.Goos, t.Goarch)
+}
+
+// targetOutputPath returns the binary path of a cross-compiled target in dest.
+func targetOutputPath(dest, name string, t Target) string {
+	output := targetArtifactName(name, t)
+	if t.Goos == "windows" {
+		output += ".exe"
+	}
+	return filepath.Join(dest, output)
+}
+
+// validateTargets checks that every target has a GOOS and GOARCH and that no target is listed twice.
+func validateTargets(targets []Target) error {
+	seen := make(map[Target]bool, len(targets))
+	for i, t := range targets {
+		if t.Goos == "" || t.Goarch == "" {
+			return fmt.Errorf("targets[%d]: goos and goarch are required", i)
+		}
+		if seen[t] {
+			return fmt.Errorf("targets[%d]: duplicate target %s/%s", i, t.Goos, t.Goarch)
+		}
+		seen[t] = true
+	}
+	return nil
+}
+
+// buildTargets cross-compiles input.Src for every target concurrently and adds one artifact per
+// target to ctx. A failing target does not stop the others; all failures are returned together.
+func buildTargets(
+	ctx context.Context,
+	input mcptypes.BuildInput,
+	targets []Target,
+	dest string,
+	tags []string,
+	ldflags string,
+	customArgs []string,
+) error {
+	artifacts := make([]*forge.Artifact, len(targets))
+	errs := make([]error, len(targets))
+	sem := make(chan struct{}, min(runtime.NumCPU(), len(targets)))
+	var wg sync.WaitGroup
+
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			artifact, err := buildTarget(input, t, dest, tags, ldflags, customArgs)
+			if err != nil {
+				errs[i] = fmt.Errorf("target %s/%s: %w", t.Goos, t.Goarch, err)
+				return
+			}
+			artifacts[i] = artifact
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("go build failed: %w", err)
+	}
+
+	// All targets are built from the same sources, so dependencies are detected once
+	if err := detectDependenciesForArtifact(input.Src, artifacts[0]); err != nil {
+		return fmt.Errorf("failed to detect dependencies: %w", err)
+	}
+
+	result := make([]forge.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		artifact.Dependencies = artifacts[0].Dependencies
+		artifact.DependencyDetectorEngine = artifacts[0].DependencyDetectorEngine
+		artifact.DependencyDetectorSpec = artifacts[0].DependencyDetectorSpec
+		result = append(result, *artifact)
+	}
+
+	if !engineframework.AddArtifacts(ctx, result...) {
+		return fmt.Errorf("builder does not support multiple artifacts")
+	}
+
+	return nil
+}
+
+// buildTarget builds a single target. GOOS and GOARCH are set on the command only, since targets
+// are built concurrently in the same process.
+func buildTarget(
+	input mcptypes.BuildInput,
+	t Target,
+	dest string,
+	tags []string,
+	ldflags string,
+	customArgs []string,
+) (*forge.Artifact, error) {
+	name := targetArtifactName(input.Name, t)
+	outputPath := targetOutputPath(dest, input.Name, t)
+
+	cmd := exec.Command("go", goBuildArgs(outputPath, input.Src, tags, ldflags, customArgs)...)
+	cmd.Env = append(os.Environ(), "GOOS="+t.Goos, "GOARCH="+t.Goarch)
+	cmd.Stdout = os.Stderr // MCP mode: redirect to stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	artifact, err := engineframework.CreateVersionedArtifact(name, "binary", outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact: %w", err)
+	}
+
+	checksum, err := forge.ComputeChecksum(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	artifact.Checksum = checksum
+
+	fmt.Fprintf(os.Stderr, "Built binary: %s (version: %s)\n", name, artifact.Version)
+
+	return artifact, nil
+}
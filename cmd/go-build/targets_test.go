@@ -0,0 +1,155 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+)
+
+func TestSpecFromMap_Targets(t *testing.T) {
+	spec, err := SpecFromMap(map[string]interface{}{
+		"targets": []interface{}{
+			map[string]interface{}{"goos": "linux", "goarch": "amd64"},
+			map[string]interface{}{"goos": "darwin", "goarch": "arm64"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SpecFromMap() error = %v", err)
+	}
+
+	want := []Target{{Goos: "linux", Goarch: "amd64"}, {Goos: "darwin", Goarch: "arm64"}}
+	if len(spec.Targets) != len(want) {
+		t.Fatalf("Targets = %+v, want %+v", spec.Targets, want)
+	}
+	for i := range want {
+		if spec.Targets[i] != want[i] {
+			t.Errorf("Targets[%d] = %+v, want %+v", i, spec.Targets[i], want[i])
+		}
+	}
+
+	if out := Validate(spec); !out.Valid {
+		t.Errorf("Validate() = %+v, want valid", out)
+	}
+}
+
+func TestSpecFromMap_TargetsInvalid(t *testing.T) {
+	if _, err := SpecFromMap(map[string]interface{}{"targets": []interface{}{"linux/amd64"}}); err == nil {
+		t.Error("SpecFromMap() expected error for non-object target")
+	}
+
+	spec, err := SpecFromMap(map[string]interface{}{
+		"targets": []interface{}{map[string]interface{}{"goos": "linux"}},
+	})
+	if err != nil {
+		t.Fatalf("SpecFromMap() error = %v", err)
+	}
+	if out := Validate(spec); out.Valid {
+		t.Error("Validate() expected invalid result for target without goarch")
+	}
+}
+
+func TestTargetArtifactName(t *testing.T) {
+	got := targetArtifactName("my-app", Target{Goos: "linux", Goarch: "arm64"})
+	if got != "my-app-linux-arm64" {
+		t.Errorf("targetArtifactName() = %q, want %q", got, "my-app-linux-arm64")
+	}
+}
+
+func TestTargetOutputPath(t *testing.T) {
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{Target{Goos: "linux", Goarch: "amd64"}, filepath.Join("build", "bin", "my-app-linux-amd64")},
+		{Target{Goos: "darwin", Goarch: "arm64"}, filepath.Join("build", "bin", "my-app-darwin-arm64")},
+		{Target{Goos: "windows", Goarch: "amd64"}, filepath.Join("build", "bin", "my-app-windows-amd64.exe")},
+	}
+
+	for _, tt := range tests {
+		if got := targetOutputPath("build/bin", "my-app", tt.target); got != tt.want {
+			t.Errorf("targetOutputPath(%+v) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []Target
+		wantErr string
+	}{
+		{"valid", []Target{{Goos: "linux", Goarch: "amd64"}, {Goos: "linux", Goarch: "arm64"}}, ""},
+		{"missing goos", []Target{{Goarch: "amd64"}}, "targets[0]: goos and goarch are required"},
+		{"missing goarch", []Target{{Goos: "linux"}}, "targets[0]: goos and goarch are required"},
+		{"duplicate", []Target{{Goos: "linux", Goarch: "amd64"}, {Goos: "linux", Goarch: "amd64"}}, "targets[1]: duplicate target linux/amd64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTargets(tt.targets)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateTargets() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validateTargets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildTargets_FailingTargetDoesNotAbortOthers(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+
+	input := mcptypes.BuildInput{Name: "app", Src: "."}
+	targets := []Target{{Goos: "linux", Goarch: "amd64"}, {Goos: "linux", Goarch: "bogus"}}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(src); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	err = buildTargets(context.Background(), input, targets, dest, nil, "", nil)
+	if err == nil {
+		t.Fatal("buildTargets() expected error for unsupported target")
+	}
+	if !strings.Contains(err.Error(), "target linux/bogus") {
+		t.Errorf("buildTargets() error = %v, want linux/bogus failure", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "app-linux-amd64")); err != nil {
+		t.Errorf("expected linux/amd64 binary to be built: %v", err)
+	}
+}
@@ -1,20 +1,28 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml
-// SourceChecksum: sha256:588f9d0e5ca9a8c7e7d81021c6dcb966dafe486bb650651bbb6a7975705f731a
+// SourceChecksum: sha256:29db91ed1d3fcb716ec0a91764204ef6e4560ac9c564e61990fc59b6a1e4e561
 
 package main
 
 import (
+	"embed"
+
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for documentation tools.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "go-build",
 	LocalDir:     "cmd/go-build/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 // RegisterDocsMCPTools registers the docs-list and docs-get MCP tools.
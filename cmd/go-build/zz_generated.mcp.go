@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:588f9d0e5ca9a8c7e7d81021c6dcb966dafe486bb650651bbb6a7975705f731a
+// SourceChecksum: sha256:29db91ed1d3fcb716ec0a91764204ef6e4560ac9c564e61990fc59b6a1e4e561
 
 package main
 
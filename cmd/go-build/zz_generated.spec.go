@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:588f9d0e5ca9a8c7e7d81021c6dcb966dafe486bb650651bbb6a7975705f731a
+// SourceChecksum: sha256:29db91ed1d3fcb716ec0a91764204ef6e4560ac9c564e61990fc59b6a1e4e561
 
 package main
 
@@ -8,13 +8,56 @@ import (
 	"fmt"
 )
 
+// Target represents the Target configuration.
+// A GOOS/GOARCH pair to cross-compile for
+type Target struct {
+	// Target architecture (e.g. amd64, arm64)
+	Goarch string `json:"goarch"`
+	// Target operating system (e.g. linux, darwin, windows)
+	Goos string `json:"goos"`
+}
+
 // Spec represents the Spec configuration.
 // Configuration for go-build engine
 type Spec struct {
 	// Additional arguments to pass to go build (optional)
 	Args []string `json:"args,omitempty"`
+	// Enable (true) or disable (false) cgo by setting CGO_ENABLED (optional, default CGO_ENABLED=0 unless set in env)
+	Cgo *bool `json:"cgo,omitempty"`
 	// Environment variables to set for the build (optional)
 	Env map[string]string `json:"env,omitempty"`
+	// Linker flags passed to go build -ldflags (optional). {{.GitVersion}} expands to the git commit SHA. Merged with GO_BUILD_LDFLAGS, whose -X flags take precedence.
+	Ldflags string `json:"ldflags,omitempty"`
+	// Build tags passed to go build -tags (optional)
+	Tags []string `json:"tags,omitempty"`
+	// Cross-compilation matrix (optional). Each target is built concurrently and produces its own artifact named <name>-<goos>-<goarch>.
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// TargetFromMap creates a Target from a map[string]interface{}.
+func TargetFromMap(m map[string]interface{}) (*Target, error) {
+	if m == nil {
+		return &Target{}, nil
+	}
+
+	s := &Target{}
+	// Parse goarch
+	if v, ok := m["goarch"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Goarch = val
+		} else {
+			return nil, fmt.Errorf("field goarch: expected string, got %T", v)
+		}
+	}
+	// Parse goos
+	if v, ok := m["goos"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Goos = val
+		} else {
+			return nil, fmt.Errorf("field goos: expected string, got %T", v)
+		}
+	}
+	return s, nil
 }
 
 // SpecFromMap creates a Spec from a map[string]interface{}.
@@ -41,6 +84,14 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field args: expected []string, got %T", v)
 		}
 	}
+	// Parse cgo
+	if v, ok := m["cgo"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.Cgo = &val
+		} else {
+			return nil, fmt.Errorf("field cgo: expected bool, got %T", v)
+		}
+	}
 	// Parse env
 	if v, ok := m["env"]; ok && v != nil {
 		if mapVal, ok := v.(map[string]interface{}); ok {
@@ -58,9 +109,71 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field env: expected map[string]string, got %T", v)
 		}
 	}
+	// Parse ldflags
+	if v, ok := m["ldflags"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Ldflags = val
+		} else {
+			return nil, fmt.Errorf("field ldflags: expected string, got %T", v)
+		}
+	}
+	// Parse tags
+	if v, ok := m["tags"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Tags = make([]string, 0, len(arr))
+			for i, item := range arr {
+				if str, ok := item.(string); ok {
+					s.Tags = append(s.Tags, str)
+				} else {
+					return nil, fmt.Errorf("field tags[%d]: expected string, got %T", i, item)
+				}
+			}
+		} else if arr, ok := v.([]string); ok {
+			s.Tags = arr
+		} else {
+			return nil, fmt.Errorf("field tags: expected []string, got %T", v)
+		}
+	}
+	// Parse targets
+	if v, ok := m["targets"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Targets = make([]Target, 0, len(arr))
+			for i, item := range arr {
+				if obj, ok := item.(map[string]interface{}); ok {
+					ref, err := TargetFromMap(obj)
+					if err != nil {
+						return nil, fmt.Errorf("field targets[%d]: %w", i, err)
+					}
+					if ref != nil {
+						s.Targets = append(s.Targets, *ref)
+					}
+				} else {
+					return nil, fmt.Errorf("field targets[%d]: expected object, got %T", i, item)
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("field targets: expected []object, got %T", v)
+		}
+	}
 	return s, nil
 }
 
+// ToMap converts a Target to a map[string]interface{}.
+func (s *Target) ToMap() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{})
+	if s.Goarch != "" {
+		m["goarch"] = s.Goarch
+	}
+	if s.Goos != "" {
+		m["goos"] = s.Goos
+	}
+	return m
+}
+
 // ToMap converts a Spec to a map[string]interface{}.
 func (s *Spec) ToMap() map[string]interface{} {
 	if s == nil {
@@ -71,9 +184,25 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if len(s.Args) > 0 {
 		m["args"] = s.Args
 	}
+	if s.Cgo != nil {
+		m["cgo"] = *s.Cgo
+	}
 	if len(s.Env) > 0 {
 		m["env"] = s.Env
 	}
+	if s.Ldflags != "" {
+		m["ldflags"] = s.Ldflags
+	}
+	if len(s.Tags) > 0 {
+		m["tags"] = s.Tags
+	}
+	if len(s.Targets) > 0 {
+		arr := make([]interface{}, 0, len(s.Targets))
+		for _, item := range s.Targets {
+			arr = append(arr, item.ToMap())
+		}
+		m["targets"] = arr
+	}
 	return m
 }
 
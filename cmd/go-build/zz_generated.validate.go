@@ -1,13 +1,52 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:588f9d0e5ca9a8c7e7d81021c6dcb966dafe486bb650651bbb6a7975705f731a
+// SourceChecksum: sha256:29db91ed1d3fcb716ec0a91764204ef6e4560ac9c564e61990fc59b6a1e4e561
 
 package main
 
 import (
+	"fmt"
+
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
+// ValidateTarget validates a Target and returns validation results.
+// It checks required fields and validates enum values.
+func ValidateTarget(s *Target) *mcptypes.ConfigValidateOutput {
+	if s == nil {
+		return &mcptypes.ConfigValidateOutput{
+			Valid: true,
+		}
+	}
+
+	var errors []mcptypes.ValidationError
+	// Validate required field: goarch
+	if s.Goarch == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.goarch",
+			Message: "required field is missing",
+		})
+	}
+	// Validate required field: goos
+	if s.Goos == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.goos",
+			Message: "required field is missing",
+		})
+	}
+
+	if len(errors) > 0 {
+		return &mcptypes.ConfigValidateOutput{
+			Valid:  false,
+			Errors: errors,
+		}
+	}
+
+	return &mcptypes.ConfigValidateOutput{
+		Valid: true,
+	}
+}
+
 // ValidateSpec validates a Spec and returns validation results.
 // It checks required fields and validates enum values.
 func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
@@ -18,6 +57,18 @@ func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
 	}
 
 	var errors []mcptypes.ValidationError
+	// Validate array of references: targets
+	for i, item := range s.Targets {
+		nestedResult := ValidateTarget(&item)
+		if !nestedResult.Valid {
+			for _, e := range nestedResult.Errors {
+				errors = append(errors, mcptypes.ValidationError{
+					Field:   fmt.Sprintf("spec.targets[%d].%s", i, e.Field),
+					Message: e.Message,
+				})
+			}
+		}
+	}
 
 	if len(errors) > 0 {
 		return &mcptypes.ConfigValidateOutput{
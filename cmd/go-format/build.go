@@ -15,11 +15,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
@@ -28,48 +32,125 @@ import (
 
 // Build implements the BuildFunc for formatting Go code
 func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.Artifact, error) {
-	// Use spec.Path if set, otherwise fall back to input.Path or input.Src
-	path := spec.Path
-	if path == "" {
-		path = input.Path
-	}
-	if path == "" && input.Src != "" {
-		path = input.Src
-	}
-	if path == "" {
-		path = "."
-	}
-
+	path := formatPath(input, spec)
 	log.Printf("Formatting Go code at: %s", path)
 
-	if err := formatCode(path); err != nil {
+	importsOrganized, err := formatCode(path, spec.LocalPrefix)
+	if err != nil {
 		return nil, fmt.Errorf("formatting failed: %w", err)
 	}
 
+	// The artifact type records whether goimports rewrote any file
+	artifactType := artifactTypeFormatted
+	if importsOrganized {
+		artifactType = artifactTypeFormattedImports
+	}
+
 	// Return artifact using CreateArtifact (formatted code has no version)
 	return engineframework.CreateArtifact(
 		"formatted-code",
-		"formatted",
+		artifactType,
 		path,
 	), nil
 }
 
-func formatCode(path string) error {
+// formatPath returns the path to format: spec.Path resolved against input.RootDir if set,
+// otherwise input.Path or input.Src (already resolved by the builder framework),
+// otherwise input.RootDir itself.
+func formatPath(input mcptypes.BuildInput, spec *Spec) string {
+	if spec.Path != "" {
+		if input.RootDir != "" && !filepath.IsAbs(spec.Path) {
+			return filepath.Join(input.RootDir, spec.Path)
+		}
+		return spec.Path
+	}
+	if input.Path != "" {
+		return input.Path
+	}
+	if input.Src != "" {
+		return input.Src
+	}
+	if input.RootDir != "" {
+		return input.RootDir
+	}
+	return "."
+}
+
+const (
+	// artifactTypeFormatted is the artifact type when only gofumpt ran.
+	artifactTypeFormatted = "formatted"
+	// artifactTypeFormattedImports is the artifact type when goimports rewrote at least one file.
+	artifactTypeFormattedImports = "formatted-imports"
+
+	defaultGofumptVersion   = "v0.6.0"
+	defaultGoimportsVersion = "v0.29.0"
+)
+
+// formatStep is a single go tool invocation performed by formatCode.
+type formatStep struct {
+	// tool is the tool name used in log and error messages.
+	tool string
+	// args are the arguments passed to "go".
+	args []string
+	// listsChanges is set when the tool prints the files it rewrote on stdout (-l).
+	listsChanges bool
+}
+
+// formatSteps returns the tool invocations needed to format path.
+// gofumpt always runs; goimports runs afterwards only when localPrefix is set.
+func formatSteps(gofumptVersion, goimportsVersion, localPrefix, path string) []formatStep {
+	steps := []formatStep{{
+		tool: "gofumpt",
+		args: []string{"run", fmt.Sprintf("mvdan.cc/gofumpt@%s", gofumptVersion), "-w", path},
+	}}
+
+	if localPrefix != "" {
+		steps = append(steps, formatStep{
+			tool: "goimports",
+			args: []string{
+				"run", fmt.Sprintf("golang.org/x/tools/cmd/goimports@%s", goimportsVersion),
+				"-local", localPrefix,
+				"-l", "-w", path,
+			},
+			listsChanges: true,
+		})
+	}
+
+	return steps
+}
+
+// formatCode formats path and reports whether goimports reorganized imports,
+// i.e. whether it rewrote any file left by gofumpt.
+func formatCode(path, localPrefix string) (bool, error) {
 	gofumptVersion := os.Getenv("GOFUMPT_VERSION")
 	if gofumptVersion == "" {
-		gofumptVersion = "v0.6.0"
+		gofumptVersion = defaultGofumptVersion
 	}
 
-	gofumptPkg := fmt.Sprintf("mvdan.cc/gofumpt@%s", gofumptVersion)
+	goimportsVersion := os.Getenv("GOIMPORTS_VERSION")
+	if goimportsVersion == "" {
+		goimportsVersion = defaultGoimportsVersion
+	}
 
-	cmd := exec.Command("go", "run", gofumptPkg, "-w", path)
-	cmd.Stdout = os.Stderr // Send to stderr to not interfere with MCP JSON-RPC on stdout
-	cmd.Stderr = os.Stderr
+	importsOrganized := false
+	steps := formatSteps(gofumptVersion, goimportsVersion, localPrefix, path)
+	for _, step := range steps {
+		var changed bytes.Buffer
+		cmd := exec.Command("go", step.args...)
+		cmd.Stdout = os.Stderr // Send to stderr to not interfere with MCP JSON-RPC on stdout
+		if step.listsChanges {
+			cmd.Stdout = io.MultiWriter(&changed, os.Stderr)
+		}
+		cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("gofumpt failed: %w", err)
+		if err := cmd.Run(); err != nil {
+			return false, fmt.Errorf("%s failed: %w", step.tool, err)
+		}
+		if strings.TrimSpace(changed.String()) != "" {
+			importsOrganized = true
+		}
 	}
 
 	fmt.Fprintf(os.Stderr, "Formatted Go code at %s\n", path)
-	return nil
+	return importsOrganized, nil
 }
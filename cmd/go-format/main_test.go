@@ -17,6 +17,9 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
@@ -55,6 +58,7 @@ func TestHandleBuildUsesSrcWhenPathEmpty(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    mcptypes.BuildInput
+		spec     *Spec
 		expected string
 	}{
 		{
@@ -89,20 +93,40 @@ func TestHandleBuildUsesSrcWhenPathEmpty(t *testing.T) {
 			},
 			expected: ".",
 		},
+		{
+			name: "Both empty - defaults to root directory",
+			input: mcptypes.BuildInput{
+				DirectoryParams: mcptypes.DirectoryParams{RootDir: "/repo"},
+			},
+			expected: "/repo",
+		},
+		{
+			name: "Relative spec path resolves against root directory",
+			input: mcptypes.BuildInput{
+				Src:             "/from/src",
+				DirectoryParams: mcptypes.DirectoryParams{RootDir: "/repo"},
+			},
+			spec:     &Spec{Path: "./pkg"},
+			expected: "/repo/pkg",
+		},
+		{
+			name: "Absolute spec path is kept",
+			input: mcptypes.BuildInput{
+				DirectoryParams: mcptypes.DirectoryParams{RootDir: "/repo"},
+			},
+			spec:     &Spec{Path: "/elsewhere"},
+			expected: "/elsewhere",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Simulate the logic in handleBuild
-			path := tt.input.Path
-			if path == "" && tt.input.Src != "" {
-				path = tt.input.Src
-			}
-			if path == "" {
-				path = "."
+			spec := tt.spec
+			if spec == nil {
+				spec = &Spec{}
 			}
 
-			if path != tt.expected {
+			if path := formatPath(tt.input, spec); path != tt.expected {
 				t.Errorf("Expected path to be %s, got %s", tt.expected, path)
 			}
 		})
@@ -174,3 +198,90 @@ func TestBuildSpecCompatibility(t *testing.T) {
 }
 
 // TestVersionInfoInitialized removed - version info now handled by cli.Bootstrap
+
+// TestFormatSteps tests the command construction for gofumpt and goimports
+func TestFormatSteps(t *testing.T) {
+	tests := []struct {
+		name        string
+		localPrefix string
+		expected    []formatStep
+	}{
+		{
+			name:        "Empty prefix - gofumpt only",
+			localPrefix: "",
+			expected: []formatStep{
+				{tool: "gofumpt", args: []string{"run", "mvdan.cc/gofumpt@v0.6.0", "-w", "./pkg"}},
+			},
+		},
+		{
+			name:        "Prefix set - gofumpt then goimports",
+			localPrefix: "github.com/example/project",
+			expected: []formatStep{
+				{tool: "gofumpt", args: []string{"run", "mvdan.cc/gofumpt@v0.6.0", "-w", "./pkg"}},
+				{tool: "goimports", args: []string{
+					"run", "golang.org/x/tools/cmd/goimports@v0.29.0",
+					"-local", "github.com/example/project",
+					"-l", "-w", "./pkg",
+				}, listsChanges: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := formatSteps("v0.6.0", "v0.29.0", tt.localPrefix, "./pkg")
+
+			if len(steps) != len(tt.expected) {
+				t.Fatalf("Expected %d steps, got %d", len(tt.expected), len(steps))
+			}
+
+			for i, step := range steps {
+				if step.tool != tt.expected[i].tool {
+					t.Errorf("Step %d: expected tool %s, got %s", i, tt.expected[i].tool, step.tool)
+				}
+				if strings.Join(step.args, " ") != strings.Join(tt.expected[i].args, " ") {
+					t.Errorf("Step %d: expected args %v, got %v", i, tt.expected[i].args, step.args)
+				}
+				if step.listsChanges != tt.expected[i].listsChanges {
+					t.Errorf("Step %d: expected listsChanges %v, got %v", i, tt.expected[i].listsChanges, step.listsChanges)
+				}
+			}
+		})
+	}
+}
+
+// TestFormatCode_ReportsImportChanges tests that formatCode only reports reorganized
+// imports when goimports lists rewritten files
+func TestFormatCode_ReportsImportChanges(t *testing.T) {
+	binDir := t.TempDir()
+	fakeGo := "#!/bin/sh\ncase \"$*\" in *goimports*) printf '%s' \"$FAKE_GOIMPORTS_CHANGED\" ;; esac\n"
+	if err := os.WriteFile(filepath.Join(binDir, "go"), []byte(fakeGo), 0o755); err != nil {
+		t.Fatalf("failed to write fake go: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	tests := []struct {
+		name        string
+		localPrefix string
+		changed     string
+		expected    bool
+	}{
+		{name: "goimports rewrote a file", localPrefix: "github.com/example/project", changed: "pkg/a.go\n", expected: true},
+		{name: "goimports changed nothing", localPrefix: "github.com/example/project", changed: "", expected: false},
+		{name: "goimports skipped", localPrefix: "", changed: "pkg/a.go\n", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FAKE_GOIMPORTS_CHANGED", tt.changed)
+
+			importsOrganized, err := formatCode("./pkg", tt.localPrefix)
+			if err != nil {
+				t.Fatalf("formatCode() error = %v", err)
+			}
+			if importsOrganized != tt.expected {
+				t.Errorf("formatCode() = %v, want %v", importsOrganized, tt.expected)
+			}
+		})
+	}
+}
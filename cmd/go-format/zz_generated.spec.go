@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:79c843541eeef5d9f3ca8212219f777dce4d6ae6600d46afde9d1e20d77c0de1
+// SourceChecksum: sha256:bf863f5fdabaa5d2dac930de733825b36231d3421b5a621bb2ab77a7c085856d
 
 package main
 
@@ -11,6 +11,8 @@ import (
 // Spec represents the Spec configuration.
 // Configuration for go-format engine
 type Spec struct {
+	// Comma-separated import prefixes passed to goimports -local (optional, goimports is skipped when empty)
+	LocalPrefix string `json:"localPrefix,omitempty"`
 	// Path to format (optional, defaults to src or current directory)
 	Path string `json:"path,omitempty"`
 }
@@ -22,6 +24,14 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	}
 
 	s := &Spec{}
+	// Parse localPrefix
+	if v, ok := m["localPrefix"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.LocalPrefix = val
+		} else {
+			return nil, fmt.Errorf("field localPrefix: expected string, got %T", v)
+		}
+	}
 	// Parse path
 	if v, ok := m["path"]; ok && v != nil {
 		if val, ok := v.(string); ok {
@@ -40,6 +50,9 @@ func (s *Spec) ToMap() map[string]interface{} {
 	}
 
 	m := make(map[string]interface{})
+	if s.LocalPrefix != "" {
+		m["localPrefix"] = s.LocalPrefix
+	}
 	if s.Path != "" {
 		m["path"] = s.Path
 	}
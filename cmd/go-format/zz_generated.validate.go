@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:79c843541eeef5d9f3ca8212219f777dce4d6ae6600d46afde9d1e20d77c0de1
+// SourceChecksum: sha256:bf863f5fdabaa5d2dac930de733825b36231d3421b5a621bb2ab77a7c085856d
 
 package main
 
@@ -34,10 +34,11 @@ import (
 //  2. --output-dir=<dest>
 //  3. --output-stem=<stem>
 //  4. --tags=<t1>,<t2>
-//  5. --type=<t1> --type=<t2> (for each type)
-//  6. <ident>
-//  7. <src>
-//  8. -- <cflags...> (if any)
+//  5. --target=<t1>,<t2> (if any)
+//  6. --type=<t1> --type=<t2> (for each type)
+//  7. <ident>
+//  8. <src>
+//  9. -- <cflags...> (if any)
 func buildBpf2goArgs(src, dest string, spec *Spec) []string {
 	args := make([]string, 0)
 
@@ -73,18 +74,23 @@ func buildBpf2goArgs(src, dest string, spec *Spec) []string {
 		args = append(args, "--tags", strings.Join(tags, ","))
 	}
 
-	// 5. Types (one --type flag per type)
+	// 5. Target architectures (comma-joined)
+	if len(spec.Targets) > 0 {
+		args = append(args, "--target", strings.Join(spec.Targets, ","))
+	}
+
+	// 6. Types (one --type flag per type)
 	for _, t := range spec.Types {
 		args = append(args, "--type", t)
 	}
 
-	// 6. Ident
+	// 7. Ident
 	args = append(args, spec.Ident)
 
-	// 7. Source file
+	// 8. Source file
 	args = append(args, src)
 
-	// 8. C flags after "--" separator
+	// 9. C flags after "--" separator
 	if len(spec.Cflags) > 0 {
 		args = append(args, "--")
 		args = append(args, spec.Cflags...)
@@ -98,33 +104,78 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 	// 1. Log start
 	log.Printf("Generating BPF code for: %s", input.Name)
 
-	// 2. Validate required inputs
-	if input.Src == "" {
-		return nil, fmt.Errorf("src is required")
+	// 2. Resolve the sources to generate and the output directory
+	sources, err := bpfSources(input.Src, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := spec.OutputDir
+	if dest == "" {
+		dest = input.Dest
 	}
-	if input.Dest == "" {
+	if dest == "" {
 		return nil, fmt.Errorf("dest is required")
 	}
 
-	// 3. Validate source file exists and is a file (not directory)
-	srcInfo, err := os.Stat(input.Src)
-	if err != nil {
-		return nil, fmt.Errorf("source file not found: %s", input.Src)
+	// 3. Validate source files exist and are files (not directories)
+	for _, source := range sources {
+		srcInfo, err := os.Stat(source.src)
+		if err != nil {
+			return nil, fmt.Errorf("source file not found: %s", source.src)
+		}
+		if srcInfo.IsDir() {
+			return nil, fmt.Errorf("src must be a file, not directory: %s", source.src)
+		}
+		log.Printf("Source file: %s", source.src)
 	}
-	if srcInfo.IsDir() {
-		return nil, fmt.Errorf("src must be a file, not directory")
+
+	// 4. Fail early with an actionable error when the C compiler is missing
+	if err := checkCompiler(spec.Cc); err != nil {
+		return nil, err
 	}
-	log.Printf("Source file: %s", input.Src)
 
-	// 4. Create destination directory
-	if err := os.MkdirAll(input.Dest, 0o755); err != nil {
+	// 5. Create destination directory
+	if err := os.MkdirAll(dest, 0o755); err != nil {
 		return nil, fmt.Errorf("failed to create dest directory: %w", err)
 	}
 
-	// 5. Build bpf2go command arguments using typed spec
-	bpf2goArgs := buildBpf2goArgs(input.Src, input.Dest, spec)
+	// 6. Execute bpf2go via "go run" (following go-gen-mocks pattern) for each source
+	for _, source := range sources {
+		if err := runBpf2go(ctx, source.src, dest, source.spec); err != nil {
+			return nil, err
+		}
+	}
+
+	// 7. Build dependencies on the sources and the local headers they include
+	var deps []forge.ArtifactDependency
+	for _, source := range sources {
+		sourceDeps, err := sourceDependencies(source.src, includeDirs(spec.Cflags))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build dependencies: %w", err)
+		}
+		deps = appendUniqueDependencies(deps, sourceDeps...)
+	}
+
+	// 8. Create and return artifact
+	artifact := &forge.Artifact{
+		Name:                     input.Name,
+		Type:                     "bpf",
+		Location:                 dest,
+		Timestamp:                time.Now().UTC().Format(time.RFC3339),
+		Dependencies:             deps,
+		DependencyDetectorEngine: "go://go-gen-bpf",
+	}
+
+	log.Printf("Successfully generated BPF code for %s", input.Name)
+
+	return artifact, nil
+}
+
+// runBpf2go generates Go code for a single BPF source file.
+func runBpf2go(ctx context.Context, src, dest string, spec *Spec) error {
+	bpf2goArgs := buildBpf2goArgs(src, dest, spec)
 
-	// 6. Execute bpf2go via "go run" (following go-gen-mocks pattern)
 	// Compute bpf2goVersion with default
 	bpf2goVersion := spec.Bpf2goVersion
 	if bpf2goVersion == "" {
@@ -146,28 +197,30 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 	}
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("bpf2go failed: %w", err)
+		return fmt.Errorf("bpf2go failed for %s: %w", src, err)
 	}
 
-	// 7. Build dependencies
-	deps, err := buildDependencies(input.Src, srcInfo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build dependencies: %w", err)
-	}
+	return nil
+}
 
-	// 8. Create and return artifact
-	artifact := &forge.Artifact{
-		Name:                     input.Name,
-		Type:                     "bpf",
-		Location:                 input.Dest,
-		Timestamp:                time.Now().UTC().Format(time.RFC3339),
-		Dependencies:             deps,
-		DependencyDetectorEngine: "go://go-gen-bpf",
+// checkCompiler verifies that the C compiler used by bpf2go is available.
+// The compiler is cc if set, then $BPF2GO_CC, then clang (the bpf2go default).
+func checkCompiler(cc string) error {
+	if cc == "" {
+		cc = os.Getenv("BPF2GO_CC")
+	}
+	if cc == "" {
+		cc = "clang"
 	}
 
-	log.Printf("Successfully generated BPF code for %s", input.Name)
+	if _, err := exec.LookPath(cc); err != nil {
+		return fmt.Errorf(
+			"C compiler %q not found: bpf2go needs clang to compile BPF programs; "+
+				"install clang (e.g. \"apt install clang\") or set spec.cc to its path: %w",
+			cc, err)
+	}
 
-	return artifact, nil
+	return nil
 }
 
 // buildDependencies creates ArtifactDependency entries for the source file.
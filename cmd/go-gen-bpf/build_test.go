@@ -249,6 +249,27 @@ func TestBuildBpf2goArgs(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "targets are comma-joined in --target flag",
+			src:  "./bpf/prog.c",
+			dest: "./bpf",
+			spec: &Spec{
+				Ident:      "prog",
+				GoPackage:  "bpf",
+				OutputStem: "zz_generated",
+				Tags:       []string{"linux"},
+				Targets:    []string{"amd64", "arm64"},
+			},
+			wantArgs: []string{
+				"--go-package", "bpf",
+				"--output-dir", "./bpf",
+				"--output-stem", "zz_generated",
+				"--tags", "linux",
+				"--target", "amd64,arm64",
+				"prog",
+				"./bpf/prog.c",
+			},
+		},
 		{
 			name: "cflags come after -- separator",
 			src:  "./bpf/app.c",
@@ -0,0 +1,201 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// bpfSource is a BPF C source file and the spec used to run bpf2go on it.
+type bpfSource struct {
+	src  string
+	spec *Spec
+}
+
+// bpfSources returns the sources to generate: src with spec.ident (if src is set), followed by spec.sources.
+// Relative paths in spec.sources are resolved against spec.sourceDir.
+func bpfSources(src string, spec *Spec) ([]bpfSource, error) {
+	if src == "" && len(spec.Sources) == 0 {
+		return nil, fmt.Errorf("src is required")
+	}
+
+	sources := make([]bpfSource, 0, len(spec.Sources)+1)
+	idents := make(map[string]bool, len(spec.Sources)+1)
+
+	if src != "" {
+		if spec.Ident == "" {
+			return nil, fmt.Errorf("spec.ident is required")
+		}
+		sources = append(sources, bpfSource{src: src, spec: spec})
+		idents[spec.Ident] = true
+	}
+
+	for i, source := range spec.Sources {
+		if source.Src == "" || source.Ident == "" {
+			return nil, fmt.Errorf("sources[%d]: src and ident are required", i)
+		}
+		if idents[source.Ident] {
+			return nil, fmt.Errorf("sources[%d]: duplicate ident %q", i, source.Ident)
+		}
+		idents[source.Ident] = true
+
+		path := source.Src
+		if spec.SourceDir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(spec.SourceDir, path)
+		}
+
+		sourceSpec := *spec
+		sourceSpec.Ident = source.Ident
+		sourceSpec.OutputStem = source.OutputStem
+		if sourceSpec.OutputStem == "" {
+			sourceSpec.OutputStem = "zz_generated_" + strings.ToLower(source.Ident)
+		}
+
+		sources = append(sources, bpfSource{src: path, spec: &sourceSpec})
+	}
+
+	return sources, nil
+}
+
+// includeDirs returns the include directories passed to the compiler with -I in cflags.
+func includeDirs(cflags []string) []string {
+	var dirs []string
+	for i := 0; i < len(cflags); i++ {
+		switch {
+		case cflags[i] == "-I" && i+1 < len(cflags):
+			i++
+			dirs = append(dirs, cflags[i])
+		case strings.HasPrefix(cflags[i], "-I") && len(cflags[i]) > 2:
+			dirs = append(dirs, strings.TrimPrefix(cflags[i], "-I"))
+		}
+	}
+	return dirs
+}
+
+// localIncludeRe matches quoted #include directives. System includes (<...>) are not tracked.
+var localIncludeRe = regexp.MustCompile(`^\s*#\s*include\s*"([^"]+)"`)
+
+// sourceDependencies returns the dependencies of a BPF source file: the file itself followed by
+// every local header it includes, directly or transitively. Headers are looked up next to the
+// including file first, then in includeDirs. Headers that cannot be found are skipped.
+func sourceDependencies(src string, includeDirs []string) ([]forge.ArtifactDependency, error) {
+	var deps []forge.ArtifactDependency
+	visited := make(map[string]bool)
+	queue := []string{src}
+
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", path, err)
+		}
+		if visited[absPath] {
+			continue
+		}
+		visited[absPath] = true
+
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+
+		fileDeps, err := buildDependencies(absPath, info)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, fileDeps...)
+
+		includes, err := parseLocalIncludes(absPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, include := range includes {
+			if header, ok := resolveInclude(include, filepath.Dir(absPath), includeDirs); ok {
+				queue = append(queue, header)
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// parseLocalIncludes returns the paths of the quoted #include directives in a C file.
+func parseLocalIncludes(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var includes []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if m := localIncludeRe.FindStringSubmatch(scanner.Text()); m != nil {
+			includes = append(includes, m[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return includes, nil
+}
+
+// resolveInclude looks up an included header in dir, then in includeDirs.
+func resolveInclude(include, dir string, includeDirs []string) (string, bool) {
+	if filepath.IsAbs(include) {
+		return include, isRegularFile(include)
+	}
+
+	for _, d := range append([]string{dir}, includeDirs...) {
+		candidate := filepath.Join(d, include)
+		if isRegularFile(candidate) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// appendUniqueDependencies appends the dependencies whose file path is not already in deps.
+func appendUniqueDependencies(deps []forge.ArtifactDependency, more ...forge.ArtifactDependency) []forge.ArtifactDependency {
+	for _, dep := range more {
+		duplicate := false
+		for _, existing := range deps {
+			if existing.FilePath == dep.FilePath {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
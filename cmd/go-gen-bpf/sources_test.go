@@ -0,0 +1,149 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBpfSources(t *testing.T) {
+	spec := &Spec{
+		Ident:      "prog",
+		OutputStem: "zz_generated",
+		SourceDir:  "./bpf",
+		Sources: []Source{
+			{Src: "xdp.c", Ident: "xdpProg"},
+			{Src: "/abs/tc.c", Ident: "tc", OutputStem: "tc_gen"},
+		},
+	}
+
+	sources, err := bpfSources("./bpf/prog.c", spec)
+	if err != nil {
+		t.Fatalf("bpfSources() error = %v", err)
+	}
+
+	want := []struct{ src, ident, stem string }{
+		{"./bpf/prog.c", "prog", "zz_generated"},
+		{filepath.Join("bpf", "xdp.c"), "xdpProg", "zz_generated_xdpprog"},
+		{"/abs/tc.c", "tc", "tc_gen"},
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("bpfSources() returned %d sources, want %d", len(sources), len(want))
+	}
+	for i, w := range want {
+		got := sources[i]
+		if got.src != w.src || got.spec.Ident != w.ident || got.spec.OutputStem != w.stem {
+			t.Errorf("sources[%d] = {%s %s %s}, want %+v", i, got.src, got.spec.Ident, got.spec.OutputStem, w)
+		}
+	}
+	if spec.Ident != "prog" || spec.OutputStem != "zz_generated" {
+		t.Errorf("bpfSources() modified spec: %+v", spec)
+	}
+}
+
+func TestBpfSources_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		spec    *Spec
+		wantErr string
+	}{
+		{"no src or sources", "", &Spec{Ident: "prog"}, "src is required"},
+		{"src without ident", "prog.c", &Spec{}, "spec.ident is required"},
+		{"source without ident", "", &Spec{Sources: []Source{{Src: "a.c"}}}, "sources[0]: src and ident are required"},
+		{
+			"duplicate ident",
+			"prog.c",
+			&Spec{Ident: "prog", Sources: []Source{{Src: "a.c", Ident: "prog"}}},
+			`sources[0]: duplicate ident "prog"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bpfSources(tt.src, tt.spec)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("bpfSources() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIncludeDirs(t *testing.T) {
+	got := includeDirs([]string{"-O2", "-I./include", "-I", "/usr/include/bpf", "-g", "-I"})
+	want := []string{"./include", "/usr/include/bpf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("includeDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestSourceDependencies(t *testing.T) {
+	deps, err := sourceDependencies("testdata/bpf/prog.c", []string{"testdata/bpf/include"})
+	if err != nil {
+		t.Fatalf("sourceDependencies() error = %v", err)
+	}
+
+	var got []string
+	for _, dep := range deps {
+		if !filepath.IsAbs(dep.FilePath) {
+			t.Errorf("dependency path %q is not absolute", dep.FilePath)
+		}
+		if dep.Timestamp == "" {
+			t.Errorf("dependency %q has no timestamp", dep.FilePath)
+		}
+		rel, err := filepath.Rel(mustAbs(t, "testdata/bpf"), dep.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+
+	// System and unresolvable headers are skipped, cyclic includes are listed once
+	want := []string{"prog.c", "common.h", "include/maps.h", "types.h"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sourceDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckCompiler(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("BPF2GO_CC", "")
+
+	err := checkCompiler("")
+	if err == nil {
+		t.Fatal("checkCompiler() expected error when clang is missing")
+	}
+	if !strings.Contains(err.Error(), `"clang" not found`) || !strings.Contains(err.Error(), "spec.cc") {
+		t.Errorf("checkCompiler() error = %v, want actionable clang error", err)
+	}
+
+	if err := checkCompiler("/bin/sh"); err != nil {
+		t.Errorf("checkCompiler(/bin/sh) error = %v", err)
+	}
+}
+
+func mustAbs(t *testing.T, path string) string {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abs
+}
@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: GPL-2.0
+
+#include <linux/bpf.h>
+#include "vmlinux.h"
+#include "common.h"
+#include "maps.h"
+
+char _license[] __attribute__((section("license"))) = "GPL";
@@ -0,0 +1,5 @@
+#pragma once
+
+#include "common.h"
+
+typedef unsigned int u32;
@@ -1,15 +1,18 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml + spec.openapi.yaml
-// SourceChecksum: sha256:ff055ed90a004dbad73755e405b83056a8932968c7707d598a5fa41bc1a69830
+// SourceChecksum: sha256:50a9fadd9e7a80ea5aa1ae40cc1cbe0457c9351ce4c3811bb9ead341114a73bb
 
 package main
 
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
+	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
@@ -32,29 +35,55 @@ func main() {
 		Version:        Version,
 		CommitSHA:      CommitSHA,
 		BuildTimestamp: BuildTimestamp,
-		RunCLI:         nil, // Generated engines are MCP-only
+		RunCLI:         runBuilderCLI,
+		FailureHandler: printCLIFailure,
 		RunMCP:         runMCPServer,
 		DocsConfig:     docsConfig,
+		SetupMCP:       setupMCPServer,
 	})
 }
 
 // runMCPServer creates and runs the MCP server.
 func runMCPServer() error {
+	server, err := setupMCPServer()
+	if err != nil {
+		return err
+	}
+
+	if err := server.Run(enginecli.Context()); err != nil {
+		return fmt.Errorf("running MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// setupMCPServer creates the MCP server and registers all its tools.
+func setupMCPServer() (*mcpserver.Server, error) {
 	server, err := SetupMCPServer(Name, Version, Build)
 	if err != nil {
-		return fmt.Errorf("setting up MCP server: %w", err)
+		return nil, fmt.Errorf("setting up MCP server: %w", err)
 	}
 
 	// Register docs MCP tools (docs-list, docs-get)
 	if err := RegisterDocsMCPTools(server); err != nil {
-		return fmt.Errorf("registering docs MCP tools: %w", err)
+		return nil, fmt.Errorf("registering docs MCP tools: %w", err)
 	}
 
-	if err := server.Run(context.Background()); err != nil {
-		return fmt.Errorf("running MCP server: %w", err)
-	}
+	return server, nil
+}
 
-	return nil
+// runBuilderCLI runs the engine in CLI mode: "go-gen-bpf build --input build-input.json".
+func runBuilderCLI() error {
+	return engineframework.RunBuilderCLI(context.Background(), engineframework.BuilderConfig{
+		Name:      Name,
+		Version:   Version,
+		BuildFunc: wrapBuildFunc(Build),
+	}, os.Args[1:], os.Stdout)
+}
+
+// printCLIFailure prints CLI mode errors to stderr.
+func printCLIFailure(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s: error: %s\n", Name, err.Error())
 }
 
 // Build is the build function that must be implemented by the engine author.
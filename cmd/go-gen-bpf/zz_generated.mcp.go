@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:ff055ed90a004dbad73755e405b83056a8932968c7707d598a5fa41bc1a69830
+// SourceChecksum: sha256:50a9fadd9e7a80ea5aa1ae40cc1cbe0457c9351ce4c3811bb9ead341114a73bb
 
 package main
 
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:ff055ed90a004dbad73755e405b83056a8932968c7707d598a5fa41bc1a69830
+// SourceChecksum: sha256:50a9fadd9e7a80ea5aa1ae40cc1cbe0457c9351ce4c3811bb9ead341114a73bb
 
 package main
 
@@ -8,6 +8,17 @@ import (
 	"fmt"
 )
 
+// Source represents the Source configuration.
+// A BPF C source file to generate Go code for
+type Source struct {
+	// Go identifier for generated types
+	Ident string `json:"ident"`
+	// Filename prefix (default "zz_generated_<ident>")
+	OutputStem string `json:"outputStem,omitempty"`
+	// Path to the BPF C source file
+	Src string `json:"src"`
+}
+
 // Spec represents the Spec configuration.
 // Configuration for go-gen-bpf engine using bpf2go
 type Spec struct {
@@ -21,18 +32,56 @@ type Spec struct {
 	GoPackage string `json:"goPackage,omitempty"`
 	// Go identifier for generated types (required)
 	Ident string `json:"ident"`
-	// Directory for generated Go code (optional)
+	// Directory for generated Go code, overriding dest (optional)
 	OutputDir string `json:"outputDir,omitempty"`
 	// Filename prefix (default "zz_generated")
 	OutputStem string `json:"outputStem,omitempty"`
-	// Directory containing BPF C source files (optional)
+	// Directory that relative paths in sources are resolved against (optional)
 	SourceDir string `json:"sourceDir,omitempty"`
+	// Additional BPF C source files to generate, each with its own identifier (optional)
+	Sources []Source `json:"sources,omitempty"`
 	// Build tags (default ["linux"])
 	Tags []string `json:"tags,omitempty"`
+	// Target architectures passed to bpf2go --target (default bpf2go default "bpfel,bpfeb")
+	Targets []string `json:"targets,omitempty"`
 	// Specific types to generate (default all)
 	Types []string `json:"types,omitempty"`
 }
 
+// SourceFromMap creates a Source from a map[string]interface{}.
+func SourceFromMap(m map[string]interface{}) (*Source, error) {
+	if m == nil {
+		return &Source{}, nil
+	}
+
+	s := &Source{}
+	// Parse ident
+	if v, ok := m["ident"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Ident = val
+		} else {
+			return nil, fmt.Errorf("field ident: expected string, got %T", v)
+		}
+	}
+	// Parse outputStem
+	if v, ok := m["outputStem"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.OutputStem = val
+		} else {
+			return nil, fmt.Errorf("field outputStem: expected string, got %T", v)
+		}
+	}
+	// Parse src
+	if v, ok := m["src"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Src = val
+		} else {
+			return nil, fmt.Errorf("field src: expected string, got %T", v)
+		}
+	}
+	return s, nil
+}
+
 // SpecFromMap creates a Spec from a map[string]interface{}.
 func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	if m == nil {
@@ -113,6 +162,27 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field sourceDir: expected string, got %T", v)
 		}
 	}
+	// Parse sources
+	if v, ok := m["sources"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Sources = make([]Source, 0, len(arr))
+			for i, item := range arr {
+				if obj, ok := item.(map[string]interface{}); ok {
+					ref, err := SourceFromMap(obj)
+					if err != nil {
+						return nil, fmt.Errorf("field sources[%d]: %w", i, err)
+					}
+					if ref != nil {
+						s.Sources = append(s.Sources, *ref)
+					}
+				} else {
+					return nil, fmt.Errorf("field sources[%d]: expected object, got %T", i, item)
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("field sources: expected []object, got %T", v)
+		}
+	}
 	// Parse tags
 	if v, ok := m["tags"]; ok && v != nil {
 		if arr, ok := v.([]interface{}); ok {
@@ -130,6 +200,23 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field tags: expected []string, got %T", v)
 		}
 	}
+	// Parse targets
+	if v, ok := m["targets"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.Targets = make([]string, 0, len(arr))
+			for i, item := range arr {
+				if str, ok := item.(string); ok {
+					s.Targets = append(s.Targets, str)
+				} else {
+					return nil, fmt.Errorf("field targets[%d]: expected string, got %T", i, item)
+				}
+			}
+		} else if arr, ok := v.([]string); ok {
+			s.Targets = arr
+		} else {
+			return nil, fmt.Errorf("field targets: expected []string, got %T", v)
+		}
+	}
 	// Parse types
 	if v, ok := m["types"]; ok && v != nil {
 		if arr, ok := v.([]interface{}); ok {
@@ -150,6 +237,25 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	return s, nil
 }
 
+// ToMap converts a Source to a map[string]interface{}.
+func (s *Source) ToMap() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{})
+	if s.Ident != "" {
+		m["ident"] = s.Ident
+	}
+	if s.OutputStem != "" {
+		m["outputStem"] = s.OutputStem
+	}
+	if s.Src != "" {
+		m["src"] = s.Src
+	}
+	return m
+}
+
 // ToMap converts a Spec to a map[string]interface{}.
 func (s *Spec) ToMap() map[string]interface{} {
 	if s == nil {
@@ -181,9 +287,19 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if s.SourceDir != "" {
 		m["sourceDir"] = s.SourceDir
 	}
+	if len(s.Sources) > 0 {
+		arr := make([]interface{}, 0, len(s.Sources))
+		for _, item := range s.Sources {
+			arr = append(arr, item.ToMap())
+		}
+		m["sources"] = arr
+	}
 	if len(s.Tags) > 0 {
 		m["tags"] = s.Tags
 	}
+	if len(s.Targets) > 0 {
+		m["targets"] = s.Targets
+	}
 	if len(s.Types) > 0 {
 		m["types"] = s.Types
 	}
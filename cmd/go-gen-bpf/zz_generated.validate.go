@@ -1,13 +1,52 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:ff055ed90a004dbad73755e405b83056a8932968c7707d598a5fa41bc1a69830
+// SourceChecksum: sha256:50a9fadd9e7a80ea5aa1ae40cc1cbe0457c9351ce4c3811bb9ead341114a73bb
 
 package main
 
 import (
+	"fmt"
+
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
+// ValidateSource validates a Source and returns validation results.
+// It checks required fields and validates enum values.
+func ValidateSource(s *Source) *mcptypes.ConfigValidateOutput {
+	if s == nil {
+		return &mcptypes.ConfigValidateOutput{
+			Valid: true,
+		}
+	}
+
+	var errors []mcptypes.ValidationError
+	// Validate required field: ident
+	if s.Ident == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.ident",
+			Message: "required field is missing",
+		})
+	}
+	// Validate required field: src
+	if s.Src == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.src",
+			Message: "required field is missing",
+		})
+	}
+
+	if len(errors) > 0 {
+		return &mcptypes.ConfigValidateOutput{
+			Valid:  false,
+			Errors: errors,
+		}
+	}
+
+	return &mcptypes.ConfigValidateOutput{
+		Valid: true,
+	}
+}
+
 // ValidateSpec validates a Spec and returns validation results.
 // It checks required fields and validates enum values.
 func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
@@ -25,6 +64,18 @@ func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
 			Message: "required field is missing",
 		})
 	}
+	// Validate array of references: sources
+	for i, item := range s.Sources {
+		nestedResult := ValidateSource(&item)
+		if !nestedResult.Valid {
+			for _, e := range nestedResult.Errors {
+				errors = append(errors, mcptypes.ValidationError{
+					Field:   fmt.Sprintf("spec.sources[%d].%s", i, e.Field),
+					Message: e.Message,
+				})
+			}
+		}
+	}
 
 	if len(errors) > 0 {
 		return &mcptypes.ConfigValidateOutput{
@@ -20,6 +20,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/engineversion"
@@ -34,7 +35,7 @@ func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.A
 	// Get mocksDir from environment variable
 	mocksDir := os.Getenv("MOCKS_DIR")
 
-	if err := generateMocks(mocksDir); err != nil {
+	if err := generateMocks(mocksDir, input.RootDir); err != nil {
 		return nil, fmt.Errorf("mock generation failed: %w", err)
 	}
 
@@ -72,14 +73,9 @@ func detectMockDependencies(ctx context.Context, rootDir string) ([]forge.Artifa
 		return nil, err
 	}
 
-	// Handle empty RootDir (use current working directory)
-	workDir := rootDir
-	if workDir == "" {
-		workDir, _ = os.Getwd()
-	}
-
+	// rootDir is always set: the builder framework defaults it to the working directory
 	input := map[string]any{
-		"rootDir": workDir,
+		"rootDir": rootDir,
 	}
 
 	return engineframework.CallDetector(ctx, cmd, args, "detectDependencies", input)
@@ -95,7 +91,9 @@ func getMocksDir(mocksDir string) string {
 	return "./internal/util/mocks"
 }
 
-func generateMocks(mocksDir string) error {
+// generateMocks runs mockery from rootDir, where .mockery.yaml is looked up.
+// A relative mocks directory is resolved against rootDir.
+func generateMocks(mocksDir, rootDir string) error {
 	mockeryVersion := os.Getenv("MOCKERY_VERSION")
 	if mockeryVersion == "" {
 		mockeryVersion = "v3.5.5"
@@ -105,12 +103,16 @@ func generateMocks(mocksDir string) error {
 
 	// Clean mocks directory
 	dir := getMocksDir(mocksDir)
+	if rootDir != "" && !filepath.IsAbs(dir) {
+		dir = filepath.Join(rootDir, dir)
+	}
 	if err := os.RemoveAll(dir); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to clean mocks directory: %w", err)
 	}
 
 	// Generate mocks
 	cmd := exec.Command("go", "run", mockery)
+	cmd.Dir = rootDir
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -24,15 +24,17 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
 	"github.com/alexandremahdhaoui/forge/internal/util"
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/engineversion"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+	"github.com/alexandremahdhaoui/forge/pkg/templateutil"
 )
 
 const (
-	sourceFileTemplate  = "%s.%s.yaml"
+	sourceFileTemplate  = "{name}.{version}.yaml"
 	zzGeneratedFilename = "zz_generated.oapi-codegen.go"
 
 	clientTemplate = `---
@@ -61,7 +63,7 @@ output-options:
 )
 
 // Build implements the BuilderFunc for generating OpenAPI client and server code
-func Build(ctx context.Context, input mcptypes.BuildInput, _ *Spec) (*forge.Artifact, error) {
+func Build(ctx context.Context, input mcptypes.BuildInput, spec *Spec) (*forge.Artifact, error) {
 	log.Printf("Generating OpenAPI code for: %s", input.Name)
 
 	// Extract OpenAPI config from BuildInput.Spec
@@ -79,7 +81,7 @@ func Build(ctx context.Context, input mcptypes.BuildInput, _ *Spec) (*forge.Arti
 	executable := fmt.Sprintf("go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@%s", oapiCodegenVersion)
 
 	// Call existing generation logic, passing RootDir for relative path resolution
-	if err := doGenerate(executable, *config, input.RootDir); err != nil {
+	if err := doGenerate(executable, *config, input.RootDir, spec.VerifyCompile); err != nil {
 		return nil, fmt.Errorf("generation failed: %w", err)
 	}
 
@@ -136,7 +138,7 @@ func detectOpenAPIDependencies(ctx context.Context, specPaths []string, rootDir
 	return engineframework.CallDetector(ctx, cmd, args, "detectDependencies", input)
 }
 
-func doGenerate(executable string, config forge.GenerateOpenAPIConfig, rootDir string) error {
+func doGenerate(executable string, config forge.GenerateOpenAPIConfig, rootDir string, verifyCompile bool) error {
 	cmdName, args := parseExecutable(executable)
 	errChan := make(chan error, 100) // Buffered to avoid goroutine leaks
 	wg := &sync.WaitGroup{}
@@ -177,7 +179,11 @@ func doGenerate(executable string, config forge.GenerateOpenAPIConfig, rootDir s
 			for _, version := range versions {
 				version := version
 
-				sourcePath := templateSourcePath(config, i, version)
+				sourcePath, err := templateSourcePath(config, i, version)
+				if err != nil {
+					errChan <- err
+					continue
+				}
 
 				// Generate client if enabled
 				if config.Specs[i].Client.Enabled {
@@ -221,13 +227,22 @@ func doGenerate(executable string, config forge.GenerateOpenAPIConfig, rootDir s
 		return fmt.Errorf("generation failed: %s", strings.Join(errors, "; "))
 	}
 
+	if verifyCompile {
+		if err := verifyPackagesCompile(generatedPackageDirs(config), rootDir); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintln(os.Stderr, "Successfully generated OpenAPI code")
 	return nil
 }
 
 func generatePackage(cmdName string, baseArgs []string, config forge.GenerateOpenAPIConfig, specIndex int, version string, opts forge.GenOpts, template string, sourcePath string, rootDir string) error {
 	outputPath := templateOutputPath(config, specIndex, opts.PackageName)
-	templatedConfig := fmt.Sprintf(template, opts.PackageName, outputPath)
+	templatedConfig, err := renderCodegenConfig(template, opts, outputPath)
+	if err != nil {
+		return err
+	}
 
 	path, cleanup, err := writeTempCodegenConfig(templatedConfig)
 	if err != nil {
@@ -262,13 +277,47 @@ func generatePackage(cmdName string, baseArgs []string, config forge.GenerateOpe
 	return nil
 }
 
+// generatedPackageDirs returns the directories of the packages generated for config.
+func generatedPackageDirs(config forge.GenerateOpenAPIConfig) []string {
+	var dirs []string
+	for i, spec := range config.Specs {
+		for _, opts := range []forge.GenOpts{spec.Client, spec.Server} {
+			if opts.Enabled {
+				dirs = append(dirs, filepath.Dir(templateOutputPath(config, i, opts.PackageName)))
+			}
+		}
+	}
+	return dirs
+}
+
+// verifyPackagesCompile runs go build on each package directory, resolved from rootDir,
+// and returns the compiler output of the first package that does not compile.
+func verifyPackagesCompile(dirs []string, rootDir string) error {
+	for _, dir := range dirs {
+		pkg := dir
+		if !filepath.IsAbs(pkg) {
+			pkg = "./" + filepath.ToSlash(filepath.Clean(pkg))
+		}
+
+		cmd := exec.Command("go", "build", pkg)
+		if rootDir != "" {
+			cmd.Dir = rootDir
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("generated package %s does not compile: %w\n%s", dir, err, output)
+		}
+		log.Printf("Verified generated package %s compiles", dir)
+	}
+	return nil
+}
+
 func parseExecutable(executable string) (string, []string) {
 	split := strings.Split(executable, " ")
 	return split[0], split[1:]
 }
 
 func writeTempCodegenConfig(templatedConfig string) (string, func(), error) {
-	tempFile, err := os.CreateTemp("", "oapi-codegen-*.yaml")
+	tempFile, err := tempdir.CreateTemp("oapi-codegen-*.yaml")
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -299,17 +348,23 @@ func templateOutputPath(config forge.GenerateOpenAPIConfig, index int, packageNa
 	return filepath.Join(destDir, packageName, zzGeneratedFilename)
 }
 
-func templateSourcePath(config forge.GenerateOpenAPIConfig, index int, version string) string {
+func templateSourcePath(config forge.GenerateOpenAPIConfig, index int, version string) (string, error) {
 	if source := config.Specs[index].Source; source != "" {
-		return source
+		return source, nil
 	}
 
-	sourceFile := fmt.Sprintf(sourceFileTemplate, config.Specs[index].Name, version)
+	sourceFile, err := templateutil.ExpandPath(sourceFileTemplate, templateutil.PathValues{
+		Name:    config.Specs[index].Name,
+		Version: version,
+	})
+	if err != nil {
+		return "", err
+	}
 
 	sourceDir := config.Defaults.SourceDir
 	if config.Specs[index].SourceDir != "" {
 		sourceDir = config.Specs[index].SourceDir
 	}
 
-	return filepath.Join(sourceDir, sourceFile)
+	return filepath.Join(sourceDir, sourceFile), nil
 }
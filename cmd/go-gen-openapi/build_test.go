@@ -0,0 +1,101 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// fixtureConfig returns a config generating a client and a server package under rootDir,
+// which is set up as a Go module.
+func fixtureConfig(t *testing.T) (forge.GenerateOpenAPIConfig, string) {
+	t.Helper()
+
+	rootDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rootDir, "go.mod"), []byte("module example.com/fixture\n\ngo 1.25\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return forge.GenerateOpenAPIConfig{
+		Specs: []forge.GenerateOpenAPISpec{{
+			Source:         "./api/example.v1.yaml",
+			DestinationDir: "./pkg/generated",
+			Client:         forge.GenOpts{Enabled: true, PackageName: "exampleclient"},
+			Server:         forge.GenOpts{Enabled: true, PackageName: "exampleserver"},
+		}},
+	}, rootDir
+}
+
+// fakeCodegen returns the executable used in place of oapi-codegen.
+func fakeCodegen(t *testing.T) string {
+	t.Helper()
+
+	script, err := filepath.Abs(filepath.Join("testdata", "fake-oapi-codegen.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "sh " + script
+}
+
+func TestGeneratedPackageDirs(t *testing.T) {
+	config, _ := fixtureConfig(t)
+	config.Specs[0].Server.Enabled = false
+
+	dirs := generatedPackageDirs(config)
+	if len(dirs) != 1 || dirs[0] != filepath.Join("pkg", "generated", "exampleclient") {
+		t.Errorf("generatedPackageDirs() = %v, want [pkg/generated/exampleclient]", dirs)
+	}
+}
+
+func TestDoGenerate_VerifyCompile(t *testing.T) {
+	config, rootDir := fixtureConfig(t)
+
+	if err := doGenerate(fakeCodegen(t), config, rootDir, true); err != nil {
+		t.Fatalf("doGenerate() error = %v", err)
+	}
+
+	for _, pkg := range []string{"exampleclient", "exampleserver"} {
+		if _, err := os.Stat(filepath.Join(rootDir, "pkg", "generated", pkg, zzGeneratedFilename)); err != nil {
+			t.Errorf("generated file for %s: %v", pkg, err)
+		}
+	}
+}
+
+func TestDoGenerate_VerifyCompileFailure(t *testing.T) {
+	t.Setenv("FAKE_OAPI_CODEGEN_BROKEN", "1")
+
+	// Without verifyCompile, code that does not compile is not detected
+	config, rootDir := fixtureConfig(t)
+	if err := doGenerate(fakeCodegen(t), config, rootDir, false); err != nil {
+		t.Fatalf("doGenerate() without verifyCompile error = %v", err)
+	}
+
+	err := doGenerate(fakeCodegen(t), config, rootDir, true)
+	if err == nil {
+		t.Fatal("doGenerate() error = nil, want a compile error")
+	}
+	for _, want := range []string{"does not compile", "undefinedValue"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
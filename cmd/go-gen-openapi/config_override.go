@@ -0,0 +1,81 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"sigs.k8s.io/yaml"
+)
+
+// reservedCodegenConfigKeys are derived from packageName and destinationDir and cannot be overridden.
+var reservedCodegenConfigKeys = []string{"package", "output"}
+
+// renderCodegenConfig renders the oapi-codegen configuration template for a package and
+// merges opts.ConfigOverride into it. Overriding keys win; each replaced value is logged.
+func renderCodegenConfig(template string, opts forge.GenOpts, outputPath string) (string, error) {
+	rendered := fmt.Sprintf(template, opts.PackageName, outputPath)
+	if len(opts.ConfigOverride) == 0 {
+		return rendered, nil
+	}
+
+	for _, key := range reservedCodegenConfigKeys {
+		if _, ok := opts.ConfigOverride[key]; ok {
+			return "", fmt.Errorf("config override for %s cannot set %q: it is derived from packageName and destinationDir", opts.PackageName, key)
+		}
+	}
+
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(rendered), &config); err != nil {
+		return "", fmt.Errorf("failed to parse codegen config for %s: %w", opts.PackageName, err)
+	}
+
+	mergeCodegenConfig(config, opts.ConfigOverride, opts.PackageName, "")
+
+	merged, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to render codegen config for %s: %w", opts.PackageName, err)
+	}
+	return string(merged), nil
+}
+
+// mergeCodegenConfig recursively merges override into config. Nested maps are merged
+// key by key; any other value replaces the existing one, which is logged when it differs.
+func mergeCodegenConfig(config, override map[string]interface{}, packageName, prefix string) {
+	for key, value := range override {
+		path := prefix + key
+
+		existing, ok := config[key]
+		if !ok {
+			config[key] = value
+			continue
+		}
+
+		existingMap, existingIsMap := existing.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if existingIsMap && valueIsMap {
+			mergeCodegenConfig(existingMap, valueMap, packageName, path+".")
+			continue
+		}
+
+		if !reflect.DeepEqual(existing, value) {
+			log.Printf("Config override for %s replaces %s: %v -> %v", packageName, path, existing, value)
+		}
+		config[key] = value
+	}
+}
@@ -0,0 +1,113 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"sigs.k8s.io/yaml"
+)
+
+// parseRendered parses a rendered codegen config.
+func parseRendered(t *testing.T, rendered string) map[string]interface{} {
+	t.Helper()
+
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(rendered), &config); err != nil {
+		t.Fatalf("rendered config is not valid YAML: %v\n%s", err, rendered)
+	}
+	return config
+}
+
+func TestRenderCodegenConfig_NoOverride(t *testing.T) {
+	opts := forge.GenOpts{Enabled: true, PackageName: "exampleclient"}
+
+	rendered, err := renderCodegenConfig(clientTemplate, opts, "pkg/generated/exampleclient/zz_generated.oapi-codegen.go")
+	if err != nil {
+		t.Fatalf("renderCodegenConfig() error = %v", err)
+	}
+	if !strings.Contains(rendered, "# to make sure that all types are generated") {
+		t.Errorf("rendered config without override should be the template as-is:\n%s", rendered)
+	}
+}
+
+func TestRenderCodegenConfig_MergesOverride(t *testing.T) {
+	opts := forge.GenOpts{
+		Enabled:     true,
+		PackageName: "exampleserver",
+		ConfigOverride: map[string]interface{}{
+			"additional-imports": []interface{}{
+				map[string]interface{}{"package": "github.com/acme/types", "alias": "types"},
+			},
+			"output-options": map[string]interface{}{
+				"response-type-suffix": "Resp",
+				"skip-prune":           false,
+			},
+			"generate": map[string]interface{}{
+				"chi-server": true,
+			},
+		},
+	}
+
+	rendered, err := renderCodegenConfig(serverTemplate, opts, "pkg/generated/exampleserver/zz_generated.oapi-codegen.go")
+	if err != nil {
+		t.Fatalf("renderCodegenConfig() error = %v", err)
+	}
+	config := parseRendered(t, rendered)
+
+	want := map[string]interface{}{
+		"package": "exampleserver",
+		"output":  "pkg/generated/exampleserver/zz_generated.oapi-codegen.go",
+		"generate": map[string]interface{}{
+			"embedded-spec":   true,
+			"models":          true,
+			"std-http-server": true,
+			"strict-server":   true,
+			"chi-server":      true,
+		},
+		"output-options": map[string]interface{}{
+			"skip-prune":           false,
+			"response-type-suffix": "Resp",
+		},
+		"additional-imports": []interface{}{
+			map[string]interface{}{"package": "github.com/acme/types", "alias": "types"},
+		},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("merged config = %v, want %v", config, want)
+	}
+}
+
+func TestRenderCodegenConfig_ReservedKeys(t *testing.T) {
+	for _, key := range reservedCodegenConfigKeys {
+		t.Run(key, func(t *testing.T) {
+			opts := forge.GenOpts{
+				Enabled:        true,
+				PackageName:    "exampleclient",
+				ConfigOverride: map[string]interface{}{key: "other"},
+			}
+
+			_, err := renderCodegenConfig(clientTemplate, opts, "pkg/generated/exampleclient/zz_generated.oapi-codegen.go")
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("renderCodegenConfig() error = %v, want an error naming %q", err, key)
+			}
+		})
+	}
+}
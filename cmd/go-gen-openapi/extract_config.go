@@ -20,6 +20,7 @@ import (
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+	"github.com/alexandremahdhaoui/forge/pkg/templateutil"
 )
 
 // extractOpenAPIConfigFromInput extracts OpenAPI config from BuildInput.Spec.
@@ -40,6 +41,10 @@ import (
 //	  // Client/Server
 //	  "client": {"enabled": bool, "packageName": string},
 //	  "server": {"enabled": bool, "packageName": string},
+//
+//	  // oapi-codegen configuration merged into the generated one
+//	  "clientConfigOverride": map,
+//	  "serverConfigOverride": map,
 //	}
 //
 // Validation rules:
@@ -97,6 +102,15 @@ func extractOpenAPIConfigFromInput(input mcptypes.BuildInput) (*forge.GenerateOp
 		serverPackageName, _ = serverMap["packageName"].(string)
 	}
 
+	clientConfigOverride, err := configOverrideFromSpec(spec, "clientConfigOverride")
+	if err != nil {
+		return nil, err
+	}
+	serverConfigOverride, err := configOverrideFromSpec(spec, "serverConfigOverride")
+	if err != nil {
+		return nil, err
+	}
+
 	// Validation Rule 1: MUST provide EITHER sourceFile OR (sourceDir AND name AND version)
 	hasSourceFile := sourceFile != ""
 	hasTemplatedSource := sourceDir != "" && name != "" && version != ""
@@ -133,8 +147,11 @@ func extractOpenAPIConfigFromInput(input mcptypes.BuildInput) (*forge.GenerateOp
 	} else {
 		// Pattern 2: Templated from sourceDir+name+version
 		// Template it now instead of relying on doGenerate to do it
-		// Use sourceFileTemplate const from main.go: "%s.%s.yaml"
-		filename := fmt.Sprintf(sourceFileTemplate, name, version)
+		// Use sourceFileTemplate const from build.go: "{name}.{version}.yaml"
+		filename, err := templateutil.ExpandPath(sourceFileTemplate, templateutil.PathValues{Name: name, Version: version})
+		if err != nil {
+			return nil, err
+		}
 		// Preserve the sourceDir path exactly as provided (including ./ prefix if present)
 		if sourceDir == "" || sourceDir == "." {
 			actualSourcePath = filename
@@ -155,12 +172,14 @@ func extractOpenAPIConfigFromInput(input mcptypes.BuildInput) (*forge.GenerateOp
 				DestinationDir: destinationDir,
 				Versions:       []string{}, // Empty - no versions array in new design
 				Client: forge.GenOpts{
-					Enabled:     clientEnabled,
-					PackageName: clientPackageName,
+					Enabled:        clientEnabled,
+					PackageName:    clientPackageName,
+					ConfigOverride: clientConfigOverride,
 				},
 				Server: forge.GenOpts{
-					Enabled:     serverEnabled,
-					PackageName: serverPackageName,
+					Enabled:        serverEnabled,
+					PackageName:    serverPackageName,
+					ConfigOverride: serverConfigOverride,
 				},
 			},
 		},
@@ -168,3 +187,17 @@ func extractOpenAPIConfigFromInput(input mcptypes.BuildInput) (*forge.GenerateOp
 
 	return config, nil
 }
+
+// configOverrideFromSpec returns the oapi-codegen configuration override stored under key.
+func configOverrideFromSpec(spec map[string]interface{}, key string) (map[string]interface{}, error) {
+	value, ok := spec[key]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	override, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a map, got %T", key, value)
+	}
+	return override, nil
+}
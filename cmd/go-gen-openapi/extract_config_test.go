@@ -17,6 +17,7 @@
 package main
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
@@ -285,6 +286,56 @@ func TestExtractOpenAPIConfigFromInput(t *testing.T) {
 			wantError: true,
 			errorMsg:  "client.enabled must be a boolean",
 		},
+		{
+			name: "valid client config override",
+			input: mcptypes.BuildInput{
+				Name:   "api-v1",
+				Engine: "go://go-gen-openapi",
+				Spec: map[string]interface{}{
+					"sourceFile": "./api/api.v1.yaml",
+					"client": map[string]interface{}{
+						"enabled":     true,
+						"packageName": "apiclient",
+					},
+					"clientConfigOverride": map[string]interface{}{
+						"output-options": map[string]interface{}{"response-type-suffix": "Resp"},
+					},
+				},
+			},
+			want: &forge.GenerateOpenAPIConfig{
+				Specs: []forge.GenerateOpenAPISpec{
+					{
+						Source:         "./api/api.v1.yaml",
+						DestinationDir: "./pkg/generated",
+						Versions:       []string{},
+						Client: forge.GenOpts{
+							Enabled:     true,
+							PackageName: "apiclient",
+							ConfigOverride: map[string]interface{}{
+								"output-options": map[string]interface{}{"response-type-suffix": "Resp"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "error - invalid config override type",
+			input: mcptypes.BuildInput{
+				Name:   "api-v1",
+				Engine: "go://go-gen-openapi",
+				Spec: map[string]interface{}{
+					"sourceFile": "./api/api.v1.yaml",
+					"server": map[string]interface{}{
+						"enabled":     true,
+						"packageName": "apiserver",
+					},
+					"serverConfigOverride": "skip-prune: false",
+				},
+			},
+			wantError: true,
+			errorMsg:  "serverConfigOverride must be a map, got string",
+		},
 	}
 
 	for _, tt := range tests {
@@ -353,6 +404,12 @@ func TestExtractOpenAPIConfigFromInput(t *testing.T) {
 			if gotSpec.Server.PackageName != wantSpec.Server.PackageName {
 				t.Errorf("Server.PackageName = %v, want %v", gotSpec.Server.PackageName, wantSpec.Server.PackageName)
 			}
+			if !reflect.DeepEqual(gotSpec.Client.ConfigOverride, wantSpec.Client.ConfigOverride) {
+				t.Errorf("Client.ConfigOverride = %v, want %v", gotSpec.Client.ConfigOverride, wantSpec.Client.ConfigOverride)
+			}
+			if !reflect.DeepEqual(gotSpec.Server.ConfigOverride, wantSpec.Server.ConfigOverride) {
+				t.Errorf("Server.ConfigOverride = %v, want %v", gotSpec.Server.ConfigOverride, wantSpec.Server.ConfigOverride)
+			}
 		})
 	}
 }
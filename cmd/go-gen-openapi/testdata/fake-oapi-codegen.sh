@@ -0,0 +1,16 @@
+#!/bin/sh
+# Stands in for oapi-codegen in unit tests: reads the package and output from the
+# config passed with --config and writes a minimal Go file there.
+# Set FAKE_OAPI_CODEGEN_BROKEN to write code that does not compile.
+set -e
+
+config="$2"
+package=$(sed -n 's/^package: //p' "$config")
+output=$(sed -n 's/^output: //p' "$config")
+
+mkdir -p "$(dirname "$output")"
+if [ -n "$FAKE_OAPI_CODEGEN_BROKEN" ]; then
+	printf 'package %s\n\nfunc Broken() string { return undefinedValue }\n' "$package" > "$output"
+else
+	printf 'package %s\n\nfunc Hello() string { return "hello" }\n' "$package" > "$output"
+fi
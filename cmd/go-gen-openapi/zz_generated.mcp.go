@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:8d20a6cfab2fb4622603c599d541f447713431484827f048c7f8fd6cc6269e94
+// SourceChecksum: sha256:ad822a60c58cc9e9b4ea23eb3fa16f3a6795a3bf1bd8da7d8202e5f342e11077
 
 package main
 
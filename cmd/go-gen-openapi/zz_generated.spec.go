@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:8d20a6cfab2fb4622603c599d541f447713431484827f048c7f8fd6cc6269e94
+// SourceChecksum: sha256:ad822a60c58cc9e9b4ea23eb3fa16f3a6795a3bf1bd8da7d8202e5f342e11077
 
 package main
 
@@ -11,8 +11,14 @@ import (
 // Spec represents the Spec configuration.
 // Configuration for go-gen-openapi. Uses oapi-codegen for code generation.
 type Spec struct {
+	// oapi-codegen configuration merged into the generated client config; overriding keys win
+	ClientConfigOverride map[string]interface{} `json:"clientConfigOverride,omitempty"`
 	// Version of oapi-codegen to use (default v2.3.0)
 	OapiCodegenVersion string `json:"oapiCodegenVersion,omitempty"`
+	// oapi-codegen configuration merged into the generated server config; overriding keys win
+	ServerConfigOverride map[string]interface{} `json:"serverConfigOverride,omitempty"`
+	// Run go build on each generated package and fail if it does not compile
+	VerifyCompile bool `json:"verifyCompile,omitempty"`
 }
 
 // SpecFromMap creates a Spec from a map[string]interface{}.
@@ -22,6 +28,17 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	}
 
 	s := &Spec{}
+	// Parse clientConfigOverride
+	if v, ok := m["clientConfigOverride"]; ok && v != nil {
+		if mapVal, ok := v.(map[string]interface{}); ok {
+			s.ClientConfigOverride = make(map[string]interface{}, len(mapVal))
+			for key, val := range mapVal {
+				s.ClientConfigOverride[key] = val.(interface{})
+			}
+		} else {
+			return nil, fmt.Errorf("field clientConfigOverride: expected map, got %T", v)
+		}
+	}
 	// Parse oapiCodegenVersion
 	if v, ok := m["oapiCodegenVersion"]; ok && v != nil {
 		if val, ok := v.(string); ok {
@@ -30,6 +47,25 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field oapiCodegenVersion: expected string, got %T", v)
 		}
 	}
+	// Parse serverConfigOverride
+	if v, ok := m["serverConfigOverride"]; ok && v != nil {
+		if mapVal, ok := v.(map[string]interface{}); ok {
+			s.ServerConfigOverride = make(map[string]interface{}, len(mapVal))
+			for key, val := range mapVal {
+				s.ServerConfigOverride[key] = val.(interface{})
+			}
+		} else {
+			return nil, fmt.Errorf("field serverConfigOverride: expected map, got %T", v)
+		}
+	}
+	// Parse verifyCompile
+	if v, ok := m["verifyCompile"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.VerifyCompile = val
+		} else {
+			return nil, fmt.Errorf("field verifyCompile: expected bool, got %T", v)
+		}
+	}
 	return s, nil
 }
 
@@ -40,9 +76,18 @@ func (s *Spec) ToMap() map[string]interface{} {
 	}
 
 	m := make(map[string]interface{})
+	if len(s.ClientConfigOverride) > 0 {
+		m["clientConfigOverride"] = s.ClientConfigOverride
+	}
 	if s.OapiCodegenVersion != "" {
 		m["oapiCodegenVersion"] = s.OapiCodegenVersion
 	}
+	if len(s.ServerConfigOverride) > 0 {
+		m["serverConfigOverride"] = s.ServerConfigOverride
+	}
+	if s.VerifyCompile {
+		m["verifyCompile"] = s.VerifyCompile
+	}
 	return m
 }
 
@@ -15,15 +15,18 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/alexandremahdhaoui/forge/internal/buildtag"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 	"github.com/google/uuid"
@@ -140,42 +143,25 @@ func findTestFiles(root string) ([]string, error) {
 	return testFiles, err
 }
 
-// checkBuildTag checks if a file has one of the expected build tags.
+// checkBuildTag reports whether the build constraint of filePath references one of expectedTags.
+// Both //go:build and legacy // +build lines are recognized; //go:build takes precedence when
+// both are present. Files relying on legacy lines only are accepted with a migration warning.
 func checkBuildTag(filePath string, expectedTags []string) (bool, error) {
-	file, err := os.Open(filePath)
+	header, err := buildtag.ParseFile(filePath)
 	if err != nil {
 		return false, err
 	}
-	defer func() { _ = file.Close() }()
-
-	scanner := bufio.NewScanner(file)
-
-	// Check first few lines for build tag
-	lineCount := 0
-	for scanner.Scan() && lineCount < 5 {
-		line := strings.TrimSpace(scanner.Text())
-		lineCount++
-
-		// Check for go:build directive
-		if strings.HasPrefix(line, "//go:build") {
-			// Verify it's one of our expected tags
-			for _, tag := range expectedTags {
-				if strings.Contains(line, tag) {
-					return true, nil
-				}
-			}
-		}
 
-		// Skip empty lines and comments, but stop at package declaration
-		if strings.HasPrefix(line, "package ") {
-			break
+	expr := header.Expr()
+	for _, tag := range expectedTags {
+		if buildtag.HasTag(expr, tag) {
+			if header.GoBuild == nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s uses legacy // +build syntax, consider migrating to //go:build\n", filePath)
+			}
+			return true, nil
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return false, err
-	}
-
 	return false, nil
 }
 
@@ -193,17 +179,55 @@ func verifyTags(rootDir string, expectedTags []string) ([]string, int, error) {
 	}
 
 	// Verify each test file has a build tag
-	var filesWithoutTags []string
-	for _, file := range testFiles {
-		hasBuildTag, err := checkBuildTag(file, expectedTags)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error checking %s: %v\n", file, err)
-			continue
-		}
-		if !hasBuildTag {
+	filesWithoutTags := findFilesWithoutTags(testFiles, expectedTags, runtime.NumCPU())
+
+	return filesWithoutTags, len(testFiles), nil
+}
+
+// findFilesWithoutTags checks files concurrently using at most workers goroutines
+// and returns the files missing an expected build tag, sorted for deterministic output.
+// Files that cannot be checked are reported on stderr and skipped.
+func findFilesWithoutTags(files []string, expectedTags []string, workers int) []string {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	// Each worker writes only the indexes it receives, so no locking is needed
+	missing := make([]bool, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hasBuildTag, err := checkBuildTag(files[i], expectedTags)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error checking %s: %v\n", files[i], err)
+					continue
+				}
+				missing[i] = !hasBuildTag
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	filesWithoutTags := []string{}
+	for i, file := range files {
+		if missing[i] {
 			filesWithoutTags = append(filesWithoutTags, file)
 		}
 	}
+	sort.Strings(filesWithoutTags)
 
-	return filesWithoutTags, len(testFiles), nil
+	return filesWithoutTags
 }
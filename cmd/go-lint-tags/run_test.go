@@ -0,0 +1,145 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCheckBuildTag(t *testing.T) {
+	expectedTags := []string{"unit", "integration", "e2e"}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{
+			name:     "modern go:build only",
+			content:  "//go:build unit\n\npackage foo\n",
+			expected: true,
+		},
+		{
+			name:     "legacy plus build only",
+			content:  "// +build integration\n\npackage foo\n",
+			expected: true,
+		},
+		{
+			name:     "both forms present",
+			content:  "//go:build e2e\n// +build e2e\n\npackage foo\n",
+			expected: true,
+		},
+		{
+			name:     "go:build takes precedence over legacy line",
+			content:  "//go:build linux\n// +build unit\n\npackage foo\n",
+			expected: false,
+		},
+		{
+			name:     "legacy plus build with unexpected tag",
+			content:  "// +build linux\n\npackage foo\n",
+			expected: false,
+		},
+		{
+			name:     "no build tag",
+			content:  "package foo\n",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "foo_test.go")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			got, err := checkBuildTag(path, expectedTags)
+			if err != nil {
+				t.Fatalf("checkBuildTag() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("checkBuildTag() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindFilesWithoutTags(t *testing.T) {
+	expectedTags := []string{"unit", "integration", "e2e"}
+	dir := t.TempDir()
+
+	// Create a mix of tagged and untagged files across nested directories
+	for i := 0; i < 50; i++ {
+		content := "//go:build unit\n\npackage foo\n"
+		if i%3 == 0 {
+			content = "package foo\n"
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("pkg%d", i%7), fmt.Sprintf("file%02d_test.go", i))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	files, err := findTestFiles(dir)
+	if err != nil {
+		t.Fatalf("findTestFiles() error = %v", err)
+	}
+
+	// Serial reference implementation
+	serial := []string{}
+	for _, file := range files {
+		hasBuildTag, err := checkBuildTag(file, expectedTags)
+		if err != nil {
+			t.Fatalf("checkBuildTag() error = %v", err)
+		}
+		if !hasBuildTag {
+			serial = append(serial, file)
+		}
+	}
+	sort.Strings(serial)
+
+	if len(serial) != 17 {
+		t.Fatalf("Expected 17 files without tags, got %d", len(serial))
+	}
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			for run := 0; run < 5; run++ {
+				got := findFilesWithoutTags(files, expectedTags, workers)
+				if !reflect.DeepEqual(got, serial) {
+					t.Fatalf("Run %d: result differs from serial version\ngot:      %v\nexpected: %v", run, got, serial)
+				}
+			}
+		})
+	}
+}
+
+func TestFindFilesWithoutTags_NoFiles(t *testing.T) {
+	got := findFilesWithoutTags(nil, []string{"unit"}, 4)
+	if len(got) != 0 {
+		t.Errorf("Expected no files, got %v", got)
+	}
+}
@@ -153,12 +153,6 @@ func storeTestReport(report *TestReport, junitFile, coverageFile string) error {
 		}
 	}
 
-	// Read or create artifact store
-	store, err := forge.ReadOrCreateArtifactStore(artifactStorePath)
-	if err != nil {
-		return fmt.Errorf("failed to read artifact store: %w", err)
-	}
-
 	// Generate report ID (UUID)
 	reportID := uuid.New().String()
 
@@ -186,11 +180,11 @@ func storeTestReport(report *TestReport, junitFile, coverageFile string) error {
 	}
 
 	// Add or update test report
-	forge.AddOrUpdateTestReport(&store, storeReport)
-
-	// Write artifact store
-	if err := forge.WriteArtifactStore(artifactStorePath, store); err != nil {
-		return fmt.Errorf("failed to write artifact store: %w", err)
+	if err := forge.UpdateArtifactStore(artifactStorePath, func(store *forge.ArtifactStore) error {
+		forge.AddOrUpdateTestReport(store, storeReport)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update artifact store: %w", err)
 	}
 
 	return nil
@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 )
@@ -32,19 +34,21 @@ type DeleteResult struct {
 	PartiallyDeleted bool     `json:"partiallyDeleted"`
 }
 
+// BulkDeleteResult represents the result of a bulk delete operation.
+type BulkDeleteResult struct {
+	DryRun  bool           `json:"dryRun"`
+	Matched int            `json:"matched"`
+	Deleted int            `json:"deleted"`
+	Failed  int            `json:"failed"`
+	IDs     []string       `json:"ids,omitempty"`
+	Results []DeleteResult `json:"results,omitempty"`
+}
+
 // cmdDelete deletes a test report and its associated artifact files.
 func cmdDelete(reportID string) error {
-	// Get artifact store path from environment variable
-	artifactStorePath := os.Getenv("FORGE_ARTIFACT_STORE_PATH")
-	if artifactStorePath == "" {
-		config, err := forge.ReadSpec()
-		if err != nil {
-			return fmt.Errorf("failed to read forge.yaml: %w", err)
-		}
-		artifactStorePath, err = forge.GetArtifactStorePath(config.ArtifactStorePath)
-		if err != nil {
-			return fmt.Errorf("failed to get artifact store path: %w", err)
-		}
+	artifactStorePath, err := resolveArtifactStorePath()
+	if err != nil {
+		return err
 	}
 
 	// Read artifact store
@@ -59,6 +63,103 @@ func cmdDelete(reportID string) error {
 		return fmt.Errorf("failed to get test report: %w", err)
 	}
 
+	result := deleteReport(artifactStorePath, report)
+	outputResult(result)
+
+	if !result.Success {
+		return fmt.Errorf("failed to delete test report: %s", result.ErrorMessage)
+	}
+
+	return nil
+}
+
+// cmdBulkDelete deletes all test reports matching the stage and age filters.
+// At least one filter is required so that a bare invocation never wipes the store.
+// With dryRun, matching reports are listed but nothing is deleted.
+func cmdBulkDelete(stageFilter string, olderThan time.Duration, dryRun bool) error {
+	if stageFilter == "" && olderThan <= 0 {
+		return fmt.Errorf("bulk delete requires at least one of --stage or --older-than")
+	}
+
+	artifactStorePath, err := resolveArtifactStorePath()
+	if err != nil {
+		return err
+	}
+
+	store, err := forge.ReadArtifactStore(artifactStorePath)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact store: %w", err)
+	}
+
+	selected := selectReportsForDeletion(forge.ListTestReports(&store, ""), stageFilter, olderThan, time.Now())
+
+	result := BulkDeleteResult{
+		DryRun:  dryRun,
+		Matched: len(selected),
+	}
+
+	for _, report := range selected {
+		result.IDs = append(result.IDs, report.ID)
+		if dryRun {
+			continue
+		}
+
+		deleteResult := deleteReport(artifactStorePath, report)
+		if deleteResult.Success {
+			result.Deleted++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, deleteResult)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(result) // Ignore error since this is best-effort output
+
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "Dry-run: %d test report(s) would be deleted\n", result.Matched)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Deleted %d of %d test report(s)\n", result.Deleted, result.Matched)
+	if result.Failed > 0 {
+		return fmt.Errorf("failed to delete %d test report(s)", result.Failed)
+	}
+
+	return nil
+}
+
+// selectReportsForDeletion returns the reports matching stageFilter (if set) and
+// started more than olderThan before now (if positive), sorted oldest first.
+func selectReportsForDeletion(reports []*forge.TestReport, stageFilter string, olderThan time.Duration, now time.Time) []*forge.TestReport {
+	var selected []*forge.TestReport
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		if stageFilter != "" && report.Stage != stageFilter {
+			continue
+		}
+		if olderThan > 0 && !report.StartTime.Before(now.Add(-olderThan)) {
+			continue
+		}
+		selected = append(selected, report)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].StartTime.Equal(selected[j].StartTime) {
+			return selected[i].ID < selected[j].ID
+		}
+		return selected[i].StartTime.Before(selected[j].StartTime)
+	})
+
+	return selected
+}
+
+// deleteReport deletes the artifact files of report, then removes it from the store.
+// Missing files are considered already deleted.
+func deleteReport(artifactStorePath string, report *forge.TestReport) DeleteResult {
 	// Delete artifact files
 	var deletedFiles []string
 	var failedFiles []string
@@ -77,23 +178,21 @@ func cmdDelete(reportID string) error {
 
 	// Delete test report from store atomically
 	// Use AtomicDeleteTestReport to avoid race conditions with concurrent writes
-	if err := forge.AtomicDeleteTestReport(artifactStorePath, reportID); err != nil {
+	if err := forge.AtomicDeleteTestReport(artifactStorePath, report.ID); err != nil {
 		// Report couldn't be deleted from store
-		result := DeleteResult{
-			ID:               reportID,
+		return DeleteResult{
+			ID:               report.ID,
 			Success:          false,
 			DeletedFiles:     deletedFiles,
 			FailedFiles:      failedFiles,
 			ErrorMessage:     fmt.Sprintf("failed to delete report from artifact store: %v", err),
 			PartiallyDeleted: len(deletedFiles) > 0,
 		}
-		outputResult(result)
-		return fmt.Errorf("failed to delete test report: %w", err)
 	}
 
 	// Success
 	result := DeleteResult{
-		ID:               reportID,
+		ID:               report.ID,
 		Success:          true,
 		DeletedFiles:     deletedFiles,
 		FailedFiles:      failedFiles,
@@ -104,8 +203,7 @@ func cmdDelete(reportID string) error {
 		result.ErrorMessage = "some files could not be deleted"
 	}
 
-	outputResult(result)
-	return nil
+	return result
 }
 
 // outputResult outputs the delete result as JSON.
@@ -0,0 +1,148 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+func TestSelectReportsForDeletion(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	reports := []*forge.TestReport{
+		{ID: "unit-new", Stage: "unit", StartTime: now.Add(-1 * time.Hour)},
+		{ID: "unit-old", Stage: "unit", StartTime: now.Add(-10 * 24 * time.Hour)},
+		{ID: "unit-older", Stage: "unit", StartTime: now.Add(-20 * 24 * time.Hour)},
+		{ID: "e2e-old", Stage: "e2e", StartTime: now.Add(-8 * 24 * time.Hour)},
+		{ID: "e2e-new", Stage: "e2e", StartTime: now.Add(-2 * time.Hour)},
+		nil,
+	}
+
+	tests := []struct {
+		name      string
+		stage     string
+		olderThan time.Duration
+		expected  []string
+	}{
+		{
+			name:     "stage only",
+			stage:    "unit",
+			expected: []string{"unit-older", "unit-old", "unit-new"},
+		},
+		{
+			name:      "age only",
+			olderThan: 7 * 24 * time.Hour,
+			expected:  []string{"unit-older", "unit-old", "e2e-old"},
+		},
+		{
+			name:      "stage and age",
+			stage:     "e2e",
+			olderThan: 7 * 24 * time.Hour,
+			expected:  []string{"e2e-old"},
+		},
+		{
+			name:     "no match",
+			stage:    "lint",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := selectReportsForDeletion(reports, tt.stage, tt.olderThan, now)
+
+			var ids []string
+			for _, report := range selected {
+				ids = append(ids, report.ID)
+			}
+
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, ids)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCmdBulkDelete_RequiresFilter(t *testing.T) {
+	if err := cmdBulkDelete("", 0, false); err == nil {
+		t.Error("Expected error when no filter is given, got nil")
+	}
+}
+
+func TestCmdBulkDelete(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+
+	oldFile := filepath.Join(dir, "old-junit.xml")
+	newFile := filepath.Join(dir, "new-junit.xml")
+	for _, f := range []string{oldFile, newFile} {
+		if err := os.WriteFile(f, []byte("<testsuites/>"), 0o644); err != nil {
+			t.Fatalf("Failed to write artifact file: %v", err)
+		}
+	}
+
+	writeSyntheticStore(t,
+		&forge.TestReport{ID: "unit-old", Stage: "unit", StartTime: now.Add(-10 * 24 * time.Hour), ArtifactFiles: []string{oldFile}},
+		&forge.TestReport{ID: "unit-new", Stage: "unit", StartTime: now.Add(-1 * time.Hour), ArtifactFiles: []string{newFile}},
+	)
+
+	// Dry-run keeps everything
+	if err := cmdBulkDelete("unit", 7*24*time.Hour, true); err != nil {
+		t.Fatalf("cmdBulkDelete(dry-run) error = %v", err)
+	}
+	store, err := readArtifactStore()
+	if err != nil {
+		t.Fatalf("readArtifactStore() error = %v", err)
+	}
+	if len(store.TestReports) != 2 {
+		t.Fatalf("Expected 2 reports after dry-run, got %d", len(store.TestReports))
+	}
+	if _, err := os.Stat(oldFile); err != nil {
+		t.Fatalf("Expected artifact file to survive dry-run: %v", err)
+	}
+
+	// Real run deletes only the old report and its files
+	if err := cmdBulkDelete("unit", 7*24*time.Hour, false); err != nil {
+		t.Fatalf("cmdBulkDelete() error = %v", err)
+	}
+	store, err = readArtifactStore()
+	if err != nil {
+		t.Fatalf("readArtifactStore() error = %v", err)
+	}
+	if _, ok := store.TestReports["unit-old"]; ok {
+		t.Error("Expected unit-old to be deleted from the store")
+	}
+	if _, ok := store.TestReports["unit-new"]; !ok {
+		t.Error("Expected unit-new to remain in the store")
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be deleted, stat err = %v", oldFile, err)
+	}
+	if _, err := os.Stat(newFile); err != nil {
+		t.Errorf("Expected %s to remain: %v", newFile, err)
+	}
+}
@@ -16,31 +16,29 @@ package main
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 )
 
-// cmdGet retrieves and displays details about a specific test report.
-func cmdGet(reportID string) error {
-	// Get artifact store path from environment variable
-	artifactStorePath := os.Getenv("FORGE_ARTIFACT_STORE_PATH")
-	if artifactStorePath == "" {
-		config, err := forge.ReadSpec()
-		if err != nil {
-			return fmt.Errorf("failed to read forge.yaml: %w", err)
-		}
-		artifactStorePath, err = forge.GetArtifactStorePath(config.ArtifactStorePath)
-		if err != nil {
-			return fmt.Errorf("failed to get artifact store path: %w", err)
-		}
-	}
+// Output formats supported by the get command.
+const (
+	outputFormatJSON  = "json"
+	outputFormatJUnit = "junit"
+	outputFormatText  = "text"
+)
 
-	// Read artifact store
-	store, err := forge.ReadArtifactStore(artifactStorePath)
+// cmdGet retrieves and displays details about a specific test report
+// in the given output format (JSON by default).
+func cmdGet(reportID, outputFormat string) error {
+	store, err := readArtifactStore()
 	if err != nil {
-		return fmt.Errorf("failed to read artifact store: %w", err)
+		return err
 	}
 
 	// Get test report
@@ -49,12 +47,175 @@ func cmdGet(reportID string) error {
 		return fmt.Errorf("failed to get test report: %w", err)
 	}
 
-	// Output JSON
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(report); err != nil {
-		return fmt.Errorf("failed to encode test report: %w", err)
+	return writeReport(os.Stdout, report, outputFormat)
+}
+
+// writeReport writes report to w in the given output format.
+// An empty format selects JSON.
+func writeReport(w io.Writer, report *forge.TestReport, outputFormat string) error {
+	switch outputFormat {
+	case outputFormatText:
+		return writeReportHuman(w, report)
+	case "", outputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode test report: %w", err)
+		}
+		return nil
+	case outputFormatJUnit:
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "  ")
+		if err := encoder.Encode(toJUnit(report)); err != nil {
+			return fmt.Errorf("failed to encode test report as JUnit XML: %w", err)
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q (supported: json, junit, text)", outputFormat)
+	}
+}
+
+// writeReportHuman writes report to w as aligned key/value lines.
+func writeReportHuman(w io.Writer, report *forge.TestReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "ID:\t%s\n", report.ID)
+	_, _ = fmt.Fprintf(tw, "Stage:\t%s\n", report.Stage)
+	_, _ = fmt.Fprintf(tw, "Status:\t%s\n", report.Status)
+	_, _ = fmt.Fprintf(tw, "Start time:\t%s\n", report.StartTime.Format("2006-01-02T15:04:05Z07:00"))
+	_, _ = fmt.Fprintf(tw, "Duration:\t%.2fs\n", report.Duration)
+	_, _ = fmt.Fprintf(tw, "Tests:\t%d total, %d passed, %d failed, %d skipped\n",
+		report.TestStats.Total, report.TestStats.Passed, report.TestStats.Failed, report.TestStats.Skipped)
+	if report.Coverage.Enabled {
+		_, _ = fmt.Fprintf(tw, "Coverage:\t%.1f%%\n", report.Coverage.Percentage)
+	} else {
+		_, _ = fmt.Fprintf(tw, "Coverage:\tdisabled\n")
+	}
+	if report.OutputPath != "" {
+		_, _ = fmt.Fprintf(tw, "Output path:\t%s\n", report.OutputPath)
+	}
+	for i, file := range report.ArtifactFiles {
+		label := ""
+		if i == 0 {
+			label = "Artifact files:"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", label, file)
+	}
+	if report.ErrorMessage != "" {
+		_, _ = fmt.Fprintf(tw, "Error:\t%s\n", report.ErrorMessage)
+	}
+	return tw.Flush()
+}
+
+// JUnit XML structures for rendering a test report.
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitSkipped struct{}
+
+// toJUnit maps a test report to a single JUnit test suite.
+//
+// Reports only store aggregate statistics, so one test case is emitted per
+// counted test: passed cases are empty, failed cases carry a failure node with
+// the report error message, and skipped cases carry a skipped node.
+// A failed report without failed tests (e.g. a runner error) gets a single
+// failing test case so that CI systems do not treat it as green.
+// Coverage is exposed as suite properties.
+func toJUnit(report *forge.TestReport) junitTestSuites {
+	suite := junitTestSuite{
+		Name: report.Stage,
+		Time: strconv.FormatFloat(report.Duration, 'f', 3, 64),
+		Properties: []junitProperty{
+			{Name: "id", Value: report.ID},
+			{Name: "status", Value: report.Status},
+			{Name: "coverage.enabled", Value: strconv.FormatBool(report.Coverage.Enabled)},
+			{Name: "coverage.percentage", Value: strconv.FormatFloat(report.Coverage.Percentage, 'f', 2, 64)},
+		},
+	}
+	if !report.StartTime.IsZero() {
+		suite.Timestamp = report.StartTime.UTC().Format("2006-01-02T15:04:05")
+	}
+	if report.Coverage.FilePath != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "coverage.filePath", Value: report.Coverage.FilePath})
+	}
+
+	failureMessage := report.ErrorMessage
+	if failureMessage == "" {
+		failureMessage = "test failed"
+	}
+
+	addCases := func(kind string, count int, mutate func(*junitTestCase)) {
+		for i := 1; i <= count; i++ {
+			tc := junitTestCase{
+				Name:      fmt.Sprintf("%s-%d", kind, i),
+				ClassName: report.Stage,
+			}
+			if mutate != nil {
+				mutate(&tc)
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+	}
+
+	stats := report.TestStats
+	addCases("passed", stats.Passed, nil)
+	addCases("failed", stats.Failed, func(tc *junitTestCase) {
+		tc.Failure = &junitFailure{Message: "test failed", Content: failureMessage}
+	})
+	addCases("skipped", stats.Skipped, func(tc *junitTestCase) {
+		tc.Skipped = &junitSkipped{}
+	})
+
+	if report.Status == "failed" && stats.Failed == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      report.Stage,
+			ClassName: report.Stage,
+			Failure:   &junitFailure{Message: "test run failed", Content: failureMessage},
+		})
+	}
+
+	for _, tc := range suite.TestCases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
 	}
 
-	return nil
+	return junitTestSuites{TestSuites: []junitTestSuite{suite}}
 }
@@ -0,0 +1,179 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+func newSampleReport(status string, stats forge.TestStats) *forge.TestReport {
+	return &forge.TestReport{
+		ID:        "test-unit-unit-20250106-abc123",
+		Stage:     "unit",
+		Status:    status,
+		StartTime: time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC),
+		Duration:  5.432,
+		TestStats: stats,
+		Coverage: forge.Coverage{
+			Enabled:    true,
+			Percentage: 85.3,
+			FilePath:   "coverage.out",
+		},
+		ArtifactFiles: []string{"junit.xml", "coverage.out"},
+		CreatedAt:     time.Date(2025, 1, 6, 10, 0, 6, 0, time.UTC),
+		UpdatedAt:     time.Date(2025, 1, 6, 10, 0, 6, 0, time.UTC),
+	}
+}
+
+func TestWriteReport_JSONRoundTrip(t *testing.T) {
+	report := newSampleReport("passed", forge.TestStats{Total: 42, Passed: 42})
+
+	// JSON is the default output format
+	for _, format := range []string{"", outputFormatJSON} {
+		t.Run("format="+format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeReport(&buf, report, format); err != nil {
+				t.Fatalf("writeReport() error = %v", err)
+			}
+
+			var decoded forge.TestReport
+			if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+				t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+			}
+
+			if !reflect.DeepEqual(&decoded, report) {
+				t.Errorf("JSON round-trip mismatch\ngot:      %+v\nexpected: %+v", decoded, *report)
+			}
+		})
+	}
+}
+
+func TestWriteReport_JUnit(t *testing.T) {
+	tests := []struct {
+		name             string
+		report           *forge.TestReport
+		expectedTests    int
+		expectedFailures int
+		expectedSkipped  int
+	}{
+		{
+			name:          "passed report",
+			report:        newSampleReport("passed", forge.TestStats{Total: 3, Passed: 3}),
+			expectedTests: 3,
+		},
+		{
+			name:             "failed report with failed and skipped tests",
+			report:           newSampleReport("failed", forge.TestStats{Total: 10, Passed: 6, Failed: 3, Skipped: 1}),
+			expectedTests:    10,
+			expectedFailures: 3,
+			expectedSkipped:  1,
+		},
+		{
+			name:             "failed report without test stats",
+			report:           newSampleReport("failed", forge.TestStats{}),
+			expectedTests:    1,
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.report.ErrorMessage = "boom"
+
+			var buf bytes.Buffer
+			if err := writeReport(&buf, tt.report, outputFormatJUnit); err != nil {
+				t.Fatalf("writeReport() error = %v", err)
+			}
+
+			var suites junitTestSuites
+			if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+				t.Fatalf("Output is not valid XML: %v\n%s", err, buf.String())
+			}
+
+			if len(suites.TestSuites) != 1 {
+				t.Fatalf("Expected 1 test suite, got %d", len(suites.TestSuites))
+			}
+			suite := suites.TestSuites[0]
+
+			if suite.Name != "unit" {
+				t.Errorf("Expected suite name 'unit', got %q", suite.Name)
+			}
+			if suite.Tests != tt.expectedTests || suite.Failures != tt.expectedFailures || suite.Skipped != tt.expectedSkipped {
+				t.Errorf("Unexpected suite counts: tests=%d failures=%d skipped=%d", suite.Tests, suite.Failures, suite.Skipped)
+			}
+			if len(suite.TestCases) != tt.expectedTests {
+				t.Errorf("Expected %d test cases, got %d", tt.expectedTests, len(suite.TestCases))
+			}
+
+			failures, skipped := 0, 0
+			for _, tc := range suite.TestCases {
+				if tc.Failure != nil {
+					failures++
+					if tc.Failure.Content != "boom" {
+						t.Errorf("Expected failure content 'boom', got %q", tc.Failure.Content)
+					}
+				}
+				if tc.Skipped != nil {
+					skipped++
+				}
+			}
+			if failures != tt.expectedFailures || skipped != tt.expectedSkipped {
+				t.Errorf("Unexpected test case nodes: failures=%d skipped=%d", failures, skipped)
+			}
+
+			properties := make(map[string]string)
+			for _, p := range suite.Properties {
+				properties[p.Name] = p.Value
+			}
+			if properties["coverage.percentage"] != "85.30" || properties["coverage.enabled"] != "true" {
+				t.Errorf("Unexpected coverage properties: %v", properties)
+			}
+		})
+	}
+}
+
+func TestWriteReport_Human(t *testing.T) {
+	report := newSampleReport("failed", forge.TestStats{Total: 10, Passed: 9, Failed: 1})
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, report, outputFormatText); err != nil {
+		t.Fatalf("writeReport() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"test-unit-unit-20250106-abc123", "failed", "10 total, 9 passed, 1 failed, 0 skipped", "85.3%", "junit.xml"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestWriteReport_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeReport(&buf, newSampleReport("passed", forge.TestStats{}), "yaml")
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Errorf("Expected unsupported output format error, got %v", err)
+	}
+}
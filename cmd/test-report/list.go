@@ -25,23 +25,9 @@ import (
 
 // cmdList lists all test reports, optionally filtered by stage.
 func cmdList(stageFilter string) error {
-	// Get artifact store path from environment variable
-	artifactStorePath := os.Getenv("FORGE_ARTIFACT_STORE_PATH")
-	if artifactStorePath == "" {
-		config, err := forge.ReadSpec()
-		if err != nil {
-			return fmt.Errorf("failed to read forge.yaml: %w", err)
-		}
-		artifactStorePath, err = forge.GetArtifactStorePath(config.ArtifactStorePath)
-		if err != nil {
-			return fmt.Errorf("failed to get artifact store path: %w", err)
-		}
-	}
-
-	// Read artifact store
-	store, err := forge.ReadArtifactStore(artifactStorePath)
+	store, err := readArtifactStore()
 	if err != nil {
-		return fmt.Errorf("failed to read artifact store: %w", err)
+		return err
 	}
 
 	// Get test reports (optionally filtered by stage)
@@ -15,9 +15,12 @@
 package main
 
 import (
+	"embed"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/alexandremahdhaoui/forge/internal/cmdutil"
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 )
@@ -31,12 +34,18 @@ var (
 	BuildTimestamp = "unknown"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for the docs subcommand.
 var docsConfig = &enginedocs.Config{
 	EngineName:   Name,
 	LocalDir:     "cmd/test-report/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 func main() {
@@ -50,29 +59,47 @@ func main() {
 				fmt.Fprintf(os.Stderr, "Error: test report ID required\n")
 				os.Exit(1)
 			}
-			if err := cmdGet(os.Args[2]); err != nil {
+			outputFormat := flagValue(os.Args[3:], "-o", "--output")
+			if err := cmdGet(os.Args[2], outputFormat); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
 		case "list":
-			stageFilter := ""
-			// Parse --stage flag if present
-			for i, arg := range os.Args {
-				if arg == "--stage" && i+1 < len(os.Args) {
-					stageFilter = os.Args[i+1]
-					break
-				}
-			}
+			stageFilter := flagValue(os.Args[2:], "--stage")
 			if err := cmdList(stageFilter); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
+		case "stats":
+			since, err := cmdutil.ParseDuration(flagValue(os.Args[2:], "--since"))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := cmdStats(flagValue(os.Args[2:], "--stage"), since); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		case "delete":
 			if len(os.Args) < 3 {
-				fmt.Fprintf(os.Stderr, "Error: test report ID required\n")
+				fmt.Fprintf(os.Stderr, "Error: test report ID or --stage/--older-than filter required\n")
+				os.Exit(1)
+			}
+			// A leading flag selects bulk deletion by filter instead of a single ID
+			if !strings.HasPrefix(os.Args[2], "-") {
+				if err := cmdDelete(os.Args[2]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				break
+			}
+			olderThan, err := cmdutil.ParseDuration(flagValue(os.Args[2:], "--older-than"))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-			if err := cmdDelete(os.Args[2]); err != nil {
+			dryRun := hasFlag(os.Args[2:], "--dry-run")
+			if err := cmdBulkDelete(flagValue(os.Args[2:], "--stage"), olderThan, dryRun); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -99,9 +126,14 @@ func printUsage() {
 	fmt.Print(`test-report - Manage test reports and artifacts
 
 Usage:
-  test-report get <REPORT-ID>          Get test report details
+  test-report get <REPORT-ID> [-o json|junit|text]
+                                       Get test report details
   test-report list [--stage=<NAME>]    List test reports
+  test-report stats [--stage=<NAME>] [--since=<DURATION>]
+                                       Show aggregated pass/fail statistics
   test-report delete <REPORT-ID>       Delete a test report and its artifacts
+  test-report delete [--stage=<NAME>] [--older-than=<DURATION>] [--dry-run]
+                                       Delete all matching test reports and their artifacts
   test-report --mcp                    Run as MCP server
   test-report version                  Show version information
 
@@ -117,10 +149,49 @@ Examples:
   # List unit test reports only
   test-report list --stage=unit
 
-  # Get details about a specific test report
+  # Show pass rate and flakiness of unit tests over the last week
+  test-report stats --stage=unit --since=7d
+
+  # Get details about a specific test report as JSON
   test-report get test-unit-unit-20251105-012345
 
+  # Print a human-readable summary of a test report
+  test-report get test-unit-unit-20251105-012345 -o text
+
+  # Export a test report as JUnit XML for CI ingestion
+  test-report get test-unit-unit-20251105-012345 -o junit > report.xml
+
   # Delete a test report and its artifacts
   test-report delete test-unit-unit-20251105-012345
+
+  # Preview which unit test reports older than a week would be deleted
+  test-report delete --stage=unit --older-than=7d --dry-run
 `)
 }
+
+// flagValue returns the value of the first flag in args matching one of names
+// (e.g. "--stage" or "-o"). Both "--name=value" and "--name value" forms are
+// accepted. Returns an empty string if no flag is present.
+func flagValue(args []string, names ...string) string {
+	for i, arg := range args {
+		for _, name := range names {
+			if value, ok := strings.CutPrefix(arg, name+"="); ok {
+				return value
+			}
+			if arg == name && i+1 < len(args) {
+				return args[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// hasFlag reports whether the boolean flag name (e.g. "--dry-run") is present in args.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name || arg == name+"=true" {
+			return true
+		}
+	}
+	return false
+}
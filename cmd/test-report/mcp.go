@@ -20,6 +20,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
@@ -86,7 +87,7 @@ func runMCPServer() error {
 	}
 
 	// Run the MCP server
-	return server.RunDefault()
+	return server.Run(enginecli.Context())
 }
 
 // handleCreateTool handles the "create" tool call from MCP clients.
@@ -0,0 +1,122 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// StageStats contains aggregated statistics for the test reports of a single stage.
+type StageStats struct {
+	Stage       string  `json:"stage"`
+	Runs        int     `json:"runs"`
+	Passed      int     `json:"passed"`
+	Failed      int     `json:"failed"`
+	PassRate    float64 `json:"passRate"`
+	AvgDuration float64 `json:"avgDuration"`
+	// Flaky is true when the stage both passed and failed within the window.
+	Flaky bool `json:"flaky"`
+}
+
+// cmdStats aggregates stored test reports, optionally filtered by stage and
+// restricted to reports started within the given window (0 means no limit).
+func cmdStats(stageFilter string, since time.Duration) error {
+	store, err := readArtifactStore()
+	if err != nil {
+		return err
+	}
+
+	reports := forge.ListTestReports(&store, stageFilter)
+	stats := computeStats(reports, since, time.Now())
+	printStats(os.Stdout, stats)
+
+	return nil
+}
+
+// computeStats groups reports by stage and computes per-stage statistics.
+// Reports started before now-since are ignored when since is positive.
+// The result is sorted by stage name.
+func computeStats(reports []*forge.TestReport, since time.Duration, now time.Time) []StageStats {
+	var cutoff time.Time
+	if since > 0 {
+		cutoff = now.Add(-since)
+	}
+
+	byStage := make(map[string]*StageStats)
+	totalDuration := make(map[string]float64)
+
+	for _, report := range reports {
+		if report == nil || report.StartTime.Before(cutoff) {
+			continue
+		}
+
+		s, ok := byStage[report.Stage]
+		if !ok {
+			s = &StageStats{Stage: report.Stage}
+			byStage[report.Stage] = s
+		}
+
+		s.Runs++
+		switch report.Status {
+		case "passed":
+			s.Passed++
+		case "failed":
+			s.Failed++
+		}
+		totalDuration[report.Stage] += report.Duration
+	}
+
+	stats := make([]StageStats, 0, len(byStage))
+	for stage, s := range byStage {
+		s.PassRate = float64(s.Passed) / float64(s.Runs) * 100
+		s.AvgDuration = totalDuration[stage] / float64(s.Runs)
+		s.Flaky = s.Passed > 0 && s.Failed > 0
+		stats = append(stats, *s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Stage < stats[j].Stage
+	})
+
+	return stats
+}
+
+// printStats writes stats as a table to w.
+// A friendly message is printed instead when there is no data.
+func printStats(w io.Writer, stats []StageStats) {
+	if len(stats) == 0 {
+		_, _ = fmt.Fprintln(w, "No test reports found in the selected range (no data)")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STAGE\tRUNS\tPASSED\tFAILED\tPASS RATE\tAVG DURATION\tFLAKY")
+	for _, s := range stats {
+		flaky := "no"
+		if s.Flaky {
+			flaky = "yes"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f%%\t%.2fs\t%s\n",
+			s.Stage, s.Runs, s.Passed, s.Failed, s.PassRate, s.AvgDuration, flaky)
+	}
+	_ = tw.Flush()
+}
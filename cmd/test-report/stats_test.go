@@ -0,0 +1,143 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// writeSyntheticStore writes a store containing reports to a temp file and
+// points FORGE_ARTIFACT_STORE_PATH at it.
+func writeSyntheticStore(t *testing.T, reports ...*forge.TestReport) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "artifact-store.yaml")
+	store := forge.ArtifactStore{
+		Version:     "1.0",
+		TestReports: make(map[string]*forge.TestReport),
+	}
+	for _, report := range reports {
+		store.TestReports[report.ID] = report
+	}
+
+	if err := forge.WriteArtifactStore(path, store); err != nil {
+		t.Fatalf("Failed to write artifact store: %v", err)
+	}
+	t.Setenv("FORGE_ARTIFACT_STORE_PATH", path)
+}
+
+func TestComputeStats(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	writeSyntheticStore(t,
+		&forge.TestReport{ID: "unit-1", Stage: "unit", Status: "passed", Duration: 2, StartTime: now.Add(-1 * time.Hour)},
+		&forge.TestReport{ID: "unit-2", Stage: "unit", Status: "failed", Duration: 4, StartTime: now.Add(-2 * time.Hour)},
+		&forge.TestReport{ID: "unit-3", Stage: "unit", Status: "passed", Duration: 6, StartTime: now.Add(-3 * time.Hour)},
+		&forge.TestReport{ID: "lint-1", Stage: "lint", Status: "passed", Duration: 10, StartTime: now.Add(-1 * time.Hour)},
+		&forge.TestReport{ID: "e2e-old", Stage: "e2e", Status: "failed", Duration: 60, StartTime: now.Add(-72 * time.Hour)},
+	)
+
+	store, err := readArtifactStore()
+	if err != nil {
+		t.Fatalf("readArtifactStore() error = %v", err)
+	}
+
+	t.Run("all stages within window", func(t *testing.T) {
+		stats := computeStats(forge.ListTestReports(&store, ""), 24*time.Hour, now)
+
+		if len(stats) != 2 {
+			t.Fatalf("Expected 2 stages, got %d: %+v", len(stats), stats)
+		}
+
+		lint, unit := stats[0], stats[1]
+		if lint.Stage != "lint" || unit.Stage != "unit" {
+			t.Fatalf("Expected stages sorted as [lint unit], got [%s %s]", lint.Stage, unit.Stage)
+		}
+
+		if unit.Runs != 3 || unit.Passed != 2 || unit.Failed != 1 {
+			t.Errorf("Unexpected unit counts: %+v", unit)
+		}
+		if unit.AvgDuration != 4 {
+			t.Errorf("Expected unit average duration 4, got %v", unit.AvgDuration)
+		}
+		if !unit.Flaky {
+			t.Error("Expected unit stage to be flaky")
+		}
+
+		if lint.PassRate != 100 || lint.Flaky {
+			t.Errorf("Unexpected lint stats: %+v", lint)
+		}
+	})
+
+	t.Run("no window includes old reports", func(t *testing.T) {
+		stats := computeStats(forge.ListTestReports(&store, "e2e"), 0, now)
+
+		if len(stats) != 1 || stats[0].Stage != "e2e" || stats[0].PassRate != 0 {
+			t.Errorf("Unexpected e2e stats: %+v", stats)
+		}
+	})
+
+	t.Run("no reports in range", func(t *testing.T) {
+		stats := computeStats(forge.ListTestReports(&store, "e2e"), 24*time.Hour, now)
+		if len(stats) != 0 {
+			t.Fatalf("Expected no stats, got %+v", stats)
+		}
+
+		var buf bytes.Buffer
+		printStats(&buf, stats)
+		if !strings.Contains(buf.String(), "no data") {
+			t.Errorf("Expected friendly no data message, got %q", buf.String())
+		}
+	})
+}
+
+func TestPrintStats(t *testing.T) {
+	var buf bytes.Buffer
+	printStats(&buf, []StageStats{
+		{Stage: "unit", Runs: 4, Passed: 3, Failed: 1, PassRate: 75, AvgDuration: 1.5, Flaky: true},
+	})
+
+	output := buf.String()
+	for _, want := range []string{"STAGE", "PASS RATE", "unit", "75.0%", "1.50s", "yes"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	args := []string{"--stage=unit", "--since", "24h", "-o", "json"}
+
+	if got := flagValue(args, "--stage"); got != "unit" {
+		t.Errorf("Expected stage 'unit', got %q", got)
+	}
+	if got := flagValue(args, "--since"); got != "24h" {
+		t.Errorf("Expected since '24h', got %q", got)
+	}
+	if got := flagValue(args, "-o", "--output"); got != "json" {
+		t.Errorf("Expected output 'json', got %q", got)
+	}
+	if got := flagValue(args, "--missing"); got != "" {
+		t.Errorf("Expected empty value for missing flag, got %q", got)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// resolveArtifactStorePath returns the artifact store path.
+// The FORGE_ARTIFACT_STORE_PATH environment variable takes precedence over forge.yaml.
+func resolveArtifactStorePath() (string, error) {
+	artifactStorePath := os.Getenv("FORGE_ARTIFACT_STORE_PATH")
+	if artifactStorePath != "" {
+		return artifactStorePath, nil
+	}
+
+	config, err := forge.ReadSpec()
+	if err != nil {
+		return "", fmt.Errorf("failed to read forge.yaml: %w", err)
+	}
+	artifactStorePath, err = forge.GetArtifactStorePath(config.ArtifactStorePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get artifact store path: %w", err)
+	}
+
+	return artifactStorePath, nil
+}
+
+// readArtifactStore resolves the artifact store path and reads the store.
+func readArtifactStore() (forge.ArtifactStore, error) {
+	artifactStorePath, err := resolveArtifactStorePath()
+	if err != nil {
+		return forge.ArtifactStore{}, err
+	}
+
+	store, err := forge.ReadArtifactStore(artifactStorePath)
+	if err != nil {
+		return forge.ArtifactStore{}, fmt.Errorf("failed to read artifact store: %w", err)
+	}
+
+	return store, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// azureBlobAPIVersion is the Blob service REST API version sent in x-ms-version.
+const azureBlobAPIVersion = "2021-08-06"
+
+// AzureBlobClient downloads blobs from Azure Blob Storage (or Azurite) using the REST API.
+// Requests are authorized with a Shared Key, a SAS token, or sent anonymously for public containers.
+type AzureBlobClient struct {
+	endpoint    string
+	accountName string
+	accountKey  []byte
+	sasToken    string
+	httpClient  *http.Client
+	now         func() time.Time
+}
+
+// NewAzureBlobClient creates an anonymous Azure Blob client for public containers.
+// The endpoint should be the blob service URL (e.g., "https://myaccount.blob.core.windows.net",
+// or "http://127.0.0.1:10000/devstoreaccount1" for Azurite).
+func NewAzureBlobClient(endpoint string) (*AzureBlobClient, error) {
+	if err := validateAzureBlobEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return &AzureBlobClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}, nil
+}
+
+// NewAzureBlobClientWithSharedKey creates an Azure Blob client authorized with the storage account key.
+// The account key is the base64-encoded key shown in the Azure portal.
+func NewAzureBlobClientWithSharedKey(endpoint, accountName, accountKey string) (*AzureBlobClient, error) {
+	client, err := NewAzureBlobClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("accountKey must be base64-encoded: %w", err)
+	}
+
+	client.accountName = accountName
+	client.accountKey = key
+	return client, nil
+}
+
+// NewAzureBlobClientWithSASToken creates an Azure Blob client authorized with a SAS token.
+// A leading "?" in the token is ignored.
+func NewAzureBlobClientWithSASToken(endpoint, sasToken string) (*AzureBlobClient, error) {
+	client, err := NewAzureBlobClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client.sasToken = strings.TrimPrefix(sasToken, "?")
+	return client, nil
+}
+
+// DownloadFile downloads a blob from a container to a local file.
+// Returns an error if the download fails.
+func (c *AzureBlobClient) DownloadFile(container, blob, destPath string) error {
+	// Validate inputs
+	if container == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if blob == "" {
+		return fmt.Errorf("blob name is required")
+	}
+	if destPath == "" {
+		return fmt.Errorf("destination path is required")
+	}
+
+	// Create context with timeout (5 minutes for download)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.blobURL(container, blob), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	req.Header.Set("x-ms-date", c.now().UTC().Format(http.TimeFormat))
+
+	if c.accountKey != nil {
+		req.Header.Set("Authorization", signAzureSharedKey(req, c.accountName, c.accountKey))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("azure blob download timed out after 5 minutes")
+		}
+		return fmt.Errorf("failed to get blob: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Warning: failed to close Azure Blob response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to get blob: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return writeObjectToFile(resp.Body, destPath)
+}
+
+// blobURL returns the URL of a blob, escaping each path segment of the blob name.
+func (c *AzureBlobClient) blobURL(container, blob string) string {
+	segments := strings.Split(blob, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	u := c.endpoint + "/" + url.PathEscape(container) + "/" + strings.Join(segments, "/")
+	if c.sasToken != "" {
+		u += "?" + c.sasToken
+	}
+	return u
+}
+
+// signAzureSharedKey returns the Authorization header value for a Shared Key signed request.
+func signAzureSharedKey(req *http.Request, accountName string, accountKey []byte) string {
+	mac := hmac.New(sha256.New, accountKey)
+	mac.Write([]byte(buildAzureSharedKeyStringToSign(req, accountName)))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return fmt.Sprintf("SharedKey %s:%s", accountName, signature)
+}
+
+// buildAzureSharedKeyStringToSign builds the Shared Key string-to-sign for the Blob service.
+// See https://learn.microsoft.com/rest/api/storageservices/authorize-with-shared-key.
+func buildAzureSharedKeyStringToSign(req *http.Request, accountName string) string {
+	contentLength := req.Header.Get("Content-Length")
+	if contentLength == "0" {
+		contentLength = ""
+	}
+
+	standardHeaders := []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}
+
+	// Canonicalized headers: lowercase x-ms-* headers sorted by name
+	var msHeaders []string
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+
+	var b strings.Builder
+	b.WriteString(strings.Join(standardHeaders, "\n"))
+	b.WriteString("\n")
+	for _, name := range msHeaders {
+		b.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	// Canonicalized resource: /account/path followed by sorted query parameters
+	b.WriteString("/" + accountName + req.URL.EscapedPath())
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		b.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(values, ","))
+	}
+
+	return b.String()
+}
+
+// validateAzureBlobEndpoint validates that the endpoint is a valid HTTP/HTTPS URL.
+func validateAzureBlobEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return fmt.Errorf("endpoint is required")
+	}
+
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return fmt.Errorf("endpoint must start with http:// or https://")
+	}
+
+	return nil
+}
+
+// writeObjectToFile copies a downloaded object body to destPath.
+func writeObjectToFile(body io.Reader, destPath string) error {
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer func() {
+		if err := destFile.Close(); err != nil {
+			log.Printf("Warning: failed to close destination file: %v", err)
+		}
+	}()
+
+	if _, err := io.Copy(destFile, body); err != nil {
+		return fmt.Errorf("failed to write object to file: %w", err)
+	}
+
+	return nil
+}
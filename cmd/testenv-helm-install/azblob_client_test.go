@@ -0,0 +1,155 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildAzureSharedKeyStringToSign(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://myaccount.blob.core.windows.net/charts/my%20app/chart.tgz?comp=metadata&timeout=30", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	req.Header.Set("x-ms-date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	req.Header.Set("Range", "bytes=0-1023")
+
+	want := strings.Join([]string{
+		"GET",
+		"", "", "", "", "", "", "", "", "", "",
+		"bytes=0-1023",
+		"x-ms-date:Mon, 02 Jan 2006 15:04:05 GMT",
+		"x-ms-version:" + azureBlobAPIVersion,
+		"/myaccount/charts/my%20app/chart.tgz",
+		"comp:metadata",
+		"timeout:30",
+	}, "\n")
+
+	if got := buildAzureSharedKeyStringToSign(req, "myaccount"); got != want {
+		t.Errorf("buildAzureSharedKeyStringToSign() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestAzureBlobClient_DownloadFile(t *testing.T) {
+	accountKey := base64.StdEncoding.EncodeToString([]byte("secret-key"))
+
+	tests := []struct {
+		name      string
+		newClient func(endpoint string) (*AzureBlobClient, error)
+		check     func(t *testing.T, r *http.Request)
+	}{
+		{
+			name:      "anonymous",
+			newClient: NewAzureBlobClient,
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "" {
+					t.Errorf("Authorization = %q, want empty", got)
+				}
+			},
+		},
+		{
+			name: "sas token",
+			newClient: func(endpoint string) (*AzureBlobClient, error) {
+				return NewAzureBlobClientWithSASToken(endpoint, "?sv=2021-08-06&sig=abc")
+			},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.URL.Query().Get("sig"); got != "abc" {
+					t.Errorf("sig query parameter = %q, want %q", got, "abc")
+				}
+			},
+		},
+		{
+			name: "shared key",
+			newClient: func(endpoint string) (*AzureBlobClient, error) {
+				return NewAzureBlobClientWithSharedKey(endpoint, "myaccount", accountKey)
+			},
+			check: func(t *testing.T, r *http.Request) {
+				key, _ := base64.StdEncoding.DecodeString(accountKey)
+				if got, want := r.Header.Get("Authorization"), signAzureSharedKey(r, "myaccount", key); got != want {
+					t.Errorf("Authorization = %q, want %q", got, want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != "/charts/myapp/myapp-1.0.0.tgz" {
+					http.NotFound(w, r)
+					return
+				}
+				if r.Header.Get("x-ms-version") != azureBlobAPIVersion {
+					t.Errorf("x-ms-version = %q, want %q", r.Header.Get("x-ms-version"), azureBlobAPIVersion)
+				}
+				tt.check(t, r)
+				_, _ = w.Write([]byte("chart-content"))
+			}))
+			defer server.Close()
+
+			client, err := tt.newClient(server.URL)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			client.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+			destPath := filepath.Join(t.TempDir(), "myapp-1.0.0.tgz")
+			if err := client.DownloadFile("charts", "myapp/myapp-1.0.0.tgz", destPath); err != nil {
+				t.Fatalf("DownloadFile() unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(destPath)
+			if err != nil {
+				t.Fatalf("failed to read downloaded file: %v", err)
+			}
+			if string(content) != "chart-content" {
+				t.Errorf("downloaded content = %q, want %q", content, "chart-content")
+			}
+		})
+	}
+}
+
+func TestAzureBlobClient_DownloadFile_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "BlobNotFound", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewAzureBlobClient(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	err = client.DownloadFile("charts", "missing.tgz", filepath.Join(t.TempDir(), "missing.tgz"))
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("DownloadFile() error = %v, want error containing 404", err)
+	}
+}
+
+func TestNewAzureBlobClientWithSharedKey_InvalidKey(t *testing.T) {
+	if _, err := NewAzureBlobClientWithSharedKey("https://myaccount.blob.core.windows.net", "myaccount", "not base64!"); err == nil {
+		t.Error("NewAzureBlobClientWithSharedKey() expected error for non-base64 key, got nil")
+	}
+}
@@ -15,19 +15,27 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -58,7 +66,7 @@ type ChartSpec struct {
 	// -------------------------------------------------------------------------
 
 	// SourceType determines the strategy for artifact acquisition.
-	// Valid values: "helm-repo", "git", "oci", "s3", "local".
+	// Valid values: "helm-repo", "git", "oci", "s3", "azblob", "gcs", "local".
 	// Required.
 	SourceType string `json:"sourceType" yaml:"sourceType"`
 
@@ -67,6 +75,8 @@ type ChartSpec struct {
 	// - 'git': HTTP/S or SSH URL of the git repo.
 	// - 'oci': Registry URL starting with 'oci://'.
 	// - 's3': The generic S3-compatible endpoint.
+	// - 'azblob': The Blob service endpoint (e.g., "https://<account>.blob.core.windows.net").
+	// - 'gcs': The GCS JSON API endpoint. Defaults to "https://storage.googleapis.com".
 	URL string `json:"url" yaml:"url"`
 
 	// Path is the filesystem path to a local chart directory.
@@ -98,7 +108,7 @@ type ChartSpec struct {
 	// -------------------------------------------------------------------------
 
 	// ChartPath is the relative file path to the chart directory within the source.
-	// Required when SourceType is "git" or "s3".
+	// Required when SourceType is "git", "s3", "azblob" or "gcs".
 	ChartPath string `json:"chartPath,omitempty" yaml:"chartPath,omitempty"`
 
 	// GitBranch specifies the Git branch to checkout.
@@ -128,6 +138,10 @@ type ChartSpec struct {
 	// Valid values: "cosign", "notation".
 	OCIProvider string `json:"ociProvider,omitempty" yaml:"ociProvider,omitempty"`
 
+	// CosignKey is the public key passed to `cosign verify --key` (file path, URL or KMS URI).
+	// When empty, the "cosign.pub" key of the AuthSecretName Secret is used.
+	CosignKey string `json:"cosignKey,omitempty" yaml:"cosignKey,omitempty"`
+
 	// OCILayerMediaType specifies the media type of the layer to extract.
 	OCILayerMediaType string `json:"ociLayerMediaType,omitempty" yaml:"ociLayerMediaType,omitempty"`
 
@@ -143,6 +157,22 @@ type ChartSpec struct {
 	// Defaults to "us-east-1" for generic S3 providers.
 	S3BucketRegion string `json:"s3BucketRegion,omitempty" yaml:"s3BucketRegion,omitempty"`
 
+	// -------------------------------------------------------------------------
+	// Azure Blob Storage Specifics
+	// -------------------------------------------------------------------------
+
+	// AzureContainerName is the name of the Azure Blob Storage container.
+	// Required when SourceType is "azblob".
+	AzureContainerName string `json:"azureContainerName,omitempty" yaml:"azureContainerName,omitempty"`
+
+	// -------------------------------------------------------------------------
+	// Google Cloud Storage Specifics
+	// -------------------------------------------------------------------------
+
+	// GCSBucketName is the name of the Google Cloud Storage bucket.
+	// Required when SourceType is "gcs".
+	GCSBucketName string `json:"gcsBucketName,omitempty" yaml:"gcsBucketName,omitempty"`
+
 	// -------------------------------------------------------------------------
 	// Authentication & Security
 	// -------------------------------------------------------------------------
@@ -171,6 +201,10 @@ type ChartSpec struct {
 	// ValuesFiles is a list of file paths within the Source artifact to use as values.
 	ValuesFiles []string `json:"valuesFiles,omitempty" yaml:"valuesFiles,omitempty"`
 
+	// ValuesOverlays maps a test stage name (e.g. "integration", "e2e") to values files
+	// appended after ValuesFiles when the environment is created for that stage.
+	ValuesOverlays map[string][]string `json:"valuesOverlays,omitempty" yaml:"valuesOverlays,omitempty"`
+
 	// ValueReferences allows referencing values from existing ConfigMaps or Secrets.
 	ValueReferences []ValueReference `json:"valueReferences,omitempty" yaml:"valueReferences,omitempty"`
 
@@ -189,6 +223,15 @@ type ChartSpec struct {
 	// Recreates resources that cannot be patched.
 	ForceUpgrade bool `json:"forceUpgrade,omitempty" yaml:"forceUpgrade,omitempty"`
 
+	// Upgrade forces 'helm upgrade --install' instead of 'helm install'.
+	// If false (default), upgrade mode is used only when the release already exists,
+	// e.g. in a reused environment.
+	Upgrade bool `json:"upgrade,omitempty" yaml:"upgrade,omitempty"`
+
+	// ResetValues adds '--reset-values' in upgrade mode so that the values of the
+	// existing release are discarded in favor of the chart defaults and composed values.
+	ResetValues bool `json:"resetValues,omitempty" yaml:"resetValues,omitempty"`
+
 	// DisableHooks prevents Helm hooks (pre-install, post-install) from running.
 	DisableHooks bool `json:"disableHooks,omitempty" yaml:"disableHooks,omitempty"`
 
@@ -199,6 +242,25 @@ type ChartSpec struct {
 
 	// TestEnable triggers the execution of Helm tests after a release.
 	TestEnable bool `json:"testEnable,omitempty" yaml:"testEnable,omitempty"`
+
+	// WaitForCRDs lists the names of CRDs (e.g. "certificates.cert-manager.io"), typically
+	// installed by a previous chart, polled with kubectl until they are established before
+	// this chart is installed. Each CRD is awaited up to the chart timeout.
+	WaitForCRDs []string `json:"waitForCRDs,omitempty" yaml:"waitForCRDs,omitempty"`
+
+	// ReadinessChecks are resources polled with kubectl after install until they are ready.
+	// A check that is not ready before its timeout fails the installation.
+	ReadinessChecks []ResourceCheck `json:"readinessChecks,omitempty" yaml:"readinessChecks,omitempty"`
+
+	// DependsOn lists the names of charts, installed before this one, that this chart depends on.
+	// On delete, a chart and its dependencies are uninstalled serially, dependents first,
+	// even when uninstallParallelism is set.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+
+	// Priority orders installation: charts are installed in ascending priority
+	// (e.g. CRDs, then operators, then workloads), in list order within equal priority.
+	// Defaults to 0.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 // ValueReference represents a reference to a ConfigMap or Secret containing values.
@@ -249,10 +311,17 @@ func resolveChartPath(chart ChartSpec, rootDir string) (string, error) {
 }
 
 // Create implements the CreateFunc for installing Helm charts.
-// The spec parameter is available but charts are parsed from input.Spec via parseChartsFromSpec.
-func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*engineframework.TestEnvArtifact, error) {
+// Charts are parsed from input.Spec via parseChartsFromSpec; spec holds top-level options.
+// When spec.RenderOnly is set, charts are rendered with helm template into TmpDir instead of being installed.
+// When spec.ValuesEnvSubst is set, ${VAR} references in values files are substituted from input.Env.
+// When spec.ValidateValuesSchema is set, composed values are validated against the chart's values.schema.json.
+func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec) (*engineframework.TestEnvArtifact, error) {
 	log.Printf("Installing Helm charts: testID=%s, stage=%s", input.TestID, input.Stage)
 
+	renderOnly := spec != nil && spec.RenderOnly
+	valuesEnvSubst := spec != nil && spec.ValuesEnvSubst
+	validateSchema := spec != nil && spec.ValidateValuesSchema
+
 	// Parse charts from spec
 	charts, err := parseChartsFromSpec(input.Spec)
 	if err != nil {
@@ -280,6 +349,18 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 		}, nil
 	}
 
+	// Install in ascending priority; dependencies are checked against this install order
+	sortChartsByPriority(charts)
+
+	if err := validateChartDependencies(charts); err != nil {
+		return nil, err
+	}
+
+	budget, err := newInstallBudget(spec, input.Env, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	// Resolve relative paths for local charts using RootDir
 	// This MUST happen before installChart() since installChart has no access to CreateInput
 	for i := range charts {
@@ -290,6 +371,7 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 			}
 			charts[i].Path = resolvedPath
 		}
+		charts[i].ValuesFiles = stageValuesFiles(charts[i], input.Stage)
 	}
 
 	// Get kubeconfig path from environment (primary source, from testenv-kind)
@@ -302,7 +384,10 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 		// Fallback to legacy behavior (search tmpDir and metadata)
 		var err error
 		kubeconfigPath, err = findKubeconfig(input.TmpDir, input.Metadata)
-		if err != nil {
+		if err != nil && renderOnly {
+			// Rendering does not need a cluster: ValueReferences are skipped without kubeconfig
+			log.Printf("Warning: no kubeconfig found in render-only mode: %v", err)
+		} else if err != nil && !input.DryRun {
 			return nil, fmt.Errorf("failed to find kubeconfig: %w", err)
 		}
 		log.Printf("Using kubeconfig from legacy sources (tmpDir/metadata): %s", kubeconfigPath)
@@ -310,25 +395,33 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 
 	// Install each chart
 	installedCharts := []string{}
+	summary := installSummary{TestID: input.TestID, Charts: []chartInstallSummary{}}
 	metadata := map[string]string{}
+	var plan []string
+
+	// Files produced by the engine (rendered manifests in render-only mode, relative to TmpDir)
+	files := map[string]string{}
+
+	// Prepare managed resources (for cleanup)
+	managedResources := []string{}
+
+	// Substituted values files only need to live until helm has consumed them
+	var valuesCleanups []func()
+	defer func() {
+		for _, cleanup := range valuesCleanups {
+			cleanup()
+		}
+	}()
 
 	for i, chart := range charts {
-		// Validate required fields
-		if chart.SourceType == "" {
-			return nil, fmt.Errorf("chart %s: sourceType is required", chart.Name)
+		if err := validateChartSource(chart); err != nil {
+			return nil, err
 		}
-		if chart.SourceType == "helm-repo" {
-			if chart.URL == "" {
-				return nil, fmt.Errorf("chart %s: url is required for helm-repo source", chart.Name)
-			}
-			if chart.ChartName == "" {
-				return nil, fmt.Errorf("chart %s: chartName is required for helm-repo source", chart.Name)
-			}
+		if err := validateReadinessChecks(chart.ReadinessChecks); err != nil {
+			return nil, fmt.Errorf("chart %s: %w", chart.Name, err)
 		}
-		if chart.SourceType == "local" {
-			if chart.Path == "" {
-				return nil, fmt.Errorf("chart %s: path is required for local source", chart.Name)
-			}
+		if err := validateWaitForCRDs(chart.WaitForCRDs); err != nil {
+			return nil, fmt.Errorf("chart %s: %w", chart.Name, err)
 		}
 
 		releaseName := chart.ReleaseName
@@ -336,6 +429,23 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 			releaseName = chart.Name
 		}
 
+		// Dry-run: record the planned install without contacting the cluster
+		if input.DryRun {
+			action := "install"
+			if renderOnly {
+				action = "render"
+			}
+			plan = append(plan, fmt.Sprintf("%s chart %s (release: %s, namespace: %s, source: %s)",
+				action, chart.Name, releaseName, chart.Namespace, chart.SourceType))
+			continue
+		}
+
+		// Abort the remaining installs once the overall budget is spent
+		if budget.exceeded(time.Now()) {
+			return nil, budget.error(installedCharts, charts[i:])
+		}
+		chart.Timeout = budget.capChartTimeout(chart.Timeout, time.Now())
+
 		log.Printf("Installing chart %d/%d: %s (release: %s)", i+1, len(charts), chart.Name, releaseName)
 
 		// Add helm repo if using helm-repo source type
@@ -347,30 +457,88 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 			}
 		}
 
-		// Install the chart
-		if err := installChart(chart, kubeconfigPath); err != nil {
+		// Substitute ${VAR} references in values files with the testenv environment
+		if valuesEnvSubst && len(chart.ValuesFiles) > 0 {
+			valuesFiles, cleanup, err := substituteValuesFiles(chart.ValuesFiles, input.Env)
+			if err != nil {
+				return nil, fmt.Errorf("chart %s: %w", chart.Name, err)
+			}
+			valuesCleanups = append(valuesCleanups, cleanup)
+			chart.ValuesFiles = valuesFiles
+		}
+
+		// Render the chart instead of installing it
+		if renderOnly {
+			fileName := renderedManifestFileName(releaseName)
+			outputPath := filepath.Join(input.TmpDir, fileName)
+			if err := renderChart(chart, kubeconfigPath, outputPath, validateSchema); err != nil {
+				return nil, fmt.Errorf("failed to render chart %s: %w", chart.Name, err)
+			}
+			files["testenv-helm-install.rendered."+chart.Name] = fileName
+			managedResources = append(managedResources, outputPath)
+			continue
+		}
+
+		// Wait for the CRDs the chart's resources depend on, e.g. installed by a previous chart
+		if len(chart.WaitForCRDs) > 0 {
+			if err := waitForChartCRDs(chart, kubeconfigPath, kubectlGetResource); err != nil {
+				if budget.exceeded(time.Now()) {
+					return nil, fmt.Errorf("%w (chart %s failed: %v)", budget.error(installedCharts, charts[i:]), chart.Name, err)
+				}
+				return nil, err
+			}
+		}
+
+		// Install the chart, timing it for performance triage
+		installStart := time.Now()
+		if err := installChart(chart, kubeconfigPath, validateSchema); err != nil {
+			// Quote the pod logs for diagnosis: helm only reports that the release is not ready.
+			// Failures before helm runs (chart fetch, invalid values) have no pods to look at.
+			if errors.Is(err, errReleaseFailed) {
+				err = collectFailureDiagnostics(chart, releaseName, kubeconfigPath, input.TmpDir, kubectlCombinedOutput).wrap(err)
+			}
+			if budget.exceeded(time.Now()) {
+				return nil, fmt.Errorf("%w (chart %s failed: %v)", budget.error(installedCharts, charts[i:]), chart.Name, err)
+			}
 			return nil, fmt.Errorf("failed to install chart %s: %w", chart.Name, err)
 		}
+		installDuration := time.Since(installStart)
+		log.Printf("Chart %s installed in %s", chart.Name, installDuration.Round(time.Millisecond))
 
 		installedCharts = append(installedCharts, releaseName)
+		summary.Charts = append(summary.Charts, summarizeInstalledChart(chart, releaseName, kubeconfigPath, installDuration))
 
 		// Store chart info in metadata
 		prefix := fmt.Sprintf("testenv-helm-install.chart.%d", i)
 		metadata[prefix+".name"] = chart.Name
 		metadata[prefix+".releaseName"] = releaseName
+		metadata[prefix+".durationSeconds"] = strconv.FormatFloat(installDuration.Seconds(), 'f', 3, 64)
 		if chart.Namespace != "" {
 			metadata[prefix+".namespace"] = chart.Namespace
 		}
+		if len(chart.DependsOn) > 0 {
+			metadata[prefix+".dependsOn"] = strings.Join(chart.DependsOn, ",")
+		}
 	}
 
 	// Store count of installed charts
 	metadata["testenv-helm-install.chartCount"] = fmt.Sprintf("%d", len(installedCharts))
-
-	// Prepare files map (no files produced by helm install)
-	files := map[string]string{}
-
-	// Prepare managed resources (for cleanup)
-	managedResources := []string{}
+	if renderOnly {
+		metadata["testenv-helm-install.renderOnly"] = "true"
+	}
+	// Summarize the installed charts in a single document
+	if !input.DryRun && !renderOnly {
+		summaryPath, err := writeInstallSummary(input.TmpDir, summary)
+		if err != nil {
+			return nil, err
+		}
+		files[installSummaryFileKey] = installSummaryFileName
+		managedResources = append(managedResources, summaryPath)
+	}
+	// Delete does not receive the spec: remember the uninstall parallelism for teardown
+	if spec != nil && spec.UninstallParallelism > 1 {
+		metadata[uninstallParallelismMetadataKey] = strconv.Itoa(spec.UninstallParallelism)
+	}
 
 	// Return artifact
 	return &engineframework.TestEnvArtifact{
@@ -378,11 +546,51 @@ func Create(ctx context.Context, input engineframework.CreateInput, _ *Spec) (*e
 		Files:            files,
 		Metadata:         metadata,
 		ManagedResources: managedResources,
+		Plan:             plan,
 	}, nil
 }
 
+// chartSourceTypes lists the supported values of ChartSpec.SourceType.
+var chartSourceTypes = []string{"helm-repo", "git", "oci", "s3", "azblob", "gcs", "local"}
+
+// validateChartSource checks that the chart declares a supported source type and the
+// fields required by the helm-repo and local sources.
+func validateChartSource(chart ChartSpec) error {
+	rules := []mcputil.SpecRule{
+		{Key: "sourceType", Type: mcputil.SpecTypeString, Required: true, Enum: chartSourceTypes},
+	}
+	switch chart.SourceType {
+	case "helm-repo":
+		rules = append(rules,
+			mcputil.SpecRule{Key: "url", Type: mcputil.SpecTypeString, Required: true},
+			mcputil.SpecRule{Key: "chartName", Type: mcputil.SpecTypeString, Required: true},
+		)
+	case "local":
+		rules = append(rules, mcputil.SpecRule{Key: "path", Type: mcputil.SpecTypeString, Required: true})
+	}
+
+	source := map[string]any{
+		"sourceType": chart.SourceType,
+		"url":        chart.URL,
+		"chartName":  chart.ChartName,
+		"path":       chart.Path,
+	}
+	if violations := mcputil.SpecViolations(source, rules); len(violations) > 0 {
+		return fmt.Errorf("chart %s: invalid spec: %s", chart.Name, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// sortChartsByPriority sorts charts in ascending priority, keeping list order within equal priority.
+func sortChartsByPriority(charts []ChartSpec) {
+	sort.SliceStable(charts, func(i, j int) bool {
+		return charts[i].Priority < charts[j].Priority
+	})
+}
+
 // Delete implements the DeleteFunc for uninstalling Helm charts.
-func Delete(ctx context.Context, input engineframework.DeleteInput, _ *Spec) error {
+// Errors are aggregated: every chart is uninstalled even if some of them fail.
+func Delete(ctx context.Context, input engineframework.DeleteInput, spec *Spec) error {
 	log.Printf("Uninstalling Helm charts: testID=%s", input.TestID)
 
 	// Extract chart count from metadata
@@ -390,6 +598,7 @@ func Delete(ctx context.Context, input engineframework.DeleteInput, _ *Spec) err
 	if !ok {
 		// No charts to uninstall
 		log.Printf("No charts found in metadata, skipping uninstall")
+		engineframework.MarkNothingDeleted(ctx)
 		return nil
 	}
 
@@ -399,6 +608,14 @@ func Delete(ctx context.Context, input engineframework.DeleteInput, _ *Spec) err
 		return nil
 	}
 
+	if input.DryRun {
+		for i := chartCount - 1; i >= 0; i-- {
+			prefix := fmt.Sprintf("testenv-helm-install.chart.%d", i)
+			log.Printf("Dry-run: would uninstall chart %s", input.Metadata[prefix+".releaseName"])
+		}
+		return nil
+	}
+
 	// Find kubeconfig from metadata (use testenv-kind's kubeconfig)
 	kubeconfigPath, ok := input.Metadata["testenv-kind.kubeconfigPath"]
 	if !ok {
@@ -411,27 +628,29 @@ func Delete(ctx context.Context, input engineframework.DeleteInput, _ *Spec) err
 		return fmt.Errorf("kubeconfig file does not exist at %s - cluster was deleted before helm uninstall (cleanup order bug)", kubeconfigPath)
 	}
 
-	// Uninstall each chart in reverse order
-	for i := chartCount - 1; i >= 0; i-- {
-		prefix := fmt.Sprintf("testenv-helm-install.chart.%d", i)
-		releaseName := input.Metadata[prefix+".releaseName"]
-		namespace := input.Metadata[prefix+".namespace"]
+	// Charts sharing a namespace or a dependency are uninstalled serially in reverse install order;
+	// independent groups are uninstalled concurrently, up to the configured parallelism
+	parallelism := uninstallParallelism(spec, input.Metadata)
+	groups := planUninstallGroups(releasesFromMetadata(input.Metadata, chartCount), parallelism)
 
-		if releaseName == "" {
-			log.Printf("Warning: chart %d missing release name, skipping", i)
-			continue
+	// Uninstall the charts (best effort: failures do not stop the teardown of other charts).
+	// Releases that are already gone are not errors.
+	var uninstalled atomic.Int32
+	err := uninstallGroups(groups, parallelism, func(r uninstallRelease) error {
+		err := uninstallChart(r.ReleaseName, r.Namespace, kubeconfigPath)
+		if engineframework.IsNotFound(err) {
+			log.Printf("Chart %s already uninstalled", r.ReleaseName)
+			return nil
 		}
-
-		log.Printf("Uninstalling chart %d/%d: %s", chartCount-i, chartCount, releaseName)
-
-		// Uninstall the chart (best effort)
-		if err := uninstallChart(releaseName, namespace, kubeconfigPath); err != nil {
-			log.Printf("Warning: failed to uninstall chart %s: %v", releaseName, err)
-			// Continue with other charts (best effort cleanup)
+		if err == nil {
+			uninstalled.Add(1)
 		}
+		return err
+	})
+	if err == nil && uninstalled.Load() == 0 {
+		engineframework.MarkNothingDeleted(ctx)
 	}
-
-	return nil
+	return err
 }
 
 // parseChartsFromSpec extracts chart specifications from the spec map
@@ -593,20 +812,6 @@ func resolveSemVerTag(repoPath string, semverConstraint string) (string, error)
 	return matchingTags[latestIndex], nil
 }
 
-// applyIgnorePatterns removes files matching ignore patterns from cloned repo.
-// This is a placeholder for future optimization.
-// Currently logs a warning if patterns are provided but does not apply them.
-// nolint:unused // Placeholder for future feature implementation
-func applyIgnorePatterns(repoPath string, ignorePatterns []string) error {
-	if len(ignorePatterns) > 0 {
-		log.Printf("Warning: IgnorePaths specified but not yet fully implemented (optimization placeholder)")
-		log.Printf("Patterns specified: %v", ignorePatterns)
-		// TODO: Implement .gitignore-style pattern matching to remove files
-		// This would reduce artifact size and improve performance
-	}
-	return nil
-}
-
 // validateGitSource validates required fields for Git source type.
 func validateGitSource(chart ChartSpec) error {
 	// Validate URL
@@ -752,6 +957,12 @@ func cloneGitRepository(chart ChartSpec, destDir string) (chartPath string, clea
 		return "", nil, fmt.Errorf("chart.yaml not found at %s", chartPath)
 	}
 
+	// Remove ignored files before the chart is installed
+	if err := applyIgnorePatterns(cloneDir, chart.ChartPath, chart.IgnorePaths); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to apply ignorePaths: %w", err)
+	}
+
 	log.Printf("Successfully cloned and validated chart at: %s", chartPath)
 	return chartPath, cleanup, nil
 }
@@ -950,21 +1161,17 @@ func mergeMap(dst, src map[string]interface{}) {
 	}
 }
 
-// installChart installs a helm chart using the ChartSpec
-func installChart(chart ChartSpec, kubeconfigPath string) error {
-	releaseName := chart.ReleaseName
-	if releaseName == "" {
-		releaseName = chart.Name
-	}
-
-	// Handle different source types
+// prepareChartRef resolves the chart reference passed to helm for the chart source type.
+// The returned cleanup function removes temporary resources (cloned repositories,
+// downloaded charts, registry credentials) and must be called once helm is done.
+func prepareChartRef(chart ChartSpec, kubeconfigPath string) (string, func(), error) {
 	var chartRef string
-	var cleanup func()
+	cleanup := func() {}
 
 	switch chart.SourceType {
 	case "helm-repo":
 		if chart.ChartName == "" {
-			return fmt.Errorf("chartName is required when sourceType is helm-repo")
+			return "", nil, fmt.Errorf("chartName is required when sourceType is helm-repo")
 		}
 		// Extract repo name from URL for chart reference
 		repoName := extractRepoNameFromURL(chart.URL)
@@ -972,7 +1179,7 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 
 	case "local":
 		if chart.Path == "" {
-			return fmt.Errorf("path is required when sourceType is local")
+			return "", nil, fmt.Errorf("path is required when sourceType is local")
 		}
 		// For local charts, use the path directly
 		chartRef = chart.Path
@@ -980,26 +1187,25 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 	case "git":
 		// Validate Git source
 		if err := validateGitSource(chart); err != nil {
-			return fmt.Errorf("invalid git source: %w", err)
+			return "", nil, fmt.Errorf("invalid git source: %w", err)
 		}
 
 		// Create temporary directory for Git clone
-		tmpDir, err := os.MkdirTemp("", "helm-git-*")
+		tmpDir, err := tempdir.MkdirTemp("helm-git-*")
 		if err != nil {
-			return fmt.Errorf("failed to create temp dir: %w", err)
+			return "", nil, fmt.Errorf("failed to create temp dir: %w", err)
 		}
 
 		// Clone repository
 		chartPath, cleanupFunc, err := cloneGitRepository(chart, tmpDir)
 		if err != nil {
 			_ = os.RemoveAll(tmpDir)
-			return fmt.Errorf("failed to clone git repository: %w", err)
+			return "", nil, fmt.Errorf("failed to clone git repository: %w", err)
 		}
 		cleanup = func() {
 			cleanupFunc()
 			_ = os.RemoveAll(tmpDir)
 		}
-		defer cleanup()
 
 		chartRef = chartPath
 		log.Printf("Using git chart at: %s", chartRef)
@@ -1007,19 +1213,20 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 	case "oci":
 		// Validate OCI source
 		if err := validateOCISource(chart); err != nil {
-			return fmt.Errorf("invalid oci source: %w", err)
+			return "", nil, fmt.Errorf("invalid oci source: %w", err)
 		}
 
 		// Setup OCI authentication if needed
 		authCleanup, err := setupOCIAuth(kubeconfigPath, chart)
 		if err != nil {
-			return fmt.Errorf("failed to setup OCI auth: %w", err)
+			return "", nil, fmt.Errorf("failed to setup OCI auth: %w", err)
 		}
-		defer authCleanup()
+		cleanup = authCleanup
 
 		// Verify OCI signature if OCIProvider is set (optional)
-		if err := verifyOCISignature(chart); err != nil {
-			return fmt.Errorf("failed to verify OCI signature: %w", err)
+		if err := verifyOCISignature(kubeconfigPath, chart); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to verify OCI signature: %w", err)
 		}
 
 		// For OCI, helm can install directly from oci:// URL
@@ -1030,82 +1237,108 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 	case "s3":
 		// Validate S3 source
 		if err := validateS3Source(chart); err != nil {
-			return fmt.Errorf("invalid s3 source: %w", err)
+			return "", nil, fmt.Errorf("invalid s3 source: %w", err)
 		}
 
 		// Setup S3 client with authentication
 		s3Client, err := setupS3Auth(kubeconfigPath, chart)
 		if err != nil {
-			return fmt.Errorf("failed to setup S3 auth: %w", err)
+			return "", nil, fmt.Errorf("failed to setup S3 auth: %w", err)
 		}
 
 		// Create temporary directory for S3 download
-		tmpDir, err := os.MkdirTemp("", "helm-s3-*")
+		tmpDir, err := tempdir.MkdirTemp("helm-s3-*")
 		if err != nil {
-			return fmt.Errorf("failed to create temp dir for S3 download: %w", err)
+			return "", nil, fmt.Errorf("failed to create temp dir for S3 download: %w", err)
 		}
 		cleanup = func() {
 			_ = os.RemoveAll(tmpDir)
 		}
-		defer cleanup()
 
 		// Download chart from S3
 		chartPath, err := downloadFromS3(s3Client, chart, tmpDir)
 		if err != nil {
-			return fmt.Errorf("failed to download chart from S3: %w", err)
+			cleanup()
+			return "", nil, fmt.Errorf("failed to download chart from S3: %w", err)
 		}
 
 		chartRef = chartPath
 		log.Printf("Using S3 chart at: %s", chartRef)
 
-	default:
-		return fmt.Errorf("sourceType %s is not yet implemented", chart.SourceType)
-	}
+	case "azblob":
+		// Validate Azure Blob source
+		if err := validateAzureSource(chart); err != nil {
+			return "", nil, fmt.Errorf("invalid azblob source: %w", err)
+		}
 
-	args := []string{
-		"install",
-		releaseName,
-		chartRef,
-		"--kubeconfig", kubeconfigPath,
-	}
+		// Setup Azure Blob client with authentication
+		azureClient, err := setupAzureAuth(kubeconfigPath, chart)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to setup Azure Blob auth: %w", err)
+		}
 
-	// Add version if specified
-	if chart.Version != "" {
-		args = append(args, "--version", chart.Version)
-	}
+		// Create temporary directory for Azure Blob download
+		tmpDir, err := tempdir.MkdirTemp("helm-azblob-*")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create temp dir for Azure Blob download: %w", err)
+		}
+		cleanup = func() {
+			_ = os.RemoveAll(tmpDir)
+		}
 
-	// Add namespace handling
-	if chart.Namespace != "" {
-		args = append(args, "--namespace", chart.Namespace)
-		if chart.CreateNamespace {
-			args = append(args, "--create-namespace")
+		// Download chart from Azure Blob Storage
+		chartPath, err := downloadFromAzureBlob(azureClient, chart, tmpDir)
+		if err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to download chart from Azure Blob: %w", err)
 		}
-	}
 
-	// Add timeout (default to 5m if not specified)
-	timeout := chart.Timeout
-	if timeout == "" {
-		timeout = "5m"
-	}
-	args = append(args, "--timeout", timeout)
+		chartRef = chartPath
+		log.Printf("Using Azure Blob chart at: %s", chartRef)
 
-	// Add wait behavior (wait by default unless DisableWait is true)
-	if !chart.DisableWait {
-		args = append(args, "--wait")
-	}
+	case "gcs":
+		// Validate GCS source
+		if err := validateGCSSource(chart); err != nil {
+			return "", nil, fmt.Errorf("invalid gcs source: %w", err)
+		}
 
-	// Add force upgrade if specified
-	if chart.ForceUpgrade {
-		args = append(args, "--force")
-	}
+		// Setup GCS client with authentication
+		gcsClient, err := setupGCSAuth(kubeconfigPath, chart)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to setup GCS auth: %w", err)
+		}
 
-	// Add disable hooks if specified
-	if chart.DisableHooks {
-		args = append(args, "--no-hooks")
+		// Create temporary directory for GCS download
+		tmpDir, err := tempdir.MkdirTemp("helm-gcs-*")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create temp dir for GCS download: %w", err)
+		}
+		cleanup = func() {
+			_ = os.RemoveAll(tmpDir)
+		}
+
+		// Download chart from GCS
+		chartPath, err := downloadFromGCS(gcsClient, chart, tmpDir)
+		if err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to download chart from GCS: %w", err)
+		}
+
+		chartRef = chartPath
+		log.Printf("Using GCS chart at: %s", chartRef)
+
+	default:
+		return "", nil, fmt.Errorf("sourceType %s is not yet implemented", chart.SourceType)
 	}
 
-	// Compose values from multiple sources
-	// Priority (lowest to highest): ValuesFiles < ValueReferences < inline Values
+	return chartRef, cleanup, nil
+}
+
+// composeValuesArgs composes the chart values and returns the matching helm --values flags.
+// Priority (lowest to highest): ValuesFiles < ValueReferences < inline Values.
+// In render-only mode, ValueReferences that cannot be read from the cluster are skipped with a warning.
+// The returned cleanup function removes the temporary composed values file.
+func composeValuesArgs(chart ChartSpec, kubeconfigPath string, renderOnly bool) ([]string, func(), error) {
 	composedValues := make(map[string]interface{})
 
 	// Note: ValuesFiles are handled by helm directly, not merged here
@@ -1113,6 +1346,11 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 
 	// Process ValueReferences (medium precedence)
 	for _, ref := range chart.ValueReferences {
+		if renderOnly && kubeconfigPath == "" {
+			log.Printf("Warning: no kubeconfig in render-only mode, skipping ValueReference %s/%s", ref.Kind, ref.Name)
+			continue
+		}
+
 		// Use default namespace if chart namespace is not set
 		namespace := chart.Namespace
 		if namespace == "" {
@@ -1120,7 +1358,11 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 		}
 		refValues, err := resolveValueReference(kubeconfigPath, namespace, ref)
 		if err != nil {
-			return fmt.Errorf("failed to resolve ValueReference %s/%s: %w", ref.Kind, ref.Name, err)
+			if renderOnly {
+				log.Printf("Warning: skipping ValueReference %s/%s in render-only mode: %v", ref.Kind, ref.Name, err)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to resolve ValueReference %s/%s: %w", ref.Kind, ref.Name, err)
 		}
 
 		// If refValues is nil (optional reference not found), skip
@@ -1132,12 +1374,12 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 				// Merge at root level
 				refMap, ok := refValues.(map[string]interface{})
 				if !ok {
-					return fmt.Errorf("ValueReference %s/%s returned non-map value at root level (type %T)", ref.Kind, ref.Name, refValues)
+					return nil, nil, fmt.Errorf("ValueReference %s/%s returned non-map value at root level (type %T)", ref.Kind, ref.Name, refValues)
 				}
 				mergeMap(composedValues, refMap)
 			}
 			if err != nil {
-				return fmt.Errorf("failed to merge values from %s/%s: %w", ref.Kind, ref.Name, err)
+				return nil, nil, fmt.Errorf("failed to merge values from %s/%s: %w", ref.Kind, ref.Name, err)
 			}
 		}
 	}
@@ -1147,51 +1389,108 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 		composedValues[key] = value
 	}
 
-	// If we have composed values from ValueReferences or inline Values, write to temp file
-	var valuesTempFile string
-	if len(composedValues) > 0 {
-		// Create temp file for values
-		tmpFile, err := os.CreateTemp("", "helm-values-*.yaml")
-		if err != nil {
-			return fmt.Errorf("failed to create temp values file: %w", err)
-		}
-		valuesTempFile = tmpFile.Name()
-		defer func() {
-			if err := os.Remove(valuesTempFile); err != nil {
-				log.Printf("Warning: failed to remove temp values file %s: %v", valuesTempFile, err)
-			}
-		}()
+	// Add values files if specified (lowest precedence)
+	var args []string
+	for _, valuesFile := range chart.ValuesFiles {
+		args = append(args, "--values", valuesFile)
+	}
 
-		// Marshal values to YAML
-		valuesYAML, err := yaml.Marshal(composedValues)
-		if err != nil {
-			return fmt.Errorf("failed to marshal values to YAML: %w", err)
+	if len(composedValues) == 0 {
+		return args, func() {}, nil
+	}
+
+	// Marshal values to YAML
+	valuesYAML, err := yaml.Marshal(composedValues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal values to YAML: %w", err)
+	}
+
+	// Create temp file for values
+	tmpFile, err := tempdir.CreateTemp("helm-values-*.yaml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temp values file: %w", err)
+	}
+	valuesTempFile := tmpFile.Name()
+	cleanup := func() {
+		if err := os.Remove(valuesTempFile); err != nil {
+			log.Printf("Warning: failed to remove temp values file %s: %v", valuesTempFile, err)
 		}
+	}
 
-		// Write to temp file
-		if _, err := tmpFile.Write(valuesYAML); err != nil {
-			if closeErr := tmpFile.Close(); closeErr != nil {
-				log.Printf("Warning: failed to close temp file: %v", closeErr)
-			}
-			return fmt.Errorf("failed to write values to temp file: %w", err)
+	// Write to temp file
+	if _, err := tmpFile.Write(valuesYAML); err != nil {
+		if closeErr := tmpFile.Close(); closeErr != nil {
+			log.Printf("Warning: failed to close temp file: %v", closeErr)
 		}
-		if err := tmpFile.Close(); err != nil {
-			log.Printf("Warning: failed to close temp file: %v", err)
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write values to temp file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		log.Printf("Warning: failed to close temp file: %v", err)
+	}
+
+	log.Printf("Composed values from %d ValueReferences and inline values, wrote to: %s", len(chart.ValueReferences), valuesTempFile)
+
+	// Add composed values file (medium precedence - after ValuesFiles)
+	return append(args, "--values", valuesTempFile), cleanup, nil
+}
+
+// installChart installs a helm chart using the ChartSpec.
+// When validateSchema is set, the composed values are validated against the chart's
+// values.schema.json before the release is touched.
+func installChart(chart ChartSpec, kubeconfigPath string, validateSchema bool) error {
+	releaseName := chart.ReleaseName
+	if releaseName == "" {
+		releaseName = chart.Name
+	}
+
+	chartRef, cleanup, err := prepareChartRef(chart, kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	valuesArgs, valuesCleanup, err := composeValuesArgs(chart, kubeconfigPath, false)
+	if err != nil {
+		return err
+	}
+	defer valuesCleanup()
+
+	if validateSchema {
+		if err := validateValuesSchema(chartRef, chart.Version, valuesArgs); err != nil {
+			return err
 		}
+	}
 
-		log.Printf("Composed values from %d ValueReferences and inline values, wrote to: %s", len(chart.ValueReferences), valuesTempFile)
+	// Detect an existing release (e.g. from a reused environment) to choose between install and upgrade
+	status, err := helmReleaseStatus(releaseName, chart.Namespace, kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to check status of release %s: %w", releaseName, err)
 	}
 
-	// Add values files if specified (lowest precedence)
-	for _, valuesFile := range chart.ValuesFiles {
-		args = append(args, "--values", valuesFile)
+	// A release stuck in a pending state blocks any further operation: remove it and install from scratch
+	if isPendingReleaseStatus(status) {
+		log.Printf("Release %s is in %s state, uninstalling before reinstalling", releaseName, status)
+		if err := uninstallChart(releaseName, chart.Namespace, kubeconfigPath); err != nil {
+			return fmt.Errorf("failed to uninstall pending release %s: %w", releaseName, err)
+		}
+		status = ""
 	}
 
-	// Add composed values file (medium precedence - after ValuesFiles)
-	if valuesTempFile != "" {
-		args = append(args, "--values", valuesTempFile)
+	upgrade := chart.Upgrade || status != ""
+	if upgrade && status != "" {
+		log.Printf("Release %s already exists (status: %s), upgrading", releaseName, status)
 	}
 
+	// Add timeout (default to 5m if not specified)
+	timeout := chart.Timeout
+	if timeout == "" {
+		timeout = "5m"
+	}
+
+	args := buildHelmInstallArgs(chart, releaseName, chartRef, kubeconfigPath, timeout, upgrade)
+	args = append(args, valuesArgs...)
+
 	log.Printf("Running: helm %v", args)
 
 	// Calculate context timeout based on helm timeout plus buffer
@@ -1210,13 +1509,21 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("helm install timed out after %v", contextTimeout)
+			return fmt.Errorf("%w: helm %s timed out after %v", errReleaseFailed, args[0], contextTimeout)
 		}
-		return fmt.Errorf("helm install failed: %w, output: %s", err, string(output))
+		return fmt.Errorf("%w: helm %s failed: %w, output: %s", errReleaseFailed, args[0], err, string(output))
 	}
 
 	log.Printf("Chart installed successfully: %s", releaseName)
 
+	// Assert resources are ready before handing over the environment
+	if len(chart.ReadinessChecks) > 0 {
+		chart.Timeout = timeout
+		if err := runReadinessChecks(chart, kubeconfigPath, kubectlGetResource); err != nil {
+			return fmt.Errorf("%w: %w", errReleaseFailed, err)
+		}
+	}
+
 	// Run helm tests if enabled
 	if chart.TestEnable {
 		log.Printf("Running helm tests for release: %s", releaseName)
@@ -1229,6 +1536,125 @@ func installChart(chart ChartSpec, kubeconfigPath string) error {
 	return nil
 }
 
+// buildHelmInstallArgs builds the helm arguments installing the chart, without values flags.
+// In upgrade mode it runs 'helm upgrade --install', which also installs a missing release.
+func buildHelmInstallArgs(chart ChartSpec, releaseName, chartRef, kubeconfigPath, timeout string, upgrade bool) []string {
+	var args []string
+	if upgrade {
+		args = []string{"upgrade", "--install", releaseName, chartRef}
+	} else {
+		args = []string{"install", releaseName, chartRef}
+	}
+	args = append(args, "--kubeconfig", kubeconfigPath)
+
+	// Add version if specified
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+
+	// Add namespace handling
+	if chart.Namespace != "" {
+		args = append(args, "--namespace", chart.Namespace)
+		if chart.CreateNamespace {
+			args = append(args, "--create-namespace")
+		}
+	}
+
+	args = append(args, "--timeout", timeout)
+
+	// Add wait behavior (wait by default unless DisableWait is true)
+	if !chart.DisableWait {
+		args = append(args, "--wait")
+	}
+
+	// Add force upgrade if specified
+	if chart.ForceUpgrade {
+		args = append(args, "--force")
+	}
+
+	// Add disable hooks if specified
+	if chart.DisableHooks {
+		args = append(args, "--no-hooks")
+	}
+
+	// Discard values of the existing release if requested
+	if upgrade && chart.ResetValues {
+		args = append(args, "--reset-values")
+	}
+
+	return args
+}
+
+// errHelmReleaseNotFound is returned by runHelmStatus when the release does not exist.
+var errHelmReleaseNotFound = errors.New("helm release not found")
+
+// helmReleaseStatus returns the status of a helm release (e.g. "deployed", "failed",
+// "pending-install"), or an empty string if the release does not exist.
+func helmReleaseStatus(releaseName, namespace, kubeconfigPath string) (string, error) {
+	data, err := runHelmStatus(releaseName, namespace, kubeconfigPath)
+	if errors.Is(err, errHelmReleaseNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return parseHelmReleaseStatus(data)
+}
+
+// runHelmStatus returns the JSON output of 'helm status' for a release.
+// Returns errHelmReleaseNotFound if the release does not exist.
+func runHelmStatus(releaseName, namespace, kubeconfigPath string) ([]byte, error) {
+	args := []string{
+		"status",
+		releaseName,
+		"--kubeconfig", kubeconfigPath,
+		"--output", "json",
+	}
+
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "helm", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("helm status timed out after 1 minute")
+		}
+		if strings.Contains(stderr.String(), "release: not found") {
+			return nil, errHelmReleaseNotFound
+		}
+		return nil, fmt.Errorf("helm status failed: %w, output: %s", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// parseHelmReleaseStatus extracts info.status from the JSON output of 'helm status'.
+func parseHelmReleaseStatus(data []byte) (string, error) {
+	var release struct {
+		Info struct {
+			Status string `json:"status"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		return "", fmt.Errorf("failed to parse helm status output: %w", err)
+	}
+	return release.Info.Status, nil
+}
+
+// isPendingReleaseStatus reports whether a release is stuck in a pending operation,
+// which makes helm refuse any install or upgrade.
+func isPendingReleaseStatus(status string) bool {
+	return strings.HasPrefix(status, "pending-")
+}
+
 // runHelmTest runs helm test for a release
 func runHelmTest(releaseName, namespace, kubeconfigPath, timeout string) error {
 	args := []string{
@@ -1510,7 +1936,7 @@ func setupOCIAuth(kubeconfigPath string, chart ChartSpec) (cleanup func(), err e
 	}
 
 	// Create temporary Docker config directory
-	tempDockerConfig, err := os.MkdirTemp("", "docker-config-*")
+	tempDockerConfig, err := tempdir.MkdirTemp("docker-config-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp docker config directory: %w", err)
 	}
@@ -1619,26 +2045,6 @@ func validateOCISource(chart ChartSpec) error {
 	return nil
 }
 
-// verifyOCISignature verifies OCI chart signature using specified provider.
-// Returns nil if verification passes or if OCIProvider is not set.
-// Currently logs a warning if OCIProvider is set (not yet fully implemented).
-func verifyOCISignature(chart ChartSpec) error {
-	// If no OCIProvider specified, skip verification
-	if chart.OCIProvider == "" {
-		return nil
-	}
-
-	// Log warning that OCIProvider verification is not yet fully implemented
-	log.Printf("Warning: OCIProvider verification (%s) is not yet fully implemented. Skipping signature verification for chart %s", chart.OCIProvider, chart.Name)
-
-	// In the future, implement verification using:
-	// - cosign: for chart.OCIProvider == "cosign"
-	// - notation: for chart.OCIProvider == "notation"
-
-	// For now, just return nil (don't fail the installation)
-	return nil
-}
-
 // -------------------------------------------------------------------------
 // S3 Source Type Functions
 // -------------------------------------------------------------------------
@@ -1824,30 +2230,340 @@ func validateS3Source(chart ChartSpec) error {
 		return fmt.Errorf("s3BucketName is required for s3 source type")
 	}
 
+	return validateTarballSourceFields(chart, "s3")
+}
+
+// validateTarballSourceFields validates the fields shared by object storage sources
+// ("s3", "azblob", "gcs") that download a chart tarball before installing it.
+func validateTarballSourceFields(chart ChartSpec, sourceType string) error {
 	// Validate ChartPath
 	if chart.ChartPath == "" {
-		return fmt.Errorf("chartPath is required for s3 source type")
+		return fmt.Errorf("chartPath is required for %s source type", sourceType)
 	}
 
 	// Validate ChartPath ends with .tgz or .tar.gz
 	if !strings.HasSuffix(chart.ChartPath, ".tgz") && !strings.HasSuffix(chart.ChartPath, ".tar.gz") {
-		return fmt.Errorf("chartPath must end with .tgz or .tar.gz for s3 source type")
+		return fmt.Errorf("chartPath must end with .tgz or .tar.gz for %s source type", sourceType)
 	}
 
-	// Git fields should not be set for S3 sources
+	// Git fields should not be set for object storage sources
 	if chart.GitBranch != "" || chart.GitTag != "" || chart.GitCommit != "" || chart.GitSemVer != "" {
-		return fmt.Errorf("git reference fields (GitBranch, GitTag, GitCommit, GitSemVer) should not be set for s3 source type")
+		return fmt.Errorf("git reference fields (GitBranch, GitTag, GitCommit, GitSemVer) should not be set for %s source type", sourceType)
 	}
 
-	// OCI fields should not be set for S3 sources
+	// OCI fields should not be set for object storage sources
 	if chart.OCIProvider != "" || chart.OCILayerMediaType != "" {
-		return fmt.Errorf("oci fields (OCIProvider, OCILayerMediaType) should not be set for s3 source type")
+		return fmt.Errorf("oci fields (OCIProvider, OCILayerMediaType) should not be set for %s source type", sourceType)
 	}
 
-	// ChartName should not be set for S3 sources
+	// ChartName should not be set for object storage sources
 	if chart.ChartName != "" {
-		return fmt.Errorf("chartName should not be set for s3 source type (chart name is in the tarball)")
+		return fmt.Errorf("chartName should not be set for %s source type (chart name is in the tarball)", sourceType)
+	}
+
+	// Bucket fields of other object storage providers should not be set
+	if sourceType != "s3" && (chart.S3BucketName != "" || chart.S3BucketRegion != "") {
+		return fmt.Errorf("s3 fields (S3BucketName, S3BucketRegion) should not be set for %s source type", sourceType)
+	}
+	if sourceType != "azblob" && chart.AzureContainerName != "" {
+		return fmt.Errorf("azureContainerName should not be set for %s source type", sourceType)
+	}
+	if sourceType != "gcs" && chart.GCSBucketName != "" {
+		return fmt.Errorf("gcsBucketName should not be set for %s source type", sourceType)
 	}
 
 	return nil
 }
+
+// -------------------------------------------------------------------------
+// Azure Blob Source Type Functions
+// -------------------------------------------------------------------------
+
+// extractAzureCredentialsFromSecret extracts Azure Blob credentials from a Kubernetes Secret's Data field.
+// The Secret must contain either sasToken, or both accountName and accountKey (Shared Key).
+// sasToken takes precedence when both are present.
+func extractAzureCredentialsFromSecret(secretData map[string]string) (accountName, accountKey, sasToken string, err error) {
+	sasToken = secretData["sasToken"]
+	if sasToken != "" {
+		return "", "", sasToken, nil
+	}
+
+	accountName = secretData["accountName"]
+	accountKey = secretData["accountKey"]
+	if accountName == "" && accountKey == "" {
+		return "", "", "", fmt.Errorf("secret must contain either sasToken or accountName and accountKey")
+	}
+	if accountName == "" {
+		return "", "", "", fmt.Errorf("accountName is required in Secret when accountKey is set")
+	}
+	if accountKey == "" {
+		return "", "", "", fmt.Errorf("accountKey is required in Secret when accountName is set")
+	}
+
+	return accountName, accountKey, "", nil
+}
+
+// setupAzureAuth configures and returns an Azure Blob client with credentials from a Kubernetes Secret.
+// If AuthSecretName is not set, it returns an anonymous client (public container).
+// Returns an error if the Secret cannot be fetched or credentials are invalid.
+func setupAzureAuth(kubeconfigPath string, chart ChartSpec) (*AzureBlobClient, error) {
+	// If no auth secret specified, access the container anonymously
+	if chart.AuthSecretName == "" {
+		log.Printf("No AuthSecretName specified, accessing Azure Blob container anonymously")
+		return NewAzureBlobClient(chart.URL)
+	}
+
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	log.Printf("Fetching Azure Blob auth secret %s from namespace %s", chart.AuthSecretName, namespace)
+
+	secretData, err := fetchSecret(kubeconfigPath, namespace, chart.AuthSecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	accountName, accountKey, sasToken, err := extractAzureCredentialsFromSecret(secretData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract Azure Blob credentials: %w", err)
+	}
+
+	if sasToken != "" {
+		log.Printf("Creating Azure Blob client with SAS token from Secret %s", chart.AuthSecretName)
+		return NewAzureBlobClientWithSASToken(chart.URL, sasToken)
+	}
+
+	log.Printf("Creating Azure Blob client with Shared Key from Secret %s", chart.AuthSecretName)
+	return NewAzureBlobClientWithSharedKey(chart.URL, accountName, accountKey)
+}
+
+// downloadFromAzureBlob downloads a chart tarball from an Azure Blob container to a local directory.
+// Returns the full path to the downloaded chart file.
+func downloadFromAzureBlob(client *AzureBlobClient, chart ChartSpec, destDir string) (string, error) {
+	container, blob := chart.AzureContainerName, chart.ChartPath
+
+	log.Printf("Downloading chart from Azure Blob: container=%s, blob=%s", container, blob)
+
+	destPath, err := tarballDestPath(blob, destDir)
+	if err != nil {
+		return "", err
+	}
+
+	if err := client.DownloadFile(container, blob, destPath); err != nil {
+		return "", fmt.Errorf("failed to download from Azure Blob: %w", err)
+	}
+
+	log.Printf("Successfully downloaded chart to: %s", destPath)
+	return destPath, nil
+}
+
+// validateAzureContainerName validates an Azure Blob container name:
+// 3-63 characters of lowercase letters, digits and hyphens, starting with a letter or digit,
+// with no consecutive hyphens and no trailing hyphen.
+func validateAzureContainerName(name string) error {
+	if len(name) < 3 || len(name) > 63 {
+		return fmt.Errorf("azureContainerName %q must be between 3 and 63 characters long", name)
+	}
+
+	for _, r := range name {
+		if !isLowerAlphaNum(r) && r != '-' {
+			return fmt.Errorf("azureContainerName %q may only contain lowercase letters, digits and hyphens", name)
+		}
+	}
+
+	if name[0] == '-' {
+		return fmt.Errorf("azureContainerName %q must start with a letter or digit", name)
+	}
+	if name[len(name)-1] == '-' {
+		return fmt.Errorf("azureContainerName %q must not end with a hyphen", name)
+	}
+	if strings.Contains(name, "--") {
+		return fmt.Errorf("azureContainerName %q must not contain consecutive hyphens", name)
+	}
+
+	return nil
+}
+
+// validateAzureSource validates required fields for Azure Blob source type.
+func validateAzureSource(chart ChartSpec) error {
+	// Validate URL
+	if chart.URL == "" {
+		return fmt.Errorf("url is required for azblob source type")
+	}
+
+	// Validate URL format (must be http:// or https://)
+	if !strings.HasPrefix(chart.URL, "http://") && !strings.HasPrefix(chart.URL, "https://") {
+		return fmt.Errorf("invalid url format: must start with http:// or https://")
+	}
+
+	// Validate AzureContainerName
+	if chart.AzureContainerName == "" {
+		return fmt.Errorf("azureContainerName is required for azblob source type")
+	}
+	if err := validateAzureContainerName(chart.AzureContainerName); err != nil {
+		return err
+	}
+
+	return validateTarballSourceFields(chart, "azblob")
+}
+
+// -------------------------------------------------------------------------
+// GCS Source Type Functions
+// -------------------------------------------------------------------------
+
+// extractGCSCredentialsFromSecret extracts GCS credentials from a Kubernetes Secret's Data field.
+// The Secret must contain either serviceAccountKey (a service account JSON key) or accessToken.
+// serviceAccountKey takes precedence when both are present.
+func extractGCSCredentialsFromSecret(secretData map[string]string) (serviceAccountKey, accessToken string, err error) {
+	serviceAccountKey = secretData["serviceAccountKey"]
+	if serviceAccountKey != "" {
+		return serviceAccountKey, "", nil
+	}
+
+	accessToken = secretData["accessToken"]
+	if accessToken != "" {
+		return "", accessToken, nil
+	}
+
+	return "", "", fmt.Errorf("secret must contain either serviceAccountKey or accessToken")
+}
+
+// setupGCSAuth configures and returns a GCS client with credentials from a Kubernetes Secret.
+// If AuthSecretName is not set, it uses the service account key referenced by
+// GOOGLE_APPLICATION_CREDENTIALS when present, and anonymous access (public bucket) otherwise.
+// Returns an error if the Secret cannot be fetched or credentials are invalid.
+func setupGCSAuth(kubeconfigPath string, chart ChartSpec) (*GCSClient, error) {
+	if chart.AuthSecretName == "" {
+		if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+			log.Printf("No AuthSecretName specified, using GOOGLE_APPLICATION_CREDENTIALS (%s)", path)
+			keyJSON, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+			}
+			return NewGCSClientWithServiceAccountKey(chart.URL, keyJSON)
+		}
+
+		log.Printf("No AuthSecretName specified, accessing GCS bucket anonymously")
+		return NewGCSClient(chart.URL)
+	}
+
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	log.Printf("Fetching GCS auth secret %s from namespace %s", chart.AuthSecretName, namespace)
+
+	secretData, err := fetchSecret(kubeconfigPath, namespace, chart.AuthSecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAccountKey, accessToken, err := extractGCSCredentialsFromSecret(secretData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract GCS credentials: %w", err)
+	}
+
+	if serviceAccountKey != "" {
+		log.Printf("Creating GCS client with service account key from Secret %s", chart.AuthSecretName)
+		return NewGCSClientWithServiceAccountKey(chart.URL, []byte(serviceAccountKey))
+	}
+
+	log.Printf("Creating GCS client with access token from Secret %s", chart.AuthSecretName)
+	return NewGCSClientWithAccessToken(chart.URL, accessToken)
+}
+
+// downloadFromGCS downloads a chart tarball from a GCS bucket to a local directory.
+// Returns the full path to the downloaded chart file.
+func downloadFromGCS(client *GCSClient, chart ChartSpec, destDir string) (string, error) {
+	bucket, object := chart.GCSBucketName, chart.ChartPath
+
+	log.Printf("Downloading chart from GCS: bucket=%s, object=%s", bucket, object)
+
+	destPath, err := tarballDestPath(object, destDir)
+	if err != nil {
+		return "", err
+	}
+
+	if err := client.DownloadFile(bucket, object, destPath); err != nil {
+		return "", fmt.Errorf("failed to download from GCS: %w", err)
+	}
+
+	log.Printf("Successfully downloaded chart to: %s", destPath)
+	return destPath, nil
+}
+
+// validateGCSBucketName validates a GCS bucket name: 3-63 characters (up to 222 when
+// dot-separated, each component at most 63) of lowercase letters, digits, hyphens,
+// underscores and dots, starting and ending with a letter or digit. Names cannot be
+// IP addresses, start with "goog" or contain "google".
+func validateGCSBucketName(name string) error {
+	if len(name) < 3 {
+		return fmt.Errorf("gcsBucketName %q must be at least 3 characters long", name)
+	}
+	if !strings.Contains(name, ".") && len(name) > 63 {
+		return fmt.Errorf("gcsBucketName %q must be at most 63 characters long", name)
+	}
+	if len(name) > 222 {
+		return fmt.Errorf("gcsBucketName %q must be at most 222 characters long", name)
+	}
+
+	for _, r := range name {
+		if !isLowerAlphaNum(r) && r != '-' && r != '_' && r != '.' {
+			return fmt.Errorf("gcsBucketName %q may only contain lowercase letters, digits, hyphens, underscores and dots", name)
+		}
+	}
+
+	if !isLowerAlphaNum(rune(name[0])) || !isLowerAlphaNum(rune(name[len(name)-1])) {
+		return fmt.Errorf("gcsBucketName %q must start and end with a letter or digit", name)
+	}
+
+	for _, component := range strings.Split(name, ".") {
+		if component == "" || len(component) > 63 {
+			return fmt.Errorf("gcsBucketName %q: each dot-separated component must be between 1 and 63 characters long", name)
+		}
+	}
+
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("gcsBucketName %q must not be an IP address", name)
+	}
+	if strings.HasPrefix(name, "goog") || strings.Contains(name, "google") {
+		return fmt.Errorf("gcsBucketName %q must not start with \"goog\" or contain \"google\"", name)
+	}
+
+	return nil
+}
+
+// validateGCSSource validates required fields for GCS source type.
+func validateGCSSource(chart ChartSpec) error {
+	// URL is optional (defaults to the public GCS endpoint) but must be http:// or https:// when set
+	if chart.URL != "" && !strings.HasPrefix(chart.URL, "http://") && !strings.HasPrefix(chart.URL, "https://") {
+		return fmt.Errorf("invalid url format: must start with http:// or https://")
+	}
+
+	// Validate GCSBucketName
+	if chart.GCSBucketName == "" {
+		return fmt.Errorf("gcsBucketName is required for gcs source type")
+	}
+	if err := validateGCSBucketName(chart.GCSBucketName); err != nil {
+		return err
+	}
+
+	return validateTarballSourceFields(chart, "gcs")
+}
+
+// tarballDestPath returns the local path a chart tarball object is downloaded to.
+func tarballDestPath(objectPath, destDir string) (string, error) {
+	filename := filepath.Base(objectPath)
+	if filename == "" || filename == "." || filename == "/" {
+		return "", fmt.Errorf("invalid chart path: cannot determine filename from %s", objectPath)
+	}
+	return filepath.Join(destDir, filename), nil
+}
+
+// isLowerAlphaNum reports whether r is a lowercase ASCII letter or a digit.
+func isLowerAlphaNum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
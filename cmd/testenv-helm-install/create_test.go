@@ -0,0 +1,545 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/testutil"
+	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
+)
+
+// fakeHelmScript stands in for helm: installs take ~200ms and releases are never found.
+// Install invocations are appended to $FAKE_HELM_LOG when set. When $FAKE_HELM_STATE is set,
+// installed releases are recorded there and reported as deployed by 'helm status'.
+// Installs fail when $FAKE_HELM_FAIL is set. 'helm pull' copies the chart directory
+// $FAKE_HELM_PULL_CHART to --untardir, and fails when it is not set.
+const fakeHelmScript = `#!/bin/sh
+case "$1" in
+status)
+  if [ -n "$FAKE_HELM_STATE" ] && [ -f "$FAKE_HELM_STATE/$2" ]; then
+    echo "{\"name\":\"$2\",\"version\":1,\"info\":{\"status\":\"deployed\"},\"chart\":{\"metadata\":{\"version\":\"1.2.3\",\"appVersion\":\"4.5.6\"}}}"
+    exit 0
+  fi
+  echo "Error: release: not found" >&2
+  exit 1
+  ;;
+install|upgrade)
+  if [ -n "$FAKE_HELM_LOG" ]; then echo "$@" >> "$FAKE_HELM_LOG"; fi
+  if [ -n "$FAKE_HELM_FAIL" ]; then
+    echo "Error: context deadline exceeded" >&2
+    exit 1
+  fi
+  if [ -n "$FAKE_HELM_STATE" ]; then
+    if [ "$1" = "install" ]; then touch "$FAKE_HELM_STATE/$2"; else touch "$FAKE_HELM_STATE/$3"; fi
+  fi
+  sleep 0.2
+  exit 0
+  ;;
+pull)
+  if [ -n "$FAKE_HELM_LOG" ]; then echo "$@" >> "$FAKE_HELM_LOG"; fi
+  if [ -z "$FAKE_HELM_PULL_CHART" ]; then
+    echo "Error: chart \"$2\" not found" >&2
+    exit 1
+  fi
+  while [ $# -gt 0 ]; do
+    if [ "$1" = "--untardir" ]; then untardir="$2"; fi
+    shift
+  done
+  cp -R "$FAKE_HELM_PULL_CHART" "$untardir/"
+  exit 0
+  ;;
+esac
+exit 0
+`
+
+// installFakeHelm puts a fake helm binary first in PATH for the duration of the test.
+func installFakeHelm(t *testing.T) {
+	t.Helper()
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "helm"), []byte(fakeHelmScript), 0o755); err != nil {
+		t.Fatalf("failed to write fake helm: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestCreate_RecordsInstallDuration(t *testing.T) {
+	installFakeHelm(t)
+
+	tmpDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	input := engineframework.CreateInput{
+		TestID: "test-duration",
+		Stage:  "integration",
+		TmpDir: tmpDir,
+		Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+		Spec: map[string]any{
+			"charts": []any{
+				map[string]any{"name": "first", "sourceType": "local", "path": chartDir},
+				map[string]any{"name": "second", "sourceType": "local", "path": chartDir, "namespace": "apps"},
+			},
+		},
+	}
+
+	// Every helm invocation must be waited for
+	var artifact *engineframework.TestEnvArtifact
+	var err error
+	testutil.AssertNoLeakedProcesses(t, func() {
+		artifact, err = Create(context.Background(), input, &Spec{})
+	})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	for _, key := range []string{
+		"testenv-helm-install.chart.0.durationSeconds",
+		"testenv-helm-install.chart.1.durationSeconds",
+	} {
+		value, ok := artifact.Metadata[key]
+		if !ok {
+			t.Fatalf("metadata %s not set: %v", key, artifact.Metadata)
+		}
+
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("metadata %s = %q is not a number: %v", key, value, err)
+		}
+		// The fake install sleeps 200ms; allow generous slack for slow machines
+		if seconds < 0.2 || seconds > 30 {
+			t.Errorf("metadata %s = %v, want a plausible install duration (>= 0.2s)", key, seconds)
+		}
+	}
+}
+
+func TestCreate_InstallBudgetAbortsRemainingInstalls(t *testing.T) {
+	installFakeHelm(t)
+
+	tmpDir := t.TempDir()
+	helmLog := filepath.Join(tmpDir, "helm.log")
+	t.Setenv("FAKE_HELM_LOG", helmLog)
+
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	input := engineframework.CreateInput{
+		TestID: "test-budget",
+		Stage:  "integration",
+		TmpDir: tmpDir,
+		Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+		Spec: map[string]any{
+			"charts": []any{
+				map[string]any{"name": "first", "sourceType": "local", "path": chartDir},
+				map[string]any{"name": "second", "sourceType": "local", "path": chartDir},
+				map[string]any{"name": "third", "sourceType": "local", "path": chartDir},
+			},
+		},
+	}
+
+	// Each fake install takes ~200ms, so the budget is spent after the first or second chart
+	_, err := Create(context.Background(), input, &Spec{InstallTimeout: "300ms"})
+	if err == nil {
+		t.Fatal("Create() expected an error when the install budget is exceeded")
+	}
+	if !errors.Is(err, errInstallBudgetExceeded) {
+		t.Fatalf("Create() error = %v, want errInstallBudgetExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "installed so far: first") {
+		t.Errorf("Create() error = %q, want it to list the installed charts", err)
+	}
+	if !strings.Contains(err.Error(), "third") {
+		t.Errorf("Create() error = %q, want it to list the charts not installed", err)
+	}
+
+	logContent, readErr := os.ReadFile(helmLog)
+	if readErr != nil {
+		t.Fatalf("failed to read fake helm log: %v", readErr)
+	}
+	if strings.Contains(string(logContent), "install third") {
+		t.Errorf("chart third was installed after the budget was exceeded:\n%s", logContent)
+	}
+}
+
+func TestCreate_InstallsChartsInPriorityOrder(t *testing.T) {
+	installFakeHelm(t)
+
+	tmpDir := t.TempDir()
+	helmLog := filepath.Join(tmpDir, "helm.log")
+	t.Setenv("FAKE_HELM_LOG", helmLog)
+
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Workloads are listed first, CRDs last; charts without priority default to 0
+	input := engineframework.CreateInput{
+		TestID: "test-priority",
+		Stage:  "integration",
+		TmpDir: tmpDir,
+		Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+		Spec: map[string]any{
+			"charts": []any{
+				map[string]any{"name": "app", "sourceType": "local", "path": chartDir, "priority": 20},
+				map[string]any{"name": "operator", "sourceType": "local", "path": chartDir, "priority": 10, "dependsOn": []any{"crds"}},
+				map[string]any{"name": "monitoring", "sourceType": "local", "path": chartDir, "priority": 20},
+				map[string]any{"name": "crds", "sourceType": "local", "path": chartDir},
+			},
+		},
+	}
+
+	artifact, err := Create(context.Background(), input, &Spec{})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	want := []string{"crds", "operator", "app", "monitoring"}
+
+	logContent, err := os.ReadFile(helmLog)
+	if err != nil {
+		t.Fatalf("failed to read fake helm log: %v", err)
+	}
+	var installed []string
+	for _, line := range strings.Split(strings.TrimSpace(string(logContent)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "install" {
+			t.Fatalf("unexpected helm invocation: %q", line)
+		}
+		installed = append(installed, fields[1])
+	}
+	if strings.Join(installed, ",") != strings.Join(want, ",") {
+		t.Errorf("install order = %v, want %v", installed, want)
+	}
+
+	// Metadata indices follow install order so that delete uninstalls in reverse
+	for i, name := range want {
+		key := "testenv-helm-install.chart." + strconv.Itoa(i) + ".name"
+		if got := artifact.Metadata[key]; got != name {
+			t.Errorf("metadata %s = %q, want %q", key, got, name)
+		}
+	}
+}
+
+func TestCreate_RejectsDependencyInstalledLater(t *testing.T) {
+	installFakeHelm(t)
+
+	tmpDir := t.TempDir()
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// crds is declared first but installed after operator because of its priority
+	input := engineframework.CreateInput{
+		TestID: "test-priority-deps",
+		Stage:  "integration",
+		TmpDir: tmpDir,
+		Spec: map[string]any{
+			"charts": []any{
+				map[string]any{"name": "crds", "sourceType": "local", "path": chartDir, "priority": 10},
+				map[string]any{"name": "operator", "sourceType": "local", "path": chartDir, "dependsOn": []any{"crds"}},
+			},
+		},
+	}
+
+	_, err := Create(context.Background(), input, &Spec{})
+	if err == nil || !strings.Contains(err.Error(), "must reference a chart installed before it") {
+		t.Fatalf("Create() error = %v, want a dependency order error", err)
+	}
+}
+
+func TestValidateChartSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   ChartSpec
+		wantErr string
+	}{
+		{name: "helm-repo", chart: ChartSpec{Name: "c", SourceType: "helm-repo", URL: "https://charts.example.com", ChartName: "app"}},
+		{name: "local", chart: ChartSpec{Name: "c", SourceType: "local", Path: "./chart"}},
+		{name: "other source types are checked later", chart: ChartSpec{Name: "c", SourceType: "oci"}},
+		{name: "missing sourceType", chart: ChartSpec{Name: "c"}, wantErr: "chart c: invalid spec: missing required field 'sourceType'"},
+		{name: "unknown sourceType", chart: ChartSpec{Name: "c", SourceType: "ftp"}, wantErr: "field 'sourceType' must be one of"},
+		{
+			name:    "helm-repo reports every missing field",
+			chart:   ChartSpec{Name: "c", SourceType: "helm-repo"},
+			wantErr: "missing required field 'url'; missing required field 'chartName'",
+		},
+		{name: "local without path", chart: ChartSpec{Name: "c", SourceType: "local"}, wantErr: "missing required field 'path'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateChartSource(tt.chart)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateChartSource() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateChartSource() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewInstallBudget(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		spec        *Spec
+		env         map[string]string
+		processEnv  string
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{name: "unlimited by default"},
+		{name: "spec", spec: &Spec{InstallTimeout: "20m"}, wantTimeout: 20 * time.Minute},
+		{
+			name:        "spec takes precedence over env",
+			spec:        &Spec{InstallTimeout: "20m"},
+			env:         map[string]string{installTimeoutEnvVar: "5m"},
+			processEnv:  "1m",
+			wantTimeout: 20 * time.Minute,
+		},
+		{
+			name:        "testenv env takes precedence over process env",
+			env:         map[string]string{installTimeoutEnvVar: "5m"},
+			processEnv:  "1m",
+			wantTimeout: 5 * time.Minute,
+		},
+		{name: "process env", processEnv: "1m", wantTimeout: time.Minute},
+		{name: "invalid duration", spec: &Spec{InstallTimeout: "soon"}, wantErr: true},
+		{name: "non-positive duration", spec: &Spec{InstallTimeout: "0s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(installTimeoutEnvVar, tt.processEnv)
+
+			budget, err := newInstallBudget(tt.spec, tt.env, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newInstallBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if budget.timeout != tt.wantTimeout {
+				t.Errorf("timeout = %v, want %v", budget.timeout, tt.wantTimeout)
+			}
+			if budget.limited() && !budget.deadline.Equal(now.Add(tt.wantTimeout)) {
+				t.Errorf("deadline = %v, want %v", budget.deadline, now.Add(tt.wantTimeout))
+			}
+		})
+	}
+}
+
+func TestInstallBudget_CapChartTimeout(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := installBudget{timeout: 2 * time.Minute, deadline: now.Add(2 * time.Minute)}
+
+	tests := []struct {
+		name         string
+		budget       installBudget
+		chartTimeout string
+		now          time.Time
+		want         string
+	}{
+		{name: "unlimited budget keeps timeout", chartTimeout: "10m", now: now, want: "10m"},
+		{name: "timeout within budget", budget: budget, chartTimeout: "1m", now: now, want: "1m"},
+		{name: "timeout capped to remaining budget", budget: budget, chartTimeout: "10m", now: now, want: "2m0s"},
+		{name: "default timeout capped", budget: budget, chartTimeout: "", now: now.Add(time.Minute), want: "1m0s"},
+		{name: "at least one second", budget: budget, chartTimeout: "10m", now: now.Add(2 * time.Minute), want: "1s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.budget.capChartTimeout(tt.chartTimeout, tt.now); got != tt.want {
+				t.Errorf("capChartTimeout(%q) = %q, want %q", tt.chartTimeout, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreate_WritesInstallSummary(t *testing.T) {
+	installFakeHelm(t)
+	t.Setenv("FAKE_HELM_STATE", t.TempDir())
+
+	tmpDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	input := engineframework.CreateInput{
+		TestID: "test-summary",
+		Stage:  "integration",
+		TmpDir: tmpDir,
+		Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+		Spec: map[string]any{
+			"charts": []any{
+				map[string]any{"name": "first", "sourceType": "local", "path": chartDir},
+				map[string]any{
+					"name":        "second",
+					"sourceType":  "local",
+					"path":        chartDir,
+					"releaseName": "second-release",
+					"namespace":   "apps",
+				},
+			},
+		},
+	}
+
+	artifact, err := Create(context.Background(), input, &Spec{})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if got := artifact.Files[installSummaryFileKey]; got != installSummaryFileName {
+		t.Fatalf("Files[%s] = %q, want %q", installSummaryFileKey, got, installSummaryFileName)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, installSummaryFileName))
+	if err != nil {
+		t.Fatalf("failed to read install summary: %v", err)
+	}
+	var summary installSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("install summary is not valid JSON: %v\n%s", err, data)
+	}
+
+	if summary.TestID != "test-summary" {
+		t.Errorf("summary testID = %q, want %q", summary.TestID, "test-summary")
+	}
+	want := []chartInstallSummary{
+		{Name: "first", ReleaseName: "first"},
+		{Name: "second", ReleaseName: "second-release", Namespace: "apps"},
+	}
+	if len(summary.Charts) != len(want) {
+		t.Fatalf("summary has %d charts, want %d: %s", len(summary.Charts), len(want), data)
+	}
+	for i, w := range want {
+		got := summary.Charts[i]
+		if got.Name != w.Name || got.ReleaseName != w.ReleaseName || got.Namespace != w.Namespace {
+			t.Errorf("chart %d = %+v, want name %q, release %q, namespace %q", i, got, w.Name, w.ReleaseName, w.Namespace)
+		}
+		if got.SourceType != "local" || got.Source != chartDir {
+			t.Errorf("chart %d source = %s %q, want local %q", i, got.SourceType, got.Source, chartDir)
+		}
+		if got.Version != "1.2.3" || got.AppVersion != "4.5.6" || got.Revision != 1 {
+			t.Errorf("chart %d version = %q/%q rev %d, want the resolved release version", i, got.Version, got.AppVersion, got.Revision)
+		}
+		if got.Status != "deployed" {
+			t.Errorf("chart %d status = %q, want %q", i, got.Status, "deployed")
+		}
+		if got.DurationSeconds < 0.2 {
+			t.Errorf("chart %d durationSeconds = %v, want the install duration", i, got.DurationSeconds)
+		}
+	}
+}
+
+func TestCreate_AppliesStageValuesOverlay(t *testing.T) {
+	for _, stage := range []string{"integration", "e2e"} {
+		t.Run(stage, func(t *testing.T) {
+			installFakeHelm(t)
+
+			tmpDir := t.TempDir()
+			helmLog := filepath.Join(tmpDir, "helm.log")
+			t.Setenv("FAKE_HELM_LOG", helmLog)
+
+			kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+			if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			chartDir := filepath.Join(tmpDir, "chart")
+			if err := os.MkdirAll(chartDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			input := engineframework.CreateInput{
+				TestID: "test-overlay",
+				Stage:  stage,
+				TmpDir: tmpDir,
+				Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+				Spec: map[string]any{
+					"charts": []any{
+						map[string]any{
+							"name":        "app",
+							"sourceType":  "local",
+							"path":        chartDir,
+							"valuesFiles": []any{"base.yaml"},
+							"valuesOverlays": map[string]any{
+								"integration": []any{"integration.yaml"},
+								"e2e":         []any{"e2e.yaml"},
+							},
+						},
+					},
+				},
+			}
+
+			if _, err := Create(context.Background(), input, &Spec{}); err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+
+			logContent, err := os.ReadFile(helmLog)
+			if err != nil {
+				t.Fatalf("failed to read fake helm log: %v", err)
+			}
+			args := string(logContent)
+
+			want := "--values base.yaml --values " + stage + ".yaml"
+			if !strings.Contains(args, want) {
+				t.Errorf("helm install args = %q, want %q", args, want)
+			}
+			other := map[string]string{"integration": "e2e", "e2e": "integration"}[stage]
+			if strings.Contains(args, other+".yaml") {
+				t.Errorf("helm install args = %q, want the %s overlay not to be applied", args, other)
+			}
+		})
+	}
+}
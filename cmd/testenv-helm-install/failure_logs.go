@@ -0,0 +1,195 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errReleaseFailed marks install errors raised once helm has run (helm install or upgrade,
+// --wait timeout, readiness checks): only then can the release's pods explain the failure.
+var errReleaseFailed = errors.New("release failed")
+
+// Pod logs collected when a chart fails to install are bounded so that a crash-looping
+// pod cannot fill tmpDir.
+const (
+	// failureLogsTailLines is the number of most recent log lines collected per container.
+	failureLogsTailLines = 500
+	// failureLogsMaxBytes bounds the logs collected per container and the size of each file.
+	failureLogsMaxBytes = 1 << 20
+	// failureExcerptLines is the number of last lines of each diagnostic quoted in the install error.
+	failureExcerptLines = 20
+	// failureExcerptMaxBytes bounds each excerpt quoted in the install error.
+	failureExcerptMaxBytes = 4 << 10
+)
+
+// failureDiagnosticsTimeout bounds each kubectl command collecting diagnostics.
+var failureDiagnosticsTimeout = 30 * time.Second
+
+// kubectlRunner runs kubectl with args and returns its combined output.
+// It is replaced in tests to fake kubectl.
+type kubectlRunner func(ctx context.Context, args []string) ([]byte, error)
+
+// kubectlCombinedOutput runs kubectl with args and returns its combined output.
+func kubectlCombinedOutput(ctx context.Context, args []string) ([]byte, error) {
+	return exec.CommandContext(ctx, "kubectl", args...).CombinedOutput()
+}
+
+// releasePodSelector selects the pods of a release, labeled by charts following the
+// Helm conventions.
+func releasePodSelector(releaseName string) string {
+	return "app.kubernetes.io/instance=" + releaseName
+}
+
+// buildPodLogsArgs builds the kubectl arguments fetching the bounded logs of all the
+// containers of the release's pods.
+func buildPodLogsArgs(kubeconfigPath, releaseName, namespace string) []string {
+	return []string{
+		"--kubeconfig", kubeconfigPath,
+		"logs",
+		"-n", namespace,
+		"-l", releasePodSelector(releaseName),
+		"--all-containers",
+		"--prefix",
+		"--ignore-errors",
+		"--tail", strconv.Itoa(failureLogsTailLines),
+		"--limit-bytes", strconv.Itoa(failureLogsMaxBytes),
+	}
+}
+
+// buildPodDescribeArgs builds the kubectl arguments describing the release's pods,
+// including their events.
+func buildPodDescribeArgs(kubeconfigPath, releaseName, namespace string) []string {
+	return []string{
+		"--kubeconfig", kubeconfigPath,
+		"describe", "pods",
+		"-n", namespace,
+		"-l", releasePodSelector(releaseName),
+	}
+}
+
+// failureDiagnostics are the pod logs and descriptions collected after a failed install.
+type failureDiagnostics struct {
+	// paths are the files holding the full diagnostics, in tmpDir.
+	paths []string
+	// excerpts are the last lines of each diagnostic, quoted in the install error because
+	// tmpDir is removed when the test environment is rolled back.
+	excerpts []string
+}
+
+// wrap appends the diagnostics to the install error.
+func (d failureDiagnostics) wrap(err error) error {
+	if len(d.paths) == 0 && len(d.excerpts) == 0 {
+		return err
+	}
+
+	var b strings.Builder
+	for _, excerpt := range d.excerpts {
+		b.WriteString("\n")
+		b.WriteString(excerpt)
+	}
+	if len(d.paths) > 0 {
+		fmt.Fprintf(&b, "\nfull pod logs and descriptions: %s", strings.Join(d.paths, ", "))
+	}
+	return fmt.Errorf("%w%s", err, b.String())
+}
+
+// collectFailureDiagnostics writes the logs and descriptions of the release's pods to tmpDir
+// after a failed install, and returns the written files with an excerpt of each diagnostic.
+// Collection is best-effort: failures are logged and what could be collected is returned.
+func collectFailureDiagnostics(chart ChartSpec, releaseName, kubeconfigPath, tmpDir string, run kubectlRunner) failureDiagnostics {
+	var diagnostics failureDiagnostics
+	if kubeconfigPath == "" {
+		return diagnostics
+	}
+
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	commands := []struct {
+		name   string
+		title  string
+		suffix string
+		args   []string
+	}{
+		{name: "logs", title: "pod logs", suffix: "pod-logs.txt", args: buildPodLogsArgs(kubeconfigPath, releaseName, namespace)},
+		{name: "describe", title: "pod descriptions", suffix: "pod-describe.txt", args: buildPodDescribeArgs(kubeconfigPath, releaseName, namespace)},
+	}
+
+	for _, c := range commands {
+		ctx, cancel := context.WithTimeout(context.Background(), failureDiagnosticsTimeout)
+		output, err := run(ctx, c.args)
+		cancel()
+		if err != nil {
+			// Keep the output: it explains the failure (e.g. no pod matches the selector)
+			output = fmt.Appendf(output, "\nkubectl %s failed: %v\n", c.name, err)
+		}
+
+		if excerpt := tailLines(output, failureExcerptLines, failureExcerptMaxBytes); excerpt != "" {
+			diagnostics.excerpts = append(diagnostics.excerpts,
+				fmt.Sprintf("--- last %d lines of %s of release %s ---\n%s", failureExcerptLines, c.title, releaseName, excerpt))
+		}
+
+		if tmpDir == "" {
+			continue
+		}
+		path := filepath.Join(tmpDir, fmt.Sprintf("helm-install-failure.%s.%s", releaseName, c.suffix))
+		if err := os.WriteFile(path, truncateOutput(output, failureLogsMaxBytes), 0o600); err != nil {
+			log.Printf("Warning: failed to write install failure diagnostics of %s: %v", releaseName, err)
+			continue
+		}
+		diagnostics.paths = append(diagnostics.paths, path)
+	}
+
+	return diagnostics
+}
+
+// tailLines returns the last n lines of output, at most maxBytes of them, without the trailing newline.
+func tailLines(output []byte, n, maxBytes int) string {
+	output = bytes.TrimRight(output, "\n")
+	if len(output) > maxBytes {
+		output = output[len(output)-maxBytes:]
+		// Drop the partial first line
+		if i := bytes.IndexByte(output, '\n'); i >= 0 {
+			output = output[i+1:]
+		}
+	}
+
+	lines := bytes.Split(output, []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return string(bytes.Join(lines, []byte("\n")))
+}
+
+// truncateOutput bounds output to maxBytes, keeping its beginning and noting the truncation.
+func truncateOutput(output []byte, maxBytes int) []byte {
+	if len(output) <= maxBytes {
+		return output
+	}
+	return fmt.Appendf(output[:maxBytes:maxBytes], "\n... truncated to %d bytes\n", maxBytes)
+}
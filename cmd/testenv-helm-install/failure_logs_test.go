@@ -0,0 +1,234 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
+)
+
+func TestBuildPodLogsArgs(t *testing.T) {
+	got := buildPodLogsArgs("/tmp/kubeconfig", "podinfo", "apps")
+	want := []string{
+		"--kubeconfig", "/tmp/kubeconfig",
+		"logs",
+		"-n", "apps",
+		"-l", "app.kubernetes.io/instance=podinfo",
+		"--all-containers",
+		"--prefix",
+		"--ignore-errors",
+		"--tail", "500",
+		"--limit-bytes", "1048576",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildPodLogsArgs() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestBuildPodDescribeArgs(t *testing.T) {
+	got := buildPodDescribeArgs("/tmp/kubeconfig", "podinfo", "apps")
+	want := []string{
+		"--kubeconfig", "/tmp/kubeconfig",
+		"describe", "pods",
+		"-n", "apps",
+		"-l", "app.kubernetes.io/instance=podinfo",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildPodDescribeArgs() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestCollectFailureDiagnostics(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var calls [][]string
+	run := func(_ context.Context, args []string) ([]byte, error) {
+		calls = append(calls, args)
+		if args[2] == "describe" {
+			return []byte("No resources found in default namespace."), errors.New("exit status 1")
+		}
+		return []byte(strings.Repeat("x", failureLogsMaxBytes+10) + "\npanic: boom\n"), nil
+	}
+
+	chart := ChartSpec{Name: "podinfo"}
+	diagnostics := collectFailureDiagnostics(chart, "podinfo-release", "/tmp/kubeconfig", tmpDir, run)
+
+	wantPaths := []string{
+		filepath.Join(tmpDir, "helm-install-failure.podinfo-release.pod-logs.txt"),
+		filepath.Join(tmpDir, "helm-install-failure.podinfo-release.pod-describe.txt"),
+	}
+	if !reflect.DeepEqual(diagnostics.paths, wantPaths) {
+		t.Fatalf("paths = %v, want %v", diagnostics.paths, wantPaths)
+	}
+
+	// The namespace defaults to "default" and the release name selects the pods
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 kubectl calls, got %d", len(calls))
+	}
+	if !reflect.DeepEqual(calls[0], buildPodLogsArgs("/tmp/kubeconfig", "podinfo-release", "default")) {
+		t.Errorf("unexpected logs call %v", calls[0])
+	}
+
+	logs, err := os.ReadFile(diagnostics.paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) > failureLogsMaxBytes+100 || !strings.HasSuffix(string(logs), "... truncated to 1048576 bytes\n") {
+		t.Errorf("expected logs truncated to %d bytes, got %d bytes", failureLogsMaxBytes, len(logs))
+	}
+
+	describe, err := os.ReadFile(diagnostics.paths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(describe), "No resources found") || !strings.Contains(string(describe), "kubectl describe failed: exit status 1") {
+		t.Errorf("unexpected describe output %q", describe)
+	}
+
+	// The error quotes bounded excerpts: the full diagnostics are removed with tmpDir on rollback
+	err = diagnostics.wrap(errors.New("helm install failed"))
+	msg := err.Error()
+	if !strings.Contains(msg, "panic: boom") || !strings.Contains(msg, "kubectl describe failed: exit status 1") {
+		t.Errorf("expected the error to quote the diagnostics, got %q", msg)
+	}
+	if len(msg) > 2*failureExcerptMaxBytes+1024 {
+		t.Errorf("expected a bounded error, got %d bytes", len(msg))
+	}
+	if !strings.Contains(msg, wantPaths[0]) {
+		t.Errorf("expected the error to list the diagnostic files, got %q", msg)
+	}
+}
+
+func TestCollectFailureDiagnostics_NoCluster(t *testing.T) {
+	run := func(_ context.Context, _ []string) ([]byte, error) {
+		t.Fatal("kubectl must not run without a kubeconfig")
+		return nil, nil
+	}
+	diagnostics := collectFailureDiagnostics(ChartSpec{Name: "podinfo"}, "podinfo", "", t.TempDir(), run)
+	if len(diagnostics.paths) != 0 || len(diagnostics.excerpts) != 0 {
+		t.Errorf("expected no diagnostics, got %+v", diagnostics)
+	}
+
+	installErr := errors.New("helm install failed")
+	if err := diagnostics.wrap(installErr); err != installErr {
+		t.Errorf("wrap() = %v, want the install error unchanged", err)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		n        int
+		maxBytes int
+		want     string
+	}{
+		{name: "empty", output: "", n: 3, maxBytes: 100, want: ""},
+		{name: "fewer lines", output: "a\nb\n", n: 3, maxBytes: 100, want: "a\nb"},
+		{name: "last lines", output: "a\nb\nc\nd\n", n: 2, maxBytes: 100, want: "c\nd"},
+		{name: "bounded bytes drop the partial line", output: "aaaa\nbbbb\ncc\n", n: 5, maxBytes: 6, want: "cc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tailLines([]byte(tt.output), tt.n, tt.maxBytes); got != tt.want {
+				t.Errorf("tailLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeKubectlScript stands in for kubectl: it appends its invocations to $FAKE_KUBECTL_LOG
+// and prints a pod log line.
+const fakeKubectlScript = `#!/bin/sh
+echo "$@" >> "$FAKE_KUBECTL_LOG"
+echo "podinfo: panic: missing config"
+`
+
+// installFakeKubectl puts a fake kubectl binary first in PATH and returns its invocation log.
+func installFakeKubectl(t *testing.T) string {
+	t.Helper()
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "kubectl"), []byte(fakeKubectlScript), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	logPath := filepath.Join(t.TempDir(), "kubectl.log")
+	t.Setenv("FAKE_KUBECTL_LOG", logPath)
+	return logPath
+}
+
+func TestCreate_FailureDiagnosticsOnlyWhenHelmRan(t *testing.T) {
+	installFakeHelm(t)
+	kubectlLog := installFakeKubectl(t)
+
+	tmpDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(chart map[string]any) error {
+		t.Helper()
+		input := engineframework.CreateInput{
+			TestID: "test-failure-diagnostics",
+			Stage:  "integration",
+			TmpDir: tmpDir,
+			Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+			Spec:   map[string]any{"charts": []any{chart}},
+		}
+		_, err := Create(context.Background(), input, &Spec{})
+		return err
+	}
+
+	t.Run("chart source failure", func(t *testing.T) {
+		err := create(map[string]any{"name": "podinfo", "sourceType": "git", "chartPath": "charts/podinfo"})
+		if err == nil {
+			t.Fatal("expected Create to fail")
+		}
+		if _, statErr := os.Stat(kubectlLog); !os.IsNotExist(statErr) {
+			t.Errorf("expected no diagnostics before helm ran, kubectl log: %v", statErr)
+		}
+	})
+
+	t.Run("helm failure", func(t *testing.T) {
+		t.Setenv("FAKE_HELM_FAIL", "1")
+		err := create(map[string]any{"name": "podinfo", "sourceType": "local", "path": chartDir})
+		if err == nil {
+			t.Fatal("expected Create to fail")
+		}
+		if !strings.Contains(err.Error(), "podinfo: panic: missing config") {
+			t.Errorf("expected the error to quote the pod logs, got %v", err)
+		}
+		if _, statErr := os.Stat(kubectlLog); statErr != nil {
+			t.Errorf("expected kubectl to collect diagnostics: %v", statErr)
+		}
+	})
+}
@@ -0,0 +1,292 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultGCSEndpoint is the Google Cloud Storage JSON API endpoint.
+	defaultGCSEndpoint = "https://storage.googleapis.com"
+	// defaultGCSTokenURI is the OAuth2 token endpoint used when the service account key omits token_uri.
+	defaultGCSTokenURI = "https://oauth2.googleapis.com/token"
+	// gcsReadOnlyScope is the OAuth2 scope requested for service account tokens.
+	gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+)
+
+// gcsServiceAccountKey is the subset of a service account JSON key used to mint access tokens.
+type gcsServiceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// GCSClient downloads objects from Google Cloud Storage (or fake-gcs-server) using the JSON API.
+// Requests are authorized with an OAuth2 access token, a service account key, or sent anonymously
+// for public buckets.
+type GCSClient struct {
+	endpoint   string
+	token      func(ctx context.Context) (string, error)
+	httpClient *http.Client
+}
+
+// NewGCSClient creates an anonymous GCS client for public buckets.
+// If endpoint is empty, it defaults to "https://storage.googleapis.com".
+func NewGCSClient(endpoint string) (*GCSClient, error) {
+	endpoint = normalizeGCSEndpoint(endpoint)
+	if err := validateGCSEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return &GCSClient{
+		endpoint:   endpoint,
+		httpClient: http.DefaultClient,
+	}, nil
+}
+
+// NewGCSClientWithAccessToken creates a GCS client authorized with a static OAuth2 access token.
+func NewGCSClientWithAccessToken(endpoint, accessToken string) (*GCSClient, error) {
+	client, err := NewGCSClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client.token = func(context.Context) (string, error) { return accessToken, nil }
+	return client, nil
+}
+
+// NewGCSClientWithServiceAccountKey creates a GCS client that exchanges a service account
+// JSON key for an access token before downloading.
+func NewGCSClientWithServiceAccountKey(endpoint string, keyJSON []byte) (*GCSClient, error) {
+	client, err := NewGCSClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parseGCSServiceAccountKey(keyJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	client.token = func(ctx context.Context) (string, error) {
+		return fetchGCSServiceAccountToken(ctx, client.httpClient, key)
+	}
+	return client, nil
+}
+
+// DownloadFile downloads an object from a GCS bucket to a local file.
+// Returns an error if the download fails.
+func (c *GCSClient) DownloadFile(bucket, object, destPath string) error {
+	// Validate inputs
+	if bucket == "" {
+		return fmt.Errorf("bucket name is required")
+	}
+	if object == "" {
+		return fmt.Errorf("object name is required")
+	}
+	if destPath == "" {
+		return fmt.Errorf("destination path is required")
+	}
+
+	// Create context with timeout (5 minutes for download)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.objectURL(bucket, object), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if c.token != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to obtain GCS access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("GCS download timed out after 5 minutes")
+		}
+		return fmt.Errorf("failed to get object from GCS: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Warning: failed to close GCS response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to get object from GCS: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return writeObjectToFile(resp.Body, destPath)
+}
+
+// objectURL returns the JSON API media download URL of an object.
+func (c *GCSClient) objectURL(bucket, object string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media",
+		c.endpoint, url.PathEscape(bucket), url.PathEscape(object))
+}
+
+// parseGCSServiceAccountKey parses and validates a service account JSON key.
+func parseGCSServiceAccountKey(keyJSON []byte) (gcsServiceAccountKey, error) {
+	var key gcsServiceAccountKey
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return gcsServiceAccountKey{}, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+
+	if key.Type != "service_account" {
+		return gcsServiceAccountKey{}, fmt.Errorf("unsupported credentials type %q: only service_account keys are supported", key.Type)
+	}
+	if key.ClientEmail == "" {
+		return gcsServiceAccountKey{}, fmt.Errorf("client_email is required in service account key")
+	}
+	if key.PrivateKey == "" {
+		return gcsServiceAccountKey{}, fmt.Errorf("private_key is required in service account key")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = defaultGCSTokenURI
+	}
+
+	return key, nil
+}
+
+// buildGCSServiceAccountAssertion builds the signed JWT used in the OAuth2 JWT bearer grant.
+func buildGCSServiceAccountAssertion(key gcsServiceAccountKey, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("private_key is not PEM-encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse private_key: %w", err)
+		}
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("private_key must be an RSA key")
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": gcsReadOnlyScope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign assertion: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// fetchGCSServiceAccountToken exchanges a service account key for an OAuth2 access token.
+func fetchGCSServiceAccountToken(ctx context.Context, httpClient *http.Client, key gcsServiceAccountKey) (string, error) {
+	assertion, err := buildGCSServiceAccountAssertion(key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response did not contain an access_token")
+	}
+
+	return token.AccessToken, nil
+}
+
+// validateGCSEndpoint validates that the endpoint is a valid HTTP/HTTPS URL.
+func validateGCSEndpoint(endpoint string) error {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return fmt.Errorf("endpoint must start with http:// or https://")
+	}
+
+	return nil
+}
+
+// normalizeGCSEndpoint returns the endpoint without a trailing slash,
+// defaulting to "https://storage.googleapis.com" if empty.
+func normalizeGCSEndpoint(endpoint string) string {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return defaultGCSEndpoint
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
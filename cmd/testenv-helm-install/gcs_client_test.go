@@ -0,0 +1,218 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeGCSEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "", want: defaultGCSEndpoint},
+		{endpoint: "  ", want: defaultGCSEndpoint},
+		{endpoint: "http://localhost:4443/", want: "http://localhost:4443"},
+		{endpoint: "https://storage.googleapis.com", want: "https://storage.googleapis.com"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeGCSEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("normalizeGCSEndpoint(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestGCSClient_DownloadFile_AccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/storage/v1/b/my-charts/o/myapp%2Fmyapp-1.0.0.tgz" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("alt") != "media" {
+			t.Errorf("alt query parameter = %q, want media", r.URL.Query().Get("alt"))
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer ya29.token" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer ya29.token")
+		}
+		_, _ = w.Write([]byte("chart-content"))
+	}))
+	defer server.Close()
+
+	client, err := NewGCSClientWithAccessToken(server.URL, "ya29.token")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "myapp-1.0.0.tgz")
+	if err := client.DownloadFile("my-charts", "myapp/myapp-1.0.0.tgz", destPath); err != nil {
+		t.Fatalf("DownloadFile() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("failed to read downloaded file: %v", err)
+	}
+	if string(content) != "chart-content" {
+		t.Errorf("downloaded content = %q, want %q", content, "chart-content")
+	}
+}
+
+func TestGCSClient_DownloadFile_ServiceAccountKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("failed to parse token request: %v", err)
+			}
+			verifyGCSAssertion(t, r.PostForm.Get("assertion"), &privateKey.PublicKey)
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "minted-token"})
+		default:
+			if got := r.Header.Get("Authorization"); got != "Bearer minted-token" {
+				t.Errorf("Authorization = %q, want %q", got, "Bearer minted-token")
+			}
+			_, _ = w.Write([]byte("chart-content"))
+		}
+	}))
+	defer server.Close()
+
+	keyJSON, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "charts@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal key JSON: %v", err)
+	}
+
+	client, err := NewGCSClientWithServiceAccountKey(server.URL, keyJSON)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "chart.tgz")
+	if err := client.DownloadFile("my-charts", "chart.tgz", destPath); err != nil {
+		t.Fatalf("DownloadFile() unexpected error: %v", err)
+	}
+}
+
+func verifyGCSAssertion(t *testing.T, assertion string, publicKey *rsa.PublicKey) {
+	t.Helper()
+
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("assertion has %d parts, want 3", len(parts))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("assertion signature is invalid: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("failed to parse claims: %v", err)
+	}
+	if claims["iss"] != "charts@project.iam.gserviceaccount.com" {
+		t.Errorf("iss = %v, want service account email", claims["iss"])
+	}
+	if claims["scope"] != gcsReadOnlyScope {
+		t.Errorf("scope = %v, want %q", claims["scope"], gcsReadOnlyScope)
+	}
+	if exp, ok := claims["exp"].(float64); !ok || int64(exp) <= time.Now().Unix() {
+		t.Errorf("exp = %v, want a future timestamp", claims["exp"])
+	}
+}
+
+func TestParseGCSServiceAccountKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyJSON string
+		wantErr string
+	}{
+		{
+			name:    "invalid JSON",
+			keyJSON: "not json",
+			wantErr: "failed to parse",
+		},
+		{
+			name:    "authorized user credentials",
+			keyJSON: `{"type":"authorized_user"}`,
+			wantErr: "only service_account keys are supported",
+		},
+		{
+			name:    "missing client_email",
+			keyJSON: `{"type":"service_account","private_key":"x"}`,
+			wantErr: "client_email is required",
+		},
+		{
+			name:    "missing private_key",
+			keyJSON: `{"type":"service_account","client_email":"a@b"}`,
+			wantErr: "private_key is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseGCSServiceAccountKey([]byte(tt.keyJSON))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseGCSServiceAccountKey() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	key, err := parseGCSServiceAccountKey([]byte(`{"type":"service_account","client_email":"a@b","private_key":"x"}`))
+	if err != nil {
+		t.Fatalf("parseGCSServiceAccountKey() unexpected error: %v", err)
+	}
+	if key.TokenURI != defaultGCSTokenURI {
+		t.Errorf("TokenURI = %q, want %q", key.TokenURI, defaultGCSTokenURI)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single compiled .gitignore-style pattern.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher matches slash-separated paths against .gitignore-style patterns.
+// Supported syntax: comments (#), negation (!), directory suffixes (dir/),
+// anchoring (a leading or inner /), and the *, ? and ** wildcards.
+// As with git, the last matching pattern wins.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// compileIgnorePatterns compiles .gitignore-style patterns into an ignoreMatcher.
+func compileIgnorePatterns(patterns []string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	for _, raw := range patterns {
+		pattern := strings.TrimSpace(raw)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+
+		// A pattern containing a slash is relative to the root; otherwise it matches at any depth.
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid ignore pattern %q", raw)
+		}
+
+		expr := globToRegexp(pattern)
+		if !anchored {
+			expr = "(?:.*/)?" + expr
+		}
+
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", raw, err)
+		}
+		rule.re = re
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// globToRegexp translates a glob pattern into a regular expression.
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether the slash-separated relative path is ignored.
+func (m *ignoreMatcher) Match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(relPath) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// chartGuard protects the chart essentials (Chart.yaml and templates/) from removal.
+type chartGuard struct {
+	chartYAML string
+	templates string
+}
+
+// newChartGuard creates a chartGuard for the chart at chartPath (relative to the repository root).
+func newChartGuard(chartPath string) chartGuard {
+	chartDir := filepath.ToSlash(filepath.Clean(chartPath))
+	if chartDir == "." {
+		return chartGuard{chartYAML: "Chart.yaml", templates: "templates"}
+	}
+	return chartGuard{chartYAML: chartDir + "/Chart.yaml", templates: chartDir + "/templates"}
+}
+
+// protected reports whether relPath is a chart essential that must never be removed.
+func (g chartGuard) protected(relPath string) bool {
+	return relPath == g.chartYAML || relPath == g.templates || strings.HasPrefix(relPath, g.templates+"/")
+}
+
+// containsProtected reports whether the directory relPath contains a chart essential.
+func (g chartGuard) containsProtected(relPath string) bool {
+	return strings.HasPrefix(g.chartYAML, relPath+"/") || strings.HasPrefix(g.templates, relPath+"/")
+}
+
+// applyIgnorePatterns removes the files matching .gitignore-style patterns from a cloned repository.
+// The chart's Chart.yaml and templates/ directory are never removed, nor are the directories
+// containing them (their other contents are still removed when ignored).
+func applyIgnorePatterns(repoPath, chartPath string, ignorePatterns []string) error {
+	if len(ignorePatterns) == 0 {
+		return nil
+	}
+
+	matcher, err := compileIgnorePatterns(ignorePatterns)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	if err := removeIgnored(repoPath, "", false, matcher, newChartGuard(chartPath), &removed); err != nil {
+		return err
+	}
+
+	log.Printf("Removed %d path(s) matching ignorePaths", removed)
+	return nil
+}
+
+// removeIgnored walks dir (relDir relative to the repository root) and removes ignored entries.
+// Everything below an ignored directory is ignored, as with git.
+func removeIgnored(root, relDir string, parentIgnored bool, matcher *ignoreMatcher, guard chartGuard, removed *int) error {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(relDir)))
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", relDir, err)
+	}
+
+	for _, entry := range entries {
+		relPath := entry.Name()
+		if relDir != "" {
+			relPath = relDir + "/" + entry.Name()
+		}
+		isDir := entry.IsDir()
+		ignored := parentIgnored || matcher.Match(relPath, isDir)
+
+		switch {
+		case guard.protected(relPath):
+			if ignored {
+				log.Printf("Warning: keeping %s matched by ignorePaths (required by the chart)", relPath)
+			}
+		case isDir && guard.containsProtected(relPath):
+			if err := removeIgnored(root, relPath, ignored, matcher, guard, removed); err != nil {
+				return err
+			}
+		case ignored:
+			if err := os.RemoveAll(filepath.Join(root, filepath.FromSlash(relPath))); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", relPath, err)
+			}
+			*removed++
+		case isDir:
+			if err := removeIgnored(root, relPath, false, matcher, guard, removed); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{name: "star matches basename at any depth", patterns: []string{"*.log"}, path: "a/b/debug.log", want: true},
+		{name: "star does not cross directories", patterns: []string{"docs/*.md"}, path: "docs/api/index.md", want: false},
+		{name: "star within anchored directory", patterns: []string{"docs/*.md"}, path: "docs/index.md", want: true},
+		{name: "leading slash anchors to root", patterns: []string{"/test"}, path: "charts/test", isDir: true, want: false},
+		{name: "anchored root match", patterns: []string{"/test"}, path: "test", isDir: true, want: true},
+		{name: "double star prefix", patterns: []string{"**/testdata"}, path: "a/b/testdata", isDir: true, want: true},
+		{name: "double star suffix", patterns: []string{"vendor/**"}, path: "vendor/x/y.go", want: true},
+		{name: "double star middle", patterns: []string{"a/**/z.txt"}, path: "a/z.txt", want: true},
+		{name: "double star middle nested", patterns: []string{"a/**/z.txt"}, path: "a/b/c/z.txt", want: true},
+		{name: "directory suffix matches directory", patterns: []string{"tmp/"}, path: "x/tmp", isDir: true, want: true},
+		{name: "directory suffix skips file", patterns: []string{"tmp/"}, path: "x/tmp", want: false},
+		{name: "question mark", patterns: []string{"file?.txt"}, path: "file1.txt", want: true},
+		{name: "negation re-includes", patterns: []string{"*.yaml", "!values.yaml"}, path: "values.yaml", want: false},
+		{name: "last match wins", patterns: []string{"!values.yaml", "*.yaml"}, path: "values.yaml", want: true},
+		{name: "comments and blanks skipped", patterns: []string{"# *.md", "", "  "}, path: "README.md", want: false},
+		{name: "dots are literal", patterns: []string{"*.tar.gz"}, path: "chartXtarXgz", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := compileIgnorePatterns(tt.patterns)
+			if err != nil {
+				t.Fatalf("compileIgnorePatterns() error = %v", err)
+			}
+			if got := m.Match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileIgnorePatterns_Invalid(t *testing.T) {
+	for _, pattern := range []string{"/", "!/"} {
+		if _, err := compileIgnorePatterns([]string{pattern}); err == nil {
+			t.Errorf("compileIgnorePatterns(%q) expected error", pattern)
+		}
+	}
+}
+
+// writeTree creates the given files (slash-separated paths) under root.
+func writeTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// listTree returns the slash-separated paths of all files under root.
+func listTree(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestApplyIgnorePatterns_RemovesMatches(t *testing.T) {
+	repo := t.TempDir()
+	writeTree(t, repo,
+		"README.md",
+		"secrets/token.txt",
+		"charts/app/Chart.yaml",
+		"charts/app/values.yaml",
+		"charts/app/ci-values.yaml",
+		"charts/app/templates/deployment.yaml",
+		"charts/app/tests/data.json",
+		"charts/app/debug.log",
+	)
+
+	err := applyIgnorePatterns(repo, "charts/app", []string{"*.md", "secrets/", "**/tests/", "*.log", "*values.yaml", "!values.yaml"})
+	if err != nil {
+		t.Fatalf("applyIgnorePatterns() error = %v", err)
+	}
+
+	want := []string{
+		"charts/app/Chart.yaml",
+		"charts/app/templates/deployment.yaml",
+		"charts/app/values.yaml",
+	}
+	if got := listTree(t, repo); !slices.Equal(got, want) {
+		t.Errorf("remaining files = %v, want %v", got, want)
+	}
+}
+
+func TestApplyIgnorePatterns_ProtectsChartEssentials(t *testing.T) {
+	tests := []struct {
+		name      string
+		chartPath string
+		patterns  []string
+		files     []string
+		want      []string
+	}{
+		{
+			name:      "ignore everything keeps Chart.yaml and templates",
+			chartPath: "charts/app",
+			patterns:  []string{"*"},
+			files: []string{
+				"LICENSE",
+				"charts/app/Chart.yaml",
+				"charts/app/values.yaml",
+				"charts/app/templates/service.yaml",
+				"charts/other/Chart.yaml",
+			},
+			want: []string{"charts/app/Chart.yaml", "charts/app/templates/service.yaml"},
+		},
+		{
+			name:      "ignoring the chart directory keeps its essentials",
+			chartPath: "charts/app",
+			patterns:  []string{"charts/"},
+			files: []string{
+				"charts/app/Chart.yaml",
+				"charts/app/README.md",
+				"charts/app/templates/_helpers.tpl",
+			},
+			want: []string{"charts/app/Chart.yaml", "charts/app/templates/_helpers.tpl"},
+		},
+		{
+			name:      "explicit patterns on essentials are refused",
+			chartPath: ".",
+			patterns:  []string{"Chart.yaml", "templates/", "*.tpl"},
+			files: []string{
+				"Chart.yaml",
+				"templates/_helpers.tpl",
+				"templates/nested/cm.yaml",
+				"extra.tpl",
+			},
+			want: []string{"Chart.yaml", "templates/_helpers.tpl", "templates/nested/cm.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			writeTree(t, repo, tt.files...)
+
+			if err := applyIgnorePatterns(repo, tt.chartPath, tt.patterns); err != nil {
+				t.Fatalf("applyIgnorePatterns() error = %v", err)
+			}
+			if got := listTree(t, repo); !slices.Equal(got, tt.want) {
+				t.Errorf("remaining files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// installTimeoutEnvVar sets the overall install timeout budget when spec.installTimeout is not set.
+const installTimeoutEnvVar = "TESTENV_HELM_INSTALL_TIMEOUT"
+
+// errInstallBudgetExceeded is returned when the overall install timeout budget is exhausted.
+var errInstallBudgetExceeded = errors.New("install timeout budget exceeded")
+
+// installBudget bounds the total time spent installing charts in Create.
+// The zero value is an unlimited budget.
+type installBudget struct {
+	timeout  time.Duration
+	deadline time.Time
+}
+
+// newInstallBudget starts the install timeout budget from spec.installTimeout, falling back to
+// TESTENV_HELM_INSTALL_TIMEOUT from the testenv environment, then from the process environment.
+// Returns an unlimited budget if none is configured.
+func newInstallBudget(spec *Spec, env map[string]string, now time.Time) (installBudget, error) {
+	value, source := "", ""
+	switch {
+	case spec != nil && spec.InstallTimeout != "":
+		value, source = spec.InstallTimeout, "spec.installTimeout"
+	case env[installTimeoutEnvVar] != "":
+		value, source = env[installTimeoutEnvVar], installTimeoutEnvVar
+	case os.Getenv(installTimeoutEnvVar) != "":
+		value, source = os.Getenv(installTimeoutEnvVar), installTimeoutEnvVar
+	default:
+		return installBudget{}, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return installBudget{}, fmt.Errorf("invalid %s %q: %w", source, value, err)
+	}
+	if timeout <= 0 {
+		return installBudget{}, fmt.Errorf("invalid %s %q: must be positive", source, value)
+	}
+
+	return installBudget{timeout: timeout, deadline: now.Add(timeout)}, nil
+}
+
+// limited reports whether the budget bounds the install time.
+func (b installBudget) limited() bool {
+	return b.timeout > 0
+}
+
+// exceeded reports whether the budget is exhausted at now.
+func (b installBudget) exceeded(now time.Time) bool {
+	return b.limited() && !now.Before(b.deadline)
+}
+
+// capChartTimeout lowers the chart timeout to the remaining budget so that an install
+// in progress cannot outlive the budget. Timeouts within the budget are left unchanged.
+func (b installBudget) capChartTimeout(chartTimeout string, now time.Time) string {
+	if !b.limited() {
+		return chartTimeout
+	}
+
+	current, err := time.ParseDuration(chartTimeout)
+	if chartTimeout == "" || err != nil {
+		current = 5 * time.Minute // helm install default used by installChart
+	}
+
+	remaining := b.deadline.Sub(now).Round(time.Second)
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+	if remaining >= current {
+		return chartTimeout
+	}
+	return remaining.String()
+}
+
+// error reports the exhausted budget with the charts installed so far and those that were not.
+func (b installBudget) error(installed []string, remaining []ChartSpec) error {
+	installedList := "none"
+	if len(installed) > 0 {
+		installedList = strings.Join(installed, ", ")
+	}
+	remainingNames := make([]string, 0, len(remaining))
+	for _, chart := range remaining {
+		remainingNames = append(remainingNames, chart.Name)
+	}
+	return fmt.Errorf("%w: %s elapsed; installed so far: %s; not installed: %s",
+		errInstallBudgetExceeded, b.timeout, installedList, strings.Join(remainingNames, ", "))
+}
@@ -25,13 +25,16 @@ import (
 
 // buildKubectlGetCommand builds the kubectl get command arguments for fetching a resource.
 // Returns the full command arguments including kubeconfig, resource type, name, namespace, and output format.
+// The namespace is omitted when empty, e.g. for cluster-scoped resources.
 func buildKubectlGetCommand(kubeconfigPath, resource, resName, namespace string) []string {
-	return []string{
+	args := []string{
 		"--kubeconfig", kubeconfigPath,
 		"get", resource, resName,
-		"-n", namespace,
-		"-o", "json",
 	}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	return append(args, "-o", "json")
 }
 
 // parseConfigMapJSON parses kubectl JSON output and extracts the .data field.
@@ -55,6 +55,13 @@ func TestBuildKubectlGetCommand(t *testing.T) {
 			namespace:      "production",
 			wantArgs:       []string{"--kubeconfig", "/home/user/.kube/config", "get", "configmap", "app-config", "-n", "production", "-o", "json"},
 		},
+		{
+			name:           "get cluster-scoped CRD",
+			kubeconfigPath: "/tmp/kubeconfig",
+			resource:       "CustomResourceDefinition",
+			resName:        "certificates.cert-manager.io",
+			wantArgs:       []string{"--kubeconfig", "/tmp/kubeconfig", "get", "CustomResourceDefinition", "certificates.cert-manager.io", "-o", "json"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,6 +72,127 @@ func TestChartSpec(t *testing.T) {
 	}
 }
 
+func TestBuildHelmInstallArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   ChartSpec
+		upgrade bool
+		want    []string
+	}{
+		{
+			name:    "install mode",
+			chart:   ChartSpec{Namespace: "ns", CreateNamespace: true, Version: "1.0.0"},
+			upgrade: false,
+			want: []string{
+				"install", "release", "repo/chart",
+				"--kubeconfig", "/tmp/kubeconfig",
+				"--version", "1.0.0",
+				"--namespace", "ns", "--create-namespace",
+				"--timeout", "5m",
+				"--wait",
+			},
+		},
+		{
+			name:    "upgrade mode",
+			chart:   ChartSpec{Namespace: "ns", CreateNamespace: true, Version: "1.0.0"},
+			upgrade: true,
+			want: []string{
+				"upgrade", "--install", "release", "repo/chart",
+				"--kubeconfig", "/tmp/kubeconfig",
+				"--version", "1.0.0",
+				"--namespace", "ns", "--create-namespace",
+				"--timeout", "5m",
+				"--wait",
+			},
+		},
+		{
+			name:    "upgrade mode with reset values and flags",
+			chart:   ChartSpec{DisableWait: true, ForceUpgrade: true, DisableHooks: true, ResetValues: true},
+			upgrade: true,
+			want: []string{
+				"upgrade", "--install", "release", "repo/chart",
+				"--kubeconfig", "/tmp/kubeconfig",
+				"--timeout", "5m",
+				"--force",
+				"--no-hooks",
+				"--reset-values",
+			},
+		},
+		{
+			name:    "reset values ignored in install mode",
+			chart:   ChartSpec{ResetValues: true},
+			upgrade: false,
+			want: []string{
+				"install", "release", "repo/chart",
+				"--kubeconfig", "/tmp/kubeconfig",
+				"--timeout", "5m",
+				"--wait",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildHelmInstallArgs(tt.chart, "release", "repo/chart", "/tmp/kubeconfig", "5m", tt.upgrade)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("buildHelmInstallArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHelmReleaseStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "deployed",
+			data: `{"name":"release","info":{"status":"deployed"},"version":1}`,
+			want: "deployed",
+		},
+		{
+			name: "pending install",
+			data: `{"name":"release","info":{"status":"pending-install"},"version":1}`,
+			want: "pending-install",
+		},
+		{
+			name:    "invalid json",
+			data:    `Error: release: not found`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHelmReleaseStatus([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHelmReleaseStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseHelmReleaseStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPendingReleaseStatus(t *testing.T) {
+	for status, want := range map[string]bool{
+		"":                 false,
+		"deployed":         false,
+		"failed":           false,
+		"pending-install":  true,
+		"pending-upgrade":  true,
+		"pending-rollback": true,
+	} {
+		if got := isPendingReleaseStatus(status); got != want {
+			t.Errorf("isPendingReleaseStatus(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
+
 func TestExtractRepoNameFromURL(t *testing.T) {
 	tests := []struct {
 		name string
@@ -524,7 +645,7 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			// Create a temporary directory
 			repoPath := t.TempDir()
 
-			err := applyIgnorePatterns(repoPath, tt.ignorePatterns)
+			err := applyIgnorePatterns(repoPath, ".", tt.ignorePatterns)
 
 			if tt.wantErr {
 				if err == nil {
@@ -1044,10 +1165,10 @@ func TestValidateOCISource(t *testing.T) {
 
 func TestVerifyOCISignature(t *testing.T) {
 	tests := []struct {
-		name          string
-		chart         ChartSpec
-		wantErr       bool
-		expectWarning bool
+		name        string
+		chart       ChartSpec
+		wantErr     bool
+		errContains string
 	}{
 		{
 			name: "no OCIProvider set - skip verification",
@@ -1055,39 +1176,150 @@ func TestVerifyOCISignature(t *testing.T) {
 				Name:        "test-chart",
 				OCIProvider: "",
 			},
-			wantErr:       false,
-			expectWarning: false,
+			wantErr: false,
 		},
 		{
-			name: "cosign provider - log warning",
+			name: "cosign provider without public key",
 			chart: ChartSpec{
 				Name:        "test-chart",
+				URL:         "oci://ghcr.io/org/charts/app:1.0.0",
 				OCIProvider: "cosign",
 			},
-			wantErr:       false,
-			expectWarning: true,
+			wantErr:     true,
+			errContains: "requires a public key",
 		},
 		{
-			name: "notation provider - log warning",
+			name: "cosign provider with version range",
+			chart: ChartSpec{
+				Name:        "test-chart",
+				URL:         "oci://ghcr.io/org/charts/app",
+				Version:     "^1.0.0",
+				OCIProvider: "cosign",
+				CosignKey:   "cosign.pub",
+			},
+			wantErr:     true,
+			errContains: "exact chart version",
+		},
+		{
+			name: "notation provider - not implemented",
 			chart: ChartSpec{
 				Name:        "test-chart",
 				OCIProvider: "notation",
 			},
-			wantErr:       false,
-			expectWarning: true,
+			wantErr:     true,
+			errContains: "not implemented",
+		},
+		{
+			name: "unknown provider",
+			chart: ChartSpec{
+				Name:        "test-chart",
+				OCIProvider: "sigstore",
+			},
+			wantErr:     true,
+			errContains: "unknown ociProvider",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifyOCISignature(tt.chart)
+			err := verifyOCISignature("", tt.chart)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("verifyOCISignature() error = %v, wantErr %v", err, tt.wantErr)
+				t.Fatalf("verifyOCISignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("verifyOCISignature() error = %v, want it to contain %q", err, tt.errContains)
 			}
+		})
+	}
+}
 
-			// Note: We can't easily test the warning log output in this unit test
-			// In integration tests, we could capture log output to verify warnings
+func TestCosignImageRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   ChartSpec
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "tag in URL",
+			chart: ChartSpec{URL: "oci://ghcr.io/org/charts/app:1.2.3"},
+			want:  "ghcr.io/org/charts/app:1.2.3",
+		},
+		{
+			name:  "digest in URL",
+			chart: ChartSpec{URL: "oci://ghcr.io/org/charts/app@sha256:abc123"},
+			want:  "ghcr.io/org/charts/app@sha256:abc123",
+		},
+		{
+			name:  "version field appended",
+			chart: ChartSpec{URL: "oci://ghcr.io/org/charts/app", Version: "1.2.3"},
+			want:  "ghcr.io/org/charts/app:1.2.3",
+		},
+		{
+			name:  "defaults to latest",
+			chart: ChartSpec{URL: "oci://ghcr.io/org/charts/app"},
+			want:  "ghcr.io/org/charts/app:latest",
+		},
+		{
+			name:  "registry with port",
+			chart: ChartSpec{URL: "oci://localhost:5000/charts/app", Version: "0.1.0"},
+			want:  "localhost:5000/charts/app:0.1.0",
+		},
+		{
+			name:    "version range rejected",
+			chart:   ChartSpec{URL: "oci://ghcr.io/org/charts/app", Version: ">=1.0.0"},
+			wantErr: true,
+		},
+		{
+			name:    "not an OCI URL",
+			chart:   ChartSpec{URL: "https://example.com/charts"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cosignImageRef(tt.chart)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cosignImageRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("cosignImageRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCosignVerifyArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		imageRef string
+		keyRef   string
+		insecure bool
+		want     []string
+	}{
+		{
+			name:     "key file",
+			imageRef: "ghcr.io/org/charts/app:1.0.0",
+			keyRef:   "/tmp/cosign.pub",
+			want:     []string{"verify", "--key", "/tmp/cosign.pub", "ghcr.io/org/charts/app:1.0.0"},
+		},
+		{
+			name:     "insecure registry",
+			imageRef: "localhost:5000/charts/app:1.0.0",
+			keyRef:   "k8s://ns/key",
+			insecure: true,
+			want:     []string{"verify", "--key", "k8s://ns/key", "--allow-insecure-registry", "localhost:5000/charts/app:1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildCosignVerifyArgs(tt.imageRef, tt.keyRef, tt.insecure)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("buildCosignVerifyArgs() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
@@ -1486,6 +1718,510 @@ func TestValidateS3Source(t *testing.T) {
 	}
 }
 
+func TestValidateAzureSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   ChartSpec
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name: "valid azblob source",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+				ChartPath:          "myapp/myapp-1.0.0.tgz",
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid azurite source with tar.gz chart",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "http://127.0.0.1:10000/devstoreaccount1",
+				AzureContainerName: "helm-charts-2",
+				ChartPath:          "chart.tar.gz",
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing URL",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				AzureContainerName: "charts",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "url is required",
+		},
+		{
+			name: "invalid URL format",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "invalid url",
+		},
+		{
+			name: "missing container name",
+			chart: ChartSpec{
+				SourceType: "azblob",
+				URL:        "https://myaccount.blob.core.windows.net",
+				ChartPath:  "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "azureContainerName is required",
+		},
+		{
+			name: "container name too short",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "ch",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "between 3 and 63 characters",
+		},
+		{
+			name: "container name with uppercase letters",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "Charts",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "lowercase letters, digits and hyphens",
+		},
+		{
+			name: "container name starting with a hyphen",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "-charts",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "must start with a letter or digit",
+		},
+		{
+			name: "container name ending with a hyphen",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts-",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "must not end with a hyphen",
+		},
+		{
+			name: "container name with consecutive hyphens",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "helm--charts",
+				ChartPath:          "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "consecutive hyphens",
+		},
+		{
+			name: "missing chart path",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+			},
+			wantErr: true,
+			errMsg:  "chartPath is required",
+		},
+		{
+			name: "chart path not ending with .tgz or .tar.gz",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+				ChartPath:          "myapp/Chart.yaml",
+			},
+			wantErr: true,
+			errMsg:  "chartPath must end with .tgz or .tar.gz",
+		},
+		{
+			name: "git fields should not be set for azblob source",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+				ChartPath:          "chart.tgz",
+				GitTag:             "v1.0.0",
+			},
+			wantErr: true,
+			errMsg:  "git reference fields",
+		},
+		{
+			name: "s3 fields should not be set for azblob source",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+				ChartPath:          "chart.tgz",
+				S3BucketName:       "charts",
+			},
+			wantErr: true,
+			errMsg:  "s3 fields",
+		},
+		{
+			name: "chartName should not be set for azblob source",
+			chart: ChartSpec{
+				SourceType:         "azblob",
+				URL:                "https://myaccount.blob.core.windows.net",
+				AzureContainerName: "charts",
+				ChartPath:          "chart.tgz",
+				ChartName:          "myapp",
+			},
+			wantErr: true,
+			errMsg:  "chartName should not be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAzureSource(tt.chart)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("validateAzureSource() expected error containing %q, got nil", tt.errMsg)
+					return
+				}
+				if !strings.Contains(strings.ToLower(err.Error()), strings.ToLower(tt.errMsg)) {
+					t.Errorf("validateAzureSource() error = %q, want error containing %q", err.Error(), tt.errMsg)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("validateAzureSource() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateGCSSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   ChartSpec
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name: "valid gcs source without URL (defaults to storage.googleapis.com)",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "my-charts",
+				ChartPath:     "myapp/myapp-1.0.0.tgz",
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid gcs source with custom endpoint and dotted bucket",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				URL:           "http://localhost:4443",
+				GCSBucketName: "charts.example.com",
+				ChartPath:     "chart.tar.gz",
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid bucket name with underscores",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "helm_charts_1",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid URL format",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				URL:           "gs://my-charts",
+				GCSBucketName: "my-charts",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "invalid url",
+		},
+		{
+			name: "missing bucket name",
+			chart: ChartSpec{
+				SourceType: "gcs",
+				ChartPath:  "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "gcsBucketName is required",
+		},
+		{
+			name: "bucket name too short",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "ab",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "at least 3 characters",
+		},
+		{
+			name: "bucket name too long without dots",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: strings.Repeat("a", 64),
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "at most 63 characters",
+		},
+		{
+			name: "bucket name with uppercase letters",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "My-Charts",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "may only contain",
+		},
+		{
+			name: "bucket name ending with a hyphen",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "my-charts-",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "must start and end with a letter or digit",
+		},
+		{
+			name: "bucket name with empty dot component",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "charts..example",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "dot-separated component",
+		},
+		{
+			name: "bucket name is an IP address",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "192.168.5.4",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "must not be an IP address",
+		},
+		{
+			name: "bucket name starting with goog",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "goog-charts",
+				ChartPath:     "chart.tgz",
+			},
+			wantErr: true,
+			errMsg:  "goog",
+		},
+		{
+			name: "missing chart path",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "my-charts",
+			},
+			wantErr: true,
+			errMsg:  "chartPath is required",
+		},
+		{
+			name: "chart path not ending with .tgz or .tar.gz",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "my-charts",
+				ChartPath:     "myapp/values.yaml",
+			},
+			wantErr: true,
+			errMsg:  "chartPath must end with .tgz or .tar.gz",
+		},
+		{
+			name: "oci fields should not be set for gcs source",
+			chart: ChartSpec{
+				SourceType:    "gcs",
+				GCSBucketName: "my-charts",
+				ChartPath:     "chart.tgz",
+				OCIProvider:   "cosign",
+			},
+			wantErr: true,
+			errMsg:  "oci fields",
+		},
+		{
+			name: "azure fields should not be set for gcs source",
+			chart: ChartSpec{
+				SourceType:         "gcs",
+				GCSBucketName:      "my-charts",
+				ChartPath:          "chart.tgz",
+				AzureContainerName: "charts",
+			},
+			wantErr: true,
+			errMsg:  "azureContainerName should not be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGCSSource(tt.chart)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("validateGCSSource() expected error containing %q, got nil", tt.errMsg)
+					return
+				}
+				if !strings.Contains(strings.ToLower(err.Error()), strings.ToLower(tt.errMsg)) {
+					t.Errorf("validateGCSSource() error = %q, want error containing %q", err.Error(), tt.errMsg)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("validateGCSSource() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractAzureCredentialsFromSecret(t *testing.T) {
+	tests := []struct {
+		name            string
+		secretData      map[string]string
+		wantAccountName string
+		wantAccountKey  string
+		wantSASToken    string
+		wantErr         bool
+	}{
+		{
+			name:            "shared key",
+			secretData:      map[string]string{"accountName": "myaccount", "accountKey": "a2V5"},
+			wantAccountName: "myaccount",
+			wantAccountKey:  "a2V5",
+		},
+		{
+			name:         "sas token",
+			secretData:   map[string]string{"sasToken": "sv=2021-08-06&sig=abc"},
+			wantSASToken: "sv=2021-08-06&sig=abc",
+		},
+		{
+			name:         "sas token takes precedence",
+			secretData:   map[string]string{"accountName": "myaccount", "accountKey": "a2V5", "sasToken": "sig=abc"},
+			wantSASToken: "sig=abc",
+		},
+		{
+			name:       "missing accountKey",
+			secretData: map[string]string{"accountName": "myaccount"},
+			wantErr:    true,
+		},
+		{
+			name:       "missing accountName",
+			secretData: map[string]string{"accountKey": "a2V5"},
+			wantErr:    true,
+		},
+		{
+			name:       "empty secret data",
+			secretData: map[string]string{},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountName, accountKey, sasToken, err := extractAzureCredentialsFromSecret(tt.secretData)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("extractAzureCredentialsFromSecret() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("extractAzureCredentialsFromSecret() unexpected error: %v", err)
+				return
+			}
+
+			if accountName != tt.wantAccountName || accountKey != tt.wantAccountKey || sasToken != tt.wantSASToken {
+				t.Errorf("extractAzureCredentialsFromSecret() = (%q, %q, %q), want (%q, %q, %q)",
+					accountName, accountKey, sasToken, tt.wantAccountName, tt.wantAccountKey, tt.wantSASToken)
+			}
+		})
+	}
+}
+
+func TestExtractGCSCredentialsFromSecret(t *testing.T) {
+	tests := []struct {
+		name                  string
+		secretData            map[string]string
+		wantServiceAccountKey string
+		wantAccessToken       string
+		wantErr               bool
+	}{
+		{
+			name:                  "service account key",
+			secretData:            map[string]string{"serviceAccountKey": `{"type":"service_account"}`},
+			wantServiceAccountKey: `{"type":"service_account"}`,
+		},
+		{
+			name:            "access token",
+			secretData:      map[string]string{"accessToken": "ya29.token"},
+			wantAccessToken: "ya29.token",
+		},
+		{
+			name:                  "service account key takes precedence",
+			secretData:            map[string]string{"serviceAccountKey": "{}", "accessToken": "ya29.token"},
+			wantServiceAccountKey: "{}",
+		},
+		{
+			name:       "empty secret data",
+			secretData: map[string]string{},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceAccountKey, accessToken, err := extractGCSCredentialsFromSecret(tt.secretData)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("extractGCSCredentialsFromSecret() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("extractGCSCredentialsFromSecret() unexpected error: %v", err)
+				return
+			}
+
+			if serviceAccountKey != tt.wantServiceAccountKey || accessToken != tt.wantAccessToken {
+				t.Errorf("extractGCSCredentialsFromSecret() = (%q, %q), want (%q, %q)",
+					serviceAccountKey, accessToken, tt.wantServiceAccountKey, tt.wantAccessToken)
+			}
+		})
+	}
+}
+
 // Tests for Task 5.2: Value merging with TargetPath
 
 func TestMergeValuesAtPath(t *testing.T) {
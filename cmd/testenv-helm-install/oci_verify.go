@@ -0,0 +1,151 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
+)
+
+// cosignPublicKeySecretKey is the AuthSecretName Secret key holding the cosign public key.
+const cosignPublicKeySecretKey = "cosign.pub"
+
+// verifyOCISignature verifies OCI chart signature using specified provider.
+// Returns nil if OCIProvider is not set. Installation must abort on any returned error.
+func verifyOCISignature(kubeconfigPath string, chart ChartSpec) error {
+	switch chart.OCIProvider {
+	case "":
+		return nil
+	case "cosign":
+		return verifyCosignSignature(kubeconfigPath, chart)
+	case "notation":
+		return fmt.Errorf("ociProvider %q is not implemented", chart.OCIProvider)
+	default:
+		return fmt.Errorf("unknown ociProvider %q: must be cosign or notation", chart.OCIProvider)
+	}
+}
+
+// cosignImageRef returns the image reference of an OCI chart as expected by cosign.
+// The oci:// scheme is dropped and chart.Version is used as tag when the URL has no tag or digest.
+func cosignImageRef(chart ChartSpec) (string, error) {
+	_, _, _, tag, digest, err := parseOCIReference(chart.URL)
+	if err != nil {
+		return "", err
+	}
+
+	ref := strings.TrimPrefix(chart.URL, "oci://")
+	if digest != "" || strings.HasSuffix(ref, ":"+tag) {
+		return ref, nil
+	}
+
+	if chart.Version == "" {
+		return ref + ":latest", nil
+	}
+	if strings.ContainsAny(chart.Version, "^~<>=*|, ") {
+		return "", fmt.Errorf("cosign verification requires an exact chart version, got range %q", chart.Version)
+	}
+
+	return ref + ":" + chart.Version, nil
+}
+
+// buildCosignVerifyArgs builds the arguments of `cosign verify` for the image reference.
+func buildCosignVerifyArgs(imageRef, keyRef string, allowInsecureRegistry bool) []string {
+	args := []string{"verify", "--key", keyRef}
+	if allowInsecureRegistry {
+		args = append(args, "--allow-insecure-registry")
+	}
+	return append(args, imageRef)
+}
+
+// resolveCosignKey returns the cosign public key reference for the chart.
+// chart.CosignKey takes precedence over the "cosign.pub" key of the AuthSecretName Secret,
+// which is written to a temporary file removed by the returned cleanup function.
+func resolveCosignKey(kubeconfigPath string, chart ChartSpec) (keyRef string, cleanup func(), err error) {
+	if chart.CosignKey != "" {
+		return chart.CosignKey, func() {}, nil
+	}
+
+	if chart.AuthSecretName == "" {
+		return "", nil, fmt.Errorf("cosign verification requires a public key: set cosignKey or add %q to the authSecretName Secret", cosignPublicKeySecretKey)
+	}
+
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	data, err := fetchSecret(kubeconfigPath, namespace, chart.AuthSecretName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	publicKey, ok := data[cosignPublicKeySecretKey]
+	if !ok || publicKey == "" {
+		return "", nil, fmt.Errorf("secret %s/%s does not contain %q", namespace, chart.AuthSecretName, cosignPublicKeySecretKey)
+	}
+
+	tmpDir, err := tempdir.MkdirTemp("cosign-key-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir for cosign key: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(tmpDir) }
+
+	keyPath := filepath.Join(tmpDir, cosignPublicKeySecretKey)
+	if err := os.WriteFile(keyPath, []byte(publicKey), 0o600); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write cosign key: %w", err)
+	}
+
+	return keyPath, cleanup, nil
+}
+
+// verifyCosignSignature verifies the chart signature by running `cosign verify`.
+func verifyCosignSignature(kubeconfigPath string, chart ChartSpec) error {
+	imageRef, err := cosignImageRef(chart)
+	if err != nil {
+		return err
+	}
+
+	keyRef, cleanup, err := resolveCosignKey(kubeconfigPath, chart)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	log.Printf("Verifying cosign signature of %s", imageRef)
+
+	cmd := exec.CommandContext(ctx, "cosign", buildCosignVerifyArgs(imageRef, keyRef, chart.InsecureSkipVerify)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("cosign verify timed out after 2 minutes")
+		}
+		return fmt.Errorf("cosign signature verification failed for %s: %w, output: %s", imageRef, err, string(output))
+	}
+
+	log.Printf("Cosign signature verified for %s", imageRef)
+	return nil
+}
@@ -0,0 +1,351 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ResourceCheck asserts that a Kubernetes resource reaches a ready condition after the chart is installed.
+type ResourceCheck struct {
+	// Kind is the resource kind (e.g. Deployment, StatefulSet, DaemonSet, Job, Pod).
+	// Required.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Name is the name of the resource.
+	// Required.
+	Name string `json:"name" yaml:"name"`
+
+	// Namespace of the resource. Defaults to the chart namespace, then "default".
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Condition is the status condition type that must be "True" (e.g. "Available", "Complete", "Ready").
+	// If empty, a default is derived from the kind:
+	//   - Deployment, StatefulSet: all desired replicas are available/ready
+	//   - DaemonSet: all scheduled pods are ready
+	//   - Job: condition "Complete"
+	//   - Pod: condition "Ready"
+	//   - CustomResourceDefinition: condition "Established"
+	// Required for other kinds.
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+
+	// Timeout is the maximum time to wait for the condition. Defaults to the chart timeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// readinessPollInterval is the delay between two evaluations of a readiness check.
+var readinessPollInterval = 2 * time.Second
+
+// errResourceFailed reports a terminal failure (e.g. a failed Job): polling stops immediately.
+var errResourceFailed = errors.New("resource failed")
+
+// resourceGetter fetches a resource as JSON. It is replaced in tests to fake kubectl output.
+type resourceGetter func(ctx context.Context, kubeconfigPath, kind, name, namespace string) ([]byte, error)
+
+// kubectlGetResource fetches a resource as JSON using kubectl.
+func kubectlGetResource(ctx context.Context, kubeconfigPath, kind, name, namespace string) ([]byte, error) {
+	args := buildKubectlGetCommand(kubeconfigPath, kind, name, namespace)
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("kubectl get %s %s/%s failed: %w, output: %s", kind, namespace, name, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("kubectl get %s %s/%s failed: %w", kind, namespace, name, err)
+	}
+
+	return output, nil
+}
+
+// validateReadinessChecks validates the readiness checks of a chart.
+func validateReadinessChecks(checks []ResourceCheck) error {
+	for i, check := range checks {
+		if check.Kind == "" {
+			return fmt.Errorf("readinessChecks[%d]: kind is required", i)
+		}
+		if check.Name == "" {
+			return fmt.Errorf("readinessChecks[%d]: name is required", i)
+		}
+		if check.Condition == "" && !hasDefaultReadiness(check.Kind) {
+			return fmt.Errorf("readinessChecks[%d]: condition is required for kind %s", i, check.Kind)
+		}
+		if check.Timeout != "" {
+			if _, err := time.ParseDuration(check.Timeout); err != nil {
+				return fmt.Errorf("readinessChecks[%d]: invalid timeout %q: %w", i, check.Timeout, err)
+			}
+		}
+	}
+	return nil
+}
+
+// hasDefaultReadiness reports whether a kind has a default readiness condition.
+func hasDefaultReadiness(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "deployment", "statefulset", "daemonset", "job", "pod", "customresourcedefinition":
+		return true
+	default:
+		return false
+	}
+}
+
+// runReadinessChecks waits for every readiness check of the chart, in order.
+// The first check that does not become ready before its timeout fails the install.
+func runReadinessChecks(chart ChartSpec, kubeconfigPath string, get resourceGetter) error {
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	for _, check := range chart.ReadinessChecks {
+		if check.Namespace == "" {
+			check.Namespace = namespace
+		}
+
+		timeoutStr := check.Timeout
+		if timeoutStr == "" {
+			timeoutStr = chart.Timeout
+		}
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			timeout = 5 * time.Minute
+		}
+
+		if err := waitForResourceCheck(kubeconfigPath, check, timeout, get); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitForResourceCheck polls a resource until its condition is met, the resource fails, or the timeout expires.
+func waitForResourceCheck(kubeconfigPath string, check ResourceCheck, timeout time.Duration, get resourceGetter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resource := fmt.Sprintf("%s %s/%s", check.Kind, check.Namespace, check.Name)
+	if check.Namespace == "" {
+		resource = fmt.Sprintf("%s %s", check.Kind, check.Name) // Cluster-scoped
+	}
+	log.Printf("Waiting up to %v for %s to be ready", timeout, resource)
+
+	var lastReason string
+	for {
+		data, err := get(ctx, kubeconfigPath, check.Kind, check.Name, check.Namespace)
+		if err != nil {
+			// The resource may not exist yet: keep polling
+			lastReason = err.Error()
+		} else {
+			ready, reason, err := evaluateResourceCheck(check, data)
+			if err != nil {
+				return fmt.Errorf("readiness check failed for %s: %w", resource, err)
+			}
+			if ready {
+				log.Printf("Readiness check passed for %s", resource)
+				return nil
+			}
+			lastReason = reason
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("readiness check timed out after %v for %s: %s", timeout, resource, lastReason)
+		case <-time.After(readinessPollInterval):
+		}
+	}
+}
+
+// k8sResourceStatus holds the fields of a resource used to evaluate readiness.
+type k8sResourceStatus struct {
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int64 `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration     int64  `json:"observedGeneration"`
+		Replicas               int64  `json:"replicas"`
+		ReadyReplicas          int64  `json:"readyReplicas"`
+		AvailableReplicas      int64  `json:"availableReplicas"`
+		UpdatedReplicas        int64  `json:"updatedReplicas"`
+		DesiredNumberScheduled int64  `json:"desiredNumberScheduled"`
+		NumberReady            int64  `json:"numberReady"`
+		Phase                  string `json:"phase"`
+		Conditions             []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// evaluateResourceCheck evaluates a readiness check against the JSON of a resource.
+// It returns whether the resource is ready, a human-readable reason when it is not,
+// and an error wrapping errResourceFailed when the resource can never become ready.
+func evaluateResourceCheck(check ResourceCheck, data []byte) (bool, string, error) {
+	var res k8sResourceStatus
+	if err := json.Unmarshal(data, &res); err != nil {
+		return false, "", fmt.Errorf("failed to parse %s JSON: %w", check.Kind, err)
+	}
+
+	kind := strings.ToLower(check.Kind)
+
+	// A failed Job never completes
+	if kind == "job" {
+		for _, c := range res.Status.Conditions {
+			if c.Type == "Failed" && c.Status == "True" && check.Condition != "Failed" {
+				return false, "", fmt.Errorf("%w: job failed: %s %s", errResourceFailed, c.Reason, c.Message)
+			}
+		}
+	}
+
+	// A CRD whose names conflict with another CRD is never established
+	if kind == "customresourcedefinition" {
+		for _, c := range res.Status.Conditions {
+			if c.Type == "NamesAccepted" && c.Status == "False" {
+				return false, "", fmt.Errorf("%w: CRD names not accepted: %s %s", errResourceFailed, c.Reason, c.Message)
+			}
+		}
+	}
+
+	if check.Condition != "" {
+		return evaluateCondition(res, check.Condition)
+	}
+
+	// A CRD has no generation-based rollout: it is ready once established
+	if kind == "customresourcedefinition" {
+		return evaluateCondition(res, "Established")
+	}
+
+	if res.Status.ObservedGeneration < res.Metadata.Generation {
+		return false, fmt.Sprintf("observed generation %d is behind generation %d", res.Status.ObservedGeneration, res.Metadata.Generation), nil
+	}
+
+	desired := int64(1)
+	if res.Spec.Replicas != nil {
+		desired = *res.Spec.Replicas
+	}
+
+	switch kind {
+	case "deployment":
+		if res.Status.UpdatedReplicas < desired || res.Status.AvailableReplicas < desired {
+			return false, fmt.Sprintf("%d/%d replicas available", res.Status.AvailableReplicas, desired), nil
+		}
+		return true, "", nil
+	case "statefulset":
+		if res.Status.ReadyReplicas < desired {
+			return false, fmt.Sprintf("%d/%d replicas ready", res.Status.ReadyReplicas, desired), nil
+		}
+		return true, "", nil
+	case "daemonset":
+		if res.Status.NumberReady < res.Status.DesiredNumberScheduled {
+			return false, fmt.Sprintf("%d/%d pods ready", res.Status.NumberReady, res.Status.DesiredNumberScheduled), nil
+		}
+		return true, "", nil
+	case "job":
+		return evaluateCondition(res, "Complete")
+	case "pod":
+		switch res.Status.Phase {
+		case "Succeeded":
+			return true, "", nil
+		case "Failed":
+			return false, "", fmt.Errorf("%w: pod phase is Failed", errResourceFailed)
+		}
+		return evaluateCondition(res, "Ready")
+	default:
+		return false, "", fmt.Errorf("condition is required for kind %s", check.Kind)
+	}
+}
+
+// evaluateCondition reports whether the status condition of the given type is "True".
+func evaluateCondition(res k8sResourceStatus, conditionType string) (bool, string, error) {
+	for _, c := range res.Status.Conditions {
+		if c.Type != conditionType {
+			continue
+		}
+		if c.Status == "True" {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("condition %s is %s: %s", conditionType, c.Status, c.Message), nil
+	}
+	return false, fmt.Sprintf("condition %s not found", conditionType), nil
+}
+
+// crdKind is the kind of the resources polled for spec.charts[].waitForCRDs.
+const crdKind = "CustomResourceDefinition"
+
+// validateWaitForCRDs validates the CRD names a chart waits for.
+// A CRD name is "<plural>.<group>" in lowercase, e.g. "certificates.cert-manager.io".
+func validateWaitForCRDs(crds []string) error {
+	for i, crd := range crds {
+		if err := validateCRDName(crd); err != nil {
+			return fmt.Errorf("waitForCRDs[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateCRDName reports whether name is a valid CRD name: "<plural>.<group>", a lowercase
+// DNS subdomain with at least one dot.
+func validateCRDName(name string) error {
+	plural, group, ok := strings.Cut(name, ".")
+	if !ok || plural == "" || group == "" {
+		return fmt.Errorf("invalid CRD name %q: expected <plural>.<group> (e.g. certificates.cert-manager.io)", name)
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("invalid CRD name %q: longer than 253 characters", name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid CRD name %q: labels must start and end with a lowercase letter or digit", name)
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return fmt.Errorf("invalid CRD name %q: only lowercase letters, digits, '-' and '.' are allowed", name)
+			}
+		}
+	}
+	return nil
+}
+
+// waitForChartCRDs waits until every CRD listed in chart.WaitForCRDs is established,
+// within the chart timeout (default 5m) for each CRD.
+// It fails with a timeout error naming the CRD if one never appears or is never established.
+func waitForChartCRDs(chart ChartSpec, kubeconfigPath string, get resourceGetter) error {
+	timeout, err := time.ParseDuration(chart.Timeout)
+	if err != nil {
+		timeout = 5 * time.Minute
+	}
+
+	for _, crd := range chart.WaitForCRDs {
+		check := ResourceCheck{Kind: crdKind, Name: crd, Condition: "Established"}
+		if err := waitForResourceCheck(kubeconfigPath, check, timeout, get); err != nil {
+			return fmt.Errorf("CRD %s is not established, chart %s cannot be installed: %w", crd, chart.Name, err)
+		}
+	}
+
+	return nil
+}
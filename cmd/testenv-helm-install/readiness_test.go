@@ -0,0 +1,406 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateResourceCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		check      ResourceCheck
+		data       string
+		wantReady  bool
+		wantReason string
+		wantErr    error
+	}{
+		{
+			name:      "deployment all replicas available",
+			check:     ResourceCheck{Kind: "Deployment", Name: "app"},
+			data:      `{"metadata":{"generation":2},"spec":{"replicas":3},"status":{"observedGeneration":2,"updatedReplicas":3,"availableReplicas":3}}`,
+			wantReady: true,
+		},
+		{
+			name:       "deployment partially available",
+			check:      ResourceCheck{Kind: "Deployment", Name: "app"},
+			data:       `{"metadata":{"generation":1},"spec":{"replicas":3},"status":{"observedGeneration":1,"updatedReplicas":3,"availableReplicas":1}}`,
+			wantReason: "1/3 replicas available",
+		},
+		{
+			name:       "deployment generation not observed",
+			check:      ResourceCheck{Kind: "Deployment", Name: "app"},
+			data:       `{"metadata":{"generation":2},"spec":{"replicas":1},"status":{"observedGeneration":1,"updatedReplicas":1,"availableReplicas":1}}`,
+			wantReason: "observed generation 1 is behind generation 2",
+		},
+		{
+			name:      "deployment replicas default to 1",
+			check:     ResourceCheck{Kind: "deployment", Name: "app"},
+			data:      `{"metadata":{"generation":1},"spec":{},"status":{"observedGeneration":1,"updatedReplicas":1,"availableReplicas":1}}`,
+			wantReady: true,
+		},
+		{
+			name:       "statefulset not ready",
+			check:      ResourceCheck{Kind: "StatefulSet", Name: "db"},
+			data:       `{"metadata":{"generation":1},"spec":{"replicas":2},"status":{"observedGeneration":1,"readyReplicas":1}}`,
+			wantReason: "1/2 replicas ready",
+		},
+		{
+			name:      "daemonset ready",
+			check:     ResourceCheck{Kind: "DaemonSet", Name: "agent"},
+			data:      `{"metadata":{"generation":1},"status":{"observedGeneration":1,"desiredNumberScheduled":2,"numberReady":2}}`,
+			wantReady: true,
+		},
+		{
+			name:      "job complete",
+			check:     ResourceCheck{Kind: "Job", Name: "migrate"},
+			data:      `{"status":{"conditions":[{"type":"Complete","status":"True"}]}}`,
+			wantReady: true,
+		},
+		{
+			name:       "job running",
+			check:      ResourceCheck{Kind: "Job", Name: "migrate"},
+			data:       `{"status":{"active":1}}`,
+			wantReason: "condition Complete not found",
+		},
+		{
+			name:    "job failed",
+			check:   ResourceCheck{Kind: "Job", Name: "migrate"},
+			data:    `{"status":{"conditions":[{"type":"Failed","status":"True","reason":"BackoffLimitExceeded"}]}}`,
+			wantErr: errResourceFailed,
+		},
+		{
+			name:      "pod succeeded",
+			check:     ResourceCheck{Kind: "Pod", Name: "seed"},
+			data:      `{"status":{"phase":"Succeeded"}}`,
+			wantReady: true,
+		},
+		{
+			name:    "pod failed",
+			check:   ResourceCheck{Kind: "Pod", Name: "seed"},
+			data:    `{"status":{"phase":"Failed"}}`,
+			wantErr: errResourceFailed,
+		},
+		{
+			name:      "explicit condition true",
+			check:     ResourceCheck{Kind: "Certificate", Name: "tls", Condition: "Ready"},
+			data:      `{"status":{"conditions":[{"type":"Ready","status":"True"}]}}`,
+			wantReady: true,
+		},
+		{
+			name:       "explicit condition false",
+			check:      ResourceCheck{Kind: "Certificate", Name: "tls", Condition: "Ready"},
+			data:       `{"status":{"conditions":[{"type":"Ready","status":"False","message":"issuing"}]}}`,
+			wantReason: "condition Ready is False: issuing",
+		},
+		{
+			name:      "crd established",
+			check:     ResourceCheck{Kind: "CustomResourceDefinition", Name: "certificates.cert-manager.io"},
+			data:      `{"metadata":{"generation":1},"status":{"conditions":[{"type":"NamesAccepted","status":"True"},{"type":"Established","status":"True"}]}}`,
+			wantReady: true,
+		},
+		{
+			name:       "crd not yet established",
+			check:      ResourceCheck{Kind: "CustomResourceDefinition", Name: "certificates.cert-manager.io", Condition: "Established"},
+			data:       `{"status":{"conditions":[{"type":"NamesAccepted","status":"True"},{"type":"Established","status":"False","message":"not all names are accepted"}]}}`,
+			wantReason: "condition Established is False: not all names are accepted",
+		},
+		{
+			name:       "crd without status",
+			check:      ResourceCheck{Kind: "CustomResourceDefinition", Name: "certificates.cert-manager.io"},
+			data:       `{"metadata":{"name":"certificates.cert-manager.io"}}`,
+			wantReason: "condition Established not found",
+		},
+		{
+			name:    "crd names not accepted",
+			check:   ResourceCheck{Kind: "CustomResourceDefinition", Name: "certificates.cert-manager.io", Condition: "Established"},
+			data:    `{"status":{"conditions":[{"type":"NamesAccepted","status":"False","reason":"NameConflict"}]}}`,
+			wantErr: errResourceFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, reason, err := evaluateResourceCheck(tt.check, []byte(tt.data))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("evaluateResourceCheck() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("evaluateResourceCheck() unexpected error: %v", err)
+			}
+			if ready != tt.wantReady {
+				t.Errorf("evaluateResourceCheck() ready = %v, want %v", ready, tt.wantReady)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("evaluateResourceCheck() reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+// fakeKubectl returns a resourceGetter replaying the given outputs, repeating the last one.
+// An empty output simulates a kubectl "not found" error.
+func fakeKubectl(outputs ...string) (resourceGetter, *int) {
+	calls := 0
+	return func(_ context.Context, _, _, _, _ string) ([]byte, error) {
+		out := outputs[len(outputs)-1]
+		if calls < len(outputs) {
+			out = outputs[calls]
+		}
+		calls++
+		if out == "" {
+			return nil, errors.New(`Error from server (NotFound): deployments.apps "app" not found`)
+		}
+		return []byte(out), nil
+	}, &calls
+}
+
+func TestWaitForResourceCheck(t *testing.T) {
+	previous := readinessPollInterval
+	readinessPollInterval = time.Millisecond
+	t.Cleanup(func() { readinessPollInterval = previous })
+
+	notReady := `{"metadata":{"generation":1},"spec":{"replicas":2},"status":{"observedGeneration":1,"updatedReplicas":2,"availableReplicas":1}}`
+	ready := `{"metadata":{"generation":1},"spec":{"replicas":2},"status":{"observedGeneration":1,"updatedReplicas":2,"availableReplicas":2}}`
+	check := ResourceCheck{Kind: "Deployment", Name: "app", Namespace: "ns"}
+
+	t.Run("becomes ready after polling", func(t *testing.T) {
+		get, calls := fakeKubectl("", notReady, ready)
+		if err := waitForResourceCheck("/tmp/kubeconfig", check, time.Second, get); err != nil {
+			t.Fatalf("waitForResourceCheck() error = %v", err)
+		}
+		if *calls != 3 {
+			t.Errorf("expected 3 kubectl calls, got %d", *calls)
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		get, _ := fakeKubectl(notReady)
+		err := waitForResourceCheck("/tmp/kubeconfig", check, 20*time.Millisecond, get)
+		if err == nil {
+			t.Fatal("expected timeout error")
+		}
+		if !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "1/2 replicas available") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("stops on terminal failure", func(t *testing.T) {
+		get, calls := fakeKubectl(`{"status":{"conditions":[{"type":"Failed","status":"True"}]}}`)
+		err := waitForResourceCheck("/tmp/kubeconfig", ResourceCheck{Kind: "Job", Name: "migrate", Namespace: "ns"}, time.Second, get)
+		if !errors.Is(err, errResourceFailed) {
+			t.Fatalf("expected errResourceFailed, got %v", err)
+		}
+		if *calls != 1 {
+			t.Errorf("expected 1 kubectl call, got %d", *calls)
+		}
+	})
+}
+
+func TestRunReadinessChecks_DefaultsNamespace(t *testing.T) {
+	var gotNamespaces []string
+	get := func(_ context.Context, _, _, _, namespace string) ([]byte, error) {
+		gotNamespaces = append(gotNamespaces, namespace)
+		return []byte(`{"status":{"conditions":[{"type":"Complete","status":"True"}]}}`), nil
+	}
+
+	chart := ChartSpec{
+		Name:      "app",
+		Namespace: "apps",
+		Timeout:   "1s",
+		ReadinessChecks: []ResourceCheck{
+			{Kind: "Job", Name: "migrate"},
+			{Kind: "Job", Name: "seed", Namespace: "data"},
+		},
+	}
+
+	if err := runReadinessChecks(chart, "/tmp/kubeconfig", get); err != nil {
+		t.Fatalf("runReadinessChecks() error = %v", err)
+	}
+	if strings.Join(gotNamespaces, ",") != "apps,data" {
+		t.Errorf("namespaces = %v, want [apps data]", gotNamespaces)
+	}
+}
+
+func TestValidateReadinessChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []ResourceCheck
+		wantErr string
+	}{
+		{
+			name:   "valid default kinds",
+			checks: []ResourceCheck{{Kind: "Deployment", Name: "app"}, {Kind: "Job", Name: "migrate", Timeout: "2m"}},
+		},
+		{
+			name:   "custom kind with condition",
+			checks: []ResourceCheck{{Kind: "Certificate", Name: "tls", Condition: "Ready"}},
+		},
+		{
+			name:    "missing kind",
+			checks:  []ResourceCheck{{Name: "app"}},
+			wantErr: "kind is required",
+		},
+		{
+			name:    "missing name",
+			checks:  []ResourceCheck{{Kind: "Deployment"}},
+			wantErr: "name is required",
+		},
+		{
+			name:    "custom kind without condition",
+			checks:  []ResourceCheck{{Kind: "Certificate", Name: "tls"}},
+			wantErr: "condition is required for kind Certificate",
+		},
+		{
+			name:    "invalid timeout",
+			checks:  []ResourceCheck{{Kind: "Deployment", Name: "app", Timeout: "soon"}},
+			wantErr: "invalid timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReadinessChecks(tt.checks)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateReadinessChecks() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateReadinessChecks() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWaitForCRDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		crds    []string
+		wantErr string
+	}{
+		{
+			name: "valid names",
+			crds: []string{"certificates.cert-manager.io", "issuers.cert-manager.io", "foos.v1-example.com"},
+		},
+		{
+			name: "empty",
+		},
+		{
+			name:    "missing group",
+			crds:    []string{"certificates"},
+			wantErr: "waitForCRDs[0]: invalid CRD name \"certificates\": expected <plural>.<group>",
+		},
+		{
+			name:    "empty plural",
+			crds:    []string{"certificates.cert-manager.io", ".cert-manager.io"},
+			wantErr: "waitForCRDs[1]",
+		},
+		{
+			name:    "uppercase",
+			crds:    []string{"Certificates.cert-manager.io"},
+			wantErr: "only lowercase letters",
+		},
+		{
+			name:    "kind/name form",
+			crds:    []string{"crd/certificates.cert-manager.io"},
+			wantErr: "only lowercase letters",
+		},
+		{
+			name:    "empty label",
+			crds:    []string{"certificates..io"},
+			wantErr: "labels must start and end",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWaitForCRDs(tt.crds)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWaitForCRDs() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateWaitForCRDs() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForChartCRDs(t *testing.T) {
+	previous := readinessPollInterval
+	readinessPollInterval = time.Millisecond
+	t.Cleanup(func() { readinessPollInterval = previous })
+
+	established := `{"status":{"conditions":[{"type":"Established","status":"True"}]}}`
+	pending := `{"status":{"conditions":[{"type":"Established","status":"False","message":"installing"}]}}`
+
+	t.Run("waits until every CRD is established", func(t *testing.T) {
+		var got []string
+		get := func(_ context.Context, _, kind, name, namespace string) ([]byte, error) {
+			if kind != crdKind || namespace != "" {
+				t.Errorf("unexpected kubectl get %s %s/%s", kind, namespace, name)
+			}
+			got = append(got, name)
+			if len(got) == 1 {
+				return nil, errors.New(`Error from server (NotFound): customresourcedefinitions.apiextensions.k8s.io "certificates.cert-manager.io" not found`)
+			}
+			return []byte(established), nil
+		}
+
+		chart := ChartSpec{Name: "issuers", Timeout: "1s", WaitForCRDs: []string{"certificates.cert-manager.io", "issuers.cert-manager.io"}}
+		if err := waitForChartCRDs(chart, "/tmp/kubeconfig", get); err != nil {
+			t.Fatalf("waitForChartCRDs() error = %v", err)
+		}
+		if strings.Join(got, ",") != "certificates.cert-manager.io,certificates.cert-manager.io,issuers.cert-manager.io" {
+			t.Errorf("kubectl calls = %v", got)
+		}
+	})
+
+	t.Run("times out when a CRD never appears", func(t *testing.T) {
+		get, _ := fakeKubectl("")
+		chart := ChartSpec{Name: "issuers", Timeout: "20ms", WaitForCRDs: []string{"certificates.cert-manager.io"}}
+		err := waitForChartCRDs(chart, "/tmp/kubeconfig", get)
+		if err == nil {
+			t.Fatal("expected timeout error")
+		}
+		for _, want := range []string{"CRD certificates.cert-manager.io is not established", "chart issuers", "timed out after 20ms", "not found"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not contain %q", err, want)
+			}
+		}
+	})
+
+	t.Run("times out when a CRD is never established", func(t *testing.T) {
+		get, _ := fakeKubectl(pending)
+		chart := ChartSpec{Name: "issuers", Timeout: "20ms", WaitForCRDs: []string{"certificates.cert-manager.io"}}
+		err := waitForChartCRDs(chart, "/tmp/kubeconfig", get)
+		if err == nil || !strings.Contains(err.Error(), "condition Established is False: installing") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
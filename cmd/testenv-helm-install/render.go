@@ -0,0 +1,111 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// renderedManifestFileName returns the name of the file holding the rendered manifests of a release.
+func renderedManifestFileName(releaseName string) string {
+	return fmt.Sprintf("helm-template-%s.yaml", releaseName)
+}
+
+// buildHelmTemplateArgs builds the helm template arguments for the chart, without values flags.
+func buildHelmTemplateArgs(chart ChartSpec, releaseName, chartRef string) []string {
+	args := []string{
+		"template",
+		releaseName,
+		chartRef,
+		"--include-crds",
+	}
+
+	// Add version if specified
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+
+	// Add namespace if specified
+	if chart.Namespace != "" {
+		args = append(args, "--namespace", chart.Namespace)
+	}
+
+	// Add disable hooks if specified
+	if chart.DisableHooks {
+		args = append(args, "--no-hooks")
+	}
+
+	return args
+}
+
+// renderChart renders the chart with helm template into outputPath instead of installing it.
+// Values are composed exactly as for an install; ValueReferences that cannot be read
+// from the cluster are skipped with a warning. When validateSchema is set, the composed
+// values are validated against the chart's values.schema.json before rendering.
+func renderChart(chart ChartSpec, kubeconfigPath, outputPath string, validateSchema bool) error {
+	releaseName := chart.ReleaseName
+	if releaseName == "" {
+		releaseName = chart.Name
+	}
+
+	chartRef, cleanup, err := prepareChartRef(chart, kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	valuesArgs, valuesCleanup, err := composeValuesArgs(chart, kubeconfigPath, true)
+	if err != nil {
+		return err
+	}
+	defer valuesCleanup()
+
+	if validateSchema {
+		if err := validateValuesSchema(chartRef, chart.Version, valuesArgs); err != nil {
+			return err
+		}
+	}
+
+	args := append(buildHelmTemplateArgs(chart, releaseName, chartRef), valuesArgs...)
+
+	log.Printf("Running: helm %v", args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "helm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("helm template timed out after 5 minutes")
+		}
+		return fmt.Errorf("helm template failed: %w, output: %s", err, stderr.String())
+	}
+
+	if err := os.WriteFile(outputPath, stdout.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write rendered manifests to %s: %w", outputPath, err)
+	}
+
+	log.Printf("Chart rendered successfully: %s -> %s", releaseName, outputPath)
+	return nil
+}
@@ -0,0 +1,126 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildHelmTemplateArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		chart ChartSpec
+		want  []string
+	}{
+		{
+			name:  "minimal",
+			chart: ChartSpec{},
+			want:  []string{"template", "release", "repo/chart", "--include-crds"},
+		},
+		{
+			name:  "version, namespace and hooks",
+			chart: ChartSpec{Version: "1.2.3", Namespace: "ns", CreateNamespace: true, DisableHooks: true, DisableWait: true},
+			want:  []string{"template", "release", "repo/chart", "--include-crds", "--version", "1.2.3", "--namespace", "ns", "--no-hooks"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildHelmTemplateArgs(tt.chart, "release", "repo/chart")
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("buildHelmTemplateArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderedManifestFileName(t *testing.T) {
+	if got := renderedManifestFileName("podinfo"); got != "helm-template-podinfo.yaml" {
+		t.Errorf("renderedManifestFileName() = %q, want %q", got, "helm-template-podinfo.yaml")
+	}
+}
+
+func TestComposeValuesArgs(t *testing.T) {
+	t.Run("values files only", func(t *testing.T) {
+		chart := ChartSpec{ValuesFiles: []string{"a.yaml", "b.yaml"}}
+
+		args, cleanup, err := composeValuesArgs(chart, "", false)
+		if err != nil {
+			t.Fatalf("composeValuesArgs() error = %v", err)
+		}
+		defer cleanup()
+
+		want := "--values a.yaml --values b.yaml"
+		if strings.Join(args, " ") != want {
+			t.Errorf("composeValuesArgs() = %v, want %q", args, want)
+		}
+	})
+
+	t.Run("inline values written after values files", func(t *testing.T) {
+		chart := ChartSpec{
+			ValuesFiles: []string{"a.yaml"},
+			Values:      map[string]interface{}{"replicaCount": 2},
+		}
+
+		args, cleanup, err := composeValuesArgs(chart, "", false)
+		if err != nil {
+			t.Fatalf("composeValuesArgs() error = %v", err)
+		}
+
+		if len(args) != 4 || args[1] != "a.yaml" || args[2] != "--values" {
+			t.Fatalf("composeValuesArgs() = %v, want values file then composed file", args)
+		}
+
+		data, err := os.ReadFile(args[3])
+		if err != nil {
+			t.Fatalf("failed to read composed values: %v", err)
+		}
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			t.Fatalf("composed values are not YAML: %v", err)
+		}
+		if values["replicaCount"] != 2 {
+			t.Errorf("replicaCount = %v, want 2", values["replicaCount"])
+		}
+
+		cleanup()
+		if _, err := os.Stat(args[3]); !os.IsNotExist(err) {
+			t.Errorf("composed values file %s not removed by cleanup", args[3])
+		}
+	})
+
+	t.Run("render-only skips ValueReferences without kubeconfig", func(t *testing.T) {
+		chart := ChartSpec{
+			Values:          map[string]interface{}{"image": "nginx"},
+			ValueReferences: []ValueReference{{Kind: "ConfigMap", Name: "app-values"}},
+		}
+
+		args, cleanup, err := composeValuesArgs(chart, "", true)
+		if err != nil {
+			t.Fatalf("composeValuesArgs() error = %v", err)
+		}
+		defer cleanup()
+
+		if len(args) != 2 || args[0] != "--values" {
+			t.Errorf("composeValuesArgs() = %v, want only the composed values file", args)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// installSummaryFileName is the name of the install summary written to tmpDir.
+const installSummaryFileName = "helm-install-summary.json"
+
+// installSummaryFileKey is the artifact Files key of the install summary.
+const installSummaryFileKey = "testenv-helm-install.summary"
+
+// installSummary describes the charts installed by Create.
+type installSummary struct {
+	TestID string                `json:"testID"`
+	Charts []chartInstallSummary `json:"charts"`
+}
+
+// chartInstallSummary describes a single installed chart.
+type chartInstallSummary struct {
+	Name            string  `json:"name"`
+	SourceType      string  `json:"sourceType"`
+	Source          string  `json:"source"`
+	Version         string  `json:"version,omitempty"`
+	AppVersion      string  `json:"appVersion,omitempty"`
+	ReleaseName     string  `json:"releaseName"`
+	Namespace       string  `json:"namespace,omitempty"`
+	Status          string  `json:"status"`
+	Revision        int     `json:"revision,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// helmRelease is the subset of the JSON output of 'helm status' used by the engine.
+type helmRelease struct {
+	Version int `json:"version"`
+	Info    struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// chartSource returns a human-readable location of the chart source.
+func chartSource(chart ChartSpec) string {
+	switch chart.SourceType {
+	case "helm-repo":
+		return strings.TrimSuffix(chart.URL, "/") + "/" + chart.ChartName
+	case "local":
+		return chart.Path
+	default:
+		if chart.Path != "" && chart.URL != "" {
+			return strings.TrimSuffix(chart.URL, "/") + "/" + strings.TrimPrefix(chart.Path, "/")
+		}
+		if chart.URL != "" {
+			return chart.URL
+		}
+		return chart.Path
+	}
+}
+
+// summarizeInstalledChart builds the summary of an installed chart, resolving its chart version
+// and status from the release. When the release cannot be queried, the requested version is
+// reported with an "unknown" status.
+func summarizeInstalledChart(chart ChartSpec, releaseName, kubeconfigPath string, duration time.Duration) chartInstallSummary {
+	summary := chartInstallSummary{
+		Name:            chart.Name,
+		SourceType:      chart.SourceType,
+		Source:          chartSource(chart),
+		Version:         chart.Version,
+		ReleaseName:     releaseName,
+		Namespace:       chart.Namespace,
+		Status:          "unknown",
+		DurationSeconds: duration.Round(time.Millisecond).Seconds(),
+	}
+
+	release, err := getHelmRelease(releaseName, chart.Namespace, kubeconfigPath)
+	if err != nil {
+		log.Printf("Warning: failed to resolve release %s for the install summary: %v", releaseName, err)
+		return summary
+	}
+
+	if release.Chart.Metadata.Version != "" {
+		summary.Version = release.Chart.Metadata.Version
+	}
+	summary.AppVersion = release.Chart.Metadata.AppVersion
+	summary.Revision = release.Version
+	if release.Info.Status != "" {
+		summary.Status = release.Info.Status
+	}
+	return summary
+}
+
+// getHelmRelease returns the release as reported by 'helm status'.
+func getHelmRelease(releaseName, namespace, kubeconfigPath string) (helmRelease, error) {
+	data, err := runHelmStatus(releaseName, namespace, kubeconfigPath)
+	if err != nil {
+		return helmRelease{}, err
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return helmRelease{}, fmt.Errorf("failed to parse helm status output: %w", err)
+	}
+	return release, nil
+}
+
+// writeInstallSummary writes the install summary as JSON to tmpDir and returns its path.
+func writeInstallSummary(tmpDir string, summary installSummary) (string, error) {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal install summary: %w", err)
+	}
+
+	path := filepath.Join(tmpDir, installSummaryFileName)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write install summary: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// uninstallParallelismMetadataKey stores spec.uninstallParallelism for Delete,
+// which only receives the metadata produced by Create.
+const uninstallParallelismMetadataKey = "testenv-helm-install.uninstallParallelism"
+
+// uninstallRelease is an installed chart, as recorded in the metadata, to uninstall on delete.
+type uninstallRelease struct {
+	// Index is the install order of the chart.
+	Index       int
+	Name        string
+	ReleaseName string
+	Namespace   string
+	DependsOn   []string
+}
+
+// validateChartDependencies checks that every dependsOn entry names a chart
+// earlier in charts, which are in install order, so that install order satisfies dependencies.
+func validateChartDependencies(charts []ChartSpec) error {
+	declared := make(map[string]bool, len(charts))
+	for _, chart := range charts {
+		for _, dep := range chart.DependsOn {
+			if dep == chart.Name {
+				return fmt.Errorf("chart %s: dependsOn cannot reference itself", chart.Name)
+			}
+			if !declared[dep] {
+				return fmt.Errorf("chart %s: dependsOn %q must reference a chart installed before it (lower priority, or same priority and declared earlier)", chart.Name, dep)
+			}
+		}
+		declared[chart.Name] = true
+	}
+	return nil
+}
+
+// releasesFromMetadata reads the installed charts from the metadata, in install order.
+// Charts without a release name are skipped.
+func releasesFromMetadata(metadata map[string]string, chartCount int) []uninstallRelease {
+	releases := make([]uninstallRelease, 0, chartCount)
+	for i := 0; i < chartCount; i++ {
+		prefix := fmt.Sprintf("testenv-helm-install.chart.%d", i)
+		releaseName := metadata[prefix+".releaseName"]
+		if releaseName == "" {
+			log.Printf("Warning: chart %d missing release name, skipping", i)
+			continue
+		}
+
+		r := uninstallRelease{
+			Index:       i,
+			Name:        metadata[prefix+".name"],
+			ReleaseName: releaseName,
+			Namespace:   metadata[prefix+".namespace"],
+		}
+		if deps := metadata[prefix+".dependsOn"]; deps != "" {
+			r.DependsOn = strings.Split(deps, ",")
+		}
+		releases = append(releases, r)
+	}
+	return releases
+}
+
+// uninstallParallelism returns the maximum number of charts to uninstall concurrently.
+// The spec takes precedence over the value recorded in the metadata at creation. Defaults to 1.
+func uninstallParallelism(spec *Spec, metadata map[string]string) int {
+	if spec != nil && spec.UninstallParallelism > 0 {
+		return spec.UninstallParallelism
+	}
+	if v, ok := metadata[uninstallParallelismMetadataKey]; ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("Warning: invalid %s in metadata: %q", uninstallParallelismMetadataKey, v)
+	}
+	return 1
+}
+
+// planUninstallGroups partitions releases into groups that can be uninstalled concurrently.
+// Releases sharing a namespace or linked by dependsOn (transitively) belong to the same group.
+// Each group is in reverse install order, and groups are ordered by their most recently
+// installed release. With a parallelism of 1, all releases form a single group.
+func planUninstallGroups(releases []uninstallRelease, parallelism int) [][]uninstallRelease {
+	if len(releases) == 0 {
+		return nil
+	}
+
+	// Union-find over release positions
+	parent := make([]int, len(releases))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		parent[find(a)] = find(b)
+	}
+
+	if parallelism <= 1 {
+		for i := range releases {
+			union(i, 0)
+		}
+	} else {
+		byNamespace := map[string]int{}
+		byName := map[string]int{}
+		for i, r := range releases {
+			if j, ok := byNamespace[r.Namespace]; ok {
+				union(i, j)
+			} else {
+				byNamespace[r.Namespace] = i
+			}
+			if r.Name != "" {
+				byName[r.Name] = i
+			}
+		}
+		for i, r := range releases {
+			for _, dep := range r.DependsOn {
+				if j, ok := byName[dep]; ok {
+					union(i, j)
+				}
+			}
+		}
+	}
+
+	// Walk releases in reverse install order so that each group is in reverse order
+	// and groups are ordered by their most recently installed release
+	groupIndex := map[int]int{}
+	var groups [][]uninstallRelease
+	for i := len(releases) - 1; i >= 0; i-- {
+		root := find(i)
+		g, ok := groupIndex[root]
+		if !ok {
+			g = len(groups)
+			groupIndex[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], releases[i])
+	}
+	return groups
+}
+
+// uninstallGroups uninstalls each group serially, running up to parallelism groups concurrently.
+// Uninstallation is best effort: every release is attempted and failures are aggregated,
+// in reverse install order, into the returned error.
+func uninstallGroups(groups [][]uninstallRelease, parallelism int, uninstall func(uninstallRelease) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	total := 0
+	for _, g := range groups {
+		total += len(g)
+	}
+
+	var (
+		mu       sync.Mutex
+		failures = map[int]error{}
+		done     int
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, parallelism)
+
+	for _, group := range groups {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, r := range group {
+				mu.Lock()
+				done++
+				log.Printf("Uninstalling chart %d/%d: %s", done, total, r.ReleaseName)
+				mu.Unlock()
+
+				if err := uninstall(r); err != nil {
+					log.Printf("Warning: failed to uninstall chart %s: %v", r.ReleaseName, err)
+					mu.Lock()
+					failures[r.Index] = fmt.Errorf("chart %s: %w", r.ReleaseName, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	indexes := make([]int, 0, len(failures))
+	for i := range failures {
+		indexes = append(indexes, i)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+	errs := make([]error, 0, len(failures))
+	for _, i := range indexes {
+		errs = append(errs, failures[i])
+	}
+	return fmt.Errorf("failed to uninstall %d/%d chart(s): %w", len(failures), total, errors.Join(errs...))
+}
@@ -0,0 +1,293 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// releaseNames returns the release names of each group, for comparison in tests.
+func releaseNames(groups [][]uninstallRelease) [][]string {
+	out := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		names := make([]string, 0, len(g))
+		for _, r := range g {
+			names = append(names, r.ReleaseName)
+		}
+		out = append(out, names)
+	}
+	return out
+}
+
+func TestPlanUninstallGroups(t *testing.T) {
+	releases := []uninstallRelease{
+		{Index: 0, Name: "cert-manager", ReleaseName: "cert-manager", Namespace: "cert-manager"},
+		{Index: 1, Name: "ingress", ReleaseName: "ingress", Namespace: "ingress"},
+		{Index: 2, Name: "app", ReleaseName: "app", Namespace: "apps", DependsOn: []string{"cert-manager"}},
+		{Index: 3, Name: "metrics", ReleaseName: "metrics", Namespace: "monitoring"},
+		{Index: 4, Name: "app-extra", ReleaseName: "app-extra", Namespace: "apps"},
+	}
+
+	tests := []struct {
+		name        string
+		releases    []uninstallRelease
+		parallelism int
+		want        [][]string
+	}{
+		{
+			name:        "serial keeps global reverse order",
+			releases:    releases,
+			parallelism: 1,
+			want:        [][]string{{"app-extra", "metrics", "app", "ingress", "cert-manager"}},
+		},
+		{
+			name:        "zero parallelism is serial",
+			releases:    releases,
+			parallelism: 0,
+			want:        [][]string{{"app-extra", "metrics", "app", "ingress", "cert-manager"}},
+		},
+		{
+			name:        "parallel groups by namespace and dependencies",
+			releases:    releases,
+			parallelism: 4,
+			want: [][]string{
+				// apps namespace + cert-manager dependency of app
+				{"app-extra", "app", "cert-manager"},
+				{"metrics"},
+				{"ingress"},
+			},
+		},
+		{
+			name: "transitive dependencies share a group",
+			releases: []uninstallRelease{
+				{Index: 0, Name: "a", ReleaseName: "a", Namespace: "ns-a"},
+				{Index: 1, Name: "b", ReleaseName: "b", Namespace: "ns-b", DependsOn: []string{"a"}},
+				{Index: 2, Name: "c", ReleaseName: "c", Namespace: "ns-c", DependsOn: []string{"b"}},
+				{Index: 3, Name: "d", ReleaseName: "d", Namespace: "ns-d"},
+			},
+			parallelism: 2,
+			want:        [][]string{{"d"}, {"c", "b", "a"}},
+		},
+		{
+			name: "charts without namespace share the default namespace",
+			releases: []uninstallRelease{
+				{Index: 0, Name: "a", ReleaseName: "a"},
+				{Index: 1, Name: "b", ReleaseName: "b", Namespace: "other"},
+				{Index: 2, Name: "c", ReleaseName: "c"},
+			},
+			parallelism: 3,
+			want:        [][]string{{"c", "a"}, {"b"}},
+		},
+		{
+			name:        "no releases",
+			parallelism: 2,
+			want:        [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := releaseNames(planUninstallGroups(tt.releases, tt.parallelism))
+			if len(got) != len(tt.want) {
+				t.Fatalf("planUninstallGroups() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if strings.Join(got[i], ",") != strings.Join(tt.want[i], ",") {
+					t.Errorf("planUninstallGroups() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestUninstallParallelism(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     *Spec
+		metadata map[string]string
+		want     int
+	}{
+		{name: "default", want: 1},
+		{name: "from spec", spec: &Spec{UninstallParallelism: 4}, want: 4},
+		{name: "from metadata", metadata: map[string]string{uninstallParallelismMetadataKey: "3"}, want: 3},
+		{name: "spec takes precedence", spec: &Spec{UninstallParallelism: 2}, metadata: map[string]string{uninstallParallelismMetadataKey: "3"}, want: 2},
+		{name: "invalid metadata", metadata: map[string]string{uninstallParallelismMetadataKey: "many"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uninstallParallelism(tt.spec, tt.metadata); got != tt.want {
+				t.Errorf("uninstallParallelism() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleasesFromMetadata(t *testing.T) {
+	metadata := map[string]string{
+		"testenv-helm-install.chart.0.name":        "base",
+		"testenv-helm-install.chart.0.releaseName": "base-release",
+		"testenv-helm-install.chart.0.namespace":   "infra",
+		"testenv-helm-install.chart.1.name":        "missing-release",
+		"testenv-helm-install.chart.2.name":        "app",
+		"testenv-helm-install.chart.2.releaseName": "app",
+		"testenv-helm-install.chart.2.dependsOn":   "base,other",
+	}
+
+	got := releasesFromMetadata(metadata, 3)
+	if len(got) != 2 {
+		t.Fatalf("releasesFromMetadata() returned %d releases, want 2: %v", len(got), got)
+	}
+	if got[0].Index != 0 || got[0].ReleaseName != "base-release" || got[0].Namespace != "infra" {
+		t.Errorf("releases[0] = %+v", got[0])
+	}
+	if got[1].Index != 2 || strings.Join(got[1].DependsOn, ",") != "base,other" {
+		t.Errorf("releases[1] = %+v", got[1])
+	}
+}
+
+func TestValidateChartDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		charts  []ChartSpec
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			charts: []ChartSpec{{Name: "a"}, {Name: "b", DependsOn: []string{"a"}}},
+		},
+		{
+			name:    "self reference",
+			charts:  []ChartSpec{{Name: "a", DependsOn: []string{"a"}}},
+			wantErr: "cannot reference itself",
+		},
+		{
+			name:    "forward reference",
+			charts:  []ChartSpec{{Name: "a", DependsOn: []string{"b"}}, {Name: "b"}},
+			wantErr: "must reference a chart installed before it",
+		},
+		{
+			name:    "unknown chart",
+			charts:  []ChartSpec{{Name: "a", DependsOn: []string{"missing"}}},
+			wantErr: "must reference a chart installed before it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateChartDependencies(tt.charts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateChartDependencies() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateChartDependencies() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUninstallGroups_AggregatesErrors(t *testing.T) {
+	groups := [][]uninstallRelease{
+		{{Index: 3, ReleaseName: "d"}, {Index: 0, ReleaseName: "a"}},
+		{{Index: 2, ReleaseName: "c"}},
+		{{Index: 1, ReleaseName: "b"}},
+	}
+
+	var mu sync.Mutex
+	var attempted []string
+	err := uninstallGroups(groups, 3, func(r uninstallRelease) error {
+		mu.Lock()
+		attempted = append(attempted, r.ReleaseName)
+		mu.Unlock()
+		if r.ReleaseName == "d" || r.ReleaseName == "b" {
+			return errors.New("release not found")
+		}
+		return nil
+	})
+
+	// Best effort: every release is attempted despite failures
+	if len(attempted) != 4 {
+		t.Errorf("attempted %v, want all 4 releases", attempted)
+	}
+
+	if err == nil {
+		t.Fatal("uninstallGroups() expected error, got nil")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "failed to uninstall 2/4 chart(s)") {
+		t.Errorf("error = %q, want failure count", msg)
+	}
+	// Errors are reported in reverse install order regardless of scheduling
+	if d, b := strings.Index(msg, "chart d:"), strings.Index(msg, "chart b:"); d < 0 || b < 0 || d > b {
+		t.Errorf("error = %q, want chart d before chart b", msg)
+	}
+}
+
+func TestUninstallGroups_SerialWithinGroup(t *testing.T) {
+	groups := [][]uninstallRelease{
+		{{Index: 2, ReleaseName: "c"}, {Index: 1, ReleaseName: "b"}, {Index: 0, ReleaseName: "a"}},
+	}
+
+	var order []string
+	if err := uninstallGroups(groups, 4, func(r uninstallRelease) error {
+		order = append(order, r.ReleaseName)
+		return nil
+	}); err != nil {
+		t.Fatalf("uninstallGroups() unexpected error: %v", err)
+	}
+
+	if strings.Join(order, ",") != "c,b,a" {
+		t.Errorf("uninstall order = %v, want [c b a]", order)
+	}
+}
+
+func TestUninstallGroups_BoundsConcurrency(t *testing.T) {
+	groups := make([][]uninstallRelease, 6)
+	for i := range groups {
+		groups[i] = []uninstallRelease{{Index: i, ReleaseName: "r"}}
+	}
+
+	var running, peak atomic.Int32
+	err := uninstallGroups(groups, 2, func(uninstallRelease) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("uninstallGroups() unexpected error: %v", err)
+	}
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrency = %d, want 2", got)
+	}
+}
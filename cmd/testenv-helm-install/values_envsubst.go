@@ -0,0 +1,132 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
+)
+
+// envVarNameRegex matches a valid environment variable name.
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// substituteEnvVars replaces ${VAR} and ${VAR:-default} references in content with values from env.
+// Like the shell, the default is used when VAR is unset or empty. Bare $VAR references are left
+// untouched so that values containing "$" are not altered by accident.
+// Returns an error listing every undefined variable that has no default.
+func substituteEnvVars(content string, env map[string]string) (string, error) {
+	var out strings.Builder
+	missing := map[string]bool{}
+
+	rest := content
+	for {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:start])
+
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference %q", rest[start:])
+		}
+		expr := rest[start+2 : start+end]
+		rest = rest[start+end+1:]
+
+		name, defaultValue, hasDefault := strings.Cut(expr, ":-")
+		if !envVarNameRegex.MatchString(name) {
+			return "", fmt.Errorf("invalid variable reference ${%s}", expr)
+		}
+
+		value := env[name]
+		switch {
+		case value != "":
+			out.WriteString(value)
+		case hasDefault:
+			out.WriteString(defaultValue)
+		default:
+			if _, ok := env[name]; !ok {
+				missing[name] = true
+			}
+			// An explicitly empty variable without default is substituted as empty
+		}
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("undefined variable(s) without default: %s", strings.Join(names, ", "))
+	}
+
+	return out.String(), nil
+}
+
+// substituteValuesFiles substitutes environment variables in each values file and writes
+// the results to temporary files. It returns the temporary file paths, in the same order,
+// and a cleanup function removing them.
+func substituteValuesFiles(valuesFiles []string, env map[string]string) ([]string, func(), error) {
+	var tmpFiles []string
+	cleanup := func() {
+		for _, f := range tmpFiles {
+			if err := os.Remove(f); err != nil {
+				log.Printf("Warning: failed to remove substituted values file %s: %v", f, err)
+			}
+		}
+	}
+
+	for _, valuesFile := range valuesFiles {
+		content, err := os.ReadFile(valuesFile)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to read values file %s: %w", valuesFile, err)
+		}
+
+		substituted, err := substituteEnvVars(string(content), env)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to substitute environment variables in values file %s: %w", valuesFile, err)
+		}
+
+		tmpFile, err := tempdir.CreateTemp("helm-values-envsubst-*.yaml")
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create temp values file: %w", err)
+		}
+		tmpFiles = append(tmpFiles, tmpFile.Name())
+
+		if _, err := tmpFile.WriteString(substituted); err != nil {
+			_ = tmpFile.Close()
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write substituted values file: %w", err)
+		}
+		if err := tmpFile.Close(); err != nil {
+			log.Printf("Warning: failed to close temp file: %v", err)
+		}
+
+		log.Printf("Substituted environment variables in values file %s, wrote to: %s", valuesFile, tmpFile.Name())
+	}
+
+	return tmpFiles, cleanup, nil
+}
@@ -0,0 +1,217 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
+)
+
+func TestSubstituteEnvVars(t *testing.T) {
+	env := map[string]string{
+		"REGISTRY": "registry.local:5000",
+		"TAG":      "v1.2.3",
+		"EMPTY":    "",
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "no references",
+			content: "replicas: 1\n",
+			want:    "replicas: 1\n",
+		},
+		{
+			name:    "defined variables",
+			content: "image: ${REGISTRY}/app:${TAG}\n",
+			want:    "image: registry.local:5000/app:v1.2.3\n",
+		},
+		{
+			name:    "default used when undefined",
+			content: "tag: ${MISSING:-latest}\n",
+			want:    "tag: latest\n",
+		},
+		{
+			name:    "default used when empty",
+			content: "tag: ${EMPTY:-latest}\n",
+			want:    "tag: latest\n",
+		},
+		{
+			name:    "default ignored when defined",
+			content: "tag: ${TAG:-latest}\n",
+			want:    "tag: v1.2.3\n",
+		},
+		{
+			name:    "empty default",
+			content: "tag: \"${MISSING:-}\"\n",
+			want:    "tag: \"\"\n",
+		},
+		{
+			name:    "empty variable without default",
+			content: "value: \"${EMPTY}\"\n",
+			want:    "value: \"\"\n",
+		},
+		{
+			name:    "bare dollar references are left unchanged",
+			content: "password: $ecret $TAG\n",
+			want:    "password: $ecret $TAG\n",
+		},
+		{
+			name:    "missing variable",
+			content: "image: ${REGISTRY}/app:${MISSING}\n",
+			wantErr: "undefined variable(s) without default: MISSING",
+		},
+		{
+			name:    "all missing variables are reported",
+			content: "a: ${ZETA}\nb: ${ALPHA}\nc: ${ZETA}\n",
+			wantErr: "undefined variable(s) without default: ALPHA, ZETA",
+		},
+		{
+			name:    "invalid variable name",
+			content: "a: ${1INVALID}\n",
+			wantErr: "invalid variable reference ${1INVALID}",
+		},
+		{
+			name:    "unterminated reference",
+			content: "a: ${REGISTRY\n",
+			wantErr: "unterminated variable reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := substituteEnvVars(tt.content, env)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("substituteEnvVars() expected error containing %q, got nil", tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("substituteEnvVars() error = %q, want error containing %q", err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("substituteEnvVars() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("substituteEnvVars() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstituteValuesFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	if err := os.WriteFile(first, []byte("tag: ${TAG}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("registry: ${REGISTRY:-docker.io}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, cleanup, err := substituteValuesFiles([]string{first, second}, map[string]string{"TAG": "v1"})
+	if err != nil {
+		t.Fatalf("substituteValuesFiles() unexpected error: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("substituteValuesFiles() returned %d files, want 2", len(files))
+	}
+	for i, want := range []string{"tag: v1\n", "registry: docker.io\n"} {
+		got, err := os.ReadFile(files[i])
+		if err != nil {
+			t.Fatalf("failed to read substituted file: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("substituted file %d = %q, want %q", i, string(got), want)
+		}
+	}
+
+	// Original files must not be modified
+	if got, _ := os.ReadFile(first); string(got) != "tag: ${TAG}\n" {
+		t.Errorf("original values file was modified: %q", string(got))
+	}
+
+	cleanup()
+	for _, f := range files {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed by cleanup", f)
+		}
+	}
+}
+
+func TestSubstituteValuesFiles_UnderForgeTmpDir(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "forge-tmp")
+	t.Setenv(tempdir.EnvVar, base)
+
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("tag: ${TAG}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, cleanup, err := substituteValuesFiles([]string{valuesFile}, map[string]string{"TAG": "v1"})
+	if err != nil {
+		t.Fatalf("substituteValuesFiles() unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if len(files) != 1 || filepath.Dir(files[0]) != base {
+		t.Errorf("substituteValuesFiles() = %v, want a file under %s", files, base)
+	}
+}
+
+func TestSubstituteValuesFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(valid, []byte("a: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("b: ${MISSING}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("missing variable", func(t *testing.T) {
+		_, _, err := substituteValuesFiles([]string{valid, invalid}, map[string]string{})
+		if err == nil {
+			t.Fatal("substituteValuesFiles() expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), invalid) || !strings.Contains(err.Error(), "MISSING") {
+			t.Errorf("substituteValuesFiles() error = %q, want file path and variable name", err.Error())
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, _, err := substituteValuesFiles([]string{filepath.Join(dir, "nope.yaml")}, nil)
+		if err == nil {
+			t.Fatal("substituteValuesFiles() expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "failed to read values file") {
+			t.Errorf("substituteValuesFiles() error = %q", err.Error())
+		}
+	})
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log"
+)
+
+// stageValuesFiles returns the chart's values files for the given test stage: the base
+// ValuesFiles followed by the files of the overlay whose key matches the stage, so that
+// the overlay takes precedence. Returns the base values files if no overlay matches.
+func stageValuesFiles(chart ChartSpec, stage string) []string {
+	overlay, ok := chart.ValuesOverlays[stage]
+	if !ok || len(overlay) == 0 {
+		return chart.ValuesFiles
+	}
+
+	log.Printf("Chart %s: applying values overlay for stage %s: %v", chart.Name, stage, overlay)
+
+	valuesFiles := make([]string, 0, len(chart.ValuesFiles)+len(overlay))
+	valuesFiles = append(valuesFiles, chart.ValuesFiles...)
+	return append(valuesFiles, overlay...)
+}
@@ -0,0 +1,91 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStageValuesFiles(t *testing.T) {
+	chart := ChartSpec{
+		Name:        "app",
+		ValuesFiles: []string{"values.yaml", "values-ci.yaml"},
+		ValuesOverlays: map[string][]string{
+			"integration": {"overlays/integration.yaml"},
+			"e2e":         {"overlays/e2e.yaml", "overlays/e2e-kind.yaml"},
+			"empty":       {},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		chart ChartSpec
+		stage string
+		want  []string
+	}{
+		{
+			name:  "integration overlay",
+			chart: chart,
+			stage: "integration",
+			want:  []string{"values.yaml", "values-ci.yaml", "overlays/integration.yaml"},
+		},
+		{
+			name:  "e2e overlay",
+			chart: chart,
+			stage: "e2e",
+			want:  []string{"values.yaml", "values-ci.yaml", "overlays/e2e.yaml", "overlays/e2e-kind.yaml"},
+		},
+		{
+			name:  "no overlay for stage",
+			chart: chart,
+			stage: "unit",
+			want:  []string{"values.yaml", "values-ci.yaml"},
+		},
+		{
+			name:  "empty overlay",
+			chart: chart,
+			stage: "empty",
+			want:  []string{"values.yaml", "values-ci.yaml"},
+		},
+		{
+			name:  "overlay without base values",
+			chart: ChartSpec{ValuesOverlays: map[string][]string{"e2e": {"e2e.yaml"}}},
+			stage: "e2e",
+			want:  []string{"e2e.yaml"},
+		},
+		{
+			name:  "no overlays",
+			chart: ChartSpec{ValuesFiles: []string{"values.yaml"}},
+			stage: "e2e",
+			want:  []string{"values.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stageValuesFiles(tt.chart, tt.stage); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stageValuesFiles(%q) = %v, want %v", tt.stage, got, tt.want)
+			}
+		})
+	}
+
+	// The base values files must not be modified by appending the overlay
+	if !reflect.DeepEqual(chart.ValuesFiles, []string{"values.yaml", "values-ci.yaml"}) {
+		t.Errorf("base ValuesFiles modified: %v", chart.ValuesFiles)
+	}
+}
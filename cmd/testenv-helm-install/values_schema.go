@@ -0,0 +1,233 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
+	"github.com/google/jsonschema-go/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// valuesSchemaFileName is the JSON schema a chart may ship to constrain its values.
+const valuesSchemaFileName = "values.schema.json"
+
+// errValuesSchemaMismatch is returned when the values do not match the chart's values.schema.json.
+var errValuesSchemaMismatch = errors.New("values do not match")
+
+// validateValuesSchema validates the values helm would use for the chart against the
+// chart's values.schema.json, so that invalid values fail before contacting the cluster.
+// Values are composed in helm's order: the chart's values.yaml, then each --values file
+// of valuesArgs. Remote charts (helm repository or OCI references) are pulled at version
+// to read their schema. It is a no-op if the chart has no schema.
+func validateValuesSchema(chartRef, version string, valuesArgs []string) error {
+	chartDir := chartRef
+	info, err := os.Stat(chartRef)
+	switch {
+	case err == nil && !info.IsDir():
+		log.Printf("Skipping values schema validation: %s is a chart archive, not a chart directory", chartRef)
+		return nil
+	case err != nil:
+		pulled, cleanup, err := pullChart(chartRef, version)
+		if err != nil {
+			return fmt.Errorf("failed to pull chart %s to validate its values: %w", chartRef, err)
+		}
+		defer cleanup()
+		chartDir = pulled
+	}
+
+	schemaPath := filepath.Join(chartDir, valuesSchemaFileName)
+	schemaData, err := os.ReadFile(schemaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", schemaPath, err)
+	}
+
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", schemaPath, err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", schemaPath, err)
+	}
+
+	var valuesFiles []string
+	defaults := filepath.Join(chartDir, "values.yaml")
+	if _, err := os.Stat(defaults); err == nil {
+		valuesFiles = append(valuesFiles, defaults)
+	}
+	for i := 0; i+1 < len(valuesArgs); i++ {
+		if valuesArgs[i] == "--values" {
+			valuesFiles = append(valuesFiles, valuesArgs[i+1])
+			i++
+		}
+	}
+
+	values, err := composeValuesFiles(valuesFiles)
+	if err != nil {
+		return err
+	}
+
+	if err := resolved.Validate(values); err != nil {
+		path, reason := schemaErrorValuesPath(err)
+		return fmt.Errorf("%w %s: %s: %s", errValuesSchemaMismatch, schemaPath, path, reason)
+	}
+
+	log.Printf("Values validated against %s", schemaPath)
+	return nil
+}
+
+// pullChart downloads the remote chart chartRef with helm pull and returns the directory
+// of the extracted chart, with a function removing it.
+func pullChart(chartRef, version string) (string, func(), error) {
+	tmpDir, err := tempdir.MkdirTemp("helm-pull-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(tmpDir)
+	}
+
+	args := []string{"pull", chartRef, "--untar", "--untardir", tmpDir}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+	log.Printf("Running: helm %v", args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if output, err := exec.CommandContext(ctx, "helm", args...).CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("helm pull failed: %w, output: %s", err, string(output))
+	}
+
+	// The chart is extracted to a directory named after it
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		cleanup()
+		return "", nil, fmt.Errorf("helm pull extracted %d entries to %s, want a single chart directory", len(entries), tmpDir)
+	}
+	return filepath.Join(tmpDir, entries[0].Name()), cleanup, nil
+}
+
+// composeValuesFiles deep-merges the values files in order, later files taking precedence,
+// and returns the result as a JSON value.
+func composeValuesFiles(valuesFiles []string) (map[string]interface{}, error) {
+	composed := map[string]interface{}{}
+	for _, valuesFile := range valuesFiles {
+		data, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %w", valuesFile, err)
+		}
+
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse values file %s: %w", valuesFile, err)
+		}
+		mergeMap(composed, values)
+	}
+
+	// Round-trip through JSON so that the values only hold JSON types (e.g. float64 numbers)
+	data, err := json.Marshal(composed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert values to JSON: %w", err)
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to convert values to JSON: %w", err)
+	}
+	return result, nil
+}
+
+// schemaErrorLocation matches the schema locations chained in validation errors,
+// e.g. "validating /properties/image/properties/repository: ".
+var schemaErrorLocation = regexp.MustCompile(`validating (root|/[^:]*): `)
+
+// schemaErrorValuesPath extracts from a schema validation error the path of the offending
+// value (e.g. "image.repository", "(root)" for the top level) and the reason it is invalid.
+func schemaErrorValuesPath(err error) (string, string) {
+	msg := err.Error()
+	matches := schemaErrorLocation.FindAllStringSubmatchIndex(msg, -1)
+	if len(matches) == 0 {
+		return "(root)", msg
+	}
+
+	last := matches[len(matches)-1]
+	location := msg[last[2]:last[3]]
+	reason := msg[last[1]:]
+
+	path := schemaLocationToValuesPath(location)
+	if path == "" {
+		path = "(root)"
+	}
+	return path, reason
+}
+
+// schemaLocationToValuesPath converts the JSON pointer of a subschema
+// (e.g. "/properties/hosts/items/properties/name") to the path of the values it
+// constrains (e.g. "hosts[].name"). Keywords that do not descend into the values,
+// such as allOf or $defs, are skipped.
+func schemaLocationToValuesPath(location string) string {
+	segments := strings.Split(strings.TrimPrefix(location, "/"), "/")
+
+	var path strings.Builder
+	for i := 0; i < len(segments); i++ {
+		switch segments[i] {
+		case "properties":
+			if i+1 < len(segments) {
+				i++
+				if path.Len() > 0 {
+					path.WriteByte('.')
+				}
+				// Unescape the JSON pointer token
+				path.WriteString(strings.NewReplacer("~1", "/", "~0", "~").Replace(segments[i]))
+			}
+		case "items", "additionalItems":
+			path.WriteString("[]")
+		case "prefixItems":
+			if i+1 < len(segments) {
+				i++
+				path.WriteString("[" + segments[i] + "]")
+			}
+		case "patternProperties":
+			i++ // Skip the pattern
+			fallthrough
+		case "additionalProperties":
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			path.WriteByte('*')
+		}
+	}
+	return path.String()
+}
@@ -0,0 +1,277 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
+)
+
+const schemaChartPath = "testdata/charts/schema-chart"
+
+func TestValidateValuesSchema(t *testing.T) {
+	valuesDir := t.TempDir()
+	writeValues := func(name, content string) string {
+		path := filepath.Join(valuesDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name      string
+		chartRef  string
+		chart     ChartSpec
+		wantError string
+	}{
+		{
+			name:     "chart defaults are valid",
+			chartRef: schemaChartPath,
+		},
+		{
+			name:     "in-range inline value",
+			chartRef: schemaChartPath,
+			chart:    ChartSpec{Values: map[string]interface{}{"replicaCount": 3}},
+		},
+		{
+			name:      "out-of-range inline value",
+			chartRef:  schemaChartPath,
+			chart:     ChartSpec{Values: map[string]interface{}{"replicaCount": 10}},
+			wantError: ": replicaCount: maximum:",
+		},
+		{
+			name:      "out-of-range value from values file",
+			chartRef:  schemaChartPath,
+			chart:     ChartSpec{ValuesFiles: []string{writeValues("zero.yaml", "replicaCount: 0\n")}},
+			wantError: ": replicaCount: minimum:",
+		},
+		{
+			name:     "inline value overrides invalid values file",
+			chartRef: schemaChartPath,
+			chart: ChartSpec{
+				ValuesFiles: []string{writeValues("many.yaml", "replicaCount: 50\n")},
+				Values:      map[string]interface{}{"replicaCount": 2},
+			},
+		},
+		{
+			name:      "wrong type in nested value",
+			chartRef:  schemaChartPath,
+			chart:     ChartSpec{Values: map[string]interface{}{"image": map[string]interface{}{"repository": 42}}},
+			wantError: `: image.repository: type: 42 has type "integer", want "string"`,
+		},
+		{
+			name:     "chart without schema",
+			chartRef: "testdata/charts/simple-chart",
+			chart:    ChartSpec{Values: map[string]interface{}{"replicaCount": 10}},
+		},
+		{
+			name:     "chart archive is skipped",
+			chartRef: "testdata/charts/schema-chart/Chart.yaml",
+			chart:    ChartSpec{Values: map[string]interface{}{"replicaCount": 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valuesArgs, cleanup, err := composeValuesArgs(tt.chart, "", false)
+			if err != nil {
+				t.Fatalf("composeValuesArgs() error: %v", err)
+			}
+			defer cleanup()
+
+			err = validateValuesSchema(tt.chartRef, "", valuesArgs)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Fatalf("validateValuesSchema() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validateValuesSchema() expected error, got nil")
+			}
+			for _, want := range []string{"values do not match", valuesSchemaFileName, tt.wantError} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateValuesSchema_PullsRemoteChart(t *testing.T) {
+	installFakeHelm(t)
+
+	helmLog := filepath.Join(t.TempDir(), "helm.log")
+	t.Setenv("FAKE_HELM_LOG", helmLog)
+	chartDir, err := filepath.Abs(schemaChartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(values map[string]interface{}) error {
+		t.Helper()
+		valuesArgs, cleanup, err := composeValuesArgs(ChartSpec{Values: values}, "", false)
+		if err != nil {
+			t.Fatalf("composeValuesArgs() error: %v", err)
+		}
+		defer cleanup()
+		return validateValuesSchema("oci://registry.example.com/charts/app", "1.2.3", valuesArgs)
+	}
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Setenv("FAKE_HELM_PULL_CHART", chartDir)
+		err := validate(map[string]interface{}{"replicaCount": 10})
+		if !errors.Is(err, errValuesSchemaMismatch) || !strings.Contains(err.Error(), "replicaCount") {
+			t.Fatalf("validateValuesSchema() error = %v, want a schema mismatch on replicaCount", err)
+		}
+
+		logged, err := os.ReadFile(helmLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(logged), "pull oci://registry.example.com/charts/app --untar") ||
+			!strings.Contains(string(logged), "--version 1.2.3") {
+			t.Errorf("unexpected helm pull invocation %q", logged)
+		}
+	})
+
+	t.Run("valid values", func(t *testing.T) {
+		t.Setenv("FAKE_HELM_PULL_CHART", chartDir)
+		if err := validate(map[string]interface{}{"replicaCount": 3}); err != nil {
+			t.Fatalf("validateValuesSchema() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("pull failure", func(t *testing.T) {
+		t.Setenv("FAKE_HELM_PULL_CHART", "")
+		err := validate(map[string]interface{}{"replicaCount": 3})
+		if err == nil || !strings.Contains(err.Error(), "failed to pull chart") {
+			t.Fatalf("validateValuesSchema() error = %v, want a pull failure", err)
+		}
+	})
+}
+
+func TestCreate_ValidatesValuesSchema(t *testing.T) {
+	installFakeHelm(t)
+
+	tmpDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	helmLog := filepath.Join(tmpDir, "helm.log")
+	t.Setenv("FAKE_HELM_LOG", helmLog)
+
+	chartDir, err := filepath.Abs(schemaChartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := engineframework.CreateInput{
+		TestID: "test-schema",
+		Stage:  "integration",
+		TmpDir: tmpDir,
+		Env:    map[string]string{"KUBECONFIG": kubeconfigPath},
+		Spec: map[string]any{
+			"charts": []any{
+				map[string]any{
+					"name":       "app",
+					"sourceType": "local",
+					"path":       chartDir,
+					"values":     map[string]any{"replicaCount": 10},
+				},
+			},
+		},
+	}
+
+	_, err = Create(context.Background(), input, &Spec{ValidateValuesSchema: true})
+	if err == nil {
+		t.Fatal("Create() expected schema violation error, got nil")
+	}
+	if !strings.Contains(err.Error(), "chart app") || !strings.Contains(err.Error(), "replicaCount") {
+		t.Errorf("error should name the chart and the invalid value, got: %v", err)
+	}
+	if _, err := os.Stat(helmLog); !os.IsNotExist(err) {
+		t.Error("helm install must not run when values do not match the schema")
+	}
+
+	// Validation is opt-in
+	if _, err := Create(context.Background(), input, &Spec{}); err != nil {
+		t.Fatalf("Create() without validateValuesSchema unexpected error: %v", err)
+	}
+}
+
+func TestSchemaErrorValuesPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        string
+		wantPath   string
+		wantReason string
+	}{
+		{
+			name:       "nested property",
+			err:        `validating root: validating /properties/image: validating /properties/image/properties/repository: type: 42 has type "integer", want "string"`,
+			wantPath:   "image.repository",
+			wantReason: `type: 42 has type "integer", want "string"`,
+		},
+		{
+			name:       "top level",
+			err:        `validating root: required: missing properties: ["image"]`,
+			wantPath:   "(root)",
+			wantReason: `required: missing properties: ["image"]`,
+		},
+		{
+			name:       "unknown format",
+			err:        "boom",
+			wantPath:   "(root)",
+			wantReason: "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, reason := schemaErrorValuesPath(errors.New(tt.err))
+			if path != tt.wantPath || reason != tt.wantReason {
+				t.Errorf("schemaErrorValuesPath() = (%q, %q), want (%q, %q)", path, reason, tt.wantPath, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSchemaLocationToValuesPath(t *testing.T) {
+	tests := map[string]string{
+		"/properties/replicaCount":                                   "replicaCount",
+		"/properties/ingress/properties/hosts/items/properties/host": "ingress.hosts[].host",
+		"/properties/env/additionalProperties":                       "env.*",
+		"/properties/labels/patternProperties/^app/properties/name":  "labels.*.name",
+		"/properties/ports/prefixItems/0":                            "ports[0]",
+		"/allOf/1/properties/a~1b/properties/c~0d":                   "a/b.c~d",
+		"/$defs/image/properties/tag":                                "tag",
+	}
+
+	for location, want := range tests {
+		if got := schemaLocationToValuesPath(location); got != want {
+			t.Errorf("schemaLocationToValuesPath(%q) = %q, want %q", location, got, want)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: forge-dev.yaml + spec.openapi.yaml
-// SourceChecksum: sha256:f6925db46930231328dc814fddf5235e413f8c0c0b888b813080e2e07b579bc2
+// SourceChecksum: sha256:cc5394f289dce6723d9179f11c766bf6d35c0e9d39f871b0fc7f43ec07ed44a5
 
 package main
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
 // Name is the engine name.
@@ -34,26 +35,49 @@ func main() {
 		RunCLI:         nil, // Generated engines are MCP-only
 		RunMCP:         runMCPServer,
 		DocsConfig:     docsConfig,
+		SetupMCP:       setupMCPServer,
 	})
 }
 
 // runMCPServer creates and runs the MCP server.
 func runMCPServer() error {
+	server, err := setupMCPServer()
+	if err != nil {
+		return err
+	}
+
+	if err := server.Run(enginecli.Context()); err != nil {
+		return fmt.Errorf("running MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// setupMCPServer creates the MCP server and registers all its tools.
+func setupMCPServer() (*mcpserver.Server, error) {
 	server, err := SetupMCPServer(Name, Version, Create, Delete)
 	if err != nil {
-		return fmt.Errorf("setting up MCP server: %w", err)
+		return nil, fmt.Errorf("setting up MCP server: %w", err)
 	}
 
 	// Register docs MCP tools (docs-list, docs-get)
 	if err := RegisterDocsMCPTools(server); err != nil {
-		return fmt.Errorf("registering docs MCP tools: %w", err)
+		return nil, fmt.Errorf("registering docs MCP tools: %w", err)
 	}
 
-	if err := server.Run(context.Background()); err != nil {
-		return fmt.Errorf("running MCP server: %w", err)
+	// Register self-test MCP tool
+	if err := engineframework.RegisterSelfTestTool(server, engineframework.SelfTestConfig{
+		Name:    Name,
+		Version: Version,
+		Dependencies: []engineframework.Dependency{
+			{Name: "helm", Command: "helm", Args: []string{"version", "--short"}},
+			{Name: "kubectl", Command: "kubectl", Args: []string{"version", "--client"}},
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("registering self-test MCP tool: %w", err)
 	}
 
-	return nil
+	return server, nil
 }
 
 // Create is the create function that must be implemented by the engine author.
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:f6925db46930231328dc814fddf5235e413f8c0c0b888b813080e2e07b579bc2
+// SourceChecksum: sha256:cc5394f289dce6723d9179f11c766bf6d35c0e9d39f871b0fc7f43ec07ed44a5
 
 package main
 
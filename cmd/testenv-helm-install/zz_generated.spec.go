@@ -1,14 +1,28 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:f6925db46930231328dc814fddf5235e413f8c0c0b888b813080e2e07b579bc2
+// SourceChecksum: sha256:cc5394f289dce6723d9179f11c766bf6d35c0e9d39f871b0fc7f43ec07ed44a5
 
 package main
 
+import (
+	"fmt"
+)
+
 // Spec represents the Spec configuration.
 // Configuration for testenv-helm-install.
 // The charts array contains ChartSpec objects that are parsed separately.
 // This Spec only captures top-level configuration options.
 type Spec struct {
+	// Overall time budget for installing all charts (e.g., 20m). Remaining installs are aborted once exceeded. Defaults to the TESTENV_HELM_INSTALL_TIMEOUT environment variable, unlimited if unset
+	InstallTimeout string `json:"installTimeout,omitempty"`
+	// Render charts with helm template into tmpDir instead of installing them (for debugging)
+	RenderOnly bool `json:"renderOnly,omitempty"`
+	// Maximum number of charts uninstalled concurrently on delete (default 1, serial). Charts sharing a namespace or linked by dependsOn are always uninstalled serially in reverse install order
+	UninstallParallelism int `json:"uninstallParallelism,omitempty"`
+	// Validate the composed values of each chart against the chart's values.schema.json, when present, before installing or rendering it
+	ValidateValuesSchema bool `json:"validateValuesSchema,omitempty"`
+	// Substitute ${VAR} and ${VAR:-default} references in chart valuesFiles with environment variables from previous subengines before passing them to helm
+	ValuesEnvSubst bool `json:"valuesEnvSubst,omitempty"`
 }
 
 // SpecFromMap creates a Spec from a map[string]interface{}.
@@ -18,6 +32,51 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	}
 
 	s := &Spec{}
+	// Parse installTimeout
+	if v, ok := m["installTimeout"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.InstallTimeout = val
+		} else {
+			return nil, fmt.Errorf("field installTimeout: expected string, got %T", v)
+		}
+	}
+	// Parse renderOnly
+	if v, ok := m["renderOnly"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.RenderOnly = val
+		} else {
+			return nil, fmt.Errorf("field renderOnly: expected bool, got %T", v)
+		}
+	}
+	// Parse uninstallParallelism
+	if v, ok := m["uninstallParallelism"]; ok && v != nil {
+		switch val := v.(type) {
+		case int:
+			s.UninstallParallelism = val
+		case int64:
+			s.UninstallParallelism = int(val)
+		case float64:
+			s.UninstallParallelism = int(val)
+		default:
+			return nil, fmt.Errorf("field uninstallParallelism: expected int, got %T", v)
+		}
+	}
+	// Parse validateValuesSchema
+	if v, ok := m["validateValuesSchema"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.ValidateValuesSchema = val
+		} else {
+			return nil, fmt.Errorf("field validateValuesSchema: expected bool, got %T", v)
+		}
+	}
+	// Parse valuesEnvSubst
+	if v, ok := m["valuesEnvSubst"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.ValuesEnvSubst = val
+		} else {
+			return nil, fmt.Errorf("field valuesEnvSubst: expected bool, got %T", v)
+		}
+	}
 	return s, nil
 }
 
@@ -28,6 +87,21 @@ func (s *Spec) ToMap() map[string]interface{} {
 	}
 
 	m := make(map[string]interface{})
+	if s.InstallTimeout != "" {
+		m["installTimeout"] = s.InstallTimeout
+	}
+	if s.RenderOnly {
+		m["renderOnly"] = s.RenderOnly
+	}
+	if s.UninstallParallelism != 0 {
+		m["uninstallParallelism"] = s.UninstallParallelism
+	}
+	if s.ValidateValuesSchema {
+		m["validateValuesSchema"] = s.ValidateValuesSchema
+	}
+	if s.ValuesEnvSubst {
+		m["valuesEnvSubst"] = s.ValuesEnvSubst
+	}
 	return m
 }
 
@@ -0,0 +1,268 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// knownNodeImages maps Kubernetes versions to the kindest/node images published
+// for them. Only these versions are accepted by spec.k8sVersion.
+var knownNodeImages = map[string]string{
+	"v1.32.2":  "kindest/node:v1.32.2",
+	"v1.32.0":  "kindest/node:v1.32.0",
+	"v1.31.6":  "kindest/node:v1.31.6",
+	"v1.31.4":  "kindest/node:v1.31.4",
+	"v1.31.0":  "kindest/node:v1.31.0",
+	"v1.30.10": "kindest/node:v1.30.10",
+	"v1.30.8":  "kindest/node:v1.30.8",
+	"v1.30.4":  "kindest/node:v1.30.4",
+	"v1.29.14": "kindest/node:v1.29.14",
+	"v1.29.12": "kindest/node:v1.29.12",
+	"v1.29.8":  "kindest/node:v1.29.8",
+	"v1.28.15": "kindest/node:v1.28.15",
+	"v1.28.13": "kindest/node:v1.28.13",
+	"v1.27.16": "kindest/node:v1.27.16",
+}
+
+// clusterOptions describes the topology of the kind cluster to create.
+type clusterOptions struct {
+	// Nodes is the total number of nodes: one control-plane and Nodes-1 workers.
+	Nodes int
+	// K8sVersion is the normalized Kubernetes version (empty for kind's default).
+	K8sVersion string
+	// Image is the kindest/node image for every node (empty for kind's default).
+	Image string
+	// ExtraMounts are mounted into every node, with absolute host paths.
+	ExtraMounts []Mount
+	// ExtraPortMappings are exposed by the control-plane node, with upper-case protocols.
+	ExtraPortMappings []PortMapping
+}
+
+// defaultClusterOptions returns kind's default single-node cluster.
+func defaultClusterOptions() clusterOptions {
+	return clusterOptions{Nodes: 1}
+}
+
+// resolveClusterOptions validates spec.nodes and spec.k8sVersion and returns the cluster options.
+// nodesSet reports whether spec.nodes was explicitly provided, so that nodes: 0 is rejected
+// instead of silently falling back to the default.
+func resolveClusterOptions(spec *Spec, nodesSet bool) (clusterOptions, error) {
+	opts := defaultClusterOptions()
+	if spec == nil {
+		return opts, nil
+	}
+
+	if nodesSet || spec.Nodes != 0 {
+		if spec.Nodes < 1 {
+			return clusterOptions{}, fmt.Errorf("invalid spec.nodes %d: must be >= 1", spec.Nodes)
+		}
+		opts.Nodes = spec.Nodes
+	}
+
+	if spec.K8sVersion != "" {
+		version, image, err := resolveNodeImage(spec.K8sVersion)
+		if err != nil {
+			return clusterOptions{}, err
+		}
+		opts.K8sVersion = version
+		opts.Image = image
+	}
+
+	return opts, nil
+}
+
+// resolveExtraMounts checks that the host paths of mounts exist and returns the mounts with
+// host paths made absolute; relative host paths are relative to rootDir.
+func resolveExtraMounts(mounts []Mount, rootDir string) ([]Mount, error) {
+	resolved := make([]Mount, 0, len(mounts))
+	for i, m := range mounts {
+		if m.HostPath == "" || m.ContainerPath == "" {
+			return nil, fmt.Errorf("invalid spec.extraMounts[%d]: hostPath and containerPath are required", i)
+		}
+		if !filepath.IsAbs(m.ContainerPath) {
+			return nil, fmt.Errorf("invalid spec.extraMounts[%d]: containerPath %q must be absolute", i, m.ContainerPath)
+		}
+		if !filepath.IsAbs(m.HostPath) {
+			m.HostPath = filepath.Join(rootDir, m.HostPath)
+		}
+		if _, err := os.Stat(m.HostPath); err != nil {
+			return nil, fmt.Errorf("invalid spec.extraMounts[%d]: host path %s: %w", i, m.HostPath, err)
+		}
+		resolved = append(resolved, m)
+	}
+	return resolved, nil
+}
+
+// resolvePortMappings checks that the ports of mappings are in range and that no host port is
+// mapped twice for the same protocol and address. It returns the mappings with normalized protocols.
+func resolvePortMappings(mappings []PortMapping) ([]PortMapping, error) {
+	resolved := make([]PortMapping, 0, len(mappings))
+	seen := make(map[string]int, len(mappings))
+	for i, m := range mappings {
+		if m.ContainerPort < 1 || m.ContainerPort > 65535 {
+			return nil, fmt.Errorf("invalid spec.extraPortMappings[%d]: containerPort %d must be in 1-65535", i, m.ContainerPort)
+		}
+		if m.HostPort < 1 || m.HostPort > 65535 {
+			return nil, fmt.Errorf("invalid spec.extraPortMappings[%d]: hostPort %d must be in 1-65535", i, m.HostPort)
+		}
+
+		m.Protocol = strings.ToUpper(m.Protocol)
+		if m.Protocol == "" {
+			m.Protocol = "TCP"
+		}
+		if m.Protocol != "TCP" && m.Protocol != "UDP" && m.Protocol != "SCTP" {
+			return nil, fmt.Errorf("invalid spec.extraPortMappings[%d]: protocol %q must be TCP, UDP or SCTP", i, m.Protocol)
+		}
+
+		key := portMappingHost(m) + "/" + m.Protocol
+		if j, ok := seen[key]; ok {
+			return nil, fmt.Errorf("invalid spec.extraPortMappings[%d]: host port %s is already mapped by spec.extraPortMappings[%d]", i, key, j)
+		}
+		seen[key] = i
+		resolved = append(resolved, m)
+	}
+	return resolved, nil
+}
+
+// portMappingHost returns the host side of m: [listenAddress:]hostPort.
+func portMappingHost(m PortMapping) string {
+	if m.ListenAddress != "" {
+		return m.ListenAddress + ":" + strconv.Itoa(m.HostPort)
+	}
+	return strconv.Itoa(m.HostPort)
+}
+
+// formatExtraMounts formats mounts as comma-separated hostPath:containerPath[:ro] entries.
+func formatExtraMounts(mounts []Mount) string {
+	entries := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		entry := m.HostPath + ":" + m.ContainerPath
+		if m.ReadOnly {
+			entry += ":ro"
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// formatPortMappings formats mappings as comma-separated [listenAddress:]hostPort:containerPort/protocol entries.
+func formatPortMappings(mappings []PortMapping) string {
+	entries := make([]string, 0, len(mappings))
+	for _, m := range mappings {
+		entries = append(entries, fmt.Sprintf("%s:%d/%s", portMappingHost(m), m.ContainerPort, m.Protocol))
+	}
+	return strings.Join(entries, ",")
+}
+
+// resolveNodeImage maps a Kubernetes version to a known kindest/node image.
+// It accepts full versions with or without the "v" prefix (v1.31.0, 1.31.0)
+// and minor versions (1.31), which resolve to the latest known patch release.
+func resolveNodeImage(k8sVersion string) (string, string, error) {
+	version := strings.TrimSpace(k8sVersion)
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	if image, ok := knownNodeImages[version]; ok {
+		return version, image, nil
+	}
+
+	// Minor version: pick the latest known patch release
+	if strings.Count(version, ".") == 1 {
+		var latest string
+		var latestPatch int
+		for known := range knownNodeImages {
+			if !strings.HasPrefix(known, version+".") {
+				continue
+			}
+			var patch int
+			if _, err := fmt.Sscanf(strings.TrimPrefix(known, version+"."), "%d", &patch); err != nil {
+				continue
+			}
+			if latest == "" || patch > latestPatch {
+				latest, latestPatch = known, patch
+			}
+		}
+		if latest != "" {
+			return latest, knownNodeImages[latest], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unsupported spec.k8sVersion %q: no known kindest/node image (known versions: %s)",
+		k8sVersion, strings.Join(knownVersions(), ", "))
+}
+
+// knownVersions returns the supported Kubernetes versions in sorted order.
+func knownVersions() []string {
+	versions := make([]string, 0, len(knownNodeImages))
+	for v := range knownNodeImages {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// renderKindConfig returns the kind cluster configuration for opts: the containerd
+// patches from kindConfigContent followed by the node list. Extra mounts are added to
+// every node and extra port mappings to the control-plane node only, since a host port
+// can only be bound once.
+func renderKindConfig(opts clusterOptions) string {
+	nodes := opts.Nodes
+	if nodes < 1 {
+		nodes = 1
+	}
+
+	var b strings.Builder
+	b.WriteString(kindConfigContent)
+	b.WriteString("nodes:\n")
+	for i := 0; i < nodes; i++ {
+		role := "worker"
+		if i == 0 {
+			role = "control-plane"
+		}
+		fmt.Fprintf(&b, "- role: %s\n", role)
+		if opts.Image != "" {
+			fmt.Fprintf(&b, "  image: %s\n", opts.Image)
+		}
+		if len(opts.ExtraMounts) > 0 {
+			b.WriteString("  extraMounts:\n")
+			for _, m := range opts.ExtraMounts {
+				fmt.Fprintf(&b, "  - hostPath: %q\n", m.HostPath)
+				fmt.Fprintf(&b, "    containerPath: %q\n", m.ContainerPath)
+				if m.ReadOnly {
+					b.WriteString("    readOnly: true\n")
+				}
+			}
+		}
+		if i == 0 && len(opts.ExtraPortMappings) > 0 {
+			b.WriteString("  extraPortMappings:\n")
+			for _, m := range opts.ExtraPortMappings {
+				fmt.Fprintf(&b, "  - containerPort: %d\n", m.ContainerPort)
+				fmt.Fprintf(&b, "    hostPort: %d\n", m.HostPort)
+				if m.ListenAddress != "" {
+					fmt.Fprintf(&b, "    listenAddress: %q\n", m.ListenAddress)
+				}
+				fmt.Fprintf(&b, "    protocol: %s\n", m.Protocol)
+			}
+		}
+	}
+	return b.String()
+}
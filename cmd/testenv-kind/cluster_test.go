@@ -0,0 +1,299 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// kindConfig is the subset of the kind cluster configuration checked by the tests.
+type kindConfig struct {
+	Kind                    string   `json:"kind"`
+	ContainerdConfigPatches []string `json:"containerdConfigPatches"`
+	Nodes                   []struct {
+		Role        string `json:"role"`
+		Image       string `json:"image"`
+		ExtraMounts []struct {
+			HostPath      string `json:"hostPath"`
+			ContainerPath string `json:"containerPath"`
+			ReadOnly      bool   `json:"readOnly"`
+		} `json:"extraMounts"`
+		ExtraPortMappings []struct {
+			ContainerPort int    `json:"containerPort"`
+			HostPort      int    `json:"hostPort"`
+			ListenAddress string `json:"listenAddress"`
+			Protocol      string `json:"protocol"`
+		} `json:"extraPortMappings"`
+	} `json:"nodes"`
+}
+
+func TestRenderKindConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        clusterOptions
+		wantRoles   []string
+		wantImage   string
+		wantPatched bool
+	}{
+		{
+			name:        "default single node",
+			opts:        defaultClusterOptions(),
+			wantRoles:   []string{"control-plane"},
+			wantPatched: true,
+		},
+		{
+			name:        "three nodes with image",
+			opts:        clusterOptions{Nodes: 3, K8sVersion: "v1.31.0", Image: "kindest/node:v1.31.0"},
+			wantRoles:   []string{"control-plane", "worker", "worker"},
+			wantImage:   "kindest/node:v1.31.0",
+			wantPatched: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg kindConfig
+			if err := yaml.Unmarshal([]byte(renderKindConfig(tt.opts)), &cfg); err != nil {
+				t.Fatalf("generated kind config is not valid YAML: %v", err)
+			}
+
+			if cfg.Kind != "Cluster" {
+				t.Errorf("Expected kind Cluster, got %q", cfg.Kind)
+			}
+			if tt.wantPatched && (len(cfg.ContainerdConfigPatches) != 1 ||
+				!strings.Contains(cfg.ContainerdConfigPatches[0], "/etc/containerd/certs.d")) {
+				t.Errorf("Expected containerd certs.d patch to be preserved, got %v", cfg.ContainerdConfigPatches)
+			}
+			if len(cfg.Nodes) != len(tt.wantRoles) {
+				t.Fatalf("Expected %d nodes, got %d", len(tt.wantRoles), len(cfg.Nodes))
+			}
+			for i, node := range cfg.Nodes {
+				if node.Role != tt.wantRoles[i] {
+					t.Errorf("Node %d: expected role %s, got %s", i, tt.wantRoles[i], node.Role)
+				}
+				if node.Image != tt.wantImage {
+					t.Errorf("Node %d: expected image %q, got %q", i, tt.wantImage, node.Image)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveClusterOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     *Spec
+		nodesSet bool
+		want     clusterOptions
+		wantErr  string
+	}{
+		{
+			name: "nil spec defaults to one node",
+			spec: nil,
+			want: clusterOptions{Nodes: 1},
+		},
+		{
+			name: "unset nodes defaults to one node",
+			spec: &Spec{},
+			want: clusterOptions{Nodes: 1},
+		},
+		{
+			name:     "explicit node count",
+			spec:     &Spec{Nodes: 3},
+			nodesSet: true,
+			want:     clusterOptions{Nodes: 3},
+		},
+		{
+			name:     "explicit zero nodes is rejected",
+			spec:     &Spec{Nodes: 0},
+			nodesSet: true,
+			wantErr:  "invalid spec.nodes 0: must be >= 1",
+		},
+		{
+			name:     "negative nodes is rejected",
+			spec:     &Spec{Nodes: -2},
+			nodesSet: true,
+			wantErr:  "invalid spec.nodes -2: must be >= 1",
+		},
+		{
+			name: "full version without prefix",
+			spec: &Spec{K8sVersion: "1.31.0"},
+			want: clusterOptions{Nodes: 1, K8sVersion: "v1.31.0", Image: "kindest/node:v1.31.0"},
+		},
+		{
+			name: "minor version resolves to latest known patch",
+			spec: &Spec{K8sVersion: "v1.30"},
+			want: clusterOptions{Nodes: 1, K8sVersion: "v1.30.10", Image: "kindest/node:v1.30.10"},
+		},
+		{
+			name:    "unknown version is rejected",
+			spec:    &Spec{K8sVersion: "v1.99.0"},
+			wantErr: `unsupported spec.k8sVersion "v1.99.0"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveClusterOptions(tt.spec, tt.nodesSet)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveClusterOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveClusterOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderKindConfig_ExtraMountsAndPortMappings(t *testing.T) {
+	opts := clusterOptions{
+		Nodes: 2,
+		ExtraMounts: []Mount{
+			{HostPath: "/srv/fixtures", ContainerPath: "/fixtures", ReadOnly: true},
+			{HostPath: "/tmp/my data", ContainerPath: "/data"},
+		},
+		ExtraPortMappings: []PortMapping{
+			{ContainerPort: 30080, HostPort: 8080, Protocol: "TCP"},
+			{ContainerPort: 30053, HostPort: 5353, ListenAddress: "127.0.0.1", Protocol: "UDP"},
+		},
+	}
+
+	var cfg kindConfig
+	if err := yaml.Unmarshal([]byte(renderKindConfig(opts)), &cfg); err != nil {
+		t.Fatalf("generated kind config is not valid YAML: %v", err)
+	}
+	if len(cfg.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(cfg.Nodes))
+	}
+
+	for i, node := range cfg.Nodes {
+		if len(node.ExtraMounts) != 2 {
+			t.Fatalf("Node %d: expected 2 extra mounts, got %+v", i, node.ExtraMounts)
+		}
+		if m := node.ExtraMounts[0]; m.HostPath != "/srv/fixtures" || m.ContainerPath != "/fixtures" || !m.ReadOnly {
+			t.Errorf("Node %d: unexpected first mount %+v", i, m)
+		}
+		if m := node.ExtraMounts[1]; m.HostPath != "/tmp/my data" || m.ContainerPath != "/data" || m.ReadOnly {
+			t.Errorf("Node %d: unexpected second mount %+v", i, m)
+		}
+	}
+
+	ports := cfg.Nodes[0].ExtraPortMappings
+	if len(ports) != 2 {
+		t.Fatalf("Expected 2 port mappings on the control-plane, got %+v", ports)
+	}
+	if p := ports[0]; p.ContainerPort != 30080 || p.HostPort != 8080 || p.ListenAddress != "" || p.Protocol != "TCP" {
+		t.Errorf("Unexpected first port mapping %+v", p)
+	}
+	if p := ports[1]; p.ContainerPort != 30053 || p.HostPort != 5353 || p.ListenAddress != "127.0.0.1" || p.Protocol != "UDP" {
+		t.Errorf("Unexpected second port mapping %+v", p)
+	}
+	if len(cfg.Nodes[1].ExtraPortMappings) != 0 {
+		t.Errorf("Expected no port mappings on the worker, got %+v", cfg.Nodes[1].ExtraPortMappings)
+	}
+}
+
+func TestResolveExtraMounts(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(rootDir, "fixtures"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	absDir := t.TempDir()
+
+	got, err := resolveExtraMounts([]Mount{
+		{HostPath: "fixtures", ContainerPath: "/fixtures", ReadOnly: true},
+		{HostPath: absDir, ContainerPath: "/data"},
+	}, rootDir)
+	if err != nil {
+		t.Fatalf("resolveExtraMounts() error = %v", err)
+	}
+	want := []Mount{
+		{HostPath: filepath.Join(rootDir, "fixtures"), ContainerPath: "/fixtures", ReadOnly: true},
+		{HostPath: absDir, ContainerPath: "/data"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveExtraMounts() = %+v, want %+v", got, want)
+	}
+	if metadata := formatExtraMounts(got); metadata != filepath.Join(rootDir, "fixtures")+":/fixtures:ro,"+absDir+":/data" {
+		t.Errorf("formatExtraMounts() = %q", metadata)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		mount   Mount
+		wantErr string
+	}{
+		{name: "missing host path", mount: Mount{HostPath: "missing", ContainerPath: "/data"}, wantErr: "invalid spec.extraMounts[0]: host path"},
+		{name: "relative container path", mount: Mount{HostPath: "fixtures", ContainerPath: "data"}, wantErr: "must be absolute"},
+		{name: "empty host path", mount: Mount{ContainerPath: "/data"}, wantErr: "hostPath and containerPath are required"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveExtraMounts([]Mount{tt.mount}, rootDir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestResolvePortMappings(t *testing.T) {
+	got, err := resolvePortMappings([]PortMapping{
+		{ContainerPort: 30080, HostPort: 8080},
+		{ContainerPort: 30080, HostPort: 8080, Protocol: "udp"},
+		{ContainerPort: 30443, HostPort: 8443, ListenAddress: "127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("resolvePortMappings() error = %v", err)
+	}
+	if metadata := formatPortMappings(got); metadata != "8080:30080/TCP,8080:30080/UDP,127.0.0.1:8443:30443/TCP" {
+		t.Errorf("formatPortMappings() = %q", metadata)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		mappings []PortMapping
+		wantErr  string
+	}{
+		{name: "container port out of range", mappings: []PortMapping{{ContainerPort: 0, HostPort: 8080}}, wantErr: "containerPort 0 must be in 1-65535"},
+		{name: "host port out of range", mappings: []PortMapping{{ContainerPort: 80, HostPort: 70000}}, wantErr: "hostPort 70000 must be in 1-65535"},
+		{name: "unknown protocol", mappings: []PortMapping{{ContainerPort: 80, HostPort: 8080, Protocol: "http"}}, wantErr: `protocol "HTTP" must be TCP, UDP or SCTP`},
+		{
+			name:     "duplicate host port",
+			mappings: []PortMapping{{ContainerPort: 80, HostPort: 8080}, {ContainerPort: 81, HostPort: 8080, Protocol: "TCP"}},
+			wantErr:  "host port 8080/TCP is already mapped by spec.extraPortMappings[0]",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolvePortMappings(tt.mappings)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
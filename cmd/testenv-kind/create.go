@@ -18,7 +18,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/alexandremahdhaoui/forge/pkg/engineframework"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
@@ -28,10 +31,30 @@ import (
 func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec) (*engineframework.TestEnvArtifact, error) {
 	log.Printf("Creating kind cluster: testID=%s, stage=%s", input.TestID, input.Stage)
 
-	// RootDir is available via input.RootDir for resolving relative paths
-	// Currently unused, but available for future features that may need path resolution
-	// (e.g., custom kind config files with relative paths)
-	_ = input.RootDir // Acknowledge availability for consistency
+	// Resolve the requested node count and node image
+	_, nodesSet := input.Spec["nodes"]
+	opts, err := resolveClusterOptions(spec, nodesSet)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the extra mounts (relative host paths are relative to input.RootDir) and port mappings
+	if spec != nil {
+		if opts.ExtraMounts, err = resolveExtraMounts(spec.ExtraMounts, input.RootDir); err != nil {
+			return nil, err
+		}
+		if opts.ExtraPortMappings, err = resolvePortMappings(spec.ExtraPortMappings); err != nil {
+			return nil, err
+		}
+	}
+
+	var images []string
+	if spec != nil {
+		if err := validatePreloadImages(spec.PreloadImages); err != nil {
+			return nil, err
+		}
+		images = spec.PreloadImages
+	}
 
 	// Read forge.yaml configuration
 	config, err := forge.ReadSpec()
@@ -45,17 +68,70 @@ func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec)
 		return nil, fmt.Errorf("failed to read environment variables: %w", err)
 	}
 
-	// Generate cluster name and kubeconfig path
-	clusterName := fmt.Sprintf("%s-%s", config.Name, input.TestID)
+	// Generate cluster name and kubeconfig path.
+	// spec.name replaces the test ID suffix, giving a stable name that can be reused across runs.
+	suffix := input.TestID
+	if spec != nil && spec.Name != "" {
+		suffix = spec.Name
+	}
+	clusterName := fmt.Sprintf("%s-%s", config.Name, suffix)
 	kubeconfigPath := filepath.Join(input.TmpDir, "kubeconfig")
 
 	// Update config with cluster-specific values
 	config.Name = clusterName
 	config.Kindenv.KubeconfigPath = kubeconfigPath
 
-	// Create the kind cluster
-	if err := doSetup(config, envs); err != nil {
-		return nil, fmt.Errorf("failed to create kind cluster: %w", err)
+	reuse := spec != nil && spec.Reuse
+
+	// Dry-run: report the planned cluster without creating it
+	var plan []string
+	created := true
+	if input.DryRun {
+		action := fmt.Sprintf("create kind cluster %s with %d node(s)", clusterName, opts.Nodes)
+		if reuse {
+			action = fmt.Sprintf("reuse kind cluster %s if present and healthy, otherwise %s", clusterName, action)
+		}
+		plan = []string{
+			action,
+			fmt.Sprintf("write kubeconfig to %s", kubeconfigPath),
+		}
+		for _, image := range images {
+			plan = append(plan, fmt.Sprintf("load image %s into kind cluster %s", image, clusterName))
+		}
+		log.Printf("Dry-run: would create kind cluster %s", clusterName)
+	} else {
+		loader := newImageLoader(envs)
+		if err := ensureImagesPresent(loader, images, spec != nil && spec.PullIfMissing); err != nil {
+			return nil, err
+		}
+
+		if reuse {
+			decision, reason, err := decideReuse(newKindProbe(envs), clusterName, kubeconfigPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check for reusable kind cluster: %w", err)
+			}
+
+			switch decision {
+			case reuseExisting:
+				log.Printf("Reusing existing kind cluster %s", clusterName)
+				created = false
+			case reuseRecreate:
+				log.Printf("Recreating stale kind cluster %s: %s", clusterName, reason)
+				if err := doTeardown(config, envs); err != nil {
+					return nil, fmt.Errorf("failed to delete stale kind cluster %s: %w", clusterName, err)
+				}
+			}
+		}
+
+		if created {
+			if err := doSetup(config, envs, opts); err != nil {
+				return nil, fmt.Errorf("failed to create kind cluster: %w", err)
+			}
+		}
+
+		if err := preloadImages(loader, clusterName, images); err != nil {
+			return nil, err
+		}
 	}
 
 	// Prepare files map (relative paths within tmpDir)
@@ -67,6 +143,21 @@ func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec)
 	metadata := map[string]string{
 		"testenv-kind.clusterName":    clusterName,
 		"testenv-kind.kubeconfigPath": kubeconfigPath,
+		"testenv-kind.nodeCount":      strconv.Itoa(opts.Nodes),
+		"testenv-kind.created":        strconv.FormatBool(created),
+	}
+	if opts.Image != "" {
+		metadata["testenv-kind.k8sVersion"] = opts.K8sVersion
+		metadata["testenv-kind.nodeImage"] = opts.Image
+	}
+	if len(opts.ExtraMounts) > 0 {
+		metadata["testenv-kind.extraMounts"] = formatExtraMounts(opts.ExtraMounts)
+	}
+	if len(opts.ExtraPortMappings) > 0 {
+		metadata["testenv-kind.extraPortMappings"] = formatPortMappings(opts.ExtraPortMappings)
+	}
+	if len(images) > 0 {
+		metadata["testenv-kind.preloadedImages"] = strings.Join(images, ",")
 	}
 
 	// Prepare managed resources (for cleanup)
@@ -83,6 +174,7 @@ func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec)
 		Env: map[string]string{
 			"KUBECONFIG": kubeconfigPath,
 		},
+		Plan: plan,
 	}, nil
 }
 
@@ -125,6 +217,26 @@ func Delete(ctx context.Context, input engineframework.DeleteInput, _ *Spec) err
 	config.Name = clusterName
 	config.Kindenv.KubeconfigPath = kubeconfigPath
 
+	// A reused cluster was not created by this test environment: keep it, only remove the kubeconfig.
+	if !createdCluster(input.Metadata) {
+		if input.DryRun {
+			log.Printf("Dry-run: would keep reused kind cluster %s", clusterName)
+			return nil
+		}
+		log.Printf("Keeping reused kind cluster %s", clusterName)
+		if kubeconfigPath != "" {
+			if err := os.Remove(kubeconfigPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove kubeconfig %s: %w", kubeconfigPath, err)
+			}
+		}
+		return nil
+	}
+
+	if input.DryRun {
+		log.Printf("Dry-run: would delete kind cluster %s", clusterName)
+		return nil
+	}
+
 	// Delete the kind cluster - return error on failure to prevent silent leaks
 	if err := doTeardown(config, envs); err != nil {
 		return fmt.Errorf("failed to delete kind cluster %s: %w", clusterName, err)
@@ -0,0 +1,113 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/internal/util"
+)
+
+// defaultContainerEngineBinary inspects and pulls images when CONTAINER_ENGINE_BINARY is not set.
+const defaultContainerEngineBinary = "docker"
+
+// imageLoader loads local images into kind clusters. It is a struct of functions so
+// preloading can be tested without a container engine or kind.
+type imageLoader struct {
+	// imagePresent reports whether the image is present in the local container engine.
+	imagePresent func(image string) (bool, error)
+	// pullImage pulls the image into the local container engine.
+	pullImage func(image string) error
+	// loadImage loads the local image into the nodes of the named cluster.
+	loadImage func(clusterName, image string) error
+}
+
+// newImageLoader returns an imageLoader backed by the container engine and the kind binary.
+func newImageLoader(envs Envs) imageLoader {
+	engine := envs.ContainerEngineBinary
+	if engine == "" {
+		engine = defaultContainerEngineBinary
+	}
+
+	return imageLoader{
+		imagePresent: func(image string) (bool, error) {
+			err := exec.Command(engine, "image", "inspect", image).Run()
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return false, nil
+			}
+			if err != nil {
+				return false, fmt.Errorf("failed to inspect image %s: %w", image, err)
+			}
+			return true, nil
+		},
+		pullImage: func(image string) error {
+			return util.RunCmdWithStdPipes(exec.Command(engine, "pull", image))
+		},
+		loadImage: func(clusterName, image string) error {
+			return util.RunCmdWithStdPipes(kindCommand(envs, kindLoadImageArgs(clusterName, image)...))
+		},
+	}
+}
+
+// kindLoadImageArgs returns the kind arguments loading a local image into the named cluster.
+func kindLoadImageArgs(clusterName, image string) []string {
+	return []string{"load", "docker-image", image, "--name", clusterName}
+}
+
+// validatePreloadImages rejects empty image references.
+func validatePreloadImages(images []string) error {
+	for i, image := range images {
+		if strings.TrimSpace(image) == "" {
+			return fmt.Errorf("invalid spec.preloadImages[%d]: image must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// ensureImagesPresent checks that every image is present locally, pulling the missing ones
+// when pullIfMissing is set. It runs before the cluster is created so that a missing image
+// fails fast.
+func ensureImagesPresent(loader imageLoader, images []string, pullIfMissing bool) error {
+	for _, image := range images {
+		present, err := loader.imagePresent(image)
+		if err != nil {
+			return err
+		}
+		if present {
+			continue
+		}
+		if !pullIfMissing {
+			return fmt.Errorf("image %s is not present locally (pull it first or set spec.pullIfMissing)", image)
+		}
+		if err := loader.pullImage(image); err != nil {
+			return fmt.Errorf("failed to pull image %s: %w", image, err)
+		}
+	}
+	return nil
+}
+
+// preloadImages loads every image into the nodes of the named cluster.
+func preloadImages(loader imageLoader, clusterName string, images []string) error {
+	for _, image := range images {
+		if err := loader.loadImage(clusterName, image); err != nil {
+			return fmt.Errorf("failed to load image %s into kind cluster %s: %w", image, clusterName, err)
+		}
+	}
+	return nil
+}
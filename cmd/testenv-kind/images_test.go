@@ -0,0 +1,154 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeImageLoader records the calls of an imageLoader over a set of local images.
+type fakeImageLoader struct {
+	local   map[string]bool
+	pullErr error
+	pulled  []string
+	loaded  []string
+}
+
+func (f *fakeImageLoader) loader() imageLoader {
+	return imageLoader{
+		imagePresent: func(image string) (bool, error) {
+			return f.local[image], nil
+		},
+		pullImage: func(image string) error {
+			if f.pullErr != nil {
+				return f.pullErr
+			}
+			f.pulled = append(f.pulled, image)
+			f.local[image] = true
+			return nil
+		},
+		loadImage: func(clusterName, image string) error {
+			f.loaded = append(f.loaded, clusterName+"/"+image)
+			return nil
+		},
+	}
+}
+
+func TestKindLoadImageCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		envs Envs
+		want []string
+	}{
+		{
+			name: "kind binary",
+			envs: Envs{KindBinary: "kind"},
+			want: []string{"kind", "load", "docker-image", "nginx:1.27", "--name", "forge-test"},
+		},
+		{
+			name: "with binary prefix",
+			envs: Envs{KindBinary: "kind", KindBinaryPrefix: "sudo"},
+			want: []string{"sudo", "kind", "load", "docker-image", "nginx:1.27", "--name", "forge-test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := kindCommand(tt.envs, kindLoadImageArgs("forge-test", "nginx:1.27")...)
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Errorf("command args = %v, want %v", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureImagesPresent(t *testing.T) {
+	t.Run("present images are not pulled", func(t *testing.T) {
+		fake := &fakeImageLoader{local: map[string]bool{"nginx:1.27": true}}
+		if err := ensureImagesPresent(fake.loader(), []string{"nginx:1.27"}, true); err != nil {
+			t.Fatalf("ensureImagesPresent() error = %v", err)
+		}
+		if len(fake.pulled) != 0 {
+			t.Errorf("pulled %v, want nothing", fake.pulled)
+		}
+	})
+
+	t.Run("missing image fails without pullIfMissing", func(t *testing.T) {
+		fake := &fakeImageLoader{local: map[string]bool{"nginx:1.27": true}}
+		err := ensureImagesPresent(fake.loader(), []string{"nginx:1.27", "redis:7"}, false)
+		if err == nil || !strings.Contains(err.Error(), "image redis:7 is not present locally") {
+			t.Fatalf("ensureImagesPresent() error = %v, want missing redis:7", err)
+		}
+		if len(fake.pulled) != 0 {
+			t.Errorf("pulled %v, want nothing", fake.pulled)
+		}
+	})
+
+	t.Run("missing image is pulled with pullIfMissing", func(t *testing.T) {
+		fake := &fakeImageLoader{local: map[string]bool{"nginx:1.27": true}}
+		if err := ensureImagesPresent(fake.loader(), []string{"nginx:1.27", "redis:7"}, true); err != nil {
+			t.Fatalf("ensureImagesPresent() error = %v", err)
+		}
+		if want := []string{"redis:7"}; !reflect.DeepEqual(fake.pulled, want) {
+			t.Errorf("pulled %v, want %v", fake.pulled, want)
+		}
+	})
+
+	t.Run("pull failure", func(t *testing.T) {
+		fake := &fakeImageLoader{local: map[string]bool{}, pullErr: errors.New("network unreachable")}
+		err := ensureImagesPresent(fake.loader(), []string{"redis:7"}, true)
+		if err == nil || !strings.Contains(err.Error(), "failed to pull image redis:7: network unreachable") {
+			t.Fatalf("ensureImagesPresent() error = %v, want pull failure", err)
+		}
+	})
+
+	t.Run("inspect failure", func(t *testing.T) {
+		loader := imageLoader{imagePresent: func(string) (bool, error) { return false, errors.New("docker: not found") }}
+		if err := ensureImagesPresent(loader, []string{"redis:7"}, true); err == nil {
+			t.Fatal("ensureImagesPresent() succeeded, want inspect failure")
+		}
+	})
+}
+
+func TestPreloadImages(t *testing.T) {
+	fake := &fakeImageLoader{local: map[string]bool{}}
+	if err := preloadImages(fake.loader(), "forge-test", []string{"nginx:1.27", "redis:7"}); err != nil {
+		t.Fatalf("preloadImages() error = %v", err)
+	}
+	if want := []string{"forge-test/nginx:1.27", "forge-test/redis:7"}; !reflect.DeepEqual(fake.loaded, want) {
+		t.Errorf("loaded %v, want %v", fake.loaded, want)
+	}
+
+	loader := fake.loader()
+	loader.loadImage = func(string, string) error { return errors.New("exit status 1") }
+	err := preloadImages(loader, "forge-test", []string{"nginx:1.27"})
+	if err == nil || !strings.Contains(err.Error(), "failed to load image nginx:1.27 into kind cluster forge-test") {
+		t.Errorf("preloadImages() error = %v, want load failure", err)
+	}
+}
+
+func TestValidatePreloadImages(t *testing.T) {
+	if err := validatePreloadImages([]string{"nginx:1.27", "redis:7"}); err != nil {
+		t.Errorf("validatePreloadImages() error = %v", err)
+	}
+	if err := validatePreloadImages([]string{"nginx:1.27", " "}); err == nil || !strings.Contains(err.Error(), "spec.preloadImages[1]") {
+		t.Errorf("validatePreloadImages() error = %v, want empty image at index 1", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// reuseDecision is the outcome of checking for a reusable kind cluster.
+type reuseDecision int
+
+const (
+	// reuseCreate means no matching cluster exists and a new one must be created.
+	reuseCreate reuseDecision = iota
+	// reuseExisting means a healthy matching cluster exists and is reused as-is.
+	reuseExisting
+	// reuseRecreate means a matching cluster exists but is stale or unhealthy,
+	// so it must be deleted and created again.
+	reuseRecreate
+)
+
+// healthCheckTimeout bounds the API server readiness probe of a reuse candidate.
+const healthCheckTimeout = 10 * time.Second
+
+// clusterProbe inspects existing kind clusters. It is a struct of functions so
+// the reuse decision can be tested without kind or a running cluster.
+type clusterProbe struct {
+	// listClusters returns the names of existing kind clusters.
+	listClusters func() ([]string, error)
+	// writeKubeconfig writes the kubeconfig of the named cluster to path.
+	writeKubeconfig func(name, path string) error
+	// checkHealth returns an error if the cluster behind the kubeconfig is not ready.
+	checkHealth func(kubeconfigPath string) error
+}
+
+// newKindProbe returns a clusterProbe backed by the kind binary and the cluster's API server.
+func newKindProbe(envs Envs) clusterProbe {
+	return clusterProbe{
+		listClusters: func() ([]string, error) {
+			out, err := kindCommand(envs, "get", "clusters").Output()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list kind clusters: %w", err)
+			}
+			return strings.Fields(string(out)), nil
+		},
+		writeKubeconfig: func(name, path string) error {
+			out, err := kindCommand(envs, "get", "kubeconfig", "--name", name).Output()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig for cluster %s: %w", name, err)
+			}
+			if err := os.WriteFile(path, out, 0o600); err != nil {
+				return fmt.Errorf("failed to write kubeconfig: %w", err)
+			}
+			return nil
+		},
+		checkHealth: checkAPIServerReady,
+	}
+}
+
+// decideReuse determines whether the cluster named clusterName can be reused.
+// When it can, its kubeconfig has been written to kubeconfigPath. The returned
+// reason explains a recreate decision.
+func decideReuse(probe clusterProbe, clusterName, kubeconfigPath string) (reuseDecision, string, error) {
+	clusters, err := probe.listClusters()
+	if err != nil {
+		return reuseCreate, "", err
+	}
+
+	found := false
+	for _, name := range clusters {
+		if name == clusterName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return reuseCreate, "", nil
+	}
+
+	if err := probe.writeKubeconfig(clusterName, kubeconfigPath); err != nil {
+		return reuseRecreate, fmt.Sprintf("cannot get kubeconfig: %v", err), nil
+	}
+	if err := probe.checkHealth(kubeconfigPath); err != nil {
+		return reuseRecreate, fmt.Sprintf("cluster is not healthy: %v", err), nil
+	}
+
+	return reuseExisting, "", nil
+}
+
+// createdCluster reports whether the test environment created its kind cluster, as
+// opposed to reusing an existing one. Environments created before the
+// testenv-kind.created flag existed have no flag and are treated as created.
+func createdCluster(metadata map[string]string) bool {
+	return metadata["testenv-kind.created"] != "false"
+}
+
+// checkAPIServerReady queries the API server's /readyz endpoint using the kubeconfig.
+func checkAPIServerReady(kubeconfigPath string) error {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	restConfig.Timeout = healthCheckTimeout
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	if _, err := clientset.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx); err != nil {
+		return fmt.Errorf("API server not ready: %w", err)
+	}
+	return nil
+}
+
+// kindCommand builds a kind command, honoring KIND_BINARY_PREFIX (e.g. sudo).
+func kindCommand(envs Envs, args ...string) *exec.Cmd {
+	cmdName := envs.KindBinary
+	if envs.KindBinaryPrefix != "" {
+		cmdName = envs.KindBinaryPrefix
+		args = append([]string{envs.KindBinary}, args...)
+	}
+	return exec.Command(cmdName, args...)
+}
@@ -0,0 +1,141 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecideReuse(t *testing.T) {
+	const clusterName = "forge-shared"
+
+	tests := []struct {
+		name              string
+		clusters          []string
+		listErr           error
+		kubeconfigErr     error
+		healthErr         error
+		wantDecision      reuseDecision
+		wantReason        string
+		wantErr           bool
+		wantKubeconfigOut bool
+	}{
+		{
+			name:         "no clusters creates",
+			clusters:     nil,
+			wantDecision: reuseCreate,
+		},
+		{
+			name:         "only non-matching clusters creates",
+			clusters:     []string{"forge-other", "forge-shared-2"},
+			wantDecision: reuseCreate,
+		},
+		{
+			name:              "healthy matching cluster is reused",
+			clusters:          []string{"forge-other", clusterName},
+			wantDecision:      reuseExisting,
+			wantKubeconfigOut: true,
+		},
+		{
+			name:              "unhealthy matching cluster is recreated",
+			clusters:          []string{clusterName},
+			healthErr:         errors.New("connection refused"),
+			wantDecision:      reuseRecreate,
+			wantReason:        "connection refused",
+			wantKubeconfigOut: true,
+		},
+		{
+			name:          "matching cluster without kubeconfig is recreated",
+			clusters:      []string{clusterName},
+			kubeconfigErr: errors.New("no control-plane node"),
+			wantDecision:  reuseRecreate,
+			wantReason:    "no control-plane node",
+		},
+		{
+			name:    "listing failure is an error",
+			listErr: errors.New("kind not found"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeconfigWritten := false
+			probe := clusterProbe{
+				listClusters: func() ([]string, error) {
+					return tt.clusters, tt.listErr
+				},
+				writeKubeconfig: func(name, path string) error {
+					if name != clusterName || path != "/tmp/kubeconfig" {
+						t.Errorf("writeKubeconfig called with %q, %q", name, path)
+					}
+					if tt.kubeconfigErr != nil {
+						return tt.kubeconfigErr
+					}
+					kubeconfigWritten = true
+					return nil
+				},
+				checkHealth: func(kubeconfigPath string) error {
+					return tt.healthErr
+				},
+			}
+
+			decision, reason, err := decideReuse(probe, clusterName, "/tmp/kubeconfig")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decideReuse() error = %v", err)
+			}
+			if decision != tt.wantDecision {
+				t.Errorf("decideReuse() decision = %v, want %v", decision, tt.wantDecision)
+			}
+			if !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("decideReuse() reason = %q, want it to contain %q", reason, tt.wantReason)
+			}
+			if kubeconfigWritten != tt.wantKubeconfigOut {
+				t.Errorf("kubeconfig written = %v, want %v", kubeconfigWritten, tt.wantKubeconfigOut)
+			}
+		})
+	}
+}
+
+func TestCreatedCluster(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{name: "reused cluster is kept", metadata: map[string]string{"testenv-kind.created": "false"}, want: false},
+		{name: "created cluster is deleted", metadata: map[string]string{"testenv-kind.created": "true"}, want: true},
+		{name: "legacy metadata without flag is deleted", metadata: map[string]string{"testenv-kind.clusterName": "x"}, want: true},
+		{name: "nil metadata is deleted", metadata: nil, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := createdCluster(tt.metadata); got != tt.want {
+				t.Errorf("createdCluster() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
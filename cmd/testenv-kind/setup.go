@@ -65,22 +65,23 @@ containerdConfigPatches:
 `
 
 // generateKindConfig creates a Kind cluster configuration file with containerdConfigPatches
-// that enable the /etc/containerd/certs.d directory for registry-specific TLS certificates.
+// that enable the /etc/containerd/certs.d directory for registry-specific TLS certificates,
+// and the control-plane and worker nodes requested in opts.
 // It writes the config to {tmpDir}/kind-config.yaml and returns the absolute path.
-func generateKindConfig(tmpDir string) (string, error) {
+func generateKindConfig(tmpDir string, opts clusterOptions) (string, error) {
 	configPath := filepath.Join(tmpDir, "kind-config.yaml")
 
-	if err := os.WriteFile(configPath, []byte(kindConfigContent), 0o600); err != nil {
+	if err := os.WriteFile(configPath, []byte(renderKindConfig(opts)), 0o600); err != nil {
 		return "", fmt.Errorf("failed to write kind config file: %w", err)
 	}
 
 	return configPath, nil
 }
 
-func doSetup(pCfg forge.Spec, envs Envs) error {
-	// 0. Generate Kind config file with containerd patches for TLS trust.
+func doSetup(pCfg forge.Spec, envs Envs, opts clusterOptions) error {
+	// 0. Generate Kind config file with containerd patches for TLS trust and the node topology.
 	tmpDir := filepath.Dir(pCfg.Kindenv.KubeconfigPath)
-	kindConfigPath, err := generateKindConfig(tmpDir)
+	kindConfigPath, err := generateKindConfig(tmpDir, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate kind config: %w", err)
 	}
@@ -46,7 +46,7 @@ func TestGenerateKindConfig(t *testing.T) {
 			tmpDir := t.TempDir()
 
 			// Generate the config
-			configPath, err := generateKindConfig(tmpDir)
+			configPath, err := generateKindConfig(tmpDir, defaultClusterOptions())
 			if err != nil {
 				t.Fatalf("generateKindConfig() error = %v", err)
 			}
@@ -76,7 +76,7 @@ func TestGenerateKindConfig(t *testing.T) {
 func TestGenerateKindConfigFilePermissions(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	configPath, err := generateKindConfig(tmpDir)
+	configPath, err := generateKindConfig(tmpDir, defaultClusterOptions())
 	if err != nil {
 		t.Fatalf("generateKindConfig() error = %v", err)
 	}
@@ -98,7 +98,7 @@ func TestGenerateKindConfigNonExistentDir(t *testing.T) {
 	// Use a non-existent directory
 	nonExistentDir := filepath.Join(t.TempDir(), "non-existent", "subdir")
 
-	_, err := generateKindConfig(nonExistentDir)
+	_, err := generateKindConfig(nonExistentDir, defaultClusterOptions())
 	if err == nil {
 		t.Error("generateKindConfig() expected error for non-existent directory")
 	}
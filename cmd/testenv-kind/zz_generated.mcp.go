@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:f685e4a20c1e1d5c8e4e2e1e5e0ea07fb17ad6cf7170f3354315dacb90bd62b6
+// SourceChecksum: sha256:e45a9b74e09bd64fe0e357ea62341168ff86c0927dd9dd9d13e82c4543d3c7a0
 
 package main
 
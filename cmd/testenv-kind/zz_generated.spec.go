@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:f685e4a20c1e1d5c8e4e2e1e5e0ea07fb17ad6cf7170f3354315dacb90bd62b6
+// SourceChecksum: sha256:e45a9b74e09bd64fe0e357ea62341168ff86c0927dd9dd9d13e82c4543d3c7a0
 
 package main
 
@@ -8,21 +8,145 @@ import (
 	"fmt"
 )
 
+// Mount represents the Mount configuration.
+// A host directory mounted into the cluster nodes
+type Mount struct {
+	// Absolute path of the mount inside the node containers
+	ContainerPath string `json:"containerPath"`
+	// Existing host directory or file, relative to the repository root unless absolute
+	HostPath string `json:"hostPath"`
+	// Mount the host path read-only (optional)
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// PortMapping represents the PortMapping configuration.
+// A port of the control-plane node exposed on the host
+type PortMapping struct {
+	// Port inside the node container (e.g. a NodePort)
+	ContainerPort int `json:"containerPort"`
+	// Port on the host
+	HostPort int `json:"hostPort"`
+	// Host address the port is bound to (optional, default 0.0.0.0)
+	ListenAddress string `json:"listenAddress,omitempty"`
+	// TCP (default), UDP or SCTP
+	Protocol string `json:"protocol,omitempty"`
+}
+
 // Spec represents the Spec configuration.
 // Configuration for testenv-kind. All fields are optional.
 type Spec struct {
 	// Path to kind config file for cluster customization
 	Config string `json:"config,omitempty"`
+	// Host directories mounted into every node (optional)
+	ExtraMounts []Mount `json:"extraMounts,omitempty"`
+	// Extra ports of the control-plane node exposed on the host (optional)
+	ExtraPortMappings []PortMapping `json:"extraPortMappings,omitempty"`
 	// Kind node image to use (e.g., kindest/node:v1.27.0)
 	Image string `json:"image,omitempty"`
+	// Kubernetes version of the cluster nodes (e.g., v1.31.0 or 1.31), mapped to a known kindest/node image
+	K8sVersion string `json:"k8sVersion,omitempty"`
 	// Custom name suffix for the kind cluster (default uses test ID)
 	Name string `json:"name,omitempty"`
+	// Total number of cluster nodes, one control-plane plus (nodes - 1) workers (default 1)
+	Nodes int `json:"nodes,omitempty"`
+	// Local container images loaded into the cluster nodes with "kind load docker-image" once the cluster is up (optional)
+	PreloadImages []string `json:"preloadImages,omitempty"`
+	// Pull preloadImages that are not present locally instead of failing (optional)
+	PullIfMissing bool `json:"pullIfMissing,omitempty"`
 	// Whether to retain the cluster on failure for debugging
 	Retain bool `json:"retain,omitempty"`
+	// Reuse an existing healthy kind cluster with the computed name instead of creating one (a reused cluster is not deleted on teardown)
+	Reuse bool `json:"reuse,omitempty"`
 	// Timeout for waiting for cluster to be ready (e.g., 5m)
 	WaitTimeout string `json:"waitTimeout,omitempty"`
 }
 
+// MountFromMap creates a Mount from a map[string]interface{}.
+func MountFromMap(m map[string]interface{}) (*Mount, error) {
+	if m == nil {
+		return &Mount{}, nil
+	}
+
+	s := &Mount{}
+	// Parse containerPath
+	if v, ok := m["containerPath"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.ContainerPath = val
+		} else {
+			return nil, fmt.Errorf("field containerPath: expected string, got %T", v)
+		}
+	}
+	// Parse hostPath
+	if v, ok := m["hostPath"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.HostPath = val
+		} else {
+			return nil, fmt.Errorf("field hostPath: expected string, got %T", v)
+		}
+	}
+	// Parse readOnly
+	if v, ok := m["readOnly"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.ReadOnly = val
+		} else {
+			return nil, fmt.Errorf("field readOnly: expected bool, got %T", v)
+		}
+	}
+	return s, nil
+}
+
+// PortMappingFromMap creates a PortMapping from a map[string]interface{}.
+func PortMappingFromMap(m map[string]interface{}) (*PortMapping, error) {
+	if m == nil {
+		return &PortMapping{}, nil
+	}
+
+	s := &PortMapping{}
+	// Parse containerPort
+	if v, ok := m["containerPort"]; ok && v != nil {
+		switch val := v.(type) {
+		case int:
+			s.ContainerPort = val
+		case int64:
+			s.ContainerPort = int(val)
+		case float64:
+			s.ContainerPort = int(val)
+		default:
+			return nil, fmt.Errorf("field containerPort: expected int, got %T", v)
+		}
+	}
+	// Parse hostPort
+	if v, ok := m["hostPort"]; ok && v != nil {
+		switch val := v.(type) {
+		case int:
+			s.HostPort = val
+		case int64:
+			s.HostPort = int(val)
+		case float64:
+			s.HostPort = int(val)
+		default:
+			return nil, fmt.Errorf("field hostPort: expected int, got %T", v)
+		}
+	}
+	// Parse listenAddress
+	if v, ok := m["listenAddress"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.ListenAddress = val
+		} else {
+			return nil, fmt.Errorf("field listenAddress: expected string, got %T", v)
+		}
+	}
+	// Parse protocol
+	if v, ok := m["protocol"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.Protocol = val
+		} else {
+			return nil, fmt.Errorf("field protocol: expected string, got %T", v)
+		}
+	}
+	return s, nil
+}
+
 // SpecFromMap creates a Spec from a map[string]interface{}.
 func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	if m == nil {
@@ -38,6 +162,48 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field config: expected string, got %T", v)
 		}
 	}
+	// Parse extraMounts
+	if v, ok := m["extraMounts"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.ExtraMounts = make([]Mount, 0, len(arr))
+			for i, item := range arr {
+				if obj, ok := item.(map[string]interface{}); ok {
+					ref, err := MountFromMap(obj)
+					if err != nil {
+						return nil, fmt.Errorf("field extraMounts[%d]: %w", i, err)
+					}
+					if ref != nil {
+						s.ExtraMounts = append(s.ExtraMounts, *ref)
+					}
+				} else {
+					return nil, fmt.Errorf("field extraMounts[%d]: expected object, got %T", i, item)
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("field extraMounts: expected []object, got %T", v)
+		}
+	}
+	// Parse extraPortMappings
+	if v, ok := m["extraPortMappings"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.ExtraPortMappings = make([]PortMapping, 0, len(arr))
+			for i, item := range arr {
+				if obj, ok := item.(map[string]interface{}); ok {
+					ref, err := PortMappingFromMap(obj)
+					if err != nil {
+						return nil, fmt.Errorf("field extraPortMappings[%d]: %w", i, err)
+					}
+					if ref != nil {
+						s.ExtraPortMappings = append(s.ExtraPortMappings, *ref)
+					}
+				} else {
+					return nil, fmt.Errorf("field extraPortMappings[%d]: expected object, got %T", i, item)
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("field extraPortMappings: expected []object, got %T", v)
+		}
+	}
 	// Parse image
 	if v, ok := m["image"]; ok && v != nil {
 		if val, ok := v.(string); ok {
@@ -46,6 +212,14 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field image: expected string, got %T", v)
 		}
 	}
+	// Parse k8sVersion
+	if v, ok := m["k8sVersion"]; ok && v != nil {
+		if val, ok := v.(string); ok {
+			s.K8sVersion = val
+		} else {
+			return nil, fmt.Errorf("field k8sVersion: expected string, got %T", v)
+		}
+	}
 	// Parse name
 	if v, ok := m["name"]; ok && v != nil {
 		if val, ok := v.(string); ok {
@@ -54,6 +228,44 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field name: expected string, got %T", v)
 		}
 	}
+	// Parse nodes
+	if v, ok := m["nodes"]; ok && v != nil {
+		switch val := v.(type) {
+		case int:
+			s.Nodes = val
+		case int64:
+			s.Nodes = int(val)
+		case float64:
+			s.Nodes = int(val)
+		default:
+			return nil, fmt.Errorf("field nodes: expected int, got %T", v)
+		}
+	}
+	// Parse preloadImages
+	if v, ok := m["preloadImages"]; ok && v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			s.PreloadImages = make([]string, 0, len(arr))
+			for i, item := range arr {
+				if str, ok := item.(string); ok {
+					s.PreloadImages = append(s.PreloadImages, str)
+				} else {
+					return nil, fmt.Errorf("field preloadImages[%d]: expected string, got %T", i, item)
+				}
+			}
+		} else if arr, ok := v.([]string); ok {
+			s.PreloadImages = arr
+		} else {
+			return nil, fmt.Errorf("field preloadImages: expected []string, got %T", v)
+		}
+	}
+	// Parse pullIfMissing
+	if v, ok := m["pullIfMissing"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.PullIfMissing = val
+		} else {
+			return nil, fmt.Errorf("field pullIfMissing: expected bool, got %T", v)
+		}
+	}
 	// Parse retain
 	if v, ok := m["retain"]; ok && v != nil {
 		if val, ok := v.(bool); ok {
@@ -62,6 +274,14 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 			return nil, fmt.Errorf("field retain: expected bool, got %T", v)
 		}
 	}
+	// Parse reuse
+	if v, ok := m["reuse"]; ok && v != nil {
+		if val, ok := v.(bool); ok {
+			s.Reuse = val
+		} else {
+			return nil, fmt.Errorf("field reuse: expected bool, got %T", v)
+		}
+	}
 	// Parse waitTimeout
 	if v, ok := m["waitTimeout"]; ok && v != nil {
 		if val, ok := v.(string); ok {
@@ -73,6 +293,47 @@ func SpecFromMap(m map[string]interface{}) (*Spec, error) {
 	return s, nil
 }
 
+// ToMap converts a Mount to a map[string]interface{}.
+func (s *Mount) ToMap() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{})
+	if s.ContainerPath != "" {
+		m["containerPath"] = s.ContainerPath
+	}
+	if s.HostPath != "" {
+		m["hostPath"] = s.HostPath
+	}
+	if s.ReadOnly {
+		m["readOnly"] = s.ReadOnly
+	}
+	return m
+}
+
+// ToMap converts a PortMapping to a map[string]interface{}.
+func (s *PortMapping) ToMap() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{})
+	if s.ContainerPort != 0 {
+		m["containerPort"] = s.ContainerPort
+	}
+	if s.HostPort != 0 {
+		m["hostPort"] = s.HostPort
+	}
+	if s.ListenAddress != "" {
+		m["listenAddress"] = s.ListenAddress
+	}
+	if s.Protocol != "" {
+		m["protocol"] = s.Protocol
+	}
+	return m
+}
+
 // ToMap converts a Spec to a map[string]interface{}.
 func (s *Spec) ToMap() map[string]interface{} {
 	if s == nil {
@@ -83,15 +344,44 @@ func (s *Spec) ToMap() map[string]interface{} {
 	if s.Config != "" {
 		m["config"] = s.Config
 	}
+	if len(s.ExtraMounts) > 0 {
+		arr := make([]interface{}, 0, len(s.ExtraMounts))
+		for _, item := range s.ExtraMounts {
+			arr = append(arr, item.ToMap())
+		}
+		m["extraMounts"] = arr
+	}
+	if len(s.ExtraPortMappings) > 0 {
+		arr := make([]interface{}, 0, len(s.ExtraPortMappings))
+		for _, item := range s.ExtraPortMappings {
+			arr = append(arr, item.ToMap())
+		}
+		m["extraPortMappings"] = arr
+	}
 	if s.Image != "" {
 		m["image"] = s.Image
 	}
+	if s.K8sVersion != "" {
+		m["k8sVersion"] = s.K8sVersion
+	}
 	if s.Name != "" {
 		m["name"] = s.Name
 	}
+	if s.Nodes != 0 {
+		m["nodes"] = s.Nodes
+	}
+	if len(s.PreloadImages) > 0 {
+		m["preloadImages"] = s.PreloadImages
+	}
+	if s.PullIfMissing {
+		m["pullIfMissing"] = s.PullIfMissing
+	}
 	if s.Retain {
 		m["retain"] = s.Retain
 	}
+	if s.Reuse {
+		m["reuse"] = s.Reuse
+	}
 	if s.WaitTimeout != "" {
 		m["waitTimeout"] = s.WaitTimeout
 	}
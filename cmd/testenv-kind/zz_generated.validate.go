@@ -1,13 +1,75 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:f685e4a20c1e1d5c8e4e2e1e5e0ea07fb17ad6cf7170f3354315dacb90bd62b6
+// SourceChecksum: sha256:e45a9b74e09bd64fe0e357ea62341168ff86c0927dd9dd9d13e82c4543d3c7a0
 
 package main
 
 import (
+	"fmt"
+
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 )
 
+// ValidateMount validates a Mount and returns validation results.
+// It checks required fields and validates enum values.
+func ValidateMount(s *Mount) *mcptypes.ConfigValidateOutput {
+	if s == nil {
+		return &mcptypes.ConfigValidateOutput{
+			Valid: true,
+		}
+	}
+
+	var errors []mcptypes.ValidationError
+	// Validate required field: containerPath
+	if s.ContainerPath == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.containerPath",
+			Message: "required field is missing",
+		})
+	}
+	// Validate required field: hostPath
+	if s.HostPath == "" {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "spec.hostPath",
+			Message: "required field is missing",
+		})
+	}
+
+	if len(errors) > 0 {
+		return &mcptypes.ConfigValidateOutput{
+			Valid:  false,
+			Errors: errors,
+		}
+	}
+
+	return &mcptypes.ConfigValidateOutput{
+		Valid: true,
+	}
+}
+
+// ValidatePortMapping validates a PortMapping and returns validation results.
+// It checks required fields and validates enum values.
+func ValidatePortMapping(s *PortMapping) *mcptypes.ConfigValidateOutput {
+	if s == nil {
+		return &mcptypes.ConfigValidateOutput{
+			Valid: true,
+		}
+	}
+
+	var errors []mcptypes.ValidationError
+
+	if len(errors) > 0 {
+		return &mcptypes.ConfigValidateOutput{
+			Valid:  false,
+			Errors: errors,
+		}
+	}
+
+	return &mcptypes.ConfigValidateOutput{
+		Valid: true,
+	}
+}
+
 // ValidateSpec validates a Spec and returns validation results.
 // It checks required fields and validates enum values.
 func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
@@ -18,6 +80,30 @@ func ValidateSpec(s *Spec) *mcptypes.ConfigValidateOutput {
 	}
 
 	var errors []mcptypes.ValidationError
+	// Validate array of references: extraMounts
+	for i, item := range s.ExtraMounts {
+		nestedResult := ValidateMount(&item)
+		if !nestedResult.Valid {
+			for _, e := range nestedResult.Errors {
+				errors = append(errors, mcptypes.ValidationError{
+					Field:   fmt.Sprintf("spec.extraMounts[%d].%s", i, e.Field),
+					Message: e.Message,
+				})
+			}
+		}
+	}
+	// Validate array of references: extraPortMappings
+	for i, item := range s.ExtraPortMappings {
+		nestedResult := ValidatePortMapping(&item)
+		if !nestedResult.Valid {
+			for _, e := range nestedResult.Errors {
+				errors = append(errors, mcptypes.ValidationError{
+					Field:   fmt.Sprintf("spec.extraPortMappings[%d].%s", i, e.Field),
+					Message: e.Message,
+				})
+			}
+		}
+	}
 
 	if len(errors) > 0 {
 		return &mcptypes.ConfigValidateOutput{
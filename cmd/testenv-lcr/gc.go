@@ -0,0 +1,240 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GCImagePullSecretsInput is the input of the gc-image-pull-secrets tool.
+type GCImagePullSecretsInput struct {
+	// KubeconfigPath overrides the kubeconfig path from forge.yaml.
+	KubeconfigPath string `json:"kubeconfigPath,omitempty"`
+	// DryRun reports the candidates without deleting them.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// GCImagePullSecretsResult reports the outcome of an image pull secret garbage collection.
+type GCImagePullSecretsResult struct {
+	DryRun     bool                  `json:"dryRun"`
+	Candidates []ImagePullSecretInfo `json:"candidates"`
+	Deleted    []ImagePullSecretInfo `json:"deleted"`
+}
+
+// selectOrphanedImagePullSecrets returns the secrets whose owning test environment
+// no longer exists in the artifact store. Secrets without a test ID label were
+// created before ownership was recorded and are never selected.
+func selectOrphanedImagePullSecrets(
+	secrets []ImagePullSecretInfo,
+	testEnvs map[string]*forge.TestEnvironment,
+) []ImagePullSecretInfo {
+	orphans := []ImagePullSecretInfo{}
+	for _, secret := range secrets {
+		if secret.TestID == "" {
+			continue
+		}
+		if _, ok := testEnvs[secret.TestID]; ok {
+			continue
+		}
+		orphans = append(orphans, secret)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Namespace != orphans[j].Namespace {
+			return orphans[i].Namespace < orphans[j].Namespace
+		}
+		return orphans[i].SecretName < orphans[j].SecretName
+	})
+
+	return orphans
+}
+
+// gcImagePullSecrets deletes the testenv-lcr image pull secrets whose test environment
+// no longer exists. Only secrets bearing the testenv-lcr label are considered.
+func gcImagePullSecrets(
+	ctx context.Context,
+	cl client.Client,
+	testEnvs map[string]*forge.TestEnvironment,
+	dryRun bool,
+) (GCImagePullSecretsResult, error) {
+	result := GCImagePullSecretsResult{DryRun: dryRun, Deleted: []ImagePullSecretInfo{}}
+
+	secrets, err := ListImagePullSecrets(ctx, cl, "")
+	if err != nil {
+		return result, err
+	}
+
+	result.Candidates = selectOrphanedImagePullSecrets(secrets, testEnvs)
+	if dryRun {
+		return result, nil
+	}
+
+	var errs []error
+	for _, candidate := range result.Candidates {
+		secret := &corev1.Secret{} //nolint:exhaustruct
+		secret.Name = candidate.SecretName
+		secret.Namespace = candidate.Namespace
+
+		if err := cl.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete image pull secret %s/%s: %w",
+				candidate.Namespace, candidate.SecretName, err))
+			continue
+		}
+		result.Deleted = append(result.Deleted, candidate)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// runGCImagePullSecrets reads forge.yaml and the artifact store, then garbage-collects
+// orphaned image pull secrets in the cluster referenced by the kubeconfig.
+func runGCImagePullSecrets(ctx context.Context, input GCImagePullSecretsInput) (GCImagePullSecretsResult, error) {
+	config, err := forge.ReadSpec()
+	if err != nil {
+		return GCImagePullSecretsResult{}, fmt.Errorf("failed to read forge spec: %w", err)
+	}
+
+	if input.KubeconfigPath != "" {
+		config.Kindenv.KubeconfigPath = input.KubeconfigPath
+	}
+
+	// A missing store must not be mistaken for "no test environment exists".
+	store, err := readArtifactStore(config)
+	if err != nil {
+		return GCImagePullSecretsResult{}, err
+	}
+
+	cl, err := createKubeClient(config)
+	if err != nil {
+		return GCImagePullSecretsResult{}, fmt.Errorf("failed to create kube client: %w", err)
+	}
+
+	return gcImagePullSecrets(ctx, cl, store.TestEnvironments, input.DryRun)
+}
+
+// ----------------------------------------------------- MCP --------------------------------------------------------- //
+
+// handleGCImagePullSecretsTool handles the "gc-image-pull-secrets" tool call from MCP clients.
+func handleGCImagePullSecretsTool(
+	ctx context.Context,
+	_ *mcp.CallToolRequest,
+	input GCImagePullSecretsInput,
+) (*mcp.CallToolResult, any, error) {
+	log.Printf("Garbage-collecting image pull secrets: dryRun=%t", input.DryRun)
+
+	result, err := runGCImagePullSecrets(ctx, input)
+	if err != nil {
+		return mcputil.ErrorResult(fmt.Sprintf("GC failed: %v", err)), nil, nil
+	}
+
+	msg := fmt.Sprintf("Deleted %d orphaned image pull secret(s)", len(result.Deleted))
+	if input.DryRun {
+		msg = fmt.Sprintf("Dry-run: %d orphaned image pull secret(s) would be deleted", len(result.Candidates))
+	}
+
+	res, artifact := mcputil.SuccessResultWithArtifact(msg, result)
+	return res, artifact, nil
+}
+
+// ----------------------------------------------------- CLI --------------------------------------------------------- //
+
+const cliUsage = `Usage:
+  testenv-lcr gc-image-pull-secrets [--kubeconfig PATH] [--dry-run]
+                                   Delete image pull secrets of test environments that no longer exist
+  testenv-lcr --mcp                Run as MCP server
+  testenv-lcr docs <command>       Show engine documentation
+  testenv-lcr version              Show version information`
+
+// runCLI runs testenv-lcr in CLI mode.
+func runCLI() error {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return fmt.Errorf("missing command")
+	}
+
+	command := os.Args[1]
+	switch command {
+	case "gc-image-pull-secrets":
+		input, err := parseGCArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, cliUsage)
+			return err
+		}
+		return cmdGCImagePullSecrets(input)
+	case "help", "--help", "-h":
+		fmt.Println(cliUsage)
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// parseGCArgs parses the arguments of the gc-image-pull-secrets command.
+func parseGCArgs(args []string) (GCImagePullSecretsInput, error) {
+	var input GCImagePullSecretsInput
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dry-run":
+			input.DryRun = true
+		case "--kubeconfig":
+			if i+1 >= len(args) {
+				return input, fmt.Errorf("--kubeconfig requires a value")
+			}
+			i++
+			input.KubeconfigPath = args[i]
+		default:
+			return input, fmt.Errorf("unknown argument: %s", args[i])
+		}
+	}
+	return input, nil
+}
+
+// cmdGCImagePullSecrets garbage-collects orphaned image pull secrets and prints the outcome.
+func cmdGCImagePullSecrets(input GCImagePullSecretsInput) error {
+	result, err := runGCImagePullSecrets(context.Background(), input)
+
+	// Report partial progress before surfacing deletion errors.
+	for _, secret := range result.Deleted {
+		fmt.Printf("Deleted image pull secret: %s/%s (testID=%s)\n", secret.Namespace, secret.SecretName, secret.TestID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if input.DryRun {
+		for _, secret := range result.Candidates {
+			fmt.Printf("Would delete image pull secret: %s/%s (testID=%s)\n", secret.Namespace, secret.SecretName, secret.TestID)
+		}
+		fmt.Printf("Dry-run: %d orphaned image pull secret(s) would be deleted\n", len(result.Candidates))
+		return nil
+	}
+
+	fmt.Printf("Deleted %d orphaned image pull secret(s)\n", len(result.Deleted))
+	return nil
+}
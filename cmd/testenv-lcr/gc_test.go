@@ -0,0 +1,119 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+func TestSelectOrphanedImagePullSecrets(t *testing.T) {
+	testEnvs := map[string]*forge.TestEnvironment{
+		"test-live": {ID: "test-live"},
+	}
+
+	tests := []struct {
+		name    string
+		secrets []ImagePullSecretInfo
+		want    []ImagePullSecretInfo
+	}{
+		{
+			name:    "no secrets",
+			secrets: nil,
+			want:    []ImagePullSecretInfo{},
+		},
+		{
+			name: "secret of existing test environment is kept",
+			secrets: []ImagePullSecretInfo{
+				{Namespace: "default", SecretName: "creds", TestID: "test-live"},
+			},
+			want: []ImagePullSecretInfo{},
+		},
+		{
+			name: "secret of deleted test environment is selected",
+			secrets: []ImagePullSecretInfo{
+				{Namespace: "default", SecretName: "creds", TestID: "test-gone"},
+			},
+			want: []ImagePullSecretInfo{
+				{Namespace: "default", SecretName: "creds", TestID: "test-gone"},
+			},
+		},
+		{
+			name: "secret without owner label is never selected",
+			secrets: []ImagePullSecretInfo{
+				{Namespace: "default", SecretName: "creds"},
+			},
+			want: []ImagePullSecretInfo{},
+		},
+		{
+			name: "mixed secrets are filtered and sorted",
+			secrets: []ImagePullSecretInfo{
+				{Namespace: "zeta", SecretName: "creds", TestID: "test-gone"},
+				{Namespace: "alpha", SecretName: "creds", TestID: "test-live"},
+				{Namespace: "alpha", SecretName: "other", TestID: "test-old"},
+				{Namespace: "alpha", SecretName: "creds", TestID: "test-old"},
+			},
+			want: []ImagePullSecretInfo{
+				{Namespace: "alpha", SecretName: "creds", TestID: "test-old"},
+				{Namespace: "alpha", SecretName: "other", TestID: "test-old"},
+				{Namespace: "zeta", SecretName: "creds", TestID: "test-gone"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectOrphanedImagePullSecrets(tt.secrets, testEnvs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectOrphanedImagePullSecrets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGCArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    GCImagePullSecretsInput
+		wantErr bool
+	}{
+		{name: "defaults", args: nil, want: GCImagePullSecretsInput{}},
+		{name: "dry-run", args: []string{"--dry-run"}, want: GCImagePullSecretsInput{DryRun: true}},
+		{
+			name: "kubeconfig",
+			args: []string{"--kubeconfig", "/tmp/kubeconfig"},
+			want: GCImagePullSecretsInput{KubeconfigPath: "/tmp/kubeconfig"},
+		},
+		{name: "missing kubeconfig value", args: []string{"--kubeconfig"}, wantErr: true},
+		{name: "unknown argument", args: []string{"--force"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGCArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGCArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseGCArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/flaterrors"
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var errLoadingImage = errors.New("loading image")
+
+// LoadImageInput is the input of the load-image tool.
+type LoadImageInput struct {
+	// TestID identifies the test environment whose registry receives the image.
+	TestID string `json:"testID"`
+	// Image is either the path to an image tarball (.tar, .tar.gz, .tgz) or an image
+	// reference present in the local container engine.
+	Image string `json:"image"`
+}
+
+// LoadImageOutput is the result of the load-image tool.
+type LoadImageOutput struct {
+	// Source is the local image reference that was pushed.
+	Source string `json:"source"`
+	// Reference is the image reference to use from inside the cluster.
+	Reference string `json:"reference"`
+}
+
+// isImageTarball reports whether image designates an image tarball rather than an image reference.
+func isImageTarball(image string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(image, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLoadedImageRefs extracts the image references from the output of `docker load`
+// or `podman load`. Untagged images ("Loaded image ID: ...") are ignored.
+func parseLoadedImageRefs(output string) []string {
+	var refs []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		var list string
+		switch {
+		case strings.HasPrefix(line, "Loaded image: "):
+			list = strings.TrimPrefix(line, "Loaded image: ")
+		case strings.HasPrefix(line, "Loaded image(s): "):
+			list = strings.TrimPrefix(line, "Loaded image(s): ")
+		default:
+			continue
+		}
+		for _, ref := range strings.Split(list, ",") {
+			if ref = strings.TrimSpace(ref); ref != "" {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// rewriteImageRef rewrites a local image reference into a reference hosted by the
+// registry (host:port). The source registry domain (and Docker Hub's implicit "library/"
+// namespace) is dropped and a missing tag defaults to "latest":
+//
+//	myapp                              -> {registry}/myapp:latest
+//	localhost/myapp:v1                 -> {registry}/myapp:v1
+//	docker.io/library/nginx:1.27       -> {registry}/nginx:1.27
+//	ghcr.io/org/tool:v2                -> {registry}/org/tool:v2
+func rewriteImageRef(ref, registry string) (string, error) {
+	if ref == "" {
+		return "", errors.New("empty image reference")
+	}
+	if strings.Contains(ref, "@") {
+		return "", fmt.Errorf("image reference %q: digest references are not supported, use a tag", ref)
+	}
+
+	path := ref
+	if parts := strings.SplitN(ref, "/", 2); len(parts) == 2 {
+		domain := parts[0]
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			path = parts[1]
+			if domain == "docker.io" || domain == "index.docker.io" {
+				path = strings.TrimPrefix(path, "library/")
+			}
+		}
+	}
+
+	lastComponent := path[strings.LastIndex(path, "/")+1:]
+	if !strings.Contains(lastComponent, ":") {
+		path += ":latest"
+	}
+
+	return fmt.Sprintf("%s/%s", registry, path), nil
+}
+
+// loadImageTarball loads an image tarball into the container engine and returns the loaded reference.
+func loadImageTarball(containerEngine, tarballPath string) (string, error) {
+	if _, err := os.Stat(tarballPath); err != nil {
+		return "", fmt.Errorf("image tarball %q: %w", tarballPath, err)
+	}
+
+	out, err := exec.Command(containerEngine, "load", "-i", tarballPath).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to load image tarball %q: %w\n%s", tarballPath, err, out)
+	}
+
+	refs := parseLoadedImageRefs(string(out))
+	switch len(refs) {
+	case 0:
+		return "", fmt.Errorf("image tarball %q contains no tagged image", tarballPath)
+	case 1:
+		return refs[0], nil
+	default:
+		return "", fmt.Errorf("image tarball %q contains %d images (%s), expected exactly one",
+			tarballPath, len(refs), strings.Join(refs, ", "))
+	}
+}
+
+// lcrTarget describes the local container registry of a test environment.
+type lcrTarget struct {
+	config forge.Spec
+	port   int32
+}
+
+// resolveLCRTarget reads the registry details of a test environment from the artifact store.
+func resolveLCRTarget(testID string) (lcrTarget, error) {
+	config, err := forge.ReadSpec()
+	if err != nil {
+		return lcrTarget{}, fmt.Errorf("failed to read forge spec: %w", err)
+	}
+
+	store, err := readArtifactStore(config)
+	if err != nil {
+		return lcrTarget{}, err
+	}
+
+	env, err := forge.GetTestEnvironment(&store, testID)
+	if err != nil {
+		return lcrTarget{}, err
+	}
+
+	md := env.Metadata
+	if md["testenv-lcr.enabled"] != "true" {
+		return lcrTarget{}, fmt.Errorf("test environment %s has no local container registry", testID)
+	}
+
+	port, err := strconv.ParseInt(md["testenv-lcr.port"], 10, 32)
+	if err != nil {
+		return lcrTarget{}, fmt.Errorf("test environment %s: invalid testenv-lcr.port %q: %w", testID, md["testenv-lcr.port"], err)
+	}
+
+	config.LocalContainerRegistry.Namespace = md["testenv-lcr.namespace"]
+	config.LocalContainerRegistry.CaCrtPath = md["testenv-lcr.caCrtPath"]
+	config.LocalContainerRegistry.CredentialPath = md["testenv-lcr.credentialPath"]
+	if kubeconfigPath := md["testenv-kind.kubeconfigPath"]; kubeconfigPath != "" {
+		config.Kindenv.KubeconfigPath = kubeconfigPath
+	}
+
+	return lcrTarget{config: config, port: int32(port)}, nil
+}
+
+// loadImage pushes a local image tarball or image reference into the registry of a test environment
+// and returns the in-cluster reference.
+func loadImage(ctx context.Context, input LoadImageInput) (LoadImageOutput, error) {
+	target, err := resolveLCRTarget(input.TestID)
+	if err != nil {
+		return LoadImageOutput{}, flaterrors.Join(err, errLoadingImage)
+	}
+
+	envs, err := readEnvs()
+	if err != nil {
+		return LoadImageOutput{}, flaterrors.Join(err, errLoadingImage)
+	}
+
+	// I. Validate the registry is reachable before touching the container engine.
+	// Port-forwards started by create live in this process; otherwise start a temporary one.
+	portForwardersMu.Lock()
+	_, forwarding := activePortForwarders[input.TestID]
+	portForwardersMu.Unlock()
+	if !forwarding {
+		pf := NewPortForwarder(target.config, target.config.LocalContainerRegistry.Namespace, target.port)
+		if err := pf.Start(ctx); err != nil {
+			return LoadImageOutput{}, flaterrors.Join(fmt.Errorf("failed to start port-forward: %w", err), errLoadingImage)
+		}
+		defer pf.Stop()
+	}
+
+	registryHost := NewContainerRegistry(nil, target.config.LocalContainerRegistry.Namespace, nil).FQDN()
+	if err := httpsHealthCheck(ctx, target.port, target.config.LocalContainerRegistry.CaCrtPath, registryHost); err != nil {
+		return LoadImageOutput{}, flaterrors.Join(fmt.Errorf("registry is not reachable: %w", err), errLoadingImage)
+	}
+
+	// II. Resolve the local source image.
+	source := input.Image
+	if isImageTarball(input.Image) {
+		source, err = loadImageTarball(envs.ContainerEngineExecutable, input.Image)
+		if err != nil {
+			return LoadImageOutput{}, flaterrors.Join(err, errLoadingImage)
+		}
+	} else if err := checkLocalImageExists(envs.ContainerEngineExecutable, input.Image); err != nil {
+		return LoadImageOutput{}, flaterrors.Join(err, errLoadingImage)
+	}
+
+	// III. Tag and push.
+	var reference string
+	err = withRegistryAccess(ctx, target.config, envs, target.port, func(registryFQDNWithPort string) error {
+		reference, err = rewriteImageRef(source, registryFQDNWithPort)
+		if err != nil {
+			return err
+		}
+		return tagAndPushImage(envs.ContainerEngineExecutable, source, reference)
+	})
+	if err != nil {
+		return LoadImageOutput{}, flaterrors.Join(err, errLoadingImage)
+	}
+
+	return LoadImageOutput{Source: source, Reference: reference}, nil
+}
+
+// handleLoadImageTool handles the "load-image" tool call from MCP clients.
+func handleLoadImageTool(
+	ctx context.Context,
+	_ *mcp.CallToolRequest,
+	input LoadImageInput,
+) (*mcp.CallToolResult, any, error) {
+	log.Printf("Loading image: testID=%s, image=%s", input.TestID, input.Image)
+
+	if result := mcputil.ValidateRequiredWithPrefix("Load image failed", map[string]string{
+		"testID": input.TestID,
+		"image":  input.Image,
+	}); result != nil {
+		return result, nil, nil
+	}
+
+	// Redirect stdout to stderr (push helpers write to stdout, but MCP uses stdout for JSON-RPC)
+	oldStdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = oldStdout }()
+
+	out, err := loadImage(ctx, input)
+	if err != nil {
+		return mcputil.ErrorResult(fmt.Sprintf("Load image failed: %v", err)), nil, nil
+	}
+
+	result, artifact := mcputil.SuccessResultWithArtifact(
+		fmt.Sprintf("Loaded image %s as %s", out.Source, out.Reference), out)
+	return result, artifact, nil
+}
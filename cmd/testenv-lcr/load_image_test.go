@@ -0,0 +1,101 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteImageRef(t *testing.T) {
+	const registry = "testenv-lcr.testenv-lcr.svc.cluster.local:31906"
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare name gets latest tag", ref: "myapp", want: registry + "/myapp:latest"},
+		{name: "name and tag", ref: "myapp:v1", want: registry + "/myapp:v1"},
+		{name: "namespaced name", ref: "org/myapp:v1", want: registry + "/org/myapp:v1"},
+		{name: "podman localhost prefix dropped", ref: "localhost/myapp:v1", want: registry + "/myapp:v1"},
+		{name: "docker hub library dropped", ref: "docker.io/library/nginx:1.27", want: registry + "/nginx:1.27"},
+		{name: "docker hub org kept", ref: "docker.io/bitnami/redis:7", want: registry + "/bitnami/redis:7"},
+		{name: "remote registry dropped", ref: "ghcr.io/org/tool:v2", want: registry + "/org/tool:v2"},
+		{name: "registry with port dropped", ref: "localhost:5000/myapp", want: registry + "/myapp:latest"},
+		{name: "digest rejected", ref: "myapp@sha256:abc", wantErr: true},
+		{name: "empty rejected", ref: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteImageRef(tt.ref, registry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rewriteImageRef(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("rewriteImageRef(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLoadedImageRefs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{name: "docker", output: "Loaded image: myapp:v1\n", want: []string{"myapp:v1"}},
+		{
+			name:   "docker multiple images",
+			output: "Loaded image: a:1\nLoaded image: b:2\n",
+			want:   []string{"a:1", "b:2"},
+		},
+		{
+			name:   "podman",
+			output: "Getting image source signatures\nLoaded image(s): localhost/myapp:v1,localhost/other:v2\n",
+			want:   []string{"localhost/myapp:v1", "localhost/other:v2"},
+		},
+		{name: "untagged image ignored", output: "Loaded image ID: sha256:abc\n", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLoadedImageRefs(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLoadedImageRefs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsImageTarball(t *testing.T) {
+	tests := map[string]bool{
+		"build/myapp.tar":    true,
+		"build/myapp.tar.gz": true,
+		"myapp.tgz":          true,
+		"myapp:v1":           false,
+		"ghcr.io/org/tar:v1": false,
+	}
+
+	for image, want := range tests {
+		if got := isImageTarball(image); got != want {
+			t.Errorf("isImageTarball(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerTools registers the testenv-lcr specific MCP tools.
+func registerTools(server *mcpserver.Server) error {
+	mcpserver.RegisterTool(server, &mcp.Tool{
+		Name:        "gc-image-pull-secrets",
+		Description: "Delete testenv-lcr image pull secrets whose test environment no longer exists",
+	}, handleGCImagePullSecretsTool)
+
+	mcpserver.RegisterTool(server, &mcp.Tool{
+		Name:        "load-image",
+		Description: "Push a local image tarball or image reference into the local container registry of a test environment",
+	}, handleLoadImageTool)
+
+	return nil
+}
@@ -240,6 +240,11 @@ func pushImage(containerEngine, sourceImage, registryFQDN string) error {
 	// This is important because Docker looks for certificates based on the hostname in the tag
 	destImage := fmt.Sprintf("%s/%s", registryFQDN, sourceImage)
 
+	return tagAndPushImage(containerEngine, sourceImage, destImage)
+}
+
+// tagAndPushImage tags sourceImage as destImage and pushes destImage to its registry.
+func tagAndPushImage(containerEngine, sourceImage, destImage string) error {
 	_, _ = fmt.Fprintf(os.Stdout, "⏳ Pushing image: %s -> %s\n", sourceImage, destImage)
 
 	// Tag the image
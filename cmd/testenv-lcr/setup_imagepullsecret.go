@@ -33,11 +33,14 @@ const (
 	defaultImagePullSecretName = "local-container-registry-credentials"
 	imagePullSecretLabel       = "app.kubernetes.io/managed-by"
 	imagePullSecretLabelValue  = "testenv-lcr"
+	// imagePullSecretTestIDLabel records the test environment that owns the secret.
+	imagePullSecretTestIDLabel = "testenv-lcr/test-id"
 )
 
 // ImagePullSecret manages the creation of Kubernetes image pull secrets for the local container registry.
 type ImagePullSecret struct {
 	client       client.Client
+	testID       string
 	secretName   string
 	registryFQDN string
 	username     string
@@ -46,8 +49,10 @@ type ImagePullSecret struct {
 }
 
 // NewImagePullSecret creates a new ImagePullSecret struct.
+// The testID labels created secrets so orphans can be garbage-collected.
 func NewImagePullSecret(
 	cl client.Client,
+	testID string,
 	secretName, registryFQDN, username, password string,
 	caCert []byte,
 ) *ImagePullSecret {
@@ -57,6 +62,7 @@ func NewImagePullSecret(
 
 	return &ImagePullSecret{
 		client:       cl,
+		testID:       testID,
 		secretName:   secretName,
 		registryFQDN: registryFQDN,
 		username:     username,
@@ -105,6 +111,9 @@ func (ips *ImagePullSecret) CreateInNamespace(ctx context.Context, namespace str
 	secret.Labels = map[string]string{
 		imagePullSecretLabel: imagePullSecretLabelValue,
 	}
+	if ips.testID != "" {
+		secret.Labels[imagePullSecretTestIDLabel] = ips.testID
+	}
 
 	if err := ips.client.Create(ctx, secret); err != nil {
 		return "", flaterrors.Join(err, errCreatingImagePullSecret)
@@ -210,6 +219,7 @@ func ListImagePullSecrets(ctx context.Context, cl client.Client, namespace strin
 		result = append(result, ImagePullSecretInfo{
 			Namespace:  secret.Namespace,
 			SecretName: secret.Name,
+			TestID:     secret.Labels[imagePullSecretTestIDLabel],
 			CreatedAt:  secret.CreationTimestamp.Time,
 		})
 	}
@@ -221,5 +231,6 @@ func ListImagePullSecrets(ctx context.Context, cl client.Client, namespace strin
 type ImagePullSecretInfo struct {
 	Namespace  string `json:"namespace"`
 	SecretName string `json:"secretName"`
+	TestID     string `json:"testID,omitempty"`
 	CreatedAt  any    `json:"createdAt"` // time.Time but using any for JSON serialization flexibility
 }
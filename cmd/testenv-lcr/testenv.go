@@ -142,6 +142,23 @@ func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec)
 		return nil, fmt.Errorf("testenv-kind not executed: missing clusterName in metadata - containerd trust cannot be configured")
 	}
 
+	// Dry-run: report the planned registry without leasing ports or touching the cluster
+	if input.DryRun {
+		plan := []string{
+			fmt.Sprintf("deploy local container registry in namespace %s of cluster %s", config.LocalContainerRegistry.Namespace, clusterName),
+		}
+		for _, img := range images {
+			plan = append(plan, fmt.Sprintf("push image %s", img.Name))
+		}
+		return &engineframework.TestEnvArtifact{
+			TestID:           input.TestID,
+			Files:            map[string]string{},
+			Metadata:         map[string]string{},
+			ManagedResources: []string{},
+			Plan:             plan,
+		}, nil
+	}
+
 	// Acquire dynamic port for this cluster using the port lease manager.
 	// This port is used for NodePort, service port, target port, container port, and port-forward.
 	portLeaseManager := NewPortLeaseManager()
@@ -194,7 +211,7 @@ func Create(ctx context.Context, input engineframework.CreateInput, spec *Spec)
 	config.LocalContainerRegistry.CredentialPath = credentialPath
 
 	// Call the existing setup logic with the overridden config and dynamic port
-	if err = setupWithConfig(&config, dynamicPort); err != nil {
+	if err = setupWithConfig(&config, input.TestID, dynamicPort); err != nil {
 		return nil, fmt.Errorf("failed to setup local container registry: %w", err)
 	}
 
@@ -343,6 +360,11 @@ func Delete(ctx context.Context, input engineframework.DeleteInput, _ *Spec) err
 		return nil
 	}
 
+	if input.DryRun {
+		log.Printf("Dry-run: would tear down local container registry for testID=%s", input.TestID)
+		return nil
+	}
+
 	// Stop port-forward if running for this testID
 	portForwardersMu.Lock()
 	if pf, ok := activePortForwarders[input.TestID]; ok {
@@ -396,8 +418,9 @@ var errSettingLocalContainerRegistry = errors.New("error received while setting
 
 // setupWithConfig executes the setup logic with an optional pre-loaded config.
 // If cfg is nil, it reads the config from forge.yaml.
+// The testID labels the created image pull secrets with their owning test environment.
 // If dynamicPort > 0, it is used as the port for the container registry (NodePort, service port, etc.).
-func setupWithConfig(cfg *forge.Spec, dynamicPort int32) error {
+func setupWithConfig(cfg *forge.Spec, testID string, dynamicPort int32) error {
 	_, _ = fmt.Fprintln(os.Stdout, "Setting up "+Name)
 	ctx := context.Background()
 
@@ -499,6 +522,7 @@ func setupWithConfig(cfg *forge.Spec, dynamicPort int32) error {
 			registryFQDNWithPort := fmt.Sprintf("%s:%d", containerRegistry.FQDN(), containerRegistry.Port())
 			imagePullSecret := NewImagePullSecret(
 				cl,
+				testID,
 				config.LocalContainerRegistry.ImagePullSecretName,
 				registryFQDNWithPort,
 				cred.credentials.Username,
@@ -1,6 +1,6 @@
 // Code generated by forge-dev. DO NOT EDIT.
 // Source: spec.openapi.yaml
-// SourceChecksum: sha256:418d90d7aeacf0c3c3305eb74c742fd7ed2f57b8f8634b43c081c8321d254465
+// SourceChecksum: sha256:8cff45db2b879b08c645cfa54684e6a616046b51cb0b8b9d183864c796f9be8e
 
 package main
 
@@ -19,6 +19,8 @@ package main
 
 import (
 	"context"
+	"embed"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -38,12 +40,18 @@ var (
 	BuildTimestamp = "unknown"
 )
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for the docs subcommand.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "testenv-stub",
 	LocalDir:     "cmd/testenv-stub/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 func main() {
@@ -82,7 +90,7 @@ func runMCPServer() error {
 		return err
 	}
 
-	return server.RunDefault()
+	return server.Run(enginecli.Context())
 }
 
 // createStubEnv implements the CreateFunc for the stub test environment.
@@ -92,6 +100,16 @@ func createStubEnv(ctx context.Context, input engineframework.CreateInput) (*eng
 
 	// Create a stub file in tmpDir to simulate artifact creation
 	stubFilePath := filepath.Join(input.TmpDir, "stub-marker.txt")
+	if input.DryRun {
+		return &engineframework.TestEnvArtifact{
+			TestID:           input.TestID,
+			Files:            map[string]string{},
+			Metadata:         map[string]string{},
+			ManagedResources: []string{},
+			Plan:             []string{"write stub marker to " + stubFilePath},
+		}, nil
+	}
+
 	stubContent := []byte("stub test environment created at " + time.Now().Format(time.RFC3339))
 	if err := os.WriteFile(stubFilePath, stubContent, 0o644); err != nil {
 		return nil, err
@@ -105,9 +123,10 @@ func createStubEnv(ctx context.Context, input engineframework.CreateInput) (*eng
 			"testenv-stub.marker": "stub-marker.txt",
 		},
 		Metadata: map[string]string{
-			"testenv-stub.createdAt": time.Now().Format(time.RFC3339),
-			"testenv-stub.testID":    input.TestID,
-			"testenv-stub.stage":     input.Stage,
+			"testenv-stub.createdAt":  time.Now().Format(time.RFC3339),
+			"testenv-stub.testID":     input.TestID,
+			"testenv-stub.stage":      input.Stage,
+			"testenv-stub.markerPath": stubFilePath,
 		},
 		ManagedResources: []string{stubFilePath},
 		Env: map[string]string{
@@ -117,11 +136,32 @@ func createStubEnv(ctx context.Context, input engineframework.CreateInput) (*eng
 }
 
 // deleteStubEnv implements the DeleteFunc for the stub test environment.
-// It does nothing since the stub doesn't create real resources.
+// It removes the stub marker, and reports a no-op if the marker is already gone
+// (e.g. the orchestrator already removed tmpDir).
 func deleteStubEnv(ctx context.Context, input engineframework.DeleteInput) error {
 	log.Printf("Deleting stub test environment: testID=%s", input.TestID)
 
-	// Nothing to clean up for stub - tmpDir cleanup is handled by the orchestrator
-	log.Printf("Stub test environment deleted (no-op): testID=%s", input.TestID)
+	markerPath := input.Metadata["testenv-stub.markerPath"]
+	if markerPath == "" {
+		log.Printf("No stub marker in metadata, nothing to delete: testID=%s", input.TestID)
+		engineframework.MarkNothingDeleted(ctx)
+		return nil
+	}
+
+	if input.DryRun {
+		log.Printf("Dry-run: would remove stub marker %s", markerPath)
+		return nil
+	}
+
+	if err := os.Remove(markerPath); err != nil {
+		if engineframework.IsNotFound(err) {
+			log.Printf("Stub marker already removed: %s", markerPath)
+			engineframework.MarkNothingDeleted(ctx)
+			return nil
+		}
+		return fmt.Errorf("failed to remove stub marker: %w", err)
+	}
+
+	log.Printf("Stub test environment deleted: testID=%s", input.TestID)
 	return nil
 }
@@ -114,6 +114,14 @@ func validateTestenvSpec(ctx context.Context, input mcptypes.ConfigValidateInput
 		}
 	}
 
+	// Validate subengine dependencies (unknown references, cycles)
+	if _, err := creationLayers(subengines); err != nil {
+		errors = append(errors, mcptypes.ValidationError{
+			Field:   "testenv[].dependsOn",
+			Message: err.Error(),
+		})
+	}
+
 	// Step 3 & 4: For each subengine, determine config and call config-validate
 	results := make([]validationResult, 0, len(subengines))
 
@@ -302,10 +310,21 @@ func extractSubenginesFromSpec(spec map[string]interface{}) ([]forge.TestenvEngi
 		// Extract deferTemplates field
 		deferTemplates, _ := itemMap["deferTemplates"].(bool)
 
+		// Extract dependsOn field
+		var dependsOn []string
+		if depsRaw, ok := itemMap["dependsOn"].([]interface{}); ok {
+			for _, dep := range depsRaw {
+				if depStr, ok := dep.(string); ok {
+					dependsOn = append(dependsOn, depStr)
+				}
+			}
+		}
+
 		subengines = append(subengines, forge.TestenvEngineSpec{
 			Engine:         engine,
 			Spec:           subSpec,
 			DeferTemplates: deferTemplates,
+			DependsOn:      dependsOn,
 		})
 	}
 
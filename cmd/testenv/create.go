@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -32,8 +33,10 @@ import (
 )
 
 // cmdCreate creates a new test environment for the given stage.
-// Returns the generated test ID.
-func cmdCreate(stageName string) (string, error) {
+// If testID is set, the partially created environment with that ID is resumed instead:
+// only the subengines that did not complete are created again.
+// Returns the test ID.
+func cmdCreate(stageName, testID string) (string, error) {
 	if stageName == "" {
 		return "", fmt.Errorf("stage name is required")
 	}
@@ -57,39 +60,71 @@ func cmdCreate(stageName string) (string, error) {
 		return "", fmt.Errorf("test stage not found in forge.yaml: %s", stageName)
 	}
 
-	// Generate unique test ID
-	testID := generateTestID(stageName)
-
-	// Create tmpDir for this test environment in project's ./.forge/tmp directory
-	// Pattern: ./.forge/tmp/test-{stage}-{testID}
-	rootDir, err := os.Getwd()
+	// Get artifact store path from config
+	artifactStorePath, err := forge.GetArtifactStorePath(config.ArtifactStorePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to get working directory: %w", err)
+		return "", fmt.Errorf("failed to get artifact store path: %w", err)
 	}
 
-	tmpBase := filepath.Join(rootDir, ".forge", "tmp")
-	if err := os.MkdirAll(tmpBase, 0o755); err != nil {
-		return "", fmt.Errorf("failed to create tmp base directory: %w", err)
-	}
+	var env *forge.TestEnvironment
+	if testID != "" {
+		// Resume an existing test environment
+		existing, err := readTestEnvironment(artifactStorePath, testID)
+		if err != nil {
+			return "", err
+		}
 
-	// testID already includes "test-{stage}-{date}-{hash}", so just use it directly
-	tmpDir := filepath.Join(tmpBase, testID)
-	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
-		return "", fmt.Errorf("failed to create tmpDir: %w", err)
-	}
+		env, err = resumeEnvironment(existing, stageName)
+		if err != nil {
+			return "", err
+		}
+		if env == nil {
+			// Creation already completed: nothing to do
+			fmt.Fprintf(os.Stderr, "Test environment %s is already created\n", testID)
+			fmt.Fprintln(os.Stderr, testID)
+			return testID, nil
+		}
 
-	// Initialize test environment
-	env := &forge.TestEnvironment{
-		ID:               testID,
-		Name:             stageName,
-		Status:           forge.TestStatusCreated,
-		CreatedAt:        time.Now().UTC(),
-		UpdatedAt:        time.Now().UTC(),
-		TmpDir:           tmpDir,
-		Files:            make(map[string]string),
-		ManagedResources: []string{tmpDir}, // tmpDir will be cleaned up
-		Metadata:         make(map[string]string),
+		if err := os.MkdirAll(env.TmpDir, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create tmpDir: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Resuming test environment %s\n", testID)
+	} else {
+		// Generate unique test ID
+		testID = generateTestID(stageName)
+
+		// Create tmpDir for this test environment in project's ./.forge/tmp directory
+		// Pattern: ./.forge/tmp/test-{stage}-{testID}
+		rootDir, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+
+		tmpBase := filepath.Join(rootDir, ".forge", "tmp")
+		if err := os.MkdirAll(tmpBase, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create tmp base directory: %w", err)
+		}
+
+		// testID already includes "test-{stage}-{date}-{hash}", so just use it directly
+		tmpDir := filepath.Join(tmpBase, testID)
+		if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create tmpDir: %w", err)
+		}
+
+		// Initialize test environment
+		env = &forge.TestEnvironment{
+			ID:               testID,
+			Name:             stageName,
+			Status:           forge.TestStatusCreated,
+			CreatedAt:        time.Now().UTC(),
+			UpdatedAt:        time.Now().UTC(),
+			TmpDir:           tmpDir,
+			Files:            make(map[string]string),
+			ManagedResources: []string{tmpDir}, // tmpDir will be cleaned up
+			Metadata:         make(map[string]string),
+		}
 	}
+	tmpDir := env.TmpDir
 
 	// Find the setup alias for this test stage
 	setupSpec := testSpec.Testenv
@@ -153,31 +188,34 @@ func cmdCreate(stageName string) (string, error) {
 		// Alias reference (e.g., alias://setup-integration)
 		setupAlias := strings.TrimPrefix(setupSpec, "alias://")
 
-		// Orchestrate testenv-subengines
-		if err := orchestrateCreate(config, setupAlias, env); err != nil {
-			// Cleanup tmpDir on failure
+		// Orchestrate testenv-subengines, recording progress after each layer
+		// so that an interrupted create can be resumed
+		orchestrator := defaultCreateOrchestrator()
+		orchestrator.checkpoint = func(env *forge.TestEnvironment) error {
+			env.Status = forge.TestStatusPartiallyCreated
+			env.UpdatedAt = time.Now().UTC()
+			return saveTestEnvironment(artifactStorePath, env)
+		}
+		if err := orchestrator.run(config, setupAlias, env); err != nil {
+			if hasSubengineRecords(env.Metadata) {
+				if orchestrator.keepOnFailure {
+					// Keep tmpDir and the partially created environment for resuming
+					return "", fmt.Errorf("failed to orchestrate testenv-subengines: %w (resume with: testenv create %s %s)", err, stageName, testID)
+				}
+				// The rollback left subengines behind: keep the environment so they can be deleted
+				return "", fmt.Errorf("failed to orchestrate testenv-subengines: %w (clean up with: testenv delete %s)", err, testID)
+			}
+			// Cleanup tmpDir and the recorded progress on failure
 			_ = os.RemoveAll(tmpDir)
+			_ = forge.AtomicDeleteTestEnvironment(artifactStorePath, testID)
 			return "", fmt.Errorf("failed to orchestrate testenv-subengines: %w", err)
 		}
 	}
 
-	// Get artifact store path from config
-	artifactStorePath, err := forge.GetArtifactStorePath(config.ArtifactStorePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to get artifact store path: %w", err)
-	}
-
-	store, err := forge.ReadOrCreateArtifactStore(artifactStorePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read artifact store: %w", err)
-	}
-
-	// Add test environment to store
-	forge.AddOrUpdateTestEnvironment(&store, env)
-
-	// Write artifact store
-	if err := forge.WriteArtifactStore(artifactStorePath, store); err != nil {
-		return "", fmt.Errorf("failed to write artifact store: %w", err)
+	env.Status = forge.TestStatusCreated
+	env.UpdatedAt = time.Now().UTC()
+	if err := saveTestEnvironment(artifactStorePath, env); err != nil {
+		return "", err
 	}
 
 	// Output test ID to stderr (safe for both CLI and MCP usage)
@@ -186,6 +224,18 @@ func cmdCreate(stageName string) (string, error) {
 	return testID, nil
 }
 
+// saveTestEnvironment adds or updates env in the artifact store.
+func saveTestEnvironment(artifactStorePath string, env *forge.TestEnvironment) error {
+	// Add test environment to store
+	if err := forge.UpdateArtifactStore(artifactStorePath, func(store *forge.ArtifactStore) error {
+		forge.AddOrUpdateTestEnvironment(store, env)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update artifact store: %w", err)
+	}
+	return nil
+}
+
 // generateTestID generates a unique test environment ID.
 // Format: test-<stage>-YYYYMMDD-XXXXXXXX
 func generateTestID(stageName string) string {
@@ -199,8 +249,41 @@ func generateTestID(stageName string) string {
 	return fmt.Sprintf("test-%s-%s-%s", stageName, dateStr, suffix)
 }
 
-// orchestrateCreate calls testenv-subengines in order to set up the test environment.
+// createOrchestrator calls the create tools of testenv-subengines.
+// Engine calls are fields so that tests can replace them.
+type createOrchestrator struct {
+	resolveEngine func(engineURI string) (string, []string, error)
+	callEngine    func(command string, args []string, toolName string, params interface{}) (interface{}, error)
+	// checkpoint, if set, persists env after each layer of subengines
+	checkpoint func(env *forge.TestEnvironment) error
+	// keepOnFailure keeps the completed subengines when a later one fails,
+	// instead of rolling them back
+	keepOnFailure bool
+}
+
+// keepOnFailureEnvVar, when set, keeps partially created test environments for debugging and resuming.
+const keepOnFailureEnvVar = "FORGE_KEEP_ON_FAILURE"
+
+// defaultCreateOrchestrator returns a createOrchestrator calling engines over MCP.
+func defaultCreateOrchestrator() createOrchestrator {
+	return createOrchestrator{
+		resolveEngine: resolveEngineURI,
+		callEngine:    callMCPEngine,
+		keepOnFailure: os.Getenv(keepOnFailureEnvVar) != "",
+	}
+}
+
+// orchestrateCreate calls testenv-subengines to set up the test environment.
 func orchestrateCreate(config forge.Spec, setupAlias string, env *forge.TestEnvironment) error {
+	return defaultCreateOrchestrator().run(config, setupAlias, env)
+}
+
+// run calls testenv-subengines to set up the test environment.
+// Subengines run in configuration order unless dependencies are declared with dependsOn,
+// in which case independent subengines are created concurrently.
+// Subengines already recorded as completed in env.Metadata are not created again.
+// If a subengine fails, the completed subengines are rolled back unless keepOnFailure is set.
+func (o createOrchestrator) run(config forge.Spec, setupAlias string, env *forge.TestEnvironment) error {
 	// Resolve the alias to get engine configuration
 	var engineConfig *forge.EngineConfig
 	for i := range config.Engines {
@@ -225,6 +308,17 @@ func orchestrateCreate(config forge.Spec, setupAlias string, env *forge.TestEnvi
 		return fmt.Errorf("no testenv-subengines configured for %s", setupAlias)
 	}
 
+	if err := o.createSubengines(subengines, env); err != nil {
+		if o.keepOnFailure {
+			return err
+		}
+		return o.rollback(subengines, env, err)
+	}
+	return nil
+}
+
+// createSubengines creates the subengines that are not recorded as completed in env.Metadata.
+func (o createOrchestrator) createSubengines(subengines []forge.TestenvEngineSpec, env *forge.TestEnvironment) error {
 	// Get project root directory for path resolution in subengines
 	rootDir, err := os.Getwd()
 	if err != nil {
@@ -242,184 +336,383 @@ func orchestrateCreate(config forge.Spec, setupAlias string, env *forge.TestEnvi
 	accumulatedMetadata := make(map[string]string)
 	envTracker := testenvutil.NewEnvSourceTracker()
 
-	// Call each subengine in order
-	for subengineIndex, subengine := range subengines {
-		fmt.Fprintf(os.Stderr, "Setting up %s...\n", subengine.Engine)
-
-		// Determine spec to use - either expand templates or pass verbatim
-		var specToUse map[string]interface{}
-		if subengine.DeferTemplates {
-			// Skip template expansion - pass spec verbatim to sub-engine
-			specToUse = subengine.Spec
-		} else {
-			// Default: expand templates using accumulated environment
-			specToUse = subengine.Spec
-			var portEnvVars map[string]string
-			if len(subengine.Spec) > 0 {
-				portEnvVars = make(map[string]string)
-				accumulatedEnv := envTracker.ToMap()
-
-				// Open allocator (acquires flock, loads state) before template expansion
-				if err := allocator.Open(); err != nil {
-					return fmt.Errorf("failed to open port allocator: %w", err)
-				}
+	// Group subengines into layers: subengines in the same layer are independent
+	// and are created concurrently, layers are created in order
+	layers, err := creationLayers(subengines)
+	if err != nil {
+		return fmt.Errorf("failed to resolve subengine dependencies: %w", err)
+	}
 
-				wrappedAllocate := func(args ...any) (string, error) {
-					if len(args) < 2 {
-						return "", fmt.Errorf("allocateOpenPort requires at least 2 args (addr, id), got %d", len(args))
-					}
-					addr, ok := args[0].(string)
-					if !ok {
-						return "", fmt.Errorf("allocateOpenPort: addr must be a string")
-					}
-					id, ok := args[1].(string)
-					if !ok {
-						return "", fmt.Errorf("allocateOpenPort: id must be a string")
-					}
+	// Subengines completed by a previous, interrupted create
+	completed, err := completedSubengines(env.Metadata, subengines)
+	if err != nil {
+		return err
+	}
 
-					var port string
-					var err error
-					if len(args) == 4 {
-						minPort, err2 := toInt(args[2])
-						if err2 != nil {
-							return "", fmt.Errorf("allocateOpenPort: minPort: %w", err2)
-						}
-						maxPort, err2 := toInt(args[3])
-						if err2 != nil {
-							return "", fmt.Errorf("allocateOpenPort: maxPort: %w", err2)
-						}
-						port, err = allocator.AllocateInRange(addr, id, minPort, maxPort)
-					} else {
-						port, err = allocator.Allocate(addr, id)
-					}
-					if err != nil {
-						return "", err
-					}
-					envKey := NormalizePortAllocEnvKey(id)
-					portEnvVars[envKey] = port
-					return port, nil
+	for _, layer := range layers {
+		// Prepare each subengine sequentially: template expansion and port allocation
+		// share the accumulated environment and the allocator state file.
+		// Completed subengines only replay the ports they allocated.
+		prepared := make(map[int]preparedSubengine, len(layer))
+		var pending []int
+		for _, subengineIndex := range layer {
+			if record, ok := completed[subengineIndex]; ok {
+				if len(record.PortEnv) > 0 {
+					envTracker.Merge(record.PortEnv, nil, subengineIndex)
 				}
-				funcMap := template.FuncMap{
-					"allocateOpenPort": wrappedAllocate,
-				}
-
-				var err error
-				specToUse, err = templateutil.ExpandTemplates(subengine.Spec, accumulatedEnv, templateutil.WithFuncMap(funcMap))
+				continue
+			}
+			p, err := o.prepareSubengine(subengines[subengineIndex], subengineIndex, env, rootDir, accumulatedMetadata, envTracker, allocator)
+			if err != nil {
+				return err
+			}
+			prepared[subengineIndex] = p
+			pending = append(pending, subengineIndex)
+		}
 
-				// Close allocator (writes state if dirty, releases flock) after template expansion
-				if closeErr := allocator.Close(); closeErr != nil {
-					if err != nil {
-						return fmt.Errorf("failed to expand templates for %s: %w (also failed to close port allocator: %v)", subengine.Engine, err, closeErr)
+		// Call the pending subengines' create tools concurrently
+		results := make(map[int]any, len(pending))
+		var createErr error
+		if len(pending) > 0 {
+			pendingResults, errs := runLayer(pending, func(index int) (any, error) {
+				p := prepared[index]
+				fmt.Fprintf(os.Stderr, "Setting up %s...\n", subengines[index].Engine)
+				return o.callEngine(p.command, p.args, "create", p.params)
+			})
+			for k, subengineIndex := range pending {
+				if errs[k] != nil {
+					if createErr == nil {
+						createErr = fmt.Errorf("failed to create with %s: %w", subengines[subengineIndex].Engine, errs[k])
 					}
-					return fmt.Errorf("failed to close port allocator: %w", closeErr)
+					continue
 				}
+				results[subengineIndex] = pendingResults[k]
+			}
+		}
 
+		// Merge responses in configuration order so the result does not depend on timing
+		for _, subengineIndex := range layer {
+			if record, ok := completed[subengineIndex]; ok {
+				envPropagation, err := subengineEnvPropagation(subengines[subengineIndex])
 				if err != nil {
-					return fmt.Errorf("failed to expand templates for %s: %w", subengine.Engine, err)
+					return err
 				}
+				mergeSubengineResult(record.Result, env, accumulatedMetadata, envTracker, envPropagation, subengineIndex)
+				fmt.Fprintf(os.Stderr, "  ✓ %s already set up\n", subengines[subengineIndex].Engine)
+				continue
+			}
+
+			result, ok := results[subengineIndex]
+			if !ok {
+				continue
 			}
-			if len(portEnvVars) > 0 {
-				envTracker.Merge(portEnvVars, nil, subengineIndex)
+			p := prepared[subengineIndex]
+			mergeSubengineResult(result, env, accumulatedMetadata, envTracker, p.envPropagation, subengineIndex)
+			if err := recordSubengine(env, subengineIndex, subengineRecord{
+				Engine:  subengines[subengineIndex].Engine,
+				PortEnv: p.portEnv,
+				Result:  result,
+			}); err != nil {
+				return err
 			}
+			fmt.Fprintf(os.Stderr, "  ✓ %s setup complete\n", subengines[subengineIndex].Engine)
 		}
 
-		// Resolve engine URI to binary path
-		command, args, err := resolveEngineURI(subengine.Engine)
-		if err != nil {
-			return fmt.Errorf("failed to resolve engine %s: %w", subengine.Engine, err)
+		// Persist progress, including subengines of this layer that succeeded
+		// when another one failed
+		env.Env = envTracker.ToMap()
+		if o.checkpoint != nil && len(pending) > 0 {
+			if err := o.checkpoint(env); err != nil {
+				if createErr != nil {
+					return fmt.Errorf("%w (also failed to record progress: %v)", createErr, err)
+				}
+				return fmt.Errorf("failed to record progress: %w", err)
+			}
 		}
 
-		// Extract EnvPropagation from spec if present
-		var envPropagation *forge.EnvPropagation
-		if envPropSpec, exists := subengine.Spec["envPropagation"]; exists {
-			// Convert map[string]interface{} to *EnvPropagation via JSON marshal/unmarshal
-			envPropagation, err = extractEnvPropagation(envPropSpec)
-			if err != nil {
-				return fmt.Errorf("failed to parse envPropagation for %s: %w", subengine.Engine, err)
-			}
+		if createErr != nil {
+			return createErr
+		}
+	}
 
-			// Validate EnvPropagation
-			if err := envPropagation.Validate(); err != nil {
-				return fmt.Errorf("invalid envPropagation for %s: %w", subengine.Engine, err)
-			}
+	// Store final merged environment in TestEnvironment
+	env.Env = envTracker.ToMap()
+
+	return nil
+}
+
+// rollback deletes the subengines recorded as completed in env.Metadata, in reverse
+// dependency order, after createErr interrupted the creation.
+// Rollback is best-effort: every completed subengine is deleted even if another one fails,
+// and only the records of the subengines that could not be deleted are kept.
+// The returned error wraps createErr and lists the failed cleanups.
+func (o createOrchestrator) rollback(subengines []forge.TestenvEngineSpec, env *forge.TestEnvironment, createErr error) error {
+	completed, err := completedSubengines(env.Metadata, subengines)
+	if err != nil || len(completed) == 0 {
+		// Records that do not match the configuration cannot be rolled back safely
+		return createErr
+	}
+
+	order, err := teardownOrder(subengines)
+	if err != nil {
+		return createErr
+	}
+
+	fmt.Fprintf(os.Stderr, "Rolling back %d created subengine(s) (set %s to keep them)...\n", len(completed), keepOnFailureEnvVar)
+
+	var cleanupErrors []error
+	for _, i := range order {
+		if _, ok := completed[i]; !ok {
+			continue
+		}
+		subengine := subengines[i]
+
+		command, args, err := o.resolveEngine(subengine.Engine)
+		if err != nil {
+			cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to resolve engine %s: %w", subengine.Engine, err))
+			continue
 		}
 
-		// Prepare parameters for MCP call
 		params := map[string]any{
 			"testID":   env.ID,
-			"stage":    env.Name,
-			"tmpDir":   env.TmpDir,
-			"rootDir":  rootDir,
-			"metadata": accumulatedMetadata, // Pass accumulated metadata from previous subengines
-			"env":      envTracker.ToMap(),  // Pass accumulated environment from previous subengines
+			"metadata": env.Metadata,
 		}
-
-		// Add spec if provided (either expanded or verbatim based on DeferTemplates)
-		if len(specToUse) > 0 {
-			params["spec"] = specToUse
+		if _, err := o.callEngine(command, args, "delete", params); err != nil {
+			cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to delete with %s: %w", subengine.Engine, err))
+			continue
 		}
 
-		// Add envPropagation if present
-		if envPropagation != nil {
-			params["envPropagation"] = envPropagation
-		}
+		delete(env.Metadata, subengineRecordPrefix+strconv.Itoa(i))
+		fmt.Fprintf(os.Stderr, "  ✓ %s rolled back\n", subengine.Engine)
+	}
 
-		// Call subengine's create tool via MCP
-		result, err := callMCPEngine(command, args, "create", params)
-		if err != nil {
-			return fmt.Errorf("failed to create with %s: %w", subengine.Engine, err)
+	// Persist the subengines that are still created
+	if o.checkpoint != nil {
+		if err := o.checkpoint(env); err != nil {
+			cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to record rollback: %w", err))
 		}
+	}
 
-		// Extract response from structured content
-		if resultMap, ok := result.(map[string]interface{}); ok {
-			// Merge files from subengine response
-			if files, ok := resultMap["files"].(map[string]interface{}); ok {
-				for key, value := range files {
-					if strValue, ok := value.(string); ok {
-						env.Files[key] = strValue
-					}
-				}
+	if len(cleanupErrors) > 0 {
+		return fmt.Errorf("%w (rollback errors, resources may be leaked: %v)", createErr, cleanupErrors)
+	}
+	return createErr
+}
+
+// preparedSubengine holds everything needed to call a subengine's create tool.
+type preparedSubengine struct {
+	command        string
+	args           []string
+	params         map[string]any
+	envPropagation *forge.EnvPropagation
+	// portEnv holds the environment variables of the ports allocated while expanding templates
+	portEnv map[string]string
+}
+
+// prepareSubengine expands the subengine's spec templates, resolves its engine URI
+// and builds the parameters for its create call.
+func (o createOrchestrator) prepareSubengine(
+	subengine forge.TestenvEngineSpec,
+	subengineIndex int,
+	env *forge.TestEnvironment,
+	rootDir string,
+	accumulatedMetadata map[string]string,
+	envTracker *testenvutil.EnvSourceTracker,
+	allocator *portalloc.PortAllocator,
+) (preparedSubengine, error) {
+	// Determine spec to use - either expand templates or pass verbatim
+	var specToUse map[string]interface{}
+	var portEnvVars map[string]string
+	if subengine.DeferTemplates {
+		// Skip template expansion - pass spec verbatim to sub-engine
+		specToUse = subengine.Spec
+	} else {
+		// Default: expand templates using accumulated environment
+		specToUse = subengine.Spec
+		if len(subengine.Spec) > 0 {
+			portEnvVars = make(map[string]string)
+			accumulatedEnv := envTracker.ToMap()
+
+			// Open allocator (acquires flock, loads state) before template expansion
+			if err := allocator.Open(); err != nil {
+				return preparedSubengine{}, fmt.Errorf("failed to open port allocator: %w", err)
 			}
 
-			// Merge metadata from subengine response and accumulate for next subengine
-			if metadata, ok := resultMap["metadata"].(map[string]interface{}); ok {
-				for key, value := range metadata {
-					if strValue, ok := value.(string); ok {
-						env.Metadata[key] = strValue
-						accumulatedMetadata[key] = strValue
-					}
+			wrappedAllocate := func(args ...any) (string, error) {
+				if len(args) < 2 {
+					return "", fmt.Errorf("allocateOpenPort requires at least 2 args (addr, id), got %d", len(args))
+				}
+				addr, ok := args[0].(string)
+				if !ok {
+					return "", fmt.Errorf("allocateOpenPort: addr must be a string")
+				}
+				id, ok := args[1].(string)
+				if !ok {
+					return "", fmt.Errorf("allocateOpenPort: id must be a string")
 				}
-			}
 
-			// Add managed resources from subengine response
-			if resources, ok := resultMap["managedResources"].([]interface{}); ok {
-				for _, resource := range resources {
-					if strResource, ok := resource.(string); ok {
-						env.ManagedResources = append(env.ManagedResources, strResource)
+				var port string
+				var err error
+				if len(args) == 4 {
+					minPort, err2 := toInt(args[2])
+					if err2 != nil {
+						return "", fmt.Errorf("allocateOpenPort: minPort: %w", err2)
+					}
+					maxPort, err2 := toInt(args[3])
+					if err2 != nil {
+						return "", fmt.Errorf("allocateOpenPort: maxPort: %w", err2)
 					}
+					port, err = allocator.AllocateInRange(addr, id, minPort, maxPort)
+				} else {
+					port, err = allocator.Allocate(addr, id)
 				}
+				if err != nil {
+					return "", err
+				}
+				envKey := NormalizePortAllocEnvKey(id)
+				portEnvVars[envKey] = port
+				return port, nil
+			}
+			funcMap := template.FuncMap{
+				"allocateOpenPort": wrappedAllocate,
 			}
 
-			// Merge environment variables from subengine response
-			if envMap, ok := resultMap["env"].(map[string]interface{}); ok {
-				newEnv := make(map[string]string)
-				for key, value := range envMap {
-					if strValue, ok := value.(string); ok {
-						newEnv[key] = strValue
-					}
+			var err error
+			specToUse, err = templateutil.ExpandTemplates(subengine.Spec, accumulatedEnv, templateutil.WithFuncMap(funcMap))
+
+			// Close allocator (writes state if dirty, releases flock) after template expansion
+			if closeErr := allocator.Close(); closeErr != nil {
+				if err != nil {
+					return preparedSubengine{}, fmt.Errorf("failed to expand templates for %s: %w (also failed to close port allocator: %v)", subengine.Engine, err, closeErr)
 				}
-				// Merge with priority-based resolution
-				envTracker.Merge(newEnv, envPropagation, subengineIndex)
+				return preparedSubengine{}, fmt.Errorf("failed to close port allocator: %w", closeErr)
+			}
+
+			if err != nil {
+				return preparedSubengine{}, fmt.Errorf("failed to expand templates for %s: %w", subengine.Engine, err)
 			}
 		}
+		if len(portEnvVars) > 0 {
+			envTracker.Merge(portEnvVars, nil, subengineIndex)
+		}
+	}
 
-		fmt.Fprintf(os.Stderr, "  ✓ %s setup complete\n", subengine.Engine)
+	// Resolve engine URI to binary path
+	command, args, err := o.resolveEngine(subengine.Engine)
+	if err != nil {
+		return preparedSubengine{}, fmt.Errorf("failed to resolve engine %s: %w", subengine.Engine, err)
 	}
 
-	// Store final merged environment in TestEnvironment
-	env.Env = envTracker.ToMap()
+	envPropagation, err := subengineEnvPropagation(subengine)
+	if err != nil {
+		return preparedSubengine{}, err
+	}
 
-	return nil
+	// Prepare parameters for MCP call
+	params := map[string]any{
+		"testID":   env.ID,
+		"stage":    env.Name,
+		"tmpDir":   env.TmpDir,
+		"rootDir":  rootDir,
+		"metadata": accumulatedMetadata, // Pass accumulated metadata from previous subengines
+		"env":      envTracker.ToMap(),  // Pass accumulated environment from previous subengines
+	}
+
+	// Add spec if provided (either expanded or verbatim based on DeferTemplates)
+	if len(specToUse) > 0 {
+		params["spec"] = specToUse
+	}
+
+	// Add envPropagation if present
+	if envPropagation != nil {
+		params["envPropagation"] = envPropagation
+	}
+
+	return preparedSubengine{
+		command:        command,
+		args:           args,
+		params:         params,
+		envPropagation: envPropagation,
+		portEnv:        portEnvVars,
+	}, nil
+}
+
+// subengineEnvPropagation extracts and validates the envPropagation of the subengine's spec, if present.
+func subengineEnvPropagation(subengine forge.TestenvEngineSpec) (*forge.EnvPropagation, error) {
+	envPropSpec, exists := subengine.Spec["envPropagation"]
+	if !exists {
+		return nil, nil
+	}
+
+	// Convert map[string]interface{} to *EnvPropagation via JSON marshal/unmarshal
+	envPropagation, err := extractEnvPropagation(envPropSpec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse envPropagation for %s: %w", subengine.Engine, err)
+	}
+
+	// Validate EnvPropagation
+	if err := envPropagation.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid envPropagation for %s: %w", subengine.Engine, err)
+	}
+
+	return envPropagation, nil
+}
+
+// mergeSubengineResult merges a subengine's create response into the test environment
+// and the state accumulated for the next subengines.
+func mergeSubengineResult(
+	result any,
+	env *forge.TestEnvironment,
+	accumulatedMetadata map[string]string,
+	envTracker *testenvutil.EnvSourceTracker,
+	envPropagation *forge.EnvPropagation,
+	subengineIndex int,
+) {
+	// Extract response from structured content
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	// Merge files from subengine response
+	if files, ok := resultMap["files"].(map[string]interface{}); ok {
+		for key, value := range files {
+			if strValue, ok := value.(string); ok {
+				env.Files[key] = strValue
+			}
+		}
+	}
+
+	// Merge metadata from subengine response and accumulate for next subengine
+	if metadata, ok := resultMap["metadata"].(map[string]interface{}); ok {
+		for key, value := range metadata {
+			if strValue, ok := value.(string); ok {
+				env.Metadata[key] = strValue
+				accumulatedMetadata[key] = strValue
+			}
+		}
+	}
+
+	// Add managed resources from subengine response
+	if resources, ok := resultMap["managedResources"].([]interface{}); ok {
+		for _, resource := range resources {
+			if strResource, ok := resource.(string); ok {
+				env.ManagedResources = append(env.ManagedResources, strResource)
+			}
+		}
+	}
+
+	// Merge environment variables from subengine response
+	if envMap, ok := resultMap["env"].(map[string]interface{}); ok {
+		newEnv := make(map[string]string)
+		for key, value := range envMap {
+			if strValue, ok := value.(string); ok {
+				newEnv[key] = strValue
+			}
+		}
+		// Merge with priority-based resolution
+		envTracker.Merge(newEnv, envPropagation, subengineIndex)
+	}
 }
 
 // portAllocStateFilePath returns the path to the port allocations state file.
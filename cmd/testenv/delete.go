@@ -122,7 +122,7 @@ func cmdDelete(testID string) error {
 	return nil
 }
 
-// orchestrateDelete calls testenv-subengines in REVERSE order to tear down the test environment.
+// orchestrateDelete calls testenv-subengines in REVERSE dependency order to tear down the test environment.
 func orchestrateDelete(config forge.Spec, setupAlias string, env *forge.TestEnvironment) error {
 	// Resolve the alias to get engine configuration
 	var engineConfig *forge.EngineConfig
@@ -148,10 +148,22 @@ func orchestrateDelete(config forge.Spec, setupAlias string, env *forge.TestEnvi
 		return fmt.Errorf("no testenv-subengines configured for %s", setupAlias)
 	}
 
-	// Call each subengine in REVERSE order for cleanup
+	// Call each subengine in REVERSE dependency order for cleanup
+	// (dependents such as helm-install are torn down before the kind cluster)
+	order, err := teardownOrder(subengines)
+	if err != nil {
+		// Dependencies were already resolved at create time; fall back to reverse
+		// configuration order rather than leaking resources
+		fmt.Fprintf(os.Stderr, "Warning: %v; tearing down in reverse configuration order\n", err)
+		order = make([]int, 0, len(subengines))
+		for i := len(subengines) - 1; i >= 0; i-- {
+			order = append(order, i)
+		}
+	}
+
 	// Collect all errors - cleanup must not leak resources
 	var cleanupErrors []error
-	for i := len(subengines) - 1; i >= 0; i-- {
+	for _, i := range order {
 		subengine := subengines[i]
 		fmt.Fprintf(os.Stderr, "Tearing down %s...\n", subengine.Engine)
 
@@ -0,0 +1,151 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/cmdutil"
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// gcOptions selects the test environments removed by cmdGC.
+type gcOptions struct {
+	// OlderThan keeps environments created less than OlderThan ago (zero selects any age).
+	OlderThan time.Duration
+	// Stage keeps environments of other stages (empty selects every stage).
+	Stage string
+	// DryRun lists the selected environments without deleting them.
+	DryRun bool
+}
+
+// parseGCFlags parses the arguments of "testenv gc".
+func parseGCFlags(args []string) (gcOptions, error) {
+	var opts gcOptions
+
+	fs := flag.NewFlagSet("gc", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Func("older-than", "only delete environments created longer ago than this duration (e.g. 12h, 7d)", func(value string) error {
+		d, err := cmdutil.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		opts.OlderThan = d
+		return nil
+	})
+	fs.StringVar(&opts.Stage, "stage", "", "only delete environments of this test stage")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "list the environments that would be deleted")
+
+	if err := fs.Parse(args); err != nil {
+		return gcOptions{}, err
+	}
+	if fs.NArg() > 0 {
+		return gcOptions{}, fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+
+	return opts, nil
+}
+
+// cmdGC deletes the leftover test environments of the artifact store selected by args.
+func cmdGC(args []string) error {
+	opts, err := parseGCFlags(args)
+	if err != nil {
+		return err
+	}
+
+	// Read forge.yaml to get artifact store path
+	config, err := forge.ReadSpec()
+	if err != nil {
+		return fmt.Errorf("failed to read forge.yaml: %w", err)
+	}
+
+	// Get artifact store path from config
+	artifactStorePath, err := forge.GetArtifactStorePath(config.ArtifactStorePath)
+	if err != nil {
+		return fmt.Errorf("failed to get artifact store path: %w", err)
+	}
+
+	store, err := forge.ReadArtifactStore(artifactStorePath)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact store: %w", err)
+	}
+
+	return runGC(os.Stderr, &store, opts, time.Now(), cmdDelete)
+}
+
+// selectGCCandidates returns the environments of store matching opts, oldest first.
+func selectGCCandidates(store *forge.ArtifactStore, opts gcOptions, now time.Time) []*forge.TestEnvironment {
+	var candidates []*forge.TestEnvironment
+	for _, env := range forge.ListTestEnvironments(store, opts.Stage) {
+		if opts.OlderThan > 0 && now.Sub(env.CreatedAt) < opts.OlderThan {
+			continue
+		}
+		candidates = append(candidates, env)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].CreatedAt.Equal(candidates[j].CreatedAt) {
+			return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	return candidates
+}
+
+// runGC deletes the environments of store selected by opts with deleteEnv, which tears down
+// the subengines of an environment and removes it from the artifact store.
+// A failed deletion does not stop the sweep; every failure is returned at the end.
+func runGC(w io.Writer, store *forge.ArtifactStore, opts gcOptions, now time.Time, deleteEnv func(testID string) error) error {
+	candidates := selectGCCandidates(store, opts, now)
+	if len(candidates) == 0 {
+		_, _ = fmt.Fprintln(w, "No test environments to delete")
+		return nil
+	}
+
+	var errs []error
+	deleted := 0
+	for _, env := range candidates {
+		age := now.Sub(env.CreatedAt).Round(time.Second)
+		if opts.DryRun {
+			_, _ = fmt.Fprintf(w, "Would delete %s (stage %s, age %s)\n", env.ID, env.Name, age)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "Deleting %s (stage %s, age %s)\n", env.ID, env.Name, age)
+		if err := deleteEnv(env.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env.ID, err))
+			continue
+		}
+		deleted++
+	}
+
+	if opts.DryRun {
+		_, _ = fmt.Fprintf(w, "%d test environment(s) would be deleted\n", len(candidates))
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(w, "Deleted %d of %d test environment(s)\n", deleted, len(candidates))
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete %d test environment(s): %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
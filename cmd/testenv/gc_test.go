@@ -0,0 +1,219 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// gcStore returns a synthetic artifact store with environments of several stages and ages.
+func gcStore(now time.Time) *forge.ArtifactStore {
+	env := func(id, stage string, age time.Duration) *forge.TestEnvironment {
+		return &forge.TestEnvironment{
+			ID:        id,
+			Name:      stage,
+			Status:    forge.TestStatusCreated,
+			CreatedAt: now.Add(-age),
+			UpdatedAt: now.Add(-age),
+		}
+	}
+
+	return &forge.ArtifactStore{
+		Version: "1.0",
+		TestEnvironments: map[string]*forge.TestEnvironment{
+			"test-e2e-old":          env("test-e2e-old", "e2e", 48*time.Hour),
+			"test-e2e-new":          env("test-e2e-new", "e2e", 10*time.Minute),
+			"test-integration-old":  env("test-integration-old", "integration", 72*time.Hour),
+			"test-integration-mid":  env("test-integration-mid", "integration", 25*time.Hour),
+			"test-integration-new":  env("test-integration-new", "integration", time.Minute),
+			"test-integration-zero": env("test-integration-zero", "integration", 0),
+		},
+	}
+}
+
+// recordingDelete returns a delete function recording the IDs it is called with and
+// failing for the IDs in fail.
+func recordingDelete(deleted *[]string, fail ...string) func(string) error {
+	return func(testID string) error {
+		*deleted = append(*deleted, testID)
+		for _, id := range fail {
+			if id == testID {
+				return errors.New("cluster still in use")
+			}
+		}
+		return nil
+	}
+}
+
+func TestSelectGCCandidates(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	store := gcStore(now)
+
+	tests := []struct {
+		name string
+		opts gcOptions
+		want []string
+	}{
+		{
+			name: "all environments, oldest first",
+			want: []string{"test-integration-old", "test-e2e-old", "test-integration-mid", "test-e2e-new", "test-integration-new", "test-integration-zero"},
+		},
+		{
+			name: "older than",
+			opts: gcOptions{OlderThan: 24 * time.Hour},
+			want: []string{"test-integration-old", "test-e2e-old", "test-integration-mid"},
+		},
+		{
+			name: "stage",
+			opts: gcOptions{Stage: "e2e"},
+			want: []string{"test-e2e-old", "test-e2e-new"},
+		},
+		{
+			name: "older than and stage",
+			opts: gcOptions{OlderThan: 48 * time.Hour, Stage: "integration"},
+			want: []string{"test-integration-old"},
+		},
+		{
+			name: "unknown stage",
+			opts: gcOptions{Stage: "unit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, env := range selectGCCandidates(store, tt.opts, now) {
+				got = append(got, env.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectGCCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunGC_DeletesSelectedEnvironments(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	var deleted []string
+	var out bytes.Buffer
+	err := runGC(&out, gcStore(now), gcOptions{OlderThan: 24 * time.Hour}, now, recordingDelete(&deleted))
+	if err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+
+	want := []string{"test-integration-old", "test-e2e-old", "test-integration-mid"}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted %v, want %v", deleted, want)
+	}
+	if !strings.Contains(out.String(), "Deleted 3 of 3 test environment(s)") {
+		t.Errorf("output = %q, want a summary", out.String())
+	}
+}
+
+func TestRunGC_DryRunDeletesNothing(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	var deleted []string
+	var out bytes.Buffer
+	err := runGC(&out, gcStore(now), gcOptions{Stage: "e2e", DryRun: true}, now, recordingDelete(&deleted))
+	if err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+
+	if len(deleted) != 0 {
+		t.Errorf("dry run deleted %v", deleted)
+	}
+	for _, want := range []string{"Would delete test-e2e-old (stage e2e, age 48h0m0s)", "Would delete test-e2e-new", "2 test environment(s) would be deleted"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunGC_ContinuesAfterFailure(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	var deleted []string
+	var out bytes.Buffer
+	err := runGC(&out, gcStore(now), gcOptions{Stage: "integration"}, now, recordingDelete(&deleted, "test-integration-old"))
+	if err == nil {
+		t.Fatal("runGC() error = nil, want the failed deletion")
+	}
+	if !strings.Contains(err.Error(), "test-integration-old: cluster still in use") {
+		t.Errorf("error = %v, want the failed environment", err)
+	}
+
+	// Every selected environment was attempted
+	if len(deleted) != 4 {
+		t.Errorf("attempted %v, want all 4 integration environments", deleted)
+	}
+	if !strings.Contains(out.String(), "Deleted 3 of 4 test environment(s)") {
+		t.Errorf("output = %q, want a summary with the failure", out.String())
+	}
+}
+
+func TestRunGC_NothingToDelete(t *testing.T) {
+	var deleted []string
+	var out bytes.Buffer
+	if err := runGC(&out, &forge.ArtifactStore{}, gcOptions{}, time.Now(), recordingDelete(&deleted)); err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+	if len(deleted) != 0 || !strings.Contains(out.String(), "No test environments to delete") {
+		t.Errorf("deleted %v, output %q", deleted, out.String())
+	}
+}
+
+func TestParseGCFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    gcOptions
+		wantErr bool
+	}{
+		{name: "no flags"},
+		{
+			name: "all flags",
+			args: []string{"--older-than=24h", "--stage", "e2e", "--dry-run"},
+			want: gcOptions{OlderThan: 24 * time.Hour, Stage: "e2e", DryRun: true},
+		},
+		{name: "days", args: []string{"--older-than=7d"}, want: gcOptions{OlderThan: 7 * 24 * time.Hour}},
+		{name: "invalid duration", args: []string{"--older-than=yesterday"}, wantErr: true},
+		{name: "negative duration", args: []string{"--older-than=-1h"}, wantErr: true},
+		{name: "unknown flag", args: []string{"--force"}, wantErr: true},
+		{name: "positional argument", args: []string{"e2e"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGCFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGCFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseGCFlags(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
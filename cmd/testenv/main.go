@@ -15,8 +15,10 @@
 package main
 
 import (
+	"embed"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
@@ -33,12 +35,18 @@ var (
 // versionInfo holds testenv's version information
 var versionInfo *engineversion.Info
 
+// embeddedDocs bundles the docs directory, served when remote docs cannot be fetched.
+//
+//go:embed docs
+var embeddedDocs embed.FS
+
 // docsConfig is the configuration for the docs subcommand.
 var docsConfig = &enginedocs.Config{
 	EngineName:   "testenv",
 	LocalDir:     "cmd/testenv/docs",
 	BaseURL:      "https://raw.githubusercontent.com/alexandremahdhaoui/forge/refs/heads/main",
 	RequiredDocs: []string{"usage", "schema"},
+	EmbeddedFS:   embeddedDocs,
 }
 
 func init() {
@@ -65,7 +73,11 @@ func main() {
 			if len(os.Args) >= 3 {
 				stageName = os.Args[2]
 			}
-			if _, err := cmdCreate(stageName); err != nil {
+			testID := ""
+			if len(os.Args) >= 4 {
+				testID = os.Args[3]
+			}
+			if _, err := cmdCreate(stageName, testID); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -80,6 +92,21 @@ func main() {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
+		case "gc":
+			if err := cmdGC(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		case "status":
+			if len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "-") {
+				fmt.Fprintf(os.Stderr, "Error: test ID required\n\n")
+				printUsage()
+				os.Exit(1)
+			}
+			if err := cmdStatus(os.Args[2], outputFlag(os.Args[3:])); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
 			printUsage()
@@ -103,8 +130,15 @@ func printUsage() {
 	fmt.Println(`testenv - Orchestrate test environments
 
 Usage:
-  testenv create <STAGE>        Create a test environment
+  testenv create <STAGE> [TEST-ID]
+                                Create a test environment, or resume a
+                                partially created one
   testenv delete <TEST-ID>      Delete a test environment
+  testenv status <TEST-ID> [-o json]
+                                Show subengine health of a test environment
+  testenv gc [--older-than=<DURATION>] [--stage=<STAGE>] [--dry-run]
+                                Delete leftover test environments of all
+                                stages, or only the selected ones
   testenv --mcp                 Run as MCP server
   testenv version               Show version information
 
@@ -114,10 +148,28 @@ Arguments:
 
 Examples:
   testenv create integration
+  testenv create integration test-integration-20241103-abc123
   testenv delete test-integration-20241103-abc123
+  testenv status test-integration-20241103-abc123 -o json
+  testenv gc --older-than=24h --stage=e2e --dry-run
   testenv --mcp
 
 Note:
   Use 'forge test <stage> get/list' to view test environments.
-  testenv only handles create/delete operations.`)
+  testenv only handles create/delete/status/gc operations.`)
+}
+
+// outputFlag returns the value of the -o/--output flag in args, or an empty string.
+func outputFlag(args []string) string {
+	for i, arg := range args {
+		for _, name := range []string{"-o", "--output"} {
+			if value, ok := strings.CutPrefix(arg, name+"="); ok {
+				return value
+			}
+			if arg == name && i+1 < len(args) {
+				return args[i+1]
+			}
+		}
+	}
+	return ""
 }
@@ -114,7 +114,7 @@ test:
 	os.Chdir(tmpDir)
 
 	// Run cmdCreate - will fail if go://test-report isn't available
-	testID, err := cmdCreate("integration")
+	testID, err := cmdCreate("integration", "")
 	if err != nil {
 		t.Fatalf("cmdCreate failed: %v", err)
 	}
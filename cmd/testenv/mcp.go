@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
@@ -28,6 +29,8 @@ import (
 // CreateInput represents the input for the create tool.
 type CreateInput struct {
 	Stage string `json:"stage"`
+	// TestID resumes the partially created test environment with this ID (optional)
+	TestID string `json:"testID,omitempty"`
 }
 
 // DeleteInput represents the input for the delete tool.
@@ -42,7 +45,7 @@ func runMCPServer() error {
 	// Register create tool
 	mcpserver.RegisterTool(server, &mcp.Tool{
 		Name:        "create",
-		Description: "Create a test environment for a given stage",
+		Description: "Create a test environment for a given stage, or resume a partially created one by testID",
 	}, handleCreateTool)
 
 	// Register delete tool
@@ -66,7 +69,7 @@ func runMCPServer() error {
 	}
 
 	// Run the MCP server
-	return server.RunDefault()
+	return server.Run(enginecli.Context())
 }
 
 // handleCreateTool handles the "create" tool call from MCP clients.
@@ -75,7 +78,7 @@ func handleCreateTool(
 	req *mcp.CallToolRequest,
 	input CreateInput,
 ) (*mcp.CallToolResult, any, error) {
-	log.Printf("Creating test environment: stage=%s", input.Stage)
+	log.Printf("Creating test environment: stage=%s testID=%s", input.Stage, input.TestID)
 
 	// Validate inputs
 	if result := mcputil.ValidateRequiredWithPrefix("Create failed", map[string]string{
@@ -85,7 +88,7 @@ func handleCreateTool(
 	}
 
 	// Call cmdCreate to do the actual work (including orchestration)
-	testID, err := cmdCreate(input.Stage)
+	testID, err := cmdCreate(input.Stage, input.TestID)
 	if err != nil {
 		return mcputil.ErrorResult(fmt.Sprintf("Create failed: %v", err)), nil, nil
 	}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/alexandremahdhaoui/forge/internal/forgepath"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -31,7 +32,7 @@ import (
 // The command and args parameters specify how to execute the MCP server:
 //   - For go run: command="go", args=["run", "package/path"]
 //   - For binary: command="binary-path", args=nil
-func callMCPEngine(command string, args []string, toolName string, params interface{}) (interface{}, error) {
+func callMCPEngine(command string, args []string, toolName string, params interface{}) (_ interface{}, err error) {
 	// Create command to spawn MCP server
 	// Append --mcp flag to the args
 	cmdArgs := append(args, "--mcp")
@@ -67,6 +68,10 @@ func callMCPEngine(command string, args []string, toolName string, params interf
 	// The MCP server itself will handle timeouts for operations
 	ctx := context.Background()
 
+	// Trace the call so that the subengine's spans join the trace
+	ctx, span := tracing.StartCall(ctx, command, args, toolName)
+	defer func() { tracing.End(span, err) }()
+
 	session, err := client.Connect(ctx, transport, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MCP server %s %v: %w", command, args, err)
@@ -87,10 +92,11 @@ func callMCPEngine(command string, args []string, toolName string, params interf
 	// Call the tool with a timeout context
 	// Use 15 minutes to allow for testenv-lcr's full setup time  (~7-12 minutes depending on system load)
 	// including cert-manager deployment, pod readiness, image pushes, and image pull secret creation
-	toolCtx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	toolCtx, cancel := context.WithTimeout(ctx, 15*time.Minute)
 	defer cancel()
 
 	result, err := session.CallTool(toolCtx, &mcp.CallToolParams{
+		Meta:      tracing.InjectMeta(ctx),
 		Name:      toolName,
 		Arguments: arguments,
 	})
@@ -0,0 +1,123 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// subengineRecordPrefix prefixes the metadata keys recording the subengines
+// whose create completed, e.g. "testenv.subengine.0".
+const subengineRecordPrefix = "testenv.subengine."
+
+// subengineRecord records a completed subengine create so that an interrupted
+// create can be resumed without calling the subengine again.
+type subengineRecord struct {
+	Engine string `json:"engine"`
+	// PortEnv holds the environment variables of the ports allocated for the subengine
+	PortEnv map[string]string `json:"portEnv,omitempty"`
+	// Result is the subengine's create response
+	Result any `json:"result,omitempty"`
+}
+
+// recordSubengine stores record in env.Metadata under the subengine's index.
+func recordSubengine(env *forge.TestEnvironment, subengineIndex int, record subengineRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", record.Engine, err)
+	}
+	env.Metadata[subengineRecordPrefix+strconv.Itoa(subengineIndex)] = string(data)
+	return nil
+}
+
+// hasSubengineRecords reports whether any subengine is recorded as completed in metadata.
+func hasSubengineRecords(metadata map[string]string) bool {
+	for key := range metadata {
+		if strings.HasPrefix(key, subengineRecordPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// completedSubengines returns the records found in metadata, keyed by subengine index.
+// A record must match the subengine configured at its index: resuming after the
+// configuration changed could otherwise skip or duplicate resources.
+func completedSubengines(metadata map[string]string, subengines []forge.TestenvEngineSpec) (map[int]subengineRecord, error) {
+	completed := make(map[int]subengineRecord)
+	for key, value := range metadata {
+		indexStr, ok := strings.CutPrefix(key, subengineRecordPrefix)
+		if !ok {
+			continue
+		}
+
+		index, err := strconv.Atoi(indexStr)
+		if err != nil || index < 0 || index >= len(subengines) {
+			return nil, fmt.Errorf("invalid subengine record %s: configuration has %d subengines", key, len(subengines))
+		}
+
+		var record subengineRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			return nil, fmt.Errorf("invalid subengine record %s: %w", key, err)
+		}
+		if record.Engine != subengines[index].Engine {
+			return nil, fmt.Errorf("subengine %d was created with %s but is now configured as %s", index, record.Engine, subengines[index].Engine)
+		}
+
+		completed[index] = record
+	}
+	return completed, nil
+}
+
+// resumeEnvironment returns the environment to continue creating from existing.
+// Files, metadata and managed resources are reset: they are rebuilt from the
+// subengine records while resuming. It returns nil if existing is already created.
+func resumeEnvironment(existing *forge.TestEnvironment, stageName string) (*forge.TestEnvironment, error) {
+	if existing.Name != stageName {
+		return nil, fmt.Errorf("test environment %s belongs to stage %s, not %s", existing.ID, existing.Name, stageName)
+	}
+
+	switch existing.Status {
+	case forge.TestStatusPartiallyCreated:
+	case forge.TestStatusPartiallyDeleted:
+		return nil, fmt.Errorf("test environment %s is partially deleted and cannot be resumed", existing.ID)
+	default:
+		return nil, nil
+	}
+
+	env := &forge.TestEnvironment{
+		ID:               existing.ID,
+		Name:             existing.Name,
+		Status:           forge.TestStatusPartiallyCreated,
+		CreatedAt:        existing.CreatedAt,
+		UpdatedAt:        time.Now().UTC(),
+		TmpDir:           existing.TmpDir,
+		Files:            make(map[string]string),
+		ManagedResources: []string{existing.TmpDir},
+		Metadata:         make(map[string]string),
+	}
+	for key, value := range existing.Metadata {
+		if strings.HasPrefix(key, subengineRecordPrefix) {
+			env.Metadata[key] = value
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,377 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// fakeCreateEngines records create and delete calls and answers create calls with a
+// response derived from the engine name. Engines listed in failing fail to create,
+// engines listed in failingDelete fail to delete.
+type fakeCreateEngines struct {
+	mu            sync.Mutex
+	calls         []string
+	deletes       []string
+	failing       map[string]bool
+	failingDelete map[string]bool
+}
+
+func (f *fakeCreateEngines) orchestrator(checkpoints *[]forge.TestEnvironment) createOrchestrator {
+	return createOrchestrator{
+		resolveEngine: func(engineURI string) (string, []string, error) {
+			return engineURI, nil, nil
+		},
+		callEngine: func(command string, args []string, toolName string, params interface{}) (interface{}, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if toolName == "delete" {
+				f.deletes = append(f.deletes, command)
+				if f.failingDelete[command] {
+					return nil, errors.New("delete boom")
+				}
+				return nil, nil
+			}
+			f.calls = append(f.calls, command)
+			if f.failing[command] {
+				return nil, errors.New("boom")
+			}
+			name := strings.TrimPrefix(command, "go://")
+			return map[string]interface{}{
+				"files":            map[string]interface{}{name + ".file": name + "/out"},
+				"metadata":         map[string]interface{}{name + ".ready": "true"},
+				"managedResources": []interface{}{"/resources/" + name},
+				"env":              map[string]interface{}{strings.ToUpper(strings.ReplaceAll(name, "-", "_")): "set"},
+			}, nil
+		},
+		checkpoint: func(env *forge.TestEnvironment) error {
+			if checkpoints != nil {
+				snapshot := *env
+				snapshot.Metadata = make(map[string]string, len(env.Metadata))
+				for k, v := range env.Metadata {
+					snapshot.Metadata[k] = v
+				}
+				*checkpoints = append(*checkpoints, snapshot)
+			}
+			return nil
+		},
+	}
+}
+
+func resumeTestConfig(subengines ...forge.TestenvEngineSpec) forge.Spec {
+	return forge.Spec{
+		Engines: []forge.EngineConfig{{
+			Alias:   "setup",
+			Type:    "testenv",
+			Testenv: subengines,
+		}},
+	}
+}
+
+func newResumeTestEnv(t *testing.T) *forge.TestEnvironment {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	return &forge.TestEnvironment{
+		ID:               "test-e2e-20250101-abcd1234",
+		Name:             "e2e",
+		Status:           forge.TestStatusCreated,
+		CreatedAt:        time.Now().UTC(),
+		TmpDir:           tmpDir,
+		Files:            make(map[string]string),
+		ManagedResources: []string{tmpDir},
+		Metadata:         make(map[string]string),
+	}
+}
+
+func TestCreateOrchestrator_ResumesPartiallyCreatedEnvironment(t *testing.T) {
+	config := resumeTestConfig(
+		forge.TestenvEngineSpec{Engine: "go://testenv-kind"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-lcr"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-helm-install"},
+	)
+	env := newResumeTestEnv(t)
+
+	// First attempt: the second subengine fails
+	first := &fakeCreateEngines{failing: map[string]bool{"go://testenv-lcr": true}}
+	var checkpoints []forge.TestEnvironment
+	orchestrator := first.orchestrator(&checkpoints)
+	orchestrator.keepOnFailure = true
+	err := orchestrator.run(config, "setup", env)
+	if err == nil || !strings.Contains(err.Error(), "go://testenv-lcr") {
+		t.Fatalf("run() error = %v, want failure of go://testenv-lcr", err)
+	}
+	if want := []string{"go://testenv-kind", "go://testenv-lcr"}; !reflect.DeepEqual(first.calls, want) {
+		t.Errorf("first attempt calls = %v, want %v", first.calls, want)
+	}
+	if len(first.deletes) != 0 {
+		t.Errorf("first attempt deletes = %v, want none with keepOnFailure", first.deletes)
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("got %d checkpoints, want 2", len(checkpoints))
+	}
+	if _, ok := checkpoints[1].Metadata[subengineRecordPrefix+"0"]; !ok {
+		t.Errorf("last checkpoint does not record go://testenv-kind: %v", checkpoints[1].Metadata)
+	}
+	if _, ok := checkpoints[1].Metadata[subengineRecordPrefix+"1"]; ok {
+		t.Error("last checkpoint records the failed go://testenv-lcr")
+	}
+
+	// The stored environment is resumed
+	stored := env
+	stored.Status = forge.TestStatusPartiallyCreated
+	resumed, err := resumeEnvironment(stored, "e2e")
+	if err != nil {
+		t.Fatalf("resumeEnvironment() error = %v", err)
+	}
+
+	second := &fakeCreateEngines{}
+	if err := second.orchestrator(nil).run(config, "setup", resumed); err != nil {
+		t.Fatalf("resumed run() error = %v", err)
+	}
+	if want := []string{"go://testenv-lcr", "go://testenv-helm-install"}; !reflect.DeepEqual(second.calls, want) {
+		t.Errorf("resumed calls = %v, want %v", second.calls, want)
+	}
+
+	// The result of the completed subengine is replayed
+	wantFiles := map[string]string{
+		"testenv-kind.file":         "testenv-kind/out",
+		"testenv-lcr.file":          "testenv-lcr/out",
+		"testenv-helm-install.file": "testenv-helm-install/out",
+	}
+	if !reflect.DeepEqual(resumed.Files, wantFiles) {
+		t.Errorf("Files = %v, want %v", resumed.Files, wantFiles)
+	}
+	wantResources := []string{env.TmpDir, "/resources/testenv-kind", "/resources/testenv-lcr", "/resources/testenv-helm-install"}
+	if !reflect.DeepEqual(resumed.ManagedResources, wantResources) {
+		t.Errorf("ManagedResources = %v, want %v", resumed.ManagedResources, wantResources)
+	}
+	if resumed.Env["TESTENV_KIND"] != "set" || resumed.Env["TESTENV_HELM_INSTALL"] != "set" {
+		t.Errorf("Env = %v, want variables of all subengines", resumed.Env)
+	}
+	if resumed.Metadata["testenv-kind.ready"] != "true" {
+		t.Errorf("Metadata = %v, want testenv-kind metadata", resumed.Metadata)
+	}
+
+	// Resuming again calls no subengine
+	third := &fakeCreateEngines{}
+	again, err := resumeEnvironment(&forge.TestEnvironment{
+		ID:       resumed.ID,
+		Name:     resumed.Name,
+		Status:   forge.TestStatusPartiallyCreated,
+		TmpDir:   resumed.TmpDir,
+		Metadata: resumed.Metadata,
+	}, "e2e")
+	if err != nil {
+		t.Fatalf("resumeEnvironment() error = %v", err)
+	}
+	if err := third.orchestrator(nil).run(config, "setup", again); err != nil {
+		t.Fatalf("second resumed run() error = %v", err)
+	}
+	if len(third.calls) != 0 {
+		t.Errorf("second resume calls = %v, want none", third.calls)
+	}
+	if !reflect.DeepEqual(again.Files, wantFiles) {
+		t.Errorf("Files = %v, want %v", again.Files, wantFiles)
+	}
+}
+
+func TestCreateOrchestrator_RecordsSucceededSubenginesOfFailedLayer(t *testing.T) {
+	config := resumeTestConfig(
+		forge.TestenvEngineSpec{Engine: "go://testenv-kind"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-postgres"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-lcr", DependsOn: []string{"go://testenv-kind"}},
+	)
+	env := newResumeTestEnv(t)
+
+	engines := &fakeCreateEngines{failing: map[string]bool{"go://testenv-postgres": true}}
+	orchestrator := engines.orchestrator(nil)
+	orchestrator.keepOnFailure = true
+	if err := orchestrator.run(config, "setup", env); err == nil {
+		t.Fatal("run() succeeded, want failure of go://testenv-postgres")
+	}
+
+	completed, err := completedSubengines(env.Metadata, config.Engines[0].Testenv)
+	if err != nil {
+		t.Fatalf("completedSubengines() error = %v", err)
+	}
+	if _, ok := completed[0]; !ok || len(completed) != 1 {
+		t.Errorf("completed = %v, want only go://testenv-kind", completed)
+	}
+}
+
+func TestCreateOrchestrator_RejectsChangedConfiguration(t *testing.T) {
+	env := newResumeTestEnv(t)
+	if err := recordSubengine(env, 0, subengineRecord{Engine: "go://testenv-kind"}); err != nil {
+		t.Fatalf("recordSubengine() error = %v", err)
+	}
+
+	config := resumeTestConfig(forge.TestenvEngineSpec{Engine: "go://testenv-lcr"})
+	engines := &fakeCreateEngines{}
+	err := engines.orchestrator(nil).run(config, "setup", env)
+	if err == nil || !strings.Contains(err.Error(), "now configured as go://testenv-lcr") {
+		t.Fatalf("run() error = %v, want configuration mismatch", err)
+	}
+	if len(engines.calls) != 0 || len(engines.deletes) != 0 {
+		t.Errorf("calls = %v, deletes = %v, want none", engines.calls, engines.deletes)
+	}
+}
+
+func TestCreateOrchestrator_RollsBackCreatedSubenginesOnFailure(t *testing.T) {
+	config := resumeTestConfig(
+		forge.TestenvEngineSpec{Engine: "go://testenv-kind"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-lcr"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-helm-install"},
+	)
+	env := newResumeTestEnv(t)
+
+	engines := &fakeCreateEngines{failing: map[string]bool{"go://testenv-helm-install": true}}
+	var checkpoints []forge.TestEnvironment
+	err := engines.orchestrator(&checkpoints).run(config, "setup", env)
+	if err == nil || !strings.Contains(err.Error(), "go://testenv-helm-install") {
+		t.Fatalf("run() error = %v, want failure of go://testenv-helm-install", err)
+	}
+	if strings.Contains(err.Error(), "rollback errors") {
+		t.Errorf("run() error = %v, want no rollback errors", err)
+	}
+
+	// Completed subengines are deleted in reverse order, the failed one is not
+	if want := []string{"go://testenv-lcr", "go://testenv-kind"}; !reflect.DeepEqual(engines.deletes, want) {
+		t.Errorf("deletes = %v, want %v", engines.deletes, want)
+	}
+	if hasSubengineRecords(env.Metadata) {
+		t.Errorf("Metadata = %v, want no subengine records after rollback", env.Metadata)
+	}
+	if len(checkpoints) == 0 || hasSubengineRecords(checkpoints[len(checkpoints)-1].Metadata) {
+		t.Errorf("rollback was not recorded: checkpoints = %v", checkpoints)
+	}
+}
+
+func TestCreateOrchestrator_RollbackRespectsDependencies(t *testing.T) {
+	config := resumeTestConfig(
+		forge.TestenvEngineSpec{Engine: "go://testenv-kind"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-lcr", DependsOn: []string{"go://testenv-kind"}},
+		forge.TestenvEngineSpec{Engine: "go://testenv-postgres"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-helm-install", DependsOn: []string{"go://testenv-lcr"}},
+	)
+	env := newResumeTestEnv(t)
+
+	// go://testenv-kind and go://testenv-postgres succeed in the first layer,
+	// go://testenv-lcr fails in the second one
+	engines := &fakeCreateEngines{failing: map[string]bool{"go://testenv-lcr": true}}
+	if err := engines.orchestrator(nil).run(config, "setup", env); err == nil {
+		t.Fatal("run() succeeded, want failure of go://testenv-lcr")
+	}
+	if want := []string{"go://testenv-postgres", "go://testenv-kind"}; !reflect.DeepEqual(engines.deletes, want) {
+		t.Errorf("deletes = %v, want %v", engines.deletes, want)
+	}
+}
+
+func TestCreateOrchestrator_RollbackReportsFailedCleanups(t *testing.T) {
+	config := resumeTestConfig(
+		forge.TestenvEngineSpec{Engine: "go://testenv-kind"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-lcr"},
+		forge.TestenvEngineSpec{Engine: "go://testenv-helm-install"},
+	)
+	env := newResumeTestEnv(t)
+
+	engines := &fakeCreateEngines{
+		failing:       map[string]bool{"go://testenv-helm-install": true},
+		failingDelete: map[string]bool{"go://testenv-lcr": true},
+	}
+	err := engines.orchestrator(nil).run(config, "setup", env)
+	if err == nil {
+		t.Fatal("run() succeeded, want failure of go://testenv-helm-install")
+	}
+	for _, want := range []string{"failed to create with go://testenv-helm-install", "failed to delete with go://testenv-lcr"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("run() error = %v, want it to contain %q", err, want)
+		}
+	}
+
+	// The rollback continues after the failed cleanup
+	if want := []string{"go://testenv-lcr", "go://testenv-kind"}; !reflect.DeepEqual(engines.deletes, want) {
+		t.Errorf("deletes = %v, want %v", engines.deletes, want)
+	}
+
+	// Only the subengine left behind is still recorded
+	completed, err := completedSubengines(env.Metadata, config.Engines[0].Testenv)
+	if err != nil {
+		t.Fatalf("completedSubengines() error = %v", err)
+	}
+	if _, ok := completed[1]; !ok || len(completed) != 1 {
+		t.Errorf("completed = %v, want only go://testenv-lcr", completed)
+	}
+}
+
+func TestResumeEnvironment(t *testing.T) {
+	existing := &forge.TestEnvironment{
+		ID:               "test-e2e-20250101-abcd1234",
+		Name:             "e2e",
+		Status:           forge.TestStatusPartiallyCreated,
+		TmpDir:           "/tmp/test-e2e",
+		Files:            map[string]string{"testenv-kind.kubeconfig": "kubeconfig"},
+		ManagedResources: []string{"/tmp/test-e2e", "/tmp/test-e2e/kubeconfig"},
+		Metadata: map[string]string{
+			"testenv-kind.clusterName":  "forge-test-e2e",
+			subengineRecordPrefix + "0": `{"engine":"go://testenv-kind"}`,
+		},
+	}
+
+	env, err := resumeEnvironment(existing, "e2e")
+	if err != nil {
+		t.Fatalf("resumeEnvironment() error = %v", err)
+	}
+	if len(env.Files) != 0 || !reflect.DeepEqual(env.ManagedResources, []string{"/tmp/test-e2e"}) {
+		t.Errorf("resumed environment keeps results: files=%v resources=%v", env.Files, env.ManagedResources)
+	}
+	if want := map[string]string{subengineRecordPrefix + "0": `{"engine":"go://testenv-kind"}`}; !reflect.DeepEqual(env.Metadata, want) {
+		t.Errorf("Metadata = %v, want %v", env.Metadata, want)
+	}
+
+	if _, err := resumeEnvironment(existing, "integration"); err == nil {
+		t.Error("resumeEnvironment() with another stage succeeded, want error")
+	}
+
+	existing.Status = forge.TestStatusPartiallyDeleted
+	if _, err := resumeEnvironment(existing, "e2e"); err == nil {
+		t.Error("resumeEnvironment() of a partially deleted environment succeeded, want error")
+	}
+
+	existing.Status = forge.TestStatusCreated
+	env, err = resumeEnvironment(existing, "e2e")
+	if err != nil || env != nil {
+		t.Errorf("resumeEnvironment() of a created environment = %v, %v, want nil, nil", env, err)
+	}
+}
+
+func TestSubengineNames_SkipsCreateRecords(t *testing.T) {
+	metadata := map[string]string{
+		"testenv-kind.clusterName":  "forge-test-e2e",
+		subengineRecordPrefix + "0": `{"engine":"go://testenv-kind"}`,
+	}
+	if got := subengineNames(metadata); !reflect.DeepEqual(got, []string{"testenv-kind"}) {
+		t.Errorf("subengineNames() = %v, want [testenv-kind]", got)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// implicitDependencies lists dependencies that always apply in dependency mode,
+// whether or not they are declared. They preserve the cleanup invariant that
+// anything deployed into the kind cluster is torn down before the cluster is deleted.
+var implicitDependencies = map[string][]string{
+	"go://testenv-helm-install": {"go://testenv-kind"},
+	"go://testenv-lcr":          {"go://testenv-kind"},
+}
+
+// usesDependencies reports whether any subengine declares dependsOn.
+// Without declarations, subengines keep their sequential, in-order behavior.
+func usesDependencies(subengines []forge.TestenvEngineSpec) bool {
+	for _, subengine := range subengines {
+		if len(subengine.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// subengineDependencies returns, for each subengine index, the indices it depends on.
+// In sequential mode every subengine depends on the one before it.
+func subengineDependencies(subengines []forge.TestenvEngineSpec) ([][]int, error) {
+	deps := make([][]int, len(subengines))
+
+	if !usesDependencies(subengines) {
+		for i := 1; i < len(subengines); i++ {
+			deps[i] = []int{i - 1}
+		}
+		return deps, nil
+	}
+
+	indicesByEngine := make(map[string][]int, len(subengines))
+	for i, subengine := range subengines {
+		indicesByEngine[subengine.Engine] = append(indicesByEngine[subengine.Engine], i)
+	}
+
+	for i, subengine := range subengines {
+		seen := make(map[int]bool)
+		for _, dep := range subengine.DependsOn {
+			indices, ok := indicesByEngine[dep]
+			if !ok {
+				return nil, fmt.Errorf("subengine %s depends on unknown subengine %s", subengine.Engine, dep)
+			}
+			for _, j := range indices {
+				if j == i {
+					return nil, fmt.Errorf("subengine %s cannot depend on itself", subengine.Engine)
+				}
+				if !seen[j] {
+					seen[j] = true
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+
+		// Implicit dependencies only apply when the dependency is configured
+		for _, dep := range implicitDependencies[subengine.Engine] {
+			for _, j := range indicesByEngine[dep] {
+				if j != i && !seen[j] {
+					seen[j] = true
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// creationLayers groups subengine indices into layers. Every subengine in a layer
+// only depends on subengines from earlier layers, so a layer can be created concurrently.
+// Indices within a layer keep their configuration order.
+func creationLayers(subengines []forge.TestenvEngineSpec) ([][]int, error) {
+	deps, err := subengineDependencies(subengines)
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(subengines))
+	level := make([]int, len(subengines))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected involving subengine %s", subengines[i].Engine)
+		}
+		state[i] = visiting
+		for _, j := range deps[i] {
+			if err := visit(j); err != nil {
+				return err
+			}
+			if level[j]+1 > level[i] {
+				level[i] = level[j] + 1
+			}
+		}
+		state[i] = done
+		return nil
+	}
+
+	maxLevel := 0
+	for i := range subengines {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+		if level[i] > maxLevel {
+			maxLevel = level[i]
+		}
+	}
+
+	if len(subengines) == 0 {
+		return nil, nil
+	}
+
+	layers := make([][]int, maxLevel+1)
+	for i := range subengines {
+		layers[level[i]] = append(layers[level[i]], i)
+	}
+	return layers, nil
+}
+
+// teardownOrder returns subengine indices in strict reverse dependency order:
+// every subengine is deleted before any subengine it depends on.
+func teardownOrder(subengines []forge.TestenvEngineSpec) ([]int, error) {
+	layers, err := creationLayers(subengines)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, 0, len(subengines))
+	for l := len(layers) - 1; l >= 0; l-- {
+		for k := len(layers[l]) - 1; k >= 0; k-- {
+			order = append(order, layers[l][k])
+		}
+	}
+	return order, nil
+}
+
+// runLayer calls create concurrently for every subengine index in layer and waits
+// for all of them. Results and errors are returned in layer order.
+func runLayer(layer []int, create func(index int) (any, error)) ([]any, []error) {
+	results := make([]any, len(layer))
+	errs := make([]error, len(layer))
+
+	if len(layer) == 1 {
+		results[0], errs[0] = create(layer[0])
+		return results, errs
+	}
+
+	var wg sync.WaitGroup
+	for k, index := range layer {
+		wg.Add(1)
+		go func(k, index int) {
+			defer wg.Done()
+			results[k], errs[k] = create(index)
+		}(k, index)
+	}
+	wg.Wait()
+
+	return results, errs
+}
@@ -0,0 +1,221 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// fakeSubengines returns a subengine set with a kind cluster, two independent
+// subengines (lcr, a database) and a helm-install that depends on the cluster.
+func fakeSubengines() []forge.TestenvEngineSpec {
+	return []forge.TestenvEngineSpec{
+		{Engine: "go://testenv-kind"},
+		{Engine: "go://testenv-postgres"},
+		{Engine: "go://testenv-lcr", DependsOn: []string{"go://testenv-kind"}},
+		{Engine: "go://testenv-helm-install"},
+	}
+}
+
+func TestCreationLayers_SequentialWithoutDependsOn(t *testing.T) {
+	subengines := []forge.TestenvEngineSpec{
+		{Engine: "go://testenv-kind"},
+		{Engine: "go://testenv-lcr"},
+		{Engine: "go://testenv-helm-install"},
+	}
+
+	layers, err := creationLayers(subengines)
+	if err != nil {
+		t.Fatalf("creationLayers() error = %v", err)
+	}
+	want := [][]int{{0}, {1}, {2}}
+	if !reflect.DeepEqual(layers, want) {
+		t.Errorf("creationLayers() = %v, want %v", layers, want)
+	}
+
+	order, err := teardownOrder(subengines)
+	if err != nil {
+		t.Fatalf("teardownOrder() error = %v", err)
+	}
+	if !reflect.DeepEqual(order, []int{2, 1, 0}) {
+		t.Errorf("teardownOrder() = %v, want reverse configuration order", order)
+	}
+}
+
+func TestCreationLayers_Dependencies(t *testing.T) {
+	layers, err := creationLayers(fakeSubengines())
+	if err != nil {
+		t.Fatalf("creationLayers() error = %v", err)
+	}
+
+	// kind and postgres are independent; lcr (declared) and helm-install (implicit)
+	// depend on kind
+	want := [][]int{{0, 1}, {2, 3}}
+	if !reflect.DeepEqual(layers, want) {
+		t.Errorf("creationLayers() = %v, want %v", layers, want)
+	}
+}
+
+func TestTeardownOrder_ReverseDependencyOrder(t *testing.T) {
+	subengines := fakeSubengines()
+
+	order, err := teardownOrder(subengines)
+	if err != nil {
+		t.Fatalf("teardownOrder() error = %v", err)
+	}
+	if len(order) != len(subengines) {
+		t.Fatalf("Expected %d subengines in teardown order, got %v", len(subengines), order)
+	}
+
+	position := make(map[int]int, len(order))
+	for pos, i := range order {
+		position[i] = pos
+	}
+
+	deps, err := subengineDependencies(subengines)
+	if err != nil {
+		t.Fatalf("subengineDependencies() error = %v", err)
+	}
+	for i, dependencies := range deps {
+		for _, j := range dependencies {
+			if position[i] > position[j] {
+				t.Errorf("%s must be torn down before its dependency %s (order %v)",
+					subengines[i].Engine, subengines[j].Engine, order)
+			}
+		}
+	}
+
+	// Invariant: helm uninstall happens before the kind cluster is deleted
+	if position[3] > position[0] {
+		t.Errorf("Expected helm-install teardown before kind teardown, got order %v", order)
+	}
+}
+
+func TestCreationLayers_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		subengines []forge.TestenvEngineSpec
+		wantErr    string
+	}{
+		{
+			name: "unknown dependency",
+			subengines: []forge.TestenvEngineSpec{
+				{Engine: "go://testenv-helm-install", DependsOn: []string{"go://testenv-kind"}},
+			},
+			wantErr: "depends on unknown subengine go://testenv-kind",
+		},
+		{
+			name: "self dependency",
+			subengines: []forge.TestenvEngineSpec{
+				{Engine: "go://testenv-kind", DependsOn: []string{"go://testenv-kind"}},
+			},
+			wantErr: "cannot depend on itself",
+		},
+		{
+			name: "cycle",
+			subengines: []forge.TestenvEngineSpec{
+				{Engine: "go://a", DependsOn: []string{"go://b"}},
+				{Engine: "go://b", DependsOn: []string{"go://a"}},
+			},
+			wantErr: "dependency cycle detected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := creationLayers(tt.subengines)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunLayer_FakeSubengines(t *testing.T) {
+	subengines := fakeSubengines()
+	layers, err := creationLayers(subengines)
+	if err != nil {
+		t.Fatalf("creationLayers() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	for _, layer := range layers {
+		// Every subengine in a layer waits for all the others to start: this only
+		// completes if the layer is created concurrently
+		var started sync.WaitGroup
+		started.Add(len(layer))
+
+		results, errs := runLayer(layer, func(index int) (any, error) {
+			record("start " + subengines[index].Engine)
+			started.Done()
+
+			done := make(chan struct{})
+			go func() {
+				started.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				return nil, fmt.Errorf("%s was not created concurrently with its layer", subengines[index].Engine)
+			}
+
+			record("finish " + subengines[index].Engine)
+			return subengines[index].Engine, nil
+		})
+
+		for k, index := range layer {
+			if errs[k] != nil {
+				t.Fatalf("runLayer() error = %v", errs[k])
+			}
+			if results[k] != subengines[index].Engine {
+				t.Errorf("Expected results in layer order, got %v at position %d", results[k], k)
+			}
+		}
+	}
+
+	indexOf := func(event string) int {
+		for i, e := range events {
+			if e == event {
+				return i
+			}
+		}
+		t.Fatalf("event %q not recorded: %v", event, events)
+		return -1
+	}
+
+	if indexOf("start go://testenv-helm-install") < indexOf("finish go://testenv-kind") {
+		t.Errorf("helm-install started before kind finished: %v", events)
+	}
+	if indexOf("start go://testenv-lcr") < indexOf("finish go://testenv-kind") {
+		t.Errorf("lcr started before kind finished: %v", events)
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+// Health values reported for a test environment and its subengines.
+const (
+	healthOK       = "ok"
+	healthMissing  = "missing"
+	healthDisabled = "disabled"
+	healthUnknown  = "unknown"
+)
+
+// EnvironmentStatus reports the health of a test environment and its subengines.
+type EnvironmentStatus struct {
+	ID         string            `json:"id"`
+	Stage      string            `json:"stage"`
+	Status     string            `json:"status"`
+	TmpDir     string            `json:"tmpDir,omitempty"`
+	TmpDirOK   bool              `json:"tmpDirOK"`
+	Subengines []SubengineStatus `json:"subengines"`
+}
+
+// SubengineStatus reports whether the resources managed by a subengine still exist.
+type SubengineStatus struct {
+	Name    string   `json:"name"`
+	Health  string   `json:"health"`
+	Details []string `json:"details,omitempty"`
+}
+
+// statusChecker probes the resources of a test environment.
+// Probes are fields so that tests can replace them.
+type statusChecker struct {
+	fileExists        func(path string) bool
+	kindClusterExists func(name string) (bool, error)
+}
+
+// defaultStatusChecker returns a statusChecker probing the local filesystem and kind.
+func defaultStatusChecker() statusChecker {
+	return statusChecker{
+		fileExists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+		kindClusterExists: kindClusterExists,
+	}
+}
+
+// cmdStatus reports the health of the test environment identified by testID.
+// outputFormat is either empty (table) or "json".
+func cmdStatus(testID, outputFormat string) error {
+	if testID == "" {
+		return fmt.Errorf("test ID is required")
+	}
+	if outputFormat != "" && outputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (supported: json)", outputFormat)
+	}
+
+	// Read forge.yaml to get artifact store path
+	config, err := forge.ReadSpec()
+	if err != nil {
+		return fmt.Errorf("failed to read forge.yaml: %w", err)
+	}
+
+	// Get artifact store path from config
+	artifactStorePath, err := forge.GetArtifactStorePath(config.ArtifactStorePath)
+	if err != nil {
+		return fmt.Errorf("failed to get artifact store path: %w", err)
+	}
+
+	env, err := readTestEnvironment(artifactStorePath, testID)
+	if err != nil {
+		return err
+	}
+
+	status := defaultStatusChecker().check(env)
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(status)
+	}
+
+	return printStatus(os.Stdout, status)
+}
+
+// readTestEnvironment reads the test environment identified by testID from the artifact store.
+func readTestEnvironment(artifactStorePath, testID string) (*forge.TestEnvironment, error) {
+	store, err := forge.ReadArtifactStore(artifactStorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact store: %w", err)
+	}
+
+	env, err := forge.GetTestEnvironment(&store, testID)
+	if err != nil {
+		return nil, fmt.Errorf("test environment not found: %s", testID)
+	}
+
+	return env, nil
+}
+
+// check builds the status of env. Subengines are discovered from the
+// namespaced metadata keys (e.g. "testenv-kind.clusterName").
+func (c statusChecker) check(env *forge.TestEnvironment) EnvironmentStatus {
+	status := EnvironmentStatus{
+		ID:     env.ID,
+		Stage:  env.Name,
+		Status: env.Status,
+		TmpDir: env.TmpDir,
+	}
+	if env.TmpDir != "" {
+		status.TmpDirOK = c.fileExists(env.TmpDir)
+	}
+
+	for _, name := range subengineNames(env.Metadata) {
+		var s SubengineStatus
+		switch name {
+		case "testenv-kind":
+			s = c.checkKind(env.Metadata)
+		case "testenv-lcr":
+			s = c.checkLCR(env.Metadata)
+		case "testenv-helm-install":
+			s = c.checkHelmInstall(env.Metadata)
+		default:
+			s = SubengineStatus{Health: healthUnknown, Details: []string{"no health check available"}}
+		}
+		s.Name = name
+		status.Subengines = append(status.Subengines, s)
+	}
+
+	return status
+}
+
+// checkKind verifies that the kubeconfig file is present and the kind cluster still exists.
+func (c statusChecker) checkKind(metadata map[string]string) SubengineStatus {
+	s := SubengineStatus{Health: healthOK}
+
+	if path := metadata["testenv-kind.kubeconfigPath"]; path != "" {
+		if c.fileExists(path) {
+			s.Details = append(s.Details, "kubeconfig present: "+path)
+		} else {
+			s.Health = healthMissing
+			s.Details = append(s.Details, "kubeconfig missing: "+path)
+		}
+	}
+
+	if name := metadata["testenv-kind.clusterName"]; name != "" {
+		exists, err := c.kindClusterExists(name)
+		switch {
+		case err != nil:
+			if s.Health == healthOK {
+				s.Health = healthUnknown
+			}
+			s.Details = append(s.Details, fmt.Sprintf("cluster %s: %v", name, err))
+		case exists:
+			s.Details = append(s.Details, "cluster running: "+name)
+		default:
+			s.Health = healthMissing
+			s.Details = append(s.Details, "cluster not found: "+name)
+		}
+	}
+
+	return s
+}
+
+// checkLCR verifies that the registry CA and credential files are present.
+func (c statusChecker) checkLCR(metadata map[string]string) SubengineStatus {
+	if metadata["testenv-lcr.enabled"] == "false" {
+		return SubengineStatus{Health: healthDisabled, Details: []string{"local container registry disabled"}}
+	}
+
+	s := SubengineStatus{Health: healthOK}
+	if fqdn := metadata["testenv-lcr.registryFQDN"]; fqdn != "" {
+		s.Details = append(s.Details, "registry: "+fqdn)
+	}
+
+	for _, key := range []string{"testenv-lcr.caCrtPath", "testenv-lcr.credentialPath"} {
+		path := metadata[key]
+		if path == "" {
+			continue
+		}
+		label := strings.TrimPrefix(key, "testenv-lcr.")
+		if c.fileExists(path) {
+			s.Details = append(s.Details, fmt.Sprintf("%s present: %s", label, path))
+		} else {
+			s.Health = healthMissing
+			s.Details = append(s.Details, fmt.Sprintf("%s missing: %s", label, path))
+		}
+	}
+
+	return s
+}
+
+// checkHelmInstall lists the recorded releases and verifies that the kubeconfig
+// needed to uninstall them is still present.
+func (c statusChecker) checkHelmInstall(metadata map[string]string) SubengineStatus {
+	s := SubengineStatus{Health: healthOK}
+
+	var releases []string
+	for key, value := range metadata {
+		if strings.HasPrefix(key, "testenv-helm-install.chart.") && strings.HasSuffix(key, ".releaseName") {
+			releases = append(releases, value)
+		}
+	}
+	sort.Strings(releases)
+	s.Details = append(s.Details, fmt.Sprintf("%d release(s) recorded: %s", len(releases), strings.Join(releases, ", ")))
+
+	if len(releases) > 0 {
+		if path := metadata["testenv-kind.kubeconfigPath"]; path != "" && !c.fileExists(path) {
+			s.Health = healthMissing
+			s.Details = append(s.Details, "kubeconfig missing, releases cannot be reached: "+path)
+		}
+	}
+
+	return s
+}
+
+// subengineNames returns the sorted, unique subengine names found in metadata keys.
+func subengineNames(metadata map[string]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for key := range metadata {
+		// Skip the records kept by testenv to resume an interrupted create
+		if strings.HasPrefix(key, subengineRecordPrefix) {
+			continue
+		}
+		name, _, ok := strings.Cut(key, ".")
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printStatus writes status as a table to w.
+func printStatus(w io.Writer, status EnvironmentStatus) error {
+	_, _ = fmt.Fprintf(w, "Test environment: %s (stage: %s, status: %s)\n", status.ID, status.Stage, status.Status)
+	if status.TmpDir != "" {
+		tmpDirHealth := healthOK
+		if !status.TmpDirOK {
+			tmpDirHealth = healthMissing
+		}
+		_, _ = fmt.Fprintf(w, "Temporary directory: %s (%s)\n", status.TmpDir, tmpDirHealth)
+	}
+	_, _ = fmt.Fprintln(w)
+
+	if len(status.Subengines) == 0 {
+		_, _ = fmt.Fprintln(w, "No subengine metadata recorded")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SUBENGINE\tHEALTH\tDETAILS")
+	for _, s := range status.Subengines {
+		details := s.Details
+		if len(details) == 0 {
+			details = []string{"-"}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, s.Health, details[0])
+		for _, detail := range details[1:] {
+			_, _ = fmt.Fprintf(tw, "\t\t%s\n", detail)
+		}
+	}
+	return tw.Flush()
+}
+
+// kindClusterExists reports whether a kind cluster named name exists.
+// The kind binary is taken from KIND_BINARY (default: "kind") and prefixed with
+// KIND_BINARY_PREFIX (e.g. sudo) when set, as testenv-kind does.
+func kindClusterExists(name string) (bool, error) {
+	out, err := kindCommand(os.Getenv("KIND_BINARY"), os.Getenv("KIND_BINARY_PREFIX"), "get", "clusters").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to list kind clusters: %w", err)
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// kindCommand builds a kind command the same way testenv-kind does:
+// kindBinary defaults to "kind" and, if prefix is set, runs as an argument of prefix.
+func kindCommand(kindBinary, prefix string, args ...string) *exec.Cmd {
+	if kindBinary == "" {
+		kindBinary = "kind"
+	}
+	if prefix != "" {
+		return exec.Command(prefix, append([]string{kindBinary}, args...)...)
+	}
+	return exec.Command(kindBinary, args...)
+}
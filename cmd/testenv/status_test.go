@@ -0,0 +1,167 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+)
+
+func TestStatus_SyntheticStoreEntry(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "artifact-store.yaml")
+	store := forge.ArtifactStore{
+		Version: "1.0",
+		TestEnvironments: map[string]*forge.TestEnvironment{
+			"test-e2e-20250101-abcd1234": {
+				ID:        "test-e2e-20250101-abcd1234",
+				Name:      "e2e",
+				Status:    forge.TestStatusCreated,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				TmpDir:    "/tmp/forge-test-e2e",
+				Metadata: map[string]string{
+					"testenv-kind.clusterName":                 "forge-test-e2e",
+					"testenv-kind.kubeconfigPath":              "/tmp/forge-test-e2e/kubeconfig",
+					"testenv-lcr.enabled":                      "true",
+					"testenv-lcr.caCrtPath":                    "/tmp/forge-test-e2e/ca.crt",
+					"testenv-lcr.credentialPath":               "/tmp/forge-test-e2e/registry-credentials.yaml",
+					"testenv-helm-install.chartCount":          "1",
+					"testenv-helm-install.chart.0.releaseName": "cert-manager",
+					"testenv-custom.something":                 "value",
+				},
+			},
+		},
+	}
+	if err := forge.WriteArtifactStore(storePath, store); err != nil {
+		t.Fatalf("Failed to write artifact store: %v", err)
+	}
+
+	env, err := readTestEnvironment(storePath, "test-e2e-20250101-abcd1234")
+	if err != nil {
+		t.Fatalf("readTestEnvironment() error = %v", err)
+	}
+
+	// The cluster and kubeconfig are gone, the registry files remain
+	checker := statusChecker{
+		fileExists: func(path string) bool {
+			return path == "/tmp/forge-test-e2e" || strings.HasSuffix(path, "ca.crt") || strings.HasSuffix(path, "registry-credentials.yaml")
+		},
+		kindClusterExists: func(name string) (bool, error) {
+			return false, nil
+		},
+	}
+
+	status := checker.check(env)
+
+	if !status.TmpDirOK {
+		t.Error("Expected tmpDir to be reported as present")
+	}
+
+	expected := map[string]string{
+		"testenv-custom":       healthUnknown,
+		"testenv-helm-install": healthMissing,
+		"testenv-kind":         healthMissing,
+		"testenv-lcr":          healthOK,
+	}
+	if len(status.Subengines) != len(expected) {
+		t.Fatalf("Expected %d subengines, got %d: %+v", len(expected), len(status.Subengines), status.Subengines)
+	}
+	for i, name := range []string{"testenv-custom", "testenv-helm-install", "testenv-kind", "testenv-lcr"} {
+		s := status.Subengines[i]
+		if s.Name != name {
+			t.Errorf("Subengine %d: expected %s, got %s", i, name, s.Name)
+		}
+		if s.Health != expected[name] {
+			t.Errorf("Subengine %s: expected health %s, got %s (%v)", name, expected[name], s.Health, s.Details)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := printStatus(&buf, status); err != nil {
+		t.Fatalf("printStatus() error = %v", err)
+	}
+	for _, want := range []string{"SUBENGINE", "testenv-kind", "cluster not found: forge-test-e2e", "1 release(s) recorded: cert-manager"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestStatus_MissingTestID(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "artifact-store.yaml")
+	if err := forge.WriteArtifactStore(storePath, forge.ArtifactStore{Version: "1.0"}); err != nil {
+		t.Fatalf("Failed to write artifact store: %v", err)
+	}
+
+	_, err := readTestEnvironment(storePath, "does-not-exist")
+	if err == nil || !strings.Contains(err.Error(), "test environment not found: does-not-exist") {
+		t.Errorf("Expected clear not found error, got %v", err)
+	}
+}
+
+func TestStatus_KindCheckError(t *testing.T) {
+	checker := statusChecker{
+		fileExists: func(path string) bool { return true },
+		kindClusterExists: func(name string) (bool, error) {
+			return false, errors.New("kind not installed")
+		},
+	}
+
+	s := checker.checkKind(map[string]string{
+		"testenv-kind.clusterName":    "cluster",
+		"testenv-kind.kubeconfigPath": "/tmp/kubeconfig",
+	})
+	if s.Health != healthUnknown {
+		t.Errorf("Expected health %s, got %s", healthUnknown, s.Health)
+	}
+}
+
+func TestStatus_LCRDisabled(t *testing.T) {
+	s := defaultStatusChecker().checkLCR(map[string]string{"testenv-lcr.enabled": "false"})
+	if s.Health != healthDisabled {
+		t.Errorf("Expected health %s, got %s", healthDisabled, s.Health)
+	}
+}
+
+func TestKindCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		kindBinary string
+		prefix     string
+		expected   []string
+	}{
+		{name: "default binary", expected: []string{"kind", "get", "clusters"}},
+		{name: "custom binary", kindBinary: "/usr/local/bin/kind", expected: []string{"/usr/local/bin/kind", "get", "clusters"}},
+		{name: "prefixed binary", kindBinary: "kind", prefix: "sudo", expected: []string{"sudo", "kind", "get", "clusters"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := kindCommand(tt.kindBinary, tt.prefix, "get", "clusters")
+			if !reflect.DeepEqual(cmd.Args, tt.expected) {
+				t.Errorf("kindCommand() args = %v, want %v", cmd.Args, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtag
+
+import (
+	"bufio"
+	"fmt"
+	"go/build/constraint"
+	"io"
+	"os"
+	"strings"
+)
+
+// Header holds the build constraints found in the header of a Go source file.
+// The header is everything before the package clause, which only contains
+// blank lines and comments.
+type Header struct {
+	// GoBuild is the parsed //go:build expression, or nil if the file has none.
+	GoBuild constraint.Expr
+	// PlusBuild is the AND of all parsed legacy // +build lines, or nil if the file has none.
+	PlusBuild constraint.Expr
+}
+
+// Expr returns the effective build constraint of the header.
+// The //go:build expression takes precedence over legacy // +build lines,
+// matching the behavior of the go command. Returns nil if the header has no constraint.
+func (h *Header) Expr() constraint.Expr {
+	if h.GoBuild != nil {
+		return h.GoBuild
+	}
+	return h.PlusBuild
+}
+
+// ParseFile opens the file at path and parses its header.
+func ParseFile(path string) (*Header, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	header, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing build constraints in %s: %w", path, err)
+	}
+
+	return header, nil
+}
+
+// Parse scans the header of a Go source file and returns its build constraints.
+//
+// Scanning follows the placement rules of the go command:
+//   - Blank lines, line comments and block comments may precede a constraint
+//   - Scanning stops at the first line that is not blank or a comment (usually the package clause)
+//   - Multiple // +build lines are combined with AND
+//
+// Returns an error if a constraint line is malformed or the reader fails.
+func Parse(r io.Reader) (*Header, error) {
+	header := &Header{}
+	scanner := bufio.NewScanner(r)
+	inBlockComment := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip the content of block comments
+		if inBlockComment {
+			if strings.Contains(line, "*/") {
+				inBlockComment = false
+			}
+			continue
+		}
+
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/*") {
+			inBlockComment = !strings.Contains(line[2:], "*/")
+			continue
+		}
+
+		// Stop at the first line that is not a comment
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+
+		switch {
+		case constraint.IsGoBuild(line):
+			if header.GoBuild != nil {
+				return nil, fmt.Errorf("multiple //go:build lines")
+			}
+			expr, err := constraint.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid //go:build line %q: %w", line, err)
+			}
+			header.GoBuild = expr
+		case constraint.IsPlusBuild(line):
+			expr, err := constraint.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid // +build line %q: %w", line, err)
+			}
+			if header.PlusBuild == nil {
+				header.PlusBuild = expr
+			} else {
+				header.PlusBuild = &constraint.AndExpr{X: header.PlusBuild, Y: expr}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// HasTag reports whether expr references tag, negated or not.
+// Returns false if expr is nil.
+func HasTag(expr constraint.Expr, tag string) bool {
+	switch e := expr.(type) {
+	case *constraint.TagExpr:
+		return e.Tag == tag
+	case *constraint.NotExpr:
+		return HasTag(e.X, tag)
+	case *constraint.AndExpr:
+		return HasTag(e.X, tag) || HasTag(e.Y, tag)
+	case *constraint.OrExpr:
+		return HasTag(e.X, tag) || HasTag(e.Y, tag)
+	default:
+		return false
+	}
+}
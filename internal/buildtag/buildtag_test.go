@@ -0,0 +1,200 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtag
+
+import (
+	"go/build/constraint"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantGoBuild   string
+		wantPlusBuild string
+	}{
+		{
+			name:        "go:build on first line",
+			content:     "//go:build unit\n\npackage foo\n",
+			wantGoBuild: "unit",
+		},
+		{
+			name:        "comments and blank lines before go:build",
+			content:     "\n// Copyright header\n//\n// More license text\n\n//go:build integration\n\npackage foo\n",
+			wantGoBuild: "integration",
+		},
+		{
+			name:        "block comment before go:build",
+			content:     "/*\n//go:build ignored\n*/\n\n//go:build e2e\n\npackage foo\n",
+			wantGoBuild: "e2e",
+		},
+		{
+			name:          "legacy plus build only",
+			content:       "// +build unit\n\npackage foo\n",
+			wantPlusBuild: "unit",
+		},
+		{
+			name:          "multiple legacy plus build lines are combined",
+			content:       "// +build linux darwin\n// +build unit\n\npackage foo\n",
+			wantPlusBuild: "(linux || darwin) && unit",
+		},
+		{
+			name:          "both forms present",
+			content:       "//go:build unit && linux\n// +build unit,linux\n\npackage foo\n",
+			wantGoBuild:   "unit && linux",
+			wantPlusBuild: "unit && linux",
+		},
+		{
+			name:    "no tag",
+			content: "// Copyright header\n\npackage foo\n",
+		},
+		{
+			name:    "go:build after package clause is ignored",
+			content: "package foo\n\n//go:build unit\n",
+		},
+		{
+			name:    "empty file",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := Parse(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := exprString(header.GoBuild); got != tt.wantGoBuild {
+				t.Errorf("GoBuild = %q, want %q", got, tt.wantGoBuild)
+			}
+			if got := exprString(header.PlusBuild); got != tt.wantPlusBuild {
+				t.Errorf("PlusBuild = %q, want %q", got, tt.wantPlusBuild)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "malformed go:build expression",
+			content: "//go:build unit &&\n\npackage foo\n",
+		},
+		{
+			name:    "multiple go:build lines",
+			content: "//go:build unit\n//go:build e2e\n\npackage foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.content)); err == nil {
+				t.Error("Parse() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestHeaderExpr(t *testing.T) {
+	goBuild := &constraint.TagExpr{Tag: "unit"}
+	plusBuild := &constraint.TagExpr{Tag: "integration"}
+
+	tests := []struct {
+		name   string
+		header Header
+		want   string
+	}{
+		{name: "go:build takes precedence", header: Header{GoBuild: goBuild, PlusBuild: plusBuild}, want: "unit"},
+		{name: "falls back to plus build", header: Header{PlusBuild: plusBuild}, want: "integration"},
+		{name: "no constraint", header: Header{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exprString(tt.header.Expr()); got != tt.want {
+				t.Errorf("Expr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		tag  string
+		want bool
+	}{
+		{name: "single tag", line: "//go:build unit", tag: "unit", want: true},
+		{name: "different tag", line: "//go:build e2e", tag: "unit", want: false},
+		{name: "tag in and expression", line: "//go:build linux && unit", tag: "unit", want: true},
+		{name: "tag in or expression", line: "//go:build integration || e2e", tag: "e2e", want: true},
+		{name: "negated tag", line: "//go:build !unit", tag: "unit", want: true},
+		{name: "tag is a substring of another tag", line: "//go:build unittest", tag: "unit", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := constraint.Parse(tt.line)
+			if err != nil {
+				t.Fatalf("constraint.Parse() error = %v", err)
+			}
+			if got := HasTag(expr, tt.tag); got != tt.want {
+				t.Errorf("HasTag(%q, %q) = %v, want %v", tt.line, tt.tag, got, tt.want)
+			}
+		})
+	}
+
+	if HasTag(nil, "unit") {
+		t.Error("HasTag(nil) = true, want false")
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo_test.go")
+	if err := os.WriteFile(path, []byte("//go:build unit\n\npackage foo\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	header, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if !HasTag(header.Expr(), "unit") {
+		t.Errorf("expected header to reference tag unit, got %q", exprString(header.Expr()))
+	}
+
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.go")); err == nil {
+		t.Error("ParseFile() expected error for missing file, got nil")
+	}
+}
+
+// exprString returns the string form of expr, or "" if expr is nil.
+func exprString(expr constraint.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
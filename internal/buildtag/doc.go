@@ -0,0 +1,21 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buildtag provides utilities for reading build constraints from Go source files.
+//
+// This package includes:
+//   - ParseFile and Parse functions for scanning a file header for //go:build and // +build lines
+//   - Header type exposing the parsed constraint expressions
+//   - HasTag function for checking whether a constraint expression references a tag
+package buildtag
@@ -18,4 +18,5 @@
 //   - ExecuteInput/ExecuteOutput types for command execution
 //   - ExecuteCommand function for running shell commands
 //   - LoadEnvFile function for loading environment variables from files
+//   - ParseDuration function for parsing duration flags accepting days (e.g. "7d")
 package cmdutil
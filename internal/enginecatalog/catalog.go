@@ -0,0 +1,281 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package enginecatalog builds a catalog of forge engines.
+// Engines are discovered from the engines registry (docs/engines-list.yaml) or by
+// scanning a directory of engine packages, then queried for their version and
+// registered MCP tools.
+package enginecatalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Engine types reported in the catalog.
+const (
+	TypeBuilder            = "builder"
+	TypeTestRunner         = "test-runner"
+	TypeTestenvSubengine   = "testenv-subengine"
+	TypeDependencyDetector = "dependency-detector"
+	TypeUnknown            = "unknown"
+)
+
+// manifestFileName is the forge-dev configuration file describing a generated engine.
+const manifestFileName = "forge-dev.yaml"
+
+// devVersion is the version reported by engines built without version information.
+const devVersion = "dev"
+
+// defaultConcurrency bounds the number of engines queried at the same time.
+const defaultConcurrency = 4
+
+var errNoEnginesFound = errors.New("no engines found")
+
+// Engine identifies an engine to include in the catalog.
+type Engine struct {
+	// Name is the engine name (e.g., "go-build"), used to build its go:// URI.
+	Name string `json:"name" yaml:"name"`
+	// Path is the engine package directory relative to the repository root (e.g., "cmd/go-build").
+	Path string `json:"path" yaml:"path"`
+}
+
+// Tool describes an MCP tool registered by an engine.
+type Tool struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Capabilities is what an engine reports about itself when queried.
+type Capabilities struct {
+	// Version is the version reported by the engine's MCP server.
+	Version string
+	// Tools lists the MCP tools registered by the engine.
+	Tools []Tool
+}
+
+// Querier retrieves the capabilities of an engine, typically by starting its MCP server.
+type Querier func(ctx context.Context, engine Engine) (Capabilities, error)
+
+// Entry is a single engine in the catalog.
+type Entry struct {
+	Name        string `json:"name" yaml:"name"`
+	Path        string `json:"path,omitempty" yaml:"path,omitempty"`
+	Type        string `json:"type" yaml:"type"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Tools       []Tool `json:"tools" yaml:"tools"`
+	// Error is set when the engine could not be queried; the remaining fields
+	// then only contain what was read from the engine's manifest.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Catalog is the aggregated list of engines and their capabilities.
+type Catalog struct {
+	Engines []Entry `json:"engines" yaml:"engines"`
+}
+
+// Builder builds a Catalog by reading engine manifests and querying each engine.
+type Builder struct {
+	// Root is the repository root used to resolve Engine.Path. When empty, manifests are not read.
+	Root string
+	// Query retrieves the capabilities of an engine.
+	Query Querier
+	// Concurrency bounds the number of engines queried in parallel. Defaults to 4.
+	Concurrency int
+}
+
+// engineManifest is the subset of forge-dev.yaml used by the catalog.
+type engineManifest struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Build queries every engine and returns the catalog sorted by engine name.
+// Engines that fail to answer are still listed, with Error set.
+func (b Builder) Build(ctx context.Context, engines []Engine) Catalog {
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	entries := make([]Entry, len(engines))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, engine := range engines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			entries[i] = b.buildEntry(ctx, engine)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return Catalog{Engines: entries}
+}
+
+// buildEntry combines the engine manifest (if any) with the queried capabilities.
+func (b Builder) buildEntry(ctx context.Context, engine Engine) Entry {
+	entry := Entry{
+		Name:  engine.Name,
+		Path:  engine.Path,
+		Tools: []Tool{},
+	}
+
+	if b.Root != "" && engine.Path != "" {
+		if manifest, err := readManifest(filepath.Join(b.Root, engine.Path, manifestFileName)); err == nil {
+			entry.Type = manifest.Type
+			entry.Version = manifest.Version
+			entry.Description = manifest.Description
+		}
+	}
+
+	if b.Query != nil {
+		caps, err := b.Query(ctx, engine)
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			// Prefer the version reported at runtime, unless it is the "dev" placeholder
+			// of an unversioned build and the manifest knows better.
+			if caps.Version != "" && (caps.Version != devVersion || entry.Version == "") {
+				entry.Version = caps.Version
+			}
+			if caps.Tools != nil {
+				entry.Tools = caps.Tools
+			}
+		}
+	}
+
+	sort.Slice(entry.Tools, func(i, j int) bool { return entry.Tools[i].Name < entry.Tools[j].Name })
+
+	if entry.Type == "" {
+		entry.Type = InferType(entry.Tools)
+	}
+
+	return entry
+}
+
+// InferType guesses the engine type from the MCP tools it registers.
+// It is used for engines without a forge-dev.yaml manifest.
+func InferType(tools []Tool) string {
+	names := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		names[t.Name] = true
+	}
+
+	switch {
+	case names["detectDependencies"]:
+		return TypeDependencyDetector
+	case names["build"]:
+		return TypeBuilder
+	case names["create"] && names["delete"]:
+		return TypeTestenvSubengine
+	case names["run"]:
+		return TypeTestRunner
+	default:
+		return TypeUnknown
+	}
+}
+
+// ParseRegistry parses an engines registry (docs/engines-list.yaml) into engines.
+func ParseRegistry(data []byte) ([]Engine, error) {
+	var registry struct {
+		Engines []Engine `json:"engines"`
+	}
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse engines registry: %w", err)
+	}
+
+	engines := make([]Engine, 0, len(registry.Engines))
+	for i, e := range registry.Engines {
+		if e.Name == "" {
+			return nil, fmt.Errorf("engines registry: engines[%d]: name is required", i)
+		}
+		engines = append(engines, e)
+	}
+
+	if len(engines) == 0 {
+		return nil, errNoEnginesFound
+	}
+	return engines, nil
+}
+
+// ScanDir discovers engines in root/dir: every subdirectory containing a
+// forge-dev.yaml manifest or a main.go file is considered an engine.
+func ScanDir(root, dir string) ([]Engine, error) {
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	var engines []Engine
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		enginePath := filepath.Join(dir, e.Name())
+		if !isEngineDir(filepath.Join(root, enginePath)) {
+			continue
+		}
+
+		name := e.Name()
+		if manifest, err := readManifest(filepath.Join(root, enginePath, manifestFileName)); err == nil && manifest.Name != "" {
+			name = manifest.Name
+		}
+
+		engines = append(engines, Engine{Name: name, Path: filepath.ToSlash(enginePath)})
+	}
+
+	if len(engines) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoEnginesFound, dir)
+	}
+	return engines, nil
+}
+
+func isEngineDir(path string) bool {
+	for _, f := range []string{manifestFileName, "main.go"} {
+		if _, err := os.Stat(filepath.Join(path, f)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func readManifest(path string) (engineManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return engineManifest{}, err
+	}
+
+	var manifest engineManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return engineManifest{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,235 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginecatalog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFakeEngines creates a small engine tree under root/cmd:
+//   - fake-builder: forge-dev.yaml manifest (type builder)
+//   - fake-runner: forge-dev.yaml manifest (type test-runner)
+//   - fake-orchestrator: main.go only (no manifest)
+//   - not-an-engine: neither manifest nor main.go
+func writeFakeEngines(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	files := map[string]string{
+		"cmd/fake-builder/forge-dev.yaml":  "name: fake-builder\ntype: builder\nversion: 0.1.0\ndescription: Builds fake artifacts\n",
+		"cmd/fake-runner/forge-dev.yaml":   "name: fake-runner\ntype: test-runner\nversion: 0.2.0\ndescription: Runs fake tests\n",
+		"cmd/fake-orchestrator/main.go":    "package main\n",
+		"cmd/not-an-engine/README.md":      "# not an engine\n",
+		"cmd/test-servers.sh":              "#!/bin/sh\n",
+		"docs/engines-list.yaml":           "version: \"1.0\"\nengines:\n  - name: fake-builder\n    path: cmd/fake-builder\n  - name: fake-runner\n    path: cmd/fake-runner\n",
+		"cmd/fake-builder/zz_generated.go": "package main\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o600))
+	}
+
+	return root
+}
+
+func fakeQuerier(caps map[string]Capabilities, failing map[string]error) Querier {
+	return func(_ context.Context, engine Engine) (Capabilities, error) {
+		if err, ok := failing[engine.Name]; ok {
+			return Capabilities{}, err
+		}
+		return caps[engine.Name], nil
+	}
+}
+
+func TestScanDir(t *testing.T) {
+	root := writeFakeEngines(t)
+
+	engines, err := ScanDir(root, "cmd")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []Engine{
+		{Name: "fake-builder", Path: "cmd/fake-builder"},
+		{Name: "fake-orchestrator", Path: "cmd/fake-orchestrator"},
+		{Name: "fake-runner", Path: "cmd/fake-runner"},
+	}, engines)
+}
+
+func TestScanDir_NoEngines(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cmd", "empty"), 0o755))
+
+	_, err := ScanDir(root, "cmd")
+	assert.ErrorIs(t, err, errNoEnginesFound)
+}
+
+func TestParseRegistry(t *testing.T) {
+	root := writeFakeEngines(t)
+	data, err := os.ReadFile(filepath.Join(root, "docs", "engines-list.yaml"))
+	require.NoError(t, err)
+
+	engines, err := ParseRegistry(data)
+	require.NoError(t, err)
+	assert.Equal(t, []Engine{
+		{Name: "fake-builder", Path: "cmd/fake-builder"},
+		{Name: "fake-runner", Path: "cmd/fake-runner"},
+	}, engines)
+
+	_, err = ParseRegistry([]byte("engines: []\n"))
+	assert.ErrorIs(t, err, errNoEnginesFound)
+
+	_, err = ParseRegistry([]byte("engines:\n  - path: cmd/nameless\n"))
+	assert.ErrorContains(t, err, "name is required")
+}
+
+func TestBuilder_Build(t *testing.T) {
+	root := writeFakeEngines(t)
+	engines, err := ScanDir(root, "cmd")
+	require.NoError(t, err)
+
+	builder := Builder{
+		Root: root,
+		Query: fakeQuerier(map[string]Capabilities{
+			"fake-builder": {
+				Version: "dev",
+				Tools:   []Tool{{Name: "build", Description: "Build"}, {Name: "buildBatch"}, {Name: "config-validate"}},
+			},
+			"fake-orchestrator": {
+				Version: "v1.2.3",
+				Tools:   []Tool{{Name: "run"}, {Name: "config-validate"}},
+			},
+		}, map[string]error{
+			"fake-runner": errors.New("failed to connect to MCP server"),
+		}),
+	}
+
+	catalog := builder.Build(context.Background(), engines)
+
+	require.Len(t, catalog.Engines, 3)
+	assert.Equal(t, Catalog{Engines: []Entry{
+		{
+			Name:        "fake-builder",
+			Path:        "cmd/fake-builder",
+			Type:        TypeBuilder,
+			Version:     "0.1.0", // manifest version wins over the "dev" placeholder
+			Description: "Builds fake artifacts",
+			Tools:       []Tool{{Name: "build", Description: "Build"}, {Name: "buildBatch"}, {Name: "config-validate"}},
+		},
+		{
+			Name:    "fake-orchestrator",
+			Path:    "cmd/fake-orchestrator",
+			Type:    TypeTestRunner, // inferred from the "run" tool
+			Version: "v1.2.3",
+			Tools:   []Tool{{Name: "config-validate"}, {Name: "run"}},
+		},
+		{
+			Name:        "fake-runner",
+			Path:        "cmd/fake-runner",
+			Type:        TypeTestRunner,
+			Version:     "0.2.0",
+			Description: "Runs fake tests",
+			Tools:       []Tool{},
+			Error:       "failed to connect to MCP server",
+		},
+	}}, catalog)
+}
+
+func TestBuilder_Build_WithoutQuerier(t *testing.T) {
+	root := writeFakeEngines(t)
+
+	catalog := Builder{Root: root}.Build(context.Background(), []Engine{
+		{Name: "fake-orchestrator", Path: "cmd/fake-orchestrator"},
+		{Name: "fake-builder", Path: "cmd/fake-builder"},
+	})
+
+	require.Len(t, catalog.Engines, 2)
+	assert.Equal(t, "fake-builder", catalog.Engines[0].Name)
+	assert.Equal(t, TypeBuilder, catalog.Engines[0].Type)
+	assert.Equal(t, "fake-orchestrator", catalog.Engines[1].Name)
+	assert.Equal(t, TypeUnknown, catalog.Engines[1].Type)
+	assert.NotNil(t, catalog.Engines[1].Tools)
+}
+
+func TestBuilder_Build_BoundsConcurrency(t *testing.T) {
+	engines := make([]Engine, 10)
+	for i := range engines {
+		engines[i] = Engine{Name: string(rune('a' + i))}
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, len(engines))
+
+	query := func(context.Context, Engine) (Capabilities, error) {
+		n := inFlight.Add(1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		started <- struct{}{}
+		<-release
+		inFlight.Add(-1)
+		return Capabilities{}, nil
+	}
+
+	done := make(chan Catalog)
+	go func() { done <- Builder{Query: query, Concurrency: 2}.Build(context.Background(), engines) }()
+
+	// Wait for the first two queries, then let everything finish
+	<-started
+	<-started
+	close(release)
+	catalog := <-done
+
+	assert.Len(t, catalog.Engines, len(engines))
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		name  string
+		tools []string
+		want  string
+	}{
+		{name: "builder", tools: []string{"build", "buildBatch"}, want: TypeBuilder},
+		{name: "test runner", tools: []string{"run"}, want: TypeTestRunner},
+		{name: "testenv subengine", tools: []string{"create", "delete"}, want: TypeTestenvSubengine},
+		{name: "dependency detector", tools: []string{"detectDependencies"}, want: TypeDependencyDetector},
+		{name: "create without delete", tools: []string{"create"}, want: TypeUnknown},
+		{name: "no tools", want: TypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools := make([]Tool, 0, len(tt.tools))
+			for _, name := range tt.tools {
+				tools = append(tools, Tool{Name: name})
+			}
+			assert.Equal(t, tt.want, InferType(tools))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginecatalog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/internal/engineresolver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NewMCPQuerier returns a Querier that starts each engine's MCP server through its
+// go:// URI, lists its tools and reads the version from the server info.
+// Each query is bounded by timeout, which must account for `go run` compilation.
+func NewMCPQuerier(forgeVersion string, timeout time.Duration) Querier {
+	return func(ctx context.Context, engine Engine) (Capabilities, error) {
+		_, command, args, err := engineresolver.ParseEngineURI("go://"+engine.Name, forgeVersion)
+		if err != nil {
+			return Capabilities{}, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, command, append(args, "--mcp")...)
+		cmd.Env = os.Environ()
+
+		client := mcp.NewClient(&mcp.Implementation{
+			Name:    "forge-catalog",
+			Version: forgeVersion,
+		}, nil)
+
+		session, err := client.Connect(ctx, &mcp.CommandTransport{Command: cmd}, nil)
+		if err != nil {
+			return Capabilities{}, fmt.Errorf("failed to connect to MCP server %s %v: %w", command, args, err)
+		}
+		defer func() { _ = session.Close() }()
+
+		caps := Capabilities{Tools: []Tool{}}
+		if res := session.InitializeResult(); res != nil && res.ServerInfo != nil {
+			caps.Version = res.ServerInfo.Version
+		}
+
+		for tool, err := range session.Tools(ctx, nil) {
+			if err != nil {
+				return Capabilities{}, fmt.Errorf("failed to list tools: %w", err)
+			}
+			caps.Tools = append(caps.Tools, Tool{Name: tool.Name, Description: tool.Description})
+		}
+
+		return caps, nil
+	}
+}
@@ -21,12 +21,18 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/alexandremahdhaoui/forge/internal/testutil"
 )
 
 func TestGetCurrentCommitSHA_InGitRepo(t *testing.T) {
 	// This test assumes we're running in a git repository
 	// which should be true for this project
-	sha, err := GetCurrentCommitSHA()
+	var sha string
+	var err error
+	testutil.AssertNoLeakedProcesses(t, func() {
+		sha, err = GetCurrentCommitSHA()
+	})
 	if err != nil {
 		t.Fatalf("GetCurrentCommitSHA failed: %v", err)
 	}
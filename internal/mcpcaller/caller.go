@@ -24,6 +24,7 @@ import (
 	"os/exec"
 
 	"github.com/alexandremahdhaoui/forge/internal/engineresolver"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -47,7 +48,7 @@ func NewCaller(forgeVersion string) *Caller {
 
 // CallMCP implements MCPCaller - spawns MCP server process and calls tool.
 // This is adapted from cmd/forge/mcp_client.go:callMCPEngine (lines 31-102)
-func (c *Caller) CallMCP(command string, args []string, toolName string, params interface{}) (interface{}, error) {
+func (c *Caller) CallMCP(command string, args []string, toolName string, params interface{}) (_ interface{}, err error) {
 	// Create command to spawn MCP server
 	// Append --mcp flag to the args
 	cmdArgs := append(args, "--mcp")
@@ -70,8 +71,9 @@ func (c *Caller) CallMCP(command string, args []string, toolName string, params
 		Command: cmd,
 	}
 
-	// Connect to the MCP server
-	ctx := context.Background()
+	// Trace the call and connect to the MCP server
+	ctx, span := tracing.StartCall(context.Background(), command, args, toolName)
+	defer func() { tracing.End(span, err) }()
 	session, err := client.Connect(ctx, transport, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MCP server %s %v: %w", command, args, err)
@@ -89,6 +91,7 @@ func (c *Caller) CallMCP(command string, args []string, toolName string, params
 
 	// Call the tool
 	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      tracing.InjectMeta(ctx),
 		Name:      toolName,
 		Arguments: arguments,
 	})
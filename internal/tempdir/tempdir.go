@@ -0,0 +1,72 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tempdir creates temporary files and directories under a base directory
+// that can be overridden with FORGE_TMPDIR, e.g. when the OS default temp directory
+// is small or read-only in sandboxed CI.
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// EnvVar overrides the base directory of temporary files and directories.
+const EnvVar = "FORGE_TMPDIR"
+
+// Dir returns the base directory for temporary resources: FORGE_TMPDIR (made absolute)
+// if set, otherwise the OS default temp directory.
+func Dir() string {
+	dir := os.Getenv(EnvVar)
+	if dir == "" {
+		return os.TempDir()
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// MkdirTemp creates a new temporary directory under Dir, like os.MkdirTemp("", pattern).
+// The base directory is created if it does not exist.
+func MkdirTemp(pattern string) (string, error) {
+	dir, err := ensureDir()
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, pattern)
+}
+
+// CreateTemp creates a new temporary file under Dir, like os.CreateTemp("", pattern).
+// The base directory is created if it does not exist.
+func CreateTemp(pattern string) (*os.File, error) {
+	dir, err := ensureDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+// ensureDir returns Dir, creating it when it is overridden by FORGE_TMPDIR.
+func ensureDir() (string, error) {
+	dir := Dir()
+	if os.Getenv(EnvVar) == "" {
+		return dir, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s directory %s: %w", EnvVar, dir, err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,116 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tempdir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	t.Run("defaults to the OS temp directory", func(t *testing.T) {
+		t.Setenv(EnvVar, "")
+		if got := Dir(); got != os.TempDir() {
+			t.Errorf("Dir() = %q, want %q", got, os.TempDir())
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		base := t.TempDir()
+		t.Setenv(EnvVar, base)
+		if got := Dir(); got != base {
+			t.Errorf("Dir() = %q, want %q", got, base)
+		}
+	})
+
+	t.Run("relative override is made absolute", func(t *testing.T) {
+		t.Setenv(EnvVar, "relative/tmp")
+		got := Dir()
+		if !filepath.IsAbs(got) || !strings.HasSuffix(got, filepath.Join("relative", "tmp")) {
+			t.Errorf("Dir() = %q, want an absolute path ending in relative/tmp", got)
+		}
+	})
+}
+
+func TestMkdirTemp_UnderOverride(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "forge-tmp")
+	t.Setenv(EnvVar, base)
+
+	dir, err := MkdirTemp("helm-git-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+
+	if filepath.Dir(dir) != base {
+		t.Errorf("MkdirTemp() = %q, want a directory under %q", dir, base)
+	}
+	if !strings.HasPrefix(filepath.Base(dir), "helm-git-") {
+		t.Errorf("MkdirTemp() = %q, want the pattern prefix", dir)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("MkdirTemp() did not create a directory: %v", err)
+	}
+}
+
+func TestCreateTemp_UnderOverride(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "nested", "forge-tmp")
+	t.Setenv(EnvVar, base)
+
+	f, err := CreateTemp("helm-values-*.yaml")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if filepath.Dir(f.Name()) != base {
+		t.Errorf("CreateTemp() = %q, want a file under %q", f.Name(), base)
+	}
+	if !strings.HasSuffix(f.Name(), ".yaml") {
+		t.Errorf("CreateTemp() = %q, want the pattern suffix", f.Name())
+	}
+}
+
+func TestCreateTemp_DefaultDir(t *testing.T) {
+	t.Setenv(EnvVar, "")
+
+	f, err := CreateTemp("forge-tempdir-test-*")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	if filepath.Dir(f.Name()) != filepath.Clean(os.TempDir()) {
+		t.Errorf("CreateTemp() = %q, want a file under %q", f.Name(), os.TempDir())
+	}
+}
+
+func TestMkdirTemp_UnusableOverride(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvVar, filepath.Join(file, "tmp"))
+
+	if _, err := MkdirTemp("x-*"); err == nil || !strings.Contains(err.Error(), EnvVar) {
+		t.Errorf("MkdirTemp() error = %v, want an error naming %s", err, EnvVar)
+	}
+}
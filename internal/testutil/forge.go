@@ -0,0 +1,64 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ForgeBinaryPath is the forge binary run by RunForge, relative to the repository root.
+const ForgeBinaryPath = "./build/bin/forge"
+
+// RunForge runs the forge binary at ForgeBinaryPath with the given arguments and the
+// current environment. It returns the combined output; on failure the error names the
+// command and includes its output.
+func RunForge(args ...string) (string, error) {
+	cmd := exec.Command(ForgeBinaryPath, args...)
+	cmd.Env = os.Environ()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("forge %s failed: %w\nOutput: %s", strings.Join(args, " "), err, output)
+	}
+	return string(output), nil
+}
+
+// VerifyContains checks that output contains every expected substring.
+// The error names the first missing substring and includes the output.
+func VerifyContains(output string, expected ...string) error {
+	for _, s := range expected {
+		if !strings.Contains(output, s) {
+			return fmt.Errorf("output missing %q\nOutput: %s", s, output)
+		}
+	}
+	return nil
+}
+
+// VerifyBinaryExists checks that path is a regular file executable by its owner.
+func VerifyBinaryExists(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("binary not found: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("binary %s is not a regular file (mode %s)", path, info.Mode())
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		return fmt.Errorf("binary %s is not executable (mode %s)", path, info.Mode())
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFakeForge writes a shell script at ForgeBinaryPath under a temporary directory
+// and changes into that directory.
+func writeFakeForge(t *testing.T, script string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ForgeBinaryPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+}
+
+func TestRunForge_Success(t *testing.T) {
+	writeFakeForge(t, `echo "args: $@"`)
+
+	output, err := RunForge("test", "list-env", "e2e-stub")
+	if err != nil {
+		t.Fatalf("RunForge() error = %v", err)
+	}
+	if strings.TrimSpace(output) != "args: test list-env e2e-stub" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestRunForge_Failure(t *testing.T) {
+	writeFakeForge(t, "echo 'environment not found' >&2\nexit 3\n")
+
+	output, err := RunForge("test", "get-env", "e2e-stub", "missing")
+	if err == nil {
+		t.Fatal("RunForge() error = nil, want an error")
+	}
+	if !strings.Contains(output, "environment not found") {
+		t.Errorf("output = %q, want the command output", output)
+	}
+	for _, want := range []string{"forge test get-env e2e-stub missing", "exit status 3", "environment not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestVerifyContains(t *testing.T) {
+	output := "ID    NAME\ntest-e2e-stub-20250101-abcdef12  e2e-stub"
+
+	if err := VerifyContains(output, "ID", "NAME", "test-e2e-stub"); err != nil {
+		t.Errorf("VerifyContains() error = %v", err)
+	}
+	if err := VerifyContains(output); err != nil {
+		t.Errorf("VerifyContains() without substrings error = %v", err)
+	}
+
+	err := VerifyContains(output, "ID", "STATUS")
+	if err == nil {
+		t.Fatal("VerifyContains() error = nil, want an error")
+	}
+	if !strings.Contains(err.Error(), `"STATUS"`) || !strings.Contains(err.Error(), output) {
+		t.Errorf("error = %q, want the missing substring and the output", err)
+	}
+}
+
+func TestVerifyBinaryExists(t *testing.T) {
+	dir := t.TempDir()
+
+	executable := filepath.Join(dir, "forge")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(dir, "forge.txt")
+	if err := os.WriteFile(notExecutable, []byte("text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "executable", path: executable},
+		{name: "missing", path: filepath.Join(dir, "missing"), wantErr: "not found"},
+		{name: "directory", path: dir, wantErr: "not a regular file"},
+		{name: "not executable", path: notExecutable, wantErr: "not executable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyBinaryExists(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyBinaryExists() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyBinaryExists() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procLeakGracePeriod is how long AssertNoLeakedProcesses waits for new child
+// processes to exit before reporting them as leaked.
+const procLeakGracePeriod = 2 * time.Second
+
+// childProcess describes a direct child of the test process.
+type childProcess struct {
+	PID     int
+	Command string
+	State   string
+}
+
+// String returns the process as "pid (command) state".
+func (p childProcess) String() string {
+	return fmt.Sprintf("%d (%s) %s", p.PID, p.Command, p.State)
+}
+
+// listChildProcesses returns the direct children of the current process, keyed by PID.
+// Exited children that were never waited for (zombies) are included.
+// It reads /proc and is therefore only supported on Linux.
+func listChildProcesses() (map[int]childProcess, error) {
+	statFiles, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return nil, err
+	}
+
+	self := os.Getpid()
+	children := make(map[int]childProcess)
+	for _, statFile := range statFiles {
+		data, err := os.ReadFile(statFile)
+		if err != nil {
+			// The process exited while listing
+			continue
+		}
+
+		proc, ppid, ok := parseProcStat(string(data))
+		if !ok || ppid != self {
+			continue
+		}
+		children[proc.PID] = proc
+	}
+
+	return children, nil
+}
+
+// parseProcStat parses the pid, command, state and parent pid out of a /proc/<pid>/stat line.
+// The command is enclosed in parentheses and may itself contain spaces or parentheses.
+func parseProcStat(stat string) (childProcess, int, bool) {
+	open := strings.IndexByte(stat, '(')
+	closing := strings.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return childProcess{}, 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(stat[:open]))
+	if err != nil {
+		return childProcess{}, 0, false
+	}
+
+	// Fields after the command: state ppid ...
+	fields := strings.Fields(stat[closing+1:])
+	if len(fields) < 2 {
+		return childProcess{}, 0, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return childProcess{}, 0, false
+	}
+
+	return childProcess{PID: pid, Command: stat[open+1 : closing], State: fields[0]}, ppid, true
+}
+
+// leakedProcesses returns the processes in after that are not in before, sorted by PID.
+func leakedProcesses(before, after map[int]childProcess) []childProcess {
+	var leaked []childProcess
+	for pid, proc := range after {
+		if _, ok := before[pid]; !ok {
+			leaked = append(leaked, proc)
+		}
+	}
+	sort.Slice(leaked, func(i, j int) bool { return leaked[i].PID < leaked[j].PID })
+	return leaked
+}
+
+// AssertNoLeakedProcesses runs fn and fails the test if fn left child processes behind.
+// It snapshots the direct children of the test process before and after fn, and gives
+// new children a short grace period to exit. Children that exited without being waited
+// for (zombies) also count as leaked.
+//
+// The check is a no-op where /proc is unavailable (non-Linux). It must not be used in
+// tests running with t.Parallel(), as processes started by other tests would be reported.
+func AssertNoLeakedProcesses(t TestingT, fn func()) {
+	t.Helper()
+
+	if !procAvailable() {
+		fn()
+		return
+	}
+
+	before, err := listChildProcesses()
+	if err != nil {
+		t.Fatalf("failed to list child processes: %v", err)
+		return
+	}
+
+	fn()
+
+	deadline := time.Now().Add(procLeakGracePeriod)
+	for {
+		after, err := listChildProcesses()
+		if err != nil {
+			t.Fatalf("failed to list child processes: %v", err)
+			return
+		}
+
+		leaked := leakedProcesses(before, after)
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			descriptions := make([]string, 0, len(leaked))
+			for _, proc := range leaked {
+				descriptions = append(descriptions, proc.String())
+			}
+			t.Fatalf("leaked %d child process(es): %s", len(leaked), strings.Join(descriptions, ", "))
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// procAvailable reports whether the current process is visible under /proc.
+func procAvailable() bool {
+	_, err := os.Stat(filepath.Join("/proc", strconv.Itoa(os.Getpid()), "stat"))
+	return err == nil
+}
@@ -0,0 +1,94 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseProcStat(t *testing.T) {
+	proc, ppid, ok := parseProcStat("4242 (my (odd) cmd) S 17 4242 4242 0 -1")
+	if !ok {
+		t.Fatal("expected stat line to parse")
+	}
+
+	AssertEqual(t, childProcess{PID: 4242, Command: "my (odd) cmd", State: "S"}, proc)
+	AssertEqual(t, 17, ppid)
+}
+
+func TestParseProcStat_Malformed(t *testing.T) {
+	for _, stat := range []string{"", "4242 S 17", "abc (cmd) S 17", "4242 (cmd) S"} {
+		if _, _, ok := parseProcStat(stat); ok {
+			t.Errorf("expected %q not to parse", stat)
+		}
+	}
+}
+
+func TestAssertNoLeakedProcesses_WaitedCommand(t *testing.T) {
+	AssertNoLeakedProcesses(t, func() {
+		if err := exec.Command("true").Run(); err != nil {
+			t.Fatalf("failed to run command: %v", err)
+		}
+	})
+}
+
+func TestAssertNoLeakedProcesses_DetectsLeak(t *testing.T) {
+	if !procAvailable() {
+		t.Skip("/proc is not available")
+	}
+
+	// Deliberately leak a process by starting it without waiting for it
+	var leaked *exec.Cmd
+	mockT := &recordingTestingT{}
+	AssertNoLeakedProcesses(mockT, func() {
+		leaked = exec.Command("sleep", "30")
+		if err := leaked.Start(); err != nil {
+			t.Fatalf("failed to start command: %v", err)
+		}
+	})
+	t.Cleanup(func() {
+		_ = leaked.Process.Kill()
+		_ = leaked.Wait()
+	})
+
+	if !mockT.failed {
+		t.Fatal("expected AssertNoLeakedProcesses to detect the leaked process")
+	}
+	AssertContains(t, mockT.message, "leaked 1 child process(es)")
+	AssertContains(t, mockT.message, "(sleep)")
+}
+
+// recordingTestingT records the failure message instead of failing the test.
+type recordingTestingT struct {
+	failed  bool
+	message string
+}
+
+func (r *recordingTestingT) Helper() {}
+
+func (r *recordingTestingT) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+	r.message = fmt.Sprintf(format, args...)
+}
+
+func (r *recordingTestingT) Fatal(args ...interface{}) {
+	r.failed = true
+	r.message = strings.TrimSpace(fmt.Sprintln(args...))
+}
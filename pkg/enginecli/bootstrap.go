@@ -15,12 +15,22 @@
 package enginecli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
 	"github.com/alexandremahdhaoui/forge/pkg/engineversion"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 )
 
 // Config holds the configuration for CLI bootstrap.
@@ -38,8 +48,14 @@ type Config struct {
 
 	// RunMCP is the function to execute in MCP server mode (optional)
 	// If nil, --mcp flag will result in an error
+	// It should run its server with Context() so that it stops on SIGINT or SIGTERM
 	RunMCP func() error
 
+	// ShutdownGracePeriod is how long RunMCP may take to return after SIGINT or SIGTERM
+	// before the process is force-exited (optional)
+	// Defaults to DefaultShutdownGracePeriod if zero
+	ShutdownGracePeriod time.Duration
+
 	// SuccessHandler is called when RunCLI completes successfully (optional)
 	// Defaults to no-op if not provided
 	SuccessHandler func()
@@ -52,13 +68,94 @@ type Config struct {
 	// DocsConfig is the configuration for the docs subcommand (optional)
 	// If set and "docs" is the first argument, the docs command is handled internally
 	DocsConfig *enginedocs.Config
+
+	// SetupMCP creates the MCP server with all its tools registered, without running it (optional)
+	// It is used by "docs verify" to cross-reference the docs with the registered tools
+	SetupMCP func() (*mcpserver.Server, error)
+}
+
+// DefaultShutdownGracePeriod is the default Config.ShutdownGracePeriod.
+const DefaultShutdownGracePeriod = 5 * time.Second
+
+// errShutdownTimeout is returned by runUntilShutdown when the run function outlives its grace period.
+var errShutdownTimeout = errors.New("did not stop within the shutdown grace period")
+
+// rootCtx is the context returned by Context.
+var rootCtx = context.Background()
+
+// Context returns the root context of the engine.
+// In MCP mode, Bootstrap cancels it when the process receives SIGINT or SIGTERM, so that the
+// MCP server and the subprocesses started with exec.CommandContext stop and clean up.
+// It is never cancelled outside of MCP mode.
+func Context() context.Context {
+	return rootCtx
+}
+
+var (
+	exitHooksMu sync.Mutex
+	exitHooks   []func()
+)
+
+// OnExit registers fn to run before Bootstrap exits the process, e.g. to remove temporary files.
+// Hooks run in reverse order of registration. They do not run when the process is killed.
+func OnExit(fn func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// runExitHooks runs and clears the hooks registered with OnExit.
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// exit runs the exit hooks and exits the process with code.
+func exit(code int) {
+	runExitHooks()
+	os.Exit(code)
+}
+
+// configPath is the absolute path of the forge.yaml given with --config.
+var configPath string
+
+// ConfigPath returns the absolute path of the forge.yaml given with the --config flag,
+// or "" when the flag was not set.
+func ConfigPath() string {
+	return configPath
+}
+
+// RootDir returns the directory containing the forge.yaml given with the --config flag,
+// or "" when the flag was not set.
+func RootDir() string {
+	if configPath == "" {
+		return ""
+	}
+	return filepath.Dir(configPath)
 }
 
 // Bootstrap provides a unified entry point for forge CLI commands.
-// It handles version flags, MCP mode, and CLI execution with standardized error handling.
+// It handles the --config flag, version flags, MCP mode, and CLI execution with standardized error handling.
+//
+// The --config flag is removed from os.Args before RunCLI or RunMCP is called.
 //
-// This function will call os.Exit and never return.
+// This function will call os.Exit, after the hooks registered with OnExit, and never return.
 func Bootstrap(cfg Config) {
+	// Extract --config so that RunCLI and RunMCP only see their own arguments
+	path, args, err := parseConfigFlag(os.Args[1:])
+	if err != nil {
+		log.Printf("Error: %v", err)
+		exit(1)
+	}
+	configPath = path
+	os.Args = append(os.Args[:1], args...)
+
 	// Initialize version information
 	versionInfo := engineversion.New(cfg.Name)
 	versionInfo.Version = cfg.Version
@@ -69,14 +166,14 @@ func Bootstrap(cfg Config) {
 	for _, arg := range os.Args[1:] {
 		if arg == "version" || arg == "--version" || arg == "-v" {
 			versionInfo.Print()
-			os.Exit(0)
+			exit(0)
 		}
 	}
 
 	// Check for docs subcommand
 	if cfg.DocsConfig != nil && len(os.Args) > 1 && os.Args[1] == "docs" {
-		exitCode := handleDocsCommand(cfg.DocsConfig, os.Args[2:])
-		os.Exit(exitCode)
+		exitCode := handleDocsCommand(cfg.DocsConfig, cfg.SetupMCP, os.Args[2:])
+		exit(exitCode)
 	}
 
 	// Check for --mcp flag to run as MCP server
@@ -84,32 +181,28 @@ func Bootstrap(cfg Config) {
 		if arg == "--mcp" {
 			if cfg.RunMCP == nil {
 				log.Printf("Error: MCP mode not supported for %s", cfg.Name)
-				os.Exit(1)
-			}
-			if err := cfg.RunMCP(); err != nil {
-				log.Printf("MCP server error: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
-			os.Exit(0)
+			exit(runMCP(cfg))
 		}
 	}
 
 	// Normal CLI mode
 	if cfg.RunCLI == nil {
 		log.Printf("Error: CLI mode not supported for %s (use --mcp flag)", cfg.Name)
-		os.Exit(1)
+		exit(1)
 	}
 	if err := cfg.RunCLI(); err != nil {
 		if cfg.FailureHandler != nil {
 			cfg.FailureHandler(err)
 		}
-		os.Exit(1)
+		exit(1)
 	}
 
 	if cfg.SuccessHandler != nil {
 		cfg.SuccessHandler()
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // BootstrapSimple is a convenience wrapper for commands that don't support MCP mode.
@@ -124,9 +217,114 @@ func BootstrapSimple(name, version, commitSHA, buildTimestamp string, runCLI fun
 	})
 }
 
+// runMCP runs cfg.RunMCP with Context cancelled on SIGINT or SIGTERM and returns the exit code.
+// The process is force-exited if RunMCP does not return within the shutdown grace period.
+func runMCP(cfg Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	rootCtx = ctx
+
+	gracePeriod := cfg.ShutdownGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultShutdownGracePeriod
+	}
+
+	err := runUntilShutdown(ctx, cfg.RunMCP, gracePeriod)
+	switch {
+	case errors.Is(err, errShutdownTimeout):
+		log.Printf("MCP server %s, forcing exit", err)
+		return 1
+	case err != nil && ctx.Err() != nil:
+		log.Printf("MCP server stopped on signal: %v", err)
+		return 1
+	case err != nil:
+		log.Printf("MCP server error: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// runUntilShutdown calls run and returns its error.
+// Once ctx is done, run has gracePeriod to return before runUntilShutdown gives up with
+// errShutdownTimeout, leaving run behind.
+func runUntilShutdown(ctx context.Context, run func() error, gracePeriod time.Duration) error {
+	done := make(chan error, 1)
+	go func() { done <- run() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errShutdownTimeout
+	}
+}
+
+// parseConfigFlag extracts "--config <path>" or "--config=<path>" from args.
+// It returns the absolute config path ("" when the flag is absent) and the remaining args.
+// The config file must exist.
+func parseConfigFlag(args []string) (string, []string, error) {
+	var path string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--config" {
+			if i+1 >= len(args) {
+				return "", nil, errors.New("--config requires a path argument")
+			}
+			path = args[i+1]
+			i++ // Skip the path
+			continue
+		}
+		if val, ok := strings.CutPrefix(arg, "--config="); ok {
+			if val == "" {
+				return "", nil, errors.New("--config requires a path argument")
+			}
+			path = val
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	if path == "" {
+		return "", rest, nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving --config path %q: %w", path, err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("--config file %q: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", nil, fmt.Errorf("--config file %q is a directory", path)
+	}
+
+	return absPath, rest, nil
+}
+
 // handleDocsCommand processes the docs subcommand and returns the exit code.
-// It supports list, get <name>, and validate subcommands.
-func handleDocsCommand(cfg *enginedocs.Config, args []string) int {
+// It supports list, get <name> [--text], validate and verify subcommands.
+// setupMCP provides the MCP server whose tools are verified; verify fails without it.
+// The --offline flag disables remote fetches in favor of cached and embedded docs.
+func handleDocsCommand(cfg *enginedocs.Config, setupMCP func() (*mcpserver.Server, error), args []string) int {
+	if offline := slices.Index(args, "--offline"); offline >= 0 {
+		offlineCfg := *cfg
+		offlineCfg.Offline = true
+		cfg = &offlineCfg
+		args = slices.Delete(slices.Clone(args), offline, offline+1)
+	}
+
 	switch {
 	case len(args) == 0 || args[0] == "list":
 		docs, err := enginedocs.DocsList(*cfg)
@@ -145,6 +343,9 @@ func handleDocsCommand(cfg *enginedocs.Config, args []string) int {
 			fmt.Fprintf(os.Stderr, "Error getting doc: %v\n", err)
 			return 1
 		}
+		if len(args) > 2 && args[2] == "--text" {
+			content = enginedocs.RenderPlainText(content)
+		}
 		fmt.Print(content)
 		return 0
 
@@ -160,13 +361,39 @@ func handleDocsCommand(cfg *enginedocs.Config, args []string) int {
 		}
 		return 1
 
+	case args[0] == "verify":
+		if setupMCP == nil {
+			fmt.Fprintln(os.Stderr, "Error: docs verify is not supported: no MCP server to verify against")
+			return 1
+		}
+		server, err := setupMCP()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up MCP server: %v\n", err)
+			return 1
+		}
+		errs := enginedocs.Verify(*cfg, server)
+		if len(errs) == 0 {
+			fmt.Println("Verification passed: docs match the registered tools")
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Verification failed with %d error(s):\n", len(errs))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "  - %v\n", err)
+		}
+		return 1
+
 	default:
-		fmt.Fprintln(os.Stderr, "Usage: <command> docs [list|get <name>|validate]")
+		fmt.Fprintln(os.Stderr, "Usage: <command> docs [--offline] [list|get <name> [--text]|validate|verify]")
 		fmt.Fprintln(os.Stderr, "")
 		fmt.Fprintln(os.Stderr, "Subcommands:")
 		fmt.Fprintln(os.Stderr, "  list              List all available documentation")
 		fmt.Fprintln(os.Stderr, "  get <name>        Get the content of a specific document")
+		fmt.Fprintln(os.Stderr, "    --text          Render the document as plain text")
 		fmt.Fprintln(os.Stderr, "  validate          Validate documentation completeness")
+		fmt.Fprintln(os.Stderr, "  verify            Verify documentation against the registered MCP tools")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fmt.Fprintln(os.Stderr, "  --offline         Do not fetch remote docs; use cached or embedded copies")
 		return 1
 	}
 }
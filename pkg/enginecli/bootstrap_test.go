@@ -17,8 +17,14 @@
 package enginecli
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestConfigValidation tests that Config struct accepts all required fields.
@@ -210,3 +216,165 @@ func TestBootstrapIntegration(t *testing.T) {
 	// 2. Check --mcp flag -> RunMCP() + os.Exit based on error
 	// 3. Run CLI mode -> RunCLI() + handlers + os.Exit based on error
 }
+
+// TestParseConfigFlag tests extracting --config from the command line.
+func TestParseConfigFlag(t *testing.T) {
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "forge.yaml")
+	if err := os.WriteFile(cfgFile, []byte("name: test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPath string
+		wantRest []string
+		wantErr  string
+	}{
+		{name: "no flag", args: []string{"build", "--input", "in.json"}, wantRest: []string{"build", "--input", "in.json"}},
+		{name: "separate value", args: []string{"--config", cfgFile, "--mcp"}, wantPath: cfgFile, wantRest: []string{"--mcp"}},
+		{name: "equals value", args: []string{"build", "--config=" + cfgFile}, wantPath: cfgFile, wantRest: []string{"build"}},
+		{name: "missing value", args: []string{"--config"}, wantErr: "requires a path"},
+		{name: "empty value", args: []string{"--config="}, wantErr: "requires a path"},
+		{name: "missing file", args: []string{"--config", filepath.Join(dir, "missing.yaml")}, wantErr: "missing.yaml"},
+		{name: "directory", args: []string{"--config", dir}, wantErr: "is a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, rest, err := parseConfigFlag(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("rest = %v, want %v", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+// TestParseConfigFlag_RelativePath tests that a relative --config path is made absolute.
+func TestParseConfigFlag_RelativePath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "custom.yaml"), []byte("name: test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	path, _, err := parseConfigFlag([]string{"--config", "custom.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !filepath.IsAbs(path) || filepath.Base(path) != "custom.yaml" {
+		t.Errorf("expected absolute path to custom.yaml, got %q", path)
+	}
+}
+
+// TestConfigPathAndRootDir tests the accessors of the --config flag.
+func TestConfigPathAndRootDir(t *testing.T) {
+	saved := configPath
+	t.Cleanup(func() { configPath = saved })
+
+	configPath = ""
+	if ConfigPath() != "" || RootDir() != "" {
+		t.Errorf("expected empty ConfigPath and RootDir without --config, got %q and %q", ConfigPath(), RootDir())
+	}
+
+	configPath = "/work/project/configs/forge.yaml"
+	if got := ConfigPath(); got != "/work/project/configs/forge.yaml" {
+		t.Errorf("ConfigPath() = %q", got)
+	}
+	if got := RootDir(); got != "/work/project/configs" {
+		t.Errorf("RootDir() = %q, want /work/project/configs", got)
+	}
+}
+
+// TestRunUntilShutdown_Cancel tests that a run function observing the context returns
+// promptly once the context is cancelled.
+func TestRunUntilShutdown_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	run := func() error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- runUntilShutdown(ctx, run, 5*time.Second) }()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled from run, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("runUntilShutdown did not return promptly after cancellation")
+	}
+}
+
+// TestRunUntilShutdown_GracePeriodExpires tests that a run function ignoring the context
+// is abandoned after the grace period.
+func TestRunUntilShutdown_GracePeriodExpires(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	run := func() error {
+		<-block
+		return nil
+	}
+
+	start := time.Now()
+	err := runUntilShutdown(ctx, run, 50*time.Millisecond)
+	if !errors.Is(err, errShutdownTimeout) {
+		t.Fatalf("expected errShutdownTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected force exit after the grace period, took %s", elapsed)
+	}
+}
+
+// TestRunUntilShutdown_NoSignal tests that the result of run is returned when it completes on its own.
+func TestRunUntilShutdown_NoSignal(t *testing.T) {
+	wantErr := errors.New("server failed")
+	err := runUntilShutdown(context.Background(), func() error { return wantErr }, time.Second)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("expected %v, got %v", wantErr, err)
+	}
+}
+
+// TestContext_DefaultsToBackground tests that Context is usable outside of Bootstrap.
+func TestContext_DefaultsToBackground(t *testing.T) {
+	if Context() == nil || Context().Err() != nil {
+		t.Error("expected a live context outside of Bootstrap")
+	}
+}
+
+// TestOnExit tests that exit hooks run once, in reverse order of registration.
+func TestOnExit(t *testing.T) {
+	var order []string
+	OnExit(func() { order = append(order, "first") })
+	OnExit(func() { order = append(order, "second") })
+
+	runExitHooks()
+	runExitHooks()
+
+	if want := []string{"second", "first"}; !slices.Equal(order, want) {
+		t.Errorf("hooks ran as %v, want %v", order, want)
+	}
+}
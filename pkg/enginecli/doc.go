@@ -18,7 +18,9 @@
 // by providing a unified bootstrap mechanism that handles:
 //   - Version information initialization from ldflags
 //   - Version flag handling (--version, -v, version)
-//   - MCP server mode handling (--mcp flag)
+//   - Config flag handling (--config <path>), exposed via ConfigPath and RootDir
+//   - MCP server mode handling (--mcp flag), with graceful shutdown on SIGINT and SIGTERM
+//     through the context returned by Context
 //   - Standardized error handling and exit codes
 //
 // Example usage:
@@ -53,7 +55,7 @@
 //	}
 //
 //	func runMCP() error {
-//	    // Command-specific MCP server logic
-//	    return nil
+//	    // Command-specific MCP server logic, stopped when enginecli.Context() is cancelled
+//	    return server.Run(enginecli.Context())
 //	}
 package enginecli
@@ -18,12 +18,18 @@ package enginecli
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/alexandremahdhaoui/forge/pkg/enginedocs"
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -115,7 +121,7 @@ func TestHandleDocsCommand_List(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{})
+			exitCode := handleDocsCommand(cfg, nil, []string{})
 			assert.Equal(t, 0, exitCode)
 		})
 
@@ -137,7 +143,7 @@ func TestHandleDocsCommand_List(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"list"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"list"})
 			assert.Equal(t, 0, exitCode)
 		})
 
@@ -154,7 +160,7 @@ func TestHandleDocsCommand_List(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"list"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"list"})
 			assert.Equal(t, 1, exitCode)
 		})
 
@@ -177,7 +183,7 @@ func TestHandleDocsCommand_Get(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"get", "usage"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"get", "usage"})
 			assert.Equal(t, 0, exitCode)
 		})
 
@@ -197,7 +203,7 @@ func TestHandleDocsCommand_Get(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"get", "nonexistent"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"get", "nonexistent"})
 			assert.Equal(t, 1, exitCode)
 		})
 
@@ -217,7 +223,7 @@ func TestHandleDocsCommand_Get(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"get"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"get"})
 			assert.Equal(t, 1, exitCode)
 		})
 
@@ -240,7 +246,7 @@ func TestHandleDocsCommand_Validate(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"validate"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"validate"})
 			assert.Equal(t, 0, exitCode)
 		})
 
@@ -269,7 +275,7 @@ docs: []
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"validate"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"validate"})
 			assert.Equal(t, 1, exitCode)
 		})
 
@@ -300,7 +306,7 @@ docs: []
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"validate"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"validate"})
 			assert.Equal(t, 1, exitCode)
 		})
 
@@ -311,6 +317,117 @@ docs: []
 	})
 }
 
+func TestHandleDocsCommand_Verify(t *testing.T) {
+	// Cannot be parallel because changes working directory
+
+	// setupMCP returns a server with the docs tools and the given tools registered
+	setupMCP := func(cfg *enginedocs.Config, tools ...string) func() (*mcpserver.Server, error) {
+		return func() (*mcpserver.Server, error) {
+			server := mcpserver.New("test-engine", "1.0.0")
+			for _, name := range tools {
+				mcpserver.RegisterTool(server, &mcp.Tool{Name: name}, func(ctx context.Context, req *mcp.CallToolRequest, input any) (*mcp.CallToolResult, any, error) {
+					return &mcp.CallToolResult{}, nil, nil
+				})
+			}
+			return server, enginedocs.RegisterDocsTools(server, *cfg)
+		}
+	}
+
+	writeDocs := func(t *testing.T, tmpDir string) *enginedocs.Config {
+		docsDir := createTestDocsDir(t, tmpDir)
+		listYAML := `version: "1.0"
+engine: test-engine
+docs:
+  - name: usage
+    title: Usage Guide
+    description: How to use test-engine
+    url: docs/usage.md
+    tools: [build]
+`
+		require.NoError(t, os.WriteFile(filepath.Join(docsDir, "list.yaml"), []byte(listYAML), 0o644))
+		return &enginedocs.Config{
+			EngineName:   "test-engine",
+			LocalDir:     docsDir,
+			RequiredDocs: []string{"usage"},
+		}
+	}
+
+	t.Run("verify passes when docs match the registered tools", func(t *testing.T) {
+		tmpDir, cleanup := setupTestWithCwd(t)
+		defer cleanup()
+
+		cfg := writeDocs(t, tmpDir)
+
+		stdout, stderr := captureOutput(t, func() {
+			exitCode := handleDocsCommand(cfg, setupMCP(cfg, "build"), []string{"verify"})
+			assert.Equal(t, 0, exitCode)
+		})
+
+		assert.Contains(t, stdout, "Verification passed")
+		assert.Empty(t, stderr)
+	})
+
+	t.Run("verify reports undocumented and stale tools", func(t *testing.T) {
+		tmpDir, cleanup := setupTestWithCwd(t)
+		defer cleanup()
+
+		cfg := writeDocs(t, tmpDir)
+
+		stdout, stderr := captureOutput(t, func() {
+			exitCode := handleDocsCommand(cfg, setupMCP(cfg, "buildBatch"), []string{"verify"})
+			assert.Equal(t, 1, exitCode)
+		})
+
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "Verification failed with 2 error(s)")
+		assert.Contains(t, stderr, `tool "buildBatch" is registered but not documented`)
+		assert.Contains(t, stderr, `documents tool "build" which is not registered`)
+	})
+
+	t.Run("verify fails without an MCP server", func(t *testing.T) {
+		tmpDir, cleanup := setupTestWithCwd(t)
+		defer cleanup()
+
+		cfg := writeDocs(t, tmpDir)
+
+		stdout, stderr := captureOutput(t, func() {
+			exitCode := handleDocsCommand(cfg, nil, []string{"verify"})
+			assert.Equal(t, 1, exitCode)
+		})
+
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "docs verify is not supported")
+	})
+}
+
+func TestHandleDocsCommand_Offline(t *testing.T) {
+	// Not parallel: captures the process stdout
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected remote fetch in offline mode: %s", r.URL)
+		http.NotFound(w, r)
+	}))
+	defer remote.Close()
+
+	cfg := &enginedocs.Config{
+		EngineName: "test-engine",
+		LocalDir:   filepath.Join(t.TempDir(), "docs"),
+		BaseURL:    remote.URL,
+		EmbeddedFS: fstest.MapFS{
+			"docs/list.yaml": {Data: []byte("version: \"1.0\"\nengine: test-engine\ndocs:\n  - name: usage\n    title: Usage Guide\n    description: How to use test-engine\n    url: docs/usage.md\n")},
+			"docs/usage.md":  {Data: []byte("# Embedded usage guide")},
+		},
+	}
+
+	stdout, _ := captureOutput(t, func() {
+		exitCode := handleDocsCommand(cfg, nil, []string{"--offline", "get", "usage"})
+		assert.Equal(t, 0, exitCode)
+	})
+
+	assert.Equal(t, "# Embedded usage guide", stdout)
+	assert.False(t, cfg.Offline, "the --offline flag must not alter the shared config")
+}
+
 func TestHandleDocsCommand_Usage(t *testing.T) {
 	t.Parallel()
 
@@ -323,7 +440,7 @@ func TestHandleDocsCommand_Usage(t *testing.T) {
 		}
 
 		stdout, stderr := captureOutput(t, func() {
-			exitCode := handleDocsCommand(cfg, []string{"unknown"})
+			exitCode := handleDocsCommand(cfg, nil, []string{"unknown"})
 			assert.Equal(t, 1, exitCode)
 		})
 
@@ -332,6 +449,7 @@ func TestHandleDocsCommand_Usage(t *testing.T) {
 		assert.Contains(t, stderr, "list")
 		assert.Contains(t, stderr, "get <name>")
 		assert.Contains(t, stderr, "validate")
+		assert.Contains(t, stderr, "verify")
 	})
 }
 
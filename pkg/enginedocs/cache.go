@@ -0,0 +1,115 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// userCacheDir resolves the user cache directory. It is a variable so tests can disable
+// the default cache location.
+var userCacheDir = os.UserCacheDir
+
+// cacheDir returns the directory caching remotely fetched docs, or "" when caching is unavailable.
+func (cfg Config) cacheDir() string {
+	if cfg.CacheDir != "" {
+		return cfg.CacheDir
+	}
+
+	dir, err := userCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "forge", "enginedocs")
+}
+
+// cachePath returns the cache file path for the given URL.
+func cachePath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:]))
+}
+
+// writeCache atomically stores content fetched from url in the cache directory.
+func writeCache(dir, url, content string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), cachePath(dir, url)); err != nil {
+		return fmt.Errorf("failed to rename cache file: %w", err)
+	}
+
+	return nil
+}
+
+// fetchRemote fetches url and caches the result. When the remote fetch fails (or is skipped
+// in offline mode), it logs a warning and returns the cached copy of a previous fetch, or
+// else the embedded copy of the file name.
+func fetchRemote(cfg Config, url, name string) (string, error) {
+	dir := cfg.cacheDir()
+
+	err := errOffline
+	if !cfg.Offline {
+		var content string
+		content, err = fetchURL(url)
+		if err == nil {
+			if dir != "" {
+				if cacheErr := writeCache(dir, url, content); cacheErr != nil {
+					log.Printf("Warning: failed to cache docs fetched from %s: %v", url, cacheErr)
+				}
+			}
+			return content, nil
+		}
+	}
+
+	reason := fmt.Sprintf("Warning: failed to fetch %s (%v)", url, err)
+	if cfg.Offline {
+		reason = "Offline mode"
+	}
+
+	if dir != "" {
+		if cached, cacheErr := os.ReadFile(cachePath(dir, url)); cacheErr == nil {
+			log.Printf("%s; using cached copy of %s", reason, url)
+			return string(cached), nil
+		}
+	}
+
+	if embedded, embeddedErr := readEmbedded(cfg, name); embeddedErr == nil {
+		log.Printf("%s; using embedded copy of %s", reason, name)
+		return embedded, nil
+	}
+
+	return "", err
+}
@@ -0,0 +1,176 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// Keep tests hermetic: only tests setting Config.CacheDir use a cache.
+	userCacheDir = func() (string, error) { return "", errors.New("user cache disabled in tests") }
+	os.Exit(m.Run())
+}
+
+func TestFetchDocStore_CacheFallback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cached docs list used when remote unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		remoteYAML := `version: "1.0"
+engine: remote-engine
+docs:
+  - name: remote-doc
+    title: Remote Doc
+    description: From remote
+    url: docs/remote.md
+`
+		online := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !online {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(remoteYAML))
+		}))
+		defer server.Close()
+
+		cfg := Config{
+			EngineName: "remote-engine",
+			LocalDir:   "non-existent-local",
+			BaseURL:    server.URL,
+			CacheDir:   t.TempDir(),
+		}
+
+		store, err := FetchDocStore(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "remote-engine", store.Engine)
+
+		online = false
+
+		store, err = FetchDocStore(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "remote-engine", store.Engine)
+		require.Len(t, store.Docs, 1)
+		assert.Equal(t, "remote-doc", store.Docs[0].Name)
+	})
+
+	t.Run("error when remote unreachable and nothing cached", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		cfg := Config{
+			EngineName: "remote-engine",
+			LocalDir:   "non-existent-local",
+			BaseURL:    server.URL,
+			CacheDir:   t.TempDir(),
+		}
+
+		_, err := FetchDocStore(cfg)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch remote docs list")
+	})
+}
+
+func TestDocsGet_CacheFallback(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	docsDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.MkdirAll(docsDir, 0o755))
+
+	remoteDocContent := "# Remote Usage Guide\n\nFrom remote server."
+	online := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(remoteDocContent))
+	}))
+	defer server.Close()
+
+	listYAML := `version: "1.0"
+engine: test-engine
+docs:
+  - name: usage
+    title: Usage Guide
+    description: How to use
+    url: docs/remote-usage.md
+`
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "list.yaml"), []byte(listYAML), 0o644))
+
+	cfg := Config{
+		EngineName: "test-engine",
+		LocalDir:   docsDir,
+		BaseURL:    server.URL,
+		CacheDir:   filepath.Join(tmpDir, "cache"),
+	}
+
+	content, err := DocsGet(cfg, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, remoteDocContent, content)
+
+	online = false
+
+	content, err = DocsGet(cfg, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, remoteDocContent, content)
+}
+
+func TestFetchRemote_RefreshesCache(t *testing.T) {
+	t.Parallel()
+
+	body := "v1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	cfg := Config{CacheDir: cacheDir}
+
+	_, err := fetchRemote(cfg, server.URL, "usage.md")
+	require.NoError(t, err)
+
+	body = "v2"
+	content, err := fetchRemote(cfg, server.URL, "usage.md")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", content)
+
+	cached, err := os.ReadFile(cachePath(cacheDir, server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(cached))
+}
+
+func TestConfigCacheDir(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/tmp/docs-cache", Config{CacheDir: "/tmp/docs-cache"}.cacheDir())
+	assert.Equal(t, "", Config{}.cacheDir(), "user cache is disabled in tests")
+}
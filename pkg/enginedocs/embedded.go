@@ -0,0 +1,54 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// errOffline is the fetch error reported when remote fetches are disabled.
+var errOffline = errors.New("offline mode")
+
+// embeddedPath returns the path of the file name in cfg.EmbeddedFS.
+func embeddedPath(cfg Config, name string) string {
+	return path.Join(filepath.ToSlash(filepath.Base(cfg.LocalDir)), name)
+}
+
+// readEmbedded returns the content of the file name of the docs directory embedded in cfg.EmbeddedFS.
+func readEmbedded(cfg Config, name string) (string, error) {
+	if cfg.EmbeddedFS == nil {
+		return "", errors.New("no embedded docs")
+	}
+
+	content, err := fs.ReadFile(cfg.EmbeddedFS, embeddedPath(cfg, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to read embedded doc %s: %w", name, err)
+	}
+
+	return string(content), nil
+}
+
+// hasEmbedded reports whether the file name is embedded in cfg.EmbeddedFS.
+func hasEmbedded(cfg Config, name string) bool {
+	if cfg.EmbeddedFS == nil {
+		return false
+	}
+	_, err := fs.Stat(cfg.EmbeddedFS, embeddedPath(cfg, name))
+	return err == nil
+}
@@ -0,0 +1,141 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const embeddedListYAML = `version: "1.0"
+engine: test-engine
+docs:
+  - name: usage
+    title: Usage Guide
+    description: How to use test-engine
+    url: cmd/test-engine/docs/usage.md
+`
+
+// newEmbeddedConfig returns a config without local docs whose remote is served by handler
+// and whose docs directory is embedded.
+func newEmbeddedConfig(t *testing.T, handler http.HandlerFunc) Config {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return Config{
+		EngineName: "test-engine",
+		LocalDir:   filepath.Join(t.TempDir(), "cmd", "test-engine", "docs"),
+		BaseURL:    server.URL,
+		EmbeddedFS: fstest.MapFS{
+			"docs/list.yaml": {Data: []byte(embeddedListYAML)},
+			"docs/usage.md":  {Data: []byte("# Embedded usage\n")},
+		},
+	}
+}
+
+// failingRemote simulates an unreachable docs remote.
+func failingRemote(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, "unavailable", http.StatusServiceUnavailable)
+}
+
+func TestFetchDocStore_EmbeddedFallbackOnFetchFailure(t *testing.T) {
+	t.Parallel()
+
+	cfg := newEmbeddedConfig(t, failingRemote)
+
+	store, err := FetchDocStore(cfg)
+	require.NoError(t, err)
+	require.Len(t, store.Docs, 1)
+	assert.Equal(t, "usage", store.Docs[0].Name)
+}
+
+func TestDocsGet_EmbeddedFallbackOnFetchFailure(t *testing.T) {
+	t.Parallel()
+
+	cfg := newEmbeddedConfig(t, failingRemote)
+
+	content, err := DocsGet(cfg, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, "# Embedded usage\n", content)
+}
+
+func TestDocsGet_RemotePreferredOverEmbedded(t *testing.T) {
+	t.Parallel()
+
+	cfg := newEmbeddedConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Base(r.URL.Path) == listFileName {
+			_, _ = w.Write([]byte(embeddedListYAML))
+			return
+		}
+		_, _ = w.Write([]byte("# Remote usage\n"))
+	})
+
+	content, err := DocsGet(cfg, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, "# Remote usage\n", content)
+}
+
+func TestDocsGet_OfflineServesEmbeddedWithoutFetching(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	cfg := newEmbeddedConfig(t, func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("# Remote usage\n"))
+	})
+	cfg.Offline = true
+
+	content, err := DocsGet(cfg, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, "# Embedded usage\n", content)
+	assert.Zero(t, requests.Load(), "offline mode must not fetch remote docs")
+
+	listings, err := DocsListWithSources(cfg)
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	assert.Equal(t, DocSourceEmbedded, listings[0].Source)
+}
+
+func TestDocsGet_EmbeddedWithoutBaseURL(t *testing.T) {
+	t.Parallel()
+
+	cfg := newEmbeddedConfig(t, failingRemote)
+	cfg.BaseURL = ""
+
+	content, err := DocsGet(cfg, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, "# Embedded usage\n", content)
+}
+
+func TestDocsGet_FetchFailureWithoutEmbeddedCopy(t *testing.T) {
+	t.Parallel()
+
+	cfg := newEmbeddedConfig(t, failingRemote)
+	cfg.EmbeddedFS = fstest.MapFS{"docs/list.yaml": {Data: []byte(embeddedListYAML)}}
+
+	_, err := DocsGet(cfg, "usage")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch remote document")
+}
@@ -34,7 +34,9 @@ const (
 
 // FetchDocStore retrieves the DocStore for an engine using local-first, remote-fallback logic.
 // It first attempts to read from {cfg.LocalDir}/list.yaml. If the local file does not exist
-// and cfg.BaseURL is set, it fetches from {cfg.BaseURL}/{cfg.LocalDir}/list.yaml.
+// and cfg.BaseURL is set, it fetches from {cfg.BaseURL}/{cfg.LocalDir}/list.yaml, falling
+// back to the cached copy of a previous fetch, then to the embedded copy (cfg.EmbeddedFS),
+// when the remote is unreachable or cfg.Offline is set.
 func FetchDocStore(cfg Config) (*DocStore, error) {
 	localPath := filepath.Join(cfg.LocalDir, listFileName)
 
@@ -53,15 +55,23 @@ func FetchDocStore(cfg Config) (*DocStore, error) {
 		return nil, fmt.Errorf("failed to read local docs list at %s: %w", localPath, err)
 	}
 
-	// Local file doesn't exist - try remote if BaseURL is configured
+	// Local file doesn't exist - try remote if BaseURL is configured, else the embedded copy
 	if cfg.BaseURL == "" {
-		return nil, fmt.Errorf("local docs list not found at %s and no BaseURL configured for remote fetch", localPath)
+		embedded, embeddedErr := readEmbedded(cfg, listFileName)
+		if embeddedErr != nil {
+			return nil, fmt.Errorf("local docs list not found at %s and no BaseURL configured for remote fetch", localPath)
+		}
+		var store DocStore
+		if err := yaml.Unmarshal([]byte(embedded), &store); err != nil {
+			return nil, fmt.Errorf("failed to parse embedded docs list: %w", err)
+		}
+		return &store, nil
 	}
 
 	// Construct remote URL: {BaseURL}/{LocalDir}/list.yaml
 	remoteURL := fmt.Sprintf("%s/%s/%s", cfg.BaseURL, cfg.LocalDir, listFileName)
 
-	remoteContent, err := fetchURL(remoteURL)
+	remoteContent, err := fetchRemote(cfg, remoteURL, listFileName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch remote docs list from %s: %w", remoteURL, err)
 	}
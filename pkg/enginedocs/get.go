@@ -17,6 +17,7 @@ package enginedocs
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 // DocsGet retrieves the content of a specific document by name.
@@ -26,9 +27,12 @@ import (
 // The function:
 // 1. Calls FetchDocStore(cfg) to get the registry
 // 2. Finds the entry where DocEntry.Name matches the provided name
-// 3. If not found, returns an error guiding the user to run 'docs list'
+// 3. If not found, looks for an extra local doc ({cfg.LocalDir}/{name}.md) not referenced by the registry,
+// and otherwise returns an error guiding the user to run 'docs list'
 // 4. Tries reading the local file at DocEntry.URL (relative path from repo root)
-// 5. If local file is missing and BaseURL is set, fetches from {cfg.BaseURL}/{entry.URL}
+// 5. If local file is missing and BaseURL is set, fetches from {cfg.BaseURL}/{entry.URL},
+// falling back to the cached copy of a previous fetch, then to the embedded copy
+// (cfg.EmbeddedFS), when the remote is unreachable or cfg.Offline is set
 // 6. Returns the content as a string
 func DocsGet(cfg Config, name string) (string, error) {
 	// Fetch the documentation store
@@ -46,30 +50,50 @@ func DocsGet(cfg Config, name string) (string, error) {
 		}
 	}
 
+	// Fall back to extra local docs that the registry does not reference
+	if doc == nil {
+		listedFiles := make(map[string]bool, len(store.Docs))
+		for _, entry := range store.Docs {
+			listedFiles[filepath.Base(entry.URL)] = true
+		}
+		for _, entry := range extraLocalDocs(cfg, listedFiles) {
+			if entry.Name == name {
+				doc = &entry
+				break
+			}
+		}
+	}
+
 	if doc == nil {
 		return "", fmt.Errorf("document not found: %s\nRun 'docs list' to see available documentation", name)
 	}
 
 	// Try to read from local file first (DocEntry.URL is relative to repo root)
-	content, err := os.ReadFile(doc.URL)
+	localPath := localDocPath(cfg, *doc)
+	content, err := os.ReadFile(localPath)
 	if err == nil {
 		return string(content), nil
 	}
 
 	// If local file doesn't exist and we have a BaseURL, try remote
 	if !os.IsNotExist(err) {
-		return "", fmt.Errorf("failed to read local document at %s: %w", doc.URL, err)
+		return "", fmt.Errorf("failed to read local document at %s: %w", localPath, err)
 	}
 
-	// Local file doesn't exist - try remote if BaseURL is configured
+	// Local file doesn't exist - try remote if BaseURL is configured, else the embedded copy
+	fileName := filepath.Base(localPath)
 	if cfg.BaseURL == "" {
-		return "", fmt.Errorf("local document not found at %s and no BaseURL configured for remote fetch", doc.URL)
+		embedded, embeddedErr := readEmbedded(cfg, fileName)
+		if embeddedErr != nil {
+			return "", fmt.Errorf("local document not found at %s and no BaseURL configured for remote fetch", localPath)
+		}
+		return embedded, nil
 	}
 
 	// Construct remote URL: {BaseURL}/{entry.URL}
 	remoteURL := fmt.Sprintf("%s/%s", cfg.BaseURL, doc.URL)
 
-	remoteContent, err := fetchURL(remoteURL)
+	remoteContent, err := fetchRemote(cfg, remoteURL, fileName)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch remote document from %s: %w", remoteURL, err)
 	}
@@ -14,6 +14,33 @@
 
 package enginedocs
 
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DocSource describes where the content of a documentation entry is read from.
+type DocSource string
+
+const (
+	// DocSourceLocal means the document is read from the local filesystem.
+	DocSourceLocal DocSource = "local"
+	// DocSourceRemote means the document is fetched from the configured BaseURL.
+	DocSourceRemote DocSource = "remote"
+	// DocSourceEmbedded means the document is read from the copy embedded at build time.
+	DocSourceEmbedded DocSource = "embedded"
+	// DocSourceMissing means the document is neither available locally nor remotely.
+	DocSourceMissing DocSource = "missing"
+)
+
+// DocListing is a documentation entry annotated with its source.
+type DocListing struct {
+	DocEntry
+	// Source is where the document content is read from
+	Source DocSource `json:"source"`
+}
+
 // DocsList returns all documentation entries for an engine.
 // It loads the documentation registry using FetchDocStore and returns the Docs array.
 func DocsList(cfg Config) ([]DocEntry, error) {
@@ -23,3 +50,90 @@ func DocsList(cfg Config) ([]DocEntry, error) {
 	}
 	return store.Docs, nil
 }
+
+// DocsListWithSources returns the documentation available for an engine with the source of each entry.
+// The listing contains:
+//  1. every entry of the registry, flagged as required when named in cfg.RequiredDocs
+//  2. required docs missing from the registry, with source "missing"
+//  3. extra markdown files in cfg.LocalDir that the registry does not reference
+func DocsListWithSources(cfg Config) ([]DocListing, error) {
+	store, err := FetchDocStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	required := make(map[string]bool, len(cfg.RequiredDocs))
+	for _, name := range cfg.RequiredDocs {
+		required[name] = true
+	}
+
+	listed := make(map[string]bool, len(store.Docs))
+	listedFiles := make(map[string]bool, len(store.Docs))
+	listings := make([]DocListing, 0, len(store.Docs))
+	for _, doc := range store.Docs {
+		listed[doc.Name] = true
+		listedFiles[filepath.Base(doc.URL)] = true
+		doc.Required = doc.Required || required[doc.Name]
+		listings = append(listings, DocListing{DocEntry: doc, Source: docSource(cfg, localDocPath(cfg, doc))})
+	}
+
+	for _, name := range cfg.RequiredDocs {
+		if !listed[name] {
+			listed[name] = true
+			listings = append(listings, DocListing{
+				DocEntry: DocEntry{Name: name, Required: true},
+				Source:   DocSourceMissing,
+			})
+		}
+	}
+
+	for _, doc := range extraLocalDocs(cfg, listedFiles) {
+		if !listed[doc.Name] {
+			listed[doc.Name] = true
+			listings = append(listings, DocListing{DocEntry: doc, Source: DocSourceLocal})
+		}
+	}
+
+	return listings, nil
+}
+
+// docSource determines where the document at localPath (relative to the repository root) is read from.
+func docSource(cfg Config, localPath string) DocSource {
+	if _, err := os.Stat(localPath); err == nil {
+		return DocSourceLocal
+	}
+	if cfg.BaseURL != "" && !cfg.Offline {
+		return DocSourceRemote
+	}
+	if hasEmbedded(cfg, filepath.Base(localPath)) {
+		return DocSourceEmbedded
+	}
+	if cfg.BaseURL != "" {
+		// Offline: served from the cache of a previous fetch, if any
+		return DocSourceRemote
+	}
+	return DocSourceMissing
+}
+
+// extraLocalDocs returns the markdown files of cfg.LocalDir whose file name is not in listedFiles.
+// Each document is named after its file name without the .md extension.
+func extraLocalDocs(cfg Config, listedFiles map[string]bool) []DocEntry {
+	entries, err := os.ReadDir(cfg.LocalDir)
+	if err != nil {
+		return nil
+	}
+
+	var docs []DocEntry
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" || listedFiles[entry.Name()] {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		docs = append(docs, DocEntry{
+			Name:  name,
+			Title: name,
+			URL:   filepath.Join(cfg.LocalDir, entry.Name()),
+		})
+	}
+	return docs
+}
@@ -160,3 +160,82 @@ docs:
 		assert.True(t, docs[0].Required)
 	})
 }
+
+func TestDocsListWithSources(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	docsDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.MkdirAll(docsDir, 0o755))
+
+	usagePath := filepath.Join(docsDir, "usage.md")
+	require.NoError(t, os.WriteFile(usagePath, []byte("# Usage"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "troubleshooting.md"), []byte("# Troubleshooting"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "notes.txt"), []byte("not a doc"), 0o644))
+
+	listYAML := `version: "1.0"
+engine: test-engine
+docs:
+  - name: usage
+    title: Usage Guide
+    description: How to use
+    url: ` + usagePath + `
+  - name: schema
+    title: Schema
+    description: Configuration schema
+    url: docs/schema.md
+`
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "list.yaml"), []byte(listYAML), 0o644))
+
+	t.Run("reflects required docs and extra local docs", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			EngineName:   "test-engine",
+			LocalDir:     docsDir,
+			BaseURL:      "https://example.com",
+			RequiredDocs: []string{"usage", "architecture"},
+		}
+
+		docs, err := DocsListWithSources(cfg)
+		require.NoError(t, err)
+
+		got := make(map[string]DocListing, len(docs))
+		names := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			got[doc.Name] = doc
+			names = append(names, doc.Name)
+		}
+		assert.Equal(t, []string{"usage", "schema", "architecture", "troubleshooting"}, names)
+
+		assert.Equal(t, DocSourceLocal, got["usage"].Source)
+		assert.True(t, got["usage"].Required)
+
+		assert.Equal(t, DocSourceRemote, got["schema"].Source)
+		assert.False(t, got["schema"].Required)
+
+		assert.Equal(t, DocSourceMissing, got["architecture"].Source)
+		assert.True(t, got["architecture"].Required)
+
+		assert.Equal(t, DocSourceLocal, got["troubleshooting"].Source)
+		assert.Equal(t, filepath.Join(docsDir, "troubleshooting.md"), got["troubleshooting"].URL)
+	})
+
+	t.Run("docs without local file or BaseURL are missing", func(t *testing.T) {
+		t.Parallel()
+
+		docs, err := DocsListWithSources(Config{EngineName: "test-engine", LocalDir: docsDir})
+		require.NoError(t, err)
+		require.Len(t, docs, 3)
+		assert.Equal(t, "schema", docs[1].Name)
+		assert.Equal(t, DocSourceMissing, docs[1].Source)
+	})
+
+	t.Run("extra local docs can be retrieved", func(t *testing.T) {
+		t.Parallel()
+
+		content, err := DocsGet(Config{EngineName: "test-engine", LocalDir: docsDir}, "troubleshooting")
+		require.NoError(t, err)
+		assert.Equal(t, "# Troubleshooting", content)
+	})
+}
@@ -32,7 +32,8 @@ type DocsListInput struct {
 
 // DocsGetInput represents the input parameters for the docs-get tool.
 type DocsGetInput struct {
-	Name string `json:"name" jsonschema:"Documentation entry name as returned by docs-list"`
+	Name   string `json:"name" jsonschema:"Documentation entry name as returned by docs-list"`
+	Format string `json:"format,omitempty" jsonschema:"Output format: markdown (default), text (plain text rendering) or both"`
 }
 
 // DocsValidateInput represents the input parameters for the docs-validate tool.
@@ -42,9 +43,9 @@ type DocsValidateInput struct {
 
 // DocsListResult represents the result of listing documentation.
 type DocsListResult struct {
-	Docs   []DocEntry `json:"docs"`
-	Engine string     `json:"engine"`
-	Count  int        `json:"count"`
+	Docs   []DocListing `json:"docs"`
+	Engine string       `json:"engine"`
+	Count  int          `json:"count"`
 }
 
 // DocsValidateResult represents the result of documentation validation.
@@ -56,14 +57,14 @@ type DocsValidateResult struct {
 
 // RegisterDocsTools registers the documentation MCP tools with the server.
 // This registers three tools:
-//  1. docs-list - lists all available documentation entries
+//  1. docs-list - lists all available documentation entries and their sources
 //  2. docs-get - retrieves specific documentation content by name
 //  3. docs-validate - validates documentation completeness (NEW functionality)
 func RegisterDocsTools(server *mcpserver.Server, cfg Config) error {
 	// Register docs-list tool
 	mcpserver.RegisterTool(server, &mcp.Tool{
 		Name:        "docs-list",
-		Description: fmt.Sprintf("List all available documentation entries for %s. Returns doc names that can be passed to docs-get.", cfg.EngineName),
+		Description: fmt.Sprintf("List all available documentation entries for %s with their source (local, remote or missing). Returns doc names that can be passed to docs-get.", cfg.EngineName),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DocsListInput) (*mcp.CallToolResult, any, error) {
 		return handleDocsListTool(ctx, req, input, cfg)
 	})
@@ -71,7 +72,7 @@ func RegisterDocsTools(server *mcpserver.Server, cfg Config) error {
 	// Register docs-get tool
 	mcpserver.RegisterTool(server, &mcp.Tool{
 		Name:        "docs-get",
-		Description: fmt.Sprintf("Retrieve the full content of a documentation entry for %s. Use docs-list first to discover available names.", cfg.EngineName),
+		Description: fmt.Sprintf("Retrieve the full content of a documentation entry for %s. Use docs-list first to discover available names. Set format to \"text\" for a plain text rendering.", cfg.EngineName),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DocsGetInput) (*mcp.CallToolResult, any, error) {
 		return handleDocsGetTool(ctx, req, input, cfg)
 	})
@@ -96,7 +97,7 @@ func handleDocsListTool(
 ) (*mcp.CallToolResult, any, error) {
 	log.Printf("Listing documentation for %s", cfg.EngineName)
 
-	docs, err := DocsList(cfg)
+	docs, err := DocsListWithSources(cfg)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -128,6 +129,20 @@ func handleDocsGetTool(
 ) (*mcp.CallToolResult, any, error) {
 	log.Printf("Getting documentation: %s for %s", input.Name, cfg.EngineName)
 
+	format := input.Format
+	if format == "" {
+		format = FormatMarkdown
+	}
+	if format != FormatMarkdown && format != FormatText && format != FormatBoth {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to get documentation: unknown format %q (expected %s, %s or %s)",
+					input.Format, FormatMarkdown, FormatText, FormatBoth)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
 	content, err := DocsGet(cfg, input.Name)
 	if err != nil {
 		return &mcp.CallToolResult{
@@ -138,10 +153,16 @@ func handleDocsGetTool(
 		}, nil, nil
 	}
 
+	var contents []mcp.Content
+	if format == FormatMarkdown || format == FormatBoth {
+		contents = append(contents, &mcp.TextContent{Text: content})
+	}
+	if format == FormatText || format == FormatBoth {
+		contents = append(contents, &mcp.TextContent{Text: RenderPlainText(content)})
+	}
+
 	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: content},
-		},
+		Content: contents,
 	}, nil, nil
 }
 
@@ -43,6 +43,12 @@ func TestRegisterDocsTools(t *testing.T) {
 
 		err := RegisterDocsTools(server, cfg)
 		assert.NoError(t, err)
+
+		var names []string
+		for _, tool := range mcpserver.RegisteredTools(server) {
+			names = append(names, tool.Name)
+		}
+		assert.Equal(t, []string{"docs-get", "docs-list", "docs-validate"}, names)
 	})
 
 	t.Run("registers tools with correct engine name in description", func(t *testing.T) {
@@ -57,6 +63,10 @@ func TestRegisterDocsTools(t *testing.T) {
 
 		err := RegisterDocsTools(server, cfg)
 		assert.NoError(t, err)
+
+		for _, tool := range mcpserver.RegisteredTools(server) {
+			assert.Contains(t, tool.Description, "my-custom-engine", "tool %s", tool.Name)
+		}
 	})
 }
 
@@ -100,8 +110,11 @@ func TestDocsListResult(t *testing.T) {
 		t.Parallel()
 
 		result := DocsListResult{
-			Docs: []DocEntry{
-				{Name: "usage", Title: "Usage", Description: "Usage guide", URL: "docs/usage.md"},
+			Docs: []DocListing{
+				{
+					DocEntry: DocEntry{Name: "usage", Title: "Usage", Description: "Usage guide", URL: "docs/usage.md"},
+					Source:   DocSourceLocal,
+				},
 			},
 			Engine: "test-engine",
 			Count:  1,
@@ -370,3 +383,62 @@ docs: []
 		assert.GreaterOrEqual(t, len(result.Content), 2)
 	})
 }
+
+func TestHandleDocsGetTool_Format(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	docsDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.MkdirAll(docsDir, 0o755))
+
+	docContent := "# Usage\n\n- **fast**"
+	docPath := filepath.Join(docsDir, "usage.md")
+	require.NoError(t, os.WriteFile(docPath, []byte(docContent), 0o644))
+
+	listYAML := `version: "1.0"
+engine: test-engine
+docs:
+  - name: usage
+    title: Usage Guide
+    description: How to use
+    url: ` + docPath + `
+`
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "list.yaml"), []byte(listYAML), 0o644))
+
+	cfg := Config{EngineName: "test-engine", LocalDir: docsDir}
+	plainText := "Usage\n=====\n\n- fast"
+
+	tests := []struct {
+		format  string
+		want    []string
+		wantErr bool
+	}{
+		{format: "", want: []string{docContent}},
+		{format: FormatMarkdown, want: []string{docContent}},
+		{format: FormatText, want: []string{plainText}},
+		{format: FormatBoth, want: []string{docContent, plainText}},
+		{format: "html", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("format="+tt.format, func(t *testing.T) {
+			t.Parallel()
+
+			input := DocsGetInput{Name: "usage", Format: tt.format}
+			result, _, err := handleDocsGetTool(context.Background(), &mcp.CallToolRequest{}, input, cfg)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantErr, result.IsError)
+			if tt.wantErr {
+				return
+			}
+
+			require.Len(t, result.Content, len(tt.want))
+			for i, want := range tt.want {
+				textContent, ok := result.Content[i].(*mcp.TextContent)
+				require.True(t, ok)
+				assert.Equal(t, want, textContent.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Doc output formats accepted by the docs-get tool.
+const (
+	// FormatMarkdown returns the raw markdown (default).
+	FormatMarkdown = "markdown"
+	// FormatText returns the document rendered as plain text.
+	FormatText = "text"
+	// FormatBoth returns the raw markdown followed by the plain text rendering.
+	FormatBoth = "both"
+)
+
+var (
+	headerRe        = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletRe        = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	ruleRe          = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	tableSepRe      = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	imageRe         = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRe          = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	inlineCodeRe    = regexp.MustCompile("`([^`]+)`")
+	strongRe        = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	emphasisRe      = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	strikethroughRe = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// RenderPlainText converts markdown into readable plain text.
+// Headers become underlined titles, bullets use "-", code blocks are indented,
+// links become "text (url)" and inline emphasis markers are removed.
+func RenderPlainText(markdown string) string {
+	var out []string
+	inCode := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, "    "+line)
+			continue
+		}
+
+		switch {
+		case headerRe.MatchString(line):
+			m := headerRe.FindStringSubmatch(line)
+			title := renderInline(m[2])
+			out = append(out, title)
+			switch len(m[1]) {
+			case 1:
+				out = append(out, strings.Repeat("=", len([]rune(title))))
+			case 2:
+				out = append(out, strings.Repeat("-", len([]rune(title))))
+			}
+		case ruleRe.MatchString(line):
+			out = append(out, "")
+		case strings.HasPrefix(trimmed, "|") && tableSepRe.MatchString(line):
+			// Drop table separator rows.
+		case strings.HasPrefix(trimmed, "|"):
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			for i := range cells {
+				cells[i] = renderInline(strings.TrimSpace(cells[i]))
+			}
+			out = append(out, strings.Join(cells, "  "))
+		case bulletRe.MatchString(line):
+			m := bulletRe.FindStringSubmatch(line)
+			out = append(out, fmt.Sprintf("%s- %s", m[1], renderInline(m[2])))
+		case strings.HasPrefix(trimmed, ">"):
+			out = append(out, renderInline(strings.TrimSpace(strings.TrimLeft(trimmed, ">"))))
+		default:
+			out = append(out, renderInline(line))
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// renderInline strips inline markdown markup from a single line.
+func renderInline(s string) string {
+	// Protect inline code spans so their content is left untouched.
+	var spans []string
+	s = inlineCodeRe.ReplaceAllStringFunc(s, func(m string) string {
+		spans = append(spans, inlineCodeRe.FindStringSubmatch(m)[1])
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+
+	s = imageRe.ReplaceAllString(s, "$1")
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := linkRe.FindStringSubmatch(m)
+		if sub[1] == sub[2] {
+			return sub[1]
+		}
+		return fmt.Sprintf("%s (%s)", sub[1], sub[2])
+	})
+	s = strongRe.ReplaceAllString(s, "$1")
+	s = emphasisRe.ReplaceAllString(s, "$1")
+	s = strikethroughRe.ReplaceAllString(s, "$1")
+
+	for i, span := range spans {
+		s = strings.Replace(s, fmt.Sprintf("\x00%d\x00", i), span, 1)
+	}
+	return s
+}
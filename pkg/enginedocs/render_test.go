@@ -0,0 +1,86 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPlainText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "headers",
+			markdown: "# Title\n\n## Section\n\n### Subsection ###",
+			want:     "Title\n=====\n\nSection\n-------\n\nSubsection",
+		},
+		{
+			name:     "bullet lists",
+			markdown: "* one\n+ two\n  - nested",
+			want:     "- one\n- two\n  - nested",
+		},
+		{
+			name:     "ordered lists are kept",
+			markdown: "1. first\n2. second",
+			want:     "1. first\n2. second",
+		},
+		{
+			name:     "fenced code blocks are indented and left untouched",
+			markdown: "Run:\n\n```bash\n# not a header\nforge build **all**\n```\n",
+			want:     "Run:\n\n    # not a header\n    forge build **all**\n",
+		},
+		{
+			name:     "inline markup",
+			markdown: "Use **bold**, *emphasis*, ~~old~~ and `code **kept**`.",
+			want:     "Use bold, emphasis, old and code **kept**.",
+		},
+		{
+			name:     "links and images",
+			markdown: "See [the schema](schema.md) ![diagram](img.png) <https://example.com>",
+			want:     "See the schema (schema.md) diagram <https://example.com>",
+		},
+		{
+			name:     "tables",
+			markdown: "| Field | Type |\n|-------|:----:|\n| `name` | string |",
+			want:     "Field  Type\nname  string",
+		},
+		{
+			name:     "blockquotes and rules",
+			markdown: "> Note: read this\n\n---\n\nAfter",
+			want:     "Note: read this\n\n\n\nAfter",
+		},
+		{
+			name:     "identifiers with underscores are preserved",
+			markdown: "Set TEST_METRICS_FILE or snake_case_value.",
+			want:     "Set TEST_METRICS_FILE or snake_case_value.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, RenderPlainText(tt.markdown))
+		})
+	}
+}
@@ -17,6 +17,8 @@
 // docs/ subdirectory with a list.yaml registry file.
 package enginedocs
 
+import "io/fs"
+
 // DocStore represents the structure of a list.yaml documentation registry.
 // Each engine maintains its own DocStore in cmd/{engine}/docs/list.yaml.
 type DocStore struct {
@@ -45,6 +47,9 @@ type DocEntry struct {
 	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
 	// Required indicates whether this is a required document for the engine (optional)
 	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// Tools lists the names of the MCP tools documented by this document (optional).
+	// It is cross-referenced with the registered tools by Verify.
+	Tools []string `yaml:"tools,omitempty" json:"tools,omitempty"`
 }
 
 // Config provides per-engine configuration for documentation management.
@@ -61,4 +66,14 @@ type Config struct {
 	// RequiredDocs is the list of required document names that must exist
 	// (e.g., ["usage", "schema"])
 	RequiredDocs []string `yaml:"requiredDocs" json:"requiredDocs"`
+	// CacheDir is the directory caching remotely fetched docs for offline fallback
+	// (defaults to {os.UserCacheDir()}/forge/enginedocs)
+	CacheDir string `yaml:"cacheDir,omitempty" json:"cacheDir,omitempty"`
+	// Offline disables remote fetches: docs missing locally are served from the cache
+	// or from EmbeddedFS
+	Offline bool `yaml:"offline,omitempty" json:"offline,omitempty"`
+	// EmbeddedFS holds the docs directory bundled at build time (e.g. with //go:embed docs),
+	// served when remote docs cannot be fetched. Files are looked up under the base name
+	// of LocalDir (e.g. "docs/usage.md")
+	EmbeddedFS fs.FS `yaml:"-" json:"-"`
 }
@@ -0,0 +1,115 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"sigs.k8s.io/yaml"
+)
+
+// docsToolNames are the tools registered by RegisterDocsTools; they document the docs
+// themselves and are not expected to be listed in any doc entry.
+var docsToolNames = map[string]bool{
+	"docs-list":     true,
+	"docs-get":      true,
+	"docs-validate": true,
+}
+
+// Verify cross-references the local documentation of an engine with the tools registered
+// on its MCP server, so that docs drifting from the actual tool set are caught in CI.
+// It returns a slice of all problems found (not just the first one).
+//
+// Verification checks:
+//  1. list.yaml exists locally and is valid YAML
+//  2. Required docs (cfg.RequiredDocs and entries marked required) are listed and exist locally
+//  3. Every registered tool is documented by the Tools of a doc entry (docs tools excepted)
+//  4. Every documented tool is registered on the server (no stale docs)
+func Verify(cfg Config, server *mcpserver.Server) []error {
+	var errs []error
+
+	// 1. list.yaml exists locally and is valid YAML
+	listPath := filepath.Join(cfg.LocalDir, listFileName)
+	data, err := os.ReadFile(listPath)
+	if err != nil {
+		return append(errs, fmt.Errorf("list.yaml: failed to read %s: %w", listPath, err))
+	}
+	var store DocStore
+	if err := yaml.Unmarshal(data, &store); err != nil {
+		return append(errs, fmt.Errorf("list.yaml: invalid YAML: %w", err))
+	}
+
+	// 2. Required docs are listed and exist locally
+	entries := make(map[string]DocEntry, len(store.Docs))
+	for _, doc := range store.Docs {
+		entries[doc.Name] = doc
+	}
+	for _, name := range cfg.RequiredDocs {
+		if _, ok := entries[name]; !ok {
+			errs = append(errs, fmt.Errorf("required doc %q is missing from docs array", name))
+		}
+	}
+	for _, doc := range store.Docs {
+		if !doc.Required && !slices.Contains(cfg.RequiredDocs, doc.Name) {
+			continue
+		}
+		path := localDocPath(cfg, doc)
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("required doc %q: local file not found at %s", doc.Name, path))
+		}
+	}
+
+	// 3. Every registered tool is documented
+	registered := make(map[string]bool)
+	for _, tool := range mcpserver.RegisteredTools(server) {
+		registered[tool.Name] = true
+	}
+	documented := make(map[string]bool)
+	for _, doc := range store.Docs {
+		for _, tool := range doc.Tools {
+			documented[tool] = true
+		}
+	}
+	for _, tool := range slices.Sorted(maps.Keys(registered)) {
+		if !documented[tool] && !docsToolNames[tool] {
+			errs = append(errs, fmt.Errorf("tool %q is registered but not documented by any doc entry", tool))
+		}
+	}
+
+	// 4. Every documented tool is registered
+	for _, doc := range store.Docs {
+		for _, tool := range doc.Tools {
+			if !registered[tool] {
+				errs = append(errs, fmt.Errorf("doc %q documents tool %q which is not registered (stale doc)", doc.Name, tool))
+			}
+		}
+	}
+
+	return errs
+}
+
+// localDocPath returns the local file of a doc entry: its URL (relative to the repository
+// root), or {cfg.LocalDir}/{name}.md when the entry has no URL.
+func localDocPath(cfg Config, doc DocEntry) string {
+	if doc.URL != "" {
+		return doc.URL
+	}
+	return filepath.Join(cfg.LocalDir, doc.Name+".md")
+}
@@ -0,0 +1,163 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginedocs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServerWithTools returns an MCP server with the given tools and the docs tools registered.
+func newServerWithTools(t *testing.T, cfg Config, names ...string) *mcpserver.Server {
+	t.Helper()
+	server := mcpserver.New("test-engine", "1.0.0")
+	for _, name := range names {
+		mcpserver.RegisterTool(server, &mcp.Tool{Name: name}, func(ctx context.Context, req *mcp.CallToolRequest, input any) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	}
+	require.NoError(t, RegisterDocsTools(server, cfg))
+	return server
+}
+
+// createVerifyDocs writes a list.yaml with the given docs section and the usage and schema docs.
+func createVerifyDocs(t *testing.T, docs string) Config {
+	t.Helper()
+	docsDir := filepath.Join(t.TempDir(), "docs")
+	createTestDocStore(t, docsDir, "version: \"1.0\"\nengine: test-engine\ndocs:\n"+docs)
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "usage.md"), []byte("# Usage\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "schema.md"), []byte("# Schema\n"), 0o644))
+	return Config{
+		EngineName:   "test-engine",
+		LocalDir:     docsDir,
+		RequiredDocs: []string{"usage", "schema"},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		docs       string
+		tools      []string
+		wantErrors []string
+	}{
+		{
+			name: "docs match registered tools",
+			docs: `  - name: usage
+    title: Usage
+    description: How to use test-engine
+    required: true
+    tools: [build, buildBatch]
+  - name: schema
+    title: Schema
+    description: Configuration options
+    required: true
+    tools: [config-validate]
+`,
+			tools: []string{"build", "buildBatch", "config-validate"},
+		},
+		{
+			name: "undocumented tool",
+			docs: `  - name: usage
+    title: Usage
+    description: How to use test-engine
+    tools: [build]
+  - name: schema
+    title: Schema
+    description: Configuration options
+`,
+			tools:      []string{"build", "buildBatch"},
+			wantErrors: []string{`tool "buildBatch" is registered but not documented by any doc entry`},
+		},
+		{
+			name: "stale documented tool",
+			docs: `  - name: usage
+    title: Usage
+    description: How to use test-engine
+    tools: [build, deploy]
+  - name: schema
+    title: Schema
+    description: Configuration options
+`,
+			tools:      []string{"build"},
+			wantErrors: []string{`doc "usage" documents tool "deploy" which is not registered (stale doc)`},
+		},
+		{
+			name: "missing required doc and undocumented tools",
+			docs: `  - name: usage
+    title: Usage
+    description: How to use test-engine
+`,
+			tools: []string{"create", "delete"},
+			wantErrors: []string{
+				`required doc "schema" is missing from docs array`,
+				`tool "create" is registered but not documented by any doc entry`,
+				`tool "delete" is registered but not documented by any doc entry`,
+			},
+		},
+		{
+			name: "required doc without local file",
+			docs: `  - name: usage
+    title: Usage
+    description: How to use test-engine
+    tools: [build]
+  - name: schema
+    title: Schema
+    description: Configuration options
+  - name: troubleshooting
+    title: Troubleshooting
+    description: Common problems
+    required: true
+`,
+			tools:      []string{"build"},
+			wantErrors: []string{`required doc "troubleshooting": local file not found`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := createVerifyDocs(t, tt.docs)
+			errs := Verify(cfg, newServerWithTools(t, cfg, tt.tools...))
+
+			require.Len(t, errs, len(tt.wantErrors), "errors: %v", errs)
+			for i, want := range tt.wantErrors {
+				assert.Contains(t, errs[i].Error(), want)
+			}
+		})
+	}
+}
+
+func TestVerify_MissingListYAML(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{EngineName: "test-engine", LocalDir: filepath.Join(t.TempDir(), "docs")}
+	errs := Verify(cfg, newServerWithTools(t, cfg))
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "list.yaml")
+}
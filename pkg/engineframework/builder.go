@@ -16,13 +16,17 @@ package engineframework
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -32,6 +36,7 @@ import (
 //   - Validate input fields (required fields should be checked, use RequireString etc. from spec.go)
 //   - Execute the build operation (compile code, generate files, etc.)
 //   - Return Artifact on success (with Name, Type, Location, Version if applicable, Timestamp)
+//   - Record additional artifacts with AddArtifacts if the build produces more than one
 //   - Return error on failure (business logic errors, not MCP errors)
 //
 // The framework handles:
@@ -59,12 +64,37 @@ import (
 //	}
 type BuilderFunc func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error)
 
+// BeforeBuildFunc is the signature for optional setup run before each build (e.g. acquiring a lock).
+//
+// An error aborts the build: neither BuildFunc nor AfterBuildFunc is called.
+type BeforeBuildFunc func(ctx context.Context, input mcptypes.BuildInput) error
+
+// AfterBuildFunc is the signature for optional teardown run after each build (e.g. releasing a
+// lock, removing temporary directories).
+//
+// It runs whenever BeforeBuildFunc succeeded, including when BuildFunc failed: artifact and
+// buildErr are the values returned by BuildFunc. An error returned by AfterBuildFunc fails the build.
+//
+// Example:
+//
+//	func releaseLock(ctx context.Context, input mcptypes.BuildInput, artifact *forge.Artifact, buildErr error) error {
+//	    if buildErr != nil {
+//	        log.Printf("Build %s failed, releasing lock", input.Name)
+//	    }
+//	    return buildLock.Release(input.Name)
+//	}
+type AfterBuildFunc func(ctx context.Context, input mcptypes.BuildInput, artifact *forge.Artifact, buildErr error) error
+
 // BuilderConfig configures builder tool registration.
 //
 // Fields:
 //   - Name: Engine name (e.g., "go-build", "container-build")
 //   - Version: Engine version string (e.g., "1.0.0" or git commit hash)
 //   - BuildFunc: The build implementation function
+//   - BeforeBuild: Optional setup run before each build
+//   - AfterBuild: Optional teardown run after each build, even if it failed
+//   - BatchConcurrency: Maximum number of builds run at once by buildBatch (default 1)
+//   - Capabilities: Optional features supported by BuildFunc beyond batch (e.g. CapabilityDryRun)
 //
 // Example:
 //
@@ -77,17 +107,32 @@ type BuilderConfig struct {
 	Name      string      // Engine name (e.g., "go-build")
 	Version   string      // Engine version
 	BuildFunc BuilderFunc // Build implementation
+	// BeforeBuild optionally runs before each BuildFunc call (nil to skip)
+	BeforeBuild BeforeBuildFunc
+	// AfterBuild optionally runs after each BuildFunc call, even if it failed (nil to skip)
+	AfterBuild AfterBuildFunc
+	// BatchConcurrency bounds the builds buildBatch runs at once (<= 0 means 1, i.e. sequential).
+	// Only raise it if BuildFunc is safe for concurrent use (e.g. it does not call os.Setenv).
+	BatchConcurrency int
+	// Capabilities lists optional features BuildFunc supports; batch is always advertised
+	Capabilities []Capability
+	// SpecSchema is the JSON schema of the engine spec, returned by the "describe-spec" tool (optional)
+	SpecSchema json.RawMessage
 }
 
 // RegisterBuilderTools registers build and buildBatch tools with the MCP server.
 //
 // This function automatically:
-//   - Registers "build" tool that calls the BuildFunc
-//   - Registers "buildBatch" tool that handles multiple builds in parallel
+//   - Registers "build" tool that calls the BuildFunc, surrounded by the BeforeBuild and AfterBuild hooks
+//   - Registers "buildBatch" tool that runs multiple builds, up to config.BatchConcurrency at once
+//   - Registers "capabilities" tool advertising batch and config.Capabilities
+//   - Registers "describe-spec" tool returning config.SpecSchema, when set
 //   - Validates required input fields (Name, Engine)
 //   - Converts BuilderFunc errors to MCP error responses
 //   - Formats successful results with artifact information
-//   - Uses mcputil.HandleBatchBuild for batch processing
+//   - Reports the outcome of every batch item, so that one failed build does not hide the others
+//   - Sets BuildInput.RootDir and resolves relative BuildInput.Src and BuildInput.Path against it
+//     (see runBuild), so builds behave the same wherever forge runs
 //
 // Parameters:
 //   - server: The MCP server instance
@@ -127,16 +172,32 @@ func RegisterBuilderTools(server *mcpserver.Server, config BuilderConfig) error
 		Description: fmt.Sprintf("Build multiple artifacts in a single batch call using %s. Forge uses this when multiple forge.yaml build[] entries share the same engine.", config.Name),
 	}, makeBatchBuildHandler(config))
 
+	// Register capabilities tool
+	registerCapabilitiesTool(server, builderCapabilities(config))
+
+	// Register describe-spec tool when the engine declares its spec schema
+	if err := registerDescribeSpecTool(server, config.Name, config.SpecSchema); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// builderCapabilities returns the capabilities advertised by a builder engine.
+func builderCapabilities(config BuilderConfig) Capabilities {
+	return newCapabilities(config.Name, config.Version, EngineKindBuilder,
+		[]Capability{CapabilityBatch}, config.Capabilities)
+}
+
 // makeBuildHandler creates an MCP handler function from a BuilderFunc.
 //
 // The returned handler:
 //   - Validates required input fields (Name, Engine)
-//   - Calls the BuilderFunc with the input
+//   - Calls the BuilderFunc with the input, in a "build" span (see package tracing), between the
+//     BeforeBuild and AfterBuild hooks if set
 //   - Converts BuilderFunc errors to MCP error responses
-//   - Formats successful results with artifact information
+//   - Formats successful results with artifact information, as a batch result if the
+//     BuilderFunc added artifacts with AddArtifacts
 //
 // This is an internal helper function used by RegisterBuilderTools.
 func makeBuildHandler(config BuilderConfig) func(context.Context, *mcp.CallToolRequest, mcptypes.BuildInput) (*mcp.CallToolResult, any, error) {
@@ -151,12 +212,29 @@ func makeBuildHandler(config BuilderConfig) func(context.Context, *mcp.CallToolR
 			return result, nil, nil
 		}
 
-		// Call the BuilderFunc
-		artifact, err := config.BuildFunc(ctx, input)
+		// Call the BuilderFunc in a span joining the caller's trace
+		ctx, span := tracing.StartOperation(tracing.ContextFromRequest(ctx, req), config.Name, "build")
+		ctx, collector := withArtifactCollector(ctx)
+		artifact, err := runBuild(ctx, config, input)
+		tracing.End(span, err)
 		if err != nil {
 			return mcputil.ErrorResult(fmt.Sprintf("Build failed: %v", err)), nil, nil
 		}
 
+		// Return all artifacts of a build that produced more than one
+		if added := collector.list(); len(added) > 0 {
+			artifacts := make([]any, 0, len(added)+1)
+			if artifact != nil {
+				artifacts = append(artifacts, artifact)
+			}
+			for i := range added {
+				added[i].Labels = withInputLabels(added[i].Labels, input.Labels)
+				artifacts = append(artifacts, &added[i])
+			}
+			result, batchResult := mcputil.FormatBatchResult("artifacts", artifacts, nil)
+			return result, batchResult, nil
+		}
+
 		// Return success with artifact
 		result, returnedArtifact := mcputil.SuccessResultWithArtifact(
 			fmt.Sprintf("Build succeeded: %s", input.Name),
@@ -166,29 +244,134 @@ func makeBuildHandler(config BuilderConfig) func(context.Context, *mcp.CallToolR
 	}
 }
 
+// runBuild calls the BuildFunc of config surrounded by its BeforeBuild and AfterBuild hooks.
+// The hooks and BuildFunc always see an absolute input.RootDir: an empty one defaults to the
+// directory of the --config file (see enginecli.RootDir), else to the working directory.
+// Relative input.Src and input.Path are joined to it; paths inside input.Spec are left to the engine.
+// The input labels are added to the labels of the returned artifact before AfterBuild runs.
+func runBuild(ctx context.Context, config BuilderConfig, input mcptypes.BuildInput) (*forge.Artifact, error) {
+	input.RootDir = resolveRootDir(input.RootDir)
+	input.Src = resolveInputPath(input.RootDir, input.Src)
+	input.Path = resolveInputPath(input.RootDir, input.Path)
+
+	if config.BeforeBuild != nil {
+		if err := config.BeforeBuild(ctx, input); err != nil {
+			return nil, fmt.Errorf("before build: %w", err)
+		}
+	}
+
+	artifact, err := config.BuildFunc(ctx, input)
+	if artifact != nil {
+		artifact.Labels = withInputLabels(artifact.Labels, input.Labels)
+	}
+
+	if config.AfterBuild != nil {
+		if afterErr := config.AfterBuild(ctx, input, artifact, err); afterErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("after build: %w", afterErr))
+		}
+	}
+
+	return artifact, err
+}
+
 // makeBatchBuildHandler creates an MCP batch handler function from a BuilderFunc.
 //
 // The returned handler:
-//   - Validates each build input
-//   - Calls the BuilderFunc for each spec in parallel (via mcputil.HandleBatchBuild)
-//   - Aggregates results and errors
-//   - Formats batch result with all artifacts and error messages
+//   - Builds every spec with the single-build handler, up to config.BatchConcurrency at once
+//   - Keeps building the remaining specs when one of them fails
+//   - Flattens the results of builds that added artifacts with AddArtifacts
+//   - Formats a batch result with the artifacts, error messages and the per-spec outcomes
+//     (name, status, error), in input order
 //
 // This is an internal helper function used by RegisterBuilderTools.
 func makeBatchBuildHandler(config BuilderConfig) func(context.Context, *mcp.CallToolRequest, mcptypes.BatchBuildInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, input mcptypes.BatchBuildInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("Building %d artifacts in batch using %s", len(input.Specs), config.Name)
+		concurrency := max(config.BatchConcurrency, 1)
+		log.Printf("Building %d artifacts in batch using %s (concurrency: %d)", len(input.Specs), config.Name, concurrency)
 
 		// Create single-build handler for batch processing
 		singleBuildHandler := makeBuildHandler(config)
 
-		// Use generic batch handler from mcputil
-		artifacts, errorMsgs := mcputil.HandleBatchBuild(ctx, input.Specs, func(ctx context.Context, spec mcptypes.BuildInput) (*mcp.CallToolResult, any, error) {
-			return singleBuildHandler(ctx, req, spec)
-		})
+		items := make([]mcputil.BatchItemResult, len(input.Specs))
+		outputs := make([]any, len(input.Specs))
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+
+		for i, spec := range input.Specs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				result, output, err := singleBuildHandler(ctx, req, spec)
+				items[i] = mcputil.BatchItem(spec.Name, result, err)
+				outputs[i] = output
+			}()
+		}
+		wg.Wait()
+
+		// Collect artifacts and errors in input order, flattening the results of builds
+		// that produced more than one artifact
+		artifacts := []any{}
+		errorMsgs := []string{}
+		for i, item := range items {
+			if item.Status == mcputil.BatchItemFailed {
+				errorMsgs = append(errorMsgs, item.Error)
+				continue
+			}
+			switch output := outputs[i].(type) {
+			case nil:
+			case mcputil.BatchResult:
+				artifacts = append(artifacts, output.Artifacts...)
+			default:
+				artifacts = append(artifacts, output)
+			}
+		}
 
 		// Format the batch result
-		result, returnedArtifacts := mcputil.FormatBatchResult("artifacts", artifacts, errorMsgs)
+		result, returnedArtifacts := mcputil.FormatBatchResultWithItems("artifacts", artifacts, errorMsgs, items)
 		return result, returnedArtifacts, nil
 	}
 }
+
+// artifactsKey is the context key of the artifact collector of a build call.
+type artifactsKey struct{}
+
+// artifactCollector collects the artifacts added by a BuilderFunc with AddArtifacts.
+type artifactCollector struct {
+	mu        sync.Mutex
+	artifacts []forge.Artifact
+}
+
+// withArtifactCollector returns a context carrying a new artifact collector.
+func withArtifactCollector(ctx context.Context) (context.Context, *artifactCollector) {
+	collector := &artifactCollector{}
+	return context.WithValue(ctx, artifactsKey{}, collector), collector
+}
+
+// list returns the collected artifacts.
+func (c *artifactCollector) list() []forge.Artifact {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]forge.Artifact(nil), c.artifacts...)
+}
+
+// AddArtifacts records additional artifacts produced by a BuilderFunc, for builds that produce
+// more than one artifact (e.g. one binary per target platform).
+//
+// When artifacts were added, the build tool returns a batch result listing the artifact returned
+// by the BuilderFunc (if not nil) followed by the added artifacts, and buildBatch flattens it.
+// A BuilderFunc producing only added artifacts may therefore return a nil artifact.
+// AddArtifacts is safe for concurrent use and reports false if ctx does not come from a build tool.
+func AddArtifacts(ctx context.Context, artifacts ...forge.Artifact) bool {
+	collector, ok := ctx.Value(artifactsKey{}).(*artifactCollector)
+	if !ok {
+		return false
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.artifacts = append(collector.artifacts, artifacts...)
+	return true
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+)
+
+// RunBuilderCLI runs a builder in CLI mode, mainly to reproduce a failing build locally.
+//
+// Supported usage:
+//
+//	<engine> build --input build-input.json
+//
+// The input file holds a full mcptypes.BuildInput as JSON (the same payload forge sends
+// to the "build" MCP tool). BuildFunc is called directly (between the BeforeBuild and
+// AfterBuild hooks, if set) and the resulting artifact is printed to out as JSON, or a JSON array if BuildFunc added artifacts with AddArtifacts.
+//
+// Example:
+//
+//	func runCLI() error {
+//	    return RunBuilderCLI(context.Background(), config, os.Args[1:], os.Stdout)
+//	}
+func RunBuilderCLI(ctx context.Context, config BuilderConfig, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "build" {
+		return fmt.Errorf("usage: %s build --input <build-input.json>", config.Name)
+	}
+
+	inputPath, err := parseBuildInputFlag(args[1:])
+	if err != nil {
+		return fmt.Errorf("%w\nusage: %s build --input <build-input.json>", err, config.Name)
+	}
+
+	input, err := LoadBuildInput(inputPath)
+	if err != nil {
+		return err
+	}
+
+	// The engine URI is implied when running the engine binary directly
+	if input.Engine == "" {
+		input.Engine = "go://" + config.Name
+	}
+
+	ctx, collector := withArtifactCollector(ctx)
+	artifact, err := runBuild(ctx, config, input)
+	if err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+
+	// Builds that added artifacts print all of them as a JSON array
+	var output any = artifact
+	if added := collector.list(); len(added) > 0 {
+		artifacts := make([]forge.Artifact, 0, len(added)+1)
+		if artifact != nil {
+			artifacts = append(artifacts, *artifact)
+		}
+		output = append(artifacts, added...)
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		return fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return nil
+}
+
+// parseBuildInputFlag extracts the value of --input from the build subcommand arguments.
+func parseBuildInputFlag(args []string) (string, error) {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--input" || args[i] == "-i":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return "", fmt.Errorf("--input requires a file path")
+			}
+			return args[i+1], nil
+		case strings.HasPrefix(args[i], "--input="):
+			if path := strings.TrimPrefix(args[i], "--input="); path != "" {
+				return path, nil
+			}
+			return "", fmt.Errorf("--input requires a file path")
+		}
+	}
+	return "", fmt.Errorf("--input is required")
+}
+
+// LoadBuildInput reads a JSON-encoded mcptypes.BuildInput from a file.
+// The input must at least set a name.
+func LoadBuildInput(path string) (mcptypes.BuildInput, error) {
+	var input mcptypes.BuildInput
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return input, fmt.Errorf("failed to read build input %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &input); err != nil {
+		return input, fmt.Errorf("failed to parse build input %s: %w", path, err)
+	}
+
+	if input.Name == "" {
+		return input, fmt.Errorf("build input %s: name is required", path)
+	}
+
+	return input, nil
+}
@@ -0,0 +1,152 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/forge"
+	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
+)
+
+func TestRunBuilderCLI_FromInputFile(t *testing.T) {
+	var got mcptypes.BuildInput
+	config := BuilderConfig{
+		Name: "test-builder",
+		BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+			got = input
+			return CreateCustomArtifact(input.Name, "binary", filepath.Join(input.Dest, input.Name), "v1.0.0"), nil
+		},
+	}
+
+	var out bytes.Buffer
+	if err := RunBuilderCLI(context.Background(), config, []string{"build", "--input", "testdata/build-input.json"}, &out); err != nil {
+		t.Fatalf("RunBuilderCLI() error = %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "my-app" || got.Src != filepath.Join(wd, "cmd", "my-app") || got.Engine != "go://test-builder" {
+		t.Errorf("BuildFunc received unexpected input: %+v", got)
+	}
+	if got.Env["CGO_ENABLED"] != "0" || got.Spec["ldflags"] != "-s -w" {
+		t.Errorf("BuildFunc received unexpected env/spec: env=%v spec=%v", got.Env, got.Spec)
+	}
+
+	var artifact forge.Artifact
+	if err := json.Unmarshal(out.Bytes(), &artifact); err != nil {
+		t.Fatalf("output is not an artifact JSON: %v\n%s", err, out.String())
+	}
+	if artifact.Name != "my-app" || artifact.Location != "build/bin/my-app" || artifact.Version != "v1.0.0" {
+		t.Errorf("unexpected artifact: %+v", artifact)
+	}
+}
+
+func TestRunBuilderCLI_DefaultsEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, []byte(`{"name":"my-app"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotEngine string
+	config := BuilderConfig{
+		Name: "test-builder",
+		BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+			gotEngine = input.Engine
+			return CreateArtifact(input.Name, "binary", "./build/bin/"+input.Name), nil
+		},
+	}
+
+	if err := RunBuilderCLI(context.Background(), config, []string{"build", "--input=" + path}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunBuilderCLI() error = %v", err)
+	}
+	if gotEngine != "go://test-builder" {
+		t.Errorf("Engine = %q, want %q", gotEngine, "go://test-builder")
+	}
+}
+
+func TestRunBuilderCLI_AddedArtifacts(t *testing.T) {
+	config := BuilderConfig{
+		Name: "test-builder",
+		BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+			AddArtifacts(ctx,
+				*CreateArtifact(input.Name+"-linux-amd64", "binary", "build/bin/a"),
+				*CreateArtifact(input.Name+"-darwin-arm64", "binary", "build/bin/b"),
+			)
+			return nil, nil
+		},
+	}
+
+	var out bytes.Buffer
+	err := RunBuilderCLI(context.Background(), config, []string{"build", "--input", "testdata/build-input.json"}, &out)
+	if err != nil {
+		t.Fatalf("RunBuilderCLI() error = %v", err)
+	}
+
+	var artifacts []forge.Artifact
+	if err := json.Unmarshal(out.Bytes(), &artifacts); err != nil {
+		t.Fatalf("output is not an artifact JSON array: %v\n%s", err, out.String())
+	}
+	if len(artifacts) != 2 || artifacts[0].Name != "my-app-linux-amd64" || artifacts[1].Name != "my-app-darwin-arm64" {
+		t.Errorf("unexpected artifacts: %+v", artifacts)
+	}
+}
+
+func TestRunBuilderCLI_Errors(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"name":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	unnamed := filepath.Join(t.TempDir(), "unnamed.json")
+	if err := os.WriteFile(unnamed, []byte(`{"src":"./cmd/app"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no subcommand", args: nil, wantErr: "usage: test-builder build --input"},
+		{name: "unknown subcommand", args: []string{"run"}, wantErr: "usage: test-builder build --input"},
+		{name: "missing --input", args: []string{"build"}, wantErr: "--input is required"},
+		{name: "--input without path", args: []string{"build", "--input"}, wantErr: "--input requires a file path"},
+		{name: "file not found", args: []string{"build", "--input", "testdata/missing.json"}, wantErr: "failed to read build input"},
+		{name: "invalid JSON", args: []string{"build", "--input", invalid}, wantErr: "failed to parse build input"},
+		{name: "missing name", args: []string{"build", "--input", unnamed}, wantErr: "name is required"},
+		{name: "build failure", args: []string{"build", "-i", "testdata/build-input.json"}, wantErr: "build failed: build failed: simulated error"},
+	}
+
+	config := BuilderConfig{Name: "test-builder", BuildFunc: mockBuildFunc(true)}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RunBuilderCLI(context.Background(), config, tt.args, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RunBuilderCLI() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -19,14 +19,25 @@ package engineframework
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // mockBuildFunc creates a mock BuilderFunc for testing.
@@ -539,3 +550,554 @@ func TestBuilderFunc_ContextPropagation(t *testing.T) {
 		t.Errorf("context value = %v, want %q", val, "value")
 	}
 }
+
+// recordSpans installs a tracer provider recording ended spans in memory
+// for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// spanAttribute returns the string value of the span attribute key.
+func spanAttribute(span tracetest.SpanStub, key string) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == attribute.Key(key) {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestMakeBuildHandler_CreatesSpanInCallerTrace(t *testing.T) {
+	exporter := recordSpans(t)
+
+	handler := makeBuildHandler(BuilderConfig{
+		Name:      "test-builder",
+		Version:   "1.0.0",
+		BuildFunc: mockBuildFunc(false),
+	})
+
+	// The caller propagates its trace context in the request metadata
+	callCtx, callSpan := tracing.StartCall(context.Background(), "test-builder", nil, "build")
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Meta: tracing.InjectMeta(callCtx)}}
+
+	result, _, err := handler(context.Background(), req, mcptypes.BuildInput{
+		Name:   "my-app",
+		Engine: "go://test-builder",
+	})
+	callSpan.End()
+	if err != nil || result.IsError {
+		t.Fatalf("handler failed: result=%v, err=%v", result, err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans (call and build), got %d", len(spans))
+	}
+
+	build := spans[0]
+	if build.Name != "test-builder build" {
+		t.Errorf("Expected span name 'test-builder build', got '%s'", build.Name)
+	}
+	if got := spanAttribute(build, tracing.AttributeEngine); got != "test-builder" {
+		t.Errorf("Expected engine attribute 'test-builder', got '%s'", got)
+	}
+	if got := spanAttribute(build, tracing.AttributeOperation); got != "build" {
+		t.Errorf("Expected operation attribute 'build', got '%s'", got)
+	}
+	if build.StartTime.IsZero() || build.EndTime.Before(build.StartTime) {
+		t.Errorf("Expected the build span to record its duration, got %v to %v", build.StartTime, build.EndTime)
+	}
+
+	call := spans[1]
+	if build.SpanContext.TraceID() != call.SpanContext.TraceID() {
+		t.Error("Expected the build span to join the caller's trace")
+	}
+	if build.Parent.SpanID() != call.SpanContext.SpanID() {
+		t.Error("Expected the build span to be a child of the call span")
+	}
+}
+
+func TestMakeBuildHandler_SpanRecordsFailure(t *testing.T) {
+	exporter := recordSpans(t)
+
+	handler := makeBuildHandler(BuilderConfig{
+		Name:      "test-builder",
+		Version:   "1.0.0",
+		BuildFunc: mockBuildFunc(true),
+	})
+
+	result, _, _ := handler(context.Background(), &mcp.CallToolRequest{}, mcptypes.BuildInput{
+		Name:   "my-app",
+		Engine: "go://test-builder",
+	})
+	if !result.IsError {
+		t.Fatal("Expected an error result")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected span status Error, got %v", spans[0].Status.Code)
+	}
+	if spans[0].Parent.IsValid() {
+		t.Error("Expected a root span without caller trace context")
+	}
+}
+
+// multiArtifactBuildFunc returns a BuilderFunc recording one artifact per target with AddArtifacts.
+func multiArtifactBuildFunc(targets ...string) BuilderFunc {
+	return func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+		for _, target := range targets {
+			name := input.Name + "-" + target
+			if !AddArtifacts(ctx, *CreateArtifact(name, "binary", "/path/to/"+name)) {
+				return nil, errors.New("no artifact collector in context")
+			}
+		}
+		return nil, nil
+	}
+}
+
+func TestMakeBuildHandler_AddArtifacts(t *testing.T) {
+	config := BuilderConfig{
+		Name:      "test-builder",
+		Version:   "1.0.0",
+		BuildFunc: multiArtifactBuildFunc("linux-amd64", "darwin-arm64"),
+	}
+
+	result, artifacts, err := makeBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BuildInput{
+		Name:   "my-app",
+		Engine: "go://test-builder",
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handler returned error result: %v", result.Content)
+	}
+
+	batchResult, ok := artifacts.(mcputil.BatchResult)
+	if !ok {
+		t.Fatalf("artifacts is not mcputil.BatchResult, got %T", artifacts)
+	}
+
+	wantNames := []string{"my-app-linux-amd64", "my-app-darwin-arm64"}
+	if len(batchResult.Artifacts) != len(wantNames) {
+		t.Fatalf("expected %d artifacts, got %d", len(wantNames), len(batchResult.Artifacts))
+	}
+	for i, wantName := range wantNames {
+		artifact, ok := batchResult.Artifacts[i].(*forge.Artifact)
+		if !ok || artifact.Name != wantName {
+			t.Errorf("artifact[%d] = %+v, want name %q", i, batchResult.Artifacts[i], wantName)
+		}
+	}
+}
+
+func TestMakeBatchBuildHandler_FlattensAddedArtifacts(t *testing.T) {
+	config := BuilderConfig{
+		Name:      "test-builder",
+		Version:   "1.0.0",
+		BuildFunc: multiArtifactBuildFunc("amd64", "arm64"),
+	}
+
+	_, artifacts, err := makeBatchBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BatchBuildInput{
+		Specs: []mcptypes.BuildInput{
+			{Name: "app1", Engine: "go://test-builder"},
+			{Name: "app2", Engine: "go://test-builder"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	batchResult, ok := artifacts.(mcputil.BatchResult)
+	if !ok {
+		t.Fatalf("artifacts is not mcputil.BatchResult, got %T", artifacts)
+	}
+
+	wantNames := []string{"app1-amd64", "app1-arm64", "app2-amd64", "app2-arm64"}
+	if batchResult.Count != len(wantNames) || len(batchResult.Artifacts) != len(wantNames) {
+		t.Fatalf("expected %d artifacts, got %d (count %d)", len(wantNames), len(batchResult.Artifacts), batchResult.Count)
+	}
+	for i, wantName := range wantNames {
+		artifact, ok := batchResult.Artifacts[i].(*forge.Artifact)
+		if !ok || artifact.Name != wantName {
+			t.Errorf("artifact[%d] = %+v, want name %q", i, batchResult.Artifacts[i], wantName)
+		}
+	}
+}
+
+func TestAddArtifacts_WithoutCollector(t *testing.T) {
+	if AddArtifacts(context.Background(), forge.Artifact{Name: "orphan"}) {
+		t.Error("AddArtifacts() = true, want false outside a build tool call")
+	}
+}
+
+// hookedBuilderConfig returns a BuilderConfig whose hooks and BuildFunc record their calls in calls.
+func hookedBuilderConfig(calls *[]string, buildErr, beforeErr, afterErr error) BuilderConfig {
+	return BuilderConfig{
+		Name:    "test-builder",
+		Version: "1.0.0",
+		BeforeBuild: func(ctx context.Context, input mcptypes.BuildInput) error {
+			*calls = append(*calls, "before:"+input.Name)
+			return beforeErr
+		},
+		BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+			*calls = append(*calls, "build:"+input.Name)
+			if buildErr != nil {
+				return nil, buildErr
+			}
+			return CreateArtifact(input.Name, "binary", "/path/to/"+input.Name), nil
+		},
+		AfterBuild: func(ctx context.Context, input mcptypes.BuildInput, artifact *forge.Artifact, err error) error {
+			call := "after:" + input.Name
+			if artifact != nil {
+				call += ":artifact=" + artifact.Name
+			}
+			if err != nil {
+				call += ":err=" + err.Error()
+			}
+			*calls = append(*calls, call)
+			return afterErr
+		},
+	}
+}
+
+func TestMakeBuildHandler_Hooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		buildErr    error
+		beforeErr   error
+		afterErr    error
+		wantCalls   []string
+		wantError   bool
+		wantMessage string
+	}{
+		{
+			name:      "hooks surround a successful build",
+			wantCalls: []string{"before:my-app", "build:my-app", "after:my-app:artifact=my-app"},
+		},
+		{
+			name:        "after build sees the build error",
+			buildErr:    errors.New("compilation failed"),
+			wantCalls:   []string{"before:my-app", "build:my-app", "after:my-app:err=compilation failed"},
+			wantError:   true,
+			wantMessage: "Build failed: compilation failed",
+		},
+		{
+			name:        "before build error skips the build",
+			beforeErr:   errors.New("lock held"),
+			wantCalls:   []string{"before:my-app"},
+			wantError:   true,
+			wantMessage: "Build failed: before build: lock held",
+		},
+		{
+			name:        "after build error fails the build",
+			afterErr:    errors.New("cleanup failed"),
+			wantCalls:   []string{"before:my-app", "build:my-app", "after:my-app:artifact=my-app"},
+			wantError:   true,
+			wantMessage: "Build failed: after build: cleanup failed",
+		},
+		{
+			name:        "build and after build errors are joined",
+			buildErr:    errors.New("compilation failed"),
+			afterErr:    errors.New("cleanup failed"),
+			wantCalls:   []string{"before:my-app", "build:my-app", "after:my-app:err=compilation failed"},
+			wantError:   true,
+			wantMessage: "Build failed: compilation failed\nafter build: cleanup failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			config := hookedBuilderConfig(&calls, tt.buildErr, tt.beforeErr, tt.afterErr)
+
+			result, _, err := makeBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BuildInput{
+				Name:   "my-app",
+				Engine: "go://test-builder",
+			})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("result.IsError = %v, want %v", result.IsError, tt.wantError)
+			}
+			if tt.wantMessage != "" {
+				textContent, ok := result.Content[0].(*mcp.TextContent)
+				if !ok || textContent.Text != tt.wantMessage {
+					t.Errorf("result message = %v, want %q", result.Content[0], tt.wantMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeBatchBuildHandler_HooksRunPerBuild(t *testing.T) {
+	var calls []string
+	config := hookedBuilderConfig(&calls, nil, nil, nil)
+
+	// A single spec keeps the recorded calls deterministic
+	_, _, err := makeBatchBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BatchBuildInput{
+		Specs: []mcptypes.BuildInput{{Name: "app-1", Engine: "go://test-builder"}},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	want := []string{"before:app-1", "build:app-1", "after:app-1:artifact=app-1"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestMakeBatchBuildHandler_ItemResults(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			config := BuilderConfig{
+				Name:             "test-builder",
+				Version:          "1.0.0",
+				BuildFunc:        mockBuildFunc(false), // Fails if name contains "fail"
+				BatchConcurrency: concurrency,
+			}
+
+			result, out, err := makeBatchBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BatchBuildInput{
+				Specs: []mcptypes.BuildInput{
+					{Name: "app1", Engine: "go://test-builder"},
+					{Name: "fail-app", Engine: "go://test-builder"},
+					{Name: "app3", Engine: "go://test-builder"},
+					{Name: "app4"}, // Missing engine
+				},
+			})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			if !result.IsError {
+				t.Error("handler should return error result when some builds fail")
+			}
+
+			batchResult, ok := out.(mcputil.BatchResult)
+			if !ok {
+				t.Fatalf("output is not mcputil.BatchResult, got %T", out)
+			}
+
+			want := []mcputil.BatchItemResult{
+				{Name: "app1", Status: mcputil.BatchItemSucceeded},
+				{Name: "fail-app", Status: mcputil.BatchItemFailed, Error: "Build failed: build failed: simulated error"},
+				{Name: "app3", Status: mcputil.BatchItemSucceeded},
+				{Name: "app4", Status: mcputil.BatchItemFailed, Error: "Build failed: missing required field 'engine'"},
+			}
+			if len(batchResult.Results) != len(want) {
+				t.Fatalf("Results = %+v, want %+v", batchResult.Results, want)
+			}
+			for i := range want {
+				if batchResult.Results[i] != want[i] {
+					t.Errorf("Results[%d] = %+v, want %+v", i, batchResult.Results[i], want[i])
+				}
+			}
+
+			if len(batchResult.Artifacts) != 2 {
+				t.Fatalf("expected 2 artifacts, got %d", len(batchResult.Artifacts))
+			}
+			for i, name := range []string{"app1", "app3"} {
+				if artifact := batchResult.Artifacts[i].(*forge.Artifact); artifact.Name != name {
+					t.Errorf("artifact[%d].Name = %q, want %q", i, artifact.Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeBatchBuildHandler_BoundsConcurrency(t *testing.T) {
+	const concurrency = 2
+	var running, maxRunning atomic.Int32
+
+	config := BuilderConfig{
+		Name:    "test-builder",
+		Version: "1.0.0",
+		BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return CreateArtifact(input.Name, "binary", "/path/to/"+input.Name), nil
+		},
+		BatchConcurrency: concurrency,
+	}
+
+	specs := make([]mcptypes.BuildInput, 6)
+	for i := range specs {
+		specs[i] = mcptypes.BuildInput{Name: fmt.Sprintf("app%d", i), Engine: "go://test-builder"}
+	}
+
+	result, _, err := makeBatchBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BatchBuildInput{Specs: specs})
+	if err != nil || result.IsError {
+		t.Fatalf("handler failed: err=%v result=%v", err, result.Content)
+	}
+
+	if got := maxRunning.Load(); got != concurrency {
+		t.Errorf("max concurrent builds = %d, want %d", got, concurrency)
+	}
+}
+
+func TestMakeBuildHandler_PropagatesLabels(t *testing.T) {
+	config := BuilderConfig{
+		Name:    "test-builder",
+		Version: "1.0.0",
+		BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+			return &forge.Artifact{
+				Name:   input.Name,
+				Type:   "binary",
+				Labels: map[string]string{"component": "engine"},
+			}, nil
+		},
+	}
+
+	_, artifact, err := makeBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BuildInput{
+		Name:   "my-app",
+		Engine: "go://test-builder",
+		Labels: map[string]string{"team": "platform", "component": "input"},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	artifactObj, ok := artifact.(*forge.Artifact)
+	if !ok {
+		t.Fatalf("artifact is not *forge.Artifact, got %T", artifact)
+	}
+	want := map[string]string{"team": "platform", "component": "engine"}
+	if !maps.Equal(artifactObj.Labels, want) {
+		t.Errorf("Labels = %v, want %v", artifactObj.Labels, want)
+	}
+}
+
+func TestMakeBatchBuildHandler_PropagatesLabelsToAddedArtifacts(t *testing.T) {
+	config := BuilderConfig{
+		Name:      "test-builder",
+		Version:   "1.0.0",
+		BuildFunc: multiArtifactBuildFunc("amd64", "arm64"),
+	}
+
+	_, artifacts, err := makeBatchBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.BatchBuildInput{
+		Specs: []mcptypes.BuildInput{
+			{Name: "app1", Engine: "go://test-builder", Labels: map[string]string{"team": "a"}},
+			{Name: "app2", Engine: "go://test-builder"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	batchResult, ok := artifacts.(mcputil.BatchResult)
+	if !ok {
+		t.Fatalf("artifacts is not mcputil.BatchResult, got %T", artifacts)
+	}
+	for _, a := range batchResult.Artifacts {
+		artifact := a.(*forge.Artifact)
+		wantTeam := ""
+		if strings.HasPrefix(artifact.Name, "app1-") {
+			wantTeam = "a"
+		}
+		if artifact.Labels["team"] != wantTeam {
+			t.Errorf("artifact %s: Labels = %v, want team %q", artifact.Name, artifact.Labels, wantTeam)
+		}
+	}
+}
+
+func TestMakeBuildHandler_ResolvesPathsAgainstRootDir(t *testing.T) {
+	rootDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		input       mcptypes.BuildInput
+		wantRootDir string
+		wantSrc     string
+		wantPath    string
+		chdir       string
+	}{
+		{
+			name: "relative src and path",
+			input: mcptypes.BuildInput{
+				Src:             "./cmd/my-app",
+				Path:            "pkg",
+				DirectoryParams: mcptypes.DirectoryParams{RootDir: rootDir},
+			},
+			wantRootDir: rootDir,
+			wantSrc:     filepath.Join(rootDir, "cmd", "my-app"),
+			wantPath:    filepath.Join(rootDir, "pkg"),
+		},
+		{
+			name: "absolute src is kept",
+			input: mcptypes.BuildInput{
+				Src:             "/elsewhere/Containerfile",
+				DirectoryParams: mcptypes.DirectoryParams{RootDir: rootDir},
+			},
+			wantRootDir: rootDir,
+			wantSrc:     "/elsewhere/Containerfile",
+		},
+		{
+			name:        "empty root dir defaults to the working directory",
+			input:       mcptypes.BuildInput{Src: "./cmd/my-app"},
+			chdir:       rootDir,
+			wantRootDir: rootDir,
+			wantSrc:     filepath.Join(rootDir, "cmd", "my-app"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.chdir != "" {
+				t.Chdir(tt.chdir)
+			}
+
+			var before, got mcptypes.BuildInput
+			config := BuilderConfig{
+				Name:    "test-builder",
+				Version: "1.0.0",
+				BeforeBuild: func(ctx context.Context, input mcptypes.BuildInput) error {
+					before = input
+					return nil
+				},
+				BuildFunc: func(ctx context.Context, input mcptypes.BuildInput) (*forge.Artifact, error) {
+					got = input
+					return CreateArtifact(input.Name, "binary", input.Src), nil
+				},
+			}
+
+			input := tt.input
+			input.Name = "my-app"
+			input.Engine = "go://test-builder"
+			if _, _, err := makeBuildHandler(config)(context.Background(), &mcp.CallToolRequest{}, input); err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			if got.RootDir != tt.wantRootDir || got.Src != tt.wantSrc || got.Path != tt.wantPath {
+				t.Errorf("BuildFunc got RootDir=%q Src=%q Path=%q, want RootDir=%q Src=%q Path=%q",
+					got.RootDir, got.Src, got.Path, tt.wantRootDir, tt.wantSrc, tt.wantPath)
+			}
+			if before.RootDir != got.RootDir || before.Src != got.Src || before.Path != got.Path {
+				t.Errorf("BeforeBuild got %+v, want the same paths as BuildFunc", before)
+			}
+		})
+	}
+}
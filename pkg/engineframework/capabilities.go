@@ -0,0 +1,198 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CapabilitiesToolName is the name of the MCP tool returning an engine's Capabilities.
+const CapabilitiesToolName = "capabilities"
+
+// Capability is an optional feature an engine may support.
+type Capability string
+
+const (
+	// CapabilityBatch means the engine handles several inputs in a single call (e.g. "buildBatch").
+	CapabilityBatch Capability = "batch"
+	// CapabilityDryRun means the engine honors dryRun inputs and reports planned actions without side effects.
+	CapabilityDryRun Capability = "dry-run"
+	// CapabilityStreaming means the engine streams progress while an operation runs.
+	CapabilityStreaming Capability = "streaming"
+	// CapabilityCancellation means the engine stops an operation when its context is canceled.
+	CapabilityCancellation Capability = "cancellation"
+)
+
+// EngineKind is the kind of engine advertising capabilities.
+type EngineKind string
+
+const (
+	// EngineKindBuilder is an engine registered with RegisterBuilderTools.
+	EngineKindBuilder EngineKind = "builder"
+	// EngineKindTestRunner is an engine registered with RegisterTestRunnerTools.
+	EngineKindTestRunner EngineKind = "test-runner"
+	// EngineKindTestEnvSubengine is an engine registered with RegisterTestEnvSubengineTools.
+	EngineKindTestEnvSubengine EngineKind = "testenv"
+)
+
+// Capabilities describes the optional features supported by an engine.
+// It is returned by the "capabilities" tool registered by each framework.
+type Capabilities struct {
+	Engine   string       `json:"engine,omitempty"`
+	Version  string       `json:"version,omitempty"`
+	Kind     EngineKind   `json:"kind,omitempty"`
+	Features []Capability `json:"features"`
+}
+
+// Supports reports whether the engine advertises the feature.
+func (c Capabilities) Supports(feature Capability) bool {
+	return slices.Contains(c.Features, feature)
+}
+
+// CapabilitiesInput is the input of the capabilities tool. It takes no parameters.
+type CapabilitiesInput struct{}
+
+// newCapabilities builds the Capabilities of an engine from the features its framework
+// provides and the features declared by its implementation. Features are deduplicated and sorted.
+func newCapabilities(name, version string, kind EngineKind, builtin, declared []Capability) Capabilities {
+	features := make([]Capability, 0, len(builtin)+len(declared))
+	features = append(features, builtin...)
+	features = append(features, declared...)
+	slices.Sort(features)
+
+	return Capabilities{
+		Engine:   name,
+		Version:  version,
+		Kind:     kind,
+		Features: slices.Compact(features),
+	}
+}
+
+// registerCapabilitiesTool registers the "capabilities" tool with the MCP server.
+func registerCapabilitiesTool(server *mcpserver.Server, capabilities Capabilities) {
+	mcpserver.RegisterTool(server, &mcp.Tool{
+		Name:        CapabilitiesToolName,
+		Description: fmt.Sprintf("List the optional features supported by %s (e.g. batch, dry-run, streaming, cancellation).", capabilities.Engine),
+	}, makeCapabilitiesHandler(capabilities))
+}
+
+// makeCapabilitiesHandler creates the MCP handler of the capabilities tool.
+func makeCapabilitiesHandler(capabilities Capabilities) func(context.Context, *mcp.CallToolRequest, CapabilitiesInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input CapabilitiesInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("Listing capabilities of %s: %v", capabilities.Engine, capabilities.Features)
+
+		result, returned := mcputil.SuccessResultWithArtifact(
+			fmt.Sprintf("%s supports %d optional features", capabilities.Engine, len(capabilities.Features)),
+			capabilities,
+		)
+		return result, returned, nil
+	}
+}
+
+// QueryCapabilities asks an engine which optional features it supports.
+// It spawns the engine as an MCP server (cmd args --mcp) and calls its "capabilities" tool.
+//
+// Engines that predate capabilities negotiation do not register the tool; for them
+// QueryCapabilities returns Capabilities without any feature, so that callers fall
+// back to the baseline behavior.
+//
+// Example usage:
+//
+//	cmd, args, err := ResolveDetector("go://go-build", "v0.9.0")
+//	// ...
+//	capabilities, err := QueryCapabilities(ctx, cmd, args)
+//	if err == nil && capabilities.Supports(CapabilityBatch) {
+//	    // call buildBatch
+//	}
+func QueryCapabilities(ctx context.Context, cmd string, args []string) (*Capabilities, error) {
+	execCmd := exec.Command(cmd, append(args, "--mcp")...)
+	execCmd.Env = os.Environ()
+	execCmd.Stderr = os.Stderr // Forward logs
+
+	client := mcp.NewClient(&mcp.Implementation{
+		Name:    "capabilities-client",
+		Version: "v1.0.0",
+	}, nil)
+
+	session, err := client.Connect(ctx, &mcp.CommandTransport{Command: execCmd}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to engine: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	return queryCapabilities(ctx, session)
+}
+
+// queryCapabilities calls the capabilities tool over an established MCP session.
+func queryCapabilities(ctx context.Context, session *mcp.ClientSession) (*Capabilities, error) {
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list engine tools: %w", err)
+	}
+
+	registered := slices.ContainsFunc(tools.Tools, func(tool *mcp.Tool) bool {
+		return tool.Name == CapabilitiesToolName
+	})
+	if !registered {
+		return &Capabilities{Features: []Capability{}}, nil
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      CapabilitiesToolName,
+		Arguments: map[string]any{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MCP tool call failed: %w", err)
+	}
+
+	if result.IsError {
+		errMsg := "unknown error"
+		if len(result.Content) > 0 {
+			if textContent, ok := result.Content[0].(*mcp.TextContent); ok {
+				errMsg = textContent.Text
+			}
+		}
+		return nil, fmt.Errorf("capabilities query failed: %s", errMsg)
+	}
+
+	if result.StructuredContent == nil {
+		return nil, fmt.Errorf("no structured content returned from engine")
+	}
+
+	jsonBytes, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal capabilities: %w", err)
+	}
+
+	var capabilities Capabilities
+	if err := json.Unmarshal(jsonBytes, &capabilities); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capabilities: %w", err)
+	}
+	if capabilities.Features == nil {
+		capabilities.Features = []Capability{}
+	}
+
+	return &capabilities, nil
+}
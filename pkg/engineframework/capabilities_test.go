@@ -0,0 +1,132 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestBuilderCapabilities_AdvertisesBatchAndDryRun(t *testing.T) {
+	config := BuilderConfig{
+		Name:         "test-builder",
+		Version:      "1.0.0",
+		BuildFunc:    mockBuildFunc(false),
+		Capabilities: []Capability{CapabilityDryRun},
+	}
+
+	capabilities := builderCapabilities(config)
+
+	if capabilities.Engine != "test-builder" || capabilities.Version != "1.0.0" || capabilities.Kind != EngineKindBuilder {
+		t.Errorf("capabilities = %+v, want engine test-builder 1.0.0 of kind builder", capabilities)
+	}
+	for _, feature := range []Capability{CapabilityBatch, CapabilityDryRun} {
+		if !capabilities.Supports(feature) {
+			t.Errorf("builder does not advertise %s: %v", feature, capabilities.Features)
+		}
+	}
+	if capabilities.Supports(CapabilityStreaming) {
+		t.Errorf("builder advertises undeclared streaming: %v", capabilities.Features)
+	}
+}
+
+func TestBuilderCapabilities_BatchByDefault(t *testing.T) {
+	capabilities := builderCapabilities(BuilderConfig{Name: "test-builder", BuildFunc: mockBuildFunc(false)})
+
+	if want := []Capability{CapabilityBatch}; !reflect.DeepEqual(capabilities.Features, want) {
+		t.Errorf("features = %v, want %v", capabilities.Features, want)
+	}
+}
+
+func TestNewCapabilities_SortsAndDeduplicates(t *testing.T) {
+	capabilities := newCapabilities("engine", "1.0.0", EngineKindTestEnvSubengine,
+		[]Capability{CapabilityDryRun},
+		[]Capability{CapabilityCancellation, CapabilityDryRun})
+
+	want := []Capability{CapabilityCancellation, CapabilityDryRun}
+	if !reflect.DeepEqual(capabilities.Features, want) {
+		t.Errorf("features = %v, want %v", capabilities.Features, want)
+	}
+}
+
+// connectInMemory serves the given tools from an in-memory MCP server and returns a connected client session.
+func connectInMemory(t *testing.T, register func(server *mcp.Server)) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-engine", Version: "1.0.0"}, nil)
+	register(server)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	return session
+}
+
+func TestQueryCapabilities_Builder(t *testing.T) {
+	want := builderCapabilities(BuilderConfig{
+		Name:         "test-builder",
+		Version:      "1.0.0",
+		BuildFunc:    mockBuildFunc(false),
+		Capabilities: []Capability{CapabilityDryRun},
+	})
+	session := connectInMemory(t, func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: CapabilitiesToolName}, makeCapabilitiesHandler(want))
+	})
+
+	got, err := queryCapabilities(context.Background(), session)
+	if err != nil {
+		t.Fatalf("queryCapabilities() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("queryCapabilities() = %+v, want %+v", *got, want)
+	}
+	if !got.Supports(CapabilityBatch) || !got.Supports(CapabilityDryRun) {
+		t.Errorf("builder does not advertise batch and dry-run: %v", got.Features)
+	}
+}
+
+func TestQueryCapabilities_EngineWithoutCapabilitiesTool(t *testing.T) {
+	session := connectInMemory(t, func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "build"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return mcputil.SuccessResult("built"), nil, nil
+		})
+	})
+
+	got, err := queryCapabilities(context.Background(), session)
+	if err != nil {
+		t.Fatalf("queryCapabilities() unexpected error: %v", err)
+	}
+	if len(got.Features) != 0 {
+		t.Errorf("features = %v, want none for an engine predating capabilities", got.Features)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+)
+
+// notFoundMessages are lowercase fragments of the errors common CLIs and APIs report when the
+// resource to delete does not exist (kubectl, helm, docker, podman, kind, cloud CLIs).
+var notFoundMessages = []string{
+	"not found",
+	"notfound",
+	"no such container",
+	"no such image",
+	"no such network",
+	"no such volume",
+	"no such object",
+	"no such file or directory",
+	"does not exist",
+	"already deleted",
+}
+
+// missingCommandMessages are lowercase fragments of errors reporting a missing executable.
+// They contain "not found" but mean the deletion could not run at all.
+var missingCommandMessages = []string{
+	"executable file not found",
+	"command not found",
+}
+
+// IsNotFound reports whether err means that the resource to delete does not exist.
+//
+// It recognizes fs.ErrNotExist and the "not found" messages of common CLIs, e.g.:
+//   - kubectl: Error from server (NotFound): namespaces "test" not found
+//   - helm: Error: uninstall: Release not loaded: my-app: release: not found
+//   - docker: Error response from daemon: No such container: registry
+//   - podman: no container with name or ID "registry" found: no such container
+//
+// A missing executable (exec.ErrNotFound, "command not found") is not a not-found error.
+func IsNotFound(err error) bool {
+	if err == nil || errors.Is(err, exec.ErrNotFound) {
+		return false
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range missingCommandMessages {
+		if strings.Contains(msg, m) {
+			return false
+		}
+	}
+	for _, m := range notFoundMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreNotFound returns nil if err means that the resource to delete does not exist (see IsNotFound),
+// and err otherwise. DeleteFuncs use it to stay idempotent:
+//
+//	if err := deleteCluster(name); engineframework.IgnoreNotFound(err) != nil {
+//	    return err
+//	}
+func IgnoreNotFound(err error) error {
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteOutcomeKey is the context key of the deleteOutcome of a delete operation.
+type deleteOutcomeKey struct{}
+
+// deleteOutcome records whether a DeleteFunc found nothing to remove.
+type deleteOutcome struct {
+	nothingDeleted atomic.Bool
+}
+
+// withDeleteOutcome returns a context in which MarkNothingDeleted records to the returned outcome.
+func withDeleteOutcome(ctx context.Context) (context.Context, *deleteOutcome) {
+	outcome := &deleteOutcome{}
+	return context.WithValue(ctx, deleteOutcomeKey{}, outcome), outcome
+}
+
+// MarkNothingDeleted records that the current DeleteFunc found no resources to remove (they were
+// already absent), so that the delete result reports a no-op instead of a deletion.
+// It returns false if ctx does not come from a delete operation of the framework.
+func MarkNothingDeleted(ctx context.Context) bool {
+	outcome, ok := ctx.Value(deleteOutcomeKey{}).(*deleteOutcome)
+	if !ok {
+		return false
+	}
+	outcome.nothingDeleted.Store(true)
+	return true
+}
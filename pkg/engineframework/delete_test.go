@@ -0,0 +1,94 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestIsNotFound(t *testing.T) {
+	_, statErr := os.Stat("/nonexistent/forge-test-path")
+	_, lookErr := exec.LookPath("forge-nonexistent-command")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"fs.ErrNotExist", statErr, true},
+		{"wrapped fs.ErrNotExist", fmt.Errorf("remove marker: %w", os.ErrNotExist), true},
+		{"kubectl namespace", errors.New(`Error from server (NotFound): namespaces "test-ns" not found`), true},
+		{"helm release", errors.New("helm uninstall failed: exit status 1, output: Error: uninstall: Release not loaded: my-app: release: not found"), true},
+		{"docker container", errors.New("Error response from daemon: No such container: registry"), true},
+		{"docker volume", errors.New("Error response from daemon: get data: no such volume"), true},
+		{"docker network", errors.New("Error response from daemon: network kind not found"), true},
+		{"podman container", errors.New(`no container with name or ID "registry" found: no such container`), true},
+		{"kind cluster", errors.New(`unknown cluster "test": cluster does not exist`), true},
+		{"file", errors.New("rm: cannot remove 'kubeconfig': No such file or directory"), true},
+		{"missing executable", lookErr, false},
+		{"wrapped missing executable", fmt.Errorf("delete cluster: %w", lookErr), false},
+		{"shell command not found", errors.New("sh: 1: kind: command not found"), false},
+		{"permission denied", errors.New("rm: cannot remove 'kubeconfig': Permission denied"), false},
+		{"timeout", errors.New("helm uninstall timed out after 3 minutes"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.want {
+				t.Errorf("IsNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreNotFound(t *testing.T) {
+	if err := IgnoreNotFound(errors.New("Error response from daemon: No such container: registry")); err != nil {
+		t.Errorf("IgnoreNotFound() = %v, want nil", err)
+	}
+
+	failure := errors.New("permission denied")
+	if err := IgnoreNotFound(failure); err != failure {
+		t.Errorf("IgnoreNotFound() = %v, want %v", err, failure)
+	}
+
+	if err := IgnoreNotFound(nil); err != nil {
+		t.Errorf("IgnoreNotFound(nil) = %v, want nil", err)
+	}
+}
+
+func TestMarkNothingDeleted(t *testing.T) {
+	if MarkNothingDeleted(context.Background()) {
+		t.Error("MarkNothingDeleted() = true without a delete operation, want false")
+	}
+
+	ctx, outcome := withDeleteOutcome(context.Background())
+	if outcome.nothingDeleted.Load() {
+		t.Fatal("new delete outcome reports nothing deleted")
+	}
+	if !MarkNothingDeleted(ctx) {
+		t.Fatal("MarkNothingDeleted() = false, want true")
+	}
+	if !outcome.nothingDeleted.Load() {
+		t.Error("MarkNothingDeleted() did not record the outcome")
+	}
+}
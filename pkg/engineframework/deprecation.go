@@ -0,0 +1,110 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"log"
+	"sync"
+)
+
+// specDeprecation maps a deprecated spec key to its replacement.
+type specDeprecation struct {
+	key         string
+	replacement string
+}
+
+// specDeprecations is the registry of deprecated spec keys consulted by the Extract* helpers.
+var specDeprecations = struct {
+	sync.Mutex
+	entries []specDeprecation
+	warned  map[string]bool
+}{warned: make(map[string]bool)}
+
+// DeprecateSpecKey marks a spec key as deprecated.
+//
+// Once registered, every Extract*/Require* helper logs a warning (once per process)
+// when the deprecated key is read. When replacement is not empty, reading the
+// replacement key falls back to the deprecated key if the replacement is absent,
+// so that old specs keep working. The replacement key wins when both are set.
+//
+// Engines typically register deprecations at init time:
+//
+//	func init() {
+//	    engineframework.DeprecateSpecKey("image", "imageRef")
+//	}
+//
+//	// spec := map[string]any{"image": "nginx"}
+//	ref, ok := engineframework.ExtractString(spec, "imageRef")  // "nginx", true (logs a warning)
+func DeprecateSpecKey(key, replacement string) {
+	specDeprecations.Lock()
+	defer specDeprecations.Unlock()
+
+	for i, d := range specDeprecations.entries {
+		if d.key == key {
+			specDeprecations.entries[i].replacement = replacement
+			return
+		}
+	}
+	specDeprecations.entries = append(specDeprecations.entries, specDeprecation{key: key, replacement: replacement})
+}
+
+// lookupSpecValue returns the value of key in spec, honoring deprecated keys.
+// It warns when a deprecated key is read, directly or through its replacement.
+func lookupSpecValue(spec map[string]any, key string) (any, bool) {
+	if spec == nil {
+		return nil, false
+	}
+
+	specDeprecations.Lock()
+	defer specDeprecations.Unlock()
+
+	if value, exists := spec[key]; exists {
+		for _, d := range specDeprecations.entries {
+			if d.key == key {
+				warnDeprecatedSpecKey(d)
+				break
+			}
+		}
+		return value, true
+	}
+
+	// Fall back to a deprecated key replaced by key
+	for _, d := range specDeprecations.entries {
+		if d.replacement != key {
+			continue
+		}
+		if value, exists := spec[d.key]; exists {
+			warnDeprecatedSpecKey(d)
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// warnDeprecatedSpecKey logs a deprecation warning the first time d.key is read.
+// The caller must hold the specDeprecations lock.
+func warnDeprecatedSpecKey(d specDeprecation) {
+	if specDeprecations.warned[d.key] {
+		return
+	}
+	specDeprecations.warned[d.key] = true
+
+	if d.replacement == "" {
+		log.Printf("Warning: spec field %q is deprecated and will be removed in a future version", d.key)
+		return
+	}
+	log.Printf("Warning: spec field %q is deprecated and will be removed in a future version, use %q instead", d.key, d.replacement)
+}
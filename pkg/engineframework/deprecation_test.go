@@ -0,0 +1,137 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// withSpecDeprecations resets the deprecation registry, registers the given
+// deprecations (key -> replacement) and captures log output for the test.
+func withSpecDeprecations(t *testing.T, deprecations map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	specDeprecations.Lock()
+	specDeprecations.entries = nil
+	specDeprecations.warned = make(map[string]bool)
+	specDeprecations.Unlock()
+
+	for key, replacement := range deprecations {
+		DeprecateSpecKey(key, replacement)
+	}
+
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+
+	t.Cleanup(func() {
+		log.SetOutput(previous)
+		specDeprecations.Lock()
+		specDeprecations.entries = nil
+		specDeprecations.warned = make(map[string]bool)
+		specDeprecations.Unlock()
+	})
+
+	return &buf
+}
+
+func TestDeprecateSpecKey_ReplacementFallsBackToDeprecatedKey(t *testing.T) {
+	logs := withSpecDeprecations(t, map[string]string{"image": "imageRef"})
+
+	spec := map[string]any{"image": "nginx:1.25"}
+
+	got, ok := ExtractString(spec, "imageRef")
+	if !ok || got != "nginx:1.25" {
+		t.Errorf("ExtractString(imageRef) = %q, %v, want %q, true", got, ok, "nginx:1.25")
+	}
+
+	if !strings.Contains(logs.String(), `spec field "image" is deprecated`) {
+		t.Errorf("expected deprecation warning, got logs: %q", logs.String())
+	}
+	if !strings.Contains(logs.String(), `use "imageRef" instead`) {
+		t.Errorf("expected replacement hint, got logs: %q", logs.String())
+	}
+}
+
+func TestDeprecateSpecKey_WarnsOnce(t *testing.T) {
+	logs := withSpecDeprecations(t, map[string]string{"tags": "labels"})
+
+	spec := map[string]any{"tags": []any{"a", "b"}}
+
+	for i := 0; i < 3; i++ {
+		got, ok := ExtractStringSlice(spec, "tags")
+		if !ok || strings.Join(got, ",") != "a,b" {
+			t.Fatalf("ExtractStringSlice(tags) = %v, %v, want [a b], true", got, ok)
+		}
+		if got := ExtractStringSliceWithDefault(spec, "labels", nil); strings.Join(got, ",") != "a,b" {
+			t.Fatalf("ExtractStringSliceWithDefault(labels) = %v, want [a b]", got)
+		}
+	}
+
+	if n := strings.Count(logs.String(), "is deprecated"); n != 1 {
+		t.Errorf("expected exactly 1 deprecation warning, got %d: %q", n, logs.String())
+	}
+}
+
+func TestDeprecateSpecKey_ReplacementWins(t *testing.T) {
+	logs := withSpecDeprecations(t, map[string]string{"timeout": "timeoutSeconds"})
+
+	spec := map[string]any{"timeout": 10, "timeoutSeconds": 30}
+
+	if got := ExtractIntWithDefault(spec, "timeoutSeconds", 0); got != 30 {
+		t.Errorf("ExtractIntWithDefault(timeoutSeconds) = %d, want 30", got)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no warning when the replacement key is set, got logs: %q", logs.String())
+	}
+}
+
+func TestDeprecateSpecKey_NoReplacement(t *testing.T) {
+	logs := withSpecDeprecations(t, map[string]string{"legacy": ""})
+
+	spec := map[string]any{"legacy": true}
+
+	got, err := RequireBool(spec, "legacy")
+	if err != nil || !got {
+		t.Errorf("RequireBool(legacy) = %v, %v, want true, nil", got, err)
+	}
+	if !strings.Contains(logs.String(), `spec field "legacy" is deprecated and will be removed`) {
+		t.Errorf("expected deprecation warning, got logs: %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "instead") {
+		t.Errorf("expected no replacement hint, got logs: %q", logs.String())
+	}
+}
+
+func TestDeprecateSpecKey_UnrelatedKeys(t *testing.T) {
+	logs := withSpecDeprecations(t, map[string]string{"old": "new"})
+
+	spec := map[string]any{"name": "app"}
+
+	if _, ok := ExtractString(spec, "new"); ok {
+		t.Error("ExtractString(new) ok = true, want false when neither key is set")
+	}
+	if got := ExtractStringWithDefault(spec, "name", ""); got != "app" {
+		t.Errorf("ExtractStringWithDefault(name) = %q, want %q", got, "app")
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no warning, got logs: %q", logs.String())
+	}
+}
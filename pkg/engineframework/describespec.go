@@ -0,0 +1,73 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DescribeSpecToolName is the name of the MCP tool returning the JSON schema of an engine's spec.
+const DescribeSpecToolName = "describe-spec"
+
+var errInvalidSpecSchema = errors.New("spec schema is not a JSON object")
+
+// SpecDescription is returned by the "describe-spec" tool.
+type SpecDescription struct {
+	Engine string `json:"engine,omitempty"`
+	// Schema is the JSON schema of the spec accepted by the engine (forge.yaml build[].spec, test[].spec, ...)
+	Schema json.RawMessage `json:"schema"`
+}
+
+// DescribeSpecInput is the input of the describe-spec tool. It takes no parameters.
+type DescribeSpecInput struct{}
+
+// registerDescribeSpecTool registers the "describe-spec" tool with the MCP server when schema is set.
+// It returns an error when schema is not a JSON object.
+func registerDescribeSpecTool(server *mcpserver.Server, name string, schema json.RawMessage) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal(schema, &object); err != nil || object == nil {
+		return fmt.Errorf("%w: %s", errInvalidSpecSchema, name)
+	}
+
+	mcpserver.RegisterTool(server, &mcp.Tool{
+		Name:        DescribeSpecToolName,
+		Description: fmt.Sprintf("Return the JSON schema of the spec accepted by %s in forge.yaml.", name),
+	}, makeDescribeSpecHandler(SpecDescription{Engine: name, Schema: schema}))
+	return nil
+}
+
+// makeDescribeSpecHandler creates the MCP handler of the describe-spec tool.
+func makeDescribeSpecHandler(description SpecDescription) func(context.Context, *mcp.CallToolRequest, DescribeSpecInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input DescribeSpecInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("Describing the spec of %s", description.Engine)
+
+		result, returned := mcputil.SuccessResultWithArtifact(
+			fmt.Sprintf("Spec schema of %s", description.Engine),
+			description,
+		)
+		return result, returned, nil
+	}
+}
@@ -0,0 +1,114 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const testSpecSchema = `{"type":"object","properties":{"command":{"type":"string"}},"required":["command"]}`
+
+func TestDescribeSpecTool_ReturnsSchema(t *testing.T) {
+	description := SpecDescription{Engine: "test-runner", Schema: json.RawMessage(testSpecSchema)}
+	session := connectInMemory(t, func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: DescribeSpecToolName}, makeDescribeSpecHandler(description))
+	})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      DescribeSpecToolName,
+		Arguments: map[string]any{},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("describe-spec returned error result: %v", result.Content)
+	}
+
+	b, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Engine string         `json:"engine"`
+		Schema map[string]any `json:"schema"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("failed to decode structured content: %v", err)
+	}
+
+	if got.Engine != "test-runner" {
+		t.Errorf("engine = %q, want %q", got.Engine, "test-runner")
+	}
+	if got.Schema["type"] != "object" || got.Schema["required"] == nil {
+		t.Errorf("schema = %v, want %s", got.Schema, testSpecSchema)
+	}
+}
+
+func TestRegisterTools_DescribeSpec(t *testing.T) {
+	schema := json.RawMessage(testSpecSchema)
+	register := map[string]func(*mcpserver.Server, json.RawMessage) error{
+		"builder": func(server *mcpserver.Server, schema json.RawMessage) error {
+			return RegisterBuilderTools(server, BuilderConfig{Name: "engine", BuildFunc: mockBuildFunc(false), SpecSchema: schema})
+		},
+		"test-runner": func(server *mcpserver.Server, schema json.RawMessage) error {
+			return RegisterTestRunnerTools(server, TestRunnerConfig{Name: "engine", RunTestFunc: mockTestRunnerFunc(false), SpecSchema: schema})
+		},
+		"testenv": func(server *mcpserver.Server, schema json.RawMessage) error {
+			return RegisterTestEnvSubengineTools(server, TestEnvSubengineConfig{Name: "engine", CreateFunc: mockCreateFunc(false), DeleteFunc: mockDeleteFunc(false), SpecSchema: schema})
+		},
+	}
+
+	for kind, fn := range register {
+		t.Run(kind, func(t *testing.T) {
+			for _, tt := range []struct {
+				name    string
+				schema  json.RawMessage
+				want    bool
+				wantErr error
+			}{
+				{name: "with schema", schema: schema, want: true},
+				{name: "without schema", schema: nil, want: false},
+				{name: "not an object", schema: json.RawMessage(`["command"]`), wantErr: errInvalidSpecSchema},
+				{name: "invalid JSON", schema: json.RawMessage(`{`), wantErr: errInvalidSpecSchema},
+			} {
+				server := mcpserver.New("engine", "1.0.0")
+				err := fn(server, tt.schema)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("%s: register error = %v, want %v", tt.name, err, tt.wantErr)
+				}
+				if tt.wantErr != nil {
+					continue
+				}
+
+				registered := slices.ContainsFunc(mcpserver.RegisteredTools(server), func(tool mcpserver.ToolInfo) bool {
+					return tool.Name == DescribeSpecToolName
+				})
+				if registered != tt.want {
+					t.Errorf("%s: describe-spec registered = %v, want %v", tt.name, registered, tt.want)
+				}
+			}
+		})
+	}
+}
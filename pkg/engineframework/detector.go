@@ -17,11 +17,15 @@ package engineframework
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/alexandremahdhaoui/forge/internal/forgepath"
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
@@ -29,16 +33,28 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// detectorBinDir is the build output directory bin:// detector URIs resolve against,
+// relative to the project root.
+var detectorBinDir = filepath.Join("build", "bin")
+
 // ResolveDetector parses a detector URI and returns the command and args to execute it.
-// Detectors only support go:// URIs.
+// Detectors support the following URIs:
+//   - go://<name>: runs the detector of this forge version with "go run"
+//   - file://<path> or a bare path containing a "/": executes the local binary at path
+//   - bin://<name>: executes <name> from the build output directory (./build/bin), relative to
+//     the directory of the --config file if the engine was given one (see enginecli.RootDir)
+//
+// Local binaries must exist and be executable. They let developers iterate on a detector
+// without publishing it.
 //
 // Parameters:
 //   - detectorURI: URI of the detector (e.g., "go://go-dependency-detector")
-//   - forgeVersion: Version of forge to use (e.g., "v0.9.0")
+//   - forgeVersion: Version of forge to use for go:// URIs (e.g., "v0.9.0")
 //
 // Returns:
-//   - cmd: The command to execute (always "go")
-//   - args: Arguments for the command (e.g., ["run", "github.com/.../cmd/detector@v0.9.0"])
+//   - cmd: The command to execute ("go" for go:// URIs, the absolute binary path otherwise)
+//   - args: Arguments for the command (e.g., ["run", "github.com/.../cmd/detector@v0.9.0"]),
+//     empty for local binaries
 //   - err: Error if the URI is invalid or resolution fails
 //
 // Example usage:
@@ -46,14 +62,39 @@ import (
 //	cmd, args, err := ResolveDetector("go://go-dependency-detector", "v0.9.0")
 //	// cmd = "go"
 //	// args = ["run", "github.com/alexandremahdhaoui/forge/cmd/go-dependency-detector@v0.9.0"]
+//
+//	cmd, args, err = ResolveDetector("bin://go-dependency-detector", "v0.9.0")
+//	// cmd = "/path/to/project/build/bin/go-dependency-detector"
+//	// args = nil
 func ResolveDetector(detectorURI, forgeVersion string) (cmd string, args []string, err error) {
-	// Validate URI starts with go://
-	if !strings.HasPrefix(detectorURI, "go://") {
-		return "", nil, fmt.Errorf("unsupported detector protocol: %s (must start with go://)", detectorURI)
+	switch {
+	case strings.HasPrefix(detectorURI, "go://"):
+		return resolveGoDetector(strings.TrimPrefix(detectorURI, "go://"), forgeVersion)
+
+	case strings.HasPrefix(detectorURI, "file://"):
+		path := strings.TrimPrefix(detectorURI, "file://")
+		if path == "" {
+			return "", nil, fmt.Errorf("empty detector path after file://")
+		}
+		return resolveLocalDetector(path)
+
+	case strings.HasPrefix(detectorURI, "bin://"):
+		name := strings.TrimPrefix(detectorURI, "bin://")
+		if name == "" {
+			return "", nil, fmt.Errorf("empty detector name after bin://")
+		}
+		return resolveLocalDetector(filepath.Join(resolveRootDir(""), detectorBinDir, name))
+
+	case !strings.Contains(detectorURI, "://") && strings.ContainsRune(filepath.ToSlash(detectorURI), '/'):
+		return resolveLocalDetector(detectorURI)
+
+	default:
+		return "", nil, fmt.Errorf("unsupported detector protocol: %s (must start with go://, file:// or bin://, or be a path)", detectorURI)
 	}
+}
 
-	// Extract detector name from URI
-	detectorName := strings.TrimPrefix(detectorURI, "go://")
+// resolveGoDetector returns the "go run" command of the forge detector named detectorName.
+func resolveGoDetector(detectorName, forgeVersion string) (string, []string, error) {
 	if detectorName == "" {
 		return "", nil, fmt.Errorf("empty detector name after go://")
 	}
@@ -67,6 +108,28 @@ func ResolveDetector(detectorURI, forgeVersion string) (cmd string, args []strin
 	return "go", runArgs, nil
 }
 
+// resolveLocalDetector returns the absolute path of the detector binary at path,
+// after checking that it is an executable regular file.
+func resolveLocalDetector(path string) (string, []string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve detector path %s: %w", path, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("detector binary not found: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", nil, fmt.Errorf("detector %s is not a regular file", absPath)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		return "", nil, fmt.Errorf("detector %s is not executable", absPath)
+	}
+
+	return absPath, nil, nil
+}
+
 // FindDetector locates a dependency detector binary by name.
 // It searches in the following order:
 //  1. PATH environment variable
@@ -97,9 +160,51 @@ func FindDetector(name string) (string, error) {
 	return "", fmt.Errorf("%s not found in PATH or ./build/bin", name)
 }
 
+// DefaultDetectorTimeout bounds a detector MCP call made by CallDetector.
+const DefaultDetectorTimeout = 60 * time.Second
+
+// DetectorErrorKind classifies why a detector call failed.
+type DetectorErrorKind string
+
+const (
+	// DetectorErrorSpawn means the detector could not be built, started or connected to.
+	DetectorErrorSpawn DetectorErrorKind = "spawn"
+	// DetectorErrorTimeout means the detector did not answer within the timeout.
+	DetectorErrorTimeout DetectorErrorKind = "timeout"
+	// DetectorErrorTool means the detector ran but its tool failed or returned an invalid result.
+	DetectorErrorTool DetectorErrorKind = "tool"
+)
+
+// DetectorError is the error returned by CallDetector when dependency detection fails.
+// Builders can inspect it with errors.As, e.g. to proceed without dependencies instead of
+// failing the build.
+type DetectorError struct {
+	// Kind tells whether the detector failed to spawn, timed out or returned a tool error.
+	Kind DetectorErrorKind
+	// Detector is the command line of the detector.
+	Detector string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *DetectorError) Error() string {
+	return fmt.Sprintf("detector %s (%s error): %v", e.Detector, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DetectorError) Unwrap() error {
+	return e.Err
+}
+
 // CallDetector calls a detector MCP server and returns dependencies.
 // It spawns the detector as a subprocess, connects via MCP, calls the specified tool,
 // and converts the response to []forge.ArtifactDependency.
+// A "go run" command is built into a temporary binary on first use, which later calls with the
+// same args reuse for the rest of the process.
+//
+// The MCP call is bounded by DefaultDetectorTimeout; use CallDetectorWithTimeout to change it.
+// Failures are returned as a *DetectorError.
 //
 // Parameters:
 //   - ctx: context for the operation
@@ -112,8 +217,40 @@ func FindDetector(name string) (string, error) {
 //   - []forge.ArtifactDependency: list of detected dependencies
 //   - error: if connection fails, tool call fails, or response parsing fails
 func CallDetector(ctx context.Context, cmd string, args []string, toolName string, input any) ([]forge.ArtifactDependency, error) {
-	// Create command to spawn MCP server (append --mcp flag)
-	execCmd := exec.Command(cmd, append(args, "--mcp")...)
+	return CallDetectorWithTimeout(ctx, DefaultDetectorTimeout, cmd, args, toolName, input)
+}
+
+// CallDetectorWithTimeout is CallDetector with the timeout of the MCP call (connection and
+// tool call). A zero or negative timeout means DefaultDetectorTimeout. Building a "go run"
+// detector is not bounded by the timeout.
+func CallDetectorWithTimeout(ctx context.Context, timeout time.Duration, cmd string, args []string, toolName string, input any) ([]forge.ArtifactDependency, error) {
+	detector := strings.Join(append([]string{cmd}, args...), " ")
+
+	// Build "go run" detectors once per process instead of recompiling them on every call
+	if cmd == "go" && slices.Contains(args, "run") {
+		var err error
+		cmd, args, err = detectorBinaries.command(ctx, args)
+		if err != nil {
+			return nil, &DetectorError{Kind: DetectorErrorSpawn, Detector: detector, Err: fmt.Errorf("failed to build detector: %w", err)}
+		}
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultDetectorTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// callError classifies err as a timeout when the call ran out of time
+	callError := func(kind DetectorErrorKind, err error) error {
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return &DetectorError{Kind: DetectorErrorTimeout, Detector: detector, Err: fmt.Errorf("no answer within %s: %w", timeout, err)}
+		}
+		return &DetectorError{Kind: kind, Detector: detector, Err: err}
+	}
+
+	// Create command to spawn MCP server (append --mcp flag); it is killed on timeout
+	execCmd := exec.CommandContext(callCtx, cmd, append(args, "--mcp")...)
 	execCmd.Env = os.Environ()
 	execCmd.Stderr = os.Stderr // Forward logs
 
@@ -129,21 +266,30 @@ func CallDetector(ctx context.Context, cmd string, args []string, toolName strin
 	}
 
 	// Connect to the MCP server
-	session, err := client.Connect(ctx, transport, nil)
+	session, err := client.Connect(callCtx, transport, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to detector: %w", err)
+		return nil, callError(DetectorErrorSpawn, fmt.Errorf("failed to connect to detector: %w", err))
 	}
 	defer func() { _ = session.Close() }()
 
 	// Call the tool
-	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+	result, err := session.CallTool(callCtx, &mcp.CallToolParams{
 		Name:      toolName,
 		Arguments: input,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("MCP tool call failed: %w", err)
+		return nil, callError(DetectorErrorTool, fmt.Errorf("MCP tool call failed: %w", err))
 	}
 
+	deps, err := parseDetectorResult(result)
+	if err != nil {
+		return nil, &DetectorError{Kind: DetectorErrorTool, Detector: detector, Err: err}
+	}
+	return deps, nil
+}
+
+// parseDetectorResult converts the result of a detector tool call to dependencies.
+func parseDetectorResult(result *mcp.CallToolResult) ([]forge.ArtifactDependency, error) {
 	// Check if result indicates an error
 	if result.IsError {
 		errMsg := "unknown error"
@@ -0,0 +1,182 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alexandremahdhaoui/forge/internal/tempdir"
+	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
+)
+
+// detectorBinaries caches the detectors that CallDetector runs with "go run", built once per process.
+var detectorBinaries = newDetectorBinaryCache(buildDetectorBinary)
+
+// detectorBuild describes how to build the package of a "go run" command into a binary.
+type detectorBuild struct {
+	// args are the arguments of the go command building the package
+	args []string
+	// env are the environment variables added to the go command
+	env []string
+	// binary is the path of the built binary
+	binary string
+	// programArgs are the arguments that followed the package in the "go run" command
+	programArgs []string
+}
+
+// goBuildCommand converts the arguments of a "go run" command into a detectorBuild writing the
+// binary into dir. Versioned packages (pkg@version) are built with "go install" and GOBIN, since
+// "go build" does not accept versions; local packages are built with "go build -o".
+func goBuildCommand(runArgs []string, dir string) (detectorBuild, error) {
+	i := slices.Index(runArgs, "run")
+	if i < 0 || i+1 >= len(runArgs) {
+		return detectorBuild{}, fmt.Errorf("not a go run command: go %s", strings.Join(runArgs, " "))
+	}
+	flags, pkg := runArgs[:i], runArgs[i+1]
+
+	modulePath, _, versioned := strings.Cut(pkg, "@")
+	name := path.Base(filepath.ToSlash(modulePath))
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	b := detectorBuild{
+		binary:      filepath.Join(dir, name),
+		programArgs: slices.Clone(runArgs[i+2:]),
+	}
+	if versioned {
+		b.args = append(slices.Clone(flags), "install", pkg)
+		b.env = []string{"GOBIN=" + dir}
+	} else {
+		b.args = append(slices.Clone(flags), "build", "-o", b.binary, pkg)
+	}
+	return b, nil
+}
+
+// buildDetectorBinary runs the go command of b.
+func buildDetectorBinary(ctx context.Context, b detectorBuild) error {
+	cmd := exec.CommandContext(ctx, "go", b.args...)
+	cmd.Env = append(os.Environ(), b.env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go %s: %w: %s", strings.Join(b.args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// detectorBinaryCache builds detectors into a temporary directory and reuses the binaries,
+// keyed by their "go run" arguments, i.e. the detector URI resolved for a forge version.
+type detectorBinaryCache struct {
+	build func(ctx context.Context, b detectorBuild) error
+
+	mu      sync.Mutex
+	dir     string
+	entries map[string]*detectorBinary
+}
+
+// detectorBinary is a cache entry. Its mutex makes concurrent callers wait for a single build.
+type detectorBinary struct {
+	mu    sync.Mutex
+	dir   string
+	built *detectorBuild
+}
+
+// newDetectorBinaryCache returns an empty cache building detectors with build.
+func newDetectorBinaryCache(build func(ctx context.Context, b detectorBuild) error) *detectorBinaryCache {
+	return &detectorBinaryCache{
+		build:   build,
+		entries: make(map[string]*detectorBinary),
+	}
+}
+
+// command returns the command and args running the detector of the "go run" command runArgs,
+// building it on first use. A failed build is not cached, so that a later call retries it.
+//
+// The temporary directory is created on first use and removed by cleanup, which is registered
+// to run when enginecli.Bootstrap exits the process.
+func (c *detectorBinaryCache) command(ctx context.Context, runArgs []string) (string, []string, error) {
+	entry, err := c.entry(strings.Join(runArgs, " "))
+	if err != nil {
+		return "", nil, err
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.built == nil {
+		b, err := goBuildCommand(runArgs, entry.dir)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := os.MkdirAll(entry.dir, 0o755); err != nil {
+			return "", nil, fmt.Errorf("failed to create detector build directory: %w", err)
+		}
+		log.Printf("Building detector once for this process: go %s", strings.Join(b.args, " "))
+		if err := c.build(ctx, b); err != nil {
+			return "", nil, err
+		}
+		entry.built = &b
+	}
+
+	return entry.built.binary, slices.Clone(entry.built.programArgs), nil
+}
+
+// entry returns the cache entry of key, creating the temporary directory on first use.
+func (c *detectorBinaryCache) entry(key string) (*detectorBinary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dir == "" {
+		dir, err := tempdir.MkdirTemp("forge-detectors-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create detector cache directory: %w", err)
+		}
+		c.dir = dir
+		enginecli.OnExit(c.cleanup)
+	}
+
+	entry, ok := c.entries[key]
+	if !ok {
+		// Each detector gets its own directory: binaries of different versions share a name
+		entry = &detectorBinary{dir: filepath.Join(c.dir, strconv.Itoa(len(c.entries)))}
+		c.entries[key] = entry
+	}
+	return entry, nil
+}
+
+// cleanup removes the temporary directory and forgets the built detectors.
+func (c *detectorBinaryCache) cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dir == "" {
+		return
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		log.Printf("Warning: failed to remove detector cache directory %s: %v", c.dir, err)
+	}
+	c.dir = ""
+	c.entries = make(map[string]*detectorBinary)
+}
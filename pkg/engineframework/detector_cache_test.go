@@ -0,0 +1,203 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGoBuildCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		runArgs     []string
+		wantArgs    []string
+		wantEnv     []string
+		wantBinary  string
+		wantProgram []string
+	}{
+		{
+			name:       "versioned package",
+			runArgs:    []string{"run", "github.com/alexandremahdhaoui/forge/cmd/go-dependency-detector@v0.9.0"},
+			wantArgs:   []string{"install", "github.com/alexandremahdhaoui/forge/cmd/go-dependency-detector@v0.9.0"},
+			wantEnv:    []string{"GOBIN=" + dir},
+			wantBinary: filepath.Join(dir, "go-dependency-detector"),
+		},
+		{
+			name:       "local package",
+			runArgs:    []string{"run", "/src/forge/cmd/go-dependency-detector"},
+			wantArgs:   []string{"build", "-o", filepath.Join(dir, "go-dependency-detector"), "/src/forge/cmd/go-dependency-detector"},
+			wantBinary: filepath.Join(dir, "go-dependency-detector"),
+		},
+		{
+			name:        "flags and program args",
+			runArgs:     []string{"-C", "/src/forge", "run", "./cmd/go-dependency-detector", "--verbose"},
+			wantArgs:    []string{"-C", "/src/forge", "build", "-o", filepath.Join(dir, "go-dependency-detector"), "./cmd/go-dependency-detector"},
+			wantBinary:  filepath.Join(dir, "go-dependency-detector"),
+			wantProgram: []string{"--verbose"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := goBuildCommand(tt.runArgs, dir)
+			if err != nil {
+				t.Fatalf("goBuildCommand returned error: %v", err)
+			}
+			if !slices.Equal(b.args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", b.args, tt.wantArgs)
+			}
+			if !slices.Equal(b.env, tt.wantEnv) {
+				t.Errorf("env = %v, want %v", b.env, tt.wantEnv)
+			}
+			if b.binary != tt.wantBinary {
+				t.Errorf("binary = %q, want %q", b.binary, tt.wantBinary)
+			}
+			if !slices.Equal(b.programArgs, tt.wantProgram) {
+				t.Errorf("programArgs = %v, want %v", b.programArgs, tt.wantProgram)
+			}
+		})
+	}
+
+	if _, err := goBuildCommand([]string{"version"}, dir); err == nil {
+		t.Error("expected an error for a command that is not go run")
+	}
+}
+
+// fakeDetectorBuild returns a build function writing an empty binary and counting its calls.
+func fakeDetectorBuild(calls *atomic.Int32) func(context.Context, detectorBuild) error {
+	return func(_ context.Context, b detectorBuild) error {
+		calls.Add(1)
+		return os.WriteFile(b.binary, nil, 0o755)
+	}
+}
+
+func TestDetectorBinaryCache_BuildsOnce(t *testing.T) {
+	var calls atomic.Int32
+	cache := newDetectorBinaryCache(fakeDetectorBuild(&calls))
+	t.Cleanup(cache.cleanup)
+
+	runArgs := []string{"run", "github.com/alexandremahdhaoui/forge/cmd/go-dependency-detector@v0.9.0"}
+
+	const n = 5
+	binaries := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			binary, args, err := cache.command(context.Background(), runArgs)
+			if err != nil {
+				t.Errorf("command returned error: %v", err)
+			}
+			if len(args) != 0 {
+				t.Errorf("expected no program args, got %v", args)
+			}
+			binaries[i] = binary
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected the detector to be built once, got %d builds", got)
+	}
+	for _, binary := range binaries {
+		if binary != binaries[0] {
+			t.Errorf("expected every call to reuse %s, got %s", binaries[0], binary)
+		}
+	}
+	if _, err := os.Stat(binaries[0]); err != nil {
+		t.Errorf("expected the built binary to exist: %v", err)
+	}
+
+	// Another version is a different detector
+	other, _, err := cache.command(context.Background(), []string{"run", "github.com/alexandremahdhaoui/forge/cmd/go-dependency-detector@v0.10.0"})
+	if err != nil {
+		t.Fatalf("command returned error: %v", err)
+	}
+	if calls.Load() != 2 || other == binaries[0] {
+		t.Errorf("expected a separate build for another version, got %d builds and binary %s", calls.Load(), other)
+	}
+}
+
+func TestDetectorBinaryCache_RetriesFailedBuild(t *testing.T) {
+	var calls atomic.Int32
+	build := fakeDetectorBuild(&calls)
+	cache := newDetectorBinaryCache(func(ctx context.Context, b detectorBuild) error {
+		if calls.Load() == 0 {
+			calls.Add(1)
+			return errors.New("network unreachable")
+		}
+		return build(ctx, b)
+	})
+	t.Cleanup(cache.cleanup)
+
+	runArgs := []string{"run", "./cmd/go-dependency-detector"}
+	if _, _, err := cache.command(context.Background(), runArgs); err == nil {
+		t.Fatal("expected the first build to fail")
+	}
+	if _, _, err := cache.command(context.Background(), runArgs); err != nil {
+		t.Fatalf("expected the second call to rebuild, got error: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 builds, got %d", got)
+	}
+}
+
+func TestDetectorBinaryCache_Cleanup(t *testing.T) {
+	var calls atomic.Int32
+	cache := newDetectorBinaryCache(fakeDetectorBuild(&calls))
+
+	binary, _, err := cache.command(context.Background(), []string{"run", "./cmd/go-dependency-detector"})
+	if err != nil {
+		t.Fatalf("command returned error: %v", err)
+	}
+	dir := cache.dir
+
+	cache.cleanup()
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", dir, err)
+	}
+	if _, err := os.Stat(binary); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", binary, err)
+	}
+}
+
+func TestDetectorBinaryCache_HonorsForgeTmpdir(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "forge-tmp")
+	t.Setenv("FORGE_TMPDIR", base)
+
+	var calls atomic.Int32
+	cache := newDetectorBinaryCache(fakeDetectorBuild(&calls))
+	t.Cleanup(cache.cleanup)
+
+	if _, _, err := cache.command(context.Background(), []string{"run", "./cmd/go-dependency-detector"}); err != nil {
+		t.Fatalf("command returned error: %v", err)
+	}
+	if filepath.Dir(cache.dir) != base {
+		t.Errorf("cache dir = %s, want it under FORGE_TMPDIR %s", cache.dir, base)
+	}
+}
@@ -17,12 +17,111 @@
 package engineframework
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// writeDetectorBinary writes a fake detector binary with the given mode at path.
+func writeDetectorBinary(t *testing.T, path string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho test"), mode); err != nil {
+		t.Fatalf("failed to create fake detector: %v", err)
+	}
+}
+
+func TestResolveDetector_GoScheme(t *testing.T) {
+	t.Setenv("FORGE_RUN_LOCAL_ENABLED", "false")
+
+	cmd, args, err := ResolveDetector("go://go-dependency-detector", "v0.9.0")
+	if err != nil {
+		t.Fatalf("ResolveDetector returned error: %v", err)
+	}
+	want := []string{"run", "github.com/alexandremahdhaoui/forge/cmd/go-dependency-detector@v0.9.0"}
+	if cmd != "go" || !slices.Equal(args, want) {
+		t.Errorf("got %s %v, want go %v", cmd, args, want)
+	}
+}
+
+func TestResolveDetector_LocalSchemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	detectorPath := filepath.Join(tmpDir, "build", "bin", "my-detector")
+	writeDetectorBinary(t, detectorPath, 0o755)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name string
+		uri  string
+	}{
+		{name: "file scheme", uri: "file://" + detectorPath},
+		{name: "file scheme relative", uri: "file://./build/bin/my-detector"},
+		{name: "bare absolute path", uri: detectorPath},
+		{name: "bare relative path", uri: "./build/bin/my-detector"},
+		{name: "bin scheme", uri: "bin://my-detector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, args, err := ResolveDetector(tt.uri, "v0.9.0")
+			if err != nil {
+				t.Fatalf("ResolveDetector(%q) returned error: %v", tt.uri, err)
+			}
+			// Compare resolved paths: the temp dir may be behind a symlink
+			wantPath, _ := filepath.EvalSymlinks(detectorPath)
+			gotPath, _ := filepath.EvalSymlinks(cmd)
+			if !filepath.IsAbs(cmd) || gotPath != wantPath {
+				t.Errorf("cmd = %q, want %q", cmd, detectorPath)
+			}
+			if len(args) != 0 {
+				t.Errorf("expected no args for a local detector, got %v", args)
+			}
+		})
+	}
+}
+
+func TestResolveDetector_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	notExecutable := filepath.Join(tmpDir, "not-executable")
+	writeDetectorBinary(t, notExecutable, 0o644)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr string
+	}{
+		{name: "unsupported scheme", uri: "http://example.com/detector", wantErr: "unsupported detector protocol"},
+		{name: "bare name", uri: "my-detector", wantErr: "unsupported detector protocol"},
+		{name: "empty go name", uri: "go://", wantErr: "empty detector name"},
+		{name: "empty file path", uri: "file://", wantErr: "empty detector path"},
+		{name: "empty bin name", uri: "bin://", wantErr: "empty detector name"},
+		{name: "missing file", uri: "file://" + filepath.Join(tmpDir, "missing"), wantErr: "not found"},
+		{name: "missing bin", uri: "bin://missing", wantErr: "not found"},
+		{name: "directory", uri: "file://" + tmpDir, wantErr: "not a regular file"},
+		{name: "not executable", uri: notExecutable, wantErr: "not executable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ResolveDetector(tt.uri, "v0.9.0")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveDetector(%q) error = %v, want error containing %q", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestFindDetector_InPATH(t *testing.T) {
 	// "ls" should be in PATH on all Unix systems
 	path, err := FindDetector("ls")
@@ -158,3 +257,87 @@ func TestCallDetector_Integration(t *testing.T) {
 	// 2. Call it with a test file
 	// 3. Verify dependencies are returned
 }
+
+func TestCallDetectorWithTimeout_Timeout(t *testing.T) {
+	// A detector that never answers the MCP handshake
+	start := time.Now()
+	_, err := CallDetectorWithTimeout(context.Background(), 200*time.Millisecond, "sh", []string{"-c", "exec sleep 30"}, "detectDependencies", nil)
+
+	var detectorErr *DetectorError
+	if !errors.As(err, &detectorErr) {
+		t.Fatalf("expected a *DetectorError, got %v", err)
+	}
+	if detectorErr.Kind != DetectorErrorTimeout {
+		t.Errorf("Kind = %s, want %s (err: %v)", detectorErr.Kind, DetectorErrorTimeout, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected CallDetectorWithTimeout to return after the timeout, took %s", elapsed)
+	}
+}
+
+func TestCallDetector_SpawnError(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing-detector")
+	_, err := CallDetector(context.Background(), missing, nil, "detectDependencies", nil)
+
+	var detectorErr *DetectorError
+	if !errors.As(err, &detectorErr) {
+		t.Fatalf("expected a *DetectorError, got %v", err)
+	}
+	if detectorErr.Kind != DetectorErrorSpawn {
+		t.Errorf("Kind = %s, want %s (err: %v)", detectorErr.Kind, DetectorErrorSpawn, err)
+	}
+	if detectorErr.Detector != missing {
+		t.Errorf("Detector = %q, want %q", detectorErr.Detector, missing)
+	}
+}
+
+func TestParseDetectorResult(t *testing.T) {
+	t.Run("tool error", func(t *testing.T) {
+		result := &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: "Dependency detection failed: no main function"}},
+		}
+		_, err := parseDetectorResult(result)
+		if err == nil || !strings.Contains(err.Error(), "no main function") {
+			t.Errorf("expected the tool error message, got %v", err)
+		}
+	})
+
+	t.Run("missing structured content", func(t *testing.T) {
+		if _, err := parseDetectorResult(&mcp.CallToolResult{}); err == nil {
+			t.Error("expected an error without structured content")
+		}
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		result := &mcp.CallToolResult{
+			StructuredContent: map[string]any{
+				"dependencies": []any{
+					map[string]any{"type": "file", "filePath": "/src/main.go", "timestamp": "2024-01-01T00:00:00Z"},
+				},
+			},
+		}
+		deps, err := parseDetectorResult(result)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(deps) != 1 || deps[0].FilePath != "/src/main.go" || deps[0].Type != "file" {
+			t.Errorf("unexpected dependencies: %+v", deps)
+		}
+	})
+}
+
+func TestDetectorError(t *testing.T) {
+	cause := errors.New("detection failed: boom")
+	err := error(&DetectorError{Kind: DetectorErrorTool, Detector: "go run ./cmd/detector", Err: cause})
+
+	if !errors.Is(err, cause) {
+		t.Error("expected DetectorError to unwrap to its cause")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "tool error") || !strings.Contains(msg, "boom") || !strings.Contains(msg, "./cmd/detector") {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
@@ -213,13 +213,9 @@
 //	}
 //
 //	func deleteResource(ctx context.Context, input engineframework.DeleteInput) error {
-//	    // Best-effort cleanup
+//	    // Idempotent cleanup: an already deleted resource is not an error
 //	    resourceName := input.Metadata["my-testenv.resourceName"]
-//	    if err := cleanupResource(resourceName); err != nil {
-//	        log.Printf("Warning: failed to cleanup: %v", err)
-//	        return nil // Don't fail on cleanup errors
-//	    }
-//	    return nil
+//	    return engineframework.IgnoreNotFound(cleanupResource(resourceName))
 //	}
 //
 // # Utilities
@@ -0,0 +1,31 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+// withInputLabels returns labels with the input labels added. Labels already set by the
+// engine take precedence over input labels with the same key.
+func withInputLabels(labels, inputLabels map[string]string) map[string]string {
+	if len(inputLabels) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(labels)+len(inputLabels))
+	for k, v := range inputLabels {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
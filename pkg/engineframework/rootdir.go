@@ -0,0 +1,51 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/alexandremahdhaoui/forge/pkg/enginecli"
+)
+
+// resolveRootDir returns rootDir, or the directory of the forge.yaml given to the engine
+// with --config when forge did not pass a root directory, or else the working directory
+// of the engine. The returned directory is absolute whenever it can be made so.
+func resolveRootDir(rootDir string) string {
+	if rootDir == "" {
+		rootDir = enginecli.RootDir()
+	}
+	if rootDir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return "."
+		}
+		return dir
+	}
+	if abs, err := filepath.Abs(rootDir); err == nil {
+		return abs
+	}
+	return rootDir
+}
+
+// resolveInputPath returns path joined to rootDir when it is relative.
+// Empty and absolute paths are returned unchanged.
+func resolveInputPath(rootDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(rootDir, path)
+}
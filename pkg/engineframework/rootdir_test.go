@@ -0,0 +1,60 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveRootDir(t *testing.T) {
+	workingDir := t.TempDir()
+	t.Chdir(workingDir)
+
+	tests := []struct {
+		rootDir string
+		want    string
+	}{
+		{rootDir: "/repo", want: "/repo"},
+		{rootDir: "sub", want: filepath.Join(workingDir, "sub")},
+		{rootDir: "", want: workingDir},
+	}
+
+	for _, tt := range tests {
+		if got := resolveRootDir(tt.rootDir); got != tt.want {
+			t.Errorf("resolveRootDir(%q) = %q, want %q", tt.rootDir, got, tt.want)
+		}
+	}
+}
+
+func TestResolveInputPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: ""},
+		{path: "./cmd/app", want: "/repo/cmd/app"},
+		{path: "Containerfile", want: "/repo/Containerfile"},
+		{path: "/abs/path", want: "/abs/path"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveInputPath("/repo", tt.path); got != tt.want {
+			t.Errorf("resolveInputPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
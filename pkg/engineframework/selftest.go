@@ -0,0 +1,200 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
+	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultSelfTestTimeout bounds the execution of a single dependency check.
+const DefaultSelfTestTimeout = 30 * time.Second
+
+// SelfTestStatus is the outcome of a single dependency check.
+type SelfTestStatus string
+
+const (
+	// SelfTestStatusOK means the dependency was found and its probe command succeeded.
+	SelfTestStatusOK SelfTestStatus = "ok"
+	// SelfTestStatusMissing means the dependency command was not found in PATH.
+	SelfTestStatusMissing SelfTestStatus = "missing"
+	// SelfTestStatusFailed means the dependency was found but its probe command failed.
+	SelfTestStatusFailed SelfTestStatus = "failed"
+)
+
+// Dependency describes an external command an engine relies on.
+//
+// The self-test looks up Command in PATH and, when Args is not empty, runs it
+// with Args (e.g. "helm version --short") to check that it is functional.
+type Dependency struct {
+	Name    string   // Human-readable dependency name (e.g., "helm")
+	Command string   // Executable looked up in PATH (e.g., "helm")
+	Args    []string // Probe arguments (e.g., ["version", "--short"]); empty means presence check only
+}
+
+// SelfTestCheck is the result of checking a single dependency.
+type SelfTestCheck struct {
+	Name    string         `json:"name"`
+	Command string         `json:"command"`
+	Status  SelfTestStatus `json:"status"`
+	Path    string         `json:"path,omitempty"`
+	Output  string         `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// SelfTestReport is the structured health report returned by the self-test tool.
+type SelfTestReport struct {
+	Engine  string          `json:"engine"`
+	Version string          `json:"version,omitempty"`
+	Healthy bool            `json:"healthy"`
+	Checks  []SelfTestCheck `json:"checks"`
+}
+
+// SelfTestInput is the input of the self-test tool. It takes no parameters.
+type SelfTestInput struct{}
+
+// SelfTestConfig configures self-test tool registration.
+//
+// Example:
+//
+//	config := SelfTestConfig{
+//	    Name:    "testenv-helm-install",
+//	    Version: "1.0.0",
+//	    Dependencies: []Dependency{
+//	        {Name: "helm", Command: "helm", Args: []string{"version", "--short"}},
+//	    },
+//	}
+type SelfTestConfig struct {
+	Name         string        // Engine name
+	Version      string        // Engine version
+	Dependencies []Dependency  // External dependencies to check
+	Timeout      time.Duration // Per-check timeout (default: DefaultSelfTestTimeout)
+}
+
+// RunSelfTest checks every dependency and returns the resulting health report.
+// The report is healthy only when all checks succeed. Checks never run a real
+// build: they only look up the command and run its probe arguments.
+func RunSelfTest(ctx context.Context, config SelfTestConfig) SelfTestReport {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSelfTestTimeout
+	}
+
+	report := SelfTestReport{
+		Engine:  config.Name,
+		Version: config.Version,
+		Healthy: true,
+		Checks:  make([]SelfTestCheck, 0, len(config.Dependencies)),
+	}
+
+	for _, dep := range config.Dependencies {
+		check := checkDependency(ctx, dep, timeout)
+		if check.Status != SelfTestStatusOK {
+			report.Healthy = false
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	return report
+}
+
+// checkDependency looks up the dependency command and runs its probe arguments.
+func checkDependency(ctx context.Context, dep Dependency, timeout time.Duration) SelfTestCheck {
+	check := SelfTestCheck{
+		Name:    dep.Name,
+		Command: dep.Command,
+	}
+
+	path, err := exec.LookPath(dep.Command)
+	if err != nil {
+		check.Status = SelfTestStatusMissing
+		check.Error = fmt.Sprintf("%s not found in PATH", dep.Command)
+		return check
+	}
+	check.Path = path
+
+	if len(dep.Args) == 0 {
+		check.Status = SelfTestStatusOK
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, dep.Args...).CombinedOutput()
+	check.Output = strings.TrimSpace(string(output))
+	if err != nil {
+		check.Status = SelfTestStatusFailed
+		if ctx.Err() == context.DeadlineExceeded {
+			check.Error = fmt.Sprintf("%s %s timed out after %s", dep.Command, strings.Join(dep.Args, " "), timeout)
+		} else {
+			check.Error = fmt.Sprintf("%s %s failed: %v", dep.Command, strings.Join(dep.Args, " "), err)
+		}
+		return check
+	}
+
+	check.Status = SelfTestStatusOK
+	return check
+}
+
+// RegisterSelfTestTool registers the "self-test" tool with the MCP server.
+//
+// The tool runs the dependency preflights of config and returns a SelfTestReport.
+// An unhealthy report is returned as an error result so that callers can detect it
+// without parsing the report.
+func RegisterSelfTestTool(server *mcpserver.Server, config SelfTestConfig) error {
+	mcpserver.RegisterTool(server, &mcp.Tool{
+		Name:        "self-test",
+		Description: fmt.Sprintf("Check that the external dependencies of %s are available and functional without running a real operation.", config.Name),
+	}, makeSelfTestHandler(config))
+
+	return nil
+}
+
+// makeSelfTestHandler creates the MCP handler of the self-test tool.
+func makeSelfTestHandler(config SelfTestConfig) func(context.Context, *mcp.CallToolRequest, SelfTestInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input SelfTestInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("Running self-test for %s (%d dependencies)", config.Name, len(config.Dependencies))
+
+		report := RunSelfTest(ctx, config)
+		if !report.Healthy {
+			var failed []string
+			for _, check := range report.Checks {
+				if check.Status != SelfTestStatusOK {
+					failed = append(failed, fmt.Sprintf("%s (%s)", check.Name, check.Status))
+				}
+			}
+			result, returnedReport := mcputil.ErrorResultWithArtifact(
+				fmt.Sprintf("Self-test failed: %s", strings.Join(failed, ", ")),
+				report,
+			)
+			return result, returnedReport, nil
+		}
+
+		result, returnedReport := mcputil.SuccessResultWithArtifact(
+			fmt.Sprintf("Self-test passed: %d dependencies checked", len(report.Checks)),
+			report,
+		)
+		return result, returnedReport, nil
+	}
+}
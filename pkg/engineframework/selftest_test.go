@@ -0,0 +1,167 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineframework
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []Dependency
+		wantHealthy  bool
+		wantStatuses []SelfTestStatus
+	}{
+		{
+			name:         "no dependencies",
+			dependencies: nil,
+			wantHealthy:  true,
+			wantStatuses: []SelfTestStatus{},
+		},
+		{
+			name: "presence check only",
+			dependencies: []Dependency{
+				{Name: "shell", Command: "sh"},
+			},
+			wantHealthy:  true,
+			wantStatuses: []SelfTestStatus{SelfTestStatusOK},
+		},
+		{
+			name: "probe succeeds",
+			dependencies: []Dependency{
+				{Name: "shell", Command: "sh", Args: []string{"-c", "echo ok"}},
+			},
+			wantHealthy:  true,
+			wantStatuses: []SelfTestStatus{SelfTestStatusOK},
+		},
+		{
+			name: "missing dependency",
+			dependencies: []Dependency{
+				{Name: "shell", Command: "sh"},
+				{Name: "missing", Command: "forge-self-test-missing-binary", Args: []string{"version"}},
+			},
+			wantHealthy:  false,
+			wantStatuses: []SelfTestStatus{SelfTestStatusOK, SelfTestStatusMissing},
+		},
+		{
+			name: "probe fails",
+			dependencies: []Dependency{
+				{Name: "shell", Command: "sh", Args: []string{"-c", "echo broken; exit 3"}},
+			},
+			wantHealthy:  false,
+			wantStatuses: []SelfTestStatus{SelfTestStatusFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := RunSelfTest(context.Background(), SelfTestConfig{
+				Name:         "test-engine",
+				Dependencies: tt.dependencies,
+			})
+
+			if report.Engine != "test-engine" {
+				t.Errorf("Engine = %q, want %q", report.Engine, "test-engine")
+			}
+			if report.Healthy != tt.wantHealthy {
+				t.Errorf("Healthy = %v, want %v", report.Healthy, tt.wantHealthy)
+			}
+			if len(report.Checks) != len(tt.wantStatuses) {
+				t.Fatalf("got %d checks, want %d", len(report.Checks), len(tt.wantStatuses))
+			}
+			for i, want := range tt.wantStatuses {
+				check := report.Checks[i]
+				if check.Status != want {
+					t.Errorf("Checks[%d].Status = %q, want %q (error: %s)", i, check.Status, want, check.Error)
+				}
+				if want != SelfTestStatusOK && check.Error == "" {
+					t.Errorf("Checks[%d].Error is empty for status %q", i, check.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSelfTest_ProbeOutput(t *testing.T) {
+	report := RunSelfTest(context.Background(), SelfTestConfig{
+		Name: "test-engine",
+		Dependencies: []Dependency{
+			{Name: "shell", Command: "sh", Args: []string{"-c", "echo v1.2.3"}},
+		},
+	})
+
+	if got := report.Checks[0].Output; got != "v1.2.3" {
+		t.Errorf("Output = %q, want %q", got, "v1.2.3")
+	}
+	if report.Checks[0].Path == "" {
+		t.Error("Path is empty, want resolved path of sh")
+	}
+}
+
+func TestMakeSelfTestHandler_MissingDependency(t *testing.T) {
+	handler := makeSelfTestHandler(SelfTestConfig{
+		Name:    "test-engine",
+		Version: "1.0.0",
+		Dependencies: []Dependency{
+			{Name: "helm", Command: "forge-self-test-missing-helm", Args: []string{"version"}},
+		},
+	})
+
+	result, artifact, err := handler(context.Background(), &mcp.CallToolRequest{}, SelfTestInput{})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected IsError=true for a missing dependency")
+	}
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "helm (missing)") {
+		t.Errorf("result text = %q, want it to mention the missing dependency", text)
+	}
+
+	report, ok := artifact.(SelfTestReport)
+	if !ok {
+		t.Fatalf("artifact type = %T, want SelfTestReport", artifact)
+	}
+	if report.Healthy {
+		t.Error("report.Healthy = true, want false")
+	}
+	if report.Version != "1.0.0" {
+		t.Errorf("report.Version = %q, want %q", report.Version, "1.0.0")
+	}
+}
+
+func TestMakeSelfTestHandler_Healthy(t *testing.T) {
+	handler := makeSelfTestHandler(SelfTestConfig{
+		Name:         "test-engine",
+		Dependencies: []Dependency{{Name: "shell", Command: "sh"}},
+	})
+
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, SelfTestInput{})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("expected success result, got error: %v", result.Content)
+	}
+}
@@ -31,7 +31,7 @@ func ExtractString(spec map[string]any, key string) (string, bool) {
 		return "", false
 	}
 
-	value, exists := spec[key]
+	value, exists := lookupSpecValue(spec, key)
 	if !exists {
 		return "", false
 	}
@@ -76,7 +76,7 @@ func ExtractStringSlice(spec map[string]any, key string) ([]string, bool) {
 		return nil, false
 	}
 
-	value, exists := spec[key]
+	value, exists := lookupSpecValue(spec, key)
 	if !exists {
 		return nil, false
 	}
@@ -135,7 +135,7 @@ func ExtractStringMap(spec map[string]any, key string) (map[string]string, bool)
 		return nil, false
 	}
 
-	value, exists := spec[key]
+	value, exists := lookupSpecValue(spec, key)
 	if !exists {
 		return nil, false
 	}
@@ -195,7 +195,7 @@ func ExtractBool(spec map[string]any, key string) (bool, bool) {
 		return false, false
 	}
 
-	value, exists := spec[key]
+	value, exists := lookupSpecValue(spec, key)
 	if !exists {
 		return false, false
 	}
@@ -240,7 +240,7 @@ func ExtractInt(spec map[string]any, key string) (int, bool) {
 		return 0, false
 	}
 
-	value, exists := spec[key]
+	value, exists := lookupSpecValue(spec, key)
 	if !exists {
 		return 0, false
 	}
@@ -297,7 +297,7 @@ func ExtractMap(spec map[string]any, key string) (map[string]any, bool) {
 		return nil, false
 	}
 
-	value, exists := spec[key]
+	value, exists := lookupSpecValue(spec, key)
 	if !exists {
 		return nil, false
 	}
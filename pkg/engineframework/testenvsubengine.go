@@ -16,12 +16,17 @@ package engineframework
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -34,11 +39,16 @@ import (
 //   - TestID: Unique identifier for this test environment instance (required)
 //   - Stage: Test stage name from forge.yaml (required)
 //   - TmpDir: Temporary directory allocated for this test environment (required)
-//   - RootDir: Project root directory for path resolution (optional)
+//   - RootDir: Project root directory for path resolution (optional, defaults to the directory
+//     of the forge.yaml given to the engine with --config)
 //   - Metadata: Metadata from previous subengines in the chain (optional)
 //   - Spec: Optional spec for configuration override from forge.yaml
 //   - Env: Accumulated environment variables from previous sub-engines (optional)
 //   - EnvPropagation: Optional EnvPropagation configuration from spec (optional)
+//   - DryRun: Report what would be created without side effects (optional)
+//   - RunID: Identifier of this create operation, generated by the framework if empty
+//   - Logger: Structured logger tagged with the engine, RunID, TestID and Stage, set by the framework
+//     (nil when CreateFunc is called outside the framework, e.g. in tests)
 //
 // Example:
 //
@@ -60,6 +70,9 @@ type CreateInput struct {
 	Spec           map[string]any        `json:"spec,omitempty" jsonschema:"Engine-specific configuration from forge.yaml testenv[].spec"`
 	Env            map[string]string     `json:"env,omitempty" jsonschema:"Accumulated environment variables from previous subengines in the chain"`
 	EnvPropagation *forge.EnvPropagation `json:"envPropagation,omitempty" jsonschema:"Configuration for filtering environment variable propagation"`
+	DryRun         bool                  `json:"dryRun,omitempty" jsonschema:"Report what would be created in the artifact plan without provisioning any resource"`
+	RunID          string                `json:"runID,omitempty" jsonschema:"Identifier correlating the logs of this operation (generated if empty)"`
+	Logger         *slog.Logger          `json:"-"`
 }
 
 // DeleteInput represents the input for testenv subengine delete operations.
@@ -70,6 +83,10 @@ type CreateInput struct {
 // Fields:
 //   - TestID: Unique identifier for the test environment instance to delete (required)
 //   - Metadata: Metadata from the test environment (optional, useful for cleanup)
+//   - DryRun: Report what would be deleted without side effects (optional)
+//   - RunID: Identifier of this delete operation, generated by the framework if empty
+//   - Logger: Structured logger tagged with the engine, RunID and TestID, set by the framework
+//     (nil when DeleteFunc is called outside the framework, e.g. in tests)
 //
 // Example:
 //
@@ -80,6 +97,9 @@ type CreateInput struct {
 type DeleteInput struct {
 	TestID   string            `json:"testID" jsonschema:"Unique identifier of the test environment instance to delete"`
 	Metadata map[string]string `json:"metadata" jsonschema:"Metadata from the test environment used for resource cleanup"`
+	DryRun   bool              `json:"dryRun,omitempty" jsonschema:"Report what would be deleted without deleting any resource"`
+	RunID    string            `json:"runID,omitempty" jsonschema:"Identifier correlating the logs of this operation (generated if empty)"`
+	Logger   *slog.Logger      `json:"-"`
 }
 
 // TestEnvArtifact represents the artifact returned by testenv subengine create operations.
@@ -93,6 +113,7 @@ type DeleteInput struct {
 //   - Metadata: Key-value metadata for downstream consumers
 //   - ManagedResources: List of resources to clean up (file paths, cluster names, etc.)
 //   - Env: Environment variables exported by this sub-engine (optional)
+//   - Plan: Human-readable actions that would be performed (dry-run only)
 //
 // Example:
 //
@@ -116,6 +137,7 @@ type TestEnvArtifact struct {
 	Metadata         map[string]string `json:"metadata"`         // Metadata for downstream consumers
 	ManagedResources []string          `json:"managedResources"` // Resources to clean up
 	Env              map[string]string `json:"env,omitempty"`    // Environment variables exported by this sub-engine
+	Plan             []string          `json:"plan,omitempty"`   // Actions that would be performed (dry-run only)
 }
 
 // CreateFunc is the signature for testenv subengine create operations.
@@ -125,6 +147,7 @@ type TestEnvArtifact struct {
 //   - Create the test environment resource (cluster, registry, etc.)
 //   - Return TestEnvArtifact on success with files, metadata, and managedResources
 //   - Return error on failure
+//   - When input.DryRun is true, provision nothing and describe the planned actions in TestEnvArtifact.Plan
 //
 // The framework handles:
 //   - MCP tool registration
@@ -161,14 +184,17 @@ type CreateFunc func(ctx context.Context, input CreateInput) (*TestEnvArtifact,
 //   - Validate input fields (testID is required)
 //   - Delete the test environment resource (cluster, registry, etc.)
 //   - Return error on failure (or nil for best-effort cleanup)
+//   - Return nil if the resources are already absent (use IgnoreNotFound), and call
+//     MarkNothingDeleted so that the result reports a no-op
+//   - When input.DryRun is true, delete nothing and only log the planned actions
 //
 // The framework handles:
 //   - MCP tool registration
-//   - Result formatting
+//   - Result formatting (including whether anything was deleted)
 //   - Error conversion to MCP responses
 //
-// IMPORTANT: Delete operations should be best-effort. If resources are already gone,
-// don't return an error. Only return errors for actual failures that need attention.
+// IMPORTANT: Delete operations must be idempotent. Deleting resources that are already gone
+// is not an error. Only return errors for actual failures that need attention.
 //
 // Example:
 //
@@ -179,15 +205,40 @@ type CreateFunc func(ctx context.Context, input CreateInput) (*TestEnvArtifact,
 //	        clusterName = fmt.Sprintf("myapp-%s", input.TestID)
 //	    }
 //
-//	    // Delete cluster (best-effort)
-//	    if err := deleteCluster(clusterName); err != nil {
-//	        log.Printf("Warning: failed to delete cluster: %v", err)
-//	        return nil // Don't fail on cleanup errors
+//	    // Delete cluster, tolerating a cluster that no longer exists
+//	    err := deleteCluster(clusterName)
+//	    if engineframework.IsNotFound(err) {
+//	        engineframework.MarkNothingDeleted(ctx)
+//	        return nil
 //	    }
+//	    return err
+//	}
+type DeleteFunc func(ctx context.Context, input DeleteInput) error
+
+// VerifyDeleteFunc is the signature for optional post-delete verification.
+//
+// It runs after a successful DeleteFunc and confirms that the resources are actually
+// gone (e.g. the cluster no longer exists, the namespace was removed).
+//
+// Implementations must:
+//   - Return nil if no resources linger
+//   - Return an error describing the leftover resources otherwise
+//   - Not attempt cleanup themselves (verification only)
+//
+// Example:
 //
+//	func myVerifyDeleteFunc(ctx context.Context, input DeleteInput) error {
+//	    clusterName := input.Metadata["clusterName"]
+//	    exists, err := clusterExists(clusterName)
+//	    if err != nil {
+//	        return fmt.Errorf("failed to check cluster %s: %w", clusterName, err)
+//	    }
+//	    if exists {
+//	        return fmt.Errorf("cluster %s still exists", clusterName)
+//	    }
 //	    return nil
 //	}
-type DeleteFunc func(ctx context.Context, input DeleteInput) error
+type VerifyDeleteFunc func(ctx context.Context, input DeleteInput) error
 
 // TestEnvSubengineConfig configures testenv subengine tool registration.
 //
@@ -196,6 +247,8 @@ type DeleteFunc func(ctx context.Context, input DeleteInput) error
 //   - Version: Engine version string (e.g., "1.0.0" or git commit hash)
 //   - CreateFunc: The create operation implementation function
 //   - DeleteFunc: The delete operation implementation function
+//   - VerifyDeleteFunc: Optional post-delete verification function
+//   - Capabilities: Optional features supported beyond dry-run (e.g. CapabilityCancellation)
 //
 // Example:
 //
@@ -210,6 +263,12 @@ type TestEnvSubengineConfig struct {
 	Version    string     // Engine version
 	CreateFunc CreateFunc // Create operation implementation
 	DeleteFunc DeleteFunc // Delete operation implementation
+	// VerifyDeleteFunc optionally confirms cleanup succeeded after DeleteFunc returns (nil to skip)
+	VerifyDeleteFunc VerifyDeleteFunc
+	// Capabilities lists optional features the engine supports; dry-run is always advertised
+	Capabilities []Capability
+	// SpecSchema is the JSON schema of the engine spec, returned by the "describe-spec" tool (optional)
+	SpecSchema json.RawMessage
 }
 
 // RegisterTestEnvSubengineTools registers create and delete tools with the MCP server.
@@ -222,6 +281,9 @@ type TestEnvSubengineConfig struct {
 //   - Returns TestEnvArtifact on successful create
 //   - Uses SuccessResultWithArtifact for create operations
 //   - Uses SuccessResult for delete operations
+//   - Forwards the DryRun flag and reports dry-run results with the planned actions
+//   - Registers "capabilities" tool advertising dry-run and config.Capabilities
+//   - Registers "describe-spec" tool returning config.SpecSchema, when set
 //
 // Parameters:
 //   - server: The MCP server instance
@@ -262,14 +324,24 @@ func RegisterTestEnvSubengineTools(server *mcpserver.Server, config TestEnvSuben
 		Description: fmt.Sprintf("Tear down a test environment resource using %s. Performs best-effort cleanup of managed resources.", config.Name),
 	}, makeDeleteHandler(config))
 
+	// Register capabilities tool
+	registerCapabilitiesTool(server, newCapabilities(config.Name, config.Version, EngineKindTestEnvSubengine,
+		[]Capability{CapabilityDryRun}, config.Capabilities))
+
+	// Register describe-spec tool when the engine declares its spec schema
+	if err := registerDescribeSpecTool(server, config.Name, config.SpecSchema); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // makeCreateHandler creates an MCP handler function from a CreateFunc.
 //
 // The returned handler:
+//   - Sets input.RunID (if empty) and input.Logger
 //   - Validates required input fields (TestID, Stage, TmpDir)
-//   - Calls the CreateFunc with the input
+//   - Calls the CreateFunc with the input, in a "create" span (see package tracing)
 //   - Converts CreateFunc errors to MCP error responses
 //   - Returns TestEnvArtifact as artifact on success
 //   - Uses SuccessResultWithArtifact for successful creates
@@ -277,7 +349,12 @@ func RegisterTestEnvSubengineTools(server *mcpserver.Server, config TestEnvSuben
 // This is an internal helper function used by RegisterTestEnvSubengineTools.
 func makeCreateHandler(config TestEnvSubengineConfig) func(context.Context, *mcp.CallToolRequest, CreateInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, input CreateInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("Creating test environment resource: testID=%s, stage=%s using %s", input.TestID, input.Stage, config.Name)
+		if input.RunID == "" {
+			input.RunID = newRunID()
+		}
+		input.Logger = operationLogger(config.Name, "create", input.RunID, input.TestID).With("stage", input.Stage)
+
+		log.Printf("Creating test environment resource: testID=%s, stage=%s, runID=%s, dryRun=%t using %s", input.TestID, input.Stage, input.RunID, input.DryRun, config.Name)
 
 		// Validate required input fields
 		if result := mcputil.ValidateRequiredWithPrefix("Create failed", map[string]string{
@@ -288,8 +365,12 @@ func makeCreateHandler(config TestEnvSubengineConfig) func(context.Context, *mcp
 			return result, nil, nil
 		}
 
-		// Call the CreateFunc
+		input.RootDir = resolveRootDir(input.RootDir)
+
+		// Call the CreateFunc in a span joining the caller's trace
+		ctx, span := tracing.StartOperation(tracing.ContextFromRequest(ctx, req), config.Name, "create")
 		artifact, err := config.CreateFunc(ctx, input)
+		tracing.End(span, err)
 		if err != nil {
 			// Creation error
 			return mcputil.ErrorResult(fmt.Sprintf("Create failed: %v", err)), nil, nil
@@ -309,6 +390,18 @@ func makeCreateHandler(config TestEnvSubengineConfig) func(context.Context, *mcp
 			"env":              artifact.Env,
 		}
 
+		// Dry-run: expose the plan and make it explicit that nothing was created
+		if input.DryRun {
+			artifactMap["dryRun"] = true
+			artifactMap["plan"] = artifact.Plan
+
+			result, returnedArtifact := mcputil.SuccessResultWithArtifact(
+				fmt.Sprintf("Planned test environment resource using %s (dry-run, %d action(s), nothing created)", config.Name, len(artifact.Plan)),
+				artifactMap,
+			)
+			return result, returnedArtifact, nil
+		}
+
 		// Return success with artifact
 		result, returnedArtifact := mcputil.SuccessResultWithArtifact(
 			fmt.Sprintf("Created test environment resource using %s", config.Name),
@@ -321,15 +414,22 @@ func makeCreateHandler(config TestEnvSubengineConfig) func(context.Context, *mcp
 // makeDeleteHandler creates an MCP handler function from a DeleteFunc.
 //
 // The returned handler:
+//   - Sets input.RunID (if empty) and input.Logger
 //   - Validates required input fields (TestID)
-//   - Calls the DeleteFunc with the input
-//   - Converts DeleteFunc errors to MCP error responses
-//   - Uses SuccessResult for successful deletes
+//   - Calls the DeleteFunc with the input, in a "delete" span (see package tracing)
+//   - Calls the VerifyDeleteFunc (if set, skipped in dry-run) to confirm no resources linger
+//   - Converts DeleteFunc and VerifyDeleteFunc errors to MCP error responses
+//   - Returns {"deleted": false} if the DeleteFunc called MarkNothingDeleted, {"deleted": true} otherwise
 //
 // This is an internal helper function used by RegisterTestEnvSubengineTools.
 func makeDeleteHandler(config TestEnvSubengineConfig) func(context.Context, *mcp.CallToolRequest, DeleteInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, input DeleteInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("Deleting test environment resource: testID=%s using %s", input.TestID, config.Name)
+		if input.RunID == "" {
+			input.RunID = newRunID()
+		}
+		input.Logger = operationLogger(config.Name, "delete", input.RunID, input.TestID)
+
+		log.Printf("Deleting test environment resource: testID=%s, runID=%s, dryRun=%t using %s", input.TestID, input.RunID, input.DryRun, config.Name)
 
 		// Validate required input fields
 		if result := mcputil.ValidateRequiredWithPrefix("Delete failed", map[string]string{
@@ -338,14 +438,53 @@ func makeDeleteHandler(config TestEnvSubengineConfig) func(context.Context, *mcp
 			return result, nil, nil
 		}
 
-		// Call the DeleteFunc
+		// Call the DeleteFunc in a span joining the caller's trace
+		ctx, span := tracing.StartOperation(tracing.ContextFromRequest(ctx, req), config.Name, "delete")
+		ctx, outcome := withDeleteOutcome(ctx)
 		err := config.DeleteFunc(ctx, input)
+		tracing.End(span, err)
 		if err != nil {
 			// Deletion error
 			return mcputil.ErrorResult(fmt.Sprintf("Delete failed: %v", err)), nil, nil
 		}
 
-		// Return success
-		return mcputil.SuccessResult(fmt.Sprintf("Deleted test environment resource using %s", config.Name)), nil, nil
+		if input.DryRun {
+			return mcputil.SuccessResult(fmt.Sprintf("Planned deletion of test environment resource using %s (dry-run, nothing deleted)", config.Name)), nil, nil
+		}
+
+		// Verify that cleanup actually succeeded
+		if config.VerifyDeleteFunc != nil {
+			if err := config.VerifyDeleteFunc(ctx, input); err != nil {
+				return mcputil.ErrorResult(fmt.Sprintf("Delete verification failed: %v", err)), nil, nil
+			}
+		}
+
+		// Report whether anything was actually removed
+		if outcome.nothingDeleted.Load() {
+			result, deleteResult := mcputil.SuccessResultWithArtifact(
+				fmt.Sprintf("No test environment resource to delete using %s (already absent)", config.Name),
+				map[string]interface{}{"deleted": false},
+			)
+			return result, deleteResult, nil
+		}
+
+		result, deleteResult := mcputil.SuccessResultWithArtifact(
+			fmt.Sprintf("Deleted test environment resource using %s", config.Name),
+			map[string]interface{}{"deleted": true},
+		)
+		return result, deleteResult, nil
 	}
 }
+
+// newRunID returns a random identifier for a create or delete operation.
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// operationLogger returns the structured logger of a create or delete operation.
+// It derives from slog.Default(), so its records go to the same output as the global log package.
+func operationLogger(engine, operation, runID, testID string) *slog.Logger {
+	return slog.Default().With("engine", engine, "operation", operation, "runID", runID, "testID", testID)
+}
@@ -17,8 +17,11 @@
 package engineframework
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"strings"
 	"testing"
 
@@ -386,9 +389,9 @@ func TestMakeDeleteHandler_Success(t *testing.T) {
 		t.Fatalf("handler returned error result")
 	}
 
-	// Artifact should be nil (delete doesn't return artifacts)
-	if artifact != nil {
-		t.Errorf("handler returned artifact for delete: %v", artifact)
+	// Delete returns no artifact, only whether resources were removed
+	if out, ok := artifact.(map[string]interface{}); !ok || out["deleted"] != true {
+		t.Errorf("handler returned %v for delete, want deleted=true", artifact)
 	}
 }
 
@@ -467,6 +470,286 @@ func TestMakeDeleteHandler_MissingTestID(t *testing.T) {
 	}
 }
 
+func TestMakeCreateHandler_DryRun(t *testing.T) {
+	var receivedDryRun bool
+	var provisioned []string
+
+	config := TestEnvSubengineConfig{
+		Name:    "testenv-test",
+		Version: "1.0.0",
+		CreateFunc: func(ctx context.Context, input CreateInput) (*TestEnvArtifact, error) {
+			receivedDryRun = input.DryRun
+			clusterName := "cluster-" + input.TestID
+			if input.DryRun {
+				return &TestEnvArtifact{
+					TestID: input.TestID,
+					Plan:   []string{"create cluster " + clusterName},
+				}, nil
+			}
+			provisioned = append(provisioned, clusterName)
+			return &TestEnvArtifact{TestID: input.TestID}, nil
+		},
+		DeleteFunc: mockDeleteFunc(false),
+	}
+
+	handler := makeCreateHandler(config)
+
+	result, artifact, err := handler(context.Background(), &mcp.CallToolRequest{}, CreateInput{
+		TestID: "test-123",
+		Stage:  "integration",
+		TmpDir: "/tmp/test-123",
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handler returned error result")
+	}
+
+	if !receivedDryRun {
+		t.Error("CreateFunc did not receive DryRun=true")
+	}
+	if len(provisioned) != 0 {
+		t.Errorf("expected no resources provisioned in dry-run, got %v", provisioned)
+	}
+
+	artifactMap, ok := artifact.(map[string]interface{})
+	if !ok {
+		t.Fatalf("artifact is not map[string]interface{}, got %T", artifact)
+	}
+	if artifactMap["dryRun"] != true {
+		t.Errorf("artifact.dryRun = %v, want true", artifactMap["dryRun"])
+	}
+	plan, ok := artifactMap["plan"].([]string)
+	if !ok || len(plan) != 1 || plan[0] != "create cluster cluster-test-123" {
+		t.Errorf("artifact.plan = %v, want [create cluster cluster-test-123]", artifactMap["plan"])
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || !strings.Contains(textContent.Text, "dry-run") {
+		t.Errorf("expected dry-run result message, got %v", result.Content[0])
+	}
+}
+
+func TestMakeCreateHandler_NoDryRunOmitsPlan(t *testing.T) {
+	config := TestEnvSubengineConfig{
+		Name:       "testenv-test",
+		Version:    "1.0.0",
+		CreateFunc: mockCreateFunc(false),
+		DeleteFunc: mockDeleteFunc(false),
+	}
+
+	_, artifact, err := makeCreateHandler(config)(context.Background(), &mcp.CallToolRequest{}, CreateInput{
+		TestID: "test-123",
+		Stage:  "integration",
+		TmpDir: "/tmp/test-123",
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	artifactMap := artifact.(map[string]interface{})
+	if _, ok := artifactMap["plan"]; ok {
+		t.Error("artifact.plan should not be set outside dry-run")
+	}
+	if _, ok := artifactMap["dryRun"]; ok {
+		t.Error("artifact.dryRun should not be set outside dry-run")
+	}
+}
+
+func TestMakeDeleteHandler_DryRun(t *testing.T) {
+	var receivedDryRun bool
+	deleted := false
+
+	config := TestEnvSubengineConfig{
+		Name:       "testenv-test",
+		Version:    "1.0.0",
+		CreateFunc: mockCreateFunc(false),
+		DeleteFunc: func(ctx context.Context, input DeleteInput) error {
+			receivedDryRun = input.DryRun
+			if !input.DryRun {
+				deleted = true
+			}
+			return nil
+		},
+	}
+
+	result, _, err := makeDeleteHandler(config)(context.Background(), &mcp.CallToolRequest{}, DeleteInput{
+		TestID: "test-123",
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handler returned error result")
+	}
+
+	if !receivedDryRun {
+		t.Error("DeleteFunc did not receive DryRun=true")
+	}
+	if deleted {
+		t.Error("expected no resources deleted in dry-run")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || !strings.Contains(textContent.Text, "nothing deleted") {
+		t.Errorf("expected dry-run result message, got %v", result.Content[0])
+	}
+}
+
+func TestMakeDeleteHandler_VerifyDelete(t *testing.T) {
+	tests := []struct {
+		name        string
+		leftovers   []string
+		dryRun      bool
+		wantError   bool
+		wantVerify  bool
+		wantMessage string
+	}{
+		{
+			name:        "no leftover resources",
+			wantVerify:  true,
+			wantMessage: "Deleted test environment resource",
+		},
+		{
+			name:        "leftover resources",
+			leftovers:   []string{"cluster/cluster-test-123", "namespace/test-ns"},
+			wantError:   true,
+			wantVerify:  true,
+			wantMessage: "Delete verification failed: resources still exist: cluster/cluster-test-123, namespace/test-ns",
+		},
+		{
+			name:        "dry-run skips verification",
+			leftovers:   []string{"cluster/cluster-test-123"},
+			dryRun:      true,
+			wantVerify:  false,
+			wantMessage: "dry-run",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifyCalled := false
+			config := TestEnvSubengineConfig{
+				Name:       "testenv-test",
+				Version:    "1.0.0",
+				CreateFunc: mockCreateFunc(false),
+				DeleteFunc: mockDeleteFunc(false),
+				VerifyDeleteFunc: func(ctx context.Context, input DeleteInput) error {
+					verifyCalled = true
+					if len(tt.leftovers) > 0 {
+						return errors.New("resources still exist: " + strings.Join(tt.leftovers, ", "))
+					}
+					return nil
+				},
+			}
+
+			result, _, err := makeDeleteHandler(config)(context.Background(), &mcp.CallToolRequest{}, DeleteInput{
+				TestID: "test-123",
+				DryRun: tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			if verifyCalled != tt.wantVerify {
+				t.Errorf("VerifyDeleteFunc called = %v, want %v", verifyCalled, tt.wantVerify)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("result.IsError = %v, want %v", result.IsError, tt.wantError)
+			}
+
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			if !ok || !strings.Contains(textContent.Text, tt.wantMessage) {
+				t.Errorf("expected result message to contain %q, got %v", tt.wantMessage, result.Content[0])
+			}
+		})
+	}
+}
+
+func TestMakeDeleteHandler_ReportsDeleted(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteFunc  DeleteFunc
+		wantDeleted bool
+		wantMessage string
+	}{
+		{
+			name:        "resources removed",
+			deleteFunc:  mockDeleteFunc(false),
+			wantDeleted: true,
+			wantMessage: "Deleted test environment resource",
+		},
+		{
+			name: "resources already absent",
+			deleteFunc: func(ctx context.Context, input DeleteInput) error {
+				MarkNothingDeleted(ctx)
+				return IgnoreNotFound(errors.New(`Error from server (NotFound): namespaces "test-ns" not found`))
+			},
+			wantDeleted: false,
+			wantMessage: "No test environment resource to delete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := TestEnvSubengineConfig{
+				Name:       "testenv-test",
+				Version:    "1.0.0",
+				CreateFunc: mockCreateFunc(false),
+				DeleteFunc: tt.deleteFunc,
+			}
+
+			result, out, err := makeDeleteHandler(config)(context.Background(), &mcp.CallToolRequest{}, DeleteInput{
+				TestID: "test-123",
+			})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("handler returned error result: %v", result.Content[0])
+			}
+
+			deleteResult, ok := out.(map[string]interface{})
+			if !ok || deleteResult["deleted"] != tt.wantDeleted {
+				t.Errorf("delete result = %v, want deleted=%v", out, tt.wantDeleted)
+			}
+
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			if !ok || !strings.Contains(textContent.Text, tt.wantMessage) {
+				t.Errorf("expected result message to contain %q, got %v", tt.wantMessage, result.Content[0])
+			}
+		})
+	}
+}
+
+func TestMakeDeleteHandler_VerifyDeleteNotCalledOnDeleteError(t *testing.T) {
+	verifyCalled := false
+	config := TestEnvSubengineConfig{
+		Name:       "testenv-test",
+		Version:    "1.0.0",
+		CreateFunc: mockCreateFunc(false),
+		DeleteFunc: mockDeleteFunc(true),
+		VerifyDeleteFunc: func(ctx context.Context, input DeleteInput) error {
+			verifyCalled = true
+			return nil
+		},
+	}
+
+	result, _, err := makeDeleteHandler(config)(context.Background(), &mcp.CallToolRequest{}, DeleteInput{TestID: "test-123"})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected error result when DeleteFunc fails")
+	}
+	if verifyCalled {
+		t.Error("VerifyDeleteFunc should not be called when DeleteFunc fails")
+	}
+}
+
 func TestRegisterTestEnvSubengineTools(t *testing.T) {
 	config := TestEnvSubengineConfig{
 		Name:       "testenv-test",
@@ -540,8 +823,8 @@ func TestRegisterTestEnvSubengineTools_IntegrationTest(t *testing.T) {
 	if deleteResult.IsError {
 		t.Error("delete handler returned error result")
 	}
-	if deleteArtifact != nil {
-		t.Error("delete handler returned artifact (should be nil)")
+	if out, ok := deleteArtifact.(map[string]interface{}); !ok || out["deleted"] != true {
+		t.Errorf("delete handler returned %v, want deleted=true", deleteArtifact)
 	}
 }
 
@@ -692,3 +975,91 @@ func TestArtifactFields(t *testing.T) {
 		t.Errorf("artifact.managedResources is not []string, got %T", artifactMap["managedResources"])
 	}
 }
+
+func TestMakeCreateHandler_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	var received CreateInput
+	config := TestEnvSubengineConfig{
+		Name:    "testenv-test",
+		Version: "1.0.0",
+		CreateFunc: func(ctx context.Context, input CreateInput) (*TestEnvArtifact, error) {
+			received = input
+			input.Logger.Info("creating cluster")
+			return mockCreateFunc(false)(ctx, input)
+		},
+		DeleteFunc: mockDeleteFunc(false),
+	}
+
+	_, _, err := makeCreateHandler(config)(context.Background(), &mcp.CallToolRequest{}, CreateInput{
+		TestID: "test-123",
+		Stage:  "integration",
+		TmpDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if received.Logger == nil {
+		t.Fatal("CreateFunc received a nil Logger")
+	}
+	if received.RunID == "" {
+		t.Error("CreateFunc received an empty RunID")
+	}
+
+	// slog.SetDefault also routes the global log package through the handler: the record of
+	// input.Logger is the last one
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatalf("failed to parse log record %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":       "creating cluster",
+		"engine":    "testenv-test",
+		"operation": "create",
+		"testID":    "test-123",
+		"stage":     "integration",
+		"runID":     received.RunID,
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("log record %s = %v, want %v", key, record[key], value)
+		}
+	}
+}
+
+func TestMakeDeleteHandler_RunID(t *testing.T) {
+	var received []DeleteInput
+	config := TestEnvSubengineConfig{
+		Name:       "testenv-test",
+		Version:    "1.0.0",
+		CreateFunc: mockCreateFunc(false),
+		DeleteFunc: func(ctx context.Context, input DeleteInput) error {
+			received = append(received, input)
+			return nil
+		},
+	}
+
+	handler := makeDeleteHandler(config)
+	for _, runID := range []string{"", "", "caller-run"} {
+		if _, _, err := handler(context.Background(), &mcp.CallToolRequest{}, DeleteInput{TestID: "test-123", RunID: runID}); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+	}
+
+	if received[0].RunID == "" || received[0].RunID == received[1].RunID {
+		t.Errorf("generated RunIDs = %q, %q, want distinct non-empty IDs", received[0].RunID, received[1].RunID)
+	}
+	if received[2].RunID != "caller-run" {
+		t.Errorf("RunID = %q, want the caller's %q", received[2].RunID, "caller-run")
+	}
+	for i, input := range received {
+		if input.Logger == nil {
+			t.Errorf("delete %d received a nil Logger", i)
+		}
+	}
+}
@@ -16,13 +16,17 @@ package engineframework
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/alexandremahdhaoui/forge/pkg/forge"
 	"github.com/alexandremahdhaoui/forge/pkg/mcpserver"
 	"github.com/alexandremahdhaoui/forge/pkg/mcptypes"
 	"github.com/alexandremahdhaoui/forge/pkg/mcputil"
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -69,6 +73,7 @@ type TestRunnerFunc func(ctx context.Context, input mcptypes.RunInput) (*forge.T
 //   - Name: Engine name (e.g., "go-test", "generic-test-runner")
 //   - Version: Engine version string (e.g., "1.0.0" or git commit hash)
 //   - RunTestFunc: The test execution implementation function
+//   - Capabilities: Optional features supported by RunTestFunc (e.g. CapabilityStreaming)
 //
 // Example:
 //
@@ -81,13 +86,20 @@ type TestRunnerConfig struct {
 	Name        string         // Engine name (e.g., "go-test")
 	Version     string         // Engine version
 	RunTestFunc TestRunnerFunc // Test execution implementation
+	// Capabilities lists optional features RunTestFunc supports
+	Capabilities []Capability
+	// SpecSchema is the JSON schema of the engine spec, returned by the "describe-spec" tool (optional)
+	SpecSchema json.RawMessage
 }
 
 // RegisterTestRunnerTools registers the run tool with the MCP server.
 //
 // This function automatically:
 //   - Registers "run" tool that calls the RunTestFunc
+//   - Registers "capabilities" tool advertising config.Capabilities
+//   - Registers "describe-spec" tool returning config.SpecSchema, when set
 //   - Validates required input fields (Stage, Runner)
+//   - Resolves the artifact refs of the input against RootDir and checks that their paths exist
 //   - Converts TestRunnerFunc errors to MCP error responses
 //   - Returns TestReport as artifact even when tests fail
 //   - Uses ErrorResultWithArtifact for failed tests (report still returned)
@@ -125,6 +137,14 @@ func RegisterTestRunnerTools(server *mcpserver.Server, config TestRunnerConfig)
 		Description: fmt.Sprintf("Execute tests using %s and return a TestReport with pass/fail status, stats, and coverage. Called by forge based on forge.yaml test[] configuration.", config.Name),
 	}, makeRunHandler(config))
 
+	// Register capabilities tool
+	registerCapabilitiesTool(server, newCapabilities(config.Name, config.Version, EngineKindTestRunner, nil, config.Capabilities))
+
+	// Register describe-spec tool when the engine declares its spec schema
+	if err := registerDescribeSpecTool(server, config.Name, config.SpecSchema); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -132,7 +152,7 @@ func RegisterTestRunnerTools(server *mcpserver.Server, config TestRunnerConfig)
 //
 // The returned handler:
 //   - Validates required input fields (Stage, Runner)
-//   - Calls the TestRunnerFunc with the input
+//   - Calls the TestRunnerFunc with the input, in a "test" span (see package tracing)
 //   - Converts TestRunnerFunc errors to MCP error responses
 //   - Returns TestReport as artifact even when tests fail
 //   - Uses ErrorResultWithArtifact for failed tests (Status="failed")
@@ -151,8 +171,18 @@ func makeRunHandler(config TestRunnerConfig) func(context.Context, *mcp.CallTool
 			return result, nil, nil
 		}
 
-		// Call the TestRunnerFunc
+		input.RootDir = resolveRootDir(input.RootDir)
+
+		artifacts, err := resolveArtifactRefs(input.Artifacts, input.RootDir)
+		if err != nil {
+			return mcputil.ErrorResult(fmt.Sprintf("Test run failed: %v", err)), nil, nil
+		}
+		input.Artifacts = artifacts
+
+		// Call the TestRunnerFunc in a span joining the caller's trace
+		ctx, span := tracing.StartOperation(tracing.ContextFromRequest(ctx, req), config.Name, "test")
 		report, err := config.RunTestFunc(ctx, input)
+		tracing.End(span, err)
 		if err != nil {
 			// Execution error (couldn't run tests)
 			return mcputil.ErrorResult(fmt.Sprintf("Test execution failed: %v", err)), nil, nil
@@ -162,6 +192,7 @@ func makeRunHandler(config TestRunnerConfig) func(context.Context, *mcp.CallTool
 		if report == nil {
 			return mcputil.ErrorResult("Test runner returned nil report"), nil, nil
 		}
+		report.Labels = withInputLabels(report.Labels, input.Labels)
 
 		// Return result based on test status
 		// IMPORTANT: Even if tests failed, we return the report as an artifact
@@ -189,3 +220,25 @@ func makeRunHandler(config TestRunnerConfig) func(context.Context, *mcp.CallTool
 		return result, returnedReport, nil
 	}
 }
+
+// resolveArtifactRefs returns a copy of refs with relative paths joined to rootDir, or an error
+// naming the first artifact whose path does not exist.
+func resolveArtifactRefs(refs []forge.ArtifactRef, rootDir string) ([]forge.ArtifactRef, error) {
+	if len(refs) == 0 {
+		return refs, nil
+	}
+	resolved := make([]forge.ArtifactRef, len(refs))
+	for i, ref := range refs {
+		if ref.Path == "" {
+			return nil, fmt.Errorf("artifact %q has no path", ref.Name)
+		}
+		if !filepath.IsAbs(ref.Path) && rootDir != "" {
+			ref.Path = filepath.Join(rootDir, ref.Path)
+		}
+		if _, err := os.Stat(ref.Path); err != nil {
+			return nil, fmt.Errorf("artifact %q: %w", ref.Name, err)
+		}
+		resolved[i] = ref
+	}
+	return resolved, nil
+}
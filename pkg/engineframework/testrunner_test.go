@@ -19,6 +19,9 @@ package engineframework
 import (
 	"context"
 	"errors"
+	"maps"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -540,3 +543,105 @@ func TestMakeRunHandler_FailedReportFields(t *testing.T) {
 		t.Error("report.TestStats.Failed is 0 for failed tests")
 	}
 }
+
+func TestMakeRunHandler_PropagatesLabels(t *testing.T) {
+	for _, stage := range []string{"unit", "unit-fail"} {
+		t.Run(stage, func(t *testing.T) {
+			config := TestRunnerConfig{
+				Name:    "test-runner",
+				Version: "1.0.0",
+				RunTestFunc: func(ctx context.Context, input mcptypes.RunInput) (*forge.TestReport, error) {
+					report, err := mockTestRunnerFunc(false)(ctx, input)
+					if err != nil {
+						return nil, err
+					}
+					report.Labels = map[string]string{"suite": "engine"}
+					return report, nil
+				},
+			}
+
+			_, report, err := makeRunHandler(config)(context.Background(), &mcp.CallToolRequest{}, mcptypes.RunInput{
+				Stage:  stage,
+				Name:   "test-runner",
+				Labels: map[string]string{"team": "platform", "suite": "input"},
+			})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			reportObj, ok := report.(*forge.TestReport)
+			if !ok {
+				t.Fatalf("report is not *forge.TestReport, got %T", report)
+			}
+			want := map[string]string{"team": "platform", "suite": "engine"}
+			if !maps.Equal(reportObj.Labels, want) {
+				t.Errorf("Labels = %v, want %v", reportObj.Labels, want)
+			}
+		})
+	}
+}
+
+func TestMakeRunHandler_ArtifactRefs(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rootDir, "app"), []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var called bool
+	var content string
+	config := TestRunnerConfig{
+		Name:    "test-runner",
+		Version: "1.0.0",
+		RunTestFunc: func(ctx context.Context, input mcptypes.RunInput) (*forge.TestReport, error) {
+			called = true
+			b, err := os.ReadFile(input.Artifacts[0].Path)
+			if err != nil {
+				return nil, err
+			}
+			content = string(b)
+			return &forge.TestReport{Stage: input.Stage, Status: "passed"}, nil
+		},
+	}
+	handler := makeRunHandler(config)
+
+	t.Run("runner reads the artifact", func(t *testing.T) {
+		result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, mcptypes.RunInput{
+			Stage:           "unit",
+			Name:            "test-runner",
+			DirectoryParams: mcptypes.DirectoryParams{RootDir: rootDir},
+			Artifacts:       []forge.ArtifactRef{{Name: "app", Path: "app"}},
+		})
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handler returned error result: %v", result.Content)
+		}
+		if content != "binary" {
+			t.Errorf("runner read %q, want %q", content, "binary")
+		}
+	})
+
+	t.Run("missing artifact", func(t *testing.T) {
+		called = false
+		result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, mcptypes.RunInput{
+			Stage:           "unit",
+			Name:            "test-runner",
+			DirectoryParams: mcptypes.DirectoryParams{RootDir: rootDir},
+			Artifacts:       []forge.ArtifactRef{{Name: "missing", Path: "missing"}},
+		})
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		if !result.IsError {
+			t.Fatal("handler should return error result for a missing artifact")
+		}
+		if called {
+			t.Error("RunTestFunc called despite a missing artifact")
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, `artifact "missing"`) {
+			t.Errorf("error message should name the artifact: %s", text)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumPrefixSHA256 identifies SHA-256 artifact checksums.
+const ChecksumPrefixSHA256 = "sha256:"
+
+// ComputeChecksum computes a SHA-256 checksum over a file or directory tree.
+// Directories are walked in lexical order and each entry contributes its relative path,
+// its kind and its content (file bytes or symlink target), so the checksum is stable
+// across runs and changes when files are added, removed, renamed or modified.
+func ComputeChecksum(path string) (string, error) {
+	h := sha256.New()
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch {
+		case d.IsDir():
+			fmt.Fprintf(h, "dir %s\x00", rel)
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "symlink %s\x00%s\x00", rel, target)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "file %s\x00%d\x00", rel, info.Size())
+			if err := hashFile(h, p); err != nil {
+				return err
+			}
+		}
+		// Other file types (sockets, devices, ...) have no stable content and are ignored
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute checksum of %s: %w", path, err)
+	}
+
+	return ChecksumPrefixSHA256 + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyArtifact re-hashes the file or directory at the artifact's location and compares
+// the result with its recorded Checksum.
+// It returns an error if the artifact has no checksum, uses an unsupported algorithm,
+// is not stored on the local filesystem or no longer matches its checksum.
+func VerifyArtifact(a *Artifact) error {
+	if a == nil {
+		return fmt.Errorf("artifact is nil")
+	}
+	if a.Checksum == "" {
+		return fmt.Errorf("artifact %s has no checksum", a.Name)
+	}
+	if !strings.HasPrefix(a.Checksum, ChecksumPrefixSHA256) {
+		return fmt.Errorf("artifact %s has unsupported checksum %q: expected %s<hex>", a.Name, a.Checksum, ChecksumPrefixSHA256)
+	}
+
+	path, err := artifactLocalPath(a.Location)
+	if err != nil {
+		return fmt.Errorf("cannot verify artifact %s: %w", a.Name, err)
+	}
+
+	actual, err := ComputeChecksum(path)
+	if err != nil {
+		return fmt.Errorf("cannot verify artifact %s: %w", a.Name, err)
+	}
+	if actual != a.Checksum {
+		return fmt.Errorf("artifact %s checksum mismatch: expected %s, got %s", a.Name, a.Checksum, actual)
+	}
+
+	return nil
+}
+
+// artifactLocalPath returns the filesystem path of an artifact location,
+// accepting both plain paths and file:// URLs.
+func artifactLocalPath(location string) (string, error) {
+	if location == "" {
+		return "", fmt.Errorf("location is empty")
+	}
+	if path, ok := strings.CutPrefix(location, "file://"); ok {
+		return path, nil
+	}
+	if strings.Contains(location, "://") {
+		return "", fmt.Errorf("location %s is not a local path", location)
+	}
+	return location, nil
+}
+
+// hashFile writes the content of the file at path to w.
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = io.Copy(w, f)
+	return err
+}
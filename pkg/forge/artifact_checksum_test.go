@@ -0,0 +1,138 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComputeChecksum(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := ComputeChecksum(dir)
+	if err != nil {
+		t.Fatalf("ComputeChecksum() error = %v", err)
+	}
+	if !strings.HasPrefix(checksum, ChecksumPrefixSHA256) || len(checksum) != len(ChecksumPrefixSHA256)+64 {
+		t.Fatalf("ComputeChecksum() = %q, want sha256:<64 hex chars>", checksum)
+	}
+
+	again, err := ComputeChecksum(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != checksum {
+		t.Errorf("ComputeChecksum() is not deterministic: %q != %q", again, checksum)
+	}
+
+	if _, err := ComputeChecksum(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ComputeChecksum() expected an error for a missing path")
+	}
+}
+
+func TestVerifyArtifact_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bin")
+	if err := os.WriteFile(path, []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := ComputeChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, location := range []string{path, "file://" + path} {
+		artifact := &Artifact{Name: "bin", Location: location, Checksum: checksum}
+		if err := VerifyArtifact(artifact); err != nil {
+			t.Errorf("VerifyArtifact(%s) error = %v", location, err)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("tampered"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyArtifact(&Artifact{Name: "bin", Location: path, Checksum: checksum})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("VerifyArtifact() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestVerifyArtifact_Directory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := ComputeChecksum(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := &Artifact{Name: "site", Location: dir, Checksum: checksum}
+	if err := VerifyArtifact(artifact); err != nil {
+		t.Fatalf("VerifyArtifact() error = %v", err)
+	}
+
+	// Adding a file changes the tree hash
+	if err := os.WriteFile(filepath.Join(dir, "extra.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyArtifact(artifact)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("VerifyArtifact() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestVerifyArtifact_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bin")
+	if err := os.WriteFile(path, []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		artifact *Artifact
+		wantErr  string
+	}{
+		{name: "nil artifact", artifact: nil, wantErr: "artifact is nil"},
+		{name: "no checksum", artifact: &Artifact{Name: "bin", Location: path}, wantErr: "has no checksum"},
+		{name: "unsupported algorithm", artifact: &Artifact{Name: "bin", Location: path, Checksum: "md5:abc"}, wantErr: "unsupported checksum"},
+		{name: "remote location", artifact: &Artifact{Name: "img", Location: "docker://img:v1", Checksum: "sha256:abc"}, wantErr: "not a local path"},
+		{name: "missing path", artifact: &Artifact{Name: "bin", Location: path + ".missing", Checksum: "sha256:abc"}, wantErr: "failed to compute checksum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyArtifact(tt.artifact)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyArtifact() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
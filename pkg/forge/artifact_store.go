@@ -16,9 +16,16 @@
 //
 // The artifact store automatically prunes old build artifacts to prevent unbounded growth:
 //   - Only the 3 most recent artifacts are kept for each unique type:name combination
-//   - Pruning occurs automatically on every WriteArtifactStore() call
+//   - Pruning occurs automatically on every WriteArtifactStore() and UpdateArtifactStore() call
+//
+// Writes are serialized across processes with an advisory lock on "<path>.lock" and replace
+// the file atomically. UpdateArtifactStore performs a whole read-modify-write under the lock.
 //   - Test environments are NOT pruned - all test history is retained
 //
+// The YAML file is the default backend. FORGE_STORE_BACKEND=sqlite stores the same entries in a
+// SQLite database instead; the functions taking a path dispatch to the selected backend, and
+// OpenStore returns it as a Store for entry-level access.
+//
 // Example usage:
 //
 //	store, _ := forge.ReadOrCreateArtifactStore(".forge/artifacts.yaml")
@@ -34,8 +41,10 @@ package forge
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"syscall"
 	"time"
@@ -75,12 +84,17 @@ type Artifact struct {
 	Timestamp string `json:"timestamp" yaml:"timestamp"`
 	// Version is the hash/commit
 	Version string `json:"version" yaml:"version"`
+	// Checksum is the algorithm-prefixed content hash of the artifact (e.g. "sha256:<hex>", optional).
+	// Use VerifyArtifact to check that the artifact at Location still matches it.
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
 	// Dependencies is the list of dependencies tracked for this artifact
 	Dependencies []ArtifactDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 	// DependencyDetectorEngine is the URI of the dependency detector used (optional)
 	DependencyDetectorEngine string `json:"dependencyDetectorEngine,omitempty" yaml:"dependencyDetectorEngine,omitempty"`
 	// DependencyDetectorSpec contains configuration for the dependency detector (optional)
 	DependencyDetectorSpec map[string]interface{} `json:"dependencyDetectorSpec,omitempty" yaml:"dependencyDetectorSpec,omitempty"`
+	// Labels are free-form key-value pairs describing the artifact (optional)
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 }
 
 // ArtifactSummary is a lightweight view of an Artifact without dependencies or version details.
@@ -91,6 +105,29 @@ type ArtifactSummary struct {
 	Timestamp string `json:"timestamp" yaml:"timestamp"`
 }
 
+// ArtifactRef points a consumer, such as a test runner, at the local file of a built artifact.
+type ArtifactRef struct {
+	// Name is the artifact name as defined in forge.yaml build[].name
+	Name string `json:"name" yaml:"name"`
+	// Path is the filesystem path of the artifact (relative paths are relative to the root directory)
+	Path string `json:"path" yaml:"path"`
+	// Checksum is the algorithm-prefixed content hash of the artifact (optional)
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+}
+
+// Ref returns a reference to the local file of this Artifact.
+// It returns false for container images and artifacts whose location is not a local path.
+func (a Artifact) Ref() (ArtifactRef, bool) {
+	if a.Type == "container" {
+		return ArtifactRef{}, false
+	}
+	path, err := artifactLocalPath(a.Location)
+	if err != nil {
+		return ArtifactRef{}, false
+	}
+	return ArtifactRef{Name: a.Name, Path: path, Checksum: a.Checksum}, true
+}
+
 // Summary returns a lightweight summary of this Artifact.
 func (a Artifact) Summary() ArtifactSummary {
 	return ArtifactSummary{
@@ -124,6 +161,10 @@ type TestReport struct {
 	// Coverage contains code coverage information
 	Coverage Coverage `json:"coverage"`
 
+	// Cases lists the individual test cases of this run (optional).
+	// Reports that only carry aggregate TestStats leave it nil.
+	Cases []TestCase `json:"cases,omitempty"`
+
 	// ArtifactFiles lists all artifact files created by this test run (e.g., XML reports, coverage files)
 	ArtifactFiles []string `json:"artifactFiles,omitempty"`
 
@@ -133,6 +174,9 @@ type TestReport struct {
 	// ErrorMessage contains error details if the test run failed
 	ErrorMessage string `json:"errorMessage,omitempty"`
 
+	// Labels are free-form key-value pairs describing the test run (optional)
+	Labels map[string]string `json:"labels,omitempty"`
+
 	// CreatedAt is when this report was stored
 	CreatedAt time.Time `json:"createdAt"`
 
@@ -173,6 +217,21 @@ type TestStats struct {
 	Skipped int `json:"skipped"`
 }
 
+// TestCase contains the result of a single test case.
+type TestCase struct {
+	// Name is the test case name (e.g., "TestFoo/bar")
+	Name string `json:"name"`
+
+	// Status is the test case result ("passed", "failed" or "skipped")
+	Status string `json:"status"`
+
+	// Duration is the test case duration in seconds
+	Duration float64 `json:"duration"`
+
+	// Message contains failure or skip details (optional)
+	Message string `json:"message,omitempty"`
+}
+
 // Coverage contains code coverage information.
 type Coverage struct {
 	// Enabled indicates whether coverage was actually calculated.
@@ -294,21 +353,38 @@ var (
 	errTestEnvironmentNotFound = errors.New("test environment not found")
 	errTestReportNotFound      = errors.New("test report not found")
 	errInvalidArtifactStore    = errors.New("invalid artifact store")
+
+	errArtifactStoreLockTimeout = errors.New("timed out waiting for the artifact store lock")
 )
 
+// artifactStoreVersion is the current artifact store schema version.
+// Stores written with an older version are migrated on read (see artifact_store_migration.go).
 const artifactStoreVersion = "1.0"
 
 // ReadArtifactStore reads the artifact store from the specified path.
-// Returns an error if the file doesn't exist.
+// Stores written with an older schema version are migrated to the current one.
+// Returns an error if the file doesn't exist or if its version is unsupported.
+//
+// With FORGE_STORE_BACKEND=sqlite, the store is read from the SQLite database next to path
+// (see OpenStore).
 func ReadArtifactStore(path string) (ArtifactStore, error) {
+	if backend, err := storeBackend(); err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	} else if backend == StoreBackendSQLite {
+		return readSQLiteArtifactStore(path)
+	}
+	return readArtifactStoreFile(path)
+}
+
+// readArtifactStoreFile reads the YAML artifact store at path.
+func readArtifactStoreFile(path string) (ArtifactStore, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
 	}
 
-	out := ArtifactStore{} //nolint:exhaustruct // unmarshal
-
-	if err := yaml.Unmarshal(b, &out); err != nil {
+	out, err := decodeArtifactStore(b)
+	if err != nil {
 		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
 	}
 
@@ -322,10 +398,6 @@ func ReadArtifactStore(path string) (ArtifactStore, error) {
 	if out.TestReports == nil {
 		out.TestReports = make(map[string]*TestReport)
 	}
-	if out.Version == "" {
-		out.Version = artifactStoreVersion
-	}
-
 	// Validate the artifact store
 	if err := out.Validate(); err != nil {
 		return ArtifactStore{}, flaterrors.Join(err, errInvalidArtifactStore, errReadingArtifactStore)
@@ -341,20 +413,35 @@ func ReadOrCreateArtifactStore(path string) (ArtifactStore, error) {
 	if err != nil {
 		// If file doesn't exist, return empty initialized store
 		if errors.Is(err, os.ErrNotExist) {
-			return ArtifactStore{
-				Version:          artifactStoreVersion,
-				LastUpdated:      time.Now().UTC(),
-				Artifacts:        []Artifact{},
-				TestEnvironments: make(map[string]*TestEnvironment),
-				TestReports:      make(map[string]*TestReport),
-			}, nil
+			return newArtifactStore(), nil
 		}
 		return ArtifactStore{}, err
 	}
 	return store, nil
 }
 
+// readOrCreateArtifactStoreFile is ReadOrCreateArtifactStore for the YAML artifact store at path.
+func readOrCreateArtifactStoreFile(path string) (ArtifactStore, error) {
+	store, err := readArtifactStoreFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return newArtifactStore(), nil
+	}
+	return store, err
+}
+
+// newArtifactStore returns an initialized empty store.
+func newArtifactStore() ArtifactStore {
+	return ArtifactStore{
+		Version:          artifactStoreVersion,
+		LastUpdated:      time.Now().UTC(),
+		Artifacts:        []Artifact{},
+		TestEnvironments: make(map[string]*TestEnvironment),
+		TestReports:      make(map[string]*TestReport),
+	}
+}
+
 // PruneBuildArtifacts keeps only the N most recent artifacts for each type+name combination.
+// Kept artifacts stay in their original order.
 // Test environments are NOT pruned - only build artifacts are affected.
 func PruneBuildArtifacts(store *ArtifactStore, keepCount int) {
 	if store == nil || len(store.Artifacts) == 0 {
@@ -368,35 +455,53 @@ func PruneBuildArtifacts(store *ArtifactStore, keepCount int) {
 		groups[key] = append(groups[key], artifact)
 	}
 
-	// For each group, keep only the N most recent
-	var prunedArtifacts []Artifact
+	// For each group, drop all but the N most recent
+	stale := make(map[string]bool)
 	for _, artifacts := range groups {
-		// Sort by timestamp (newest first)
-		sort.Slice(artifacts, func(i, j int) bool {
-			ti, errI := time.Parse(time.RFC3339, artifacts[i].Timestamp)
-			tj, errJ := time.Parse(time.RFC3339, artifacts[j].Timestamp)
-			// If parsing fails, keep the artifact at the end
-			if errI != nil {
-				return false
-			}
-			if errJ != nil {
-				return true
-			}
-			return ti.After(tj)
-		})
-
-		// Keep only N most recent
-		if len(artifacts) > keepCount {
-			artifacts = artifacts[:keepCount]
+		if len(artifacts) <= keepCount {
+			continue
+		}
+		sortArtifactsNewestFirst(artifacts)
+		for _, artifact := range artifacts[keepCount:] {
+			stale[artifactID(artifact)] = true
 		}
-		prunedArtifacts = append(prunedArtifacts, artifacts...)
 	}
 
-	store.Artifacts = prunedArtifacts
+	store.Artifacts = slices.DeleteFunc(store.Artifacts, func(a Artifact) bool {
+		return stale[artifactID(a)]
+	})
+}
+
+// sortArtifactsNewestFirst sorts artifacts by timestamp, newest first.
+// Artifacts whose timestamp cannot be parsed are sorted last.
+func sortArtifactsNewestFirst(artifacts []Artifact) {
+	sort.Slice(artifacts, func(i, j int) bool {
+		ti, errI := time.Parse(time.RFC3339, artifacts[i].Timestamp)
+		tj, errJ := time.Parse(time.RFC3339, artifacts[j].Timestamp)
+		// If parsing fails, keep the artifact at the end
+		if errI != nil {
+			return false
+		}
+		if errJ != nil {
+			return true
+		}
+		return ti.After(tj)
+	})
 }
 
+// artifactStoreKeepArtifacts is the number of most recent artifacts kept per type+name on write.
+const artifactStoreKeepArtifacts = 3
+
+// artifactStoreLockTimeout bounds the wait for the artifact store lock held by another process.
+var artifactStoreLockTimeout = 30 * time.Second
+
+// artifactStoreLockPollInterval is the delay between two attempts to take the artifact store lock.
+const artifactStoreLockPollInterval = 20 * time.Millisecond
+
 // lockArtifactStore acquires an exclusive file lock for the artifact store.
 // The lock is held on a separate .lock file to avoid interfering with reads.
+// It fails with errArtifactStoreLockTimeout when the lock is still held by another process
+// after artifactStoreLockTimeout.
 // The caller must call unlockArtifactStore to release the lock.
 func lockArtifactStore(path string) (*os.File, error) {
 	lockPath := path + ".lock"
@@ -413,13 +518,23 @@ func lockArtifactStore(path string) (*os.File, error) {
 		return nil, flaterrors.Join(err, errors.New("failed to open lock file"))
 	}
 
-	// Acquire exclusive lock (LOCK_EX) - blocks until lock is available
-	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
-		_ = lockFile.Close()
-		return nil, flaterrors.Join(err, errors.New("failed to acquire lock"))
+	// Acquire exclusive lock (LOCK_EX) without blocking, retrying until the timeout
+	deadline := time.Now().Add(artifactStoreLockTimeout)
+	for {
+		err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return lockFile, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) && !errors.Is(err, syscall.EINTR) {
+			_ = lockFile.Close()
+			return nil, flaterrors.Join(err, errors.New("failed to acquire lock"))
+		}
+		if time.Now().After(deadline) {
+			_ = lockFile.Close()
+			return nil, fmt.Errorf("%w %s: still held by another process after %s", errArtifactStoreLockTimeout, lockPath, artifactStoreLockTimeout)
+		}
+		time.Sleep(artifactStoreLockPollInterval)
 	}
-
-	return lockFile, nil
 }
 
 // unlockArtifactStore releases the file lock and closes the lock file.
@@ -443,30 +558,37 @@ func unlockArtifactStore(lockFile *os.File) error {
 // This function uses file locking to prevent concurrent write conflicts.
 //
 // IMPORTANT: This function performs an atomic read-merge-write to prevent race conditions.
-// After acquiring the lock, it re-reads the current store from disk and merges TestEnvironments
-// and TestReports to preserve entries that may have been written by concurrent processes.
+// After acquiring the lock, it re-reads the current store from disk and merges Artifacts,
+// TestEnvironments and TestReports to preserve entries that may have been written by concurrent
+// processes. Entries deleted concurrently may be restored from the incoming store: use
+// UpdateArtifactStore to modify the store read under the lock instead.
 func WriteArtifactStore(path string, store ArtifactStore) error {
-	// Acquire exclusive lock
-	lockFile, err := lockArtifactStore(path)
-	if err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-	defer func() { _ = unlockArtifactStore(lockFile) }()
-
-	// Re-read current store from disk while holding the lock
-	// This prevents race conditions where concurrent processes lose each other's writes
-	currentStore, err := ReadArtifactStore(path)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return flaterrors.Join(err, errWritingArtifactStore)
+	return UpdateArtifactStore(path, func(current *ArtifactStore) error {
+		mergeArtifactStore(&store, *current)
+		*current = store
+		return nil
+	})
+}
+
+// mergeArtifactStore adds the entries of current that are missing from store.
+// The entries of store take precedence (they're newer).
+func mergeArtifactStore(store *ArtifactStore, current ArtifactStore) {
+	// Merge Artifacts: preserve artifacts from disk that aren't in the incoming store
+	// (same name, type and version); pruning on write keeps the most recent ones
+	for _, artifact := range current.Artifacts {
+		if !slices.ContainsFunc(store.Artifacts, func(a Artifact) bool {
+			return a.Name == artifact.Name && a.Type == artifact.Type && a.Version == artifact.Version
+		}) {
+			store.Artifacts = append(store.Artifacts, artifact)
+		}
 	}
 
 	// Merge TestEnvironments: preserve entries from disk that aren't in the incoming store
-	// The incoming store's entries take precedence (they're newer)
-	if currentStore.TestEnvironments != nil {
+	if current.TestEnvironments != nil {
 		if store.TestEnvironments == nil {
 			store.TestEnvironments = make(map[string]*TestEnvironment)
 		}
-		for id, env := range currentStore.TestEnvironments {
+		for id, env := range current.TestEnvironments {
 			if _, exists := store.TestEnvironments[id]; !exists {
 				store.TestEnvironments[id] = env
 			}
@@ -474,20 +596,71 @@ func WriteArtifactStore(path string, store ArtifactStore) error {
 	}
 
 	// Merge TestReports: preserve entries from disk that aren't in the incoming store
-	// The incoming store's entries take precedence (they're newer)
-	if currentStore.TestReports != nil {
+	if current.TestReports != nil {
 		if store.TestReports == nil {
 			store.TestReports = make(map[string]*TestReport)
 		}
-		for id, report := range currentStore.TestReports {
+		for id, report := range current.TestReports {
 			if _, exists := store.TestReports[id]; !exists {
 				store.TestReports[id] = report
 			}
 		}
 	}
+}
+
+// UpdateArtifactStore applies update to the artifact store at path while holding its lock,
+// so that concurrent processes cannot interleave between the read and the write.
+// The store is created if it does not exist. Nothing is written if update returns an error.
+//
+// Example usage:
+//
+//	err := forge.UpdateArtifactStore(path, func(store *forge.ArtifactStore) error {
+//	    forge.AddOrUpdateTestReport(store, report)
+//	    return nil
+//	})
+//
+// With FORGE_STORE_BACKEND=sqlite, the update runs in a transaction of the SQLite database
+// next to path (see OpenStore).
+func UpdateArtifactStore(path string, update func(store *ArtifactStore) error) error {
+	store, err := OpenStore(path)
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	defer func() { _ = store.Close() }()
+	return store.Update(update)
+}
+
+// updateArtifactStoreFile is UpdateArtifactStore for the YAML artifact store at path.
+func updateArtifactStoreFile(path string, update func(store *ArtifactStore) error) error {
+	// Acquire exclusive lock
+	lockFile, err := lockArtifactStore(path)
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	defer func() { _ = unlockArtifactStore(lockFile) }()
+
+	// Read current store from disk while holding the lock
+	store, err := readOrCreateArtifactStoreFile(path)
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+
+	if err := update(&store); err != nil {
+		return err
+	}
+
+	return writeArtifactStoreFile(path, &store)
+}
+
+// writeArtifactStoreFile prunes store and writes it to path. The caller must hold the lock.
+// The file is replaced atomically so that readers, which do not take the lock, never see a
+// partially written store.
+func writeArtifactStoreFile(path string, store *ArtifactStore) error {
+	// The in-memory store always follows the current schema
+	store.Version = artifactStoreVersion
 
 	// Prune old build artifacts (keep only 3 most recent per type+name)
-	PruneBuildArtifacts(&store, 3)
+	PruneBuildArtifacts(store, artifactStoreKeepArtifacts)
 
 	b, err := yaml.Marshal(store)
 	if err != nil {
@@ -500,7 +673,22 @@ func WriteArtifactStore(path string, store ArtifactStore) error {
 		return flaterrors.Join(err, errWritingArtifactStore)
 	}
 
-	if err := os.WriteFile(path, b, 0o600); err != nil {
+	// Write to a temporary file in the same directory, then rename it over the store
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpPath) }() // No-op once renamed
+
+	if _, err := tmpFile.Write(b); err != nil {
+		_ = tmpFile.Close()
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
 		return flaterrors.Join(err, errWritingArtifactStore)
 	}
 
@@ -683,51 +871,12 @@ func DeleteTestEnvironment(store *ArtifactStore, id string) error {
 // This function handles file locking internally and reads/writes the store atomically.
 // Use this function instead of DeleteTestEnvironment + WriteArtifactStore to avoid race conditions.
 func AtomicDeleteTestEnvironment(path string, id string) error {
-	// Acquire exclusive lock
-	lockFile, err := lockArtifactStore(path)
-	if err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-	defer func() { _ = unlockArtifactStore(lockFile) }()
-
-	// Read current store from disk while holding the lock
-	store, err := ReadArtifactStore(path)
-	if err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-
-	// Delete the test environment
-	if store.TestEnvironments == nil {
-		return flaterrors.Join(
-			errors.New("test environment not found: "+id),
-			errTestEnvironmentNotFound,
-		)
-	}
-
-	if _, exists := store.TestEnvironments[id]; !exists {
-		return flaterrors.Join(
-			errors.New("test environment not found: "+id),
-			errTestEnvironmentNotFound,
-		)
-	}
-
-	delete(store.TestEnvironments, id)
-	store.LastUpdated = time.Now().UTC()
-
-	// Prune old build artifacts (keep only 3 most recent per type+name)
-	PruneBuildArtifacts(&store, 3)
-
-	// Write directly without merge (we already read the current state)
-	b, err := yaml.Marshal(store)
+	store, err := OpenStore(path)
 	if err != nil {
 		return flaterrors.Join(err, errWritingArtifactStore)
 	}
-
-	if err := os.WriteFile(path, b, 0o600); err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-
-	return nil
+	defer func() { _ = store.Close() }()
+	return store.Delete(StoreKindTestEnvironment, id)
 }
 
 // AddOrUpdateTestReport adds or updates a test report in the store.
@@ -819,51 +968,12 @@ func DeleteTestReport(store *ArtifactStore, id string) error {
 // Note: This does not delete the actual artifact files. Callers should handle
 // file cleanup separately using the report.ArtifactFiles list.
 func AtomicDeleteTestReport(path string, id string) error {
-	// Acquire exclusive lock
-	lockFile, err := lockArtifactStore(path)
-	if err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-	defer func() { _ = unlockArtifactStore(lockFile) }()
-
-	// Read current store from disk while holding the lock
-	store, err := ReadArtifactStore(path)
+	store, err := OpenStore(path)
 	if err != nil {
 		return flaterrors.Join(err, errWritingArtifactStore)
 	}
-
-	// Delete the test report
-	if store.TestReports == nil {
-		return flaterrors.Join(
-			errors.New("test report not found: "+id),
-			errTestReportNotFound,
-		)
-	}
-
-	if _, exists := store.TestReports[id]; !exists {
-		return flaterrors.Join(
-			errors.New("test report not found: "+id),
-			errTestReportNotFound,
-		)
-	}
-
-	delete(store.TestReports, id)
-	store.LastUpdated = time.Now().UTC()
-
-	// Prune old build artifacts (keep only 3 most recent per type+name)
-	PruneBuildArtifacts(&store, 3)
-
-	// Write directly without merge (we already read the current state)
-	b, err := yaml.Marshal(store)
-	if err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-
-	if err := os.WriteFile(path, b, 0o600); err != nil {
-		return flaterrors.Join(err, errWritingArtifactStore)
-	}
-
-	return nil
+	defer func() { _ = store.Close() }()
+	return store.Delete(StoreKindTestReport, id)
 }
 
 // GetArtifactStorePath returns the configured artifact store path from forge.yaml,
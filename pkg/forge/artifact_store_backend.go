@@ -0,0 +1,303 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/flaterrors"
+)
+
+// StoreBackendEnvVar is the environment variable selecting the artifact store backend.
+const StoreBackendEnvVar = "FORGE_STORE_BACKEND"
+
+// Artifact store backends.
+const (
+	// StoreBackendYAML stores the artifact store in a YAML file (default).
+	StoreBackendYAML = "yaml"
+	// StoreBackendSQLite stores the artifact store in a SQLite database next to the YAML path,
+	// with the ".db" extension (e.g. ".forge/artifact-store.db").
+	StoreBackendSQLite = "sqlite"
+)
+
+// Kinds of artifact store entries.
+const (
+	StoreKindArtifact        = "artifact"
+	StoreKindTestEnvironment = "testEnvironment"
+	StoreKindTestReport      = "testReport"
+)
+
+var (
+	errUnknownStoreBackend = errors.New("unknown artifact store backend")
+	errUnknownStoreKind    = errors.New("unknown artifact store entry kind")
+	errInvalidStoreEntry   = errors.New("invalid artifact store entry")
+)
+
+// StoreEntry is an entry of the artifact store. Exactly one of Artifact, TestEnvironment and
+// TestReport is set, according to Kind.
+type StoreEntry struct {
+	// Kind is StoreKindArtifact, StoreKindTestEnvironment or StoreKindTestReport.
+	Kind string
+	// ID identifies the entry within its kind: "<type>/<name>@<version>" for artifacts,
+	// the ID of the test environment or test report otherwise.
+	ID string
+
+	Artifact        *Artifact
+	TestEnvironment *TestEnvironment
+	TestReport      *TestReport
+}
+
+// Store is an artifact store backend.
+//
+// Every method is atomic with respect to other processes using the same backend.
+// Build artifacts are pruned on write like WriteArtifactStore does.
+type Store interface {
+	// Get returns the entry of kind with id.
+	// The error wraps the "not found" error of the kind when there is no such entry.
+	Get(kind, id string) (StoreEntry, error)
+	// List returns the entries of kind. Artifacts are listed in the order they were added,
+	// test environments and test reports by ID.
+	List(kind string) ([]StoreEntry, error)
+	// Put adds the entry, or replaces the entry of the same kind and ID.
+	// The ID is derived from the entry value.
+	Put(entry StoreEntry) error
+	// Delete removes the entry of kind with id.
+	// The error wraps the "not found" error of the kind when there is no such entry.
+	Delete(kind, id string) error
+
+	// Read returns the whole artifact store, empty if nothing was written yet.
+	Read() (ArtifactStore, error)
+	// Update applies update to the whole artifact store in a single transaction.
+	// Nothing is written if update returns an error.
+	Update(update func(store *ArtifactStore) error) error
+
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// OpenStore opens the artifact store at path with the backend selected by FORGE_STORE_BACKEND
+// (StoreBackendYAML if unset). path is the path of the YAML artifact store, as configured by
+// artifactStorePath in forge.yaml; other backends derive their location from it.
+func OpenStore(path string) (Store, error) {
+	backend, err := storeBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case StoreBackendSQLite:
+		return NewSQLiteStore(sqliteStorePath(path))
+	default:
+		return NewYAMLStore(path), nil
+	}
+}
+
+// storeBackend returns the artifact store backend selected by FORGE_STORE_BACKEND.
+func storeBackend() (string, error) {
+	switch backend := os.Getenv(StoreBackendEnvVar); backend {
+	case "", StoreBackendYAML:
+		return StoreBackendYAML, nil
+	case StoreBackendSQLite:
+		return StoreBackendSQLite, nil
+	default:
+		return "", flaterrors.Join(
+			fmt.Errorf("%s=%q: expected %q or %q", StoreBackendEnvVar, backend, StoreBackendYAML, StoreBackendSQLite),
+			errUnknownStoreBackend,
+		)
+	}
+}
+
+// yamlStore is the Store backed by the YAML file at path. Writes hold the file lock.
+type yamlStore struct {
+	path string
+}
+
+// NewYAMLStore returns the Store backed by the YAML artifact store at path.
+func NewYAMLStore(path string) Store {
+	return &yamlStore{path: path}
+}
+
+func (s *yamlStore) Get(kind, id string) (StoreEntry, error) {
+	store, err := s.Read()
+	if err != nil {
+		return StoreEntry{}, err
+	}
+	return getStoreEntry(&store, kind, id)
+}
+
+func (s *yamlStore) List(kind string) ([]StoreEntry, error) {
+	store, err := s.Read()
+	if err != nil {
+		return nil, err
+	}
+	return listStoreEntries(&store, kind)
+}
+
+func (s *yamlStore) Put(entry StoreEntry) error {
+	return s.Update(func(store *ArtifactStore) error {
+		return putStoreEntry(store, entry)
+	})
+}
+
+func (s *yamlStore) Delete(kind, id string) error {
+	return s.Update(func(store *ArtifactStore) error {
+		return deleteStoreEntry(store, kind, id)
+	})
+}
+
+func (s *yamlStore) Read() (ArtifactStore, error) {
+	return readOrCreateArtifactStoreFile(s.path)
+}
+
+func (s *yamlStore) Update(update func(store *ArtifactStore) error) error {
+	return updateArtifactStoreFile(s.path, update)
+}
+
+func (s *yamlStore) Close() error {
+	return nil
+}
+
+// storeEntryID derives the ID of entry from its value.
+func storeEntryID(entry StoreEntry) (string, error) {
+	switch entry.Kind {
+	case StoreKindArtifact:
+		if entry.Artifact != nil {
+			return artifactID(*entry.Artifact), nil
+		}
+	case StoreKindTestEnvironment:
+		if entry.TestEnvironment != nil && entry.TestEnvironment.ID != "" {
+			return entry.TestEnvironment.ID, nil
+		}
+	case StoreKindTestReport:
+		if entry.TestReport != nil && entry.TestReport.ID != "" {
+			return entry.TestReport.ID, nil
+		}
+	default:
+		return "", flaterrors.Join(fmt.Errorf("kind %q", entry.Kind), errUnknownStoreKind)
+	}
+	return "", flaterrors.Join(fmt.Errorf("%s entry has no value or no ID", entry.Kind), errInvalidStoreEntry)
+}
+
+// validateStoreKind returns an error if kind is not a kind of artifact store entry.
+func validateStoreKind(kind string) error {
+	switch kind {
+	case StoreKindArtifact, StoreKindTestEnvironment, StoreKindTestReport:
+		return nil
+	default:
+		return flaterrors.Join(fmt.Errorf("kind %q", kind), errUnknownStoreKind)
+	}
+}
+
+// artifactStoreExists reports whether the artifact store at path exists for the selected backend.
+func artifactStoreExists(path string) bool {
+	if backend, err := storeBackend(); err == nil && backend == StoreBackendSQLite {
+		path = sqliteStorePath(path)
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// storeEntryNotFound returns the "not found" error of kind for id.
+func storeEntryNotFound(kind, id string) error {
+	switch kind {
+	case StoreKindArtifact:
+		return flaterrors.Join(errors.New("artifact not found: "+id), errArtifactNotFound)
+	case StoreKindTestEnvironment:
+		return flaterrors.Join(errors.New("test environment not found: "+id), errTestEnvironmentNotFound)
+	default:
+		return flaterrors.Join(errors.New("test report not found: "+id), errTestReportNotFound)
+	}
+}
+
+// getStoreEntry returns the entry of kind with id from store.
+func getStoreEntry(store *ArtifactStore, kind, id string) (StoreEntry, error) {
+	entries, err := listStoreEntries(store, kind)
+	if err != nil {
+		return StoreEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return StoreEntry{}, storeEntryNotFound(kind, id)
+}
+
+// listStoreEntries returns the entries of kind from store.
+func listStoreEntries(store *ArtifactStore, kind string) ([]StoreEntry, error) {
+	if err := validateStoreKind(kind); err != nil {
+		return nil, err
+	}
+
+	var entries []StoreEntry
+	switch kind {
+	case StoreKindArtifact:
+		for _, artifact := range store.Artifacts {
+			entries = append(entries, StoreEntry{Kind: kind, ID: artifactID(artifact), Artifact: &artifact})
+		}
+	case StoreKindTestEnvironment:
+		for _, id := range slices.Sorted(maps.Keys(store.TestEnvironments)) {
+			entries = append(entries, StoreEntry{Kind: kind, ID: id, TestEnvironment: store.TestEnvironments[id]})
+		}
+	case StoreKindTestReport:
+		for _, id := range slices.Sorted(maps.Keys(store.TestReports)) {
+			entries = append(entries, StoreEntry{Kind: kind, ID: id, TestReport: store.TestReports[id]})
+		}
+	}
+	return entries, nil
+}
+
+// putStoreEntry adds or replaces entry in store.
+func putStoreEntry(store *ArtifactStore, entry StoreEntry) error {
+	if _, err := storeEntryID(entry); err != nil {
+		return err
+	}
+
+	switch entry.Kind {
+	case StoreKindArtifact:
+		AddOrUpdateArtifact(store, *entry.Artifact)
+		store.LastUpdated = time.Now().UTC()
+	case StoreKindTestEnvironment:
+		AddOrUpdateTestEnvironment(store, entry.TestEnvironment)
+	case StoreKindTestReport:
+		AddOrUpdateTestReport(store, entry.TestReport)
+	}
+	return nil
+}
+
+// deleteStoreEntry removes the entry of kind with id from store.
+func deleteStoreEntry(store *ArtifactStore, kind, id string) error {
+	switch kind {
+	case StoreKindArtifact:
+		i := slices.IndexFunc(store.Artifacts, func(a Artifact) bool { return artifactID(a) == id })
+		if i < 0 {
+			return storeEntryNotFound(kind, id)
+		}
+		store.Artifacts = slices.Delete(store.Artifacts, i, i+1)
+		store.LastUpdated = time.Now().UTC()
+		return nil
+	case StoreKindTestEnvironment:
+		return DeleteTestEnvironment(store, id)
+	case StoreKindTestReport:
+		return DeleteTestReport(store, id)
+	default:
+		return validateStoreKind(kind)
+	}
+}
@@ -0,0 +1,426 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// storeBackends opens an empty Store of each backend.
+var storeBackends = map[string]func(t *testing.T) Store{
+	StoreBackendYAML: func(t *testing.T) Store {
+		return NewYAMLStore(filepath.Join(t.TempDir(), "artifact-store.yaml"))
+	},
+	StoreBackendSQLite: func(t *testing.T) Store {
+		store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "artifact-store.db"))
+		if err != nil {
+			t.Fatalf("NewSQLiteStore failed: %v", err)
+		}
+		return store
+	},
+}
+
+// TestStoreConformance runs the Store conformance suite against every backend.
+func TestStoreConformance(t *testing.T) {
+	for backend, open := range storeBackends {
+		t.Run(backend, func(t *testing.T) {
+			t.Run("GetPutDelete", func(t *testing.T) { testStoreGetPutDelete(t, open(t)) })
+			t.Run("List", func(t *testing.T) { testStoreList(t, open(t)) })
+			t.Run("PrunesArtifacts", func(t *testing.T) { testStorePrunesArtifacts(t, open(t)) })
+			t.Run("Update", func(t *testing.T) { testStoreUpdate(t, open(t)) })
+			t.Run("ConcurrentPuts", func(t *testing.T) { testStoreConcurrentPuts(t, open(t)) })
+			t.Run("InvalidEntries", func(t *testing.T) { testStoreInvalidEntries(t, open(t)) })
+		})
+	}
+}
+
+func closeStore(t *testing.T, store Store) {
+	t.Helper()
+	if err := store.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func testStoreGetPutDelete(t *testing.T, store Store) {
+	defer closeStore(t, store)
+
+	artifact := Artifact{Name: "app", Type: "binary", Location: "./build/bin/app", Version: "v1", Timestamp: "2024-01-01T00:00:00Z"}
+	entries := []StoreEntry{
+		{Kind: StoreKindArtifact, Artifact: &artifact},
+		{Kind: StoreKindTestEnvironment, TestEnvironment: &TestEnvironment{ID: "env-1", Name: "e2e", Status: TestStatusCreated}},
+		{Kind: StoreKindTestReport, TestReport: &TestReport{ID: "report-1", Stage: "unit", Status: "passed"}},
+	}
+	ids := []string{"binary/app@v1", "env-1", "report-1"}
+	notFound := []error{errArtifactNotFound, errTestEnvironmentNotFound, errTestReportNotFound}
+
+	for i, entry := range entries {
+		if _, err := store.Get(entry.Kind, ids[i]); !errors.Is(err, notFound[i]) {
+			t.Errorf("Get(%s) before Put: expected %v, got %v", ids[i], notFound[i], err)
+		}
+		if err := store.Put(entry); err != nil {
+			t.Fatalf("Put(%s) failed: %v", ids[i], err)
+		}
+
+		got, err := store.Get(entry.Kind, ids[i])
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", ids[i], err)
+		}
+		if got.Kind != entry.Kind || got.ID != ids[i] {
+			t.Errorf("Get(%s): got kind %q and ID %q", ids[i], got.Kind, got.ID)
+		}
+	}
+
+	got, _ := store.Get(StoreKindArtifact, "binary/app@v1")
+	if got.Artifact == nil || got.Artifact.Location != artifact.Location {
+		t.Errorf("expected artifact %+v, got %+v", artifact, got.Artifact)
+	}
+	got, _ = store.Get(StoreKindTestEnvironment, "env-1")
+	if got.TestEnvironment == nil || got.TestEnvironment.Name != "e2e" || got.TestEnvironment.UpdatedAt.IsZero() {
+		t.Errorf("unexpected test environment %+v", got.TestEnvironment)
+	}
+	got, _ = store.Get(StoreKindTestReport, "report-1")
+	if got.TestReport == nil || got.TestReport.Stage != "unit" || got.TestReport.CreatedAt.IsZero() {
+		t.Errorf("unexpected test report %+v", got.TestReport)
+	}
+
+	// Put replaces the entry of the same ID
+	updated := artifact
+	updated.Location = "./build/bin/app-v1"
+	if err := store.Put(StoreEntry{Kind: StoreKindArtifact, Artifact: &updated}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	list, err := store.List(StoreKindArtifact)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Artifact.Location != updated.Location {
+		t.Errorf("expected the artifact to be replaced, got %+v", list)
+	}
+
+	for i, entry := range entries {
+		if err := store.Delete(entry.Kind, ids[i]); err != nil {
+			t.Fatalf("Delete(%s) failed: %v", ids[i], err)
+		}
+		if _, err := store.Get(entry.Kind, ids[i]); !errors.Is(err, notFound[i]) {
+			t.Errorf("Get(%s) after Delete: expected %v, got %v", ids[i], notFound[i], err)
+		}
+		if err := store.Delete(entry.Kind, ids[i]); !errors.Is(err, notFound[i]) {
+			t.Errorf("Delete(%s) twice: expected %v, got %v", ids[i], notFound[i], err)
+		}
+	}
+}
+
+func testStoreList(t *testing.T, store Store) {
+	defer closeStore(t, store)
+
+	for _, name := range []string{"b", "a", "c"} {
+		artifact := Artifact{Name: name, Type: "binary", Location: name, Version: "v1", Timestamp: "2024-01-01T00:00:00Z"}
+		if err := store.Put(StoreEntry{Kind: StoreKindArtifact, Artifact: &artifact}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+		if err := store.Put(StoreEntry{Kind: StoreKindTestReport, TestReport: &TestReport{ID: "report-" + name}}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	artifacts, err := store.List(StoreKindArtifact)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, entry := range artifacts {
+		names = append(names, entry.Artifact.Name)
+	}
+	if fmt.Sprint(names) != "[b a c]" {
+		t.Errorf("expected artifacts in insertion order [b a c], got %v", names)
+	}
+
+	reports, err := store.List(StoreKindTestReport)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var ids []string
+	for _, entry := range reports {
+		ids = append(ids, entry.ID)
+	}
+	if fmt.Sprint(ids) != "[report-a report-b report-c]" {
+		t.Errorf("expected test reports sorted by ID, got %v", ids)
+	}
+
+	envs, err := store.List(StoreKindTestEnvironment)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(envs) != 0 {
+		t.Errorf("expected no test environments, got %v", envs)
+	}
+}
+
+func testStorePrunesArtifacts(t *testing.T, store Store) {
+	defer closeStore(t, store)
+
+	for i := 1; i <= artifactStoreKeepArtifacts+2; i++ {
+		artifact := Artifact{
+			Name:      "app",
+			Type:      "binary",
+			Location:  "./build/bin/app",
+			Version:   fmt.Sprintf("v%d", i),
+			Timestamp: time.Date(2024, 1, i, 0, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		}
+		if err := store.Put(StoreEntry{Kind: StoreKindArtifact, Artifact: &artifact}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	artifacts, err := store.List(StoreKindArtifact)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(artifacts) != artifactStoreKeepArtifacts {
+		t.Fatalf("expected %d artifacts, got %d", artifactStoreKeepArtifacts, len(artifacts))
+	}
+	if _, err := store.Get(StoreKindArtifact, "binary/app@v1"); !errors.Is(err, errArtifactNotFound) {
+		t.Errorf("expected the oldest artifact to be pruned, got %v", err)
+	}
+}
+
+func testStoreUpdate(t *testing.T, store Store) {
+	defer closeStore(t, store)
+
+	err := store.Update(func(s *ArtifactStore) error {
+		AddOrUpdateTestEnvironment(s, &TestEnvironment{ID: "env-1", Name: "e2e"})
+		AddOrUpdateArtifact(s, Artifact{Name: "app", Type: "binary", Location: "app", Version: "v1", Timestamp: "2024-01-01T00:00:00Z"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Nothing is written when the update fails
+	errUpdate := errors.New("update failed")
+	err = store.Update(func(s *ArtifactStore) error {
+		AddOrUpdateTestEnvironment(s, &TestEnvironment{ID: "env-2", Name: "e2e"})
+		return errUpdate
+	})
+	if !errors.Is(err, errUpdate) {
+		t.Fatalf("expected the update error, got %v", err)
+	}
+
+	read, err := store.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if read.Version != artifactStoreVersion {
+		t.Errorf("expected version %s, got %s", artifactStoreVersion, read.Version)
+	}
+	if len(read.Artifacts) != 1 || len(read.TestEnvironments) != 1 {
+		t.Errorf("expected 1 artifact and 1 test environment, got %+v", read)
+	}
+	if _, ok := read.TestEnvironments["env-2"]; ok {
+		t.Error("expected the failed update to be discarded")
+	}
+	if _, err := store.Get(StoreKindTestEnvironment, "env-1"); err != nil {
+		t.Errorf("expected env-1 to be visible through Get: %v", err)
+	}
+}
+
+func testStoreConcurrentPuts(t *testing.T, store Store) {
+	defer closeStore(t, store)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env := &TestEnvironment{ID: fmt.Sprintf("env-%d", i), Name: "e2e"}
+			errs <- store.Put(StoreEntry{Kind: StoreKindTestEnvironment, TestEnvironment: env})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	envs, err := store.List(StoreKindTestEnvironment)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(envs) != writers {
+		t.Errorf("expected %d test environments, got %d", writers, len(envs))
+	}
+}
+
+func testStoreInvalidEntries(t *testing.T, store Store) {
+	defer closeStore(t, store)
+
+	if _, err := store.Get("unknown", "id"); !errors.Is(err, errUnknownStoreKind) {
+		t.Errorf("Get: expected %v, got %v", errUnknownStoreKind, err)
+	}
+	if _, err := store.List("unknown"); !errors.Is(err, errUnknownStoreKind) {
+		t.Errorf("List: expected %v, got %v", errUnknownStoreKind, err)
+	}
+	if err := store.Delete("unknown", "id"); !errors.Is(err, errUnknownStoreKind) {
+		t.Errorf("Delete: expected %v, got %v", errUnknownStoreKind, err)
+	}
+	if err := store.Put(StoreEntry{Kind: StoreKindTestReport}); !errors.Is(err, errInvalidStoreEntry) {
+		t.Errorf("Put without value: expected %v, got %v", errInvalidStoreEntry, err)
+	}
+	if err := store.Put(StoreEntry{Kind: StoreKindTestEnvironment, TestEnvironment: &TestEnvironment{}}); !errors.Is(err, errInvalidStoreEntry) {
+		t.Errorf("Put without ID: expected %v, got %v", errInvalidStoreEntry, err)
+	}
+}
+
+func TestOpenStore_Backend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artifact-store.yaml")
+
+	t.Setenv(StoreBackendEnvVar, "")
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	if _, ok := store.(*yamlStore); !ok {
+		t.Errorf("expected the YAML backend by default, got %T", store)
+	}
+
+	t.Setenv(StoreBackendEnvVar, "postgres")
+	if _, err := OpenStore(path); !errors.Is(err, errUnknownStoreBackend) {
+		t.Errorf("expected %v, got %v", errUnknownStoreBackend, err)
+	}
+}
+
+func TestArtifactStore_SQLiteBackend(t *testing.T) {
+	t.Setenv(StoreBackendEnvVar, StoreBackendSQLite)
+	path := filepath.Join(t.TempDir(), ".forge", "artifact-store.yaml")
+
+	if _, err := ReadArtifactStore(path); !errors.Is(err, errReadingArtifactStore) || artifactStoreExists(path) {
+		t.Fatalf("expected a missing store, got %v", err)
+	}
+
+	store, err := ReadOrCreateArtifactStore(path)
+	if err != nil {
+		t.Fatalf("ReadOrCreateArtifactStore failed: %v", err)
+	}
+	AddOrUpdateTestEnvironment(&store, &TestEnvironment{ID: "env-1", Name: "e2e"})
+	if err := WriteArtifactStore(path, store); err != nil {
+		t.Fatalf("WriteArtifactStore failed: %v", err)
+	}
+	err = UpdateArtifactStore(path, func(s *ArtifactStore) error {
+		AddOrUpdateTestReport(s, &TestReport{ID: "report-1", Stage: "unit"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateArtifactStore failed: %v", err)
+	}
+
+	if !artifactStoreExists(path) {
+		t.Fatalf("expected the SQLite store %s to exist", sqliteStorePath(path))
+	}
+	if fileExists(path) {
+		t.Errorf("expected no YAML store at %s", path)
+	}
+
+	read, err := ReadArtifactStore(path)
+	if err != nil {
+		t.Fatalf("ReadArtifactStore failed: %v", err)
+	}
+	if _, ok := read.TestEnvironments["env-1"]; !ok {
+		t.Error("expected env-1 to be stored")
+	}
+	if _, ok := read.TestReports["report-1"]; !ok {
+		t.Error("expected report-1 to be stored")
+	}
+
+	if err := AtomicDeleteTestReport(path, "report-1"); err != nil {
+		t.Fatalf("AtomicDeleteTestReport failed: %v", err)
+	}
+	if err := AtomicDeleteTestReport(path, "report-1"); !errors.Is(err, errTestReportNotFound) {
+		t.Errorf("expected %v, got %v", errTestReportNotFound, err)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestSQLiteStore_PathWithSpecialCharacters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my store#1?", "artifact-store.db")
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.Put(StoreEntry{Kind: StoreKindTestEnvironment, TestEnvironment: &TestEnvironment{ID: "env-1"}}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if !fileExists(path) {
+		t.Errorf("expected the SQLite store to be created at %s", path)
+	}
+}
+
+func TestSQLiteStore_ReadDoesNotWaitForUpdate(t *testing.T) {
+	oldTimeout := artifactStoreLockTimeout
+	artifactStoreLockTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { artifactStoreLockTimeout = oldTimeout })
+
+	path := filepath.Join(t.TempDir(), "artifact-store.db")
+	writer, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer func() { _ = writer.Close() }()
+	reader, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- writer.Update(func(store *ArtifactStore) error {
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+
+	<-locked
+	_, readErr := reader.Read()
+	close(release)
+
+	if readErr != nil {
+		t.Errorf("Read failed while an update held the write lock: %v", readErr)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alexandremahdhaoui/forge/pkg/flaterrors"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	errUnsupportedArtifactStoreVersion = errors.New("unsupported artifact store version")
+	errMigratingArtifactStore          = errors.New("migrating artifact store")
+)
+
+// legacyArtifactStoreVersion is assumed for stores written without a version field.
+const legacyArtifactStoreVersion = "1.0"
+
+// artifactStoreMigration upgrades the raw representation of an artifact store
+// from one schema version to the next.
+type artifactStoreMigration struct {
+	From    string
+	To      string
+	Migrate func(raw map[string]any) error
+}
+
+// artifactStoreMigrations is the ordered chain of registered schema migrations.
+// Each entry's To must match the From of the next entry, and the last entry's To
+// must be artifactStoreVersion. No migration is registered while the schema is
+// still at its first version.
+var artifactStoreMigrations = []artifactStoreMigration{}
+
+// decodeArtifactStore parses raw artifact store bytes, detects the schema version,
+// and applies registered migrations until the store matches artifactStoreVersion.
+func decodeArtifactStore(b []byte) (ArtifactStore, error) {
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return ArtifactStore{}, err
+	}
+	if raw == nil {
+		raw = map[string]any{}
+	}
+
+	if err := migrateArtifactStore(raw); err != nil {
+		return ArtifactStore{}, err
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errMigratingArtifactStore)
+	}
+
+	out := ArtifactStore{} //nolint:exhaustruct // unmarshal
+	if err := json.Unmarshal(migrated, &out); err != nil {
+		return ArtifactStore{}, err
+	}
+
+	return out, nil
+}
+
+// migrateArtifactStore upgrades raw in place to artifactStoreVersion.
+// It fails if the version is unknown or newer than the one supported by this binary.
+func migrateArtifactStore(raw map[string]any) error {
+	return migrateArtifactStoreTo(raw, artifactStoreVersion, artifactStoreMigrations)
+}
+
+// migrateArtifactStoreTo upgrades raw in place to target by applying migrations in order.
+func migrateArtifactStoreTo(raw map[string]any, target string, migrations []artifactStoreMigration) error {
+	version, err := artifactStoreVersionOf(raw)
+	if err != nil {
+		return err
+	}
+
+	cmp, err := compareArtifactStoreVersions(version, target)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return fmt.Errorf("%w: store version %q is newer than supported version %q; upgrade forge",
+			errUnsupportedArtifactStoreVersion, version, target)
+	}
+
+	// Normalize legacy forms (missing or unquoted version) before decoding
+	raw["version"] = version
+
+	for version != target {
+		migration, ok := findArtifactStoreMigration(migrations, version)
+		if !ok {
+			return fmt.Errorf("%w: no migration registered from version %q",
+				errUnsupportedArtifactStoreVersion, version)
+		}
+
+		if err := migration.Migrate(raw); err != nil {
+			return flaterrors.Join(
+				fmt.Errorf("failed to migrate from %q to %q: %w", migration.From, migration.To, err),
+				errMigratingArtifactStore,
+			)
+		}
+
+		version = migration.To
+		raw["version"] = version
+	}
+
+	return nil
+}
+
+// artifactStoreVersionOf returns the schema version recorded in raw.
+func artifactStoreVersionOf(raw map[string]any) (string, error) {
+	v, ok := raw["version"]
+	if !ok || v == nil {
+		return legacyArtifactStoreVersion, nil
+	}
+
+	switch version := v.(type) {
+	case string:
+		if version == "" {
+			return legacyArtifactStoreVersion, nil
+		}
+		return version, nil
+	case float64:
+		// An unquoted `version: 1.0` is decoded as a number.
+		return strconv.FormatFloat(version, 'f', 1, 64), nil
+	default:
+		return "", fmt.Errorf("%w: version must be a string, got %T", errUnsupportedArtifactStoreVersion, v)
+	}
+}
+
+func findArtifactStoreMigration(migrations []artifactStoreMigration, from string) (artifactStoreMigration, bool) {
+	for _, m := range migrations {
+		if m.From == from {
+			return m, true
+		}
+	}
+	return artifactStoreMigration{}, false
+}
+
+// compareArtifactStoreVersions compares two "major.minor" versions and returns
+// -1, 0 or 1 like strings.Compare.
+func compareArtifactStoreVersions(a, b string) (int, error) {
+	pa, err := parseArtifactStoreVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseArtifactStoreVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range pa {
+		if pa[i] < pb[i] {
+			return -1, nil
+		}
+		if pa[i] > pb[i] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseArtifactStoreVersion(version string) ([2]int, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) != 2 {
+		return [2]int{}, fmt.Errorf("%w: %q is not a \"major.minor\" version", errUnsupportedArtifactStoreVersion, version)
+	}
+
+	var out [2]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return [2]int{}, fmt.Errorf("%w: %q is not a \"major.minor\" version", errUnsupportedArtifactStoreVersion, version)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
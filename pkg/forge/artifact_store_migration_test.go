@@ -0,0 +1,244 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadArtifactStore_V1_0Fixture(t *testing.T) {
+	store, err := ReadArtifactStore(filepath.Join("testdata", "artifact-store-v1.0.yaml"))
+	if err != nil {
+		t.Fatalf("ReadArtifactStore() error = %v", err)
+	}
+
+	if store.Version != artifactStoreVersion {
+		t.Errorf("Version = %q, want %q", store.Version, artifactStoreVersion)
+	}
+
+	if len(store.Artifacts) != 1 {
+		t.Fatalf("len(Artifacts) = %d, want 1", len(store.Artifacts))
+	}
+	if got := store.Artifacts[0]; got.Name != "my-app" || len(got.Dependencies) != 1 {
+		t.Errorf("unexpected artifact after read: %+v", got)
+	}
+
+	withCoverage, ok := store.TestReports["report-with-coverage"]
+	if !ok {
+		t.Fatal("report-with-coverage not found")
+	}
+	if withCoverage.Coverage.Percentage != 82.5 {
+		t.Errorf("Coverage.Percentage = %v, want 82.5", withCoverage.Coverage.Percentage)
+	}
+	if _, ok := store.TestReports["report-without-coverage"]; !ok {
+		t.Fatal("report-without-coverage not found")
+	}
+}
+
+func TestWriteArtifactStore_PersistsCurrentVersion(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "store.yaml")
+	if err := os.WriteFile(storePath, []byte("artifacts: []\n"), 0o600); err != nil {
+		t.Fatalf("failed to write store: %v", err)
+	}
+
+	store, err := ReadArtifactStore(storePath)
+	if err != nil {
+		t.Fatalf("ReadArtifactStore() error = %v", err)
+	}
+	if err := WriteArtifactStore(storePath, store); err != nil {
+		t.Fatalf("WriteArtifactStore() error = %v", err)
+	}
+
+	b, err := os.ReadFile(storePath)
+	if err != nil {
+		t.Fatalf("failed to read written store: %v", err)
+	}
+	reread, err := decodeArtifactStore(b)
+	if err != nil {
+		t.Fatalf("decodeArtifactStore() error = %v", err)
+	}
+
+	if reread.Version != artifactStoreVersion {
+		t.Errorf("Version = %q, want %q", reread.Version, artifactStoreVersion)
+	}
+}
+
+// testArtifactStoreMigrations is a test-only chain exercising multi-step migrations.
+func testArtifactStoreMigrations(applied *[]string) []artifactStoreMigration {
+	return []artifactStoreMigration{
+		{From: "1.0", To: "1.1", Migrate: func(raw map[string]any) error {
+			*applied = append(*applied, "1.0->1.1")
+			raw["renamed"] = raw["original"]
+			delete(raw, "original")
+			return nil
+		}},
+		{From: "1.1", To: "2.0", Migrate: func(raw map[string]any) error {
+			*applied = append(*applied, "1.1->2.0")
+			if _, ok := raw["renamed"]; !ok {
+				return errors.New("expected the 1.1 migration to run first")
+			}
+			return nil
+		}},
+	}
+}
+
+func TestMigrateArtifactStoreTo_AppliesChainInOrder(t *testing.T) {
+	var applied []string
+	raw := map[string]any{"version": "1.0", "original": "value"}
+
+	if err := migrateArtifactStoreTo(raw, "2.0", testArtifactStoreMigrations(&applied)); err != nil {
+		t.Fatalf("migrateArtifactStoreTo() error = %v", err)
+	}
+
+	if got := strings.Join(applied, ","); got != "1.0->1.1,1.1->2.0" {
+		t.Errorf("applied migrations = %q, want %q", got, "1.0->1.1,1.1->2.0")
+	}
+	if raw["version"] != "2.0" {
+		t.Errorf("version = %v, want 2.0", raw["version"])
+	}
+	if raw["renamed"] != "value" {
+		t.Errorf("renamed = %v, want value", raw["renamed"])
+	}
+	if _, ok := raw["original"]; ok {
+		t.Error("expected original to be removed by the migration")
+	}
+}
+
+func TestMigrateArtifactStoreTo_StartsFromRecordedVersion(t *testing.T) {
+	var applied []string
+	raw := map[string]any{"version": "1.1", "renamed": "value"}
+
+	if err := migrateArtifactStoreTo(raw, "2.0", testArtifactStoreMigrations(&applied)); err != nil {
+		t.Fatalf("migrateArtifactStoreTo() error = %v", err)
+	}
+	if got := strings.Join(applied, ","); got != "1.1->2.0" {
+		t.Errorf("applied migrations = %q, want %q", got, "1.1->2.0")
+	}
+}
+
+func TestMigrateArtifactStoreTo_Errors(t *testing.T) {
+	t.Run("missing migration", func(t *testing.T) {
+		var applied []string
+		migrations := testArtifactStoreMigrations(&applied)[:1]
+		err := migrateArtifactStoreTo(map[string]any{"version": "1.0", "original": "value"}, "2.0", migrations)
+		if !errors.Is(err, errUnsupportedArtifactStoreVersion) {
+			t.Fatalf("migrateArtifactStoreTo() error = %v, want %v", err, errUnsupportedArtifactStoreVersion)
+		}
+	})
+
+	t.Run("failing migration", func(t *testing.T) {
+		migrations := []artifactStoreMigration{{From: "1.0", To: "1.1", Migrate: func(map[string]any) error {
+			return errors.New("boom")
+		}}}
+		err := migrateArtifactStoreTo(map[string]any{"version": "1.0"}, "1.1", migrations)
+		if !errors.Is(err, errMigratingArtifactStore) {
+			t.Fatalf("migrateArtifactStoreTo() error = %v, want %v", err, errMigratingArtifactStore)
+		}
+	})
+}
+
+func TestDecodeArtifactStore_Versions(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		wantVersion string
+		wantErr     error
+	}{
+		{
+			name:        "missing version is treated as legacy",
+			yaml:        "artifacts: []\n",
+			wantVersion: artifactStoreVersion,
+		},
+		{
+			name:        "unquoted legacy version",
+			yaml:        "version: 1.0\nartifacts: []\n",
+			wantVersion: artifactStoreVersion,
+		},
+		{
+			name:        "current version",
+			yaml:        "version: \"" + artifactStoreVersion + "\"\nartifacts: []\n",
+			wantVersion: artifactStoreVersion,
+		},
+		{
+			name:    "newer version",
+			yaml:    "version: \"99.0\"\nartifacts: []\n",
+			wantErr: errUnsupportedArtifactStoreVersion,
+		},
+		{
+			name:    "unknown older version",
+			yaml:    "version: \"0.9\"\nartifacts: []\n",
+			wantErr: errUnsupportedArtifactStoreVersion,
+		},
+		{
+			name:    "malformed version",
+			yaml:    "version: \"v1\"\nartifacts: []\n",
+			wantErr: errUnsupportedArtifactStoreVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := decodeArtifactStore([]byte(tt.yaml))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("decodeArtifactStore() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeArtifactStore() error = %v", err)
+			}
+			if store.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", store.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestReadArtifactStore_NewerVersionFails(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "store.yaml")
+	if err := os.WriteFile(storePath, []byte("version: \"2.0\"\nartifacts: []\n"), 0o600); err != nil {
+		t.Fatalf("failed to write store: %v", err)
+	}
+
+	_, err := ReadArtifactStore(storePath)
+	if !errors.Is(err, errUnsupportedArtifactStoreVersion) {
+		t.Fatalf("ReadArtifactStore() error = %v, want %v", err, errUnsupportedArtifactStoreVersion)
+	}
+	if !errors.Is(err, errReadingArtifactStore) {
+		t.Errorf("expected error to wrap errReadingArtifactStore, got %v", err)
+	}
+}
+
+func TestArtifactStoreMigrationsChainToCurrentVersion(t *testing.T) {
+	if len(artifactStoreMigrations) == 0 {
+		t.Skip("no migrations registered")
+	}
+	for i := 1; i < len(artifactStoreMigrations); i++ {
+		if artifactStoreMigrations[i-1].To != artifactStoreMigrations[i].From {
+			t.Errorf("migration %d ends at %q but migration %d starts at %q",
+				i-1, artifactStoreMigrations[i-1].To, i, artifactStoreMigrations[i].From)
+		}
+	}
+	if last := artifactStoreMigrations[len(artifactStoreMigrations)-1]; last.To != artifactStoreVersion {
+		t.Errorf("last migration ends at %q, want %q", last.To, artifactStoreVersion)
+	}
+}
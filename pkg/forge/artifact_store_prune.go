@@ -0,0 +1,228 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+)
+
+// PruneOptions configures PruneArtifactStore.
+type PruneOptions struct {
+	// KeepArtifacts is the number of most recent artifacts kept per type+name (default: 1).
+	KeepArtifacts int
+	// DryRun reports the stale entries without modifying the artifact store.
+	DryRun bool
+}
+
+// PrunedEntry is an artifact store entry removed by PruneArtifactStore.
+type PrunedEntry struct {
+	// Kind is StoreKindArtifact, StoreKindTestEnvironment or StoreKindTestReport.
+	Kind string `json:"kind" yaml:"kind"`
+	// ID identifies the entry: "<type>/<name>@<version>" for artifacts, the ID otherwise.
+	ID string `json:"id" yaml:"id"`
+	// Reason explains why the entry is stale.
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// PruneResult is the result of PruneArtifactStore.
+type PruneResult struct {
+	// DryRun is true when the entries were only reported, not removed.
+	DryRun bool `json:"dryRun" yaml:"dryRun"`
+	// Pruned lists the stale entries.
+	Pruned []PrunedEntry `json:"pruned" yaml:"pruned"`
+	// Kept lists the test environments that look stale but were not removed because
+	// their subengine resources (kind cluster, helm releases, registry...) may still
+	// exist. They must be deleted with testenv gc or testenv delete.
+	Kept []PrunedEntry `json:"kept,omitempty" yaml:"kept,omitempty"`
+}
+
+// PruneArtifactStore removes stale entries from the artifact store at path:
+//   - artifacts whose local file or directory no longer exists (container images and
+//     remote locations are not checked)
+//   - artifacts superseded by more recent builds of the same type and name, beyond
+//     opts.KeepArtifacts
+//   - test environments whose temporary directory no longer exists, when no subengine
+//     resources were recorded for them and none of their managed resources exist
+//   - test reports whose artifact files and output path no longer exist
+//
+// Test environments whose temporary directory is gone but whose subengine resources
+// cannot be confirmed gone are reported in PruneResult.Kept and left in the store:
+// testenv delete and testenv gc need the entry to tear those resources down.
+//
+// The store is read and written under its lock (see UpdateArtifactStore).
+// With opts.DryRun, the stale entries are reported and the store is left untouched.
+func PruneArtifactStore(path string, opts PruneOptions) (PruneResult, error) {
+	result := PruneResult{DryRun: opts.DryRun}
+
+	if opts.DryRun {
+		store, err := ReadArtifactStore(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return result, nil
+			}
+			return result, err
+		}
+		result.Pruned, result.Kept = PruneStaleEntries(&store, opts.KeepArtifacts)
+		return result, nil
+	}
+
+	if !artifactStoreExists(path) {
+		return result, nil
+	}
+	err := UpdateArtifactStore(path, func(store *ArtifactStore) error {
+		result.Pruned, result.Kept = PruneStaleEntries(store, opts.KeepArtifacts)
+		return nil
+	})
+	return result, err
+}
+
+// PruneStaleEntries removes the stale entries described in PruneArtifactStore from store,
+// keeping the keepArtifacts most recent artifacts per type+name (1 if keepArtifacts < 1),
+// and returns them along with the stale test environments it kept.
+func PruneStaleEntries(store *ArtifactStore, keepArtifacts int) (pruned, kept []PrunedEntry) {
+	if store == nil {
+		return nil, nil
+	}
+	if keepArtifacts < 1 {
+		keepArtifacts = 1
+	}
+
+	// Artifacts whose backing file is gone
+	live := make([]Artifact, 0, len(store.Artifacts))
+	for _, artifact := range store.Artifacts {
+		if path, ok := artifactBackingPath(artifact); ok && !pathExists(path) {
+			pruned = append(pruned, PrunedEntry{
+				Kind:   StoreKindArtifact,
+				ID:     artifactID(artifact),
+				Reason: fmt.Sprintf("location %s no longer exists", path),
+			})
+			continue
+		}
+		live = append(live, artifact)
+	}
+
+	// Artifacts superseded by more recent builds of the same type and name
+	groups := make(map[string][]Artifact)
+	var order []string
+	for _, artifact := range live {
+		key := artifact.Type + ":" + artifact.Name
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], artifact)
+	}
+	keptArtifacts := make([]Artifact, 0, len(live))
+	for _, key := range order {
+		artifacts := groups[key]
+		sortArtifactsNewestFirst(artifacts)
+		for i, artifact := range artifacts {
+			if i < keepArtifacts {
+				keptArtifacts = append(keptArtifacts, artifact)
+				continue
+			}
+			pruned = append(pruned, PrunedEntry{
+				Kind:   StoreKindArtifact,
+				ID:     artifactID(artifact),
+				Reason: fmt.Sprintf("superseded by %s", artifacts[0].Version),
+			})
+		}
+	}
+	store.Artifacts = keptArtifacts
+
+	// Test environments whose temporary directory is gone. A missing tmpDir does not mean
+	// that the subengine resources were deleted, so only environments without any are pruned.
+	for _, id := range slices.Sorted(maps.Keys(store.TestEnvironments)) {
+		env := store.TestEnvironments[id]
+		if env == nil || env.TmpDir == "" || pathExists(env.TmpDir) {
+			continue
+		}
+		if testEnvironmentMayHoldResources(env) {
+			kept = append(kept, PrunedEntry{
+				Kind:   StoreKindTestEnvironment,
+				ID:     id,
+				Reason: fmt.Sprintf("tmpDir %s no longer exists but its subengine resources may still exist", env.TmpDir),
+			})
+			continue
+		}
+		pruned = append(pruned, PrunedEntry{
+			Kind:   StoreKindTestEnvironment,
+			ID:     id,
+			Reason: fmt.Sprintf("tmpDir %s no longer exists", env.TmpDir),
+		})
+		delete(store.TestEnvironments, id)
+	}
+
+	// Test reports whose files are all gone
+	for _, id := range slices.Sorted(maps.Keys(store.TestReports)) {
+		report := store.TestReports[id]
+		if report == nil {
+			continue
+		}
+		files := slices.Clone(report.ArtifactFiles)
+		if report.OutputPath != "" {
+			files = append(files, report.OutputPath)
+		}
+		if len(files) == 0 || slices.ContainsFunc(files, pathExists) {
+			continue
+		}
+		pruned = append(pruned, PrunedEntry{
+			Kind:   StoreKindTestReport,
+			ID:     id,
+			Reason: "artifact files no longer exist",
+		})
+		delete(store.TestReports, id)
+	}
+
+	return pruned, kept
+}
+
+// testEnvironmentMayHoldResources reports whether env may still own resources outside its
+// tmpDir: subengines record their resources in env.Metadata, and managed resources are
+// local paths that can be checked.
+func testEnvironmentMayHoldResources(env *TestEnvironment) bool {
+	if len(env.Metadata) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(env.ManagedResources, pathExists)
+}
+
+// artifactBackingPath returns the local path backing an artifact, if its existence can be checked.
+// Container artifacts are located by an image reference, not a path.
+func artifactBackingPath(artifact Artifact) (string, bool) {
+	if artifact.Type == "container" {
+		return "", false
+	}
+	path, err := artifactLocalPath(artifact.Location)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// artifactID identifies an artifact in a PrunedEntry.
+func artifactID(artifact Artifact) string {
+	return fmt.Sprintf("%s/%s@%s", artifact.Type, artifact.Name, artifact.Version)
+}
+
+// pathExists reports whether path exists. Errors other than "not exist" count as existing,
+// so that an unreadable entry is never pruned.
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
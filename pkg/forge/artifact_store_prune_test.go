@@ -0,0 +1,216 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeSyntheticStore writes an artifact store mixing live and dead entries and returns its path.
+func writeSyntheticStore(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	liveBin := filepath.Join(dir, "build", "bin", "app")
+	liveReport := filepath.Join(dir, "report.xml")
+	liveEnv := filepath.Join(dir, "env-live")
+	for _, path := range []string{liveBin, liveReport} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(liveEnv, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	store := ArtifactStore{
+		Version: "v1",
+		Artifacts: []Artifact{
+			{Name: "app", Type: "binary", Location: liveBin, Version: "v3", Timestamp: "2024-01-03T00:00:00Z"},
+			{Name: "app", Type: "binary", Location: liveBin, Version: "v2", Timestamp: "2024-01-02T00:00:00Z"},
+			{Name: "app", Type: "binary", Location: "file://" + liveBin, Version: "v1", Timestamp: "2024-01-01T00:00:00Z"},
+			{Name: "gone", Type: "binary", Location: filepath.Join(dir, "missing"), Version: "v1", Timestamp: "2024-01-01T00:00:00Z"},
+			{Name: "img", Type: "container", Location: "img:abc", Version: "abc", Timestamp: "2024-01-01T00:00:00Z"},
+		},
+		TestEnvironments: map[string]*TestEnvironment{
+			"env-live": {ID: "env-live", TmpDir: liveEnv},
+			"env-dead": {ID: "env-dead", TmpDir: filepath.Join(dir, "env-dead")},
+			"env-orphan": {
+				ID:       "env-orphan",
+				TmpDir:   filepath.Join(dir, "env-orphan"),
+				Metadata: map[string]string{"testenv-kind.clusterName": "forge-env-orphan"},
+			},
+			"env-resources": {
+				ID:               "env-resources",
+				TmpDir:           filepath.Join(dir, "env-resources"),
+				ManagedResources: []string{filepath.Join(dir, "env-resources"), liveReport},
+			},
+		},
+		TestReports: map[string]*TestReport{
+			"report-live": {ID: "report-live", ArtifactFiles: []string{liveReport, filepath.Join(dir, "gone.xml")}},
+			"report-dead": {ID: "report-dead", ArtifactFiles: []string{filepath.Join(dir, "gone.xml")}},
+			"report-none": {ID: "report-none"},
+		},
+	}
+
+	path := filepath.Join(dir, ".forge", "artifact-store.yaml")
+	if err := WriteArtifactStore(path, store); err != nil {
+		t.Fatalf("WriteArtifactStore failed: %v", err)
+	}
+	return path
+}
+
+func prunedIDs(entries []PrunedEntry) map[string]string {
+	ids := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		ids[entry.ID] = entry.Kind
+	}
+	return ids
+}
+
+func TestPruneArtifactStore(t *testing.T) {
+	path := writeSyntheticStore(t)
+
+	result, err := PruneArtifactStore(path, PruneOptions{})
+	if err != nil {
+		t.Fatalf("PruneArtifactStore failed: %v", err)
+	}
+	if result.DryRun {
+		t.Error("expected DryRun to be false")
+	}
+
+	want := map[string]string{
+		"binary/app@v2":  StoreKindArtifact,
+		"binary/app@v1":  StoreKindArtifact,
+		"binary/gone@v1": StoreKindArtifact,
+		"env-dead":       StoreKindTestEnvironment,
+		"report-dead":    StoreKindTestReport,
+	}
+	got := prunedIDs(result.Pruned)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d pruned entries, got %d: %v", len(want), len(got), result.Pruned)
+	}
+	for id, kind := range want {
+		if got[id] != kind {
+			t.Errorf("expected %s to be pruned as %s, got %q", id, kind, got[id])
+		}
+	}
+
+	store, err := ReadArtifactStore(path)
+	if err != nil {
+		t.Fatalf("ReadArtifactStore failed: %v", err)
+	}
+	if len(store.Artifacts) != 2 {
+		t.Errorf("expected 2 artifacts left, got %d: %v", len(store.Artifacts), store.Artifacts)
+	}
+	if _, err := GetLatestArtifact(store, "app"); err != nil {
+		t.Errorf("expected latest app artifact to be kept: %v", err)
+	}
+	if _, ok := store.TestEnvironments["env-live"]; !ok {
+		t.Error("expected env-live to be kept")
+	}
+	if _, ok := store.TestEnvironments["env-dead"]; ok {
+		t.Error("expected env-dead to be removed")
+	}
+
+	// Environments whose resources may outlive their tmpDir are reported, not removed
+	wantKept := map[string]string{
+		"env-orphan":    StoreKindTestEnvironment,
+		"env-resources": StoreKindTestEnvironment,
+	}
+	if gotKept := prunedIDs(result.Kept); !reflect.DeepEqual(gotKept, wantKept) {
+		t.Errorf("expected kept entries %v, got %v", wantKept, result.Kept)
+	}
+	for id := range wantKept {
+		if _, ok := store.TestEnvironments[id]; !ok {
+			t.Errorf("expected %s to be kept", id)
+		}
+	}
+	for _, id := range []string{"report-live", "report-none"} {
+		if _, ok := store.TestReports[id]; !ok {
+			t.Errorf("expected %s to be kept", id)
+		}
+	}
+	if _, ok := store.TestReports["report-dead"]; ok {
+		t.Error("expected report-dead to be removed")
+	}
+}
+
+func TestPruneArtifactStore_DryRun(t *testing.T) {
+	path := writeSyntheticStore(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := PruneArtifactStore(path, PruneOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneArtifactStore failed: %v", err)
+	}
+	if !result.DryRun {
+		t.Error("expected DryRun to be true")
+	}
+	if len(result.Pruned) != 5 {
+		t.Errorf("expected 5 stale entries, got %d: %v", len(result.Pruned), result.Pruned)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Error("expected dry run to leave the artifact store unchanged")
+	}
+}
+
+func TestPruneArtifactStore_KeepArtifacts(t *testing.T) {
+	path := writeSyntheticStore(t)
+
+	result, err := PruneArtifactStore(path, PruneOptions{KeepArtifacts: 2})
+	if err != nil {
+		t.Fatalf("PruneArtifactStore failed: %v", err)
+	}
+
+	got := prunedIDs(result.Pruned)
+	if _, ok := got["binary/app@v2"]; ok {
+		t.Error("expected app@v2 to be kept with KeepArtifacts=2")
+	}
+	if got["binary/app@v1"] != StoreKindArtifact {
+		t.Error("expected app@v1 to be pruned with KeepArtifacts=2")
+	}
+}
+
+func TestPruneArtifactStore_MissingStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artifact-store.yaml")
+
+	result, err := PruneArtifactStore(path, PruneOptions{})
+	if err != nil {
+		t.Fatalf("PruneArtifactStore failed: %v", err)
+	}
+	if len(result.Pruned) != 0 {
+		t.Errorf("expected no pruned entries, got %v", result.Pruned)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected no artifact store to be created")
+	}
+}
@@ -0,0 +1,447 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alexandremahdhaoui/forge/pkg/flaterrors"
+
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver
+)
+
+var errOpeningArtifactStore = errors.New("opening artifact store")
+
+// sqliteStoreSchema creates the tables of the SQLite artifact store.
+// Entries are stored as JSON documents; seq preserves the insertion order of artifacts.
+const sqliteStoreSchema = `
+CREATE TABLE IF NOT EXISTS entries (
+	seq  INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,
+	id   TEXT NOT NULL,
+	data BLOB NOT NULL,
+	UNIQUE (kind, id)
+);
+CREATE TABLE IF NOT EXISTS metadata (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// sqliteStore is the Store backed by a SQLite database.
+// Reads and single-entry writes use deferred transactions. Update reads the store
+// before writing it back, so its transactions take the write lock when they begin
+// and wait for it up to artifactStoreLockTimeout.
+type sqliteStore struct {
+	db       *sql.DB
+	updateDB *sql.DB
+}
+
+// NewSQLiteStore opens, and creates if needed, the SQLite artifact store at path.
+func NewSQLiteStore(path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, flaterrors.Join(err, errOpeningArtifactStore)
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(path, "deferred"))
+	if err != nil {
+		return nil, flaterrors.Join(err, errOpeningArtifactStore)
+	}
+	if _, err := db.Exec(sqliteStoreSchema); err != nil {
+		_ = db.Close()
+		return nil, flaterrors.Join(fmt.Errorf("creating schema of %s: %w", path, err), errOpeningArtifactStore)
+	}
+
+	updateDB, err := sql.Open("sqlite", sqliteDSN(path, "immediate"))
+	if err != nil {
+		_ = db.Close()
+		return nil, flaterrors.Join(err, errOpeningArtifactStore)
+	}
+
+	return &sqliteStore{db: db, updateDB: updateDB}, nil
+}
+
+// sqliteDSN returns the data source name of the SQLite database at path, beginning
+// transactions with txlock ("deferred" or "immediate").
+func sqliteDSN(path, txlock string) string {
+	query := url.Values{}
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", artifactStoreLockTimeout.Milliseconds()))
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Set("_txlock", txlock)
+
+	dsn := url.URL{Scheme: "file", Path: path, RawQuery: query.Encode()}
+	return dsn.String()
+}
+
+// sqliteStorePath returns the path of the SQLite artifact store for the YAML artifact store path.
+func sqliteStorePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".db"
+}
+
+// readSQLiteArtifactStore is ReadArtifactStore for the SQLite backend.
+// Like for a YAML store, the error wraps os.ErrNotExist when the store does not exist.
+func readSQLiteArtifactStore(path string) (ArtifactStore, error) {
+	dbPath := sqliteStorePath(path)
+	if _, err := os.Stat(dbPath); err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+	defer func() { _ = store.Close() }()
+
+	out, err := store.Read()
+	if err != nil {
+		return ArtifactStore{}, err
+	}
+	if err := out.Validate(); err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errInvalidArtifactStore, errReadingArtifactStore)
+	}
+	return out, nil
+}
+
+func (s *sqliteStore) Get(kind, id string) (StoreEntry, error) {
+	if err := validateStoreKind(kind); err != nil {
+		return StoreEntry{}, err
+	}
+
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM entries WHERE kind = ? AND id = ?`, kind, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return StoreEntry{}, storeEntryNotFound(kind, id)
+	}
+	if err != nil {
+		return StoreEntry{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+
+	return decodeStoreEntry(kind, id, data)
+}
+
+func (s *sqliteStore) List(kind string) ([]StoreEntry, error) {
+	if err := validateStoreKind(kind); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, data FROM entries WHERE kind = ? ORDER BY id`
+	if kind == StoreKindArtifact {
+		query = `SELECT id, data FROM entries WHERE kind = ? ORDER BY seq`
+	}
+	rows, err := s.db.Query(query, kind)
+	if err != nil {
+		return nil, flaterrors.Join(err, errReadingArtifactStore)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []StoreEntry
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, flaterrors.Join(err, errReadingArtifactStore)
+		}
+		entry, err := decodeStoreEntry(kind, id, data)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, flaterrors.Join(err, errReadingArtifactStore)
+	}
+	return entries, nil
+}
+
+func (s *sqliteStore) Put(entry StoreEntry) error {
+	id, err := storeEntryID(entry)
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+
+	// Same timestamps as AddOrUpdateTestEnvironment and AddOrUpdateTestReport
+	now := time.Now().UTC()
+	switch entry.Kind {
+	case StoreKindTestEnvironment:
+		entry.TestEnvironment.UpdatedAt = now
+	case StoreKindTestReport:
+		if entry.TestReport.CreatedAt.IsZero() {
+			entry.TestReport.CreatedAt = now
+		}
+		entry.TestReport.UpdatedAt = now
+	}
+
+	return s.inTx(s.db, func(tx *sql.Tx) error {
+		if err := putSQLiteEntry(tx, entry); err != nil {
+			return err
+		}
+		if entry.Kind == StoreKindArtifact {
+			if err := pruneSQLiteArtifacts(tx, *entry.Artifact); err != nil {
+				return err
+			}
+		}
+		return setSQLiteLastUpdated(tx, now)
+	})
+}
+
+func (s *sqliteStore) Delete(kind, id string) error {
+	if err := validateStoreKind(kind); err != nil {
+		return err
+	}
+
+	return s.inTx(s.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec(`DELETE FROM entries WHERE kind = ? AND id = ?`, kind, id)
+		if err != nil {
+			return flaterrors.Join(err, errWritingArtifactStore)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return flaterrors.Join(err, errWritingArtifactStore)
+		} else if n == 0 {
+			return storeEntryNotFound(kind, id)
+		}
+		return setSQLiteLastUpdated(tx, time.Now().UTC())
+	})
+}
+
+func (s *sqliteStore) Read() (ArtifactStore, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return readSQLiteStore(tx)
+}
+
+func (s *sqliteStore) Update(update func(store *ArtifactStore) error) error {
+	return s.inTx(s.updateDB, func(tx *sql.Tx) error {
+		store, err := readSQLiteStore(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := update(&store); err != nil {
+			return err
+		}
+		PruneBuildArtifacts(&store, artifactStoreKeepArtifacts)
+
+		// Replace all entries: the store is small, and the transaction makes it atomic
+		if _, err := tx.Exec(`DELETE FROM entries`); err != nil {
+			return flaterrors.Join(err, errWritingArtifactStore)
+		}
+		for _, artifact := range store.Artifacts {
+			if err := putSQLiteEntry(tx, StoreEntry{Kind: StoreKindArtifact, ID: artifactID(artifact), Artifact: &artifact}); err != nil {
+				return err
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(store.TestEnvironments)) {
+			entry := StoreEntry{Kind: StoreKindTestEnvironment, ID: id, TestEnvironment: store.TestEnvironments[id]}
+			if err := putSQLiteEntry(tx, entry); err != nil {
+				return err
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(store.TestReports)) {
+			entry := StoreEntry{Kind: StoreKindTestReport, ID: id, TestReport: store.TestReports[id]}
+			if err := putSQLiteEntry(tx, entry); err != nil {
+				return err
+			}
+		}
+
+		lastUpdated := store.LastUpdated
+		if lastUpdated.IsZero() {
+			lastUpdated = time.Now().UTC()
+		}
+		return setSQLiteLastUpdated(tx, lastUpdated)
+	})
+}
+
+func (s *sqliteStore) Close() error {
+	return flaterrors.Join(s.db.Close(), s.updateDB.Close())
+}
+
+// inTx runs fn in a transaction of db, committed if fn succeeds and rolled back otherwise.
+func (s *sqliteStore) inTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	defer func() { _ = tx.Rollback() }() // No-op once committed
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	return nil
+}
+
+// readSQLiteStore reads all the entries of the SQLite artifact store into an ArtifactStore.
+func readSQLiteStore(tx *sql.Tx) (ArtifactStore, error) {
+	store := newArtifactStore()
+
+	var lastUpdated string
+	err := tx.QueryRow(`SELECT value FROM metadata WHERE key = 'lastUpdated'`).Scan(&lastUpdated)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+	if lastUpdated != "" {
+		if store.LastUpdated, err = time.Parse(time.RFC3339Nano, lastUpdated); err != nil {
+			return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+		}
+	}
+
+	rows, err := tx.Query(`SELECT kind, id, data FROM entries ORDER BY seq`)
+	if err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var kind, id string
+		var data []byte
+		if err := rows.Scan(&kind, &id, &data); err != nil {
+			return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+		}
+		entry, err := decodeStoreEntry(kind, id, data)
+		if err != nil {
+			return ArtifactStore{}, err
+		}
+		switch kind {
+		case StoreKindArtifact:
+			store.Artifacts = append(store.Artifacts, *entry.Artifact)
+		case StoreKindTestEnvironment:
+			store.TestEnvironments[id] = entry.TestEnvironment
+		case StoreKindTestReport:
+			store.TestReports[id] = entry.TestReport
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return ArtifactStore{}, flaterrors.Join(err, errReadingArtifactStore)
+	}
+
+	return store, nil
+}
+
+// putSQLiteEntry inserts entry, or replaces the data of the entry of the same kind and ID.
+func putSQLiteEntry(tx *sql.Tx, entry StoreEntry) error {
+	var value any
+	switch entry.Kind {
+	case StoreKindArtifact:
+		value = entry.Artifact
+	case StoreKindTestEnvironment:
+		value = entry.TestEnvironment
+	case StoreKindTestReport:
+		value = entry.TestReport
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+
+	_, err = tx.Exec(`INSERT INTO entries (kind, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (kind, id) DO UPDATE SET data = excluded.data`, entry.Kind, entry.ID, data)
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	return nil
+}
+
+// pruneSQLiteArtifacts deletes the artifacts of the same type and name as artifact beyond the
+// artifactStoreKeepArtifacts most recent ones.
+func pruneSQLiteArtifacts(tx *sql.Tx, artifact Artifact) error {
+	rows, err := tx.Query(`SELECT id, data FROM entries WHERE kind = ?`, StoreKindArtifact)
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+
+	var group []Artifact
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			_ = rows.Close()
+			return flaterrors.Join(err, errWritingArtifactStore)
+		}
+		entry, err := decodeStoreEntry(StoreKindArtifact, id, data)
+		if err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if entry.Artifact.Type == artifact.Type && entry.Artifact.Name == artifact.Name {
+			group = append(group, *entry.Artifact)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+
+	if len(group) <= artifactStoreKeepArtifacts {
+		return nil
+	}
+	sortArtifactsNewestFirst(group)
+	for _, stale := range group[artifactStoreKeepArtifacts:] {
+		if _, err := tx.Exec(`DELETE FROM entries WHERE kind = ? AND id = ?`, StoreKindArtifact, artifactID(stale)); err != nil {
+			return flaterrors.Join(err, errWritingArtifactStore)
+		}
+	}
+	return nil
+}
+
+// setSQLiteLastUpdated records when the SQLite artifact store was last updated.
+func setSQLiteLastUpdated(tx *sql.Tx, lastUpdated time.Time) error {
+	_, err := tx.Exec(`INSERT INTO metadata (key, value) VALUES ('lastUpdated', ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, lastUpdated.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return flaterrors.Join(err, errWritingArtifactStore)
+	}
+	return nil
+}
+
+// decodeStoreEntry decodes the JSON data of the entry of kind with id.
+func decodeStoreEntry(kind, id string, data []byte) (StoreEntry, error) {
+	entry := StoreEntry{Kind: kind, ID: id}
+
+	var err error
+	switch kind {
+	case StoreKindArtifact:
+		entry.Artifact = &Artifact{}
+		err = json.Unmarshal(data, entry.Artifact)
+	case StoreKindTestEnvironment:
+		entry.TestEnvironment = &TestEnvironment{}
+		err = json.Unmarshal(data, entry.TestEnvironment)
+	case StoreKindTestReport:
+		entry.TestReport = &TestReport{}
+		err = json.Unmarshal(data, entry.TestReport)
+	default:
+		return StoreEntry{}, flaterrors.Join(fmt.Errorf("kind %q", kind), errUnknownStoreKind, errReadingArtifactStore)
+	}
+	if err != nil {
+		return StoreEntry{}, flaterrors.Join(fmt.Errorf("decoding %s %s: %w", kind, id, err), errReadingArtifactStore)
+	}
+	return entry, nil
+}
@@ -17,8 +17,12 @@
 package forge
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -295,8 +299,8 @@ func TestArtifactStoreReadWrite(t *testing.T) {
 	}
 
 	// Verify version
-	if readStore.Version != "1.0" {
-		t.Errorf("Expected version 1.0, got %s", readStore.Version)
+	if readStore.Version != artifactStoreVersion {
+		t.Errorf("Expected version %s, got %s", artifactStoreVersion, readStore.Version)
 	}
 
 	// Verify artifacts
@@ -363,8 +367,8 @@ func TestReadOrCreateArtifactStore_NonexistentFile(t *testing.T) {
 	}
 
 	// Verify empty store is initialized properly
-	if store.Version != "1.0" {
-		t.Errorf("Expected version 1.0, got %s", store.Version)
+	if store.Version != artifactStoreVersion {
+		t.Errorf("Expected version %s, got %s", artifactStoreVersion, store.Version)
 	}
 
 	if store.Artifacts == nil {
@@ -434,6 +438,9 @@ func TestTestEnvironmentStatusConstants(t *testing.T) {
 	if TestStatusPartiallyDeleted != "partially_deleted" {
 		t.Errorf("Expected TestStatusPartiallyDeleted to be 'partially_deleted', got %s", TestStatusPartiallyDeleted)
 	}
+	if TestStatusPartiallyCreated != "partially_created" {
+		t.Errorf("Expected TestStatusPartiallyCreated to be 'partially_created', got %s", TestStatusPartiallyCreated)
+	}
 }
 
 func TestPruneBuildArtifacts_KeepsThreeMostRecent(t *testing.T) {
@@ -1167,3 +1174,163 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestArtifactStore_ConcurrentWritesLoseNothing(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), ".forge", "artifact-store.yaml")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writers)
+	for i := range writers {
+		wg.Add(2)
+
+		// Read-modify-write through WriteArtifactStore, which merges with the store on disk
+		go func() {
+			defer wg.Done()
+			store, err := ReadOrCreateArtifactStore(storePath)
+			if err != nil {
+				errs <- err
+				return
+			}
+			AddOrUpdateArtifact(&store, Artifact{
+				Name:      fmt.Sprintf("app-%d", i),
+				Type:      "binary",
+				Location:  "./build/bin/app",
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Version:   "v1",
+			})
+			errs <- WriteArtifactStore(storePath, store)
+		}()
+
+		// Read-modify-write under the lock with UpdateArtifactStore
+		go func() {
+			defer wg.Done()
+			errs <- UpdateArtifactStore(storePath, func(store *ArtifactStore) error {
+				AddOrUpdateTestReport(store, &TestReport{
+					ID:        fmt.Sprintf("report-%d", i),
+					Stage:     "unit",
+					Status:    "passed",
+					StartTime: time.Now().UTC(),
+				})
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent write failed: %v", err)
+		}
+	}
+
+	store, err := ReadArtifactStore(storePath)
+	if err != nil {
+		t.Fatalf("failed to read artifact store: %v", err)
+	}
+	if len(store.Artifacts) != writers {
+		t.Errorf("expected %d artifacts, got %d", writers, len(store.Artifacts))
+	}
+	if len(store.TestReports) != writers {
+		t.Errorf("expected %d test reports, got %d", writers, len(store.TestReports))
+	}
+
+	// Atomic writes leave no temporary files behind
+	entries, err := os.ReadDir(filepath.Dir(storePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("unexpected temporary file left behind: %s", entry.Name())
+		}
+	}
+}
+
+func TestUpdateArtifactStore_ErrorWritesNothing(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "artifact-store.yaml")
+	wantErr := errors.New("update failed")
+
+	err := UpdateArtifactStore(storePath, func(store *ArtifactStore) error {
+		AddOrUpdateTestReport(store, &TestReport{ID: "report-1"})
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if _, err := os.Stat(storePath); !os.IsNotExist(err) {
+		t.Errorf("expected no artifact store to be written, got %v", err)
+	}
+}
+
+func TestLockArtifactStore_Timeout(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "artifact-store.yaml")
+
+	saved := artifactStoreLockTimeout
+	artifactStoreLockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { artifactStoreLockTimeout = saved })
+
+	held, err := lockArtifactStore(storePath)
+	if err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+
+	start := time.Now()
+	err = WriteArtifactStore(storePath, ArtifactStore{})
+	if !errors.Is(err, errArtifactStoreLockTimeout) {
+		t.Fatalf("expected a lock timeout error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), storePath+".lock") {
+		t.Errorf("expected the error to name the lock file, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the write to give up after the timeout, took %s", elapsed)
+	}
+
+	// Once released, the lock can be taken again
+	if err := unlockArtifactStore(held); err != nil {
+		t.Fatalf("failed to release the lock: %v", err)
+	}
+	if err := WriteArtifactStore(storePath, ArtifactStore{}); err != nil {
+		t.Errorf("expected the write to succeed after the lock was released, got %v", err)
+	}
+}
+
+func TestArtifactRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		artifact Artifact
+		want     ArtifactRef
+		wantOK   bool
+	}{
+		{
+			name:     "binary",
+			artifact: Artifact{Name: "app", Type: "binary", Location: "./build/bin/app", Checksum: "sha256:abc"},
+			want:     ArtifactRef{Name: "app", Path: "./build/bin/app", Checksum: "sha256:abc"},
+			wantOK:   true,
+		},
+		{
+			name:     "file URL",
+			artifact: Artifact{Name: "app", Type: "binary", Location: "file:///tmp/app"},
+			want:     ArtifactRef{Name: "app", Path: "/tmp/app"},
+			wantOK:   true,
+		},
+		{
+			name:     "container image",
+			artifact: Artifact{Name: "app", Type: "container", Location: "app:abc123"},
+		},
+		{
+			name:     "remote location",
+			artifact: Artifact{Name: "chart", Type: "helm-chart", Location: "oci://registry/chart"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.artifact.Ref()
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Ref() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -43,6 +43,8 @@ type BuildSpec struct {
 	//   - dependsOn: []DependsOnSpec - list of dependency detectors to run
 	// The exact fields supported depend on the engine being used
 	Spec map[string]interface{} `json:"spec,omitempty"`
+	// Labels are passed to the engine and recorded on the built artifacts
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // DependsOnSpec defines a dependency detector configuration
@@ -113,6 +113,12 @@ type TestenvEngineSpec struct {
 	// This allows sub-engines to handle their own template expansion.
 	// Default is false (templates are expanded by the orchestrator).
 	DeferTemplates bool `json:"deferTemplates,omitempty" yaml:"deferTemplates,omitempty"`
+
+	// DependsOn lists the engine URIs of other subengines in the same testenv that must be
+	// created before this one (e.g., ["go://testenv-kind"]).
+	// When any subengine declares dependencies, independent subengines are created concurrently
+	// and teardown runs in reverse dependency order. When none do, subengines run sequentially.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
 }
 
 // DependencyDetectorEngineSpec defines specification for dependency-detector-type engines
@@ -194,10 +200,21 @@ func (ec *EngineConfig) Validate() error {
 			errs.AddErrorf("EngineConfig %q: type=testenv requires at least one testenv specification", ec.Alias)
 		}
 		// Validate each testenv spec
+		engines := make(map[string]bool, testenvCount)
+		for _, te := range ec.Testenv {
+			engines[te.Engine] = true
+		}
 		for i, te := range ec.Testenv {
 			if err := te.Validate(ec.Alias, i); err != nil {
 				errs.Add(err)
 			}
+			for _, dep := range te.DependsOn {
+				if dep == te.Engine {
+					errs.AddErrorf("EngineConfig %q testenv[%d]: subengine cannot depend on itself: %s", ec.Alias, i, dep)
+				} else if !engines[dep] {
+					errs.AddErrorf("EngineConfig %q testenv[%d]: dependsOn references unknown subengine: %s", ec.Alias, i, dep)
+				}
+			}
 		}
 
 	case DependencyDetectorEngineConfigType:
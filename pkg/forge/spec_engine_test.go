@@ -17,6 +17,7 @@
 package forge
 
 import (
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/yaml"
@@ -609,3 +610,70 @@ engines:
 		})
 	}
 }
+
+func TestTestenvEngineSpecDependsOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid dependencies",
+			yaml: `
+alias: my-testenv
+type: testenv
+testenv:
+  - engine: go://testenv-kind
+  - engine: go://testenv-lcr
+    dependsOn: ["go://testenv-kind"]
+  - engine: go://testenv-helm-install
+    dependsOn: ["go://testenv-kind"]
+`,
+		},
+		{
+			name: "unknown dependency",
+			yaml: `
+alias: my-testenv
+type: testenv
+testenv:
+  - engine: go://testenv-helm-install
+    dependsOn: ["go://testenv-kind"]
+`,
+			wantErr: "dependsOn references unknown subengine: go://testenv-kind",
+		},
+		{
+			name: "self dependency",
+			yaml: `
+alias: my-testenv
+type: testenv
+testenv:
+  - engine: go://testenv-kind
+    dependsOn: ["go://testenv-kind"]
+`,
+			wantErr: "subengine cannot depend on itself: go://testenv-kind",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ec EngineConfig
+			if err := yaml.Unmarshal([]byte(tt.yaml), &ec); err != nil {
+				t.Fatalf("Failed to unmarshal YAML: %v", err)
+			}
+
+			err := ec.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if len(ec.Testenv[1].DependsOn) != 1 {
+					t.Errorf("Expected dependsOn to be parsed, got %v", ec.Testenv[1].DependsOn)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
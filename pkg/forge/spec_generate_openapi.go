@@ -28,6 +28,9 @@ type GenOpts struct {
 	Enabled bool `json:"enabled"`
 	// PackageName is the name of the package for the generated code.
 	PackageName string `json:"packageName"`
+	// ConfigOverride is merged into the oapi-codegen configuration of this package.
+	// Its keys win over the generated ones.
+	ConfigOverride map[string]interface{} `json:"configOverride,omitempty"`
 }
 
 // GenerateOpenAPISpec holds the configuration for a single OpenAPI specification.
@@ -40,6 +40,9 @@ type TestSpec struct {
 	// Optional filtering applied at test runner level (whitelist/blacklist)
 	// Note: This is for test runner filtering only - testenv sub-engine EnvPropagation is separate
 	EnvPropagation *EnvPropagation `json:"envPropagation,omitempty"`
+
+	// Labels are passed to the runner and recorded on the test reports of this stage
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // Validate validates the TestSpec
@@ -114,4 +117,5 @@ const (
 	TestStatusPassed           = "passed"
 	TestStatusFailed           = "failed"
 	TestStatusPartiallyDeleted = "partially_deleted"
+	TestStatusPartiallyCreated = "partially_created"
 )
@@ -0,0 +1,120 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"slices"
+	"strings"
+)
+
+// TestCaseStatusSkipped is the status of a test case that was skipped.
+const TestCaseStatusSkipped = "skipped"
+
+// MergeReports combines several test reports into a single report.
+//
+// Stats and durations are summed, cases and artifact files are concatenated and
+// the overall status is the worst status among the reports ("failed" wins over any
+// other status, and any other status wins over "passed").
+// Distinct stages are joined with a comma, the start time is the earliest one and
+// error messages are joined with "; ". Coverage is the average of the reports
+// that computed it.
+//
+// Nil reports are ignored. MergeReports returns nil if no report is given.
+// The merged report has no ID, CreatedAt or UpdatedAt: callers set them when storing it.
+func MergeReports(reports []*TestReport) *TestReport {
+	var merged *TestReport
+	var stages, errorMessages, outputPaths []string
+	var coverageSum float64
+	var coverageCount int
+	var coverageFiles []string
+
+	for _, r := range reports {
+		if r == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &TestReport{Status: r.Status, StartTime: r.StartTime}
+		}
+
+		if r.Stage != "" && !slices.Contains(stages, r.Stage) {
+			stages = append(stages, r.Stage)
+		}
+		if testStatusSeverity(r.Status) > testStatusSeverity(merged.Status) {
+			merged.Status = r.Status
+		}
+		if !r.StartTime.IsZero() && (merged.StartTime.IsZero() || r.StartTime.Before(merged.StartTime)) {
+			merged.StartTime = r.StartTime
+		}
+		merged.Duration += r.Duration
+
+		merged.TestStats.Total += r.TestStats.Total
+		merged.TestStats.Passed += r.TestStats.Passed
+		merged.TestStats.Failed += r.TestStats.Failed
+		merged.TestStats.Skipped += r.TestStats.Skipped
+
+		if r.Coverage.Enabled {
+			coverageSum += r.Coverage.Percentage
+			coverageCount++
+			if r.Coverage.FilePath != "" {
+				coverageFiles = append(coverageFiles, r.Coverage.FilePath)
+			}
+		}
+
+		merged.Cases = append(merged.Cases, r.Cases...)
+		merged.ArtifactFiles = append(merged.ArtifactFiles, r.ArtifactFiles...)
+
+		if r.OutputPath != "" && !slices.Contains(outputPaths, r.OutputPath) {
+			outputPaths = append(outputPaths, r.OutputPath)
+		}
+		if r.ErrorMessage != "" {
+			errorMessages = append(errorMessages, r.ErrorMessage)
+		}
+	}
+
+	if merged == nil {
+		return nil
+	}
+
+	merged.Stage = strings.Join(stages, ",")
+	merged.ErrorMessage = strings.Join(errorMessages, "; ")
+
+	// A single output path or coverage file is kept; several cannot be represented
+	if len(outputPaths) == 1 {
+		merged.OutputPath = outputPaths[0]
+	}
+	if coverageCount > 0 {
+		merged.Coverage.Enabled = true
+		merged.Coverage.Percentage = coverageSum / float64(coverageCount)
+		if len(coverageFiles) == 1 {
+			merged.Coverage.FilePath = coverageFiles[0]
+		}
+	}
+
+	return merged
+}
+
+// testStatusSeverity ranks a test status so that the worst status of several reports can be picked.
+func testStatusSeverity(status string) int {
+	switch status {
+	case "", TestStatusPassed:
+		return 0
+	case TestCaseStatusSkipped:
+		return 1
+	case TestStatusFailed:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,149 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeReports_MixedStatus(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	unit := &TestReport{
+		ID:        "unit-1",
+		Stage:     "unit",
+		Status:    TestStatusPassed,
+		StartTime: start.Add(time.Minute),
+		Duration:  1.5,
+		TestStats: TestStats{Total: 2, Passed: 1, Skipped: 1},
+		Coverage:  Coverage{Enabled: true, Percentage: 80, FilePath: "unit.out"},
+		Cases: []TestCase{
+			{Name: "TestA", Status: TestStatusPassed, Duration: 1},
+			{Name: "TestB", Status: TestCaseStatusSkipped, Duration: 0.5, Message: "short mode"},
+		},
+		ArtifactFiles: []string{"unit.xml"},
+	}
+	lint := &TestReport{
+		Stage:     "lint",
+		Status:    TestStatusPassed,
+		StartTime: start,
+		Duration:  2,
+		TestStats: TestStats{Total: 1, Passed: 1},
+	}
+	integration := &TestReport{
+		Stage:        "integration",
+		Status:       TestStatusFailed,
+		StartTime:    start.Add(2 * time.Minute),
+		Duration:     3,
+		TestStats:    TestStats{Total: 1, Failed: 1},
+		Coverage:     Coverage{Enabled: true, Percentage: 60, FilePath: "integration.out"},
+		Cases:        []TestCase{{Name: "TestC", Status: TestStatusFailed, Duration: 3, Message: "boom"}},
+		ErrorMessage: "1 test failed",
+	}
+
+	merged := MergeReports([]*TestReport{unit, nil, lint, integration})
+	if merged == nil {
+		t.Fatal("MergeReports() returned nil")
+	}
+
+	if merged.Status != TestStatusFailed {
+		t.Errorf("Status = %q, want %q", merged.Status, TestStatusFailed)
+	}
+	if merged.Stage != "unit,lint,integration" {
+		t.Errorf("Stage = %q, want %q", merged.Stage, "unit,lint,integration")
+	}
+	if !merged.StartTime.Equal(start) {
+		t.Errorf("StartTime = %v, want %v", merged.StartTime, start)
+	}
+	if merged.Duration != 6.5 {
+		t.Errorf("Duration = %v, want 6.5", merged.Duration)
+	}
+	wantStats := TestStats{Total: 4, Passed: 2, Failed: 1, Skipped: 1}
+	if merged.TestStats != wantStats {
+		t.Errorf("TestStats = %+v, want %+v", merged.TestStats, wantStats)
+	}
+	wantCoverage := Coverage{Enabled: true, Percentage: 70}
+	if merged.Coverage != wantCoverage {
+		t.Errorf("Coverage = %+v, want %+v", merged.Coverage, wantCoverage)
+	}
+	wantCases := []TestCase{unit.Cases[0], unit.Cases[1], integration.Cases[0]}
+	if !reflect.DeepEqual(merged.Cases, wantCases) {
+		t.Errorf("Cases = %+v, want %+v", merged.Cases, wantCases)
+	}
+	if !reflect.DeepEqual(merged.ArtifactFiles, []string{"unit.xml"}) {
+		t.Errorf("ArtifactFiles = %v, want [unit.xml]", merged.ArtifactFiles)
+	}
+	if merged.ErrorMessage != "1 test failed" {
+		t.Errorf("ErrorMessage = %q, want %q", merged.ErrorMessage, "1 test failed")
+	}
+	if merged.ID != "" {
+		t.Errorf("ID = %q, want empty", merged.ID)
+	}
+}
+
+func TestMergeReports_WorstStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{name: "all passed", statuses: []string{TestStatusPassed, TestStatusPassed}, want: TestStatusPassed},
+		{name: "skipped over passed", statuses: []string{TestStatusPassed, TestCaseStatusSkipped}, want: TestCaseStatusSkipped},
+		{name: "unknown over skipped", statuses: []string{TestCaseStatusSkipped, "error", TestStatusPassed}, want: "error"},
+		{name: "failed over everything", statuses: []string{"error", TestStatusFailed, TestStatusPassed}, want: TestStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []*TestReport
+			for _, status := range tt.statuses {
+				reports = append(reports, &TestReport{Status: status})
+			}
+			if got := MergeReports(reports).Status; got != tt.want {
+				t.Errorf("MergeReports().Status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeReports_AggregateOnly(t *testing.T) {
+	merged := MergeReports([]*TestReport{
+		{Stage: "unit", Status: TestStatusPassed, TestStats: TestStats{Total: 3, Passed: 3}, OutputPath: "out"},
+		{Stage: "unit", Status: TestStatusPassed, TestStats: TestStats{Total: 2, Passed: 2}, OutputPath: "out"},
+	})
+
+	if merged.Cases != nil {
+		t.Errorf("Cases = %+v, want nil", merged.Cases)
+	}
+	if merged.Stage != "unit" || merged.OutputPath != "out" {
+		t.Errorf("Stage = %q, OutputPath = %q, want unit and out", merged.Stage, merged.OutputPath)
+	}
+	if merged.TestStats.Total != 5 || merged.Coverage.Enabled {
+		t.Errorf("unexpected merged report %+v", merged)
+	}
+}
+
+func TestMergeReports_Empty(t *testing.T) {
+	if got := MergeReports(nil); got != nil {
+		t.Errorf("MergeReports(nil) = %+v, want nil", got)
+	}
+	if got := MergeReports([]*TestReport{nil}); got != nil {
+		t.Errorf("MergeReports([nil]) = %+v, want nil", got)
+	}
+}
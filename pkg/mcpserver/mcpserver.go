@@ -17,18 +17,28 @@ package mcpserver
 import (
 	"context"
 	"log"
+	"sync"
 
+	"github.com/alexandremahdhaoui/forge/pkg/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // Server wraps the MCP server with common functionality.
 type Server struct {
 	server *mcp.Server
+	name   string
+
+	mu              sync.Mutex
+	protocolVersion string
+	middlewares     []Middleware
+	tools           map[string]ToolInfo
 }
 
 // New creates a new MCP server with the given name and version.
+// The Timing middleware is installed by default.
 func New(name, version string) *Server {
-	server := mcp.NewServer(&mcp.Implementation{
+	s := &Server{name: name}
+	s.server = mcp.NewServer(&mcp.Implementation{
 		Name:    name,
 		Version: version,
 	}, &mcp.ServerOptions{
@@ -37,24 +47,64 @@ func New(name, version string) *Server {
 				ListChanged: false,
 			},
 		},
+		InitializedHandler: func(_ context.Context, req *mcp.InitializedRequest) {
+			s.recordProtocolVersion(req.Session.InitializeParams())
+		},
 	})
+	s.Use(Timing())
+
+	return s
+}
+
+// ProtocolVersion returns the MCP protocol version negotiated with the client.
+// It is empty until the client has completed initialization.
+func (s *Server) ProtocolVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.protocolVersion
+}
 
-	return &Server{
-		server: server,
+// recordProtocolVersion stores the version negotiated for the client's initialize parameters.
+func (s *Server) recordProtocolVersion(params *mcp.InitializeParams) {
+	if params == nil {
+		return
 	}
+	version := NegotiateProtocolVersion(params.ProtocolVersion)
+	if err := CheckProtocolCompatibility(params.ProtocolVersion, version); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protocolVersion = version
 }
 
 // RegisterTool registers a tool with the MCP server.
 // The handler must be a function with signature:
 // func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error)
+// Calls go through the server's middlewares (see Use) before reaching the handler.
+// Registered tools can be enumerated with RegisteredTools.
 func RegisterTool[In any](s *Server, tool *mcp.Tool, handler func(context.Context, *mcp.CallToolRequest, In) (*mcp.CallToolResult, any, error)) {
-	mcp.AddTool(s.server, tool, handler)
+	mcp.AddTool(s.server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		call := ToolCall{Name: tool.Name, Request: req, Input: input}
+		return s.chain(func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error) {
+			return handler(ctx, call.Request, input)
+		})(ctx, call)
+	})
+	recordTool[In](s, tool)
 }
 
 // Run starts the MCP server with stdio transport.
 // It reads JSON-RPC requests from stdin and writes responses to stdout.
 // All logs should go to stderr only to avoid corrupting the JSON-RPC stream.
+// Tracing is set up for the server's engine name when an OTLP endpoint is configured.
 func (s *Server) Run(ctx context.Context) error {
+	shutdownTracing, err := tracing.Setup(ctx, s.name)
+	if err != nil {
+		log.Printf("Warning: tracing disabled: %v", err)
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
+
 	if err := s.server.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		log.Printf("MCP server failed: %v", err)
 		return err
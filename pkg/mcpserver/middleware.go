@@ -0,0 +1,83 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpserver
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolCall describes a tool invocation as seen by middlewares.
+type ToolCall struct {
+	// Name is the name of the called tool.
+	Name string
+	// Request is the raw MCP request.
+	Request *mcp.CallToolRequest
+	// Input is the decoded tool input, of the type the tool was registered with.
+	Input any
+}
+
+// ToolHandler handles a tool call.
+type ToolHandler func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error)
+
+// Middleware wraps a ToolHandler to run cross-cutting concerns around tool calls.
+// A middleware may short-circuit the call by returning without calling next.
+type Middleware func(next ToolHandler) ToolHandler
+
+// Use appends middlewares to the server. Middlewares apply to every tool,
+// including tools registered before Use, and run outer-to-inner in the order
+// they were added: the first middleware sees the call first and the result last.
+func (s *Server) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+// chain wraps handler with the server's middlewares.
+func (s *Server) chain(handler ToolHandler) ToolHandler {
+	s.mu.Lock()
+	middlewares := append([]Middleware(nil), s.middlewares...)
+	s.mu.Unlock()
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Timing returns a middleware logging each tool call with its duration and outcome.
+// A call fails when the handler returns an error or an error result.
+func Timing() Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error) {
+			start := time.Now()
+			result, out, err := next(ctx, call)
+			duration := time.Since(start).Round(time.Millisecond)
+
+			switch {
+			case err != nil:
+				log.Printf("Tool %s failed after %s: %v", call.Name, duration, err)
+			case result != nil && result.IsError:
+				log.Printf("Tool %s failed after %s", call.Name, duration)
+			default:
+				log.Printf("Tool %s succeeded in %s", call.Name, duration)
+			}
+			return result, out, err
+		}
+	}
+}
@@ -0,0 +1,189 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type echoInput struct {
+	Message string `json:"message"`
+}
+
+// serveInMemory connects a client to s over in-memory transports.
+func serveInMemory(t *testing.T, s *Server) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	return session
+}
+
+// registerEcho registers an "echo" tool recording its invocations in events.
+func registerEcho(s *Server, events *[]string, mu *sync.Mutex) {
+	RegisterTool(s, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, input echoInput) (*mcp.CallToolResult, any, error) {
+		mu.Lock()
+		*events = append(*events, "handler")
+		mu.Unlock()
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: input.Message}}}, nil, nil
+	})
+}
+
+// recording returns a middleware appending its name to events around the call.
+func recording(name string, events *[]string, mu *sync.Mutex) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error) {
+			mu.Lock()
+			*events = append(*events, name+" before")
+			mu.Unlock()
+			result, out, err := next(ctx, call)
+			mu.Lock()
+			*events = append(*events, name+" after")
+			mu.Unlock()
+			return result, out, err
+		}
+	}
+}
+
+func callEcho(t *testing.T, session *mcp.ClientSession) *mcp.CallToolResult {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"message": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	return result
+}
+
+func TestUse_MiddlewareObservesCall(t *testing.T) {
+	s := New("test-engine", "1.0.0")
+	var mu sync.Mutex
+	var events []string
+	registerEcho(s, &events, &mu)
+
+	var observed ToolCall
+	s.Use(func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error) {
+			observed = call
+			return next(ctx, call)
+		}
+	})
+
+	result := callEcho(t, serveInMemory(t, s))
+
+	if result.IsError {
+		t.Fatalf("call failed: %v", result.Content)
+	}
+	if observed.Name != "echo" {
+		t.Errorf("observed tool name = %q, want %q", observed.Name, "echo")
+	}
+	if input, ok := observed.Input.(echoInput); !ok || input.Message != "hello" {
+		t.Errorf("observed input = %#v, want echoInput{Message: \"hello\"}", observed.Input)
+	}
+	if observed.Request == nil {
+		t.Error("observed request is nil")
+	}
+}
+
+func TestUse_RunsOuterToInner(t *testing.T) {
+	s := New("test-engine", "1.0.0")
+	var mu sync.Mutex
+	var events []string
+	registerEcho(s, &events, &mu)
+	s.Use(recording("first", &events, &mu), recording("second", &events, &mu))
+	s.Use(recording("third", &events, &mu))
+
+	callEcho(t, serveInMemory(t, s))
+
+	want := []string{
+		"first before", "second before", "third before",
+		"handler",
+		"third after", "second after", "first after",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestUse_MiddlewareCanShortCircuit(t *testing.T) {
+	s := New("test-engine", "1.0.0")
+	var mu sync.Mutex
+	var events []string
+	registerEcho(s, &events, &mu)
+	s.Use(func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "unauthorized"}},
+				IsError: true,
+			}, nil, nil
+		}
+	})
+
+	result := callEcho(t, serveInMemory(t, s))
+
+	if !result.IsError {
+		t.Error("expected an error result")
+	}
+	if len(events) != 0 {
+		t.Errorf("handler ran despite short-circuit: %v", events)
+	}
+}
+
+func TestTiming_LogsOutcome(t *testing.T) {
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	handler := Timing()(func(ctx context.Context, call ToolCall) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{IsError: call.Name == "failing"}, nil, nil
+	})
+
+	_, _, _ = handler(context.Background(), ToolCall{Name: "build"})
+	_, _, _ = handler(context.Background(), ToolCall{Name: "failing"})
+
+	output := buf.String()
+	if !strings.Contains(output, "Tool build succeeded in ") {
+		t.Errorf("log output = %q, want the successful call", output)
+	}
+	if !strings.Contains(output, "Tool failing failed after ") {
+		t.Errorf("log output = %q, want the failed call", output)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// LatestProtocolVersion is the MCP protocol version declared by forge engines.
+// It is the version negotiated when a client requests a version the server does not support.
+const LatestProtocolVersion = "2025-06-18"
+
+// SupportedProtocolVersions lists the MCP protocol versions the server accepts from clients.
+// It mirrors the versions supported by the MCP go-sdk in use, newest first.
+var SupportedProtocolVersions = []string{
+	"2025-11-25",
+	"2025-06-18",
+	"2025-03-26",
+	"2024-11-05",
+}
+
+// NegotiateProtocolVersion returns the protocol version the server uses for a client
+// requesting clientVersion: the client's version when supported, LatestProtocolVersion otherwise.
+func NegotiateProtocolVersion(clientVersion string) string {
+	if slices.Contains(SupportedProtocolVersions, clientVersion) {
+		return clientVersion
+	}
+	return LatestProtocolVersion
+}
+
+// CheckProtocolCompatibility returns an error when the version negotiated by the server
+// differs from the version requested by the client, i.e. when the client would be
+// talking a protocol version it did not ask for.
+func CheckProtocolCompatibility(clientVersion, negotiatedVersion string) error {
+	if clientVersion == negotiatedVersion {
+		return nil
+	}
+	return fmt.Errorf("MCP protocol version mismatch: client requested %q, server negotiated %q (server supports: %s)",
+		clientVersion, negotiatedVersion, strings.Join(SupportedProtocolVersions, ", "))
+}
+
+// ProtocolVersionFromInitializeResponse extracts the negotiated protocol version from a
+// raw JSON-RPC response to an "initialize" request.
+func ProtocolVersionFromInitializeResponse(data []byte) (string, error) {
+	var response struct {
+		Result *struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("failed to parse initialize response: %w", err)
+	}
+	if response.Error != nil {
+		return "", fmt.Errorf("initialize failed: %s (code %d)", response.Error.Message, response.Error.Code)
+	}
+	if response.Result == nil || response.Result.ProtocolVersion == "" {
+		return "", fmt.Errorf("initialize response has no protocolVersion")
+	}
+	return response.Result.ProtocolVersion, nil
+}
@@ -0,0 +1,151 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	for _, version := range SupportedProtocolVersions {
+		if got := NegotiateProtocolVersion(version); got != version {
+			t.Errorf("NegotiateProtocolVersion(%q) = %q, want the client version", version, got)
+		}
+	}
+
+	if got := NegotiateProtocolVersion("0.1.0"); got != LatestProtocolVersion {
+		t.Errorf("NegotiateProtocolVersion(\"0.1.0\") = %q, want %q", got, LatestProtocolVersion)
+	}
+}
+
+func TestCheckProtocolCompatibility(t *testing.T) {
+	if err := CheckProtocolCompatibility("2024-11-05", "2024-11-05"); err != nil {
+		t.Errorf("Expected matching versions to be compatible, got %v", err)
+	}
+
+	err := CheckProtocolCompatibility("0.1.0", LatestProtocolVersion)
+	if err == nil {
+		t.Fatal("Expected mismatch error")
+	}
+	for _, want := range []string{`client requested "0.1.0"`, `server negotiated "` + LatestProtocolVersion + `"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestProtocolVersionFromInitializeResponse(t *testing.T) {
+	got, err := ProtocolVersionFromInitializeResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26"}}`))
+	if err != nil || got != "2025-03-26" {
+		t.Errorf("ProtocolVersionFromInitializeResponse() = %q, %v", got, err)
+	}
+
+	if _, err := ProtocolVersionFromInitializeResponse([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"bad"}}`)); err == nil {
+		t.Error("Expected error for an error response")
+	}
+	if _, err := ProtocolVersionFromInitializeResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`)); err == nil {
+		t.Error("Expected error for a missing protocolVersion")
+	}
+}
+
+func TestServerProtocolVersionNegotiation(t *testing.T) {
+	tests := []struct {
+		name           string
+		clientVersion  string
+		wantNegotiated string
+		wantCompatible bool
+	}{
+		{
+			name:           "compatible client version",
+			clientVersion:  "2025-03-26",
+			wantNegotiated: "2025-03-26",
+			wantCompatible: true,
+		},
+		{
+			name:           "incompatible client version",
+			clientVersion:  "0.1.0",
+			wantNegotiated: LatestProtocolVersion,
+			wantCompatible: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New("test-engine", "1.0.0")
+			if s.ProtocolVersion() != "" {
+				t.Fatalf("Expected no protocol version before initialization, got %q", s.ProtocolVersion())
+			}
+
+			clientIn, serverOut := io.Pipe()
+			serverIn, clientOut := io.Pipe()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				_ = s.server.Run(ctx, &mcp.IOTransport{Reader: serverIn, Writer: serverOut})
+			}()
+
+			initRequest := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`+"\n", tt.clientVersion)
+			if _, err := clientOut.Write([]byte(initRequest)); err != nil {
+				t.Fatalf("Failed to write initialize request: %v", err)
+			}
+
+			line, err := bufio.NewReader(clientIn).ReadBytes('\n')
+			if err != nil {
+				t.Fatalf("Failed to read initialize response: %v", err)
+			}
+
+			negotiated, err := ProtocolVersionFromInitializeResponse(line)
+			if err != nil {
+				t.Fatalf("ProtocolVersionFromInitializeResponse() error = %v", err)
+			}
+			if negotiated != tt.wantNegotiated {
+				t.Errorf("Negotiated version = %q, want %q", negotiated, tt.wantNegotiated)
+			}
+
+			err = CheckProtocolCompatibility(tt.clientVersion, negotiated)
+			if tt.wantCompatible && err != nil {
+				t.Errorf("Expected compatible versions, got %v", err)
+			}
+			if !tt.wantCompatible && err == nil {
+				t.Error("Expected a version mismatch error")
+			}
+
+			// The server exposes the negotiated version once the client is initialized
+			if _, err := clientOut.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized","params":{}}` + "\n")); err != nil {
+				t.Fatalf("Failed to write initialized notification: %v", err)
+			}
+			deadline := time.Now().Add(2 * time.Second)
+			for s.ProtocolVersion() == "" && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := s.ProtocolVersion(); got != tt.wantNegotiated {
+				t.Errorf("Server.ProtocolVersion() = %q, want %q", got, tt.wantNegotiated)
+			}
+
+			_ = clientOut.Close()
+		})
+	}
+}
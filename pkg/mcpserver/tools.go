@@ -0,0 +1,87 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpserver
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolInfo describes a tool registered with a Server, as advertised by tools/list.
+type ToolInfo struct {
+	// Name is the tool name.
+	Name string
+	// Description is the human-readable description of the tool.
+	Description string
+	// InputSchema is the JSON schema of the tool arguments, either provided with the tool
+	// or inferred from the handler's input type.
+	InputSchema any
+}
+
+// RegisteredTools returns the tools registered with the server, sorted by name.
+// It lets tests and documentation checks enumerate tools without going through JSON-RPC.
+func RegisteredTools(s *Server) []ToolInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tools := make([]ToolInfo, 0, len(s.tools))
+	for _, tool := range s.tools {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
+// recordTool remembers a registered tool, inferring its input schema from In the same way
+// the MCP SDK does when none is provided. Registering a name again replaces the tool.
+func recordTool[In any](s *Server, tool *mcp.Tool) {
+	info := ToolInfo{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: tool.InputSchema,
+	}
+	if info.InputSchema == nil {
+		info.InputSchema = inferInputSchema[In]()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tools == nil {
+		s.tools = map[string]ToolInfo{}
+	}
+	s.tools[tool.Name] = info
+}
+
+// inferInputSchema returns the JSON schema inferred for the input type In.
+func inferInputSchema[In any]() any {
+	rt := reflect.TypeFor[In]()
+	if rt == reflect.TypeFor[any]() {
+		return &jsonschema.Schema{Type: "object"}
+	}
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	schema, err := jsonschema.ForType(rt, &jsonschema.ForOptions{})
+	if err != nil {
+		// Unreachable for registered tools: mcp.AddTool panics on such types
+		return nil
+	}
+	return schema
+}
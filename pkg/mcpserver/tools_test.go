@@ -0,0 +1,133 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type greetInput struct {
+	Name  string `json:"name" jsonschema:"the person to greet"`
+	Times int    `json:"times,omitempty"`
+}
+
+func TestRegisteredTools(t *testing.T) {
+	s := New("test-server", "1.0.0")
+	if tools := RegisteredTools(s); len(tools) != 0 {
+		t.Fatalf("RegisteredTools() on a new server = %v, want none", tools)
+	}
+
+	statusSchema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"verbose": {Type: "boolean"}},
+	}
+	RegisterTool(s, &mcp.Tool{Name: "status", Description: "Report status", InputSchema: statusSchema},
+		func(ctx context.Context, req *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	RegisterTool(s, &mcp.Tool{Name: "greet", Description: "Greet someone"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input greetInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+
+	tools := RegisteredTools(s)
+	if len(tools) != 2 {
+		t.Fatalf("RegisteredTools() returned %d tools, want 2: %v", len(tools), tools)
+	}
+
+	// Sorted by name
+	if tools[0].Name != "greet" || tools[1].Name != "status" {
+		t.Fatalf("tool names = [%s %s], want [greet status]", tools[0].Name, tools[1].Name)
+	}
+	if tools[0].Description != "Greet someone" || tools[1].Description != "Report status" {
+		t.Errorf("unexpected descriptions: %q, %q", tools[0].Description, tools[1].Description)
+	}
+	if tools[1].InputSchema != statusSchema {
+		t.Errorf("provided input schema not reported as is: %v", tools[1].InputSchema)
+	}
+
+	greetSchema, ok := tools[0].InputSchema.(*jsonschema.Schema)
+	if !ok {
+		t.Fatalf("inferred input schema has type %T, want *jsonschema.Schema", tools[0].InputSchema)
+	}
+	if greetSchema.Properties["name"] == nil || greetSchema.Properties["times"] == nil {
+		t.Errorf("inferred input schema misses input fields: %v", greetSchema.Properties)
+	}
+	if !reflect.DeepEqual(greetSchema.Required, []string{"name"}) {
+		t.Errorf("inferred required fields = %v, want [name]", greetSchema.Required)
+	}
+
+	// The introspection output must match what clients see through tools/list
+	session := serveInMemory(t, s)
+	listed, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+	if len(listed.Tools) != len(tools) {
+		t.Fatalf("tools/list returned %d tools, want %d", len(listed.Tools), len(tools))
+	}
+	for i, tool := range listed.Tools {
+		if tool.Name != tools[i].Name || tool.Description != tools[i].Description {
+			t.Errorf("tools/list tool %d = %s (%q), want %s (%q)", i, tool.Name, tool.Description, tools[i].Name, tools[i].Description)
+		}
+		if got, want := schemaJSON(t, tool.InputSchema), schemaJSON(t, tools[i].InputSchema); got != want {
+			t.Errorf("tool %s: tools/list input schema = %s, want %s", tool.Name, got, want)
+		}
+	}
+}
+
+func TestRegisteredTools_ReregisteredToolIsReplaced(t *testing.T) {
+	s := New("test-server", "1.0.0")
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	RegisterTool(s, &mcp.Tool{Name: "build", Description: "old"}, handler)
+	RegisterTool(s, &mcp.Tool{Name: "build", Description: "new"}, handler)
+
+	tools := RegisteredTools(s)
+	if len(tools) != 1 || tools[0].Description != "new" {
+		t.Fatalf("RegisteredTools() = %v, want the latest build tool only", tools)
+	}
+	if got := schemaJSON(t, tools[0].InputSchema); got != `{"type":"object"}` {
+		t.Errorf("input schema for an untyped input = %s, want an empty object schema", got)
+	}
+}
+
+// schemaJSON returns the JSON encoding of a schema, normalized through a generic decode.
+func schemaJSON(t *testing.T, schema any) string {
+	t.Helper()
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+	data, err = json.Marshal(normalized)
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	return string(data)
+}
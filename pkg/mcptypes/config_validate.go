@@ -103,6 +103,10 @@ type ValidationError struct {
 	// Set by orchestrators during aggregation.
 	Path []string `json:"path,omitempty"`
 
+	// Line is the 1-based line in forge.yaml closest to the invalid field, or 0 if unknown.
+	// Set by forge during aggregation by resolving Path and Field against the config file.
+	Line int `json:"line,omitempty"`
+
 	// Nested contains validation errors from sub-engines (recursive validation).
 	// For example, testenv may contain errors from testenv-kind, testenv-lcr, etc.
 	// This allows preserving the full error tree for detailed diagnostics.
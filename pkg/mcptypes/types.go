@@ -36,6 +36,12 @@ type RunInput struct {
 	Env     map[string]string `json:"env,omitempty" jsonschema:"Environment variables as key-value pairs"`
 	EnvFile string            `json:"envFile,omitempty" jsonschema:"Path to file containing environment variables"`
 
+	// Labels are copied by the test-runner framework into the labels of the returned TestReport
+	Labels map[string]string `json:"labels,omitempty" jsonschema:"Labels to record on the test report (e.g. team or suite)"`
+
+	// Artifacts are the local outputs of the build stage, validated by the test-runner framework
+	Artifacts []forge.ArtifactRef `json:"artifacts,omitempty" jsonschema:"Local build artifacts (name, path, checksum) the tests can run against"`
+
 	// Artifact files from testenv (e.g., kubeconfig, registry credentials)
 	ArtifactFiles map[string]string `json:"artifactFiles,omitempty" jsonschema:"Map of artifact file keys to relative paths from testenv tmpDir"`
 
@@ -95,6 +101,9 @@ type BuildInput struct {
 	Env     map[string]string `json:"env,omitempty" jsonschema:"Environment variables as key-value pairs"`
 	EnvFile string            `json:"envFile,omitempty" jsonschema:"Path to file containing environment variables"`
 
+	// Labels are copied by the builder framework into the labels of the returned artifacts
+	Labels map[string]string `json:"labels,omitempty" jsonschema:"Labels to record on the built artifacts (e.g. team or component)"`
+
 	// Format-go specific fields (optional)
 	Path string `json:"path,omitempty" jsonschema:"Path to format (go-format engine only)"`
 
@@ -77,12 +77,35 @@ func extractErrorMessage(result *mcp.CallToolResult, err error) string {
 	return "unknown error"
 }
 
+// Batch item statuses reported in BatchItemResult.Status.
+const (
+	BatchItemSucceeded = "succeeded"
+	BatchItemFailed    = "failed"
+)
+
+// BatchItemResult is the outcome of one item of a batch operation.
+type BatchItemResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // BatchItemSucceeded or BatchItemFailed
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchItem returns the BatchItemResult of the item name from the result and error of its handler.
+func BatchItem(name string, result *mcp.CallToolResult, err error) BatchItemResult {
+	if err != nil || (result != nil && result.IsError) {
+		return BatchItemResult{Name: name, Status: BatchItemFailed, Error: extractErrorMessage(result, err)}
+	}
+	return BatchItemResult{Name: name, Status: BatchItemSucceeded}
+}
+
 // BatchResult wraps an array of artifacts in an object for MCP structured content.
 // Claude Code's MCP client requires structured content to always be an object, never a bare array.
 type BatchResult struct {
 	Artifacts []any  `json:"artifacts"`
 	Summary   string `json:"summary"`
 	Count     int    `json:"count"`
+	// Results lists the outcome of every item, in input order (optional)
+	Results []BatchItemResult `json:"results,omitempty"`
 }
 
 // FormatBatchResult creates an MCP result for batch operations.
@@ -119,3 +142,13 @@ func FormatBatchResult(operationType string, artifacts []any, errorMsgs []string
 		},
 	}, batchResult
 }
+
+// FormatBatchResultWithItems is FormatBatchResult with the per-item outcomes added to the
+// BatchResult, so that callers can tell which items failed and still use the artifacts of
+// the items that succeeded.
+func FormatBatchResultWithItems(operationType string, artifacts []any, errorMsgs []string, items []BatchItemResult) (*mcp.CallToolResult, any) {
+	result, out := FormatBatchResult(operationType, artifacts, errorMsgs)
+	batchResult := out.(BatchResult)
+	batchResult.Results = items
+	return result, batchResult
+}
@@ -180,3 +180,44 @@ func TestFormatBatchResult_Success(t *testing.T) {
 		t.Errorf("Expected Count to be 2, got %d", batchResult.Count)
 	}
 }
+
+func TestBatchItem(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		err    error
+		want   BatchItemResult
+	}{
+		{"success", SuccessResult("ok"), nil, BatchItemResult{Name: "app", Status: BatchItemSucceeded}},
+		{"error result", ErrorResult("Build failed: boom"), nil, BatchItemResult{Name: "app", Status: BatchItemFailed, Error: "Build failed: boom"}},
+		{"handler error", nil, errors.New("connection lost"), BatchItemResult{Name: "app", Status: BatchItemFailed, Error: "connection lost"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BatchItem("app", tt.result, tt.err); got != tt.want {
+				t.Errorf("BatchItem() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBatchResultWithItems(t *testing.T) {
+	items := []BatchItemResult{
+		{Name: "app1", Status: BatchItemSucceeded},
+		{Name: "app2", Status: BatchItemFailed, Error: "boom"},
+	}
+
+	result, out := FormatBatchResultWithItems("artifacts", []any{"app1-artifact"}, []string{"boom"}, items)
+	if !result.IsError {
+		t.Error("expected error result when an item failed")
+	}
+
+	batchResult, ok := out.(BatchResult)
+	if !ok {
+		t.Fatalf("expected BatchResult, got %T", out)
+	}
+	if batchResult.Count != 1 || len(batchResult.Results) != 2 || batchResult.Results[1] != items[1] {
+		t.Errorf("unexpected batch result: %+v", batchResult)
+	}
+}
@@ -17,6 +17,7 @@
 //
 // This package simplifies the creation of MCP servers by providing reusable patterns for:
 //   - Batch operation handling (HandleBatchBuild)
-//   - Input validation (ValidateRequired)
+//   - Input validation (ValidateRequired, ValidateSpec)
 //   - Standardized result creation (ErrorResult, SuccessResult, SuccessResultWithArtifact)
+//   - Warnings reported alongside results (WarningResult, SuccessResultWithWarnings, ResultWarnings)
 package mcputil
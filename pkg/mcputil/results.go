@@ -15,6 +15,8 @@
 package mcputil
 
 import (
+	"strings"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -102,3 +104,81 @@ func ErrorResultWithArtifact(message string, artifact any) (*mcp.CallToolResult,
 	}
 	return result, artifact
 }
+
+// SeverityMetaKey is the content metadata key marking the severity of a text content.
+// Warning contents carry SeverityWarning so that clients can tell them apart from
+// the result message and from errors.
+const SeverityMetaKey = "severity"
+
+// SeverityWarning is the SeverityMetaKey value of warning contents.
+const SeverityWarning = "warning"
+
+// warningContent creates the text content of a single warning.
+func warningContent(warning string) *mcp.TextContent {
+	return &mcp.TextContent{
+		Text: "Warning: " + warning,
+		Meta: mcp.Meta{SeverityMetaKey: SeverityWarning},
+	}
+}
+
+// WarningResult creates a non-error MCP result that reports a warning.
+// Use it when an operation completed but something should be surfaced to the user,
+// e.g. a best-effort cleanup step that failed.
+//
+// Parameters:
+//   - message: warning message to display
+//
+// Example usage:
+//
+//	return mcputil.WarningResult("release not found, nothing to uninstall"), nil, nil
+func WarningResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			warningContent(message),
+		},
+		IsError: false,
+	}
+}
+
+// SuccessResultWithWarnings creates a success result that returns an artifact and
+// reports warnings. The message is the first content; each warning follows as its
+// own content marked with SeverityWarning. Use ResultWarnings to read them back.
+//
+// Parameters:
+//   - message: success message to display
+//   - warnings: warnings to report (may be empty)
+//   - artifact: the artifact to return
+//
+// Returns:
+//   - result: the MCP CallToolResult
+//   - artifact: the artifact (passed through for MCP handler return)
+//
+// Example usage:
+//
+//	result, artifact := mcputil.SuccessResultWithWarnings("Deleted", warnings, myArtifact)
+//	return result, artifact, nil
+func SuccessResultWithWarnings(message string, warnings []string, artifact any) (*mcp.CallToolResult, any) {
+	result, artifact := SuccessResultWithArtifact(message, artifact)
+	for _, warning := range warnings {
+		result.Content = append(result.Content, warningContent(warning))
+	}
+	return result, artifact
+}
+
+// ResultWarnings returns the warnings reported in result, without the "Warning: " prefix.
+// Returns nil if result is nil or reports no warnings.
+func ResultWarnings(result *mcp.CallToolResult) []string {
+	if result == nil {
+		return nil
+	}
+
+	var warnings []string
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok || text.Meta[SeverityMetaKey] != SeverityWarning {
+			continue
+		}
+		warnings = append(warnings, strings.TrimPrefix(text.Text, "Warning: "))
+	}
+	return warnings
+}
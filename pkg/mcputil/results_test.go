@@ -134,3 +134,96 @@ func TestSuccessResultWithArtifact_WithComplexArtifact(t *testing.T) {
 		t.Errorf("Expected artifact version 'v1.0.0', got '%s'", complexArtifact.Version)
 	}
 }
+
+func TestWarningResult_IsNotAnError(t *testing.T) {
+	result := WarningResult("release not found")
+
+	if result == nil {
+		t.Fatal("Expected non-nil result")
+	}
+
+	if result.IsError {
+		t.Error("Expected IsError to be false")
+	}
+
+	if len(result.Content) != 1 {
+		t.Fatalf("Expected 1 content, got %d", len(result.Content))
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected Content[0] to be *TextContent")
+	}
+	if textContent.Text != "Warning: release not found" {
+		t.Errorf("Expected text 'Warning: release not found', got '%s'", textContent.Text)
+	}
+	if textContent.Meta[SeverityMetaKey] != SeverityWarning {
+		t.Errorf("Expected severity %q, got %v", SeverityWarning, textContent.Meta[SeverityMetaKey])
+	}
+
+	warnings := ResultWarnings(result)
+	if len(warnings) != 1 || warnings[0] != "release not found" {
+		t.Errorf("Expected warnings [release not found], got %v", warnings)
+	}
+}
+
+func TestSuccessResultWithWarnings_KeepsWarningsDistinct(t *testing.T) {
+	warnings := []string{"failed to remove kubeconfig", "release cert-manager not found"}
+
+	result, artifact := SuccessResultWithWarnings("Deleted test environment", warnings, "test-artifact")
+
+	if result.IsError {
+		t.Error("Expected IsError to be false")
+	}
+
+	if artifact.(string) != "test-artifact" {
+		t.Errorf("Expected artifact 'test-artifact', got '%v'", artifact)
+	}
+
+	if len(result.Content) != 3 {
+		t.Fatalf("Expected 3 contents, got %d", len(result.Content))
+	}
+
+	message, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected Content[0] to be *TextContent")
+	}
+	if message.Text != "Deleted test environment" {
+		t.Errorf("Expected message 'Deleted test environment', got '%s'", message.Text)
+	}
+	if _, ok := message.Meta[SeverityMetaKey]; ok {
+		t.Error("Expected the message to carry no severity")
+	}
+
+	got := ResultWarnings(result)
+	if len(got) != len(warnings) {
+		t.Fatalf("Expected %d warnings, got %v", len(warnings), got)
+	}
+	for i := range warnings {
+		if got[i] != warnings[i] {
+			t.Errorf("Expected warning %d to be '%s', got '%s'", i, warnings[i], got[i])
+		}
+	}
+}
+
+func TestSuccessResultWithWarnings_NoWarnings(t *testing.T) {
+	result, _ := SuccessResultWithWarnings("Built successfully", nil, nil)
+
+	if len(result.Content) != 1 {
+		t.Errorf("Expected 1 content, got %d", len(result.Content))
+	}
+
+	if warnings := ResultWarnings(result); warnings != nil {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestResultWarnings_IgnoresErrors(t *testing.T) {
+	if warnings := ResultWarnings(ErrorResult("Warning: not really")); warnings != nil {
+		t.Errorf("Expected no warnings in an error result, got %v", warnings)
+	}
+
+	if warnings := ResultWarnings(nil); warnings != nil {
+		t.Errorf("Expected no warnings for a nil result, got %v", warnings)
+	}
+}
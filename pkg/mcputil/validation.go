@@ -16,6 +16,9 @@ package mcputil
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -76,3 +79,104 @@ func ValidateRequiredWithPrefix(prefix string, fields map[string]string) *mcp.Ca
 	}
 	return nil
 }
+
+// SpecType is the type expected for a spec value by a SpecRule.
+type SpecType string
+
+// Spec value types checked by ValidateSpec.
+const (
+	SpecTypeString SpecType = "string"
+	SpecTypeInt    SpecType = "int"
+	SpecTypeBool   SpecType = "bool"
+	SpecTypeSlice  SpecType = "slice"
+)
+
+// SpecRule declares the constraints on one key of an engine spec.
+type SpecRule struct {
+	Key      string   // Spec key (e.g., "sourceType")
+	Type     SpecType // Expected type; empty accepts any type
+	Required bool     // Key must be present (and a non-empty string for SpecTypeString)
+	Enum     []string // Allowed values, compared with fmt.Sprint (optional)
+}
+
+// ValidateSpec checks spec against rules and returns a single MCP error result
+// listing every violation, or nil if spec is valid.
+//
+// Parameters:
+//   - prefix: error message prefix (e.g., "Create failed")
+//   - spec: the engine spec (typically input.Spec)
+//   - rules: the constraints to check, reported in order
+//
+// Example usage:
+//
+//	if result := mcputil.ValidateSpec("Create failed", input.Spec, []mcputil.SpecRule{
+//	    {Key: "sourceType", Type: mcputil.SpecTypeString, Required: true, Enum: []string{"helm-repo", "local"}},
+//	    {Key: "timeout", Type: mcputil.SpecTypeInt},
+//	}); result != nil {
+//	    return result, nil, nil
+//	}
+func ValidateSpec(prefix string, spec map[string]any, rules []SpecRule) *mcp.CallToolResult {
+	violations := SpecViolations(spec, rules)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("%s: invalid spec: %s", prefix, strings.Join(violations, "; "))},
+		},
+		IsError: true,
+	}
+}
+
+// SpecViolations returns a message for each rule that spec violates, in rule order.
+// Use it instead of ValidateSpec when the violations are not returned as an MCP result.
+func SpecViolations(spec map[string]any, rules []SpecRule) []string {
+	var violations []string
+	for _, rule := range rules {
+		value, ok := spec[rule.Key]
+		if !ok || value == nil || (rule.Type == SpecTypeString && value == "") {
+			if rule.Required {
+				violations = append(violations, fmt.Sprintf("missing required field '%s'", rule.Key))
+			}
+			continue
+		}
+
+		if rule.Type != "" && !hasSpecType(value, rule.Type) {
+			violations = append(violations, fmt.Sprintf("field '%s' must be %s, got %T", rule.Key, rule.Type, value))
+			continue
+		}
+
+		if len(rule.Enum) > 0 && !slices.Contains(rule.Enum, fmt.Sprint(value)) {
+			violations = append(violations, fmt.Sprintf("field '%s' must be one of [%s], got %q", rule.Key, strings.Join(rule.Enum, ", "), fmt.Sprint(value)))
+		}
+	}
+	return violations
+}
+
+// hasSpecType reports whether value has type t. Integers decoded from JSON are
+// float64, so whole floats are accepted as SpecTypeInt.
+func hasSpecType(value any, t SpecType) bool {
+	switch t {
+	case SpecTypeString:
+		_, ok := value.(string)
+		return ok
+	case SpecTypeBool:
+		_, ok := value.(bool)
+		return ok
+	case SpecTypeInt:
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			return f == float64(int64(f))
+		}
+		return false
+	case SpecTypeSlice:
+		kind := reflect.ValueOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	}
+	return false
+}
@@ -17,6 +17,7 @@
 package mcputil
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -109,3 +110,122 @@ func TestValidateRequiredWithPrefix_AllValid(t *testing.T) {
 		t.Errorf("Expected nil result for valid fields, got error: %v", result)
 	}
 }
+
+func TestSpecViolations(t *testing.T) {
+	rules := []SpecRule{
+		{Key: "sourceType", Type: SpecTypeString, Required: true, Enum: []string{"helm-repo", "local", "oci"}},
+		{Key: "timeout", Type: SpecTypeInt},
+		{Key: "wait", Type: SpecTypeBool},
+		{Key: "charts", Type: SpecTypeSlice, Required: true},
+	}
+
+	tests := []struct {
+		name string
+		spec map[string]any
+		want []string
+	}{
+		{
+			name: "valid spec",
+			spec: map[string]any{"sourceType": "local", "timeout": 30, "wait": true, "charts": []any{"a"}},
+			want: nil,
+		},
+		{
+			name: "json numbers are accepted as int",
+			spec: map[string]any{"sourceType": "oci", "timeout": float64(30), "charts": []string{"a"}},
+			want: nil,
+		},
+		{
+			name: "missing required fields",
+			spec: map[string]any{},
+			want: []string{"missing required field 'sourceType'", "missing required field 'charts'"},
+		},
+		{
+			name: "empty required string",
+			spec: map[string]any{"sourceType": "", "charts": []any{}},
+			want: []string{"missing required field 'sourceType'"},
+		},
+		{
+			name: "nil value is missing",
+			spec: map[string]any{"sourceType": "local", "charts": nil},
+			want: []string{"missing required field 'charts'"},
+		},
+		{
+			name: "wrong types",
+			spec: map[string]any{"sourceType": 1, "timeout": "30s", "wait": "yes", "charts": "a"},
+			want: []string{
+				"field 'sourceType' must be string, got int",
+				"field 'timeout' must be int, got string",
+				"field 'wait' must be bool, got string",
+				"field 'charts' must be slice, got string",
+			},
+		},
+		{
+			name: "fractional number is not int",
+			spec: map[string]any{"sourceType": "local", "timeout": 1.5, "charts": []any{}},
+			want: []string{"field 'timeout' must be int, got float64"},
+		},
+		{
+			name: "out of enum",
+			spec: map[string]any{"sourceType": "git", "charts": []any{}},
+			want: []string{`field 'sourceType' must be one of [helm-repo, local, oci], got "git"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpecViolations(tt.spec, rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SpecViolations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecViolations_EnumOfInts(t *testing.T) {
+	rules := []SpecRule{{Key: "replicas", Type: SpecTypeInt, Enum: []string{"1", "3"}}}
+
+	if got := SpecViolations(map[string]any{"replicas": float64(3)}, rules); got != nil {
+		t.Errorf("Expected no violation, got %v", got)
+	}
+	if got := SpecViolations(map[string]any{"replicas": 2}, rules); len(got) != 1 {
+		t.Errorf("Expected 1 violation, got %v", got)
+	}
+}
+
+func TestValidateSpec_AggregatesViolations(t *testing.T) {
+	result := ValidateSpec("Create failed", map[string]any{"sourceType": "git", "timeout": "30s"}, []SpecRule{
+		{Key: "sourceType", Type: SpecTypeString, Required: true, Enum: []string{"helm-repo", "local"}},
+		{Key: "timeout", Type: SpecTypeInt},
+		{Key: "charts", Type: SpecTypeSlice, Required: true},
+	})
+
+	if result == nil {
+		t.Fatal("Expected error result for invalid spec")
+	}
+
+	if !result.IsError {
+		t.Error("Expected IsError to be true")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected Content[0] to be *TextContent")
+	}
+
+	expected := `Create failed: invalid spec: field 'sourceType' must be one of [helm-repo, local], got "git"; ` +
+		"field 'timeout' must be int, got string; missing required field 'charts'"
+	if textContent.Text != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, textContent.Text)
+	}
+}
+
+func TestValidateSpec_Valid(t *testing.T) {
+	result := ValidateSpec("Create failed", map[string]any{"sourceType": "local"}, []SpecRule{
+		{Key: "sourceType", Type: SpecTypeString, Required: true, Enum: []string{"helm-repo", "local"}},
+		{Key: "timeout", Type: SpecTypeInt},
+	})
+
+	if result != nil {
+		t.Errorf("Expected nil result for valid spec, got: %v", result)
+	}
+}
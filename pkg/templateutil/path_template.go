@@ -0,0 +1,115 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templateutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Placeholders supported in path templates.
+const (
+	PathPlaceholderName    = "name"
+	PathPlaceholderVersion = "version"
+	PathPlaceholderStage   = "stage"
+)
+
+var (
+	// ErrUnknownPathPlaceholder is returned when a path template references an unsupported placeholder.
+	ErrUnknownPathPlaceholder = errors.New("unknown path placeholder")
+	// ErrMalformedPathTemplate is returned when a path template contains an unterminated placeholder.
+	ErrMalformedPathTemplate = errors.New("malformed path template")
+)
+
+// PathValues holds the values substituted into a path template.
+type PathValues struct {
+	// Name replaces {name}, e.g. the artifact or spec name.
+	Name string
+	// Version replaces {version}, e.g. an API version such as "v1".
+	Version string
+	// Stage replaces {stage}, e.g. the test stage such as "integration".
+	Stage string
+}
+
+// lookup returns the value of the placeholder and whether the placeholder is supported.
+func (v PathValues) lookup(placeholder string) (string, bool) {
+	switch placeholder {
+	case PathPlaceholderName:
+		return v.Name, true
+	case PathPlaceholderVersion:
+		return v.Version, true
+	case PathPlaceholderStage:
+		return v.Stage, true
+	default:
+		return "", false
+	}
+}
+
+// ExpandPath replaces the {name}, {version} and {stage} placeholders of a path template
+// with the given values. Placeholders with an empty value are replaced by an empty string.
+//
+// Example:
+//
+//	path, err := templateutil.ExpandPath("./api/{name}.{version}.yaml",
+//	    templateutil.PathValues{Name: "users", Version: "v1"})
+//	// path == "./api/users.v1.yaml"
+//
+// Returns an error wrapping ErrUnknownPathPlaceholder listing every unsupported placeholder,
+// or ErrMalformedPathTemplate if a placeholder is not terminated.
+func ExpandPath(tmpl string, values PathValues) (string, error) {
+	var out strings.Builder
+	var unknown []string
+
+	rest := tmpl
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:start])
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: unterminated placeholder in %q", ErrMalformedPathTemplate, tmpl)
+		}
+
+		placeholder := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		value, ok := values.lookup(placeholder)
+		if !ok {
+			unknown = append(unknown, "{"+placeholder+"}")
+			continue
+		}
+		out.WriteString(value)
+	}
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("%w %s in %q (supported: {%s}, {%s}, {%s})",
+			ErrUnknownPathPlaceholder, strings.Join(unknown, ", "), tmpl,
+			PathPlaceholderName, PathPlaceholderVersion, PathPlaceholderStage)
+	}
+
+	return out.String(), nil
+}
+
+// ValidatePathTemplate checks that a path template only uses supported placeholders,
+// e.g. when validating configuration before any value is known.
+func ValidatePathTemplate(tmpl string) error {
+	_, err := ExpandPath(tmpl, PathValues{})
+	return err
+}
@@ -0,0 +1,126 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templateutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	values := PathValues{Name: "users", Version: "v1", Stage: "integration"}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{name: "no placeholder", tmpl: "./api/spec.yaml", want: "./api/spec.yaml"},
+		{name: "name and version", tmpl: "./api/{name}.{version}.yaml", want: "./api/users.v1.yaml"},
+		{name: "stage", tmpl: "build/{stage}/report.json", want: "build/integration/report.json"},
+		{name: "repeated placeholder", tmpl: "{name}/{name}.go", want: "users/users.go"},
+		{name: "adjacent placeholders", tmpl: "{name}{version}{stage}", want: "usersv1integration"},
+		{name: "stray closing brace", tmpl: "a}/{name}", want: "a}/users"},
+		{name: "empty template", tmpl: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPath(tt.tmpl, values)
+			if err != nil {
+				t.Fatalf("ExpandPath(%q) error = %v", tt.tmpl, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPath_EmptyValue(t *testing.T) {
+	got, err := ExpandPath("out/{stage}/{name}", PathValues{Name: "app"})
+	if err != nil {
+		t.Fatalf("ExpandPath() error = %v", err)
+	}
+	if got != "out//app" {
+		t.Errorf("ExpandPath() = %q, want %q", got, "out//app")
+	}
+}
+
+func TestExpandPath_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		tmpl        string
+		wantErr     error
+		wantMessage []string
+	}{
+		{
+			name:        "unknown placeholder",
+			tmpl:        "./api/{service}.yaml",
+			wantErr:     ErrUnknownPathPlaceholder,
+			wantMessage: []string{"{service}", "supported: {name}, {version}, {stage}"},
+		},
+		{
+			name:        "all unknown placeholders are listed",
+			tmpl:        "{name}/{os}/{arch}",
+			wantErr:     ErrUnknownPathPlaceholder,
+			wantMessage: []string{"{os}, {arch}"},
+		},
+		{
+			name:        "placeholders are case sensitive",
+			tmpl:        "{Name}",
+			wantErr:     ErrUnknownPathPlaceholder,
+			wantMessage: []string{"{Name}"},
+		},
+		{
+			name:        "empty placeholder",
+			tmpl:        "a/{}/b",
+			wantErr:     ErrUnknownPathPlaceholder,
+			wantMessage: []string{"{}"},
+		},
+		{
+			name:        "unterminated placeholder",
+			tmpl:        "./api/{name.yaml",
+			wantErr:     ErrMalformedPathTemplate,
+			wantMessage: []string{"unterminated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExpandPath(tt.tmpl, PathValues{Name: "users"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExpandPath(%q) error = %v, want %v", tt.tmpl, err, tt.wantErr)
+			}
+			for _, want := range tt.wantMessage {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ExpandPath(%q) error = %q, want it to contain %q", tt.tmpl, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePathTemplate(t *testing.T) {
+	if err := ValidatePathTemplate("./{stage}/{name}-{version}"); err != nil {
+		t.Errorf("ValidatePathTemplate() error = %v, want supported placeholders to be valid", err)
+	}
+	if err := ValidatePathTemplate("./{unknown}"); !errors.Is(err, ErrUnknownPathPlaceholder) {
+		t.Errorf("ValidatePathTemplate() error = %v, want ErrUnknownPathPlaceholder", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing provides optional OpenTelemetry tracing for forge engine operations.
+//
+// Tracing is disabled unless an OTLP endpoint is configured through the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment
+// variables. When disabled, spans are no-ops.
+//
+// Trace context crosses MCP calls in the request's _meta field, using the W3C
+// traceparent and tracestate keys, so that engine spans join the caller's trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of forge spans.
+const tracerName = "github.com/alexandremahdhaoui/forge"
+
+// Span attribute keys set on every operation span.
+const (
+	AttributeEngine    = "forge.engine"
+	AttributeOperation = "forge.operation"
+)
+
+// propagator carries the trace context across MCP calls.
+var propagator = propagation.TraceContext{}
+
+// Enabled reports whether an OTLP endpoint is configured.
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs a tracer provider exporting spans over OTLP/HTTP for serviceName.
+// It does nothing when no OTLP endpoint is configured (see Enabled).
+// The returned shutdown function flushes and stops the exporter; it is never nil.
+//
+// Spans are exported synchronously as they end: forge and its engines often exit
+// through os.Exit, which would drop spans still waiting in a batch.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !Enabled() {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// StartOperation starts a span for an engine operation (e.g. build, test, create, delete).
+// The span is named "<engine> <operation>" and records both as attributes.
+// End it with End.
+func StartOperation(ctx context.Context, engine, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, engine+" "+operation,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String(AttributeEngine, engine),
+			attribute.String(AttributeOperation, operation),
+		),
+	)
+}
+
+// StartCall starts a span for an MCP tool call to the engine run by command and args.
+// Pass the returned context to InjectMeta to propagate the trace to the engine.
+// End it with End.
+func StartCall(ctx context.Context, command string, args []string, tool string) (context.Context, trace.Span) {
+	engine := engineLabel(command, args)
+	return otel.Tracer(tracerName).Start(ctx, "call "+engine+" "+tool,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String(AttributeEngine, engine),
+			attribute.String(AttributeOperation, tool),
+		),
+	)
+}
+
+// engineLabel names the engine run by command and args: the package path for
+// "go run <package>", the binary name otherwise.
+func engineLabel(command string, args []string) string {
+	if command == "go" && len(args) > 1 && args[0] == "run" {
+		return args[len(args)-1]
+	}
+	return filepath.Base(command)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectMeta returns MCP request metadata carrying the trace context of ctx.
+// Returns nil when ctx has no valid span context.
+func InjectMeta(ctx context.Context) mcp.Meta {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+
+	meta := make(mcp.Meta, len(carrier))
+	for key, value := range carrier {
+		meta[key] = value
+	}
+	return meta
+}
+
+// ExtractMeta returns ctx with the trace context carried by MCP request metadata, if any.
+func ExtractMeta(ctx context.Context, meta mcp.Meta) context.Context {
+	carrier := propagation.MapCarrier{}
+	for _, key := range propagator.Fields() {
+		if value, ok := meta[key].(string); ok {
+			carrier[key] = value
+		}
+	}
+	return propagator.Extract(ctx, carrier)
+}
+
+// ContextFromRequest returns ctx with the trace context of the MCP tool call request, if any.
+func ContextFromRequest(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil || req.Params == nil {
+		return ctx
+	}
+	return ExtractMeta(ctx, req.Params.Meta)
+}
@@ -0,0 +1,102 @@
+//go:build unit
+
+// Copyright 2024 Alexandre Mahdhaoui
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup_NoopWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	if Enabled() {
+		t.Fatal("Expected tracing to be disabled")
+	}
+
+	shutdown, err := Setup(context.Background(), "test-engine")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+
+	_, span := StartOperation(context.Background(), "test-engine", "build")
+	defer span.End()
+	if span.SpanContext().IsValid() {
+		t.Error("Expected a no-op span without an endpoint")
+	}
+}
+
+func TestInjectExtractMeta_RoundTrip(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "call")
+	defer span.End()
+
+	meta := InjectMeta(ctx)
+	if _, ok := meta["traceparent"].(string); !ok {
+		t.Fatalf("Expected a traceparent in the metadata, got %v", meta)
+	}
+
+	extracted := trace.SpanContextFromContext(ExtractMeta(context.Background(), meta))
+	if extracted.TraceID() != span.SpanContext().TraceID() || extracted.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("Expected extracted span context %v, got %v", span.SpanContext(), extracted)
+	}
+	if !extracted.IsRemote() {
+		t.Error("Expected the extracted span context to be remote")
+	}
+}
+
+func TestInjectMeta_WithoutSpan(t *testing.T) {
+	if meta := InjectMeta(context.Background()); meta != nil {
+		t.Errorf("Expected no metadata without a span, got %v", meta)
+	}
+}
+
+func TestContextFromRequest_IgnoresMissingMeta(t *testing.T) {
+	for _, req := range []*mcp.CallToolRequest{nil, {}, {Params: &mcp.CallToolParamsRaw{}}} {
+		ctx := ContextFromRequest(context.Background(), req)
+		if trace.SpanContextFromContext(ctx).IsValid() {
+			t.Errorf("Expected no span context for request %v", req)
+		}
+	}
+}
+
+func TestEngineLabel(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    string
+	}{
+		{"go", []string{"run", "github.com/alexandremahdhaoui/forge/cmd/go-build@v1.0.0"}, "github.com/alexandremahdhaoui/forge/cmd/go-build@v1.0.0"},
+		{"/usr/local/bin/go-build", nil, "go-build"},
+		{"go-build", nil, "go-build"},
+	}
+	for _, tt := range tests {
+		if got := engineLabel(tt.command, tt.args); got != tt.want {
+			t.Errorf("engineLabel(%q, %v) = %q, want %q", tt.command, tt.args, got, tt.want)
+		}
+	}
+}